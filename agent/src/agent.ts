@@ -1,4 +1,4 @@
-import { generateText, ImagePart, LanguageModelUsage, ModelMessage, stepCountIs, streamText, UserModelMessage } from 'ai'
+import { FilePart, generateText, ImagePart, LanguageModelUsage, ModelMessage, stepCountIs, streamText, UserModelMessage } from 'ai'
 import { AgentInput, AgentParams, AgentSkill, allActions, HTTPMCPConnection, MCPConnection, Schedule, StdioMCPConnection } from './types'
 import { system, schedule, user, subagentSystem } from './prompts'
 import { AuthFetcher } from './index'
@@ -11,7 +11,7 @@ import {
   dedupeAttachments,
   AttachmentsStreamExtractor,
 } from './utils/attachments'
-import type { ContainerFileAttachment } from './types/attachment'
+import type { AudioAttachment, ContainerFileAttachment, ImageAttachment } from './types/attachment'
 import { getMCPTools } from './tools/mcp'
 
 export const createAgent = ({
@@ -127,7 +127,8 @@ export const createAgent = ({
   }
 
   const generateUserPrompt = (input: AgentInput) => {
-    const images = input.attachments.filter(attachment => attachment.type === 'image')
+    const images = input.attachments.filter((a): a is ImageAttachment => a.type === 'image')
+    const audios = input.attachments.filter((a): a is AudioAttachment => a.type === 'audio')
     const files = input.attachments.filter((a): a is ContainerFileAttachment => a.type === 'file')
     const text = user(input.query, {
       contactId: identity.contactId,
@@ -140,7 +141,8 @@ export const createAgent = ({
       role: 'user',
       content: [
         { type: 'text', text },
-        ...images.map(image => ({ type: 'image', image: image.base64 }) as ImagePart),
+        ...images.map(image => ({ type: 'image', image: image.base64, mediaType: image.mediaType }) as ImagePart),
+        ...audios.map(audio => ({ type: 'file', data: audio.base64, mediaType: audio.mediaType }) as FilePart),
       ]
     }
     return userMessage
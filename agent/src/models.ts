@@ -13,7 +13,7 @@ export const ClientTypeModel = z.enum(['openai', 'openai-compatible', 'anthropic
 export const ModelConfigModel = z.object({
   modelId: z.string().min(1, 'Model ID is required'),
   clientType: ClientTypeModel,
-  input: z.array(z.enum(['text', 'image'])),
+  input: z.array(z.enum(['text', 'image', 'audio'])),
   apiKey: z.string().min(1, 'API key is required'),
   baseUrl: z.string(),
 })
@@ -45,6 +45,14 @@ export const ScheduleModel = z.object({
 export const ImageAttachmentModel = z.object({
   type: z.literal('image'),
   base64: z.string().min(1, 'Image base64 is required'),
+  mediaType: z.string().optional(),
+  metadata: z.record(z.string(), z.any()).optional(),
+})
+
+export const AudioAttachmentModel = z.object({
+  type: z.literal('audio'),
+  base64: z.string().min(1, 'Audio base64 is required'),
+  mediaType: z.string().min(1, 'Audio media type is required'),
   metadata: z.record(z.string(), z.any()).optional(),
 })
 
@@ -54,7 +62,7 @@ export const FileAttachmentModel = z.object({
   metadata: z.record(z.string(), z.any()).optional(),
 })
 
-export const AttachmentModel = z.union([ImageAttachmentModel, FileAttachmentModel])
+export const AttachmentModel = z.union([ImageAttachmentModel, AudioAttachmentModel, FileAttachmentModel])
 
 export const HTTPMCPConnectionModel = z.object({
   name: z.string().min(1, 'Name is required'),
@@ -31,7 +31,7 @@ export const getTools = (
     Object.assign(tools, scheduleTools)
   }
   if (actions.includes(AgentAction.Memory)) {
-    const memoryTools = getMemoryTools({ fetch })
+    const memoryTools = getMemoryTools({ fetch, identity })
     Object.assign(tools, memoryTools)
   }
   if (actions.includes(AgentAction.Subagent)) {
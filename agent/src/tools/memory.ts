@@ -1,9 +1,11 @@
 import { tool } from 'ai'
 import { AuthFetcher } from '..'
 import { z } from 'zod'
+import type { IdentityContext } from '../types'
 
 export type MemoryToolParams = {
   fetch: AuthFetcher
+  identity: IdentityContext
 }
 
 type MemorySearchItem = {
@@ -16,20 +18,24 @@ type MemorySearchItem = {
   }
 }
 
-export const getMemoryTools = ({ fetch }: MemoryToolParams) => {
+export const getMemoryTools = ({ fetch, identity }: MemoryToolParams) => {
+  const botId = identity.botId.trim()
+  const sessionId = encodeURIComponent(identity.sessionId.trim())
   const searchMemory = tool({
     description: 'Search for memories',
     inputSchema: z.object({
       query: z.string().describe('The query to search for memories'),
     }),
     execute: async ({ query }) => {
-      const response = await fetch('/memory/search', {
+      // The speaker decides which private memories are visible in the conversation.
+      const response = await fetch(`/bots/${botId}/memory/search?session_id=${sessionId}`, {
         method: 'POST',
         headers: {
           'Content-Type': 'application/json',
         },
         body: JSON.stringify({
           query,
+          contact_id: identity.contactId,
         }),
       })
       const data = await response.json()
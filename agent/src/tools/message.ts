@@ -11,48 +11,62 @@ export type MessageToolParams = {
 const SendMessageSchema = z.object({
   bot_id: z.string().optional(),
   platform: z.string().optional(),
-  target: z.string().optional(),
+  target: z.string().optional().describe('Platform target ID, e.g. a chat ID'),
+  to: z.string().optional().describe('Recipient name, @handle or group name, resolved through the channel directory'),
   to_user_id: z.string().optional(),
   message: z.string(),
+  buttons: z.array(z.object({
+    label: z.string(),
+    value: z.string().describe('Returned to you as `[action:<value>]` when the user presses the button'),
+  })).optional().describe('Buttons offering choices to the user, e.g. Approve / Reject'),
 })
 
 export const getMessageTools = ({ fetch, identity }: MessageToolParams) => {
   const sendMessage = tool({
-    description: 'Send a message to a channel or session',
+    description: 'Send a message to a channel or session. Use `to` to address a contact or group by name; omit target, to and to_user_id to reply in the current session',
     inputSchema: SendMessageSchema,
     execute: async (payload) => {
       const botId = (payload.bot_id ?? identity.botId ?? '').trim()
       const platform = (payload.platform ?? identity.currentPlatform ?? '').trim()
       const replyTarget = (identity.replyTarget ?? '').trim()
-      const target = (payload.target ?? replyTarget).trim()
+      const explicitTarget = (payload.target ?? '').trim()
+      const to = (payload.to ?? '').trim()
       const toUserID = (payload.to_user_id ?? '').trim()
+      const target = explicitTarget || (to || toUserID ? '' : replyTarget)
       if (!botId) {
         throw new Error('bot_id is required')
       }
       if (!platform) {
         throw new Error('platform is required')
       }
-      if (!target && !toUserID && !identity.sessionToken) {
-        throw new Error('target or to_user_id is required')
+      if (!target && !to && !toUserID && !identity.sessionToken) {
+        throw new Error('target, to or to_user_id is required')
       }
-      // Use session token if available and no explicit to_user_id specified
+      // Use session token if available and no explicit destination specified
       // This allows replying to current session without needing explicit auth
-      const useSessionToken = !!identity.sessionToken && !toUserID
+      const useSessionToken = !!identity.sessionToken && !explicitTarget && !to && !toUserID
       console.log('[Tool] send_message', {
         botId,
         platform,
         target: target || undefined,
+        to: to || undefined,
         toUserID: toUserID || undefined,
         replyTarget,
         useSessionToken,
       })
-      const body: Record<string, unknown> = { message: payload.message }
+      const message: Record<string, unknown> = { text: payload.message }
+      if (payload.buttons?.length) {
+        message.actions = payload.buttons.map(button => ({ type: 'button', label: button.label, value: button.value }))
+      }
+      const body: Record<string, unknown> = { message }
       if (!useSessionToken) {
         if (target) {
-          body.to = target
+          body.target = target
+        } else if (to) {
+          body.to = to
         }
         if (toUserID) {
-          body.to_user_id = toUserID
+          body.user_id = toUserID
         }
       }
       const url = useSessionToken
@@ -67,6 +81,10 @@ export const getMessageTools = ({ fetch, identity }: MessageToolParams) => {
         headers,
         body: JSON.stringify(body),
       })
+      if (!response.ok) {
+        const text = await response.text().catch(() => '')
+        throw new Error(`send_message failed: ${response.status} ${text}`)
+      }
       const result = await response.json()
       return {
         ...result,
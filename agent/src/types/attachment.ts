@@ -6,6 +6,13 @@ export interface BaseAgentAttachment {
 export interface ImageAttachment extends BaseAgentAttachment {
   type: 'image'
   base64: string
+  mediaType?: string
+}
+
+export interface AudioAttachment extends BaseAgentAttachment {
+  type: 'audio'
+  base64: string
+  mediaType: string
 }
 
 export interface ContainerFileAttachment extends BaseAgentAttachment {
@@ -13,4 +20,4 @@ export interface ContainerFileAttachment extends BaseAgentAttachment {
   path: string
 }
 
-export type AgentAttachment = ImageAttachment | ContainerFileAttachment
\ No newline at end of file
+export type AgentAttachment = ImageAttachment | AudioAttachment | ContainerFileAttachment
\ No newline at end of file
@@ -8,6 +8,7 @@ export enum ClientType {
 export enum ModelInput {
   Text = 'text',
   Image = 'image',
+  Audio = 'audio',
 }
 
 export interface ModelConfig {
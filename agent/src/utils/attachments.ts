@@ -11,6 +11,7 @@ const getAttachmentKey = (a: AgentAttachment): string => {
   switch (a.type) {
     case 'file': return `file:${a.path}`
     case 'image': return `image:${a.base64.slice(0, 64)}`
+    case 'audio': return `audio:${a.base64.slice(0, 64)}`
   }
 }
 
@@ -10,15 +10,21 @@ import (
 
 	"github.com/memohai/memoh/internal/bots"
 	"github.com/memohai/memoh/internal/channel"
+	"github.com/memohai/memoh/internal/channel/adapters/discord"
+	"github.com/memohai/memoh/internal/channel/adapters/email"
 	"github.com/memohai/memoh/internal/channel/adapters/feishu"
 	"github.com/memohai/memoh/internal/channel/adapters/local"
+	"github.com/memohai/memoh/internal/channel/adapters/matrix"
+	"github.com/memohai/memoh/internal/channel/adapters/slack"
 	"github.com/memohai/memoh/internal/channel/adapters/telegram"
+	"github.com/memohai/memoh/internal/channel/adapters/webhook"
 	"github.com/memohai/memoh/internal/chat"
 	"github.com/memohai/memoh/internal/config"
 	"github.com/memohai/memoh/internal/contacts"
 	ctr "github.com/memohai/memoh/internal/containerd"
 	"github.com/memohai/memoh/internal/db"
 	dbsqlc "github.com/memohai/memoh/internal/db/sqlc"
+	"github.com/memohai/memoh/internal/directory"
 	"github.com/memohai/memoh/internal/embeddings"
 	"github.com/memohai/memoh/internal/handlers"
 	"github.com/memohai/memoh/internal/history"
@@ -38,6 +44,7 @@ import (
 	"github.com/memohai/memoh/internal/version"
 
 	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
 	"golang.org/x/crypto/bcrypt"
 )
 
@@ -130,10 +137,21 @@ func main() {
 	if hasEmbeddingModels && multimodalModel.ModelID == "" {
 		logger.Warn("No multimodal embedding model configured. Multimodal embedding features will be limited.")
 	}
-	store := buildQdrantStore(logger.L, cfg.Qdrant, vectors, hasEmbeddingModels, textModel.Dimensions)
+	store := buildMemoryStore(logger.L, cfg, conn, vectors, hasEmbeddingModels, textModel.Dimensions)
 
 	bm25Indexer := memory.NewBM25Indexer(logger.L)
 	memoryService := memory.NewService(logger.L, llmClient, textEmbedder, store, resolver, bm25Indexer, textModel.ModelID, multimodalModel.ModelID)
+	memoryService.SetSearchConfig(cfg.Memory)
+	memoryService.SetHistoryStore(memory.NewDBHistoryStore(queries))
+	memoryService.SetReindexStore(memory.NewDBReindexStore(queries))
+	if qdrantStore, ok := store.(*memory.QdrantStore); ok {
+		memoryService.SetSiblingStores(qdrantStore.Collection(), func(collection string, dimension int) (memory.VectorStore, error) {
+			return qdrantStore.NewSibling(collection, dimension)
+		})
+	}
+	if err := memoryService.ResumeReindexJobs(ctx); err != nil {
+		logger.Warn("resume memory reindex failed", slog.Any("error", err))
+	}
 	memoryHandler := handlers.NewMemoryHandler(logger.L, memoryService, botService, usersService)
 	go func() {
 		if err := memoryService.WarmupBM25(ctx, 200); err != nil {
@@ -145,6 +163,7 @@ func main() {
 	providersService := providers.NewService(logger.L, queries)
 	providersHandler := handlers.NewProvidersHandler(logger.L, providersService, modelsService)
 	settingsService := settings.NewService(logger.L, queries)
+	settingsService.SetEmbeddingModelListener(memoryService)
 	settingsHandler := handlers.NewSettingsHandler(logger.L, settingsService, botService, usersService)
 	modelsHandler := handlers.NewModelsHandler(logger.L, modelsService, settingsService)
 	policyService := policy.NewService(logger.L, botService, settingsService)
@@ -159,6 +178,7 @@ func main() {
 
 	chatResolver = chat.NewResolver(logger.L, modelsService, queries, memoryService, historyService, settingsService, mcpConnectionsService, cfg.AgentGateway.BaseURL(), 120*time.Second)
 	chatResolver.SetSkillLoader(&skillLoaderAdapter{handler: containerdHandler})
+	chatResolver.SetAttachmentStore(containerdHandler)
 	embeddingsHandler := handlers.NewEmbeddingsHandler(logger.L, modelsService, queries)
 	swaggerHandler := handlers.NewSwaggerHandler(logger.L)
 	chatHandler := handlers.NewChatHandler(logger.L, chatResolver, botService, usersService)
@@ -166,16 +186,42 @@ func main() {
 	sessionHub := local.NewSessionHub()
 	channelRegistry.MustRegister(telegram.NewTelegramAdapter(logger.L))
 	channelRegistry.MustRegister(feishu.NewFeishuAdapter(logger.L))
+	channelRegistry.MustRegister(discord.NewDiscordAdapter(logger.L))
+	channelRegistry.MustRegister(slack.NewSlackAdapter(logger.L))
+	channelRegistry.MustRegister(webhook.NewWebhookAdapter(logger.L))
+	channelRegistry.MustRegister(email.NewEmailAdapter(logger.L))
+	channelRegistry.MustRegister(matrix.NewMatrixAdapter(logger.L))
 	channelRegistry.MustRegister(local.NewCLIAdapter(sessionHub))
 	channelRegistry.MustRegister(local.NewWebAdapter(sessionHub))
 	channelService := channel.NewService(queries, channelRegistry)
 	channelRouter := router.NewChannelInboundProcessor(logger.L, channelRegistry, channelService, chatResolver, contactsService, policyService, preauthService, cfg.Auth.JWTSecret, 5*time.Minute)
+	channelRouter.SetCommandServices(router.CommandServices{
+		Bots:     botService,
+		History:  historyService,
+		Settings: settingsService,
+		Models:   modelsService,
+		Memory:   memoryService,
+	})
 	channelManager := channel.NewManager(logger.L, channelRegistry, channelService, channelRouter)
+	channelManager.SetCommands(channelRouter.Commands())
+	channelManager.SetInboundQueue(channelService)
+	if keys := channelRouter.SessionKeys(); keys != nil {
+		channelManager.SetSessionKeys(keys)
+	}
+	channelManager.SetOutboundLog(channelService)
+	channelManager.SetConnectionEventLog(channelService)
+	directoryService := directory.NewService(logger.L, channelRegistry, channelService, directory.NewLocalService(logger.L, contactsService, channelService))
+	channelManager.SetDirectory(directoryService)
 	if mw := channelRouter.IdentityMiddleware(); mw != nil {
 		channelManager.Use(mw)
 	}
+	rateLimiter := router.NewRateLimiter(logger.L, cfg.RateLimit)
+	channelManager.Use(rateLimiter.Middleware())
 	channelManager.Start(ctx)
-	channelHandler := handlers.NewChannelHandler(channelService, channelRegistry)
+	channelHandler := handlers.NewChannelHandler(channelService, channelRegistry, usersService)
+	channelHandler.SetRateLimiter(rateLimiter)
+	channelHandler.SetManager(channelManager)
+	directoryHandler := handlers.NewDirectoryHandler(directoryService, channelRegistry, botService, usersService)
 	usersHandler := handlers.NewUsersHandler(logger.L, usersService, botService, channelService, channelManager, channelRegistry)
 	cliHandler := handlers.NewLocalChannelHandler(local.CLIType, channelManager, channelService, sessionHub, botService, usersService)
 	webHandler := handlers.NewLocalChannelHandler(local.WebType, channelManager, channelService, sessionHub, botService, usersService)
@@ -188,7 +234,7 @@ func main() {
 	scheduleHandler := handlers.NewScheduleHandler(logger.L, scheduleService, botService, usersService)
 	subagentService := subagent.NewService(logger.L, queries)
 	subagentHandler := handlers.NewSubagentHandler(logger.L, subagentService, botService, usersService)
-	srv := server.NewServer(logger.L, addr, cfg.Auth.JWTSecret, pingHandler, authHandler, memoryHandler, embeddingsHandler, chatHandler, swaggerHandler, providersHandler, modelsHandler, settingsHandler, historyHandler, contactsHandler, preauthHandler, scheduleHandler, subagentHandler, containerdHandler, channelHandler, usersHandler, mcpHandler, cliHandler, webHandler)
+	srv := server.NewServer(logger.L, addr, cfg.Auth.JWTSecret, pingHandler, authHandler, memoryHandler, embeddingsHandler, chatHandler, swaggerHandler, providersHandler, modelsHandler, settingsHandler, historyHandler, contactsHandler, preauthHandler, scheduleHandler, subagentHandler, containerdHandler, channelHandler, directoryHandler, usersHandler, mcpHandler, cliHandler, webHandler)
 
 	if err := srv.Start(); err != nil {
 		logger.Error("server failed", slog.Any("error", err))
@@ -211,6 +257,27 @@ func buildTextEmbedder(resolver *embeddings.Resolver, textModel models.GetRespon
 	}
 }
 
+func buildMemoryStore(log *slog.Logger, cfg config.Config, pool *pgxpool.Pool, vectors map[string]int, hasModels bool, textDims int) memory.VectorStore {
+	switch strings.ToLower(strings.TrimSpace(cfg.Memory.Store)) {
+	case "", config.MemoryStoreQdrant:
+		return buildQdrantStore(log, cfg.Qdrant, vectors, hasModels, textDims)
+	case config.MemoryStorePgvector:
+		if !hasModels {
+			vectors = nil
+		}
+		store, err := memory.NewPgvectorStore(log, pool, vectors, "sparse_hash")
+		if err != nil {
+			log.Error("pgvector init", slog.Any("error", err))
+			os.Exit(1)
+		}
+		return store
+	default:
+		log.Error("unknown memory store", slog.String("store", cfg.Memory.Store))
+		os.Exit(1)
+		return nil
+	}
+}
+
 func buildQdrantStore(log *slog.Logger, cfg config.QdrantConfig, vectors map[string]int, hasModels bool, textDims int) *memory.QdrantStore {
 	timeout := time.Duration(cfg.TimeoutSeconds) * time.Second
 	if hasModels && len(vectors) > 0 {
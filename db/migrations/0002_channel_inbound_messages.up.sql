@@ -0,0 +1,21 @@
+-- Durable queue of inbound channel messages, drained in order per session.
+CREATE TABLE IF NOT EXISTS channel_inbound_messages (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  bot_id UUID NOT NULL REFERENCES bots(id) ON DELETE CASCADE,
+  channel_config_id TEXT NOT NULL,
+  channel_type TEXT NOT NULL,
+  session_key TEXT NOT NULL,
+  payload JSONB NOT NULL,
+  status TEXT NOT NULL DEFAULT 'pending',
+  attempts INTEGER NOT NULL DEFAULT 0,
+  last_error TEXT,
+  available_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  locked_at TIMESTAMPTZ,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  CONSTRAINT channel_inbound_messages_status_check CHECK (status IN ('pending', 'processing', 'dead'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_channel_inbound_messages_session ON channel_inbound_messages(session_key, created_at);
+CREATE INDEX IF NOT EXISTS idx_channel_inbound_messages_status ON channel_inbound_messages(status, available_at);
+CREATE INDEX IF NOT EXISTS idx_channel_inbound_messages_bot_id ON channel_inbound_messages(bot_id);
@@ -0,0 +1,2 @@
+ALTER TABLE bot_preauth_keys DROP COLUMN IF EXISTS contact_id;
+ALTER TABLE bot_settings DROP COLUMN IF EXISTS unified_session;
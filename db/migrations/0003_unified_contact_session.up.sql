@@ -0,0 +1,4 @@
+-- Contacts linked across channels with one-time codes share one chat session when the bot
+-- enables unified_session.
+ALTER TABLE bot_settings ADD COLUMN IF NOT EXISTS unified_session BOOLEAN NOT NULL DEFAULT false;
+ALTER TABLE bot_preauth_keys ADD COLUMN IF NOT EXISTS contact_id UUID REFERENCES contacts(id) ON DELETE CASCADE;
@@ -0,0 +1,20 @@
+-- Delivery log of outbound channel messages with the platform message IDs, for edit and unsend.
+CREATE TABLE IF NOT EXISTS channel_outbound_messages (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  bot_id UUID NOT NULL REFERENCES bots(id) ON DELETE CASCADE,
+  channel_config_id TEXT NOT NULL,
+  channel_type TEXT NOT NULL,
+  session_id TEXT NOT NULL DEFAULT '',
+  target TEXT NOT NULL,
+  platform_message_ids TEXT[] NOT NULL DEFAULT '{}',
+  payload JSONB NOT NULL,
+  status TEXT NOT NULL,
+  attempts INTEGER NOT NULL DEFAULT 0,
+  last_error TEXT,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  CONSTRAINT channel_outbound_messages_status_check CHECK (status IN ('sent', 'failed', 'edited', 'deleted'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_channel_outbound_messages_bot_created ON channel_outbound_messages(bot_id, channel_type, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_channel_outbound_messages_session ON channel_outbound_messages(session_id);
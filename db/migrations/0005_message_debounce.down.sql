@@ -0,0 +1,2 @@
+ALTER TABLE bot_settings DROP COLUMN IF EXISTS interrupt_on_new_message;
+ALTER TABLE bot_settings DROP COLUMN IF EXISTS message_debounce_ms;
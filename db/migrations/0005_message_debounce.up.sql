@@ -0,0 +1,3 @@
+-- Per-bot coalescing of message bursts and interruption of running turns.
+ALTER TABLE bot_settings ADD COLUMN IF NOT EXISTS message_debounce_ms INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE bot_settings ADD COLUMN IF NOT EXISTS interrupt_on_new_message BOOLEAN NOT NULL DEFAULT false;
@@ -0,0 +1,12 @@
+-- Connection health events of channel configs: connected, failed, dropped and stopped.
+CREATE TABLE IF NOT EXISTS channel_connection_events (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  bot_id UUID NOT NULL REFERENCES bots(id) ON DELETE CASCADE,
+  channel_config_id TEXT NOT NULL,
+  channel_type TEXT NOT NULL,
+  event_type TEXT NOT NULL,
+  message TEXT NOT NULL DEFAULT '',
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_channel_connection_events_config_created ON channel_connection_events(channel_config_id, created_at DESC);
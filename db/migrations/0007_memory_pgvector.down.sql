@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS memory_vectors;
+DROP TABLE IF EXISTS memory_points;
@@ -0,0 +1,30 @@
+-- Memory vector store of the pgvector backend ([memory] store = "pgvector"). Skipped when the
+-- vector extension (0.7 or later, for sparsevec) is not available on the server.
+DO $$
+BEGIN
+  IF EXISTS (SELECT 1 FROM pg_available_extensions WHERE name = 'vector') THEN
+    CREATE EXTENSION IF NOT EXISTS vector;
+    EXECUTE $sql$
+      CREATE TABLE IF NOT EXISTS memory_points (
+        id UUID PRIMARY KEY,
+        payload JSONB NOT NULL DEFAULT '{}'::jsonb,
+        sparse sparsevec,
+        created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+        updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+      )
+    $sql$;
+    EXECUTE $sql$ CREATE INDEX IF NOT EXISTS idx_memory_points_bot_id ON memory_points ((payload->>'botId')) $sql$;
+    EXECUTE $sql$ CREATE INDEX IF NOT EXISTS idx_memory_points_session_id ON memory_points ((payload->>'sessionId')) $sql$;
+    EXECUTE $sql$ CREATE INDEX IF NOT EXISTS idx_memory_points_run_id ON memory_points ((payload->>'runId')) $sql$;
+    -- Dimensions vary per embedding model, so the column is unconstrained and searched exactly.
+    EXECUTE $sql$
+      CREATE TABLE IF NOT EXISTS memory_vectors (
+        point_id UUID NOT NULL REFERENCES memory_points(id) ON DELETE CASCADE,
+        name TEXT NOT NULL DEFAULT '',
+        embedding vector NOT NULL,
+        PRIMARY KEY (point_id, name)
+      )
+    $sql$;
+  END IF;
+END
+$$;
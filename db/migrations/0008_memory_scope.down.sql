@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_memory_points_contact_id;
+ALTER TABLE bot_settings DROP COLUMN IF EXISTS memory_scope;
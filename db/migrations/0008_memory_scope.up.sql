@@ -0,0 +1,11 @@
+-- Whether memories learned from a contact are private to them, shared with the conversation
+-- or shared across all conversations of the bot.
+ALTER TABLE bot_settings ADD COLUMN IF NOT EXISTS memory_scope TEXT NOT NULL DEFAULT 'session';
+
+DO $$
+BEGIN
+  IF to_regclass('memory_points') IS NOT NULL THEN
+    CREATE INDEX IF NOT EXISTS idx_memory_points_contact_id ON memory_points ((payload->>'contactId'));
+  END IF;
+END
+$$;
@@ -0,0 +1,18 @@
+-- Every change made to a memory, kept to audit and restore earlier versions. The payload is the
+-- memory after the change, or before it for deletions.
+CREATE TABLE IF NOT EXISTS memory_history (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  memory_id TEXT NOT NULL,
+  bot_id TEXT NOT NULL DEFAULT '',
+  event TEXT NOT NULL,
+  old_memory TEXT NOT NULL DEFAULT '',
+  new_memory TEXT NOT NULL DEFAULT '',
+  payload JSONB NOT NULL DEFAULT '{}'::jsonb,
+  session_id TEXT NOT NULL DEFAULT '',
+  history_id UUID REFERENCES history(id) ON DELETE SET NULL,
+  model TEXT NOT NULL DEFAULT '',
+  restored_from UUID REFERENCES memory_history(id) ON DELETE SET NULL,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_memory_history_memory_created ON memory_history(memory_id, created_at DESC);
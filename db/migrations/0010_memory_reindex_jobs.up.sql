@@ -0,0 +1,16 @@
+-- Re-embedding of the memories of a bot with a new embedding model. The model of the latest
+-- completed job is the one the bot's memories are searched with.
+CREATE TABLE IF NOT EXISTS memory_reindex_jobs (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  bot_id TEXT NOT NULL,
+  model_id TEXT NOT NULL,
+  status TEXT NOT NULL DEFAULT 'running',
+  total INTEGER NOT NULL DEFAULT 0,
+  processed INTEGER NOT NULL DEFAULT 0,
+  error TEXT NOT NULL DEFAULT '',
+  started_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  finished_at TIMESTAMPTZ,
+  CONSTRAINT memory_reindex_jobs_status_check CHECK (status IN ('running', 'completed', 'failed', 'canceled'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_memory_reindex_jobs_bot_started ON memory_reindex_jobs(bot_id, started_at DESC);
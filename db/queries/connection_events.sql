@@ -0,0 +1,15 @@
+-- name: CreateConnectionEvent :exec
+INSERT INTO channel_connection_events (bot_id, channel_config_id, channel_type, event_type, message)
+VALUES (
+  sqlc.arg(bot_id),
+  sqlc.arg(channel_config_id),
+  sqlc.arg(channel_type),
+  sqlc.arg(event_type),
+  sqlc.arg(message)
+);
+
+-- name: ListConnectionEvents :many
+SELECT * FROM channel_connection_events
+WHERE channel_config_id = sqlc.arg(channel_config_id)
+ORDER BY created_at DESC
+LIMIT sqlc.arg(max_items);
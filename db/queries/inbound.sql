@@ -0,0 +1,92 @@
+-- name: EnqueueInboundMessage :one
+INSERT INTO channel_inbound_messages (bot_id, channel_config_id, channel_type, session_key, payload)
+VALUES (
+  sqlc.arg(bot_id),
+  sqlc.arg(channel_config_id),
+  sqlc.arg(channel_type),
+  sqlc.arg(session_key),
+  sqlc.arg(payload)
+)
+RETURNING *;
+
+-- name: ClaimInboundMessages :many
+-- Claims the oldest unfinished message of each session, so a session never has more than
+-- one message in flight and messages are processed in arrival order.
+WITH heads AS (
+  SELECT DISTINCT ON (session_key) id, status, available_at
+  FROM channel_inbound_messages
+  WHERE status IN ('pending', 'processing')
+  ORDER BY session_key, created_at, id
+), claimable AS (
+  SELECT m.id
+  FROM channel_inbound_messages m
+  JOIN heads h ON h.id = m.id
+  WHERE h.status = 'pending' AND h.available_at <= now()
+  ORDER BY m.created_at
+  LIMIT sqlc.arg(max_items)
+  FOR UPDATE OF m SKIP LOCKED
+)
+UPDATE channel_inbound_messages
+SET status = 'processing',
+    attempts = channel_inbound_messages.attempts + 1,
+    locked_at = now(),
+    updated_at = now()
+FROM claimable
+WHERE channel_inbound_messages.id = claimable.id
+  AND channel_inbound_messages.status = 'pending'
+RETURNING channel_inbound_messages.*;
+
+-- name: ListSessionPendingInbound :many
+-- Lists the messages waiting behind the one in flight of a session, in arrival order.
+SELECT * FROM channel_inbound_messages
+WHERE session_key = sqlc.arg(session_key) AND status = 'pending'
+ORDER BY created_at, id
+LIMIT sqlc.arg(max_items);
+
+-- name: DeleteInboundMessage :exec
+DELETE FROM channel_inbound_messages WHERE id = sqlc.arg(id);
+
+-- name: RetryInboundMessage :exec
+UPDATE channel_inbound_messages
+SET status = 'pending',
+    last_error = sqlc.arg(last_error),
+    available_at = sqlc.arg(available_at),
+    locked_at = NULL,
+    updated_at = now()
+WHERE id = sqlc.arg(id);
+
+-- name: DeadLetterInboundMessage :exec
+UPDATE channel_inbound_messages
+SET status = 'dead',
+    last_error = sqlc.arg(last_error),
+    locked_at = NULL,
+    updated_at = now()
+WHERE id = sqlc.arg(id);
+
+-- name: ReleaseStaleInboundMessages :execrows
+UPDATE channel_inbound_messages
+SET status = 'pending',
+    locked_at = NULL,
+    updated_at = now()
+WHERE status = 'processing' AND locked_at < sqlc.arg(locked_before);
+
+-- name: GetInboundMessage :one
+SELECT * FROM channel_inbound_messages WHERE id = sqlc.arg(id);
+
+-- name: ListInboundMessages :many
+SELECT * FROM channel_inbound_messages
+WHERE (sqlc.arg(status)::text = '' OR status = sqlc.arg(status)::text)
+  AND (sqlc.narg(bot_id)::uuid IS NULL OR bot_id = sqlc.narg(bot_id)::uuid)
+ORDER BY created_at ASC
+LIMIT sqlc.arg(max_items);
+
+-- name: ReplayInboundMessage :one
+UPDATE channel_inbound_messages
+SET status = 'pending',
+    attempts = 0,
+    last_error = NULL,
+    available_at = now(),
+    locked_at = NULL,
+    updated_at = now()
+WHERE id = sqlc.arg(id) AND status IN ('pending', 'dead')
+RETURNING *;
@@ -0,0 +1,25 @@
+-- name: CreateMemoryHistory :one
+INSERT INTO memory_history (memory_id, bot_id, event, old_memory, new_memory, payload, session_id, history_id, model, restored_from)
+VALUES (
+  sqlc.arg(memory_id),
+  sqlc.arg(bot_id),
+  sqlc.arg(event),
+  sqlc.arg(old_memory),
+  sqlc.arg(new_memory),
+  sqlc.arg(payload),
+  sqlc.arg(session_id),
+  sqlc.narg(history_id),
+  sqlc.arg(model),
+  sqlc.narg(restored_from)
+)
+RETURNING *;
+
+-- name: ListMemoryHistory :many
+SELECT * FROM memory_history
+WHERE memory_id = sqlc.arg(memory_id)
+ORDER BY created_at DESC
+LIMIT sqlc.arg(max_items);
+
+-- name: GetMemoryHistory :one
+SELECT * FROM memory_history
+WHERE id = sqlc.arg(id);
@@ -0,0 +1,37 @@
+-- name: CreateMemoryReindexJob :one
+INSERT INTO memory_reindex_jobs (bot_id, model_id)
+VALUES (sqlc.arg(bot_id), sqlc.arg(model_id))
+RETURNING *;
+
+-- name: UpdateMemoryReindexProgress :exec
+UPDATE memory_reindex_jobs
+SET total = sqlc.arg(total),
+    processed = sqlc.arg(processed)
+WHERE id = sqlc.arg(id);
+
+-- name: FinishMemoryReindexJob :one
+UPDATE memory_reindex_jobs
+SET status = sqlc.arg(status),
+    total = sqlc.arg(total),
+    processed = sqlc.arg(processed),
+    error = sqlc.arg(error),
+    finished_at = now()
+WHERE id = sqlc.arg(id)
+RETURNING *;
+
+-- name: GetLatestMemoryReindexJob :one
+SELECT * FROM memory_reindex_jobs
+WHERE bot_id = sqlc.arg(bot_id)
+ORDER BY started_at DESC
+LIMIT 1;
+
+-- name: GetMemoryEmbeddingModel :one
+SELECT model_id FROM memory_reindex_jobs
+WHERE bot_id = sqlc.arg(bot_id) AND status = 'completed'
+ORDER BY finished_at DESC
+LIMIT 1;
+
+-- name: ListRunningMemoryReindexJobs :many
+SELECT * FROM memory_reindex_jobs
+WHERE status = 'running'
+ORDER BY started_at ASC;
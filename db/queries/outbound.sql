@@ -0,0 +1,36 @@
+-- name: CreateOutboundMessage :one
+INSERT INTO channel_outbound_messages (bot_id, channel_config_id, channel_type, session_id, target, platform_message_ids, payload, status, attempts, last_error)
+VALUES (
+  sqlc.arg(bot_id),
+  sqlc.arg(channel_config_id),
+  sqlc.arg(channel_type),
+  sqlc.arg(session_id),
+  sqlc.arg(target),
+  sqlc.arg(platform_message_ids),
+  sqlc.arg(payload),
+  sqlc.arg(status),
+  sqlc.arg(attempts),
+  sqlc.arg(last_error)
+)
+RETURNING *;
+
+-- name: GetOutboundMessage :one
+SELECT * FROM channel_outbound_messages WHERE id = sqlc.arg(id);
+
+-- name: ListOutboundMessages :many
+SELECT * FROM channel_outbound_messages
+WHERE bot_id = sqlc.arg(bot_id)
+  AND channel_type = sqlc.arg(channel_type)
+  AND (sqlc.arg(session_id)::text = '' OR session_id = sqlc.arg(session_id)::text)
+  AND (sqlc.arg(status)::text = '' OR status = sqlc.arg(status)::text)
+ORDER BY created_at DESC
+LIMIT sqlc.arg(max_items);
+
+-- name: UpdateOutboundMessageStatus :one
+UPDATE channel_outbound_messages
+SET status = sqlc.arg(status),
+    payload = COALESCE(sqlc.narg(payload), payload),
+    last_error = sqlc.arg(last_error),
+    updated_at = now()
+WHERE id = sqlc.arg(id)
+RETURNING *;
@@ -1,10 +1,10 @@
 -- name: CreateBotPreauthKey :one
-INSERT INTO bot_preauth_keys (bot_id, token, issued_by_user_id, expires_at)
-VALUES ($1, $2, $3, $4)
-RETURNING id, bot_id, token, issued_by_user_id, expires_at, used_at, created_at;
+INSERT INTO bot_preauth_keys (bot_id, token, issued_by_user_id, contact_id, expires_at)
+VALUES ($1, $2, $3, $4, $5)
+RETURNING id, bot_id, token, issued_by_user_id, expires_at, used_at, created_at, contact_id;
 
 -- name: GetBotPreauthKey :one
-SELECT id, bot_id, token, issued_by_user_id, expires_at, used_at, created_at
+SELECT id, bot_id, token, issued_by_user_id, expires_at, used_at, created_at, contact_id
 FROM bot_preauth_keys
 WHERE token = $1
 LIMIT 1;
@@ -12,5 +12,5 @@ LIMIT 1;
 -- name: MarkBotPreauthKeyUsed :one
 UPDATE bot_preauth_keys
 SET used_at = now()
-WHERE id = $1
-RETURNING id, bot_id, token, issued_by_user_id, expires_at, used_at, created_at;
+WHERE id = $1 AND used_at IS NULL
+RETURNING id, bot_id, token, issued_by_user_id, expires_at, used_at, created_at, contact_id;
@@ -15,7 +15,7 @@ ON CONFLICT (user_id) DO UPDATE SET
 RETURNING user_id, chat_model_id, memory_model_id, embedding_model_id, max_context_load_time, language;
 
 -- name: GetSettingsByBotID :one
-SELECT bot_id, max_context_load_time, language, allow_guest
+SELECT bot_id, max_context_load_time, language, allow_guest, unified_session, message_debounce_ms, interrupt_on_new_message, memory_scope
 FROM bot_settings
 WHERE bot_id = $1;
 
@@ -32,13 +32,17 @@ LEFT JOIN models AS embedding_models ON embedding_models.id = bot_model_configs.
 WHERE bot_model_configs.bot_id = $1;
 
 -- name: UpsertBotSettings :one
-INSERT INTO bot_settings (bot_id, max_context_load_time, language, allow_guest)
-VALUES ($1, $2, $3, $4)
+INSERT INTO bot_settings (bot_id, max_context_load_time, language, allow_guest, unified_session, message_debounce_ms, interrupt_on_new_message, memory_scope)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
 ON CONFLICT (bot_id) DO UPDATE SET
   max_context_load_time = EXCLUDED.max_context_load_time,
   language = EXCLUDED.language,
-  allow_guest = EXCLUDED.allow_guest
-RETURNING bot_id, max_context_load_time, language, allow_guest;
+  allow_guest = EXCLUDED.allow_guest,
+  unified_session = EXCLUDED.unified_session,
+  message_debounce_ms = EXCLUDED.message_debounce_ms,
+  interrupt_on_new_message = EXCLUDED.interrupt_on_new_message,
+  memory_scope = EXCLUDED.memory_scope
+RETURNING bot_id, max_context_load_time, language, allow_guest, unified_session, message_debounce_ms, interrupt_on_new_message, memory_scope;
 
 -- name: UpsertBotModelConfig :one
 INSERT INTO bot_model_configs (bot_id, chat_model_id, memory_model_id, embedding_model_id)
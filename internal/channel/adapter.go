@@ -3,11 +3,26 @@ package channel
 import (
 	"context"
 	"errors"
+	"io"
+	"net/http"
+	"sync"
 	"sync/atomic"
 )
 
-// ErrStopNotSupported is returned when a connection does not support graceful shutdown.
-var ErrStopNotSupported = errors.New("channel connection stop not supported")
+var (
+	// ErrStopNotSupported is returned when a connection does not support graceful shutdown.
+	ErrStopNotSupported = errors.New("channel connection stop not supported")
+	// ErrWebhookNotFound is returned when no active webhook connection matches a request.
+	ErrWebhookNotFound = errors.New("channel webhook not found")
+	// ErrWebhookUnauthorized is returned when a webhook request fails verification.
+	ErrWebhookUnauthorized = errors.New("channel webhook unauthorized")
+	// ErrEditNotSupported is returned when an adapter cannot edit messages it has sent.
+	ErrEditNotSupported = errors.New("channel message edit not supported")
+	// ErrUnsendNotSupported is returned when an adapter cannot delete messages it has sent.
+	ErrUnsendNotSupported = errors.New("channel message unsend not supported")
+	// ErrOutboundNotFound is returned when an outbound log entry does not exist.
+	ErrOutboundNotFound = errors.New("outbound message not found")
+)
 
 // InboundHandler is a callback invoked when a message arrives from a channel.
 type InboundHandler func(ctx context.Context, cfg ChannelConfig, msg InboundMessage) error
@@ -17,6 +32,21 @@ type ReplySender interface {
 	Send(ctx context.Context, msg OutboundMessage) error
 }
 
+// StreamReplySender is a ReplySender that can also post a draft reply and edit it in place.
+// Both methods return ErrEditNotSupported when the adapter is not a MessageEditor.
+type StreamReplySender interface {
+	ReplySender
+	SendEditable(ctx context.Context, msg OutboundMessage) (string, error)
+	Edit(ctx context.Context, target, messageID string, msg Message) error
+}
+
+// PresenceReplySender is a ReplySender that can show a presence indicator while a reply is
+// prepared. The returned stop function clears it; it is a no-op for adapters without presence.
+type PresenceReplySender interface {
+	ReplySender
+	StartPresence(ctx context.Context, msg InboundMessage) (stop func())
+}
+
 // Adapter is the base interface every channel adapter must implement.
 type Adapter interface {
 	Type() ChannelType
@@ -54,9 +84,54 @@ type BindingMatcher interface {
 	BuildUserConfig(identity Identity) map[string]any
 }
 
+// SendResult reports what a Sender delivered. MessageIDs holds the platform IDs of the
+// messages posted, in order; adapters whose platform does not return IDs leave it empty.
+type SendResult struct {
+	MessageIDs []string `json:"message_ids,omitempty"`
+}
+
 // Sender is an adapter capable of sending outbound messages.
 type Sender interface {
-	Send(ctx context.Context, cfg ChannelConfig, msg OutboundMessage) error
+	Send(ctx context.Context, cfg ChannelConfig, msg OutboundMessage) (SendResult, error)
+}
+
+// MessageEditor is an adapter that can post a message, report its platform message ID,
+// and later replace that message's content. It backs streaming replies.
+type MessageEditor interface {
+	SendEditable(ctx context.Context, cfg ChannelConfig, msg OutboundMessage) (string, error)
+	Edit(ctx context.Context, cfg ChannelConfig, target, messageID string, msg Message) error
+}
+
+// MessageUnsender is an adapter that can delete a message it has sent, identified by the
+// platform message ID reported in a SendResult.
+type MessageUnsender interface {
+	Unsend(ctx context.Context, cfg ChannelConfig, target, messageID string) error
+}
+
+// PresenceSender is an adapter that can show the user a reply is being prepared, such as a
+// typing indicator or an acknowledgement reaction on the inbound message. SendPresence may
+// return an ID that ClearPresence needs to remove the indicator again.
+type PresenceSender interface {
+	SendPresence(ctx context.Context, cfg ChannelConfig, msg InboundMessage) (string, error)
+	ClearPresence(ctx context.Context, cfg ChannelConfig, msg InboundMessage, presenceID string) error
+}
+
+// AttachmentDownloader is an adapter that fetches the content of inbound attachments itself,
+// for platforms whose attachment URLs are resource keys or require credentials.
+type AttachmentDownloader interface {
+	DownloadAttachment(ctx context.Context, cfg ChannelConfig, att Attachment) (io.ReadCloser, error)
+}
+
+// Command is a bot command advertised in a platform's command menu.
+type Command struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+// CommandRegistrar is an adapter that can publish the bot's commands to the platform's
+// command menu. It is called once a connection has been established.
+type CommandRegistrar interface {
+	RegisterCommands(ctx context.Context, cfg ChannelConfig, commands []Command) error
 }
 
 // Receiver is an adapter capable of establishing a long-lived connection to receive messages.
@@ -64,6 +139,12 @@ type Receiver interface {
 	Connect(ctx context.Context, cfg ChannelConfig, handler InboundHandler) (Connection, error)
 }
 
+// WebhookReceiver is an adapter that accepts inbound events pushed to the server over HTTP.
+// Requests are routed to the active connection identified by configID.
+type WebhookReceiver interface {
+	HandleWebhook(ctx context.Context, configID string, req *http.Request) error
+}
+
 // Connection represents an active, long-lived link to a channel platform.
 type Connection interface {
 	ConfigID() string
@@ -73,6 +154,12 @@ type Connection interface {
 	Running() bool
 }
 
+// ConnectionErrorReporter is a Connection that can tell why it stopped running on its own,
+// such as an invalid token or a dropped websocket.
+type ConnectionErrorReporter interface {
+	Err() error
+}
+
 // BaseConnection is a default Connection implementation backed by a stop function.
 type BaseConnection struct {
 	configID    string
@@ -80,6 +167,9 @@ type BaseConnection struct {
 	channelType ChannelType
 	stop        func(ctx context.Context) error
 	running     atomic.Bool
+
+	mu  sync.Mutex
+	err error
 }
 
 // NewConnection creates a BaseConnection for the given config and stop function.
@@ -122,3 +212,18 @@ func (c *BaseConnection) Stop(ctx context.Context) error {
 func (c *BaseConnection) Running() bool {
 	return c.running.Load()
 }
+
+// Fail marks the connection as no longer running because of err, so the manager reconnects it.
+func (c *BaseConnection) Fail(err error) {
+	c.mu.Lock()
+	c.err = err
+	c.mu.Unlock()
+	c.running.Store(false)
+}
+
+// Err returns the error the connection failed with, if any.
+func (c *BaseConnection) Err() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.err
+}
@@ -0,0 +1,295 @@
+package discord
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const defaultAPIBaseURL = "https://discord.com/api/v10"
+
+// restClient is a minimal Discord REST API client scoped to one bot token.
+type restClient struct {
+	baseURL    string
+	token      string
+	httpClient *http.Client
+}
+
+func newRESTClient(baseURL, token string, httpClient *http.Client) *restClient {
+	if strings.TrimSpace(baseURL) == "" {
+		baseURL = defaultAPIBaseURL
+	}
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: 30 * time.Second}
+	}
+	return &restClient{
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		token:      token,
+		httpClient: httpClient,
+	}
+}
+
+type apiError struct {
+	Status  int
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *apiError) Error() string {
+	if e.Message == "" {
+		return fmt.Sprintf("discord api error: status %d", e.Status)
+	}
+	return fmt.Sprintf("discord api error: %s (code: %d, status: %d)", e.Message, e.Code, e.Status)
+}
+
+type apiUser struct {
+	ID         string `json:"id"`
+	Username   string `json:"username"`
+	GlobalName string `json:"global_name"`
+	Avatar     string `json:"avatar"`
+	Bot        bool   `json:"bot"`
+}
+
+func (u apiUser) displayName() string {
+	if strings.TrimSpace(u.GlobalName) != "" {
+		return strings.TrimSpace(u.GlobalName)
+	}
+	return strings.TrimSpace(u.Username)
+}
+
+type apiAttachment struct {
+	ID          string `json:"id"`
+	Filename    string `json:"filename"`
+	Size        int64  `json:"size"`
+	URL         string `json:"url"`
+	ContentType string `json:"content_type"`
+	Width       int    `json:"width"`
+	Height      int    `json:"height"`
+}
+
+type apiMessageReference struct {
+	MessageID string `json:"message_id,omitempty"`
+	ChannelID string `json:"channel_id,omitempty"`
+	GuildID   string `json:"guild_id,omitempty"`
+}
+
+type apiMessage struct {
+	ID               string               `json:"id"`
+	ChannelID        string               `json:"channel_id"`
+	GuildID          string               `json:"guild_id"`
+	Author           apiUser              `json:"author"`
+	Content          string               `json:"content"`
+	Timestamp        string               `json:"timestamp"`
+	Attachments      []apiAttachment      `json:"attachments"`
+	Mentions         []apiUser            `json:"mentions"`
+	MessageReference *apiMessageReference `json:"message_reference"`
+	// ReferencedMessage is the message replied to; Discord only sets it for replies.
+	ReferencedMessage *apiMessage `json:"referenced_message"`
+}
+
+type apiChannel struct {
+	ID         string    `json:"id"`
+	Type       int       `json:"type"`
+	GuildID    string    `json:"guild_id"`
+	Name       string    `json:"name"`
+	ParentID   string    `json:"parent_id"`
+	Topic      string    `json:"topic"`
+	Recipients []apiUser `json:"recipients"`
+}
+
+type apiGuild struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+	Icon string `json:"icon"`
+}
+
+type apiGuildMember struct {
+	User apiUser `json:"user"`
+	Nick string  `json:"nick"`
+}
+
+type apiAllowedMentions struct {
+	Parse       []string `json:"parse"`
+	RepliedUser bool     `json:"replied_user"`
+}
+
+type apiEmbedImage struct {
+	URL string `json:"url"`
+}
+
+type apiEmbed struct {
+	Image *apiEmbedImage `json:"image,omitempty"`
+}
+
+type apiCreateMessage struct {
+	Content          string               `json:"content,omitempty"`
+	Embeds           []apiEmbed           `json:"embeds,omitempty"`
+	MessageReference *apiMessageReference `json:"message_reference,omitempty"`
+	AllowedMentions  *apiAllowedMentions  `json:"allowed_mentions,omitempty"`
+}
+
+// apiFile is an in-memory file uploaded alongside a message.
+type apiFile struct {
+	Name        string
+	ContentType string
+	Data        []byte
+}
+
+// Discord channel types that can receive text messages.
+const (
+	channelTypeGuildText          = 0
+	channelTypeGuildAnnouncement  = 5
+	channelTypeAnnouncementThread = 10
+	channelTypePublicThread       = 11
+	channelTypePrivateThread      = 12
+)
+
+func (c *restClient) gatewayURL(ctx context.Context) (string, error) {
+	var resp struct {
+		URL string `json:"url"`
+	}
+	if err := c.do(ctx, http.MethodGet, "/gateway/bot", nil, &resp); err != nil {
+		return "", err
+	}
+	if strings.TrimSpace(resp.URL) == "" {
+		return "", fmt.Errorf("discord gateway url is empty")
+	}
+	return resp.URL, nil
+}
+
+func (c *restClient) currentUser(ctx context.Context) (apiUser, error) {
+	var user apiUser
+	err := c.do(ctx, http.MethodGet, "/users/@me", nil, &user)
+	return user, err
+}
+
+func (c *restClient) createDM(ctx context.Context, userID string) (apiChannel, error) {
+	var ch apiChannel
+	err := c.do(ctx, http.MethodPost, "/users/@me/channels", map[string]string{"recipient_id": userID}, &ch)
+	return ch, err
+}
+
+func (c *restClient) getChannel(ctx context.Context, channelID string) (apiChannel, error) {
+	var ch apiChannel
+	err := c.do(ctx, http.MethodGet, "/channels/"+url.PathEscape(channelID), nil, &ch)
+	return ch, err
+}
+
+func (c *restClient) listGuilds(ctx context.Context) ([]apiGuild, error) {
+	var guilds []apiGuild
+	err := c.do(ctx, http.MethodGet, "/users/@me/guilds", nil, &guilds)
+	return guilds, err
+}
+
+func (c *restClient) listGuildChannels(ctx context.Context, guildID string) ([]apiChannel, error) {
+	var channels []apiChannel
+	err := c.do(ctx, http.MethodGet, "/guilds/"+url.PathEscape(guildID)+"/channels", nil, &channels)
+	return channels, err
+}
+
+func (c *restClient) listGuildMembers(ctx context.Context, guildID string, limit int) ([]apiGuildMember, error) {
+	if limit <= 0 || limit > 1000 {
+		limit = 1000
+	}
+	var members []apiGuildMember
+	path := "/guilds/" + url.PathEscape(guildID) + "/members?limit=" + strconv.Itoa(limit)
+	err := c.do(ctx, http.MethodGet, path, nil, &members)
+	return members, err
+}
+
+func (c *restClient) createMessage(ctx context.Context, channelID string, payload apiCreateMessage, files []apiFile) (apiMessage, error) {
+	path := "/channels/" + url.PathEscape(channelID) + "/messages"
+	var msg apiMessage
+	if len(files) == 0 {
+		err := c.do(ctx, http.MethodPost, path, payload, &msg)
+		return msg, err
+	}
+	body, contentType, err := buildMultipartMessage(payload, files)
+	if err != nil {
+		return apiMessage{}, err
+	}
+	err = c.send(ctx, http.MethodPost, path, contentType, body, &msg)
+	return msg, err
+}
+
+func (c *restClient) deleteMessage(ctx context.Context, channelID, messageID string) error {
+	path := "/channels/" + url.PathEscape(channelID) + "/messages/" + url.PathEscape(messageID)
+	return c.do(ctx, http.MethodDelete, path, nil, nil)
+}
+
+func buildMultipartMessage(payload apiCreateMessage, files []apiFile) (*bytes.Buffer, string, error) {
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+	payloadJSON, err := json.Marshal(payload)
+	if err != nil {
+		return nil, "", err
+	}
+	if err := writer.WriteField("payload_json", string(payloadJSON)); err != nil {
+		return nil, "", err
+	}
+	for i, file := range files {
+		part, err := writer.CreateFormFile(fmt.Sprintf("files[%d]", i), file.Name)
+		if err != nil {
+			return nil, "", err
+		}
+		if _, err := part.Write(file.Data); err != nil {
+			return nil, "", err
+		}
+	}
+	if err := writer.Close(); err != nil {
+		return nil, "", err
+	}
+	return body, writer.FormDataContentType(), nil
+}
+
+func (c *restClient) do(ctx context.Context, method, path string, payload any, out any) error {
+	var body io.Reader
+	contentType := ""
+	if payload != nil {
+		data, err := json.Marshal(payload)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(data)
+		contentType = "application/json"
+	}
+	return c.send(ctx, method, path, contentType, body, out)
+}
+
+func (c *restClient) send(ctx context.Context, method, path, contentType string, body io.Reader, out any) error {
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bot "+c.token)
+	req.Header.Set("User-Agent", "DiscordBot (https://github.com/memohai/memoh, 1.0)")
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		apiErr := &apiError{Status: resp.StatusCode}
+		_ = json.Unmarshal(respBody, apiErr)
+		return apiErr
+	}
+	if out == nil || len(respBody) == 0 {
+		return nil
+	}
+	return json.Unmarshal(respBody, out)
+}
@@ -0,0 +1,177 @@
+package discord
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/memohai/memoh/internal/channel"
+)
+
+// Config holds the Discord bot credentials extracted from a channel configuration.
+type Config struct {
+	BotToken string
+}
+
+// UserConfig holds the identifiers used to target a Discord user or channel.
+type UserConfig struct {
+	UserID    string
+	Username  string
+	ChannelID string
+}
+
+func normalizeConfig(raw map[string]any) (map[string]any, error) {
+	cfg, err := parseConfig(raw)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]any{
+		"botToken": cfg.BotToken,
+	}, nil
+}
+
+func normalizeUserConfig(raw map[string]any) (map[string]any, error) {
+	cfg, err := parseUserConfig(raw)
+	if err != nil {
+		return nil, err
+	}
+	result := map[string]any{}
+	if cfg.UserID != "" {
+		result["user_id"] = cfg.UserID
+	}
+	if cfg.Username != "" {
+		result["username"] = cfg.Username
+	}
+	if cfg.ChannelID != "" {
+		result["channel_id"] = cfg.ChannelID
+	}
+	return result, nil
+}
+
+func resolveTarget(raw map[string]any) (string, error) {
+	cfg, err := parseUserConfig(raw)
+	if err != nil {
+		return "", err
+	}
+	if cfg.ChannelID != "" {
+		return "channel:" + cfg.ChannelID, nil
+	}
+	if cfg.UserID != "" {
+		return "user:" + cfg.UserID, nil
+	}
+	return "", fmt.Errorf("discord binding is incomplete")
+}
+
+func matchBinding(raw map[string]any, criteria channel.BindingCriteria) bool {
+	cfg, err := parseUserConfig(raw)
+	if err != nil {
+		return false
+	}
+	if value := strings.TrimSpace(criteria.Attribute("user_id")); value != "" && value == cfg.UserID {
+		return true
+	}
+	if value := strings.TrimSpace(criteria.Attribute("username")); value != "" && strings.EqualFold(value, cfg.Username) {
+		return true
+	}
+	if criteria.ExternalID != "" {
+		if criteria.ExternalID == cfg.UserID || strings.EqualFold(criteria.ExternalID, cfg.Username) {
+			return true
+		}
+	}
+	return false
+}
+
+func buildUserConfig(identity channel.Identity) map[string]any {
+	result := map[string]any{}
+	if value := strings.TrimSpace(identity.Attribute("user_id")); value != "" {
+		result["user_id"] = value
+	}
+	if value := strings.TrimSpace(identity.Attribute("username")); value != "" {
+		result["username"] = value
+	}
+	return result
+}
+
+func parseConfig(raw map[string]any) (Config, error) {
+	token := strings.TrimSpace(channel.ReadString(raw, "botToken", "bot_token"))
+	if token == "" {
+		return Config{}, fmt.Errorf("discord botToken is required")
+	}
+	return Config{BotToken: token}, nil
+}
+
+func parseUserConfig(raw map[string]any) (UserConfig, error) {
+	userID := strings.TrimSpace(channel.ReadString(raw, "userId", "user_id"))
+	username := strings.TrimSpace(channel.ReadString(raw, "username"))
+	channelID := strings.TrimSpace(channel.ReadString(raw, "channelId", "channel_id"))
+	if userID == "" && username == "" && channelID == "" {
+		return UserConfig{}, fmt.Errorf("discord user config requires user_id, username, or channel_id")
+	}
+	return UserConfig{
+		UserID:    userID,
+		Username:  username,
+		ChannelID: channelID,
+	}, nil
+}
+
+// normalizeTarget converts raw targets into "channel:<id>" or "user:<id>".
+// Bare snowflakes are treated as channel IDs since replies always go to a channel.
+func normalizeTarget(raw string) string {
+	value := strings.TrimSpace(raw)
+	if value == "" {
+		return ""
+	}
+	value = strings.TrimPrefix(value, "discord:")
+	switch {
+	case strings.HasPrefix(value, "channel:"):
+		id := strings.TrimSpace(strings.TrimPrefix(value, "channel:"))
+		if !isSnowflake(id) {
+			return ""
+		}
+		return "channel:" + id
+	case strings.HasPrefix(value, "user:"):
+		id := strings.TrimSpace(strings.TrimPrefix(value, "user:"))
+		if !isSnowflake(id) {
+			return ""
+		}
+		return "user:" + id
+	case strings.HasPrefix(value, "<@") && strings.HasSuffix(value, ">"):
+		id := strings.TrimPrefix(strings.TrimSuffix(value, ">"), "<@")
+		id = strings.TrimPrefix(id, "!")
+		if !isSnowflake(id) {
+			return ""
+		}
+		return "user:" + id
+	case strings.HasPrefix(value, "<#") && strings.HasSuffix(value, ">"):
+		id := strings.TrimPrefix(strings.TrimSuffix(value, ">"), "<#")
+		if !isSnowflake(id) {
+			return ""
+		}
+		return "channel:" + id
+	case isSnowflake(value):
+		return "channel:" + value
+	default:
+		return ""
+	}
+}
+
+// parseTarget splits a normalized target into its kind ("channel" or "user") and ID.
+func parseTarget(raw string) (string, string, error) {
+	normalized := normalizeTarget(raw)
+	if normalized == "" {
+		return "", "", fmt.Errorf("discord target must be channel:<id> or user:<id>")
+	}
+	kind, id, _ := strings.Cut(normalized, ":")
+	return kind, id, nil
+}
+
+func isSnowflake(value string) bool {
+	if value == "" {
+		return false
+	}
+	for _, r := range value {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
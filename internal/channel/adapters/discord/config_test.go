@@ -0,0 +1,93 @@
+package discord
+
+import (
+	"testing"
+
+	"github.com/memohai/memoh/internal/channel"
+)
+
+func TestNormalizeConfig(t *testing.T) {
+	t.Parallel()
+
+	got, err := normalizeConfig(map[string]any{
+		"bot_token": "token-123",
+	})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if got["botToken"] != "token-123" {
+		t.Fatalf("unexpected botToken: %#v", got["botToken"])
+	}
+}
+
+func TestNormalizeConfigRequiresToken(t *testing.T) {
+	t.Parallel()
+
+	_, err := normalizeConfig(map[string]any{})
+	if err == nil {
+		t.Fatalf("expected error, got nil")
+	}
+}
+
+func TestNormalizeUserConfigRequiresBinding(t *testing.T) {
+	t.Parallel()
+
+	_, err := normalizeUserConfig(map[string]any{})
+	if err == nil {
+		t.Fatalf("expected error, got nil")
+	}
+}
+
+func TestResolveTarget(t *testing.T) {
+	t.Parallel()
+
+	target, err := resolveTarget(map[string]any{"user_id": "42"})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if target != "user:42" {
+		t.Fatalf("unexpected target: %s", target)
+	}
+	target, err = resolveTarget(map[string]any{"user_id": "42", "channel_id": "7"})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if target != "channel:7" {
+		t.Fatalf("unexpected target: %s", target)
+	}
+}
+
+func TestNormalizeTarget(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]string{
+		"123":              "channel:123",
+		"discord:user:123": "user:123",
+		"<@123>":           "user:123",
+		"<@!123>":          "user:123",
+		"<#456>":           "channel:456",
+		"channel:456":      "channel:456",
+		"channel:general":  "",
+		"@alice":           "",
+	}
+	for raw, want := range cases {
+		if got := normalizeTarget(raw); got != want {
+			t.Fatalf("normalizeTarget(%q) = %q, want %q", raw, got, want)
+		}
+	}
+}
+
+func TestMatchBinding(t *testing.T) {
+	t.Parallel()
+
+	cfg := map[string]any{"user_id": "42", "username": "alice"}
+	if !matchBinding(cfg, channel.BindingCriteria{ExternalID: "42"}) {
+		t.Fatalf("expected external id match")
+	}
+	if !matchBinding(cfg, channel.BindingCriteria{Attributes: map[string]string{"username": "Alice"}}) {
+		t.Fatalf("expected username match")
+	}
+	if matchBinding(cfg, channel.BindingCriteria{ExternalID: "43"}) {
+		t.Fatalf("unexpected match")
+	}
+}
@@ -0,0 +1,7 @@
+// Package discord implements the Discord channel adapter.
+package discord
+
+import "github.com/memohai/memoh/internal/channel"
+
+// Type is the registered ChannelType identifier for Discord.
+const Type channel.ChannelType = "discord"
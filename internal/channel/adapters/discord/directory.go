@@ -0,0 +1,249 @@
+package discord
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/memohai/memoh/internal/channel"
+)
+
+var (
+	errDirectoryNotFound  = errors.New("discord directory entry not found")
+	errDirectoryAmbiguous = errors.New("discord directory entry ambiguous")
+)
+
+// DirectoryAdapter implements channel.ChannelDirectoryAdapter for Discord.
+// It is separate from DiscordAdapter because both interfaces declare ResolveTarget.
+type DirectoryAdapter struct {
+	adapter *DiscordAdapter
+}
+
+// ListPeers lists members of every guild the bot belongs to, filtered by query.
+func (d *DirectoryAdapter) ListPeers(ctx context.Context, cfg channel.ChannelConfig, query channel.DirectoryQuery) ([]channel.DirectoryEntry, error) {
+	rest, err := d.client(cfg)
+	if err != nil {
+		return nil, err
+	}
+	guilds, err := rest.listGuilds(ctx)
+	if err != nil {
+		return nil, err
+	}
+	seen := map[string]struct{}{}
+	results := make([]channel.DirectoryEntry, 0)
+	for _, guild := range guilds {
+		members, err := rest.listGuildMembers(ctx, guild.ID, 0)
+		if err != nil {
+			if d.adapter.logger != nil {
+				d.adapter.logger.Warn("list guild members failed", slog.String("guild_id", guild.ID), slog.Any("error", err))
+			}
+			continue
+		}
+		for _, member := range members {
+			if member.User.Bot {
+				continue
+			}
+			if _, ok := seen[member.User.ID]; ok {
+				continue
+			}
+			entry := memberEntry(member)
+			if !matchesQuery(entry, query.Query) {
+				continue
+			}
+			seen[member.User.ID] = struct{}{}
+			results = append(results, entry)
+			if query.Limit > 0 && len(results) >= query.Limit {
+				return results, nil
+			}
+		}
+	}
+	return results, nil
+}
+
+// ListGroups lists text channels of every guild the bot belongs to, filtered by query.
+func (d *DirectoryAdapter) ListGroups(ctx context.Context, cfg channel.ChannelConfig, query channel.DirectoryQuery) ([]channel.DirectoryEntry, error) {
+	rest, err := d.client(cfg)
+	if err != nil {
+		return nil, err
+	}
+	guilds, err := rest.listGuilds(ctx)
+	if err != nil {
+		return nil, err
+	}
+	results := make([]channel.DirectoryEntry, 0)
+	for _, guild := range guilds {
+		channels, err := rest.listGuildChannels(ctx, guild.ID)
+		if err != nil {
+			if d.adapter.logger != nil {
+				d.adapter.logger.Warn("list guild channels failed", slog.String("guild_id", guild.ID), slog.Any("error", err))
+			}
+			continue
+		}
+		for _, ch := range channels {
+			if !isTextChannel(ch.Type) {
+				continue
+			}
+			entry := channel.DirectoryEntry{
+				Kind:   channel.DirectoryEntryGroup,
+				ID:     "channel:" + ch.ID,
+				Name:   ch.Name,
+				Handle: "#" + ch.Name,
+				Metadata: map[string]any{
+					"guild_id":   guild.ID,
+					"guild_name": guild.Name,
+				},
+			}
+			if !matchesQuery(entry, query.Query) {
+				continue
+			}
+			results = append(results, entry)
+			if query.Limit > 0 && len(results) >= query.Limit {
+				return results, nil
+			}
+		}
+	}
+	return results, nil
+}
+
+// ListGroupMembers lists members of the guild that owns the given channel.
+func (d *DirectoryAdapter) ListGroupMembers(ctx context.Context, cfg channel.ChannelConfig, groupID string, query channel.DirectoryQuery) ([]channel.DirectoryEntry, error) {
+	rest, err := d.client(cfg)
+	if err != nil {
+		return nil, err
+	}
+	kind, id, err := parseTarget(groupID)
+	if err != nil {
+		return nil, err
+	}
+	if kind != "channel" {
+		return nil, fmt.Errorf("discord group id must be a channel")
+	}
+	ch, err := rest.getChannel(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if ch.GuildID == "" {
+		results := make([]channel.DirectoryEntry, 0, len(ch.Recipients))
+		for _, user := range ch.Recipients {
+			entry := memberEntry(apiGuildMember{User: user})
+			if matchesQuery(entry, query.Query) {
+				results = append(results, entry)
+			}
+		}
+		return results, nil
+	}
+	members, err := rest.listGuildMembers(ctx, ch.GuildID, 0)
+	if err != nil {
+		return nil, err
+	}
+	results := make([]channel.DirectoryEntry, 0, len(members))
+	for _, member := range members {
+		entry := memberEntry(member)
+		if !matchesQuery(entry, query.Query) {
+			continue
+		}
+		results = append(results, entry)
+		if query.Limit > 0 && len(results) >= query.Limit {
+			break
+		}
+	}
+	return results, nil
+}
+
+// ResolveTarget resolves an ID, mention, or name to a single directory entry.
+func (d *DirectoryAdapter) ResolveTarget(ctx context.Context, cfg channel.ChannelConfig, input string, kind channel.DirectoryEntryKind) (channel.DirectoryEntry, error) {
+	trimmed := strings.TrimSpace(input)
+	if trimmed == "" {
+		return channel.DirectoryEntry{}, errDirectoryNotFound
+	}
+	if normalized := normalizeTarget(trimmed); normalized != "" {
+		targetKind, id, _ := strings.Cut(normalized, ":")
+		if isSnowflake(trimmed) && kind == channel.DirectoryEntryUser {
+			targetKind = "user"
+		}
+		if targetKind == "user" {
+			return channel.DirectoryEntry{Kind: channel.DirectoryEntryUser, ID: "user:" + id}, nil
+		}
+		return channel.DirectoryEntry{Kind: channel.DirectoryEntryGroup, ID: "channel:" + id}, nil
+	}
+	name := strings.TrimPrefix(strings.TrimPrefix(trimmed, "@"), "#")
+	var (
+		items []channel.DirectoryEntry
+		err   error
+	)
+	if kind == channel.DirectoryEntryGroup {
+		items, err = d.ListGroups(ctx, cfg, channel.DirectoryQuery{Query: name})
+	} else {
+		items, err = d.ListPeers(ctx, cfg, channel.DirectoryQuery{Query: name})
+	}
+	if err != nil {
+		return channel.DirectoryEntry{}, err
+	}
+	return pickSingleMatch(items, name)
+}
+
+func (d *DirectoryAdapter) client(cfg channel.ChannelConfig) (*restClient, error) {
+	discordCfg, err := parseConfig(cfg.Credentials)
+	if err != nil {
+		return nil, err
+	}
+	return d.adapter.client(discordCfg.BotToken), nil
+}
+
+func memberEntry(member apiGuildMember) channel.DirectoryEntry {
+	name := strings.TrimSpace(member.Nick)
+	if name == "" {
+		name = member.User.displayName()
+	}
+	entry := channel.DirectoryEntry{
+		Kind:     channel.DirectoryEntryUser,
+		ID:       "user:" + member.User.ID,
+		Name:     name,
+		Handle:   member.User.Username,
+		Metadata: map[string]any{"user_id": member.User.ID},
+	}
+	if member.User.Avatar != "" {
+		entry.AvatarURL = "https://cdn.discordapp.com/avatars/" + member.User.ID + "/" + member.User.Avatar + ".png"
+	}
+	return entry
+}
+
+func matchesQuery(entry channel.DirectoryEntry, query string) bool {
+	needle := strings.ToLower(strings.TrimSpace(query))
+	if needle == "" {
+		return true
+	}
+	for _, value := range []string{entry.ID, entry.Name, entry.Handle} {
+		if strings.Contains(strings.ToLower(value), needle) {
+			return true
+		}
+	}
+	return false
+}
+
+func pickSingleMatch(items []channel.DirectoryEntry, input string) (channel.DirectoryEntry, error) {
+	if len(items) == 0 {
+		return channel.DirectoryEntry{}, errDirectoryNotFound
+	}
+	if len(items) == 1 {
+		return items[0], nil
+	}
+	lower := strings.ToLower(strings.TrimSpace(input))
+	for _, item := range items {
+		if strings.ToLower(item.Name) == lower || strings.ToLower(item.Handle) == lower {
+			return item, nil
+		}
+	}
+	return channel.DirectoryEntry{}, errDirectoryAmbiguous
+}
+
+func isTextChannel(kind int) bool {
+	switch kind {
+	case channelTypeGuildText, channelTypeGuildAnnouncement, channelTypeAnnouncementThread, channelTypePublicThread, channelTypePrivateThread:
+		return true
+	default:
+		return false
+	}
+}
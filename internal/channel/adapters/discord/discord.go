@@ -0,0 +1,467 @@
+package discord
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/memohai/memoh/internal/channel"
+	"github.com/memohai/memoh/internal/channel/adapters/common"
+)
+
+// maxAttachmentBytes caps the size of a single outbound attachment download.
+const maxAttachmentBytes = 25 << 20
+
+// DiscordAdapter implements the channel.Adapter, channel.Sender, and channel.Receiver interfaces for Discord.
+type DiscordAdapter struct {
+	logger     *slog.Logger
+	apiBaseURL string
+	httpClient *http.Client
+	mu         sync.Mutex
+	dmChannels map[string]string // keyed by bot token + user ID
+}
+
+// NewDiscordAdapter creates a DiscordAdapter with the given logger.
+func NewDiscordAdapter(log *slog.Logger) *DiscordAdapter {
+	if log == nil {
+		log = slog.Default()
+	}
+	return &DiscordAdapter{
+		logger:     log.With(slog.String("adapter", "discord")),
+		apiBaseURL: defaultAPIBaseURL,
+		httpClient: &http.Client{Timeout: 30 * time.Second},
+		dmChannels: make(map[string]string),
+	}
+}
+
+func (a *DiscordAdapter) client(token string) *restClient {
+	return newRESTClient(a.apiBaseURL, token, a.httpClient)
+}
+
+// Type returns the Discord channel type.
+func (a *DiscordAdapter) Type() channel.ChannelType {
+	return Type
+}
+
+// Descriptor returns the Discord channel metadata.
+func (a *DiscordAdapter) Descriptor() channel.Descriptor {
+	return channel.Descriptor{
+		Type:        Type,
+		DisplayName: "Discord",
+		Capabilities: channel.ChannelCapabilities{
+			Text:           true,
+			Markdown:       true,
+			Reply:          true,
+			Attachments:    true,
+			Media:          true,
+			Unsend:         true,
+			BlockStreaming: true,
+		},
+		OutboundPolicy: channel.OutboundPolicy{
+			TextChunkLimit: 2000,
+			ChunkerMode:    channel.ChunkerModeMarkdown,
+		},
+		ConfigSchema: channel.ConfigSchema{
+			Version: 1,
+			Fields: map[string]channel.FieldSchema{
+				"botToken": {
+					Type:     channel.FieldSecret,
+					Required: true,
+					Title:    "Bot Token",
+				},
+			},
+		},
+		UserConfigSchema: channel.ConfigSchema{
+			Version: 1,
+			Fields: map[string]channel.FieldSchema{
+				"user_id":    {Type: channel.FieldString},
+				"username":   {Type: channel.FieldString},
+				"channel_id": {Type: channel.FieldString},
+			},
+		},
+		TargetSpec: channel.TargetSpec{
+			Format: "channel:<id> | user:<id>",
+			Hints: []channel.TargetHint{
+				{Label: "Channel ID", Example: "channel:1234567890123456789"},
+				{Label: "User ID", Example: "user:1234567890123456789"},
+			},
+		},
+	}
+}
+
+// NormalizeConfig validates and normalizes a Discord channel configuration map.
+func (a *DiscordAdapter) NormalizeConfig(raw map[string]any) (map[string]any, error) {
+	return normalizeConfig(raw)
+}
+
+// NormalizeUserConfig validates and normalizes a Discord user-binding configuration map.
+func (a *DiscordAdapter) NormalizeUserConfig(raw map[string]any) (map[string]any, error) {
+	return normalizeUserConfig(raw)
+}
+
+// NormalizeTarget normalizes a Discord delivery target string.
+func (a *DiscordAdapter) NormalizeTarget(raw string) string {
+	return normalizeTarget(raw)
+}
+
+// ResolveTarget derives a delivery target from a Discord user-binding configuration.
+func (a *DiscordAdapter) ResolveTarget(userConfig map[string]any) (string, error) {
+	return resolveTarget(userConfig)
+}
+
+// MatchBinding reports whether a Discord user binding matches the given criteria.
+func (a *DiscordAdapter) MatchBinding(config map[string]any, criteria channel.BindingCriteria) bool {
+	return matchBinding(config, criteria)
+}
+
+// BuildUserConfig constructs a Discord user-binding config from an Identity.
+func (a *DiscordAdapter) BuildUserConfig(identity channel.Identity) map[string]any {
+	return buildUserConfig(identity)
+}
+
+// Connect opens a gateway session and forwards incoming messages to the handler.
+func (a *DiscordAdapter) Connect(ctx context.Context, cfg channel.ChannelConfig, handler channel.InboundHandler) (channel.Connection, error) {
+	if a.logger != nil {
+		a.logger.Info("start", slog.String("config_id", cfg.ID))
+	}
+	discordCfg, err := parseConfig(cfg.Credentials)
+	if err != nil {
+		if a.logger != nil {
+			a.logger.Error("decode config failed", slog.String("config_id", cfg.ID), slog.Any("error", err))
+		}
+		return nil, err
+	}
+	rest := a.client(discordCfg.BotToken)
+	if _, err := rest.gatewayURL(ctx); err != nil {
+		if a.logger != nil {
+			a.logger.Error("fetch gateway failed", slog.String("config_id", cfg.ID), slog.Any("error", err))
+		}
+		return nil, err
+	}
+	connCtx, cancel := context.WithCancel(ctx)
+	var gw *gateway
+	gw = newGateway(rest, discordCfg.BotToken, a.logger.With(slog.String("config_id", cfg.ID)), func(raw apiMessage) {
+		msg, ok := a.buildInboundMessage(cfg, raw, gw.selfID())
+		if !ok {
+			return
+		}
+		if a.logger != nil {
+			a.logger.Info(
+				"inbound received",
+				slog.String("config_id", cfg.ID),
+				slog.String("chat_type", msg.Conversation.Type),
+				slog.String("chat_id", msg.Conversation.ID),
+				slog.String("user_id", msg.Sender.Attribute("user_id")),
+				slog.String("username", msg.Sender.Attribute("username")),
+				slog.String("text", common.SummarizeText(msg.Message.Text)),
+			)
+		}
+		go func() {
+			if err := handler(connCtx, cfg, msg); err != nil && a.logger != nil {
+				a.logger.Error("handle inbound failed", slog.String("config_id", cfg.ID), slog.Any("error", err))
+			}
+		}()
+	})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		gw.run(connCtx)
+	}()
+
+	stop := func(stopCtx context.Context) error {
+		if a.logger != nil {
+			a.logger.Info("stop", slog.String("config_id", cfg.ID))
+		}
+		cancel()
+		gw.close()
+		select {
+		case <-done:
+		case <-stopCtx.Done():
+			return stopCtx.Err()
+		}
+		return nil
+	}
+	return channel.NewConnection(cfg, stop), nil
+}
+
+func (a *DiscordAdapter) buildInboundMessage(cfg channel.ChannelConfig, raw apiMessage, selfID string) (channel.InboundMessage, bool) {
+	if raw.Author.Bot || (selfID != "" && raw.Author.ID == selfID) {
+		return channel.InboundMessage{}, false
+	}
+	text := strings.TrimSpace(raw.Content)
+	attachments := buildDiscordAttachments(raw.Attachments)
+	if text == "" && len(attachments) == 0 {
+		return channel.InboundMessage{}, false
+	}
+	chatType := "p2p"
+	if strings.TrimSpace(raw.GuildID) != "" {
+		chatType = "group"
+	}
+	attrs := map[string]string{}
+	if raw.Author.ID != "" {
+		attrs["user_id"] = raw.Author.ID
+	}
+	if username := strings.TrimSpace(raw.Author.Username); username != "" {
+		attrs["username"] = username
+	}
+	if raw.GuildID != "" {
+		attrs["guild_id"] = raw.GuildID
+	}
+	receivedAt := time.Now().UTC()
+	if parsed, err := time.Parse(time.RFC3339, raw.Timestamp); err == nil {
+		receivedAt = parsed.UTC()
+	}
+	msg := channel.InboundMessage{
+		Channel: Type,
+		Message: channel.Message{
+			ID:          raw.ID,
+			Format:      channel.MessageFormatPlain,
+			Text:        text,
+			Attachments: attachments,
+			Reply:       buildDiscordReplyRef(raw),
+		},
+		BotID:       cfg.BotID,
+		ReplyTarget: "channel:" + raw.ChannelID,
+		Sender: channel.Identity{
+			ExternalID:  raw.Author.ID,
+			DisplayName: raw.Author.displayName(),
+			Attributes:  attrs,
+		},
+		Conversation: channel.Conversation{
+			ID:   raw.ChannelID,
+			Type: chatType,
+		},
+		ReceivedAt: receivedAt,
+		Source:     "discord",
+	}
+	if raw.GuildID != "" {
+		msg.Conversation.Metadata = map[string]any{"guild_id": raw.GuildID}
+	}
+	if selfID != "" {
+		mentioned := false
+		for _, user := range raw.Mentions {
+			if user.ID == selfID {
+				mentioned = true
+				break
+			}
+		}
+		msg.Metadata = map[string]any{
+			channel.MetadataKeyMentioned:  mentioned,
+			channel.MetadataKeyReplyToBot: raw.ReferencedMessage != nil && raw.ReferencedMessage.Author.ID == selfID,
+		}
+	}
+	return msg, true
+}
+
+func buildDiscordReplyRef(raw apiMessage) *channel.ReplyRef {
+	if raw.MessageReference == nil || strings.TrimSpace(raw.MessageReference.MessageID) == "" {
+		return nil
+	}
+	target := strings.TrimSpace(raw.MessageReference.ChannelID)
+	if target == "" {
+		target = raw.ChannelID
+	}
+	return &channel.ReplyRef{
+		MessageID: strings.TrimSpace(raw.MessageReference.MessageID),
+		Target:    "channel:" + target,
+	}
+}
+
+func buildDiscordAttachments(items []apiAttachment) []channel.Attachment {
+	if len(items) == 0 {
+		return nil
+	}
+	attachments := make([]channel.Attachment, 0, len(items))
+	for _, item := range items {
+		att := channel.Attachment{
+			Type:     resolveAttachmentType(item.ContentType),
+			URL:      strings.TrimSpace(item.URL),
+			Name:     strings.TrimSpace(item.Filename),
+			Size:     item.Size,
+			Mime:     strings.TrimSpace(item.ContentType),
+			Width:    item.Width,
+			Height:   item.Height,
+			Metadata: map[string]any{},
+		}
+		if item.ID != "" {
+			att.Metadata["attachment_id"] = item.ID
+		}
+		attachments = append(attachments, att)
+	}
+	return attachments
+}
+
+func resolveAttachmentType(mime string) channel.AttachmentType {
+	mime = strings.ToLower(strings.TrimSpace(mime))
+	switch {
+	case mime == "image/gif":
+		return channel.AttachmentGIF
+	case strings.HasPrefix(mime, "image/"):
+		return channel.AttachmentImage
+	case strings.HasPrefix(mime, "video/"):
+		return channel.AttachmentVideo
+	case strings.HasPrefix(mime, "audio/"):
+		return channel.AttachmentAudio
+	default:
+		return channel.AttachmentFile
+	}
+}
+
+// Send delivers an outbound message to a Discord channel or user DM.
+func (a *DiscordAdapter) Send(ctx context.Context, cfg channel.ChannelConfig, msg channel.OutboundMessage) (channel.SendResult, error) {
+	discordCfg, err := parseConfig(cfg.Credentials)
+	if err != nil {
+		if a.logger != nil {
+			a.logger.Error("decode config failed", slog.String("config_id", cfg.ID), slog.Any("error", err))
+		}
+		return channel.SendResult{}, err
+	}
+	if strings.TrimSpace(msg.Target) == "" {
+		return channel.SendResult{}, fmt.Errorf("discord target is required")
+	}
+	if msg.Message.IsEmpty() {
+		return channel.SendResult{}, fmt.Errorf("message is required")
+	}
+	rest := a.client(discordCfg.BotToken)
+	channelID, err := a.resolveChannelID(ctx, rest, discordCfg.BotToken, msg.Target)
+	if err != nil {
+		return channel.SendResult{}, err
+	}
+	payload := apiCreateMessage{
+		Content:         strings.TrimSpace(msg.Message.PlainText()),
+		AllowedMentions: &apiAllowedMentions{Parse: []string{"users"}},
+	}
+	if msg.Message.Reply != nil && strings.TrimSpace(msg.Message.Reply.MessageID) != "" {
+		payload.MessageReference = &apiMessageReference{MessageID: strings.TrimSpace(msg.Message.Reply.MessageID)}
+	}
+	files := make([]apiFile, 0, len(msg.Message.Attachments))
+	for _, att := range msg.Message.Attachments {
+		file, err := a.downloadAttachment(ctx, att)
+		if err != nil {
+			if a.logger != nil {
+				a.logger.Error("download attachment failed", slog.String("config_id", cfg.ID), slog.Any("error", err))
+			}
+			return channel.SendResult{}, err
+		}
+		files = append(files, file)
+	}
+	created, err := rest.createMessage(ctx, channelID, payload, files)
+	if err != nil {
+		if a.logger != nil {
+			a.logger.Error("send message failed", slog.String("config_id", cfg.ID), slog.Any("error", err))
+		}
+		return channel.SendResult{}, err
+	}
+	return channel.SendResult{MessageIDs: []string{created.ID}}, nil
+}
+
+// Unsend deletes a message the bot has sent.
+func (a *DiscordAdapter) Unsend(ctx context.Context, cfg channel.ChannelConfig, target, messageID string) error {
+	discordCfg, err := parseConfig(cfg.Credentials)
+	if err != nil {
+		return err
+	}
+	if strings.TrimSpace(messageID) == "" {
+		return fmt.Errorf("discord message id is required")
+	}
+	rest := a.client(discordCfg.BotToken)
+	channelID, err := a.resolveChannelID(ctx, rest, discordCfg.BotToken, target)
+	if err != nil {
+		return err
+	}
+	return rest.deleteMessage(ctx, channelID, strings.TrimSpace(messageID))
+}
+
+// resolveChannelID maps a target to a channel ID, opening a DM channel for user targets.
+func (a *DiscordAdapter) resolveChannelID(ctx context.Context, rest *restClient, token, target string) (string, error) {
+	kind, id, err := parseTarget(target)
+	if err != nil {
+		return "", err
+	}
+	if kind == "channel" {
+		return id, nil
+	}
+	key := token + ":" + id
+	a.mu.Lock()
+	cached, ok := a.dmChannels[key]
+	a.mu.Unlock()
+	if ok {
+		return cached, nil
+	}
+	dm, err := rest.createDM(ctx, id)
+	if err != nil {
+		return "", err
+	}
+	a.mu.Lock()
+	a.dmChannels[key] = dm.ID
+	a.mu.Unlock()
+	return dm.ID, nil
+}
+
+func (a *DiscordAdapter) downloadAttachment(ctx context.Context, att channel.Attachment) (apiFile, error) {
+	if strings.TrimSpace(att.URL) == "" {
+		return apiFile{}, fmt.Errorf("attachment url is required")
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, att.URL, nil)
+	if err != nil {
+		return apiFile{}, err
+	}
+	resp, err := a.httpClient.Do(req)
+	if err != nil {
+		return apiFile{}, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return apiFile{}, fmt.Errorf("download attachment: status %d", resp.StatusCode)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxAttachmentBytes+1))
+	if err != nil {
+		return apiFile{}, err
+	}
+	if len(data) > maxAttachmentBytes {
+		return apiFile{}, fmt.Errorf("attachment exceeds %d bytes", maxAttachmentBytes)
+	}
+	name := strings.TrimSpace(att.Name)
+	if name == "" {
+		name = attachmentFileName(att)
+	}
+	mime := strings.TrimSpace(att.Mime)
+	if mime == "" {
+		mime = resp.Header.Get("Content-Type")
+	}
+	return apiFile{Name: name, ContentType: mime, Data: data}, nil
+}
+
+func attachmentFileName(att channel.Attachment) string {
+	if idx := strings.LastIndex(att.URL, "/"); idx >= 0 {
+		name := att.URL[idx+1:]
+		if q := strings.IndexAny(name, "?#"); q >= 0 {
+			name = name[:q]
+		}
+		if name != "" {
+			return name
+		}
+	}
+	switch att.Type {
+	case channel.AttachmentImage:
+		return "image.png"
+	case channel.AttachmentGIF:
+		return "image.gif"
+	case channel.AttachmentVideo:
+		return "video.mp4"
+	case channel.AttachmentAudio, channel.AttachmentVoice:
+		return "audio.ogg"
+	default:
+		return "file"
+	}
+}
+
+// Directory returns the Discord directory adapter backed by the same REST endpoint.
+func (a *DiscordAdapter) Directory() channel.ChannelDirectoryAdapter {
+	return &DirectoryAdapter{adapter: a}
+}
@@ -0,0 +1,339 @@
+package discord
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	"github.com/memohai/memoh/internal/channel"
+)
+
+// fakeDiscord serves a minimal subset of the Discord REST API and gateway.
+type fakeDiscord struct {
+	t      *testing.T
+	server *httptest.Server
+
+	mu       sync.Mutex
+	identify map[string]any
+	posts    map[string][]apiCreateMessage
+	dms      []string
+	deleted  []string
+	events   []gatewayPayload
+}
+
+func newFakeDiscord(t *testing.T, events ...gatewayPayload) *fakeDiscord {
+	t.Helper()
+	f := &fakeDiscord{t: t, posts: map[string][]apiCreateMessage{}, events: events}
+	f.server = httptest.NewServer(http.HandlerFunc(f.serve))
+	t.Cleanup(f.server.Close)
+	return f
+}
+
+func (f *fakeDiscord) adapter() *DiscordAdapter {
+	adapter := NewDiscordAdapter(nil)
+	adapter.apiBaseURL = f.server.URL + "/api/v10"
+	adapter.httpClient = f.server.Client()
+	return adapter
+}
+
+func (f *fakeDiscord) serve(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path == "/gateway" {
+		f.serveGateway(w, r)
+		return
+	}
+	if r.Header.Get("Authorization") != "Bot token-1" {
+		w.WriteHeader(http.StatusUnauthorized)
+		_ = json.NewEncoder(w).Encode(map[string]any{"code": 0, "message": "401: Unauthorized"})
+		return
+	}
+	path := strings.TrimPrefix(r.URL.Path, "/api/v10")
+	w.Header().Set("Content-Type", "application/json")
+	switch {
+	case r.Method == http.MethodGet && path == "/gateway/bot":
+		_ = json.NewEncoder(w).Encode(map[string]any{"url": "ws" + strings.TrimPrefix(f.server.URL, "http") + "/gateway"})
+	case r.Method == http.MethodPost && path == "/users/@me/channels":
+		var body map[string]string
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		f.mu.Lock()
+		f.dms = append(f.dms, body["recipient_id"])
+		f.mu.Unlock()
+		_ = json.NewEncoder(w).Encode(apiChannel{ID: "900", Type: 1})
+	case r.Method == http.MethodPost && strings.HasPrefix(path, "/channels/") && strings.HasSuffix(path, "/messages"):
+		channelID := strings.TrimSuffix(strings.TrimPrefix(path, "/channels/"), "/messages")
+		var body apiCreateMessage
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		f.mu.Lock()
+		f.posts[channelID] = append(f.posts[channelID], body)
+		f.mu.Unlock()
+		_ = json.NewEncoder(w).Encode(apiMessage{ID: "m-1", ChannelID: channelID, Content: body.Content})
+	case r.Method == http.MethodDelete && strings.HasPrefix(path, "/channels/"):
+		f.mu.Lock()
+		f.deleted = append(f.deleted, strings.TrimPrefix(path, "/channels/"))
+		f.mu.Unlock()
+		w.WriteHeader(http.StatusNoContent)
+	case r.Method == http.MethodGet && path == "/users/@me/guilds":
+		_ = json.NewEncoder(w).Encode([]apiGuild{{ID: "10", Name: "Guild"}})
+	case r.Method == http.MethodGet && path == "/guilds/10/channels":
+		_ = json.NewEncoder(w).Encode([]apiChannel{
+			{ID: "11", Type: channelTypeGuildText, Name: "general", GuildID: "10"},
+			{ID: "12", Type: 2, Name: "voice", GuildID: "10"},
+			{ID: "13", Type: channelTypeGuildText, Name: "random", GuildID: "10"},
+		})
+	case r.Method == http.MethodGet && path == "/guilds/10/members":
+		_ = json.NewEncoder(w).Encode([]apiGuildMember{
+			{User: apiUser{ID: "21", Username: "alice"}},
+			{User: apiUser{ID: "22", Username: "bob"}, Nick: "Bobby"},
+			{User: apiUser{ID: "99", Username: "memoh", Bot: true}},
+		})
+	default:
+		w.WriteHeader(http.StatusNotFound)
+		_ = json.NewEncoder(w).Encode(map[string]any{"code": 10003, "message": "Unknown Channel"})
+	}
+}
+
+func (f *fakeDiscord) serveGateway(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Query().Get("v") != "10" {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	upgrader := websocket.Upgrader{}
+	conn, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+	if err := conn.WriteJSON(gatewayPayload{Op: opHello, D: json.RawMessage(`{"heartbeat_interval":45000}`)}); err != nil {
+		return
+	}
+	var identify gatewayPayload
+	if err := conn.ReadJSON(&identify); err != nil || identify.Op != opIdentify {
+		return
+	}
+	var data map[string]any
+	_ = json.Unmarshal(identify.D, &data)
+	f.mu.Lock()
+	f.identify = data
+	f.mu.Unlock()
+	seq := int64(1)
+	ready := gatewayPayload{Op: opDispatch, T: "READY", S: &seq, D: json.RawMessage(`{"session_id":"s-1","user":{"id":"99","username":"memoh","bot":true}}`)}
+	if err := conn.WriteJSON(ready); err != nil {
+		return
+	}
+	for _, event := range f.events {
+		if err := conn.WriteJSON(event); err != nil {
+			return
+		}
+	}
+	for {
+		if _, _, err := conn.ReadMessage(); err != nil {
+			return
+		}
+	}
+}
+
+func messageEvent(t *testing.T, msg apiMessage) gatewayPayload {
+	t.Helper()
+	raw, err := json.Marshal(msg)
+	if err != nil {
+		t.Fatalf("marshal message: %v", err)
+	}
+	return gatewayPayload{Op: opDispatch, T: "MESSAGE_CREATE", D: raw}
+}
+
+func testConfig() channel.ChannelConfig {
+	return channel.ChannelConfig{
+		ID:          "cfg-1",
+		BotID:       "bot-1",
+		ChannelType: Type,
+		Credentials: map[string]any{"botToken": "token-1"},
+	}
+}
+
+func TestDiscordConnectReceivesMessages(t *testing.T) {
+	t.Parallel()
+
+	fake := newFakeDiscord(t,
+		messageEvent(t, apiMessage{ID: "1", ChannelID: "11", GuildID: "10", Author: apiUser{ID: "99", Username: "memoh", Bot: true}, Content: "echo"}),
+		messageEvent(t, apiMessage{
+			ID:               "2",
+			ChannelID:        "11",
+			GuildID:          "10",
+			Author:           apiUser{ID: "21", Username: "alice", GlobalName: "Alice"},
+			Content:          "hello bot",
+			Timestamp:        "2025-01-02T03:04:05.000000+00:00",
+			MessageReference: &apiMessageReference{MessageID: "1"},
+		}),
+	)
+	adapter := fake.adapter()
+	received := make(chan channel.InboundMessage, 4)
+	conn, err := adapter.Connect(context.Background(), testConfig(), func(ctx context.Context, cfg channel.ChannelConfig, msg channel.InboundMessage) error {
+		received <- msg
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("connect failed: %v", err)
+	}
+	defer func() {
+		stopCtx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+		defer cancel()
+		if err := conn.Stop(stopCtx); err != nil {
+			t.Errorf("stop failed: %v", err)
+		}
+	}()
+
+	var msg channel.InboundMessage
+	select {
+	case msg = <-received:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("timed out waiting for inbound message")
+	}
+	if msg.Message.Text != "hello bot" || msg.Message.ID != "2" {
+		t.Fatalf("unexpected message: %#v", msg.Message)
+	}
+	if msg.ReplyTarget != "channel:11" || msg.Conversation.Type != "group" {
+		t.Fatalf("unexpected routing: %s %s", msg.ReplyTarget, msg.Conversation.Type)
+	}
+	if msg.Sender.ExternalID != "21" || msg.Sender.DisplayName != "Alice" || msg.Sender.Attribute("username") != "alice" {
+		t.Fatalf("unexpected sender: %#v", msg.Sender)
+	}
+	if msg.Message.Reply == nil || msg.Message.Reply.MessageID != "1" {
+		t.Fatalf("expected reply ref, got %#v", msg.Message.Reply)
+	}
+	select {
+	case extra := <-received:
+		t.Fatalf("unexpected extra message: %#v", extra.Message)
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+	if fake.identify["token"] != "token-1" {
+		t.Fatalf("unexpected identify payload: %#v", fake.identify)
+	}
+	if intents, _ := fake.identify["intents"].(float64); int(intents) != gatewayIntents {
+		t.Fatalf("unexpected intents: %#v", fake.identify["intents"])
+	}
+}
+
+func TestDiscordSend(t *testing.T) {
+	t.Parallel()
+
+	fake := newFakeDiscord(t)
+	adapter := fake.adapter()
+	result, err := adapter.Send(context.Background(), testConfig(), channel.OutboundMessage{
+		Target: "channel:11",
+		Message: channel.Message{
+			Text:  "hi there",
+			Reply: &channel.ReplyRef{MessageID: "5"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("send failed: %v", err)
+	}
+	if len(result.MessageIDs) != 1 || result.MessageIDs[0] != "m-1" {
+		t.Fatalf("unexpected message ids: %#v", result.MessageIDs)
+	}
+	for i := 0; i < 2; i++ {
+		if _, err := adapter.Send(context.Background(), testConfig(), channel.OutboundMessage{
+			Target:  "user:21",
+			Message: channel.Message{Text: "dm"},
+		}); err != nil {
+			t.Fatalf("send dm failed: %v", err)
+		}
+	}
+
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+	posts := fake.posts["11"]
+	if len(posts) != 1 || posts[0].Content != "hi there" {
+		t.Fatalf("unexpected channel posts: %#v", posts)
+	}
+	if posts[0].MessageReference == nil || posts[0].MessageReference.MessageID != "5" {
+		t.Fatalf("expected message reference, got %#v", posts[0].MessageReference)
+	}
+	if len(fake.posts["900"]) != 2 {
+		t.Fatalf("unexpected dm posts: %#v", fake.posts["900"])
+	}
+	if len(fake.dms) != 1 || fake.dms[0] != "21" {
+		t.Fatalf("expected a single cached dm channel, got %#v", fake.dms)
+	}
+}
+
+func TestDiscordUnsend(t *testing.T) {
+	t.Parallel()
+
+	fake := newFakeDiscord(t)
+	adapter := fake.adapter()
+	if err := adapter.Unsend(context.Background(), testConfig(), "channel:11", "m-1"); err != nil {
+		t.Fatalf("unsend failed: %v", err)
+	}
+
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+	if len(fake.deleted) != 1 || fake.deleted[0] != "11/messages/m-1" {
+		t.Fatalf("unexpected deletes: %#v", fake.deleted)
+	}
+}
+
+func TestDiscordSendAPIError(t *testing.T) {
+	t.Parallel()
+
+	fake := newFakeDiscord(t)
+	adapter := fake.adapter()
+	cfg := testConfig()
+	cfg.Credentials = map[string]any{"botToken": "wrong"}
+	_, err := adapter.Send(context.Background(), cfg, channel.OutboundMessage{
+		Target:  "channel:11",
+		Message: channel.Message{Text: "hi"},
+	})
+	if err == nil || !strings.Contains(err.Error(), "401") {
+		t.Fatalf("expected unauthorized error, got %v", err)
+	}
+}
+
+func TestDiscordDirectory(t *testing.T) {
+	t.Parallel()
+
+	fake := newFakeDiscord(t)
+	dir := fake.adapter().Directory()
+	ctx := context.Background()
+
+	groups, err := dir.ListGroups(ctx, testConfig(), channel.DirectoryQuery{})
+	if err != nil {
+		t.Fatalf("list groups failed: %v", err)
+	}
+	if len(groups) != 2 || groups[0].ID != "channel:11" || groups[0].Name != "general" {
+		t.Fatalf("unexpected groups: %#v", groups)
+	}
+	peers, err := dir.ListPeers(ctx, testConfig(), channel.DirectoryQuery{Query: "bob"})
+	if err != nil {
+		t.Fatalf("list peers failed: %v", err)
+	}
+	if len(peers) != 1 || peers[0].ID != "user:22" || peers[0].Name != "Bobby" {
+		t.Fatalf("unexpected peers: %#v", peers)
+	}
+	entry, err := dir.ResolveTarget(ctx, testConfig(), "#random", channel.DirectoryEntryGroup)
+	if err != nil {
+		t.Fatalf("resolve group failed: %v", err)
+	}
+	if entry.ID != "channel:13" {
+		t.Fatalf("unexpected entry: %#v", entry)
+	}
+	entry, err = dir.ResolveTarget(ctx, testConfig(), "<@21>", channel.DirectoryEntryUser)
+	if err != nil {
+		t.Fatalf("resolve mention failed: %v", err)
+	}
+	if entry.ID != "user:21" || entry.Kind != channel.DirectoryEntryUser {
+		t.Fatalf("unexpected entry: %#v", entry)
+	}
+	if _, err := dir.ResolveTarget(ctx, testConfig(), "nobody", channel.DirectoryEntryUser); err == nil {
+		t.Fatalf("expected not found error")
+	}
+}
@@ -0,0 +1,344 @@
+package discord
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// Gateway opcodes used by the adapter.
+const (
+	opDispatch       = 0
+	opHeartbeat      = 1
+	opIdentify       = 2
+	opResume         = 6
+	opReconnect      = 7
+	opInvalidSession = 9
+	opHello          = 10
+	opHeartbeatACK   = 11
+)
+
+// gatewayIntents requests GUILDS, GUILD_MESSAGES, DIRECT_MESSAGES and MESSAGE_CONTENT.
+const gatewayIntents = 1<<0 | 1<<9 | 1<<12 | 1<<15
+
+const (
+	gatewayMinBackoff = time.Second
+	gatewayMaxBackoff = time.Minute
+)
+
+type gatewayPayload struct {
+	Op int             `json:"op"`
+	D  json.RawMessage `json:"d,omitempty"`
+	S  *int64          `json:"s,omitempty"`
+	T  string          `json:"t,omitempty"`
+}
+
+type gatewayHello struct {
+	HeartbeatInterval int64 `json:"heartbeat_interval"`
+}
+
+type gatewayReady struct {
+	SessionID        string  `json:"session_id"`
+	ResumeGatewayURL string  `json:"resume_gateway_url"`
+	User             apiUser `json:"user"`
+}
+
+// gateway maintains a single Discord gateway session, reconnecting and
+// resuming until its context is cancelled.
+type gateway struct {
+	rest      *restClient
+	token     string
+	logger    *slog.Logger
+	onMessage func(apiMessage)
+
+	mu         sync.Mutex
+	writeMu    sync.Mutex
+	conn       *websocket.Conn
+	seq        *int64
+	sessionID  string
+	resumeURL  string
+	selfUserID string
+	ready      chan struct{}
+	readyOnce  sync.Once
+}
+
+func newGateway(rest *restClient, token string, logger *slog.Logger, onMessage func(apiMessage)) *gateway {
+	return &gateway{
+		rest:      rest,
+		token:     token,
+		logger:    logger,
+		onMessage: onMessage,
+		ready:     make(chan struct{}),
+	}
+}
+
+// run connects to the gateway and keeps the session alive until ctx is done.
+func (g *gateway) run(ctx context.Context) {
+	backoff := gatewayMinBackoff
+	for {
+		err := g.session(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+		if err != nil && g.logger != nil {
+			g.logger.Warn("gateway session ended", slog.Any("error", err), slog.Duration("retry_in", backoff))
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+		if backoff > gatewayMaxBackoff {
+			backoff = gatewayMaxBackoff
+		}
+	}
+}
+
+func (g *gateway) close() {
+	g.mu.Lock()
+	conn := g.conn
+	g.mu.Unlock()
+	if conn != nil {
+		g.writeMu.Lock()
+		_ = conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""), time.Now().Add(time.Second))
+		g.writeMu.Unlock()
+		_ = conn.Close()
+	}
+}
+
+func (g *gateway) selfID() string {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.selfUserID
+}
+
+func (g *gateway) session(ctx context.Context) error {
+	endpoint, resuming, err := g.endpoint(ctx)
+	if err != nil {
+		return err
+	}
+	conn, _, err := websocket.DefaultDialer.DialContext(ctx, endpoint, nil)
+	if err != nil {
+		return fmt.Errorf("dial gateway: %w", err)
+	}
+	g.mu.Lock()
+	g.conn = conn
+	g.mu.Unlock()
+	defer func() {
+		g.mu.Lock()
+		g.conn = nil
+		g.mu.Unlock()
+		_ = conn.Close()
+	}()
+
+	sessionCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	go func() {
+		<-sessionCtx.Done()
+		_ = conn.Close()
+	}()
+
+	for {
+		var payload gatewayPayload
+		if err := conn.ReadJSON(&payload); err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			var closeErr *websocket.CloseError
+			if errors.As(err, &closeErr) && !resumableCloseCode(closeErr.Code) {
+				g.resetSession()
+			}
+			return err
+		}
+		if payload.S != nil {
+			g.mu.Lock()
+			seq := *payload.S
+			g.seq = &seq
+			g.mu.Unlock()
+		}
+		switch payload.Op {
+		case opHello:
+			var hello gatewayHello
+			if err := json.Unmarshal(payload.D, &hello); err != nil {
+				return fmt.Errorf("decode hello: %w", err)
+			}
+			go g.heartbeat(sessionCtx, conn, time.Duration(hello.HeartbeatInterval)*time.Millisecond)
+			if resuming {
+				err = g.sendResume(conn)
+			} else {
+				err = g.sendIdentify(conn)
+			}
+			if err != nil {
+				return err
+			}
+		case opHeartbeat:
+			if err := g.sendHeartbeat(conn); err != nil {
+				return err
+			}
+		case opHeartbeatACK:
+		case opReconnect:
+			return fmt.Errorf("gateway requested reconnect")
+		case opInvalidSession:
+			var resumable bool
+			_ = json.Unmarshal(payload.D, &resumable)
+			if !resumable {
+				g.resetSession()
+			}
+			return fmt.Errorf("gateway invalidated session")
+		case opDispatch:
+			g.dispatch(payload.T, payload.D)
+		}
+	}
+}
+
+func (g *gateway) endpoint(ctx context.Context) (string, bool, error) {
+	g.mu.Lock()
+	resumeURL := g.resumeURL
+	resuming := g.sessionID != "" && g.seq != nil
+	g.mu.Unlock()
+	base := resumeURL
+	if !resuming || base == "" {
+		resuming = false
+		value, err := g.rest.gatewayURL(ctx)
+		if err != nil {
+			return "", false, err
+		}
+		base = value
+	}
+	parsed, err := url.Parse(base)
+	if err != nil {
+		return "", false, fmt.Errorf("parse gateway url: %w", err)
+	}
+	query := parsed.Query()
+	query.Set("v", "10")
+	query.Set("encoding", "json")
+	parsed.RawQuery = query.Encode()
+	return parsed.String(), resuming, nil
+}
+
+func (g *gateway) dispatch(event string, data json.RawMessage) {
+	switch event {
+	case "READY":
+		var ready gatewayReady
+		if err := json.Unmarshal(data, &ready); err != nil {
+			if g.logger != nil {
+				g.logger.Warn("decode ready failed", slog.Any("error", err))
+			}
+			return
+		}
+		g.mu.Lock()
+		g.sessionID = ready.SessionID
+		g.resumeURL = strings.TrimSpace(ready.ResumeGatewayURL)
+		g.selfUserID = ready.User.ID
+		g.mu.Unlock()
+		g.readyOnce.Do(func() { close(g.ready) })
+		if g.logger != nil {
+			g.logger.Info("gateway ready", slog.String("user_id", ready.User.ID), slog.String("username", ready.User.Username))
+		}
+	case "RESUMED":
+		if g.logger != nil {
+			g.logger.Info("gateway resumed")
+		}
+	case "MESSAGE_CREATE":
+		var msg apiMessage
+		if err := json.Unmarshal(data, &msg); err != nil {
+			if g.logger != nil {
+				g.logger.Warn("decode message failed", slog.Any("error", err))
+			}
+			return
+		}
+		if g.onMessage != nil {
+			g.onMessage(msg)
+		}
+	}
+}
+
+func (g *gateway) heartbeat(ctx context.Context, conn *websocket.Conn, interval time.Duration) {
+	if interval <= 0 {
+		return
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := g.sendHeartbeat(conn); err != nil {
+				return
+			}
+		}
+	}
+}
+
+func (g *gateway) sendHeartbeat(conn *websocket.Conn) error {
+	g.mu.Lock()
+	var seq any
+	if g.seq != nil {
+		seq = *g.seq
+	}
+	g.mu.Unlock()
+	return g.write(conn, opHeartbeat, seq)
+}
+
+func (g *gateway) sendIdentify(conn *websocket.Conn) error {
+	return g.write(conn, opIdentify, map[string]any{
+		"token":   g.token,
+		"intents": gatewayIntents,
+		"properties": map[string]string{
+			"os":      "linux",
+			"browser": "memoh",
+			"device":  "memoh",
+		},
+	})
+}
+
+func (g *gateway) sendResume(conn *websocket.Conn) error {
+	g.mu.Lock()
+	data := map[string]any{
+		"token":      g.token,
+		"session_id": g.sessionID,
+	}
+	if g.seq != nil {
+		data["seq"] = *g.seq
+	}
+	g.mu.Unlock()
+	return g.write(conn, opResume, data)
+}
+
+func (g *gateway) write(conn *websocket.Conn, op int, data any) error {
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	g.writeMu.Lock()
+	defer g.writeMu.Unlock()
+	return conn.WriteJSON(gatewayPayload{Op: op, D: raw})
+}
+
+func (g *gateway) resetSession() {
+	g.mu.Lock()
+	g.sessionID = ""
+	g.resumeURL = ""
+	g.seq = nil
+	g.mu.Unlock()
+}
+
+// resumableCloseCode reports whether the gateway allows resuming after the close code.
+func resumableCloseCode(code int) bool {
+	switch code {
+	case 4004, 4010, 4011, 4012, 4013, 4014:
+		return false
+	default:
+		return true
+	}
+}
@@ -0,0 +1,231 @@
+package email
+
+import (
+	"fmt"
+	"net/mail"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/memohai/memoh/internal/channel"
+)
+
+// Transport security modes for the IMAP and SMTP connections.
+const (
+	SecurityTLS      = "tls"
+	SecurityStartTLS = "starttls"
+	SecurityNone     = "none"
+)
+
+const (
+	defaultMailbox      = "INBOX"
+	defaultPollInterval = 60 * time.Second
+)
+
+// Config holds the mailbox credentials and server endpoints extracted from a channel configuration.
+type Config struct {
+	Address      string
+	Username     string
+	Password     string
+	IMAPHost     string
+	IMAPPort     int
+	IMAPSecurity string
+	SMTPHost     string
+	SMTPPort     int
+	SMTPSecurity string
+	Mailbox      string
+	PollInterval time.Duration
+}
+
+// UserConfig holds the address used to target an email correspondent.
+type UserConfig struct {
+	Address string
+}
+
+func normalizeConfig(raw map[string]any) (map[string]any, error) {
+	cfg, err := parseConfig(raw)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]any{
+		"address":             cfg.Address,
+		"username":            cfg.Username,
+		"password":            cfg.Password,
+		"imapHost":            cfg.IMAPHost,
+		"imapPort":            cfg.IMAPPort,
+		"imapSecurity":        cfg.IMAPSecurity,
+		"smtpHost":            cfg.SMTPHost,
+		"smtpPort":            cfg.SMTPPort,
+		"smtpSecurity":        cfg.SMTPSecurity,
+		"mailbox":             cfg.Mailbox,
+		"pollIntervalSeconds": int(cfg.PollInterval / time.Second),
+	}, nil
+}
+
+func normalizeUserConfig(raw map[string]any) (map[string]any, error) {
+	cfg, err := parseUserConfig(raw)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]any{"address": cfg.Address}, nil
+}
+
+func resolveTarget(raw map[string]any) (string, error) {
+	cfg, err := parseUserConfig(raw)
+	if err != nil {
+		return "", err
+	}
+	return cfg.Address, nil
+}
+
+// normalizeTarget reduces a raw target such as "Alice <Alice@Example.com>" to "alice@example.com".
+func normalizeTarget(raw string) string {
+	value := strings.TrimSpace(raw)
+	value = strings.TrimPrefix(value, "email:")
+	value = strings.TrimPrefix(value, "mailto:")
+	if value == "" {
+		return ""
+	}
+	addr, err := mail.ParseAddress(value)
+	if err != nil {
+		return ""
+	}
+	return strings.ToLower(addr.Address)
+}
+
+func matchBinding(raw map[string]any, criteria channel.BindingCriteria) bool {
+	cfg, err := parseUserConfig(raw)
+	if err != nil {
+		return false
+	}
+	if value := normalizeTarget(criteria.Attribute("email")); value != "" && value == cfg.Address {
+		return true
+	}
+	return normalizeTarget(criteria.ExternalID) == cfg.Address
+}
+
+func buildUserConfig(identity channel.Identity) map[string]any {
+	result := map[string]any{}
+	address := normalizeTarget(identity.Attribute("email"))
+	if address == "" {
+		address = normalizeTarget(identity.ExternalID)
+	}
+	if address != "" {
+		result["address"] = address
+	}
+	return result
+}
+
+func parseConfig(raw map[string]any) (Config, error) {
+	address := normalizeTarget(channel.ReadString(raw, "address", "emailAddress", "email_address"))
+	if address == "" {
+		return Config{}, fmt.Errorf("email address is required")
+	}
+	password := channel.ReadString(raw, "password")
+	if strings.TrimSpace(password) == "" {
+		return Config{}, fmt.Errorf("email password is required")
+	}
+	username := strings.TrimSpace(channel.ReadString(raw, "username"))
+	if username == "" {
+		username = address
+	}
+	imapHost := strings.TrimSpace(channel.ReadString(raw, "imapHost", "imap_host"))
+	if imapHost == "" {
+		return Config{}, fmt.Errorf("email imapHost is required")
+	}
+	smtpHost := strings.TrimSpace(channel.ReadString(raw, "smtpHost", "smtp_host"))
+	if smtpHost == "" {
+		return Config{}, fmt.Errorf("email smtpHost is required")
+	}
+	imapSecurity, err := parseSecurity(channel.ReadString(raw, "imapSecurity", "imap_security"), SecurityTLS)
+	if err != nil {
+		return Config{}, fmt.Errorf("email imapSecurity: %w", err)
+	}
+	smtpSecurity, err := parseSecurity(channel.ReadString(raw, "smtpSecurity", "smtp_security"), SecurityStartTLS)
+	if err != nil {
+		return Config{}, fmt.Errorf("email smtpSecurity: %w", err)
+	}
+	imapPort, err := parsePort(channel.ReadString(raw, "imapPort", "imap_port"), defaultIMAPPort(imapSecurity))
+	if err != nil {
+		return Config{}, fmt.Errorf("email imapPort: %w", err)
+	}
+	smtpPort, err := parsePort(channel.ReadString(raw, "smtpPort", "smtp_port"), defaultSMTPPort(smtpSecurity))
+	if err != nil {
+		return Config{}, fmt.Errorf("email smtpPort: %w", err)
+	}
+	mailbox := strings.TrimSpace(channel.ReadString(raw, "mailbox"))
+	if mailbox == "" {
+		mailbox = defaultMailbox
+	}
+	pollInterval := defaultPollInterval
+	if value := strings.TrimSpace(channel.ReadString(raw, "pollIntervalSeconds", "poll_interval_seconds")); value != "" {
+		seconds, err := strconv.Atoi(value)
+		if err != nil || seconds <= 0 {
+			return Config{}, fmt.Errorf("email pollIntervalSeconds must be a positive integer")
+		}
+		pollInterval = time.Duration(seconds) * time.Second
+	}
+	return Config{
+		Address:      address,
+		Username:     username,
+		Password:     password,
+		IMAPHost:     imapHost,
+		IMAPPort:     imapPort,
+		IMAPSecurity: imapSecurity,
+		SMTPHost:     smtpHost,
+		SMTPPort:     smtpPort,
+		SMTPSecurity: smtpSecurity,
+		Mailbox:      mailbox,
+		PollInterval: pollInterval,
+	}, nil
+}
+
+func parseSecurity(raw, fallback string) (string, error) {
+	value := strings.ToLower(strings.TrimSpace(raw))
+	switch value {
+	case "":
+		return fallback, nil
+	case SecurityTLS, SecurityStartTLS, SecurityNone:
+		return value, nil
+	default:
+		return "", fmt.Errorf("must be %q, %q or %q", SecurityTLS, SecurityStartTLS, SecurityNone)
+	}
+}
+
+func parsePort(raw string, fallback int) (int, error) {
+	value := strings.TrimSpace(raw)
+	if value == "" {
+		return fallback, nil
+	}
+	port, err := strconv.Atoi(value)
+	if err != nil || port <= 0 || port > 65535 {
+		return 0, fmt.Errorf("invalid port %q", value)
+	}
+	return port, nil
+}
+
+func defaultIMAPPort(security string) int {
+	if security == SecurityTLS {
+		return 993
+	}
+	return 143
+}
+
+func defaultSMTPPort(security string) int {
+	switch security {
+	case SecurityTLS:
+		return 465
+	case SecurityStartTLS:
+		return 587
+	default:
+		return 25
+	}
+}
+
+func parseUserConfig(raw map[string]any) (UserConfig, error) {
+	address := normalizeTarget(channel.ReadString(raw, "address", "email"))
+	if address == "" {
+		return UserConfig{}, fmt.Errorf("email user config requires a valid address")
+	}
+	return UserConfig{Address: address}, nil
+}
@@ -0,0 +1,100 @@
+package email
+
+import (
+	"testing"
+
+	"github.com/memohai/memoh/internal/channel"
+)
+
+func TestNormalizeConfigDefaults(t *testing.T) {
+	t.Parallel()
+
+	got, err := normalizeConfig(map[string]any{
+		"address":  "Bot <Bot@Example.com>",
+		"password": "pw",
+		"imapHost": "imap.example.com",
+		"smtpHost": "smtp.example.com",
+	})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if got["address"] != "bot@example.com" || got["username"] != "bot@example.com" {
+		t.Fatalf("unexpected identity: %#v", got)
+	}
+	if got["imapPort"] != 993 || got["imapSecurity"] != SecurityTLS {
+		t.Fatalf("unexpected imap settings: %#v", got)
+	}
+	if got["smtpPort"] != 587 || got["smtpSecurity"] != SecurityStartTLS {
+		t.Fatalf("unexpected smtp settings: %#v", got)
+	}
+	if got["mailbox"] != "INBOX" || got["pollIntervalSeconds"] != 60 {
+		t.Fatalf("unexpected mailbox settings: %#v", got)
+	}
+}
+
+func TestNormalizeConfigValidation(t *testing.T) {
+	t.Parallel()
+
+	base := func() map[string]any {
+		return map[string]any{
+			"address":  "bot@example.com",
+			"password": "pw",
+			"imapHost": "imap.example.com",
+			"smtpHost": "smtp.example.com",
+		}
+	}
+	cases := map[string]func(map[string]any){
+		"missing address":  func(m map[string]any) { delete(m, "address") },
+		"invalid address":  func(m map[string]any) { m["address"] = "not-an-address" },
+		"missing password": func(m map[string]any) { delete(m, "password") },
+		"missing imap":     func(m map[string]any) { delete(m, "imapHost") },
+		"missing smtp":     func(m map[string]any) { delete(m, "smtpHost") },
+		"bad security":     func(m map[string]any) { m["smtpSecurity"] = "ssl3" },
+		"bad port":         func(m map[string]any) { m["imapPort"] = 70000.0 },
+		"bad poll":         func(m map[string]any) { m["pollIntervalSeconds"] = "0" },
+	}
+	for name, mutate := range cases {
+		raw := base()
+		mutate(raw)
+		if _, err := normalizeConfig(raw); err == nil {
+			t.Fatalf("%s: expected error", name)
+		}
+	}
+}
+
+func TestNormalizeTarget(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]string{
+		"alice@example.com":         "alice@example.com",
+		"Alice <Alice@Example.com>": "alice@example.com",
+		"mailto:alice@example.com":  "alice@example.com",
+		"email:Alice@example.com":   "alice@example.com",
+		"alice":                     "",
+		"":                          "",
+	}
+	for raw, want := range cases {
+		if got := normalizeTarget(raw); got != want {
+			t.Fatalf("normalizeTarget(%q) = %q, want %q", raw, got, want)
+		}
+	}
+}
+
+func TestMatchBinding(t *testing.T) {
+	t.Parallel()
+
+	cfg := map[string]any{"address": "alice@example.com"}
+	if !matchBinding(cfg, channel.BindingCriteria{ExternalID: "Alice@Example.com"}) {
+		t.Fatalf("expected external id match")
+	}
+	if !matchBinding(cfg, channel.BindingCriteria{Attributes: map[string]string{"email": "alice@example.com"}}) {
+		t.Fatalf("expected email attribute match")
+	}
+	if matchBinding(cfg, channel.BindingCriteria{ExternalID: "bob@example.com"}) {
+		t.Fatalf("unexpected match")
+	}
+	target, err := resolveTarget(buildUserConfig(channel.Identity{ExternalID: "alice@example.com"}))
+	if err != nil || target != "alice@example.com" {
+		t.Fatalf("unexpected target: %q %v", target, err)
+	}
+}
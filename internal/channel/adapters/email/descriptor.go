@@ -0,0 +1,7 @@
+// Package email implements the email channel adapter over IMAP and SMTP.
+package email
+
+import "github.com/memohai/memoh/internal/channel"
+
+// Type is the registered ChannelType identifier for email.
+const Type channel.ChannelType = "email"
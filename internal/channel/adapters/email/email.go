@@ -0,0 +1,379 @@
+package email
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/memohai/memoh/internal/channel"
+	"github.com/memohai/memoh/internal/channel/adapters/common"
+)
+
+// maxThreads bounds the number of correspondents whose last thread is remembered per adapter.
+const maxThreads = 4096
+
+// EmailAdapter implements the channel.Adapter, channel.Sender, and channel.Receiver interfaces for email.
+type EmailAdapter struct {
+	logger     *slog.Logger
+	httpClient *http.Client
+	now        func() time.Time
+	mu         sync.Mutex
+	threads    map[string]threadState // keyed by config ID and correspondent address
+}
+
+// NewEmailAdapter creates an EmailAdapter with the given logger.
+func NewEmailAdapter(log *slog.Logger) *EmailAdapter {
+	if log == nil {
+		log = slog.Default()
+	}
+	return &EmailAdapter{
+		logger:     log.With(slog.String("adapter", "email")),
+		httpClient: &http.Client{Timeout: 60 * time.Second},
+		now:        time.Now,
+		threads:    make(map[string]threadState),
+	}
+}
+
+// Type returns the email channel type.
+func (a *EmailAdapter) Type() channel.ChannelType {
+	return Type
+}
+
+// Descriptor returns the email channel metadata.
+func (a *EmailAdapter) Descriptor() channel.Descriptor {
+	return channel.Descriptor{
+		Type:        Type,
+		DisplayName: "Email",
+		Capabilities: channel.ChannelCapabilities{
+			Text:        true,
+			Markdown:    true,
+			Attachments: true,
+			Media:       true,
+			Reply:       true,
+			Threads:     true,
+			ChatTypes:   []string{"p2p"},
+		},
+		OutboundPolicy: channel.OutboundPolicy{
+			TextChunkLimit: 100000,
+			ChunkerMode:    channel.ChunkerModeMarkdown,
+			MediaOrder:     channel.OutboundOrderTextFirst,
+		},
+		ConfigSchema: channel.ConfigSchema{
+			Version: 1,
+			Fields: map[string]channel.FieldSchema{
+				"address": {
+					Type:     channel.FieldString,
+					Required: true,
+					Title:    "Email Address",
+					Example:  "bot@example.com",
+				},
+				"username": {
+					Type:        channel.FieldString,
+					Title:       "Username",
+					Description: "Login for IMAP and SMTP; defaults to the email address",
+				},
+				"password": {
+					Type:     channel.FieldSecret,
+					Required: true,
+					Title:    "Password",
+				},
+				"imapHost": {
+					Type:     channel.FieldString,
+					Required: true,
+					Title:    "IMAP Host",
+					Example:  "imap.example.com",
+				},
+				"imapPort": {
+					Type:        channel.FieldNumber,
+					Title:       "IMAP Port",
+					Description: "Defaults to 993 with tls and 143 otherwise",
+				},
+				"imapSecurity": {
+					Type:  channel.FieldEnum,
+					Title: "IMAP Security",
+					Enum:  []string{SecurityTLS, SecurityStartTLS, SecurityNone},
+				},
+				"smtpHost": {
+					Type:     channel.FieldString,
+					Required: true,
+					Title:    "SMTP Host",
+					Example:  "smtp.example.com",
+				},
+				"smtpPort": {
+					Type:        channel.FieldNumber,
+					Title:       "SMTP Port",
+					Description: "Defaults to 465 with tls, 587 with starttls and 25 otherwise",
+				},
+				"smtpSecurity": {
+					Type:  channel.FieldEnum,
+					Title: "SMTP Security",
+					Enum:  []string{SecurityStartTLS, SecurityTLS, SecurityNone},
+				},
+				"mailbox": {
+					Type:    channel.FieldString,
+					Title:   "Mailbox",
+					Example: defaultMailbox,
+				},
+				"pollIntervalSeconds": {
+					Type:        channel.FieldNumber,
+					Title:       "Poll Interval (seconds)",
+					Description: "How often to check for mail when IDLE is unavailable or quiet",
+					Example:     60,
+				},
+			},
+		},
+		UserConfigSchema: channel.ConfigSchema{
+			Version: 1,
+			Fields: map[string]channel.FieldSchema{
+				"address": {Type: channel.FieldString, Required: true},
+			},
+		},
+		TargetSpec: channel.TargetSpec{
+			Format: "email address",
+			Hints: []channel.TargetHint{
+				{Label: "Address", Example: "alice@example.com"},
+			},
+		},
+	}
+}
+
+// NormalizeConfig validates and normalizes an email channel configuration map.
+func (a *EmailAdapter) NormalizeConfig(raw map[string]any) (map[string]any, error) {
+	return normalizeConfig(raw)
+}
+
+// NormalizeUserConfig validates and normalizes an email user-binding configuration map.
+func (a *EmailAdapter) NormalizeUserConfig(raw map[string]any) (map[string]any, error) {
+	return normalizeUserConfig(raw)
+}
+
+// NormalizeTarget normalizes an email delivery target string.
+func (a *EmailAdapter) NormalizeTarget(raw string) string {
+	return normalizeTarget(raw)
+}
+
+// ResolveTarget derives a delivery target from an email user-binding configuration.
+func (a *EmailAdapter) ResolveTarget(userConfig map[string]any) (string, error) {
+	return resolveTarget(userConfig)
+}
+
+// MatchBinding reports whether an email user binding matches the given criteria.
+func (a *EmailAdapter) MatchBinding(config map[string]any, criteria channel.BindingCriteria) bool {
+	return matchBinding(config, criteria)
+}
+
+// BuildUserConfig constructs an email user-binding config from an Identity.
+func (a *EmailAdapter) BuildUserConfig(identity channel.Identity) map[string]any {
+	return buildUserConfig(identity)
+}
+
+// Connect logs in to the IMAP mailbox and forwards unseen messages to the handler.
+func (a *EmailAdapter) Connect(ctx context.Context, cfg channel.ChannelConfig, handler channel.InboundHandler) (channel.Connection, error) {
+	if a.logger != nil {
+		a.logger.Info("start", slog.String("config_id", cfg.ID))
+	}
+	emailCfg, err := parseConfig(cfg.Credentials)
+	if err != nil {
+		if a.logger != nil {
+			a.logger.Error("decode config failed", slog.String("config_id", cfg.ID), slog.Any("error", err))
+		}
+		return nil, err
+	}
+	c, updates, err := dialIMAP(emailCfg)
+	if err != nil {
+		if a.logger != nil {
+			a.logger.Error("imap connect failed", slog.String("config_id", cfg.ID), slog.Any("error", err))
+		}
+		return nil, err
+	}
+	connCtx, cancel := context.WithCancel(ctx)
+	watcher := &mailboxWatcher{
+		cfg:       emailCfg,
+		logger:    a.logger,
+		configID:  cfg.ID,
+		processed: map[uint32]struct{}{},
+		deliver: func(raw []byte) {
+			a.handleMail(connCtx, cfg, emailCfg, handler, raw)
+		},
+	}
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		watcher.run(connCtx, c, updates)
+	}()
+
+	stop := func(stopCtx context.Context) error {
+		if a.logger != nil {
+			a.logger.Info("stop", slog.String("config_id", cfg.ID))
+		}
+		cancel()
+		select {
+		case <-done:
+			return nil
+		case <-stopCtx.Done():
+			return stopCtx.Err()
+		}
+	}
+	return channel.NewConnection(cfg, stop), nil
+}
+
+// handleMail converts a raw message into an inbound message and dispatches it to the handler.
+func (a *EmailAdapter) handleMail(ctx context.Context, cfg channel.ChannelConfig, emailCfg Config, handler channel.InboundHandler, raw []byte) {
+	parsed, err := parseMail(bytes.NewReader(raw))
+	if err != nil {
+		if a.logger != nil {
+			a.logger.Warn("parse mail failed", slog.String("config_id", cfg.ID), slog.Any("error", err))
+		}
+		return
+	}
+	if parsed.FromAddress == "" || parsed.FromAddress == emailCfg.Address || parsed.AutoSubmitted {
+		return
+	}
+	if parsed.Text == "" && len(parsed.Attachments) == 0 {
+		return
+	}
+	a.rememberThread(cfg.ID, parsed.FromAddress, threadState{
+		MessageID:  parsed.MessageID,
+		References: parsed.References,
+		Subject:    parsed.Subject,
+	})
+	threadID := parsed.ThreadID()
+	message := channel.Message{
+		ID:          parsed.MessageID,
+		Format:      channel.MessageFormatPlain,
+		Text:        parsed.Text,
+		Attachments: parsed.Attachments,
+		Metadata:    map[string]any{"subject": parsed.Subject},
+	}
+	if threadID != "" {
+		message.Thread = &channel.ThreadRef{ID: threadID}
+	}
+	if len(parsed.InReplyTo) > 0 {
+		message.Reply = &channel.ReplyRef{MessageID: parsed.InReplyTo[0], Target: parsed.FromAddress}
+	}
+	displayName := parsed.FromName
+	if displayName == "" {
+		displayName = parsed.FromAddress
+	}
+	receivedAt := parsed.Date
+	if receivedAt.IsZero() {
+		receivedAt = a.now()
+	}
+	msg := channel.InboundMessage{
+		Channel:     Type,
+		Message:     message,
+		BotID:       cfg.BotID,
+		ReplyTarget: parsed.FromAddress,
+		Sender: channel.Identity{
+			ExternalID:  parsed.FromAddress,
+			DisplayName: displayName,
+			Attributes: map[string]string{
+				"email": parsed.FromAddress,
+				"name":  parsed.FromName,
+			},
+		},
+		Conversation: channel.Conversation{
+			ID:       parsed.FromAddress,
+			Type:     "p2p",
+			Name:     parsed.Subject,
+			ThreadID: threadID,
+		},
+		ReceivedAt: receivedAt.UTC(),
+		Source:     "email",
+	}
+	if a.logger != nil {
+		a.logger.Info(
+			"inbound received",
+			slog.String("config_id", cfg.ID),
+			slog.String("from", parsed.FromAddress),
+			slog.String("thread_id", threadID),
+			slog.Int("attachments", len(parsed.Attachments)),
+			slog.String("text", common.SummarizeText(parsed.Text)),
+		)
+	}
+	go func() {
+		if err := handler(ctx, cfg, msg); err != nil && a.logger != nil {
+			a.logger.Error("handle inbound failed", slog.String("config_id", cfg.ID), slog.Any("error", err))
+		}
+	}()
+}
+
+// Send delivers an outbound message over SMTP, threading it under the correspondent's last message.
+func (a *EmailAdapter) Send(ctx context.Context, cfg channel.ChannelConfig, msg channel.OutboundMessage) (channel.SendResult, error) {
+	emailCfg, err := parseConfig(cfg.Credentials)
+	if err != nil {
+		if a.logger != nil {
+			a.logger.Error("decode config failed", slog.String("config_id", cfg.ID), slog.Any("error", err))
+		}
+		return channel.SendResult{}, err
+	}
+	to := normalizeTarget(msg.Target)
+	if to == "" {
+		return channel.SendResult{}, fmt.Errorf("email target must be an email address")
+	}
+	if msg.Message.IsEmpty() {
+		return channel.SendResult{}, fmt.Errorf("message is required")
+	}
+	attachments := make([]outboundAttachment, 0, len(msg.Message.Attachments))
+	for _, att := range msg.Message.Attachments {
+		loaded, err := fetchAttachment(ctx, a.httpClient, att)
+		if err != nil {
+			if a.logger != nil {
+				a.logger.Error("load attachment failed", slog.String("config_id", cfg.ID), slog.Any("error", err))
+			}
+			return channel.SendResult{}, err
+		}
+		attachments = append(attachments, loaded)
+	}
+	thread := a.lookupThread(cfg.ID, to)
+	if reply := msg.Message.Reply; reply != nil && strings.TrimSpace(reply.MessageID) != "" {
+		replyID := strings.Trim(strings.TrimSpace(reply.MessageID), "<>")
+		if replyID != thread.MessageID {
+			thread = threadState{MessageID: replyID, Subject: thread.Subject}
+		}
+	}
+	body, messageID, err := composeMail(emailCfg.Address, to, msg.Message, thread, attachments, a.now())
+	if err != nil {
+		return channel.SendResult{}, err
+	}
+	if err := sendSMTP(ctx, emailCfg, to, body); err != nil {
+		if a.logger != nil {
+			a.logger.Error("send mail failed", slog.String("config_id", cfg.ID), slog.Any("error", err))
+		}
+		return channel.SendResult{}, err
+	}
+	_, references := thread.replyHeaders()
+	subject := thread.Subject
+	if subject == "" {
+		subject = subjectFromText(msg.Message.PlainText())
+	}
+	a.rememberThread(cfg.ID, to, threadState{MessageID: messageID, References: references, Subject: subject})
+	return channel.SendResult{MessageIDs: []string{messageID}}, nil
+}
+
+func (a *EmailAdapter) rememberThread(configID, address string, state threadState) {
+	if state.MessageID == "" {
+		return
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	key := configID + "|" + address
+	if _, ok := a.threads[key]; !ok && len(a.threads) >= maxThreads {
+		for existing := range a.threads {
+			delete(a.threads, existing)
+			break
+		}
+	}
+	a.threads[key] = state
+}
+
+func (a *EmailAdapter) lookupThread(configID, address string) threadState {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.threads[configID+"|"+address]
+}
@@ -0,0 +1,427 @@
+package email
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/emersion/go-imap"
+	"github.com/emersion/go-imap/backend/memory"
+	imapclient "github.com/emersion/go-imap/client"
+	imapserver "github.com/emersion/go-imap/server"
+	"github.com/emersion/go-message/mail"
+	"github.com/emersion/go-smtp"
+
+	"github.com/memohai/memoh/internal/channel"
+)
+
+const sampleMultipart = "From: Alice <alice@example.com>\r\n" +
+	"To: bot@example.com\r\n" +
+	"Subject: Dinner plans\r\n" +
+	"Date: Tue, 14 Nov 2023 22:13:20 +0000\r\n" +
+	"Message-ID: <m2@example.com>\r\n" +
+	"In-Reply-To: <m1@example.com>\r\n" +
+	"References: <m0@example.com> <m1@example.com>\r\n" +
+	"MIME-Version: 1.0\r\n" +
+	"Content-Type: multipart/mixed; boundary=outer\r\n" +
+	"\r\n" +
+	"--outer\r\n" +
+	"Content-Type: multipart/alternative; boundary=inner\r\n" +
+	"\r\n" +
+	"--inner\r\n" +
+	"Content-Type: text/plain; charset=utf-8\r\n" +
+	"\r\n" +
+	"Sounds good, see you at 7.\r\n" +
+	"\r\n" +
+	"On Tue, Nov 14, 2023 at 9:00 PM Bot <bot@example.com> wrote:\r\n" +
+	"> Shall we meet for dinner?\r\n" +
+	"--inner\r\n" +
+	"Content-Type: text/html; charset=utf-8\r\n" +
+	"\r\n" +
+	"<p>Sounds good, see you at 7.</p>\r\n" +
+	"--inner--\r\n" +
+	"--outer\r\n" +
+	"Content-Type: image/png\r\n" +
+	"Content-Disposition: attachment; filename=\"map.png\"\r\n" +
+	"Content-Transfer-Encoding: base64\r\n" +
+	"\r\n" +
+	"iVBORw0KGgo=\r\n" +
+	"--outer--\r\n"
+
+func TestParseMail(t *testing.T) {
+	t.Parallel()
+
+	parsed, err := parseMail(strings.NewReader(sampleMultipart))
+	if err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+	if parsed.FromAddress != "alice@example.com" || parsed.FromName != "Alice" || parsed.Subject != "Dinner plans" {
+		t.Fatalf("unexpected headers: %#v", parsed)
+	}
+	if parsed.MessageID != "m2@example.com" || parsed.ThreadID() != "m0@example.com" {
+		t.Fatalf("unexpected threading: id=%q thread=%q", parsed.MessageID, parsed.ThreadID())
+	}
+	if parsed.Text != "Sounds good, see you at 7." {
+		t.Fatalf("unexpected text: %q", parsed.Text)
+	}
+	if len(parsed.Attachments) != 1 {
+		t.Fatalf("expected one attachment, got %#v", parsed.Attachments)
+	}
+	att := parsed.Attachments[0]
+	if att.Type != channel.AttachmentImage || att.Name != "map.png" || att.Mime != "image/png" || att.URL != "data:image/png;base64,iVBORw0KGgo=" {
+		t.Fatalf("unexpected attachment: %#v", att)
+	}
+}
+
+func TestParseMailHTMLOnly(t *testing.T) {
+	t.Parallel()
+
+	raw := "From: bob@example.com\r\n" +
+		"Subject: hi\r\n" +
+		"Message-ID: <h1@example.com>\r\n" +
+		"Content-Type: text/html; charset=utf-8\r\n" +
+		"\r\n" +
+		"<html><head><style>p{}</style></head><body><p>Hello &amp; welcome</p><div>Line two</div></body></html>"
+	parsed, err := parseMail(strings.NewReader(raw))
+	if err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+	if parsed.Text != "Hello & welcome\nLine two" {
+		t.Fatalf("unexpected text: %q", parsed.Text)
+	}
+	if parsed.ThreadID() != "h1@example.com" {
+		t.Fatalf("unexpected thread: %q", parsed.ThreadID())
+	}
+}
+
+// smtpRecorder is an in-process SMTP backend that keeps delivered messages.
+type smtpRecorder struct {
+	mu       sync.Mutex
+	messages []recordedMail
+}
+
+type recordedMail struct {
+	From string
+	To   []string
+	Data []byte
+}
+
+func (r *smtpRecorder) Login(_ *smtp.ConnectionState, username, password string) (smtp.Session, error) {
+	if username != "bot@example.com" || password != "pw" {
+		return nil, smtp.ErrAuthRequired
+	}
+	return &smtpSession{recorder: r}, nil
+}
+
+func (r *smtpRecorder) AnonymousLogin(*smtp.ConnectionState) (smtp.Session, error) {
+	return nil, smtp.ErrAuthRequired
+}
+
+func (r *smtpRecorder) delivered() []recordedMail {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]recordedMail(nil), r.messages...)
+}
+
+type smtpSession struct {
+	recorder *smtpRecorder
+	current  recordedMail
+}
+
+func (s *smtpSession) Reset()        { s.current = recordedMail{} }
+func (s *smtpSession) Logout() error { return nil }
+
+func (s *smtpSession) Mail(from string, _ smtp.MailOptions) error {
+	s.current.From = from
+	return nil
+}
+
+func (s *smtpSession) Rcpt(to string) error {
+	s.current.To = append(s.current.To, to)
+	return nil
+}
+
+func (s *smtpSession) Data(r io.Reader) error {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	s.current.Data = data
+	s.recorder.mu.Lock()
+	s.recorder.messages = append(s.recorder.messages, s.current)
+	s.recorder.mu.Unlock()
+	return nil
+}
+
+func listen(t *testing.T) (net.Listener, string, string) {
+	t.Helper()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	host, port, _ := net.SplitHostPort(l.Addr().String())
+	return l, host, port
+}
+
+func startSMTP(t *testing.T) (*smtpRecorder, string, string) {
+	t.Helper()
+	recorder := &smtpRecorder{}
+	server := smtp.NewServer(recorder)
+	server.Domain = "localhost"
+	server.AllowInsecureAuth = true
+	l, host, port := listen(t)
+	go func() { _ = server.Serve(l) }()
+	t.Cleanup(func() { _ = server.Close() })
+	return recorder, host, port
+}
+
+func startIMAP(t *testing.T) (string, string) {
+	t.Helper()
+	server := imapserver.New(memory.New())
+	server.AllowInsecureAuth = true
+	l, host, port := listen(t)
+	go func() { _ = server.Serve(l) }()
+	t.Cleanup(func() { _ = server.Close() })
+	return host, port
+}
+
+func testConfig(imapHost, imapPort, smtpHost, smtpPort string) channel.ChannelConfig {
+	return channel.ChannelConfig{
+		ID:          "cfg-1",
+		BotID:       "bot-1",
+		ChannelType: Type,
+		Credentials: map[string]any{
+			"address":             "bot@example.com",
+			"username":            "username",
+			"password":            "password",
+			"imapHost":            imapHost,
+			"imapPort":            imapPort,
+			"imapSecurity":        SecurityNone,
+			"smtpHost":            smtpHost,
+			"smtpPort":            smtpPort,
+			"smtpSecurity":        SecurityNone,
+			"pollIntervalSeconds": 1,
+		},
+	}
+}
+
+func appendMail(t *testing.T, addr, raw string) {
+	t.Helper()
+	c, err := imapclient.Dial(addr)
+	if err != nil {
+		t.Fatalf("dial imap: %v", err)
+	}
+	defer func() { _ = c.Logout() }()
+	if err := c.Login("username", "password"); err != nil {
+		t.Fatalf("login: %v", err)
+	}
+	if err := c.Append("INBOX", nil, time.Now(), bytes.NewBufferString(raw)); err != nil {
+		t.Fatalf("append: %v", err)
+	}
+}
+
+func waitInbound(t *testing.T, received <-chan channel.InboundMessage) channel.InboundMessage {
+	t.Helper()
+	select {
+	case msg := <-received:
+		return msg
+	case <-time.After(5 * time.Second):
+		t.Fatalf("timed out waiting for inbound message")
+	}
+	return channel.InboundMessage{}
+}
+
+func TestEmailConnectReceivesUnseenMail(t *testing.T) {
+	t.Parallel()
+
+	imapHost, imapPort := startIMAP(t)
+	imapAddr := net.JoinHostPort(imapHost, imapPort)
+	appendMail(t, imapAddr, "From: Alice <alice@example.com>\r\n"+
+		"To: bot@example.com\r\n"+
+		"Subject: Hello\r\n"+
+		"Message-ID: <a1@example.com>\r\n"+
+		"Content-Type: text/plain\r\n\r\n"+
+		"Are you there?")
+	appendMail(t, imapAddr, "From: bot@example.com\r\n"+
+		"Subject: own\r\n"+
+		"Message-ID: <own@example.com>\r\n\r\n"+
+		"ignored")
+
+	adapter := NewEmailAdapter(nil)
+	received := make(chan channel.InboundMessage, 4)
+	conn, err := adapter.Connect(context.Background(), testConfig(imapHost, imapPort, "127.0.0.1", "1"), func(ctx context.Context, cfg channel.ChannelConfig, msg channel.InboundMessage) error {
+		received <- msg
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("connect failed: %v", err)
+	}
+	defer func() {
+		stopCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := conn.Stop(stopCtx); err != nil {
+			t.Errorf("stop failed: %v", err)
+		}
+	}()
+
+	msg := waitInbound(t, received)
+	if msg.Message.Text != "Are you there?" || msg.ReplyTarget != "alice@example.com" || msg.Sender.DisplayName != "Alice" {
+		t.Fatalf("unexpected message: %#v", msg)
+	}
+	if msg.Conversation.Type != "p2p" || msg.Conversation.ThreadID != "a1@example.com" || msg.Conversation.Name != "Hello" {
+		t.Fatalf("unexpected conversation: %#v", msg.Conversation)
+	}
+
+	appendMail(t, imapAddr, "From: alice@example.com\r\n"+
+		"Subject: Re: Hello\r\n"+
+		"Message-ID: <a2@example.com>\r\n"+
+		"In-Reply-To: <r1@example.com>\r\n"+
+		"References: <a1@example.com> <r1@example.com>\r\n\r\n"+
+		"Still waiting")
+	msg = waitInbound(t, received)
+	if msg.Message.Text != "Still waiting" || msg.Conversation.ThreadID != "a1@example.com" {
+		t.Fatalf("unexpected follow-up: %#v", msg)
+	}
+	if msg.Message.Reply == nil || msg.Message.Reply.MessageID != "r1@example.com" {
+		t.Fatalf("unexpected reply ref: %#v", msg.Message.Reply)
+	}
+	select {
+	case extra := <-received:
+		t.Fatalf("unexpected extra message: %#v", extra.Message)
+	case <-time.After(200 * time.Millisecond):
+	}
+
+	c, err := imapclient.Dial(imapAddr)
+	if err != nil {
+		t.Fatalf("dial imap: %v", err)
+	}
+	defer func() { _ = c.Logout() }()
+	if err := c.Login("username", "password"); err != nil {
+		t.Fatalf("login: %v", err)
+	}
+	if _, err := c.Select("INBOX", true); err != nil {
+		t.Fatalf("select: %v", err)
+	}
+	criteria := imap.NewSearchCriteria()
+	criteria.WithoutFlags = []string{imap.SeenFlag}
+	unseen, err := c.Search(criteria)
+	if err != nil {
+		t.Fatalf("search: %v", err)
+	}
+	if len(unseen) != 0 {
+		t.Fatalf("expected all messages to be marked seen, got %v", unseen)
+	}
+}
+
+func TestEmailSendThreadsReply(t *testing.T) {
+	t.Parallel()
+
+	recorder, smtpHost, smtpPort := startSMTP(t)
+	adapter := NewEmailAdapter(nil)
+	cfg := testConfig("127.0.0.1", "1", smtpHost, smtpPort)
+	cfg.Credentials["username"] = "bot@example.com"
+	cfg.Credentials["password"] = "pw"
+	adapter.rememberThread(cfg.ID, "alice@example.com", threadState{
+		MessageID:  "a2@example.com",
+		References: []string{"a1@example.com"},
+		Subject:    "Dinner plans",
+	})
+
+	_, err := adapter.Send(context.Background(), cfg, channel.OutboundMessage{
+		Target: "Alice <alice@example.com>",
+		Message: channel.Message{
+			Format: channel.MessageFormatMarkdown,
+			Text:   "See you **at 7**",
+			Attachments: []channel.Attachment{
+				{Type: channel.AttachmentFile, URL: "data:text/plain;base64,aGVsbG8=", Name: "note.txt"},
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("send failed: %v", err)
+	}
+	delivered := recorder.delivered()
+	if len(delivered) != 1 || delivered[0].From != "bot@example.com" || len(delivered[0].To) != 1 || delivered[0].To[0] != "alice@example.com" {
+		t.Fatalf("unexpected envelope: %#v", delivered)
+	}
+	reader, err := mail.CreateReader(bytes.NewReader(delivered[0].Data))
+	if err != nil {
+		t.Fatalf("read sent mail: %v", err)
+	}
+	subject, _ := reader.Header.Subject()
+	inReplyTo, _ := reader.Header.MsgIDList("In-Reply-To")
+	references, _ := reader.Header.MsgIDList("References")
+	if subject != "Re: Dinner plans" {
+		t.Fatalf("unexpected subject: %q", subject)
+	}
+	if len(inReplyTo) != 1 || inReplyTo[0] != "a2@example.com" {
+		t.Fatalf("unexpected In-Reply-To: %v", inReplyTo)
+	}
+	if strings.Join(references, " ") != "a1@example.com a2@example.com" {
+		t.Fatalf("unexpected References: %v", references)
+	}
+	sentID, _ := reader.Header.MessageID()
+
+	var plain, html, attachment string
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("next part: %v", err)
+		}
+		body, _ := io.ReadAll(part.Body)
+		switch h := part.Header.(type) {
+		case *mail.InlineHeader:
+			contentType, _, _ := h.ContentType()
+			if contentType == "text/plain" {
+				plain = string(body)
+			} else if contentType == "text/html" {
+				html = string(body)
+			}
+		case *mail.AttachmentHeader:
+			name, _ := h.Filename()
+			attachment = name + ":" + string(body)
+		}
+	}
+	if plain != "See you **at 7**" || !strings.Contains(html, "<strong>at 7</strong>") || attachment != "note.txt:hello" {
+		t.Fatalf("unexpected body: plain=%q html=%q attachment=%q", plain, html, attachment)
+	}
+
+	if _, err := adapter.Send(context.Background(), cfg, channel.OutboundMessage{
+		Target:  "alice@example.com",
+		Message: channel.Message{Text: "One more thing"},
+	}); err != nil {
+		t.Fatalf("second send failed: %v", err)
+	}
+	delivered = recorder.delivered()
+	reader, err = mail.CreateReader(bytes.NewReader(delivered[1].Data))
+	if err != nil {
+		t.Fatalf("read sent mail: %v", err)
+	}
+	inReplyTo, _ = reader.Header.MsgIDList("In-Reply-To")
+	if len(inReplyTo) != 1 || inReplyTo[0] != sentID {
+		t.Fatalf("expected follow-up to reply to %q, got %v", sentID, inReplyTo)
+	}
+}
+
+func TestSubjectFromText(t *testing.T) {
+	t.Parallel()
+
+	if got := subjectFromText("# Weekly summary\nDetails"); got != "Weekly summary" {
+		t.Fatalf("unexpected subject: %q", got)
+	}
+	if got := subjectFromText(""); got != "New message" {
+		t.Fatalf("unexpected subject: %q", got)
+	}
+	long := strings.Repeat("a", 80)
+	if got := subjectFromText(long); got != strings.Repeat("a", 60)+"…" {
+		t.Fatalf("unexpected subject: %q", got)
+	}
+}
@@ -0,0 +1,207 @@
+package email
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"strconv"
+	"time"
+
+	"github.com/emersion/go-imap"
+	"github.com/emersion/go-imap/client"
+)
+
+const (
+	minReconnectBackoff = time.Second
+	maxReconnectBackoff = time.Minute
+)
+
+// dialIMAP connects, logs in and selects the configured mailbox.
+func dialIMAP(cfg Config) (*client.Client, <-chan client.Update, error) {
+	addr := net.JoinHostPort(cfg.IMAPHost, strconv.Itoa(cfg.IMAPPort))
+	dialer := &net.Dialer{Timeout: 30 * time.Second}
+	tlsConfig := &tls.Config{ServerName: cfg.IMAPHost}
+	var (
+		c   *client.Client
+		err error
+	)
+	if cfg.IMAPSecurity == SecurityTLS {
+		c, err = client.DialWithDialerTLS(dialer, addr, tlsConfig)
+	} else {
+		c, err = client.DialWithDialer(dialer, addr)
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("imap dial: %w", err)
+	}
+	updates := make(chan client.Update, 32)
+	c.Updates = updates
+	if cfg.IMAPSecurity == SecurityStartTLS {
+		if err := c.StartTLS(tlsConfig); err != nil {
+			_ = c.Logout()
+			return nil, nil, fmt.Errorf("imap starttls: %w", err)
+		}
+	}
+	if err := c.Login(cfg.Username, cfg.Password); err != nil {
+		_ = c.Logout()
+		return nil, nil, fmt.Errorf("imap login: %w", err)
+	}
+	if _, err := c.Select(cfg.Mailbox, false); err != nil {
+		_ = c.Logout()
+		return nil, nil, fmt.Errorf("imap select %s: %w", cfg.Mailbox, err)
+	}
+	return c, updates, nil
+}
+
+// mailboxWatcher fetches unseen messages from a mailbox, waiting for new mail with IDLE
+// (or polling when the server lacks it) and reconnecting with backoff on failures.
+type mailboxWatcher struct {
+	cfg       Config
+	logger    *slog.Logger
+	configID  string
+	deliver   func(raw []byte)
+	processed map[uint32]struct{}
+}
+
+func (w *mailboxWatcher) run(ctx context.Context, c *client.Client, updates <-chan client.Update) {
+	backoff := minReconnectBackoff
+	for {
+		if c != nil {
+			err := w.watch(ctx, c, updates)
+			_ = c.Logout()
+			c = nil
+			if ctx.Err() != nil {
+				return
+			}
+			if w.logger != nil {
+				w.logger.Warn("imap connection lost", slog.String("config_id", w.configID), slog.Any("error", err))
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+		var err error
+		c, updates, err = dialIMAP(w.cfg)
+		if err != nil {
+			if w.logger != nil {
+				w.logger.Error("imap reconnect failed", slog.String("config_id", w.configID), slog.Any("error", err))
+			}
+			backoff *= 2
+			if backoff > maxReconnectBackoff {
+				backoff = maxReconnectBackoff
+			}
+			continue
+		}
+		// UIDs may not survive a reconnect; the \Seen flag remains the source of truth.
+		w.processed = map[uint32]struct{}{}
+		backoff = minReconnectBackoff
+	}
+}
+
+// watch processes unseen messages until ctx is cancelled or the connection fails.
+func (w *mailboxWatcher) watch(ctx context.Context, c *client.Client, updates <-chan client.Update) error {
+	wake := make(chan struct{}, 1)
+	go func() {
+		for {
+			select {
+			case <-updates:
+				select {
+				case wake <- struct{}{}:
+				default:
+				}
+			case <-c.LoggedOut():
+				return
+			}
+		}
+	}()
+	for {
+		if err := w.fetchUnseen(c); err != nil {
+			return err
+		}
+		stop := make(chan struct{})
+		idleDone := make(chan error, 1)
+		go func() {
+			idleDone <- c.Idle(stop, &client.IdleOptions{PollInterval: w.cfg.PollInterval})
+		}()
+		timer := time.NewTimer(w.cfg.PollInterval)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			close(stop)
+			<-idleDone
+			return ctx.Err()
+		case err := <-idleDone:
+			timer.Stop()
+			close(stop)
+			if err != nil {
+				return err
+			}
+			continue
+		case <-wake:
+		case <-timer.C:
+		}
+		timer.Stop()
+		close(stop)
+		if err := <-idleDone; err != nil {
+			return err
+		}
+	}
+}
+
+// fetchUnseen delivers every unseen message and then flags them as \Seen.
+func (w *mailboxWatcher) fetchUnseen(c *client.Client) error {
+	criteria := imap.NewSearchCriteria()
+	criteria.WithoutFlags = []string{imap.SeenFlag}
+	uids, err := c.UidSearch(criteria)
+	if err != nil {
+		return fmt.Errorf("imap search: %w", err)
+	}
+	pending := new(imap.SeqSet)
+	for _, uid := range uids {
+		if _, ok := w.processed[uid]; ok {
+			continue
+		}
+		pending.AddNum(uid)
+	}
+	if pending.Empty() {
+		return nil
+	}
+	section := &imap.BodySectionName{Peek: true}
+	messages := make(chan *imap.Message, 8)
+	fetchDone := make(chan error, 1)
+	go func() {
+		fetchDone <- c.UidFetch(pending, []imap.FetchItem{imap.FetchUid, section.FetchItem()}, messages)
+	}()
+	fetched := new(imap.SeqSet)
+	for msg := range messages {
+		body := msg.GetBody(section)
+		if body == nil {
+			continue
+		}
+		raw, err := io.ReadAll(body)
+		if err != nil {
+			if w.logger != nil {
+				w.logger.Warn("imap read body failed", slog.String("config_id", w.configID), slog.Any("error", err))
+			}
+			continue
+		}
+		w.processed[msg.Uid] = struct{}{}
+		fetched.AddNum(msg.Uid)
+		w.deliver(raw)
+	}
+	if err := <-fetchDone; err != nil {
+		return fmt.Errorf("imap fetch: %w", err)
+	}
+	if fetched.Empty() {
+		return nil
+	}
+	flags := []interface{}{imap.SeenFlag}
+	if err := c.UidStore(fetched, imap.FormatFlagsOp(imap.AddFlags, true), flags, nil); err != nil {
+		return fmt.Errorf("imap store: %w", err)
+	}
+	return nil
+}
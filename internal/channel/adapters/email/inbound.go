@@ -0,0 +1,187 @@
+package email
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"regexp"
+	"strings"
+	"time"
+
+	_ "github.com/emersion/go-message/charset" // register non-UTF-8 charsets for decoding
+	"github.com/emersion/go-message/mail"
+
+	"github.com/memohai/memoh/internal/channel"
+)
+
+// maxInboundAttachmentBytes bounds the size of a single attachment inlined as a data URL.
+const maxInboundAttachmentBytes = 10 << 20
+
+var (
+	htmlBreakPattern  = regexp.MustCompile(`(?i)<br\s*/?>|</p>|</div>|</li>|</tr>|</h[1-6]>`)
+	htmlTagPattern    = regexp.MustCompile(`(?s)<[^>]*>`)
+	htmlDropPattern   = regexp.MustCompile(`(?is)<(style|script|head)[^>]*>.*?</(style|script|head)>`)
+	blankLinesPattern = regexp.MustCompile(`\n{3,}`)
+	quoteHeadPattern  = regexp.MustCompile(`^On .+ wrote:$`)
+)
+
+// inboundMail is the subset of a parsed RFC 5322 message the adapter needs.
+type inboundMail struct {
+	MessageID     string
+	InReplyTo     []string
+	References    []string
+	Subject       string
+	FromAddress   string
+	FromName      string
+	Date          time.Time
+	AutoSubmitted bool
+	Text          string
+	Attachments   []channel.Attachment
+}
+
+// ThreadID returns the root Message-ID of the thread the message belongs to.
+func (m inboundMail) ThreadID() string {
+	if len(m.References) > 0 {
+		return m.References[0]
+	}
+	if len(m.InReplyTo) > 0 {
+		return m.InReplyTo[0]
+	}
+	return m.MessageID
+}
+
+// parseMail reads a raw message, extracting headers, the reply text and attachments.
+func parseMail(r io.Reader) (inboundMail, error) {
+	reader, err := mail.CreateReader(r)
+	if err != nil && reader == nil {
+		return inboundMail{}, err
+	}
+	defer reader.Close()
+	header := reader.Header
+	result := inboundMail{}
+	result.MessageID, _ = header.MessageID()
+	result.InReplyTo, _ = header.MsgIDList("In-Reply-To")
+	result.References, _ = header.MsgIDList("References")
+	result.Subject, _ = header.Subject()
+	result.Date, _ = header.Date()
+	if from, err := header.AddressList("From"); err == nil && len(from) > 0 {
+		result.FromAddress = strings.ToLower(strings.TrimSpace(from[0].Address))
+		result.FromName = strings.TrimSpace(from[0].Name)
+	}
+	if value := strings.ToLower(strings.TrimSpace(header.Get("Auto-Submitted"))); value != "" && value != "no" {
+		result.AutoSubmitted = true
+	}
+
+	plain, htmlBody := "", ""
+	for {
+		part, err := reader.NextPart()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return result, fmt.Errorf("read mail part: %w", err)
+		}
+		switch h := part.Header.(type) {
+		case *mail.InlineHeader:
+			contentType, params, _ := h.ContentType()
+			switch {
+			case contentType == "text/plain" && plain == "":
+				body, err := io.ReadAll(io.LimitReader(part.Body, maxInboundAttachmentBytes))
+				if err != nil {
+					return result, fmt.Errorf("read text part: %w", err)
+				}
+				plain = string(body)
+			case contentType == "text/html" && htmlBody == "":
+				body, err := io.ReadAll(io.LimitReader(part.Body, maxInboundAttachmentBytes))
+				if err != nil {
+					return result, fmt.Errorf("read html part: %w", err)
+				}
+				htmlBody = string(body)
+			case strings.HasPrefix(contentType, "text/"):
+			default:
+				if att, ok := readAttachment(part.Body, contentType, params["name"]); ok {
+					result.Attachments = append(result.Attachments, att)
+				}
+			}
+		case *mail.AttachmentHeader:
+			contentType, _, _ := h.ContentType()
+			name, _ := h.Filename()
+			if att, ok := readAttachment(part.Body, contentType, name); ok {
+				result.Attachments = append(result.Attachments, att)
+			}
+		}
+	}
+	text := plain
+	if strings.TrimSpace(text) == "" && htmlBody != "" {
+		text = htmlToText(htmlBody)
+	}
+	result.Text = stripQuotedReply(text)
+	return result, nil
+}
+
+// readAttachment inlines a MIME part as a data URL, skipping parts above the size cap.
+func readAttachment(r io.Reader, contentType, name string) (channel.Attachment, bool) {
+	data, err := io.ReadAll(io.LimitReader(r, maxInboundAttachmentBytes+1))
+	if err != nil || len(data) == 0 || len(data) > maxInboundAttachmentBytes {
+		return channel.Attachment{}, false
+	}
+	contentType = strings.ToLower(strings.TrimSpace(contentType))
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	return channel.Attachment{
+		Type: attachmentTypeFromMime(contentType),
+		URL:  "data:" + contentType + ";base64," + base64.StdEncoding.EncodeToString(data),
+		Name: strings.TrimSpace(name),
+		Mime: contentType,
+		Size: int64(len(data)),
+	}, true
+}
+
+func attachmentTypeFromMime(contentType string) channel.AttachmentType {
+	switch {
+	case contentType == "image/gif":
+		return channel.AttachmentGIF
+	case strings.HasPrefix(contentType, "image/"):
+		return channel.AttachmentImage
+	case strings.HasPrefix(contentType, "audio/"):
+		return channel.AttachmentAudio
+	case strings.HasPrefix(contentType, "video/"):
+		return channel.AttachmentVideo
+	default:
+		return channel.AttachmentFile
+	}
+}
+
+// htmlToText is a best-effort conversion used when a message has no text/plain part.
+func htmlToText(body string) string {
+	body = htmlDropPattern.ReplaceAllString(body, "")
+	body = htmlBreakPattern.ReplaceAllString(body, "\n")
+	body = htmlTagPattern.ReplaceAllString(body, "")
+	body = html.UnescapeString(body)
+	lines := strings.Split(body, "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimSpace(line)
+	}
+	return blankLinesPattern.ReplaceAllString(strings.Join(lines, "\n"), "\n\n")
+}
+
+// stripQuotedReply drops the quoted history most clients append below a reply.
+func stripQuotedReply(text string) string {
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	lines := strings.Split(text, "\n")
+	kept := make([]string, 0, len(lines))
+	for _, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if quoteHeadPattern.MatchString(trimmed) || trimmed == "-----Original Message-----" {
+			break
+		}
+		if strings.HasPrefix(trimmed, ">") {
+			continue
+		}
+		kept = append(kept, strings.TrimRight(line, " \t"))
+	}
+	return strings.TrimSpace(strings.Join(kept, "\n"))
+}
@@ -0,0 +1,269 @@
+package email
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/emersion/go-message/mail"
+	"github.com/emersion/go-sasl"
+	"github.com/emersion/go-smtp"
+	"github.com/yuin/goldmark"
+
+	"github.com/memohai/memoh/internal/channel"
+)
+
+// maxOutboundAttachmentBytes bounds the size of a single attachment fetched for sending.
+const maxOutboundAttachmentBytes = 25 << 20
+
+// subjectPreviewRunes is the length of the subject derived from the first line of a new message.
+const subjectPreviewRunes = 60
+
+// threadState remembers the last message exchanged with a correspondent so replies can be threaded.
+type threadState struct {
+	MessageID  string
+	References []string
+	Subject    string
+}
+
+// replyHeaders returns the In-Reply-To and References values for a reply to the thread.
+func (t threadState) replyHeaders() ([]string, []string) {
+	if t.MessageID == "" {
+		return nil, nil
+	}
+	references := append([]string(nil), t.References...)
+	if len(references) == 0 || references[len(references)-1] != t.MessageID {
+		references = append(references, t.MessageID)
+	}
+	return []string{t.MessageID}, references
+}
+
+// outboundAttachment is an attachment whose content has been fetched.
+type outboundAttachment struct {
+	Name string
+	Mime string
+	Data []byte
+}
+
+// composeMail renders an outbound message as RFC 5322 bytes and returns its Message-ID.
+// Markdown is sent as multipart/alternative with an HTML rendering.
+func composeMail(from, to string, msg channel.Message, thread threadState, attachments []outboundAttachment, now time.Time) ([]byte, string, error) {
+	var header mail.Header
+	header.SetDate(now)
+	header.SetAddressList("From", []*mail.Address{{Address: from}})
+	header.SetAddressList("To", []*mail.Address{{Address: to}})
+	domain := from[strings.LastIndex(from, "@")+1:]
+	if err := header.GenerateMessageIDWithHostname(domain); err != nil {
+		return nil, "", err
+	}
+	messageID, _ := header.MessageID()
+	text := strings.TrimSpace(msg.PlainText())
+	subject := strings.TrimSpace(thread.Subject)
+	if subject != "" {
+		if !strings.HasPrefix(strings.ToLower(subject), "re:") {
+			subject = "Re: " + subject
+		}
+	} else {
+		subject = subjectFromText(text)
+	}
+	header.SetSubject(subject)
+	if inReplyTo, references := thread.replyHeaders(); len(inReplyTo) > 0 {
+		header.SetMsgIDList("In-Reply-To", inReplyTo)
+		header.SetMsgIDList("References", references)
+	}
+
+	var buf bytes.Buffer
+	writer, err := mail.CreateWriter(&buf, header)
+	if err != nil {
+		return nil, "", err
+	}
+	if text != "" {
+		if err := writeBody(writer, msg, text); err != nil {
+			return nil, "", err
+		}
+	}
+	for _, att := range attachments {
+		var attHeader mail.AttachmentHeader
+		attHeader.Set("Content-Type", att.Mime)
+		attHeader.SetFilename(att.Name)
+		part, err := writer.CreateAttachment(attHeader)
+		if err != nil {
+			return nil, "", err
+		}
+		if _, err := part.Write(att.Data); err != nil {
+			return nil, "", err
+		}
+		if err := part.Close(); err != nil {
+			return nil, "", err
+		}
+	}
+	if err := writer.Close(); err != nil {
+		return nil, "", err
+	}
+	return buf.Bytes(), messageID, nil
+}
+
+func writeBody(writer *mail.Writer, msg channel.Message, text string) error {
+	inline, err := writer.CreateInline()
+	if err != nil {
+		return err
+	}
+	if err := writeInlinePart(inline, "text/plain", []byte(text)); err != nil {
+		return err
+	}
+	if msg.Format == channel.MessageFormatMarkdown {
+		var rendered bytes.Buffer
+		if err := goldmark.Convert([]byte(text), &rendered); err != nil {
+			return fmt.Errorf("render markdown: %w", err)
+		}
+		if err := writeInlinePart(inline, "text/html", rendered.Bytes()); err != nil {
+			return err
+		}
+	}
+	return inline.Close()
+}
+
+func writeInlinePart(inline *mail.InlineWriter, contentType string, body []byte) error {
+	var h mail.InlineHeader
+	h.Set("Content-Type", contentType+"; charset=utf-8")
+	part, err := inline.CreatePart(h)
+	if err != nil {
+		return err
+	}
+	if _, err := part.Write(body); err != nil {
+		return err
+	}
+	return part.Close()
+}
+
+// subjectFromText derives a subject from the first line of a message that starts a new thread.
+func subjectFromText(text string) string {
+	line, _, _ := strings.Cut(strings.TrimSpace(text), "\n")
+	line = strings.Trim(strings.TrimSpace(line), "#*_`> ")
+	runes := []rune(line)
+	if len(runes) > subjectPreviewRunes {
+		line = strings.TrimSpace(string(runes[:subjectPreviewRunes])) + "…"
+	}
+	if line == "" {
+		return "New message"
+	}
+	return line
+}
+
+// fetchAttachment loads attachment content from a data URL or an HTTP(S) URL.
+func fetchAttachment(ctx context.Context, client *http.Client, att channel.Attachment) (outboundAttachment, error) {
+	url := strings.TrimSpace(att.URL)
+	if url == "" {
+		return outboundAttachment{}, fmt.Errorf("attachment url is required")
+	}
+	name := strings.TrimSpace(att.Name)
+	if name == "" {
+		name = "attachment"
+	}
+	mimeType := strings.TrimSpace(att.Mime)
+	var data []byte
+	if strings.HasPrefix(url, "data:") {
+		meta, payload, ok := strings.Cut(strings.TrimPrefix(url, "data:"), ",")
+		if !ok || !strings.HasSuffix(meta, ";base64") {
+			return outboundAttachment{}, fmt.Errorf("unsupported data url")
+		}
+		decoded, err := base64.StdEncoding.DecodeString(payload)
+		if err != nil {
+			return outboundAttachment{}, fmt.Errorf("decode data url: %w", err)
+		}
+		if mimeType == "" {
+			mimeType = strings.TrimSuffix(meta, ";base64")
+		}
+		data = decoded
+	} else {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+		if err != nil {
+			return outboundAttachment{}, err
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			return outboundAttachment{}, fmt.Errorf("download attachment: %w", err)
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+			return outboundAttachment{}, fmt.Errorf("download attachment: status %d", resp.StatusCode)
+		}
+		data, err = io.ReadAll(io.LimitReader(resp.Body, maxOutboundAttachmentBytes+1))
+		if err != nil {
+			return outboundAttachment{}, fmt.Errorf("download attachment: %w", err)
+		}
+		if mimeType == "" {
+			mimeType = resp.Header.Get("Content-Type")
+		}
+	}
+	if len(data) > maxOutboundAttachmentBytes {
+		return outboundAttachment{}, fmt.Errorf("attachment exceeds %d bytes", maxOutboundAttachmentBytes)
+	}
+	if mimeType == "" {
+		mimeType = http.DetectContentType(data)
+	}
+	return outboundAttachment{Name: name, Mime: mimeType, Data: data}, nil
+}
+
+// sendSMTP delivers a composed message to a single recipient.
+func sendSMTP(ctx context.Context, cfg Config, to string, body []byte) error {
+	addr := net.JoinHostPort(cfg.SMTPHost, strconv.Itoa(cfg.SMTPPort))
+	dialer := &net.Dialer{Timeout: 30 * time.Second}
+	tlsConfig := &tls.Config{ServerName: cfg.SMTPHost}
+	var conn net.Conn
+	var err error
+	if cfg.SMTPSecurity == SecurityTLS {
+		conn, err = tls.DialWithDialer(dialer, "tcp", addr, tlsConfig)
+	} else {
+		conn, err = dialer.DialContext(ctx, "tcp", addr)
+	}
+	if err != nil {
+		return fmt.Errorf("smtp dial: %w", err)
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	} else {
+		_ = conn.SetDeadline(time.Now().Add(2 * time.Minute))
+	}
+	client, err := smtp.NewClient(conn, cfg.SMTPHost)
+	if err != nil {
+		_ = conn.Close()
+		return fmt.Errorf("smtp handshake: %w", err)
+	}
+	defer client.Close()
+	if cfg.SMTPSecurity == SecurityStartTLS {
+		if err := client.StartTLS(tlsConfig); err != nil {
+			return fmt.Errorf("smtp starttls: %w", err)
+		}
+	}
+	if ok, _ := client.Extension("AUTH"); ok {
+		if err := client.Auth(sasl.NewPlainClient("", cfg.Username, cfg.Password)); err != nil {
+			return fmt.Errorf("smtp auth: %w", err)
+		}
+	}
+	if err := client.Mail(cfg.Address, nil); err != nil {
+		return fmt.Errorf("smtp mail from: %w", err)
+	}
+	if err := client.Rcpt(to); err != nil {
+		return fmt.Errorf("smtp rcpt to: %w", err)
+	}
+	writer, err := client.Data()
+	if err != nil {
+		return fmt.Errorf("smtp data: %w", err)
+	}
+	if _, err := writer.Write(body); err != nil {
+		return fmt.Errorf("smtp data: %w", err)
+	}
+	if err := writer.Close(); err != nil {
+		return fmt.Errorf("smtp data: %w", err)
+	}
+	return client.Quit()
+}
@@ -0,0 +1,238 @@
+package feishu
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	lark "github.com/larksuite/oapi-sdk-go/v3"
+	larkim "github.com/larksuite/oapi-sdk-go/v3/service/im/v1"
+
+	"github.com/memohai/memoh/internal/channel"
+)
+
+// directoryPageSize caps a single chat or member listing request.
+const directoryPageSize = 100
+
+// feishuDirectory implements channel.ChannelDirectoryAdapter with the IM chat APIs.
+// Listing the tenant's users needs the contact scope, so peers are left to the local directory.
+type feishuDirectory struct {
+	adapter *FeishuAdapter
+}
+
+// Directory returns the Feishu directory adapter.
+func (a *FeishuAdapter) Directory() channel.ChannelDirectoryAdapter {
+	return &feishuDirectory{adapter: a}
+}
+
+// ListPeers is not supported without the contact scope.
+func (d *feishuDirectory) ListPeers(ctx context.Context, cfg channel.ChannelConfig, query channel.DirectoryQuery) ([]channel.DirectoryEntry, error) {
+	return nil, channel.ErrDirectoryNotSupported
+}
+
+// ListGroups lists the chats the bot is a member of, searching by name when a query is given.
+func (d *feishuDirectory) ListGroups(ctx context.Context, cfg channel.ChannelConfig, query channel.DirectoryQuery) ([]channel.DirectoryEntry, error) {
+	client, err := d.client(cfg)
+	if err != nil {
+		return nil, err
+	}
+	pageSize := directoryLimit(query.Limit)
+	var items []*larkim.ListChat
+	if needle := strings.TrimSpace(query.Query); needle != "" {
+		req := larkim.NewSearchChatReqBuilder().
+			Query(needle).
+			PageSize(pageSize).
+			Build()
+		resp, err := client.Im.V1.Chat.Search(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+		if !resp.Success() {
+			return nil, fmt.Errorf("feishu search chats failed: %s (code: %d)", resp.Msg, resp.Code)
+		}
+		if resp.Data != nil {
+			items = resp.Data.Items
+		}
+	} else {
+		req := larkim.NewListChatReqBuilder().
+			PageSize(pageSize).
+			Build()
+		resp, err := client.Im.V1.Chat.List(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+		if !resp.Success() {
+			return nil, fmt.Errorf("feishu list chats failed: %s (code: %d)", resp.Msg, resp.Code)
+		}
+		if resp.Data != nil {
+			items = resp.Data.Items
+		}
+	}
+	results := make([]channel.DirectoryEntry, 0, len(items))
+	for _, item := range items {
+		if item == nil || item.ChatId == nil || strings.TrimSpace(*item.ChatId) == "" {
+			continue
+		}
+		results = append(results, channel.DirectoryEntry{
+			Kind:      channel.DirectoryEntryGroup,
+			ID:        "chat_id:" + strings.TrimSpace(*item.ChatId),
+			Name:      strings.TrimSpace(derefString(item.Name)),
+			AvatarURL: strings.TrimSpace(derefString(item.Avatar)),
+		})
+		if query.Limit > 0 && len(results) >= query.Limit {
+			break
+		}
+	}
+	return results, nil
+}
+
+// ListGroupMembers lists the members of a chat by open_id.
+func (d *feishuDirectory) ListGroupMembers(ctx context.Context, cfg channel.ChannelConfig, groupID string, query channel.DirectoryQuery) ([]channel.DirectoryEntry, error) {
+	chatID, kind, ok := parseFeishuDirectoryID(groupID)
+	if !ok || kind != channel.DirectoryEntryGroup {
+		return nil, fmt.Errorf("feishu group must be chat_id:<id> or oc_<id>")
+	}
+	client, err := d.client(cfg)
+	if err != nil {
+		return nil, err
+	}
+	req := larkim.NewGetChatMembersReqBuilder().
+		ChatId(chatID).
+		MemberIdType(larkim.MemberIdTypeOpenId).
+		PageSize(directoryLimit(query.Limit)).
+		Build()
+	resp, err := client.Im.V1.ChatMembers.Get(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	if !resp.Success() {
+		return nil, fmt.Errorf("feishu list chat members failed: %s (code: %d)", resp.Msg, resp.Code)
+	}
+	if resp.Data == nil {
+		return nil, nil
+	}
+	needle := strings.ToLower(strings.TrimSpace(query.Query))
+	results := make([]channel.DirectoryEntry, 0, len(resp.Data.Items))
+	for _, member := range resp.Data.Items {
+		if member == nil || member.MemberId == nil || strings.TrimSpace(*member.MemberId) == "" {
+			continue
+		}
+		name := strings.TrimSpace(derefString(member.Name))
+		if needle != "" && !strings.Contains(strings.ToLower(name), needle) {
+			continue
+		}
+		results = append(results, channel.DirectoryEntry{
+			Kind: channel.DirectoryEntryUser,
+			ID:   "open_id:" + strings.TrimSpace(*member.MemberId),
+			Name: name,
+		})
+		if query.Limit > 0 && len(results) >= query.Limit {
+			break
+		}
+	}
+	return results, nil
+}
+
+// ResolveTarget resolves open_id/chat_id targets directly and group names through chat search.
+// Plain user names are not supported.
+func (d *feishuDirectory) ResolveTarget(ctx context.Context, cfg channel.ChannelConfig, input string, kind channel.DirectoryEntryKind) (channel.DirectoryEntry, error) {
+	trimmed := strings.TrimSpace(input)
+	if trimmed == "" {
+		return channel.DirectoryEntry{}, channel.ErrDirectoryEntryNotFound
+	}
+	if id, idKind, ok := parseFeishuDirectoryID(trimmed); ok {
+		if kind != "" && kind != idKind {
+			return channel.DirectoryEntry{}, channel.ErrDirectoryEntryNotFound
+		}
+		if idKind == channel.DirectoryEntryGroup {
+			return d.getGroup(ctx, cfg, id)
+		}
+		return channel.DirectoryEntry{Kind: idKind, ID: normalizeTarget(trimmed)}, nil
+	}
+	if kind != channel.DirectoryEntryGroup {
+		return channel.DirectoryEntry{}, channel.ErrDirectoryNotSupported
+	}
+	groups, err := d.ListGroups(ctx, cfg, channel.DirectoryQuery{Query: trimmed, Limit: 5})
+	if err != nil {
+		return channel.DirectoryEntry{}, err
+	}
+	var match *channel.DirectoryEntry
+	for i := range groups {
+		if strings.EqualFold(groups[i].Name, trimmed) {
+			if match != nil {
+				return channel.DirectoryEntry{}, channel.ErrDirectoryEntryAmbiguous
+			}
+			match = &groups[i]
+		}
+	}
+	if match == nil {
+		return channel.DirectoryEntry{}, channel.ErrDirectoryNotSupported
+	}
+	return *match, nil
+}
+
+func (d *feishuDirectory) getGroup(ctx context.Context, cfg channel.ChannelConfig, chatID string) (channel.DirectoryEntry, error) {
+	client, err := d.client(cfg)
+	if err != nil {
+		return channel.DirectoryEntry{}, err
+	}
+	req := larkim.NewGetChatReqBuilder().
+		ChatId(chatID).
+		Build()
+	resp, err := client.Im.V1.Chat.Get(ctx, req)
+	if err != nil {
+		return channel.DirectoryEntry{}, err
+	}
+	if !resp.Success() {
+		return channel.DirectoryEntry{}, channel.ErrDirectoryEntryNotFound
+	}
+	entry := channel.DirectoryEntry{
+		Kind: channel.DirectoryEntryGroup,
+		ID:   "chat_id:" + chatID,
+	}
+	if resp.Data != nil {
+		entry.Name = strings.TrimSpace(derefString(resp.Data.Name))
+		entry.AvatarURL = strings.TrimSpace(derefString(resp.Data.Avatar))
+	}
+	return entry, nil
+}
+
+func (d *feishuDirectory) client(cfg channel.ChannelConfig) (*lark.Client, error) {
+	feishuCfg, err := parseConfig(cfg.Credentials)
+	if err != nil {
+		return nil, err
+	}
+	return lark.NewClient(feishuCfg.AppID, feishuCfg.AppSecret), nil
+}
+
+// parseFeishuDirectoryID recognizes prefixed targets and bare open_id (ou_) and chat_id (oc_) values.
+func parseFeishuDirectoryID(raw string) (string, channel.DirectoryEntryKind, bool) {
+	value := strings.TrimSpace(raw)
+	switch {
+	case strings.HasPrefix(value, "open_id:"), strings.HasPrefix(value, "user_id:"):
+		id := strings.TrimSpace(value[strings.Index(value, ":")+1:])
+		return id, channel.DirectoryEntryUser, id != ""
+	case strings.HasPrefix(value, "chat_id:"):
+		id := strings.TrimSpace(strings.TrimPrefix(value, "chat_id:"))
+		return id, channel.DirectoryEntryGroup, id != ""
+	case strings.HasPrefix(value, "ou_"):
+		return value, channel.DirectoryEntryUser, true
+	case strings.HasPrefix(value, "oc_"):
+		return value, channel.DirectoryEntryGroup, true
+	}
+	return "", "", false
+}
+
+func directoryLimit(limit int) int {
+	if limit <= 0 || limit > directoryPageSize {
+		return directoryPageSize
+	}
+	return limit
+}
+
+func derefString(value *string) string {
+	if value == nil {
+		return ""
+	}
+	return *value
+}
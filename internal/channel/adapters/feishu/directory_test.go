@@ -0,0 +1,55 @@
+package feishu
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/memohai/memoh/internal/channel"
+)
+
+func TestParseFeishuDirectoryID(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		raw      string
+		wantID   string
+		wantKind channel.DirectoryEntryKind
+		wantOK   bool
+	}{
+		{raw: "open_id:ou_123", wantID: "ou_123", wantKind: channel.DirectoryEntryUser, wantOK: true},
+		{raw: "user_id:uu_123", wantID: "uu_123", wantKind: channel.DirectoryEntryUser, wantOK: true},
+		{raw: "chat_id:oc_123", wantID: "oc_123", wantKind: channel.DirectoryEntryGroup, wantOK: true},
+		{raw: "ou_999", wantID: "ou_999", wantKind: channel.DirectoryEntryUser, wantOK: true},
+		{raw: "oc_999", wantID: "oc_999", wantKind: channel.DirectoryEntryGroup, wantOK: true},
+		{raw: "chat_id:", wantKind: channel.DirectoryEntryGroup},
+		{raw: "Alice"},
+	}
+	for _, tc := range cases {
+		id, kind, ok := parseFeishuDirectoryID(tc.raw)
+		if ok != tc.wantOK || id != tc.wantID || kind != tc.wantKind {
+			t.Fatalf("unexpected result for %q: %q %q %v", tc.raw, id, kind, ok)
+		}
+	}
+}
+
+func TestFeishuDirectoryResolveTargetWithoutLookup(t *testing.T) {
+	t.Parallel()
+
+	directory := NewFeishuAdapter(nil).Directory()
+	cfg := channel.ChannelConfig{Credentials: map[string]any{"appId": "app", "appSecret": "secret"}}
+
+	entry, err := directory.ResolveTarget(context.Background(), cfg, "ou_123", "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if entry.Kind != channel.DirectoryEntryUser || entry.ID != "open_id:ou_123" {
+		t.Fatalf("unexpected entry: %+v", entry)
+	}
+	if _, err := directory.ResolveTarget(context.Background(), cfg, "ou_123", channel.DirectoryEntryGroup); !errors.Is(err, channel.ErrDirectoryEntryNotFound) {
+		t.Fatalf("expected kind mismatch to be not found, got %v", err)
+	}
+	if _, err := directory.ResolveTarget(context.Background(), cfg, "Alice", channel.DirectoryEntryUser); !errors.Is(err, channel.ErrDirectoryNotSupported) {
+		t.Fatalf("expected user names to be unsupported, got %v", err)
+	}
+}
@@ -3,7 +3,9 @@ package feishu
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"log/slog"
 	"net/http"
 	"strings"
@@ -13,6 +15,7 @@ import (
 	lark "github.com/larksuite/oapi-sdk-go/v3"
 	larkcore "github.com/larksuite/oapi-sdk-go/v3/core"
 	"github.com/larksuite/oapi-sdk-go/v3/event/dispatcher"
+	"github.com/larksuite/oapi-sdk-go/v3/event/dispatcher/callback"
 	larkim "github.com/larksuite/oapi-sdk-go/v3/service/im/v1"
 	larkws "github.com/larksuite/oapi-sdk-go/v3/ws"
 
@@ -50,6 +53,16 @@ func (a *FeishuAdapter) Descriptor() channel.Descriptor {
 			RichText:    true,
 			Attachments: true,
 			Reply:       true,
+			Edit:        true,
+			Unsend:      true,
+			Streaming:   true,
+			Presence:    true,
+			Buttons:     true,
+		},
+		OutboundPolicy: channel.OutboundPolicy{
+			// Feishu limits how often a single message may be edited.
+			StreamEditIntervalMs: 2000,
+			StreamMaxEdits:       18,
 		},
 		ConfigSchema: channel.ConfigSchema{
 			Version: 1,
@@ -127,6 +140,8 @@ func (a *FeishuAdapter) Connect(ctx context.Context, cfg channel.ChannelConfig,
 		return nil, err
 	}
 	connCtx, cancel := context.WithCancel(ctx)
+	apiClient := lark.NewClient(feishuCfg.AppID, feishuCfg.AppSecret)
+	botOpenID := a.resolveBotOpenID(connCtx, apiClient, cfg)
 	eventDispatcher := dispatcher.NewEventDispatcher(
 		feishuCfg.VerificationToken,
 		feishuCfg.EncryptKey,
@@ -138,6 +153,7 @@ func (a *FeishuAdapter) Connect(ctx context.Context, cfg channel.ChannelConfig,
 			return nil
 		}
 		msg.BotID = cfg.BotID
+		msg.Metadata = feishuAddressMetadata(event.Event.Message, botOpenID)
 		if a.logger != nil {
 			a.logger.Info(
 				"inbound received",
@@ -148,6 +164,11 @@ func (a *FeishuAdapter) Connect(ctx context.Context, cfg channel.ChannelConfig,
 			)
 		}
 		go func() {
+			// The event does not name the sender of the replied message, so it is looked up
+			// for the group policy.
+			if msg.Message.Reply != nil && channel.IsGroupConversation(msg.Conversation.Type) {
+				msg.Metadata[channel.MetadataKeyReplyToBot] = a.isMessageFromApp(connCtx, apiClient, feishuCfg.AppID, msg.Message.Reply.MessageID)
+			}
 			if err := handler(connCtx, cfg, msg); err != nil && a.logger != nil {
 				a.logger.Error("handle inbound failed", slog.String("config_id", cfg.ID), slog.Any("error", err))
 			}
@@ -157,6 +178,28 @@ func (a *FeishuAdapter) Connect(ctx context.Context, cfg channel.ChannelConfig,
 	eventDispatcher.OnP2MessageReadV1(func(_ context.Context, _ *larkim.P2MessageReadV1) error {
 		return nil
 	})
+	eventDispatcher.OnP2CardActionTrigger(func(_ context.Context, event *callback.CardActionTriggerEvent) (*callback.CardActionTriggerResponse, error) {
+		msg, ok := extractFeishuCardAction(event)
+		if !ok {
+			return &callback.CardActionTriggerResponse{}, nil
+		}
+		msg.BotID = cfg.BotID
+		if a.logger != nil {
+			a.logger.Info(
+				"inbound action received",
+				slog.String("config_id", cfg.ID),
+				slog.String("session_id", msg.SessionID()),
+				slog.String("chat_type", msg.Conversation.Type),
+				slog.String("value", common.SummarizeText(msg.Action.Value)),
+			)
+		}
+		go func() {
+			if err := handler(connCtx, cfg, msg); err != nil && a.logger != nil {
+				a.logger.Error("handle inbound failed", slog.String("config_id", cfg.ID), slog.Any("error", err))
+			}
+		}()
+		return &callback.CardActionTriggerResponse{}, nil
+	})
 
 	client := larkws.NewClient(
 		feishuCfg.AppID,
@@ -166,66 +209,130 @@ func (a *FeishuAdapter) Connect(ctx context.Context, cfg channel.ChannelConfig,
 		larkws.WithLogLevel(larkcore.LogLevelDebug),
 	)
 
+	stop := func(context.Context) error {
+		cancel()
+		return nil
+	}
+	conn := channel.NewConnection(cfg, stop)
+
 	go func() {
-		if err := client.Start(connCtx); err != nil && a.logger != nil {
+		err := client.Start(connCtx)
+		if connCtx.Err() != nil {
+			return
+		}
+		if err == nil {
+			err = errors.New("feishu websocket client exited")
+		}
+		if a.logger != nil {
 			a.logger.Error("client start failed", slog.String("config_id", cfg.ID), slog.Any("error", err))
 		}
+		conn.Fail(err)
 	}()
+	return conn, nil
+}
 
-	stop := func(context.Context) error {
-		cancel()
-		return nil
+// resolveBotOpenID returns the open_id of the bot, which mentions of it carry. The self
+// identity of the config takes precedence over asking Feishu.
+func (a *FeishuAdapter) resolveBotOpenID(ctx context.Context, client *lark.Client, cfg channel.ChannelConfig) string {
+	if openID := strings.TrimSpace(channel.ReadString(cfg.SelfIdentity, "open_id")); openID != "" {
+		return openID
+	}
+	resp, err := client.Get(ctx, "/open-apis/bot/v3/info", nil, larkcore.AccessTokenTypeTenant)
+	if err == nil {
+		var info struct {
+			Code int    `json:"code"`
+			Msg  string `json:"msg"`
+			Bot  struct {
+				OpenID string `json:"open_id"`
+			} `json:"bot"`
+		}
+		if err = json.Unmarshal(resp.RawBody, &info); err == nil && info.Code != 0 {
+			err = fmt.Errorf("%s (code: %d)", info.Msg, info.Code)
+		}
+		if err == nil {
+			return strings.TrimSpace(info.Bot.OpenID)
+		}
+	}
+	if a.logger != nil {
+		a.logger.Warn("get bot info failed; mentions of the bot are not detected", slog.String("config_id", cfg.ID), slog.Any("error", err))
+	}
+	return ""
+}
+
+// isMessageFromApp reports whether the app sent a message.
+func (a *FeishuAdapter) isMessageFromApp(ctx context.Context, client *lark.Client, appID, messageID string) bool {
+	resp, err := client.Im.V1.Message.Get(ctx, larkim.NewGetMessageReqBuilder().MessageId(messageID).Build())
+	if err == nil && !resp.Success() {
+		err = fmt.Errorf("%s (code: %d)", resp.Msg, resp.Code)
+	}
+	if err != nil {
+		if a.logger != nil {
+			a.logger.Warn("get replied message failed", slog.String("message_id", messageID), slog.Any("error", err))
+		}
+		return false
+	}
+	if resp.Data == nil || len(resp.Data.Items) == 0 || resp.Data.Items[0] == nil {
+		return false
+	}
+	return isAppSender(resp.Data.Items[0].Sender, appID)
+}
+
+func isAppSender(sender *larkim.Sender, appID string) bool {
+	if sender == nil || sender.SenderType == nil || sender.Id == nil {
+		return false
+	}
+	return *sender.SenderType == "app" && strings.TrimSpace(*sender.Id) == appID
+}
+
+// feishuAddressMetadata records whether a message @mentions the bot. Whether it replies to
+// the bot is filled in once the replied message is looked up.
+func feishuAddressMetadata(message *larkim.EventMessage, botOpenID string) map[string]any {
+	mentioned := false
+	if message != nil && botOpenID != "" {
+		for _, mention := range message.Mentions {
+			if mention != nil && mention.Id != nil && mention.Id.OpenId != nil && strings.TrimSpace(*mention.Id.OpenId) == botOpenID {
+				mentioned = true
+			}
+		}
+	}
+	return map[string]any{
+		channel.MetadataKeyMentioned:  mentioned,
+		channel.MetadataKeyReplyToBot: false,
 	}
-	return channel.NewConnection(cfg, stop), nil
 }
 
 // Send delivers an outbound message to Feishu, handling attachments, rich text, and replies.
-func (a *FeishuAdapter) Send(ctx context.Context, cfg channel.ChannelConfig, msg channel.OutboundMessage) error {
+func (a *FeishuAdapter) Send(ctx context.Context, cfg channel.ChannelConfig, msg channel.OutboundMessage) (channel.SendResult, error) {
 	feishuCfg, err := parseConfig(cfg.Credentials)
 	if err != nil {
 		if a.logger != nil {
 			a.logger.Error("decode config failed", slog.String("config_id", cfg.ID), slog.Any("error", err))
 		}
-		return err
+		return channel.SendResult{}, err
 	}
 
 	receiveID, receiveType, err := resolveFeishuReceiveID(strings.TrimSpace(msg.Target))
 	if err != nil {
-		return err
+		return channel.SendResult{}, err
 	}
 
 	client := lark.NewClient(feishuCfg.AppID, feishuCfg.AppSecret)
 
 	if len(msg.Message.Attachments) > 0 {
+		var result channel.SendResult
 		for _, att := range msg.Message.Attachments {
-			if err := a.sendAttachment(ctx, client, receiveID, receiveType, att, msg.Message.Text); err != nil {
-				return err
+			messageID, err := a.sendAttachment(ctx, client, receiveID, receiveType, att, msg.Message.Text)
+			if err != nil {
+				return result, err
 			}
+			result.MessageIDs = appendMessageID(result.MessageIDs, messageID)
 		}
-		return nil
+		return result, nil
 	}
 
-	var msgType string
-	var content string
-
-	if len(msg.Message.Parts) > 1 {
-		msgType = larkim.MsgTypePost
-		postContent, postErr := a.buildPostContent(msg.Message)
-		if postErr != nil {
-			return postErr
-		}
-		content = postContent
-	} else {
-		msgType = larkim.MsgTypeText
-		text := strings.TrimSpace(msg.Message.PlainText())
-		if text == "" {
-			return fmt.Errorf("message is required")
-		}
-		payload, marshalErr := json.Marshal(map[string]string{"text": text})
-		if marshalErr != nil {
-			return fmt.Errorf("failed to marshal text content: %w", marshalErr)
-		}
-		content = string(payload)
+	msgType, content, err := a.buildContent(msg.Message, receiveType)
+	if err != nil {
+		return channel.SendResult{}, err
 	}
 
 	reqBuilder := larkim.NewCreateMessageReqBodyBuilder().
@@ -249,11 +356,245 @@ func (a *FeishuAdapter) Send(ctx context.Context, cfg channel.ChannelConfig, msg
 				Build()).
 			Build()
 		resp, err := client.Im.V1.Message.Reply(ctx, replyReq)
-		return a.handleReplyResponse(cfg.ID, resp, err)
+		if err := a.handleReplyResponse(cfg.ID, resp, err); err != nil {
+			return channel.SendResult{}, err
+		}
+		return channel.SendResult{MessageIDs: appendMessageID(nil, replyMessageID(resp))}, nil
+	}
+
+	resp, err := client.Im.V1.Message.Create(ctx, req)
+	if err := a.handleResponse(cfg.ID, resp, err); err != nil {
+		return channel.SendResult{}, err
 	}
+	return channel.SendResult{MessageIDs: appendMessageID(nil, createdMessageID(resp))}, nil
+}
 
+// SendEditable sends a text or post message and returns its message ID so it can be edited later.
+func (a *FeishuAdapter) SendEditable(ctx context.Context, cfg channel.ChannelConfig, msg channel.OutboundMessage) (string, error) {
+	feishuCfg, err := parseConfig(cfg.Credentials)
+	if err != nil {
+		return "", err
+	}
+	receiveID, receiveType, err := resolveFeishuReceiveID(strings.TrimSpace(msg.Target))
+	if err != nil {
+		return "", err
+	}
+	msgType, content, err := a.buildContent(msg.Message, receiveType)
+	if err != nil {
+		return "", err
+	}
+	client := lark.NewClient(feishuCfg.AppID, feishuCfg.AppSecret)
+	if msg.Message.Reply != nil && msg.Message.Reply.MessageID != "" {
+		replyReq := larkim.NewReplyMessageReqBuilder().
+			MessageId(msg.Message.Reply.MessageID).
+			Body(larkim.NewReplyMessageReqBodyBuilder().
+				Content(content).
+				MsgType(msgType).
+				Uuid(uuid.NewString()).
+				Build()).
+			Build()
+		resp, err := client.Im.V1.Message.Reply(ctx, replyReq)
+		if err := a.handleReplyResponse(cfg.ID, resp, err); err != nil {
+			return "", err
+		}
+		messageID := replyMessageID(resp)
+		if messageID == "" {
+			return "", fmt.Errorf("feishu reply returned no message id")
+		}
+		return messageID, nil
+	}
+	req := larkim.NewCreateMessageReqBuilder().
+		ReceiveIdType(receiveType).
+		Body(larkim.NewCreateMessageReqBodyBuilder().
+			ReceiveId(receiveID).
+			MsgType(msgType).
+			Content(content).
+			Uuid(uuid.NewString()).
+			Build()).
+		Build()
 	resp, err := client.Im.V1.Message.Create(ctx, req)
-	return a.handleResponse(cfg.ID, resp, err)
+	if err := a.handleResponse(cfg.ID, resp, err); err != nil {
+		return "", err
+	}
+	messageID := createdMessageID(resp)
+	if messageID == "" {
+		return "", fmt.Errorf("feishu send returned no message id")
+	}
+	return messageID, nil
+}
+
+// Edit replaces the content of a previously sent Feishu text or post message.
+func (a *FeishuAdapter) Edit(ctx context.Context, cfg channel.ChannelConfig, target, messageID string, msg channel.Message) error {
+	feishuCfg, err := parseConfig(cfg.Credentials)
+	if err != nil {
+		return err
+	}
+	if strings.TrimSpace(messageID) == "" {
+		return fmt.Errorf("feishu message id is required")
+	}
+	// Text and post messages cannot be turned into cards, so edits carry no buttons.
+	msg.Actions = nil
+	msgType, content, err := a.buildContent(msg, "")
+	if err != nil {
+		return err
+	}
+	client := lark.NewClient(feishuCfg.AppID, feishuCfg.AppSecret)
+	req := larkim.NewUpdateMessageReqBuilder().
+		MessageId(strings.TrimSpace(messageID)).
+		Body(larkim.NewUpdateMessageReqBodyBuilder().
+			MsgType(msgType).
+			Content(content).
+			Build()).
+		Build()
+	resp, err := client.Im.V1.Message.Update(ctx, req)
+	if err != nil {
+		return err
+	}
+	if !resp.Success() {
+		return fmt.Errorf("feishu edit failed: %s (code: %d)", resp.Msg, resp.Code)
+	}
+	return nil
+}
+
+// Unsend recalls a message the bot has sent. Feishu only allows recalling recent messages.
+func (a *FeishuAdapter) Unsend(ctx context.Context, cfg channel.ChannelConfig, target, messageID string) error {
+	feishuCfg, err := parseConfig(cfg.Credentials)
+	if err != nil {
+		return err
+	}
+	if strings.TrimSpace(messageID) == "" {
+		return fmt.Errorf("feishu message id is required")
+	}
+	client := lark.NewClient(feishuCfg.AppID, feishuCfg.AppSecret)
+	req := larkim.NewDeleteMessageReqBuilder().
+		MessageId(strings.TrimSpace(messageID)).
+		Build()
+	resp, err := client.Im.V1.Message.Delete(ctx, req)
+	if err != nil {
+		return err
+	}
+	if !resp.Success() {
+		return fmt.Errorf("feishu recall failed: %s (code: %d)", resp.Msg, resp.Code)
+	}
+	return nil
+}
+
+// presenceEmoji is the reaction added to an inbound message while its reply is prepared.
+const presenceEmoji = "Typing"
+
+// SendPresence reacts to the inbound message so the sender can see the bot is working on it.
+// It returns the reaction ID, which ClearPresence uses to remove the reaction again.
+func (a *FeishuAdapter) SendPresence(ctx context.Context, cfg channel.ChannelConfig, msg channel.InboundMessage) (string, error) {
+	feishuCfg, err := parseConfig(cfg.Credentials)
+	if err != nil {
+		return "", err
+	}
+	messageID := strings.TrimSpace(msg.Message.ID)
+	if messageID == "" {
+		return "", fmt.Errorf("feishu message id is required")
+	}
+	client := lark.NewClient(feishuCfg.AppID, feishuCfg.AppSecret)
+	req := larkim.NewCreateMessageReactionReqBuilder().
+		MessageId(messageID).
+		Body(larkim.NewCreateMessageReactionReqBodyBuilder().
+			ReactionType(larkim.NewEmojiBuilder().EmojiType(presenceEmoji).Build()).
+			Build()).
+		Build()
+	resp, err := client.Im.V1.MessageReaction.Create(ctx, req)
+	if err != nil {
+		return "", err
+	}
+	if !resp.Success() {
+		return "", fmt.Errorf("feishu add reaction failed: %s (code: %d)", resp.Msg, resp.Code)
+	}
+	if resp.Data == nil || resp.Data.ReactionId == nil {
+		return "", nil
+	}
+	return *resp.Data.ReactionId, nil
+}
+
+// ClearPresence removes the reaction added by SendPresence.
+func (a *FeishuAdapter) ClearPresence(ctx context.Context, cfg channel.ChannelConfig, msg channel.InboundMessage, presenceID string) error {
+	if strings.TrimSpace(presenceID) == "" {
+		return nil
+	}
+	feishuCfg, err := parseConfig(cfg.Credentials)
+	if err != nil {
+		return err
+	}
+	client := lark.NewClient(feishuCfg.AppID, feishuCfg.AppSecret)
+	req := larkim.NewDeleteMessageReactionReqBuilder().
+		MessageId(strings.TrimSpace(msg.Message.ID)).
+		ReactionId(strings.TrimSpace(presenceID)).
+		Build()
+	resp, err := client.Im.V1.MessageReaction.Delete(ctx, req)
+	if err != nil {
+		return err
+	}
+	if !resp.Success() {
+		return fmt.Errorf("feishu remove reaction failed: %s (code: %d)", resp.Msg, resp.Code)
+	}
+	return nil
+}
+
+// DownloadAttachment fetches an inbound image or file through the message resource API.
+// Inbound attachment URLs are resource keys that are only valid together with their message ID.
+func (a *FeishuAdapter) DownloadAttachment(ctx context.Context, cfg channel.ChannelConfig, att channel.Attachment) (io.ReadCloser, error) {
+	feishuCfg, err := parseConfig(cfg.Credentials)
+	if err != nil {
+		return nil, err
+	}
+	key := strings.TrimSpace(att.URL)
+	messageID, _ := att.Metadata["message_id"].(string)
+	if key == "" || strings.TrimSpace(messageID) == "" {
+		return nil, fmt.Errorf("feishu attachment key and message id are required")
+	}
+	resourceType := "file"
+	if att.Type == channel.AttachmentImage {
+		resourceType = "image"
+	}
+	client := lark.NewClient(feishuCfg.AppID, feishuCfg.AppSecret)
+	req := larkim.NewGetMessageResourceReqBuilder().
+		MessageId(strings.TrimSpace(messageID)).
+		FileKey(key).
+		Type(resourceType).
+		Build()
+	resp, err := client.Im.V1.MessageResource.Get(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	if !resp.Success() {
+		return nil, fmt.Errorf("feishu download attachment failed: %s (code: %d)", resp.Msg, resp.Code)
+	}
+	return io.NopCloser(resp.File), nil
+}
+
+// buildContent renders a message as a Feishu text message, as a post when it has multiple parts,
+// or as an interactive card when it has actions.
+func (a *FeishuAdapter) buildContent(msg channel.Message, receiveType string) (string, string, error) {
+	if hasFeishuButtons(msg.Actions) {
+		content, err := buildCardContent(msg, receiveType)
+		if err != nil {
+			return "", "", err
+		}
+		return larkim.MsgTypeInteractive, content, nil
+	}
+	if len(msg.Parts) > 1 {
+		content, err := a.buildPostContent(msg)
+		if err != nil {
+			return "", "", err
+		}
+		return larkim.MsgTypePost, content, nil
+	}
+	text := strings.TrimSpace(msg.PlainText())
+	if text == "" {
+		return "", "", fmt.Errorf("message is required")
+	}
+	payload, err := json.Marshal(map[string]string{"text": text})
+	if err != nil {
+		return "", "", fmt.Errorf("failed to marshal text content: %w", err)
+	}
+	return larkim.MsgTypeText, string(payload), nil
 }
 
 func (a *FeishuAdapter) handleReplyResponse(configID string, resp *larkim.ReplyMessageResp, err error) error {
@@ -306,20 +647,20 @@ func (a *FeishuAdapter) handleResponse(configID string, resp *larkim.CreateMessa
 	return nil
 }
 
-func (a *FeishuAdapter) sendAttachment(ctx context.Context, client *lark.Client, receiveID, receiveType string, att channel.Attachment, text string) error {
+func (a *FeishuAdapter) sendAttachment(ctx context.Context, client *lark.Client, receiveID, receiveType string, att channel.Attachment, text string) (string, error) {
 	httpReq, err := http.NewRequestWithContext(ctx, http.MethodGet, att.URL, nil)
 	if err != nil {
-		return fmt.Errorf("failed to build download request: %w", err)
+		return "", fmt.Errorf("failed to build download request: %w", err)
 	}
 	httpClient := &http.Client{Timeout: 60 * time.Second}
 	resp, err := httpClient.Do(httpReq)
 	if err != nil {
-		return fmt.Errorf("failed to download attachment: %w", err)
+		return "", fmt.Errorf("failed to download attachment: %w", err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("failed to download attachment, status: %d", resp.StatusCode)
+		return "", fmt.Errorf("failed to download attachment, status: %d", resp.StatusCode)
 	}
 
 	var msgType string
@@ -334,14 +675,14 @@ func (a *FeishuAdapter) sendAttachment(ctx context.Context, client *lark.Client,
 			Build()
 		uploadResp, err := client.Im.V1.Image.Create(ctx, uploadReq)
 		if err != nil {
-			return fmt.Errorf("failed to upload image: %w", err)
+			return "", fmt.Errorf("failed to upload image: %w", err)
 		}
 		if uploadResp == nil || !uploadResp.Success() {
 			code, msg := 0, ""
 			if uploadResp != nil {
 				code, msg = uploadResp.Code, uploadResp.Msg
 			}
-			return fmt.Errorf("failed to upload image: %s (code: %d)", msg, code)
+			return "", fmt.Errorf("failed to upload image: %s (code: %d)", msg, code)
 		}
 		msgType = larkim.MsgTypeImage
 		contentMap = map[string]string{"image_key": *uploadResp.Data.ImageKey}
@@ -356,14 +697,14 @@ func (a *FeishuAdapter) sendAttachment(ctx context.Context, client *lark.Client,
 			Build()
 		uploadResp, err := client.Im.V1.File.Create(ctx, uploadReq)
 		if err != nil {
-			return fmt.Errorf("failed to upload file: %w", err)
+			return "", fmt.Errorf("failed to upload file: %w", err)
 		}
 		if uploadResp == nil || !uploadResp.Success() {
 			code, msg := 0, ""
 			if uploadResp != nil {
 				code, msg = uploadResp.Code, uploadResp.Msg
 			}
-			return fmt.Errorf("failed to upload file: %s (code: %d)", msg, code)
+			return "", fmt.Errorf("failed to upload file: %s (code: %d)", msg, code)
 		}
 		msgType = larkim.MsgTypeFile
 		contentMap = map[string]string{"file_key": *uploadResp.Data.FileKey}
@@ -371,7 +712,7 @@ func (a *FeishuAdapter) sendAttachment(ctx context.Context, client *lark.Client,
 
 	content, err := json.Marshal(contentMap)
 	if err != nil {
-		return fmt.Errorf("failed to marshal content: %w", err)
+		return "", fmt.Errorf("failed to marshal content: %w", err)
 	}
 	req := larkim.NewCreateMessageReqBuilder().
 		ReceiveIdType(receiveType).
@@ -384,7 +725,32 @@ func (a *FeishuAdapter) sendAttachment(ctx context.Context, client *lark.Client,
 		Build()
 
 	sendResp, err := client.Im.V1.Message.Create(ctx, req)
-	return a.handleResponse("", sendResp, err)
+	if err := a.handleResponse("", sendResp, err); err != nil {
+		return "", err
+	}
+	return createdMessageID(sendResp), nil
+}
+
+func createdMessageID(resp *larkim.CreateMessageResp) string {
+	if resp == nil || resp.Data == nil || resp.Data.MessageId == nil {
+		return ""
+	}
+	return *resp.Data.MessageId
+}
+
+func replyMessageID(resp *larkim.ReplyMessageResp) string {
+	if resp == nil || resp.Data == nil || resp.Data.MessageId == nil {
+		return ""
+	}
+	return *resp.Data.MessageId
+}
+
+// appendMessageID skips empty IDs, which Feishu omits for some message types.
+func appendMessageID(ids []string, id string) []string {
+	if id == "" {
+		return ids
+	}
+	return append(ids, id)
 }
 
 // resolveFeishuFileType maps MIME type and filename to a Feishu file type constant.
@@ -434,6 +800,115 @@ func (a *FeishuAdapter) buildPostContent(msg channel.Message) (string, error) {
 	return string(payload), err
 }
 
+func hasFeishuButtons(actions []channel.Action) bool {
+	for _, action := range actions {
+		if strings.TrimSpace(action.Value) != "" || strings.TrimSpace(action.URL) != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// buildCardContent renders the message text in a card with one button per action. Card
+// callbacks do not report the chat type, so each button value carries it along with the
+// action value and label.
+func buildCardContent(msg channel.Message, receiveType string) (string, error) {
+	chatType := "p2p"
+	if receiveType == larkim.ReceiveIdTypeChatId {
+		chatType = "group"
+	}
+	buttons := make([]map[string]any, 0, len(msg.Actions))
+	for _, action := range msg.Actions {
+		label := strings.TrimSpace(action.Label)
+		value := strings.TrimSpace(action.Value)
+		if label == "" {
+			label = value
+		}
+		if label == "" {
+			continue
+		}
+		button := map[string]any{
+			"tag":  "button",
+			"text": map[string]string{"tag": "plain_text", "content": label},
+			"type": "default",
+		}
+		switch {
+		case strings.TrimSpace(action.URL) != "":
+			button["url"] = strings.TrimSpace(action.URL)
+		case value != "":
+			button["value"] = map[string]string{"value": value, "label": label, "chat_type": chatType}
+		default:
+			continue
+		}
+		buttons = append(buttons, button)
+	}
+	elements := []map[string]any{}
+	if text := strings.TrimSpace(msg.PlainText()); text != "" {
+		elements = append(elements, map[string]any{"tag": "markdown", "content": text})
+	}
+	elements = append(elements, map[string]any{"tag": "action", "actions": buttons})
+	payload, err := json.Marshal(map[string]any{
+		"config":   map[string]any{"wide_screen_mode": true},
+		"elements": elements,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal card content: %w", err)
+	}
+	return string(payload), nil
+}
+
+// extractFeishuCardAction converts a card button press into an inbound action. Presses on
+// buttons that were not sent by buildCardContent are ignored.
+func extractFeishuCardAction(event *callback.CardActionTriggerEvent) (channel.InboundMessage, bool) {
+	if event == nil || event.Event == nil || event.Event.Action == nil || event.Event.Operator == nil || event.Event.Context == nil {
+		return channel.InboundMessage{}, false
+	}
+	value, _ := event.Event.Action.Value["value"].(string)
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return channel.InboundMessage{}, false
+	}
+	label, _ := event.Event.Action.Value["label"].(string)
+	chatType, _ := event.Event.Action.Value["chat_type"].(string)
+	chatType = strings.TrimSpace(chatType)
+	if chatType == "" {
+		chatType = "p2p"
+	}
+	openID := strings.TrimSpace(event.Event.Operator.OpenID)
+	chatID := strings.TrimSpace(event.Event.Context.OpenChatID)
+	attrs := map[string]string{}
+	if openID != "" {
+		attrs["open_id"] = openID
+	}
+	if event.Event.Operator.UserID != nil && strings.TrimSpace(*event.Event.Operator.UserID) != "" {
+		attrs["user_id"] = strings.TrimSpace(*event.Event.Operator.UserID)
+	}
+	replyTo := openID
+	if chatType != "p2p" && chatID != "" {
+		replyTo = "chat_id:" + chatID
+	}
+	return channel.InboundMessage{
+		Channel:     Type,
+		ReplyTarget: replyTo,
+		Sender: channel.Identity{
+			ExternalID:  openID,
+			DisplayName: openID,
+			Attributes:  attrs,
+		},
+		Conversation: channel.Conversation{
+			ID:   chatID,
+			Type: chatType,
+		},
+		ReceivedAt: time.Now().UTC(),
+		Source:     "feishu",
+		Action: &channel.InboundAction{
+			Value:     value,
+			Label:     strings.TrimSpace(label),
+			MessageID: strings.TrimSpace(event.Event.Context.OpenMessageID),
+		},
+	}, true
+}
+
 func extractFeishuInbound(event *larkim.P2MessageReceiveV1) channel.InboundMessage {
 	if event == nil || event.Event == nil || event.Event.Message == nil {
 		return channel.InboundMessage{Channel: Type}
@@ -459,22 +934,43 @@ func extractFeishuInbound(event *larkim.P2MessageReceiveV1) channel.InboundMessa
 		case larkim.MsgTypeImage:
 			if key, ok := contentMap["image_key"].(string); ok {
 				msg.Attachments = append(msg.Attachments, channel.Attachment{
-					Type: channel.AttachmentImage,
-					URL:  key,
+					Type:     channel.AttachmentImage,
+					URL:      key,
+					Metadata: map[string]any{"message_id": msg.ID},
 				})
 			}
 		case larkim.MsgTypeFile, larkim.MsgTypeAudio:
 			if key, ok := contentMap["file_key"].(string); ok {
 				name, _ := contentMap["file_name"].(string)
 				msg.Attachments = append(msg.Attachments, channel.Attachment{
-					Type: channel.AttachmentType(*message.MessageType),
-					URL:  key,
-					Name: name,
+					Type:     channel.AttachmentType(*message.MessageType),
+					URL:      key,
+					Name:     name,
+					Metadata: map[string]any{"message_id": msg.ID},
 				})
 			}
 		}
 	}
 
+	for _, mention := range message.Mentions {
+		if mention == nil {
+			continue
+		}
+		name := ""
+		if mention.Name != nil {
+			name = strings.TrimSpace(*mention.Name)
+		}
+		// Text mentions arrive as placeholders such as @_user_1.
+		if mention.Key != nil && *mention.Key != "" && name != "" {
+			msg.Text = strings.ReplaceAll(msg.Text, *mention.Key, "@"+name)
+		}
+		part := channel.MessagePart{Type: channel.MessagePartMention, Text: "@" + name}
+		if mention.Id != nil && mention.Id.OpenId != nil {
+			part.UserID = strings.TrimSpace(*mention.Id.OpenId)
+		}
+		msg.Parts = append(msg.Parts, part)
+	}
+
 	if message.ParentId != nil && *message.ParentId != "" {
 		msg.Reply = &channel.ReplyRef{
 			MessageID: *message.ParentId,
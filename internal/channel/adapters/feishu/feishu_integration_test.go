@@ -74,7 +74,7 @@ func TestFeishuGateway_Integration(t *testing.T) {
 			},
 		}
 
-		if err := adapter.Send(ctx, c, reply); err != nil {
+		if _, err := adapter.Send(ctx, c, reply); err != nil {
 			return fmt.Errorf("failed to send reply: %w", err)
 		}
 
@@ -87,7 +87,7 @@ func TestFeishuGateway_Integration(t *testing.T) {
 					Text: "【Memoh 集成测试】主动推送验证成功。",
 				},
 			}
-			_ = adapter.Send(context.Background(), c, pushMsg)
+			_, _ = adapter.Send(context.Background(), c, pushMsg)
 		}()
 
 		return nil
@@ -1,9 +1,13 @@
 package feishu
 
 import (
+	"encoding/json"
 	"testing"
 
+	"github.com/larksuite/oapi-sdk-go/v3/event/dispatcher/callback"
 	larkim "github.com/larksuite/oapi-sdk-go/v3/service/im/v1"
+
+	"github.com/memohai/memoh/internal/channel"
 )
 
 func TestResolveFeishuReceiveID(t *testing.T) {
@@ -119,3 +123,175 @@ func TestExtractFeishuInboundNonText(t *testing.T) {
 		t.Fatalf("expected empty text, got %s", got.Message.PlainText())
 	}
 }
+
+func TestExtractFeishuInboundMentions(t *testing.T) {
+	t.Parallel()
+
+	text := `{"text":"@_user_1 hello"}`
+	msgType := larkim.MsgTypeText
+	chatType := "group"
+	chatID := "oc_3"
+	key := "@_user_1"
+	name := "Memoh"
+	botOpenID := "ou_bot"
+	event := &larkim.P2MessageReceiveV1{
+		Event: &larkim.P2MessageReceiveV1Data{
+			Message: &larkim.EventMessage{
+				MessageType: &msgType,
+				Content:     &text,
+				ChatType:    &chatType,
+				ChatId:      &chatID,
+				Mentions: []*larkim.MentionEvent{
+					{Key: &key, Name: &name, Id: &larkim.UserId{OpenId: &botOpenID}},
+				},
+			},
+		},
+	}
+	got := extractFeishuInbound(event)
+	if got.Message.Text != "@Memoh hello" {
+		t.Fatalf("unexpected text: %q", got.Message.Text)
+	}
+	if len(got.Message.Parts) != 1 || got.Message.Parts[0].Type != channel.MessagePartMention || got.Message.Parts[0].UserID != "ou_bot" {
+		t.Fatalf("unexpected mention parts: %+v", got.Message.Parts)
+	}
+}
+
+func TestFeishuAddressMetadata(t *testing.T) {
+	t.Parallel()
+
+	key := "@_user_1"
+	userOpenID := "ou_user"
+	botOpenID := "ou_bot"
+	mentionUser := &larkim.EventMessage{Mentions: []*larkim.MentionEvent{
+		{Key: &key, Id: &larkim.UserId{OpenId: &userOpenID}},
+	}}
+	mentionBot := &larkim.EventMessage{Mentions: []*larkim.MentionEvent{
+		{Key: &key, Id: &larkim.UserId{OpenId: &userOpenID}},
+		{Key: &key, Id: &larkim.UserId{OpenId: &botOpenID}},
+	}}
+
+	cases := []struct {
+		name      string
+		message   *larkim.EventMessage
+		botOpenID string
+		want      bool
+	}{
+		{name: "bot mentioned", message: mentionBot, botOpenID: "ou_bot", want: true},
+		{name: "other user mentioned", message: mentionUser, botOpenID: "ou_bot", want: false},
+		{name: "no mentions", message: &larkim.EventMessage{}, botOpenID: "ou_bot", want: false},
+		{name: "bot open_id unknown", message: mentionBot, botOpenID: "", want: false},
+	}
+	for _, tc := range cases {
+		got := feishuAddressMetadata(tc.message, tc.botOpenID)
+		if got[channel.MetadataKeyMentioned] != tc.want {
+			t.Fatalf("%s: expected mentioned=%v, got %v", tc.name, tc.want, got[channel.MetadataKeyMentioned])
+		}
+		if got[channel.MetadataKeyReplyToBot] != false {
+			t.Fatalf("%s: expected reply_to_bot=false, got %v", tc.name, got[channel.MetadataKeyReplyToBot])
+		}
+	}
+}
+
+func TestIsAppSender(t *testing.T) {
+	t.Parallel()
+
+	app := "app"
+	user := "user"
+	appID := "cli_1"
+	otherID := "cli_2"
+	if !isAppSender(&larkim.Sender{Id: &appID, SenderType: &app}, "cli_1") {
+		t.Fatal("expected message from the app")
+	}
+	if isAppSender(&larkim.Sender{Id: &otherID, SenderType: &app}, "cli_1") {
+		t.Fatal("expected message from another app to be rejected")
+	}
+	if isAppSender(&larkim.Sender{Id: &appID, SenderType: &user}, "cli_1") {
+		t.Fatal("expected message from a user to be rejected")
+	}
+	if isAppSender(nil, "cli_1") {
+		t.Fatal("expected nil sender to be rejected")
+	}
+}
+
+func TestBuildContentCard(t *testing.T) {
+	t.Parallel()
+
+	adapter := NewFeishuAdapter(nil)
+	msgType, content, err := adapter.buildContent(channel.Message{
+		Text: "Deploy to production?",
+		Actions: []channel.Action{
+			{Type: "button", Label: "Approve", Value: "approve"},
+			{Type: "button", Label: "Docs", URL: "https://memoh.example.com/docs"},
+		},
+	}, larkim.ReceiveIdTypeChatId)
+	if err != nil {
+		t.Fatalf("build content failed: %v", err)
+	}
+	if msgType != larkim.MsgTypeInteractive {
+		t.Fatalf("expected interactive message, got %s", msgType)
+	}
+	var card struct {
+		Elements []struct {
+			Tag     string `json:"tag"`
+			Content string `json:"content"`
+			Actions []struct {
+				URL   string            `json:"url"`
+				Value map[string]string `json:"value"`
+			} `json:"actions"`
+		} `json:"elements"`
+	}
+	if err := json.Unmarshal([]byte(content), &card); err != nil {
+		t.Fatalf("decode card: %v", err)
+	}
+	if len(card.Elements) != 2 || card.Elements[0].Content != "Deploy to production?" || len(card.Elements[1].Actions) != 2 {
+		t.Fatalf("unexpected card: %s", content)
+	}
+	value := card.Elements[1].Actions[0].Value
+	if value["value"] != "approve" || value["label"] != "Approve" || value["chat_type"] != "group" {
+		t.Fatalf("unexpected button value: %+v", value)
+	}
+	if card.Elements[1].Actions[1].URL != "https://memoh.example.com/docs" {
+		t.Fatalf("unexpected link button: %+v", card.Elements[1].Actions[1])
+	}
+
+	msgType, _, err = adapter.buildContent(channel.Message{Text: "hello"}, larkim.ReceiveIdTypeOpenId)
+	if err != nil || msgType != larkim.MsgTypeText {
+		t.Fatalf("expected text message, got %s %v", msgType, err)
+	}
+}
+
+func TestExtractFeishuCardAction(t *testing.T) {
+	t.Parallel()
+
+	event := &callback.CardActionTriggerEvent{
+		Event: &callback.CardActionTriggerRequest{
+			Operator: &callback.Operator{OpenID: "ou_1"},
+			Action: &callback.CallBackAction{
+				Tag:   "button",
+				Value: map[string]interface{}{"value": "approve", "label": "Approve", "chat_type": "group"},
+			},
+			Context: &callback.Context{OpenChatID: "oc_1", OpenMessageID: "om_1"},
+		},
+	}
+	got, ok := extractFeishuCardAction(event)
+	if !ok {
+		t.Fatalf("expected action")
+	}
+	if got.Action == nil || got.Action.Value != "approve" || got.Action.Label != "Approve" || got.Action.MessageID != "om_1" {
+		t.Fatalf("unexpected action: %+v", got.Action)
+	}
+	if got.ReplyTarget != "chat_id:oc_1" || got.Conversation.Type != "group" || got.Sender.ExternalID != "ou_1" {
+		t.Fatalf("unexpected inbound: %+v", got)
+	}
+
+	event.Event.Action.Value = map[string]interface{}{"value": "approve"}
+	got, _ = extractFeishuCardAction(event)
+	if got.ReplyTarget != "ou_1" || got.Conversation.Type != "p2p" {
+		t.Fatalf("expected p2p fallback, got %+v", got)
+	}
+
+	event.Event.Action.Value = map[string]interface{}{}
+	if _, ok := extractFeishuCardAction(event); ok {
+		t.Fatalf("expected presses without a value to be ignored")
+	}
+}
@@ -67,3 +67,30 @@ func (h *SessionHub) Publish(sessionID string, msg channel.OutboundMessage) {
 		}
 	}
 }
+
+// Presence states published to session subscribers while a reply is prepared.
+const (
+	PresenceTyping = "typing"
+	PresenceIdle   = "idle"
+)
+
+const presenceMetadataKey = "presence"
+
+// PublishPresence delivers a presence event to all subscribers of the given session.
+func (h *SessionHub) PublishPresence(sessionID, state string) {
+	h.Publish(sessionID, channel.OutboundMessage{
+		Target: sessionID,
+		Message: channel.Message{
+			Metadata: map[string]any{presenceMetadataKey: state},
+		},
+	})
+}
+
+// PresenceState reports the presence state carried by a message published with PublishPresence.
+func PresenceState(msg channel.OutboundMessage) (string, bool) {
+	if !msg.Message.IsEmpty() {
+		return "", false
+	}
+	state, ok := msg.Message.Metadata[presenceMetadataKey].(string)
+	return state, ok
+}
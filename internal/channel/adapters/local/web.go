@@ -33,6 +33,8 @@ func (a *WebAdapter) Descriptor() channel.Descriptor {
 			Text:        true,
 			Reply:       true,
 			Attachments: true,
+			Presence:    true,
+			Buttons:     true,
 		},
 		TargetSpec: channel.TargetSpec{
 			Format: "session_id",
@@ -44,17 +46,43 @@ func (a *WebAdapter) Descriptor() channel.Descriptor {
 }
 
 // Send publishes an outbound message to the Web session hub.
-func (a *WebAdapter) Send(ctx context.Context, cfg channel.ChannelConfig, msg channel.OutboundMessage) error {
+func (a *WebAdapter) Send(ctx context.Context, cfg channel.ChannelConfig, msg channel.OutboundMessage) (channel.SendResult, error) {
 	if a.hub == nil {
-		return fmt.Errorf("web hub not configured")
+		return channel.SendResult{}, fmt.Errorf("web hub not configured")
 	}
 	target := strings.TrimSpace(msg.Target)
 	if target == "" {
-		return fmt.Errorf("web target is required")
+		return channel.SendResult{}, fmt.Errorf("web target is required")
 	}
 	if msg.Message.IsEmpty() {
-		return fmt.Errorf("message is required")
+		return channel.SendResult{}, fmt.Errorf("message is required")
 	}
 	a.hub.Publish(target, msg)
+	return channel.SendResult{}, nil
+}
+
+// SendPresence publishes a typing event to the Web session hub.
+func (a *WebAdapter) SendPresence(ctx context.Context, cfg channel.ChannelConfig, msg channel.InboundMessage) (string, error) {
+	if a.hub == nil {
+		return "", fmt.Errorf("web hub not configured")
+	}
+	target := strings.TrimSpace(msg.ReplyTarget)
+	if target == "" {
+		return "", fmt.Errorf("web target is required")
+	}
+	a.hub.PublishPresence(target, PresenceTyping)
+	return "", nil
+}
+
+// ClearPresence publishes an idle event to the Web session hub.
+func (a *WebAdapter) ClearPresence(ctx context.Context, cfg channel.ChannelConfig, msg channel.InboundMessage, presenceID string) error {
+	if a.hub == nil {
+		return fmt.Errorf("web hub not configured")
+	}
+	target := strings.TrimSpace(msg.ReplyTarget)
+	if target == "" {
+		return fmt.Errorf("web target is required")
+	}
+	a.hub.PublishPresence(target, PresenceIdle)
 	return nil
 }
@@ -0,0 +1,334 @@
+package matrix
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	clientPrefix = "/_matrix/client/v3"
+	mediaPrefix  = "/_matrix/media/v3"
+)
+
+// txnCounter makes transaction IDs unique within the process.
+var txnCounter atomic.Uint64
+
+// restClient is a minimal Matrix client-server API client scoped to one access token.
+type restClient struct {
+	baseURL    string
+	token      string
+	httpClient *http.Client
+}
+
+func newRESTClient(baseURL, token string, httpClient *http.Client) *restClient {
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: 30 * time.Second}
+	}
+	return &restClient{
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		token:      token,
+		httpClient: httpClient,
+	}
+}
+
+type apiError struct {
+	Status  int
+	ErrCode string `json:"errcode"`
+	Message string `json:"error"`
+}
+
+func (e *apiError) Error() string {
+	if e.ErrCode == "" {
+		return fmt.Sprintf("matrix api error: status %d", e.Status)
+	}
+	return fmt.Sprintf("matrix api error: %s (%s, status: %d)", e.Message, e.ErrCode, e.Status)
+}
+
+// isNotFound reports whether err is an M_NOT_FOUND response.
+func isNotFound(err error) bool {
+	var apiErr *apiError
+	return errors.As(err, &apiErr) && (apiErr.ErrCode == "M_NOT_FOUND" || apiErr.Status == http.StatusNotFound)
+}
+
+type apiEvent struct {
+	Type           string          `json:"type"`
+	EventID        string          `json:"event_id"`
+	Sender         string          `json:"sender"`
+	OriginServerTS int64           `json:"origin_server_ts"`
+	StateKey       *string         `json:"state_key,omitempty"`
+	Content        json.RawMessage `json:"content"`
+}
+
+type apiRoomSummary struct {
+	JoinedMemberCount *int `json:"m.joined_member_count,omitempty"`
+}
+
+type apiJoinedRoom struct {
+	Summary  apiRoomSummary `json:"summary"`
+	State    apiEventList   `json:"state"`
+	Timeline apiEventList   `json:"timeline"`
+}
+
+type apiEventList struct {
+	Events []apiEvent `json:"events"`
+}
+
+type apiSyncResponse struct {
+	NextBatch string `json:"next_batch"`
+	Rooms     struct {
+		Join   map[string]apiJoinedRoom   `json:"join"`
+		Invite map[string]json.RawMessage `json:"invite"`
+	} `json:"rooms"`
+}
+
+type apiInReplyTo struct {
+	EventID string `json:"event_id"`
+}
+
+type apiRelatesTo struct {
+	RelType       string        `json:"rel_type,omitempty"`
+	EventID       string        `json:"event_id,omitempty"`
+	Key           string        `json:"key,omitempty"`
+	IsFallingBack bool          `json:"is_falling_back,omitempty"`
+	InReplyTo     *apiInReplyTo `json:"m.in_reply_to,omitempty"`
+}
+
+type apiMediaInfo struct {
+	Mimetype string `json:"mimetype,omitempty"`
+	Size     int64  `json:"size,omitempty"`
+	Width    int    `json:"w,omitempty"`
+	Height   int    `json:"h,omitempty"`
+	Duration int64  `json:"duration,omitempty"`
+}
+
+type apiMessageContent struct {
+	MsgType       string          `json:"msgtype"`
+	Body          string          `json:"body"`
+	Format        string          `json:"format,omitempty"`
+	FormattedBody string          `json:"formatted_body,omitempty"`
+	URL           string          `json:"url,omitempty"`
+	FileName      string          `json:"filename,omitempty"`
+	Info          *apiMediaInfo   `json:"info,omitempty"`
+	RelatesTo     *apiRelatesTo   `json:"m.relates_to,omitempty"`
+	NewContent    *apiMessageBody `json:"m.new_content,omitempty"`
+	Voice         *struct{}       `json:"org.matrix.msc3245.voice,omitempty"`
+}
+
+// apiMessageBody is the replacement content carried by an edit.
+type apiMessageBody struct {
+	MsgType       string `json:"msgtype"`
+	Body          string `json:"body"`
+	Format        string `json:"format,omitempty"`
+	FormattedBody string `json:"formatted_body,omitempty"`
+}
+
+type apiReaction struct {
+	RelatesTo apiRelatesTo `json:"m.relates_to"`
+}
+
+type apiMemberContent struct {
+	Membership  string `json:"membership"`
+	DisplayName string `json:"displayname"`
+	AvatarURL   string `json:"avatar_url"`
+}
+
+type apiJoinedMember struct {
+	DisplayName string `json:"display_name"`
+	AvatarURL   string `json:"avatar_url"`
+}
+
+type apiCreateRoom struct {
+	Invite   []string `json:"invite,omitempty"`
+	IsDirect bool     `json:"is_direct"`
+	Preset   string   `json:"preset,omitempty"`
+}
+
+func (c *restClient) whoami(ctx context.Context) (string, error) {
+	var resp struct {
+		UserID string `json:"user_id"`
+	}
+	if err := c.do(ctx, http.MethodGet, clientPrefix+"/account/whoami", nil, &resp); err != nil {
+		return "", err
+	}
+	if strings.TrimSpace(resp.UserID) == "" {
+		return "", fmt.Errorf("matrix whoami returned no user id")
+	}
+	return resp.UserID, nil
+}
+
+// syncFilter keeps the sync payload to room events; presence and account data are not used.
+const syncFilter = `{"presence":{"not_types":["*"]},"account_data":{"not_types":["*"]},"room":{"state":{"lazy_load_members":true},"timeline":{"limit":50},"ephemeral":{"not_types":["*"]},"account_data":{"not_types":["*"]}}}`
+
+func (c *restClient) sync(ctx context.Context, since string, timeout time.Duration) (apiSyncResponse, error) {
+	query := url.Values{}
+	query.Set("filter", syncFilter)
+	query.Set("timeout", strconv.FormatInt(timeout.Milliseconds(), 10))
+	if since != "" {
+		query.Set("since", since)
+	}
+	var resp apiSyncResponse
+	err := c.do(ctx, http.MethodGet, clientPrefix+"/sync?"+query.Encode(), nil, &resp)
+	return resp, err
+}
+
+func (c *restClient) joinRoom(ctx context.Context, roomID string) error {
+	return c.do(ctx, http.MethodPost, clientPrefix+"/rooms/"+url.PathEscape(roomID)+"/join", map[string]any{}, nil)
+}
+
+func (c *restClient) joinedRooms(ctx context.Context) ([]string, error) {
+	var resp struct {
+		JoinedRooms []string `json:"joined_rooms"`
+	}
+	err := c.do(ctx, http.MethodGet, clientPrefix+"/joined_rooms", nil, &resp)
+	return resp.JoinedRooms, err
+}
+
+func (c *restClient) joinedMembers(ctx context.Context, roomID string) (map[string]apiJoinedMember, error) {
+	var resp struct {
+		Joined map[string]apiJoinedMember `json:"joined"`
+	}
+	err := c.do(ctx, http.MethodGet, clientPrefix+"/rooms/"+url.PathEscape(roomID)+"/joined_members", nil, &resp)
+	return resp.Joined, err
+}
+
+// roomState fetches a single state event content, e.g. m.room.name.
+func (c *restClient) roomState(ctx context.Context, roomID, eventType string, out any) error {
+	path := clientPrefix + "/rooms/" + url.PathEscape(roomID) + "/state/" + url.PathEscape(eventType)
+	return c.do(ctx, http.MethodGet, path, nil, out)
+}
+
+func (c *restClient) resolveAlias(ctx context.Context, alias string) (string, error) {
+	var resp struct {
+		RoomID string `json:"room_id"`
+	}
+	if err := c.do(ctx, http.MethodGet, clientPrefix+"/directory/room/"+url.PathEscape(alias), nil, &resp); err != nil {
+		return "", err
+	}
+	return resp.RoomID, nil
+}
+
+func (c *restClient) createRoom(ctx context.Context, payload apiCreateRoom) (string, error) {
+	var resp struct {
+		RoomID string `json:"room_id"`
+	}
+	if err := c.do(ctx, http.MethodPost, clientPrefix+"/createRoom", payload, &resp); err != nil {
+		return "", err
+	}
+	return resp.RoomID, nil
+}
+
+// directRooms returns the m.direct account data mapping user IDs to DM room IDs.
+func (c *restClient) directRooms(ctx context.Context, userID string) (map[string][]string, error) {
+	rooms := map[string][]string{}
+	path := clientPrefix + "/user/" + url.PathEscape(userID) + "/account_data/m.direct"
+	if err := c.do(ctx, http.MethodGet, path, nil, &rooms); err != nil && !isNotFound(err) {
+		return nil, err
+	}
+	return rooms, nil
+}
+
+func (c *restClient) setDirectRooms(ctx context.Context, userID string, rooms map[string][]string) error {
+	path := clientPrefix + "/user/" + url.PathEscape(userID) + "/account_data/m.direct"
+	return c.do(ctx, http.MethodPut, path, rooms, nil)
+}
+
+// sendEvent sends a room event and returns its event ID.
+func (c *restClient) sendEvent(ctx context.Context, roomID, eventType string, content any) (string, error) {
+	txnID := fmt.Sprintf("memoh-%d-%d", time.Now().UnixNano(), txnCounter.Add(1))
+	path := clientPrefix + "/rooms/" + url.PathEscape(roomID) + "/send/" + url.PathEscape(eventType) + "/" + url.PathEscape(txnID)
+	var resp struct {
+		EventID string `json:"event_id"`
+	}
+	if err := c.do(ctx, http.MethodPut, path, content, &resp); err != nil {
+		return "", err
+	}
+	return resp.EventID, nil
+}
+
+// redactEvent removes the content of a room event.
+func (c *restClient) redactEvent(ctx context.Context, roomID, eventID string) error {
+	txnID := fmt.Sprintf("memoh-%d-%d", time.Now().UnixNano(), txnCounter.Add(1))
+	path := clientPrefix + "/rooms/" + url.PathEscape(roomID) + "/redact/" + url.PathEscape(eventID) + "/" + url.PathEscape(txnID)
+	return c.do(ctx, http.MethodPut, path, map[string]any{}, nil)
+}
+
+// upload stores media on the homeserver and returns its mxc:// URI.
+func (c *restClient) upload(ctx context.Context, name, contentType string, data []byte) (string, error) {
+	path := mediaPrefix + "/upload?filename=" + url.QueryEscape(name)
+	var resp struct {
+		ContentURI string `json:"content_uri"`
+	}
+	if err := c.send(ctx, http.MethodPost, path, contentType, bytes.NewReader(data), &resp); err != nil {
+		return "", err
+	}
+	if !strings.HasPrefix(resp.ContentURI, "mxc://") {
+		return "", fmt.Errorf("matrix upload returned invalid content uri")
+	}
+	return resp.ContentURI, nil
+}
+
+// downloadURL converts an mxc:// URI into an HTTP download URL on the homeserver.
+func (c *restClient) downloadURL(mxc string) string {
+	rest, ok := strings.CutPrefix(strings.TrimSpace(mxc), "mxc://")
+	if !ok {
+		return ""
+	}
+	server, mediaID, ok := strings.Cut(rest, "/")
+	if !ok || server == "" || mediaID == "" {
+		return ""
+	}
+	return c.baseURL + mediaPrefix + "/download/" + url.PathEscape(server) + "/" + url.PathEscape(mediaID)
+}
+
+func (c *restClient) do(ctx context.Context, method, path string, payload any, out any) error {
+	var body io.Reader
+	contentType := ""
+	if payload != nil {
+		data, err := json.Marshal(payload)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(data)
+		contentType = "application/json"
+	}
+	return c.send(ctx, method, path, contentType, body, out)
+}
+
+func (c *restClient) send(ctx context.Context, method, path, contentType string, body io.Reader, out any) error {
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+c.token)
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		apiErr := &apiError{Status: resp.StatusCode}
+		_ = json.Unmarshal(respBody, apiErr)
+		return apiErr
+	}
+	if out == nil || len(respBody) == 0 {
+		return nil
+	}
+	return json.Unmarshal(respBody, out)
+}
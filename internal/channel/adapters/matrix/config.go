@@ -0,0 +1,183 @@
+package matrix
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/memohai/memoh/internal/channel"
+)
+
+// Config holds the homeserver endpoint and bot credentials extracted from a channel configuration.
+type Config struct {
+	HomeserverURL string
+	AccessToken   string
+}
+
+// UserConfig holds the identifiers used to target a Matrix user or room.
+type UserConfig struct {
+	UserID string
+	RoomID string
+}
+
+func normalizeConfig(raw map[string]any) (map[string]any, error) {
+	cfg, err := parseConfig(raw)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]any{
+		"homeserverUrl": cfg.HomeserverURL,
+		"accessToken":   cfg.AccessToken,
+	}, nil
+}
+
+func normalizeUserConfig(raw map[string]any) (map[string]any, error) {
+	cfg, err := parseUserConfig(raw)
+	if err != nil {
+		return nil, err
+	}
+	result := map[string]any{}
+	if cfg.UserID != "" {
+		result["user_id"] = cfg.UserID
+	}
+	if cfg.RoomID != "" {
+		result["room_id"] = cfg.RoomID
+	}
+	return result, nil
+}
+
+func resolveTarget(raw map[string]any) (string, error) {
+	cfg, err := parseUserConfig(raw)
+	if err != nil {
+		return "", err
+	}
+	if cfg.RoomID != "" {
+		return "room:" + cfg.RoomID, nil
+	}
+	if cfg.UserID != "" {
+		return "user:" + cfg.UserID, nil
+	}
+	return "", fmt.Errorf("matrix binding is incomplete")
+}
+
+func matchBinding(raw map[string]any, criteria channel.BindingCriteria) bool {
+	cfg, err := parseUserConfig(raw)
+	if err != nil {
+		return false
+	}
+	if value := strings.TrimSpace(criteria.Attribute("user_id")); value != "" && value == cfg.UserID {
+		return true
+	}
+	if criteria.ExternalID != "" && criteria.ExternalID == cfg.UserID {
+		return true
+	}
+	return false
+}
+
+func buildUserConfig(identity channel.Identity) map[string]any {
+	result := map[string]any{}
+	if value := strings.TrimSpace(identity.Attribute("user_id")); value != "" {
+		result["user_id"] = value
+	}
+	return result
+}
+
+func parseConfig(raw map[string]any) (Config, error) {
+	homeserver := strings.TrimRight(strings.TrimSpace(channel.ReadString(raw, "homeserverUrl", "homeserver_url")), "/")
+	if homeserver == "" {
+		return Config{}, fmt.Errorf("matrix homeserverUrl is required")
+	}
+	parsed, err := url.Parse(homeserver)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return Config{}, fmt.Errorf("matrix homeserverUrl must be an http(s) URL")
+	}
+	token := strings.TrimSpace(channel.ReadString(raw, "accessToken", "access_token"))
+	if token == "" {
+		return Config{}, fmt.Errorf("matrix accessToken is required")
+	}
+	return Config{HomeserverURL: homeserver, AccessToken: token}, nil
+}
+
+func parseUserConfig(raw map[string]any) (UserConfig, error) {
+	userID := strings.TrimSpace(channel.ReadString(raw, "userId", "user_id"))
+	roomID := strings.TrimSpace(channel.ReadString(raw, "roomId", "room_id"))
+	if userID == "" && roomID == "" {
+		return UserConfig{}, fmt.Errorf("matrix user config requires user_id or room_id")
+	}
+	if userID != "" && !isMatrixID(userID, '@') {
+		return UserConfig{}, fmt.Errorf("matrix user_id must look like @user:server")
+	}
+	if roomID != "" && !isMatrixID(roomID, '!') {
+		return UserConfig{}, fmt.Errorf("matrix room_id must look like !room:server")
+	}
+	return UserConfig{UserID: userID, RoomID: roomID}, nil
+}
+
+// matrixTarget is a parsed delivery target. ThreadID is only set for room targets.
+// Room targets may carry an alias (#alias:server) that is resolved before sending.
+type matrixTarget struct {
+	Kind     string
+	ID       string
+	ThreadID string
+}
+
+func (t matrixTarget) String() string {
+	value := t.Kind + ":" + t.ID
+	if t.ThreadID != "" {
+		value += "/" + t.ThreadID
+	}
+	return value
+}
+
+// normalizeTarget converts raw targets into "room:<id|alias>[/<thread_event_id>]" or "user:<id>".
+func normalizeTarget(raw string) string {
+	target, ok := parseTarget(raw)
+	if !ok {
+		return ""
+	}
+	return target.String()
+}
+
+func parseTarget(raw string) (matrixTarget, bool) {
+	value := strings.TrimSpace(raw)
+	value = strings.TrimPrefix(value, "matrix:")
+	if value == "" {
+		return matrixTarget{}, false
+	}
+	switch {
+	case strings.HasPrefix(value, "room:"):
+		return parseRoomTarget(strings.TrimSpace(strings.TrimPrefix(value, "room:")))
+	case strings.HasPrefix(value, "user:"):
+		id := strings.TrimSpace(strings.TrimPrefix(value, "user:"))
+		if !isMatrixID(id, '@') {
+			return matrixTarget{}, false
+		}
+		return matrixTarget{Kind: "user", ID: id}, true
+	case isMatrixID(value, '@'):
+		return matrixTarget{Kind: "user", ID: value}, true
+	default:
+		return parseRoomTarget(value)
+	}
+}
+
+func parseRoomTarget(value string) (matrixTarget, bool) {
+	id, threadID, _ := strings.Cut(value, "/")
+	id = strings.TrimSpace(id)
+	threadID = strings.TrimSpace(threadID)
+	if !isMatrixID(id, '!') && !isMatrixID(id, '#') {
+		return matrixTarget{}, false
+	}
+	if threadID != "" && !strings.HasPrefix(threadID, "$") {
+		return matrixTarget{}, false
+	}
+	return matrixTarget{Kind: "room", ID: id, ThreadID: threadID}, true
+}
+
+// isMatrixID reports whether value has the sigil-prefixed "<sigil>localpart:server" shape.
+func isMatrixID(value string, sigil byte) bool {
+	if len(value) < 4 || value[0] != sigil || strings.ContainsAny(value, " /") {
+		return false
+	}
+	local, server, ok := strings.Cut(value[1:], ":")
+	return ok && local != "" && server != ""
+}
@@ -0,0 +1,105 @@
+package matrix
+
+import (
+	"testing"
+
+	"github.com/memohai/memoh/internal/channel"
+)
+
+func TestNormalizeConfig(t *testing.T) {
+	t.Parallel()
+
+	got, err := normalizeConfig(map[string]any{
+		"homeserver_url": "https://matrix.example.com/",
+		"access_token":   "syt_token",
+	})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if got["homeserverUrl"] != "https://matrix.example.com" || got["accessToken"] != "syt_token" {
+		t.Fatalf("unexpected config: %#v", got)
+	}
+}
+
+func TestNormalizeConfigValidation(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]map[string]any{
+		"missing homeserver": {"accessToken": "t"},
+		"invalid homeserver": {"homeserverUrl": "matrix.example.com", "accessToken": "t"},
+		"missing token":      {"homeserverUrl": "https://matrix.example.com"},
+	}
+	for name, raw := range cases {
+		if _, err := normalizeConfig(raw); err == nil {
+			t.Fatalf("%s: expected error", name)
+		}
+	}
+}
+
+func TestNormalizeUserConfigValidation(t *testing.T) {
+	t.Parallel()
+
+	if _, err := normalizeUserConfig(map[string]any{}); err == nil {
+		t.Fatalf("expected error for empty binding")
+	}
+	if _, err := normalizeUserConfig(map[string]any{"user_id": "alice"}); err == nil {
+		t.Fatalf("expected error for malformed user id")
+	}
+	got, err := normalizeUserConfig(map[string]any{"userId": "@alice:example.com"})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if got["user_id"] != "@alice:example.com" {
+		t.Fatalf("unexpected user config: %#v", got)
+	}
+}
+
+func TestResolveTarget(t *testing.T) {
+	t.Parallel()
+
+	target, err := resolveTarget(map[string]any{"user_id": "@alice:example.com"})
+	if err != nil || target != "user:@alice:example.com" {
+		t.Fatalf("unexpected target: %q %v", target, err)
+	}
+	target, err = resolveTarget(map[string]any{"user_id": "@alice:example.com", "room_id": "!room:example.com"})
+	if err != nil || target != "room:!room:example.com" {
+		t.Fatalf("unexpected target: %q %v", target, err)
+	}
+}
+
+func TestNormalizeTarget(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]string{
+		"room:!abc:example.com":             "room:!abc:example.com",
+		"matrix:room:!abc:example.com/$evt": "room:!abc:example.com/$evt",
+		"!abc:example.com:8448":             "room:!abc:example.com:8448",
+		"#general:example.com":              "room:#general:example.com",
+		"@alice:example.com":                "user:@alice:example.com",
+		"user:@alice:example.com":           "user:@alice:example.com",
+		"room:!abc:example.com/evt":         "",
+		"user:alice":                        "",
+		"alice":                             "",
+		"":                                  "",
+	}
+	for raw, want := range cases {
+		if got := normalizeTarget(raw); got != want {
+			t.Fatalf("normalizeTarget(%q) = %q, want %q", raw, got, want)
+		}
+	}
+}
+
+func TestMatchBinding(t *testing.T) {
+	t.Parallel()
+
+	cfg := map[string]any{"user_id": "@alice:example.com"}
+	if !matchBinding(cfg, channel.BindingCriteria{ExternalID: "@alice:example.com"}) {
+		t.Fatalf("expected external id match")
+	}
+	if !matchBinding(cfg, channel.BindingCriteria{Attributes: map[string]string{"user_id": "@alice:example.com"}}) {
+		t.Fatalf("expected user_id attribute match")
+	}
+	if matchBinding(cfg, channel.BindingCriteria{ExternalID: "@bob:example.com"}) {
+		t.Fatalf("unexpected match")
+	}
+}
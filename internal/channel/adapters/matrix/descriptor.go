@@ -0,0 +1,9 @@
+// Package matrix implements the Matrix channel adapter using the client-server sync API.
+// Encrypted rooms are not supported: m.room.encrypted events are skipped because
+// decrypting them requires a device crypto store.
+package matrix
+
+import "github.com/memohai/memoh/internal/channel"
+
+// Type is the registered ChannelType identifier for Matrix.
+const Type channel.ChannelType = "matrix"
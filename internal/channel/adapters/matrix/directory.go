@@ -0,0 +1,256 @@
+package matrix
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+
+	"github.com/memohai/memoh/internal/channel"
+)
+
+var (
+	errDirectoryNotFound  = errors.New("matrix directory entry not found")
+	errDirectoryAmbiguous = errors.New("matrix directory entry ambiguous")
+)
+
+// DirectoryAdapter implements channel.ChannelDirectoryAdapter for Matrix.
+// It is separate from MatrixAdapter because both interfaces declare ResolveTarget.
+type DirectoryAdapter struct {
+	adapter *MatrixAdapter
+}
+
+// ListPeers lists members of every room the bot has joined, filtered by query.
+func (d *DirectoryAdapter) ListPeers(ctx context.Context, cfg channel.ChannelConfig, query channel.DirectoryQuery) ([]channel.DirectoryEntry, error) {
+	rest, err := d.client(cfg)
+	if err != nil {
+		return nil, err
+	}
+	selfID, err := rest.whoami(ctx)
+	if err != nil {
+		return nil, err
+	}
+	rooms, err := rest.joinedRooms(ctx)
+	if err != nil {
+		return nil, err
+	}
+	seen := map[string]struct{}{selfID: {}}
+	results := make([]channel.DirectoryEntry, 0)
+	for _, roomID := range rooms {
+		members, err := rest.joinedMembers(ctx, roomID)
+		if err != nil {
+			if d.adapter.logger != nil {
+				d.adapter.logger.Warn("list room members failed", slog.String("room_id", roomID), slog.Any("error", err))
+			}
+			continue
+		}
+		for _, userID := range sortedKeys(members) {
+			if _, ok := seen[userID]; ok {
+				continue
+			}
+			entry := memberEntry(rest, userID, members[userID])
+			if !matchesQuery(entry, query.Query) {
+				continue
+			}
+			seen[userID] = struct{}{}
+			results = append(results, entry)
+			if query.Limit > 0 && len(results) >= query.Limit {
+				return results, nil
+			}
+		}
+	}
+	return results, nil
+}
+
+// ListGroups lists the rooms the bot has joined, filtered by query.
+func (d *DirectoryAdapter) ListGroups(ctx context.Context, cfg channel.ChannelConfig, query channel.DirectoryQuery) ([]channel.DirectoryEntry, error) {
+	rest, err := d.client(cfg)
+	if err != nil {
+		return nil, err
+	}
+	rooms, err := rest.joinedRooms(ctx)
+	if err != nil {
+		return nil, err
+	}
+	results := make([]channel.DirectoryEntry, 0, len(rooms))
+	for _, roomID := range rooms {
+		entry := d.roomEntry(ctx, rest, roomID)
+		if !matchesQuery(entry, query.Query) {
+			continue
+		}
+		results = append(results, entry)
+		if query.Limit > 0 && len(results) >= query.Limit {
+			break
+		}
+	}
+	return results, nil
+}
+
+// ListGroupMembers lists the joined members of a room.
+func (d *DirectoryAdapter) ListGroupMembers(ctx context.Context, cfg channel.ChannelConfig, groupID string, query channel.DirectoryQuery) ([]channel.DirectoryEntry, error) {
+	rest, err := d.client(cfg)
+	if err != nil {
+		return nil, err
+	}
+	target, ok := parseTarget(groupID)
+	if !ok || target.Kind != "room" {
+		return nil, fmt.Errorf("matrix group id must be a room")
+	}
+	roomID := target.ID
+	if strings.HasPrefix(roomID, "#") {
+		if roomID, err = rest.resolveAlias(ctx, roomID); err != nil {
+			return nil, err
+		}
+	}
+	members, err := rest.joinedMembers(ctx, roomID)
+	if err != nil {
+		return nil, err
+	}
+	results := make([]channel.DirectoryEntry, 0, len(members))
+	for _, userID := range sortedKeys(members) {
+		entry := memberEntry(rest, userID, members[userID])
+		if !matchesQuery(entry, query.Query) {
+			continue
+		}
+		results = append(results, entry)
+		if query.Limit > 0 && len(results) >= query.Limit {
+			break
+		}
+	}
+	return results, nil
+}
+
+// ResolveTarget resolves an ID, alias, or name to a single directory entry.
+func (d *DirectoryAdapter) ResolveTarget(ctx context.Context, cfg channel.ChannelConfig, input string, kind channel.DirectoryEntryKind) (channel.DirectoryEntry, error) {
+	trimmed := strings.TrimSpace(input)
+	if trimmed == "" {
+		return channel.DirectoryEntry{}, errDirectoryNotFound
+	}
+	if target, ok := parseTarget(trimmed); ok {
+		if target.Kind == "user" {
+			return channel.DirectoryEntry{Kind: channel.DirectoryEntryUser, ID: target.String()}, nil
+		}
+		if strings.HasPrefix(target.ID, "#") {
+			rest, err := d.client(cfg)
+			if err != nil {
+				return channel.DirectoryEntry{}, err
+			}
+			roomID, err := rest.resolveAlias(ctx, target.ID)
+			if err != nil {
+				if isNotFound(err) {
+					return channel.DirectoryEntry{}, errDirectoryNotFound
+				}
+				return channel.DirectoryEntry{}, err
+			}
+			return channel.DirectoryEntry{
+				Kind:   channel.DirectoryEntryGroup,
+				ID:     matrixTarget{Kind: "room", ID: roomID, ThreadID: target.ThreadID}.String(),
+				Handle: target.ID,
+			}, nil
+		}
+		return channel.DirectoryEntry{Kind: channel.DirectoryEntryGroup, ID: target.String()}, nil
+	}
+	var (
+		items []channel.DirectoryEntry
+		err   error
+	)
+	if kind == channel.DirectoryEntryGroup {
+		items, err = d.ListGroups(ctx, cfg, channel.DirectoryQuery{Query: trimmed})
+	} else {
+		items, err = d.ListPeers(ctx, cfg, channel.DirectoryQuery{Query: trimmed})
+	}
+	if err != nil {
+		return channel.DirectoryEntry{}, err
+	}
+	return pickSingleMatch(items, trimmed)
+}
+
+func (d *DirectoryAdapter) client(cfg channel.ChannelConfig) (*restClient, error) {
+	matrixCfg, err := parseConfig(cfg.Credentials)
+	if err != nil {
+		return nil, err
+	}
+	return d.adapter.client(matrixCfg), nil
+}
+
+// roomEntry describes a room using its name and canonical alias when they are set.
+func (d *DirectoryAdapter) roomEntry(ctx context.Context, rest *restClient, roomID string) channel.DirectoryEntry {
+	entry := channel.DirectoryEntry{
+		Kind:     channel.DirectoryEntryGroup,
+		ID:       "room:" + roomID,
+		Metadata: map[string]any{"room_id": roomID},
+	}
+	var name struct {
+		Name string `json:"name"`
+	}
+	if err := rest.roomState(ctx, roomID, "m.room.name", &name); err != nil && !isNotFound(err) && d.adapter.logger != nil {
+		d.adapter.logger.Warn("fetch room name failed", slog.String("room_id", roomID), slog.Any("error", err))
+	}
+	entry.Name = strings.TrimSpace(name.Name)
+	var alias struct {
+		Alias string `json:"alias"`
+	}
+	if err := rest.roomState(ctx, roomID, "m.room.canonical_alias", &alias); err != nil && !isNotFound(err) && d.adapter.logger != nil {
+		d.adapter.logger.Warn("fetch room alias failed", slog.String("room_id", roomID), slog.Any("error", err))
+	}
+	entry.Handle = strings.TrimSpace(alias.Alias)
+	if entry.Name == "" {
+		entry.Name = entry.Handle
+	}
+	return entry
+}
+
+func memberEntry(rest *restClient, userID string, member apiJoinedMember) channel.DirectoryEntry {
+	name := strings.TrimSpace(member.DisplayName)
+	if name == "" {
+		name = localpart(userID)
+	}
+	return channel.DirectoryEntry{
+		Kind:      channel.DirectoryEntryUser,
+		ID:        "user:" + userID,
+		Name:      name,
+		Handle:    userID,
+		AvatarURL: rest.downloadURL(member.AvatarURL),
+		Metadata:  map[string]any{"user_id": userID},
+	}
+}
+
+func sortedKeys(members map[string]apiJoinedMember) []string {
+	keys := make([]string, 0, len(members))
+	for key := range members {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	return keys
+}
+
+func matchesQuery(entry channel.DirectoryEntry, query string) bool {
+	needle := strings.ToLower(strings.TrimSpace(query))
+	if needle == "" {
+		return true
+	}
+	for _, value := range []string{entry.ID, entry.Name, entry.Handle} {
+		if strings.Contains(strings.ToLower(value), needle) {
+			return true
+		}
+	}
+	return false
+}
+
+func pickSingleMatch(items []channel.DirectoryEntry, input string) (channel.DirectoryEntry, error) {
+	if len(items) == 0 {
+		return channel.DirectoryEntry{}, errDirectoryNotFound
+	}
+	if len(items) == 1 {
+		return items[0], nil
+	}
+	lower := strings.ToLower(strings.TrimSpace(input))
+	for _, item := range items {
+		if strings.ToLower(item.Name) == lower || strings.ToLower(item.Handle) == lower {
+			return item, nil
+		}
+	}
+	return channel.DirectoryEntry{}, errDirectoryAmbiguous
+}
@@ -0,0 +1,762 @@
+package matrix
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/yuin/goldmark"
+
+	"github.com/memohai/memoh/internal/channel"
+	"github.com/memohai/memoh/internal/channel/adapters/common"
+)
+
+// maxAttachmentBytes caps the size of a single outbound attachment download.
+const maxAttachmentBytes = 25 << 20
+
+// MatrixAdapter implements the channel.Adapter, channel.Sender, and channel.Receiver interfaces for Matrix.
+type MatrixAdapter struct {
+	logger     *slog.Logger
+	httpClient *http.Client
+	mu         sync.Mutex
+	dmRooms    map[string]string // keyed by access token + user ID
+}
+
+// NewMatrixAdapter creates a MatrixAdapter with the given logger.
+func NewMatrixAdapter(log *slog.Logger) *MatrixAdapter {
+	if log == nil {
+		log = slog.Default()
+	}
+	return &MatrixAdapter{
+		logger:     log.With(slog.String("adapter", "matrix")),
+		httpClient: &http.Client{Timeout: 30 * time.Second},
+		dmRooms:    make(map[string]string),
+	}
+}
+
+func (a *MatrixAdapter) client(cfg Config) *restClient {
+	return newRESTClient(cfg.HomeserverURL, cfg.AccessToken, a.httpClient)
+}
+
+// Type returns the Matrix channel type.
+func (a *MatrixAdapter) Type() channel.ChannelType {
+	return Type
+}
+
+// Descriptor returns the Matrix channel metadata.
+func (a *MatrixAdapter) Descriptor() channel.Descriptor {
+	return channel.Descriptor{
+		Type:        Type,
+		DisplayName: "Matrix",
+		Capabilities: channel.ChannelCapabilities{
+			Text:        true,
+			Markdown:    true,
+			Reply:       true,
+			Threads:     true,
+			Attachments: true,
+			Media:       true,
+			Reactions:   true,
+			Edit:        true,
+			Unsend:      true,
+			Streaming:   true,
+			ChatTypes:   []string{"p2p", "group"},
+		},
+		OutboundPolicy: channel.OutboundPolicy{
+			TextChunkLimit: 16000,
+			ChunkerMode:    channel.ChunkerModeMarkdown,
+		},
+		ConfigSchema: channel.ConfigSchema{
+			Version: 1,
+			Fields: map[string]channel.FieldSchema{
+				"homeserverUrl": {
+					Type:        channel.FieldString,
+					Required:    true,
+					Title:       "Homeserver URL",
+					Example:     "https://matrix.example.com",
+					Description: "Client-server API base URL of the homeserver",
+				},
+				"accessToken": {
+					Type:     channel.FieldSecret,
+					Required: true,
+					Title:    "Access Token",
+				},
+			},
+		},
+		UserConfigSchema: channel.ConfigSchema{
+			Version: 1,
+			Fields: map[string]channel.FieldSchema{
+				"user_id": {Type: channel.FieldString},
+				"room_id": {Type: channel.FieldString},
+			},
+		},
+		TargetSpec: channel.TargetSpec{
+			Format: "room:<id|alias>[/<thread_event_id>] | user:<id>",
+			Hints: []channel.TargetHint{
+				{Label: "Room ID", Example: "room:!abcdef:example.com"},
+				{Label: "Room alias", Example: "room:#general:example.com"},
+				{Label: "Thread", Example: "room:!abcdef:example.com/$threadroot"},
+				{Label: "User ID", Example: "user:@alice:example.com"},
+			},
+		},
+	}
+}
+
+// NormalizeConfig validates and normalizes a Matrix channel configuration map.
+func (a *MatrixAdapter) NormalizeConfig(raw map[string]any) (map[string]any, error) {
+	return normalizeConfig(raw)
+}
+
+// NormalizeUserConfig validates and normalizes a Matrix user-binding configuration map.
+func (a *MatrixAdapter) NormalizeUserConfig(raw map[string]any) (map[string]any, error) {
+	return normalizeUserConfig(raw)
+}
+
+// NormalizeTarget normalizes a Matrix delivery target string.
+func (a *MatrixAdapter) NormalizeTarget(raw string) string {
+	return normalizeTarget(raw)
+}
+
+// ResolveTarget derives a delivery target from a Matrix user-binding configuration.
+func (a *MatrixAdapter) ResolveTarget(userConfig map[string]any) (string, error) {
+	return resolveTarget(userConfig)
+}
+
+// MatchBinding reports whether a Matrix user binding matches the given criteria.
+func (a *MatrixAdapter) MatchBinding(config map[string]any, criteria channel.BindingCriteria) bool {
+	return matchBinding(config, criteria)
+}
+
+// BuildUserConfig constructs a Matrix user-binding config from an Identity.
+func (a *MatrixAdapter) BuildUserConfig(identity channel.Identity) map[string]any {
+	return buildUserConfig(identity)
+}
+
+// Directory returns the directory adapter that lists joined rooms and their members.
+func (a *MatrixAdapter) Directory() channel.ChannelDirectoryAdapter {
+	return &DirectoryAdapter{adapter: a}
+}
+
+// Connect follows the sync stream and forwards incoming room messages to the handler.
+func (a *MatrixAdapter) Connect(ctx context.Context, cfg channel.ChannelConfig, handler channel.InboundHandler) (channel.Connection, error) {
+	if a.logger != nil {
+		a.logger.Info("start", slog.String("config_id", cfg.ID))
+	}
+	matrixCfg, err := parseConfig(cfg.Credentials)
+	if err != nil {
+		if a.logger != nil {
+			a.logger.Error("decode config failed", slog.String("config_id", cfg.ID), slog.Any("error", err))
+		}
+		return nil, err
+	}
+	rest := a.client(matrixCfg)
+	selfID, err := rest.whoami(ctx)
+	if err != nil {
+		if a.logger != nil {
+			a.logger.Error("whoami failed", slog.String("config_id", cfg.ID), slog.Any("error", err))
+		}
+		return nil, err
+	}
+	connCtx, cancel := context.WithCancel(ctx)
+	s := newSyncer(rest, selfID, a.logger.With(slog.String("config_id", cfg.ID)), func(raw roomMessage) {
+		msg, ok := a.buildInboundMessage(cfg, rest, raw)
+		if !ok {
+			return
+		}
+		if a.logger != nil {
+			a.logger.Info(
+				"inbound received",
+				slog.String("config_id", cfg.ID),
+				slog.String("chat_type", msg.Conversation.Type),
+				slog.String("chat_id", msg.Conversation.ID),
+				slog.String("thread_id", msg.Conversation.ThreadID),
+				slog.String("user_id", msg.Sender.Attribute("user_id")),
+				slog.String("text", common.SummarizeText(msg.Message.Text)),
+			)
+		}
+		go func() {
+			if err := handler(connCtx, cfg, msg); err != nil && a.logger != nil {
+				a.logger.Error("handle inbound failed", slog.String("config_id", cfg.ID), slog.Any("error", err))
+			}
+		}()
+	})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		s.run(connCtx)
+	}()
+
+	stop := func(stopCtx context.Context) error {
+		if a.logger != nil {
+			a.logger.Info("stop", slog.String("config_id", cfg.ID))
+		}
+		cancel()
+		select {
+		case <-done:
+		case <-stopCtx.Done():
+			return stopCtx.Err()
+		}
+		return nil
+	}
+	return channel.NewConnection(cfg, stop), nil
+}
+
+func (a *MatrixAdapter) buildInboundMessage(cfg channel.ChannelConfig, rest *restClient, raw roomMessage) (channel.InboundMessage, bool) {
+	content := raw.Content
+	var threadID string
+	var reply *channel.ReplyRef
+	if rel := content.RelatesTo; rel != nil {
+		if rel.RelType == "m.thread" {
+			threadID = strings.TrimSpace(rel.EventID)
+		}
+		// In threads, m.in_reply_to is only a fallback for older clients unless is_falling_back is false.
+		if rel.InReplyTo != nil && rel.InReplyTo.EventID != "" && (threadID == "" || !rel.IsFallingBack) {
+			reply = &channel.ReplyRef{
+				MessageID: rel.InReplyTo.EventID,
+				Target:    "room:" + raw.RoomID,
+			}
+		}
+	}
+	body := content.Body
+	if content.RelatesTo != nil && content.RelatesTo.InReplyTo != nil {
+		body = stripReplyFallback(body)
+	}
+	text := strings.TrimSpace(body)
+	var attachments []channel.Attachment
+	if att, ok := buildAttachment(rest, content); ok {
+		attachments = append(attachments, att)
+		text = strings.TrimSpace(att.Caption)
+	}
+	if text == "" && len(attachments) == 0 {
+		return channel.InboundMessage{}, false
+	}
+	chatType := "group"
+	if raw.Room.isDirect() {
+		chatType = "p2p"
+	}
+	displayName := strings.TrimSpace(raw.Room.Members[raw.Event.Sender])
+	attrs := map[string]string{"user_id": raw.Event.Sender}
+	if displayName != "" {
+		attrs["username"] = displayName
+	} else {
+		displayName = localpart(raw.Event.Sender)
+	}
+	receivedAt := time.Now().UTC()
+	if raw.Event.OriginServerTS > 0 {
+		receivedAt = time.UnixMilli(raw.Event.OriginServerTS).UTC()
+	}
+	msg := channel.InboundMessage{
+		Channel: Type,
+		Message: channel.Message{
+			ID:          raw.Event.EventID,
+			Format:      channel.MessageFormatPlain,
+			Text:        text,
+			Attachments: attachments,
+			Reply:       reply,
+		},
+		BotID:       cfg.BotID,
+		ReplyTarget: matrixTarget{Kind: "room", ID: raw.RoomID, ThreadID: threadID}.String(),
+		Sender: channel.Identity{
+			ExternalID:  raw.Event.Sender,
+			DisplayName: displayName,
+			Attributes:  attrs,
+		},
+		Conversation: channel.Conversation{
+			ID:       raw.RoomID,
+			Type:     chatType,
+			Name:     raw.Room.Name,
+			ThreadID: threadID,
+		},
+		ReceivedAt: receivedAt,
+		Source:     "matrix",
+	}
+	if threadID != "" {
+		msg.Message.Thread = &channel.ThreadRef{ID: threadID}
+	}
+	if content.MsgType == "m.emote" {
+		msg.Message.Metadata = map[string]any{"msgtype": content.MsgType}
+	}
+	return msg, true
+}
+
+// stripReplyFallback removes the quoted "> " block clients prepend to replies.
+func stripReplyFallback(body string) string {
+	lines := strings.Split(body, "\n")
+	i := 0
+	for i < len(lines) && strings.HasPrefix(lines[i], ">") {
+		i++
+	}
+	if i == 0 {
+		return body
+	}
+	if i < len(lines) && strings.TrimSpace(lines[i]) == "" {
+		i++
+	}
+	return strings.Join(lines[i:], "\n")
+}
+
+// buildAttachment maps media msgtypes to an attachment. The body of a media event is its
+// file name unless a separate filename is present, in which case the body is a caption.
+func buildAttachment(rest *restClient, content apiMessageContent) (channel.Attachment, bool) {
+	var kind channel.AttachmentType
+	switch content.MsgType {
+	case "m.image":
+		kind = channel.AttachmentImage
+	case "m.video":
+		kind = channel.AttachmentVideo
+	case "m.audio":
+		kind = channel.AttachmentAudio
+		if content.Voice != nil {
+			kind = channel.AttachmentVoice
+		}
+	case "m.file":
+		kind = channel.AttachmentFile
+	default:
+		return channel.Attachment{}, false
+	}
+	downloadURL := rest.downloadURL(content.URL)
+	if downloadURL == "" {
+		return channel.Attachment{}, false
+	}
+	att := channel.Attachment{
+		Type:     kind,
+		URL:      downloadURL,
+		Name:     strings.TrimSpace(content.Body),
+		Metadata: map[string]any{"mxc_url": content.URL},
+	}
+	if name := strings.TrimSpace(content.FileName); name != "" && name != strings.TrimSpace(content.Body) {
+		att.Name = name
+		att.Caption = strings.TrimSpace(content.Body)
+	}
+	if info := content.Info; info != nil {
+		att.Mime = info.Mimetype
+		att.Size = info.Size
+		att.Width = info.Width
+		att.Height = info.Height
+		att.DurationMs = info.Duration
+		if kind == channel.AttachmentImage && info.Mimetype == "image/gif" {
+			att.Type = channel.AttachmentGIF
+		}
+	}
+	return att, true
+}
+
+func localpart(userID string) string {
+	local, _, _ := strings.Cut(strings.TrimPrefix(userID, "@"), ":")
+	return local
+}
+
+// Send delivers an outbound message to a Matrix room or user DM.
+func (a *MatrixAdapter) Send(ctx context.Context, cfg channel.ChannelConfig, msg channel.OutboundMessage) (channel.SendResult, error) {
+	matrixCfg, err := parseConfig(cfg.Credentials)
+	if err != nil {
+		if a.logger != nil {
+			a.logger.Error("decode config failed", slog.String("config_id", cfg.ID), slog.Any("error", err))
+		}
+		return channel.SendResult{}, err
+	}
+	if msg.Message.IsEmpty() {
+		return channel.SendResult{}, fmt.Errorf("message is required")
+	}
+	rest := a.client(matrixCfg)
+	target, roomID, err := a.resolveRoom(ctx, rest, matrixCfg.AccessToken, msg.Target)
+	if err != nil {
+		return channel.SendResult{}, err
+	}
+	relation := buildRelation(msg.Message, target)
+	var result channel.SendResult
+	if text := strings.TrimSpace(msg.Message.PlainText()); text != "" {
+		content, err := buildTextContent(msg.Message.Format, text)
+		if err != nil {
+			return channel.SendResult{}, err
+		}
+		content.RelatesTo = relation
+		eventID, err := rest.sendEvent(ctx, roomID, "m.room.message", content)
+		if err != nil {
+			if a.logger != nil {
+				a.logger.Error("send message failed", slog.String("config_id", cfg.ID), slog.Any("error", err))
+			}
+			return result, err
+		}
+		result.MessageIDs = append(result.MessageIDs, eventID)
+	}
+	for _, att := range msg.Message.Attachments {
+		content, err := a.buildMediaContent(ctx, rest, att)
+		if err != nil {
+			if a.logger != nil {
+				a.logger.Error("upload attachment failed", slog.String("config_id", cfg.ID), slog.Any("error", err))
+			}
+			return result, err
+		}
+		content.RelatesTo = relation
+		eventID, err := rest.sendEvent(ctx, roomID, "m.room.message", content)
+		if err != nil {
+			if a.logger != nil {
+				a.logger.Error("send attachment failed", slog.String("config_id", cfg.ID), slog.Any("error", err))
+			}
+			return result, err
+		}
+		result.MessageIDs = append(result.MessageIDs, eventID)
+	}
+	return result, nil
+}
+
+// SendEditable sends a text message and returns its event ID so it can be edited later.
+func (a *MatrixAdapter) SendEditable(ctx context.Context, cfg channel.ChannelConfig, msg channel.OutboundMessage) (string, error) {
+	matrixCfg, err := parseConfig(cfg.Credentials)
+	if err != nil {
+		return "", err
+	}
+	text := strings.TrimSpace(msg.Message.PlainText())
+	if text == "" {
+		return "", fmt.Errorf("message is required")
+	}
+	rest := a.client(matrixCfg)
+	target, roomID, err := a.resolveRoom(ctx, rest, matrixCfg.AccessToken, msg.Target)
+	if err != nil {
+		return "", err
+	}
+	content, err := buildTextContent(msg.Message.Format, text)
+	if err != nil {
+		return "", err
+	}
+	content.RelatesTo = buildRelation(msg.Message, target)
+	return rest.sendEvent(ctx, roomID, "m.room.message", content)
+}
+
+// Edit replaces the content of a previously sent Matrix message.
+func (a *MatrixAdapter) Edit(ctx context.Context, cfg channel.ChannelConfig, target, messageID string, msg channel.Message) error {
+	matrixCfg, err := parseConfig(cfg.Credentials)
+	if err != nil {
+		return err
+	}
+	if strings.TrimSpace(messageID) == "" {
+		return fmt.Errorf("matrix message id is required")
+	}
+	rest := a.client(matrixCfg)
+	_, roomID, err := a.resolveRoom(ctx, rest, matrixCfg.AccessToken, target)
+	if err != nil {
+		return err
+	}
+	replacement, err := buildTextContent(msg.Format, strings.TrimSpace(msg.PlainText()))
+	if err != nil {
+		return err
+	}
+	content := apiMessageContent{
+		MsgType: replacement.MsgType,
+		Body:    "* " + replacement.Body,
+		NewContent: &apiMessageBody{
+			MsgType:       replacement.MsgType,
+			Body:          replacement.Body,
+			Format:        replacement.Format,
+			FormattedBody: replacement.FormattedBody,
+		},
+		RelatesTo: &apiRelatesTo{RelType: "m.replace", EventID: strings.TrimSpace(messageID)},
+	}
+	if replacement.FormattedBody != "" {
+		content.Format = replacement.Format
+		content.FormattedBody = "* " + replacement.FormattedBody
+	}
+	_, err = rest.sendEvent(ctx, roomID, "m.room.message", content)
+	return err
+}
+
+// Unsend redacts a message the bot has sent.
+func (a *MatrixAdapter) Unsend(ctx context.Context, cfg channel.ChannelConfig, target, messageID string) error {
+	matrixCfg, err := parseConfig(cfg.Credentials)
+	if err != nil {
+		return err
+	}
+	if strings.TrimSpace(messageID) == "" {
+		return fmt.Errorf("matrix message id is required")
+	}
+	rest := a.client(matrixCfg)
+	_, roomID, err := a.resolveRoom(ctx, rest, matrixCfg.AccessToken, target)
+	if err != nil {
+		return err
+	}
+	return rest.redactEvent(ctx, roomID, strings.TrimSpace(messageID))
+}
+
+// React adds an emoji reaction to a Matrix message.
+func (a *MatrixAdapter) React(ctx context.Context, cfg channel.ChannelConfig, target, messageID, emoji string) error {
+	matrixCfg, err := parseConfig(cfg.Credentials)
+	if err != nil {
+		return err
+	}
+	key := strings.TrimSpace(emoji)
+	if key == "" {
+		return fmt.Errorf("matrix reaction emoji is required")
+	}
+	rest := a.client(matrixCfg)
+	_, roomID, err := a.resolveRoom(ctx, rest, matrixCfg.AccessToken, target)
+	if err != nil {
+		return err
+	}
+	_, err = rest.sendEvent(ctx, roomID, "m.reaction", apiReaction{
+		RelatesTo: apiRelatesTo{RelType: "m.annotation", EventID: strings.TrimSpace(messageID), Key: key},
+	})
+	return err
+}
+
+// resolveRoom parses a target into a room ID, resolving aliases and opening a DM for user targets.
+func (a *MatrixAdapter) resolveRoom(ctx context.Context, rest *restClient, token, raw string) (matrixTarget, string, error) {
+	target, ok := parseTarget(raw)
+	if !ok {
+		return matrixTarget{}, "", fmt.Errorf("matrix target must be room:<id|alias> or user:<id>")
+	}
+	if target.Kind == "room" {
+		if strings.HasPrefix(target.ID, "#") {
+			roomID, err := rest.resolveAlias(ctx, target.ID)
+			if err != nil {
+				return matrixTarget{}, "", err
+			}
+			return target, roomID, nil
+		}
+		return target, target.ID, nil
+	}
+	roomID, err := a.directRoom(ctx, rest, token, target.ID)
+	if err != nil {
+		return matrixTarget{}, "", err
+	}
+	return target, roomID, nil
+}
+
+// directRoom returns the DM room shared with userID, reusing rooms recorded in m.direct
+// and creating (and recording) a new one otherwise.
+func (a *MatrixAdapter) directRoom(ctx context.Context, rest *restClient, token, userID string) (string, error) {
+	key := token + ":" + userID
+	a.mu.Lock()
+	cached, ok := a.dmRooms[key]
+	a.mu.Unlock()
+	if ok {
+		return cached, nil
+	}
+	selfID, err := rest.whoami(ctx)
+	if err != nil {
+		return "", err
+	}
+	direct, err := rest.directRooms(ctx, selfID)
+	if err != nil {
+		return "", err
+	}
+	roomID := ""
+	if candidates := direct[userID]; len(candidates) > 0 {
+		joined, err := rest.joinedRooms(ctx)
+		if err != nil {
+			return "", err
+		}
+		for _, candidate := range candidates {
+			for _, id := range joined {
+				if id == candidate {
+					roomID = candidate
+					break
+				}
+			}
+			if roomID != "" {
+				break
+			}
+		}
+	}
+	if roomID == "" {
+		roomID, err = rest.createRoom(ctx, apiCreateRoom{
+			Invite:   []string{userID},
+			IsDirect: true,
+			Preset:   "trusted_private_chat",
+		})
+		if err != nil {
+			return "", err
+		}
+		direct[userID] = append(direct[userID], roomID)
+		if err := rest.setDirectRooms(ctx, selfID, direct); err != nil && a.logger != nil {
+			a.logger.Warn("update m.direct failed", slog.String("room_id", roomID), slog.Any("error", err))
+		}
+	}
+	a.mu.Lock()
+	a.dmRooms[key] = roomID
+	a.mu.Unlock()
+	return roomID, nil
+}
+
+// buildRelation threads the message under the explicit thread or the target thread and
+// marks replies. Inside a thread the reply link doubles as the fallback for older clients.
+func buildRelation(msg channel.Message, target matrixTarget) *apiRelatesTo {
+	threadID := target.ThreadID
+	if msg.Thread != nil && strings.TrimSpace(msg.Thread.ID) != "" {
+		threadID = strings.TrimSpace(msg.Thread.ID)
+	}
+	replyID := ""
+	if msg.Reply != nil {
+		replyID = strings.TrimSpace(msg.Reply.MessageID)
+	}
+	if threadID != "" {
+		relation := &apiRelatesTo{RelType: "m.thread", EventID: threadID}
+		if replyID != "" {
+			relation.InReplyTo = &apiInReplyTo{EventID: replyID}
+		} else {
+			relation.IsFallingBack = true
+			relation.InReplyTo = &apiInReplyTo{EventID: threadID}
+		}
+		return relation
+	}
+	if replyID != "" {
+		return &apiRelatesTo{InReplyTo: &apiInReplyTo{EventID: replyID}}
+	}
+	return nil
+}
+
+// buildTextContent renders markdown into the org.matrix.custom.html formatted body.
+func buildTextContent(format channel.MessageFormat, text string) (apiMessageContent, error) {
+	content := apiMessageContent{MsgType: "m.text", Body: text}
+	if format != channel.MessageFormatMarkdown {
+		return content, nil
+	}
+	var rendered bytes.Buffer
+	if err := goldmark.Convert([]byte(text), &rendered); err != nil {
+		return apiMessageContent{}, fmt.Errorf("render markdown: %w", err)
+	}
+	content.Format = "org.matrix.custom.html"
+	content.FormattedBody = strings.TrimSpace(rendered.String())
+	return content, nil
+}
+
+// buildMediaContent uploads an attachment unless it already references homeserver media.
+func (a *MatrixAdapter) buildMediaContent(ctx context.Context, rest *restClient, att channel.Attachment) (apiMessageContent, error) {
+	name := strings.TrimSpace(att.Name)
+	mime := strings.TrimSpace(att.Mime)
+	mxc, _ := att.Metadata["mxc_url"].(string)
+	if strings.HasPrefix(att.URL, "mxc://") {
+		mxc = att.URL
+	}
+	var size int64
+	if mxc == "" {
+		data, detected, err := a.downloadAttachment(ctx, att)
+		if err != nil {
+			return apiMessageContent{}, err
+		}
+		if mime == "" {
+			mime = detected
+		}
+		if name == "" {
+			name = attachmentFileName(att)
+		}
+		mxc, err = rest.upload(ctx, name, mime, data)
+		if err != nil {
+			return apiMessageContent{}, err
+		}
+		size = int64(len(data))
+	}
+	if name == "" {
+		name = attachmentFileName(att)
+	}
+	content := apiMessageContent{
+		MsgType: mediaMsgType(att.Type),
+		Body:    name,
+		URL:     mxc,
+		Info: &apiMediaInfo{
+			Mimetype: mime,
+			Size:     size,
+			Width:    att.Width,
+			Height:   att.Height,
+			Duration: att.DurationMs,
+		},
+	}
+	if caption := strings.TrimSpace(att.Caption); caption != "" {
+		content.FileName = name
+		content.Body = caption
+	}
+	if att.Type == channel.AttachmentVoice {
+		content.Voice = &struct{}{}
+	}
+	return content, nil
+}
+
+func mediaMsgType(kind channel.AttachmentType) string {
+	switch kind {
+	case channel.AttachmentImage, channel.AttachmentGIF:
+		return "m.image"
+	case channel.AttachmentVideo:
+		return "m.video"
+	case channel.AttachmentAudio, channel.AttachmentVoice:
+		return "m.audio"
+	default:
+		return "m.file"
+	}
+}
+
+func (a *MatrixAdapter) downloadAttachment(ctx context.Context, att channel.Attachment) ([]byte, string, error) {
+	rawURL := strings.TrimSpace(att.URL)
+	if rawURL == "" {
+		return nil, "", fmt.Errorf("attachment url is required")
+	}
+	if strings.HasPrefix(rawURL, "data:") {
+		meta, payload, ok := strings.Cut(strings.TrimPrefix(rawURL, "data:"), ",")
+		if !ok || !strings.HasSuffix(meta, ";base64") {
+			return nil, "", fmt.Errorf("unsupported data url")
+		}
+		data, err := base64.StdEncoding.DecodeString(payload)
+		if err != nil {
+			return nil, "", fmt.Errorf("decode data url: %w", err)
+		}
+		if len(data) > maxAttachmentBytes {
+			return nil, "", fmt.Errorf("attachment exceeds %d bytes", maxAttachmentBytes)
+		}
+		return data, strings.TrimSuffix(meta, ";base64"), nil
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return nil, "", err
+	}
+	resp, err := a.httpClient.Do(req)
+	if err != nil {
+		return nil, "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, "", fmt.Errorf("download attachment: status %d", resp.StatusCode)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxAttachmentBytes+1))
+	if err != nil {
+		return nil, "", err
+	}
+	if len(data) > maxAttachmentBytes {
+		return nil, "", fmt.Errorf("attachment exceeds %d bytes", maxAttachmentBytes)
+	}
+	mime := resp.Header.Get("Content-Type")
+	if mime == "" {
+		mime = http.DetectContentType(data)
+	}
+	return data, mime, nil
+}
+
+func attachmentFileName(att channel.Attachment) string {
+	if !strings.HasPrefix(att.URL, "data:") {
+		if idx := strings.LastIndex(att.URL, "/"); idx >= 0 {
+			name := att.URL[idx+1:]
+			if q := strings.IndexAny(name, "?#"); q >= 0 {
+				name = name[:q]
+			}
+			if name != "" {
+				return name
+			}
+		}
+	}
+	switch att.Type {
+	case channel.AttachmentImage:
+		return "image.png"
+	case channel.AttachmentGIF:
+		return "image.gif"
+	case channel.AttachmentVideo:
+		return "video.mp4"
+	case channel.AttachmentAudio, channel.AttachmentVoice:
+		return "audio.ogg"
+	default:
+		return "file"
+	}
+}
@@ -0,0 +1,521 @@
+package matrix
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/memohai/memoh/internal/channel"
+)
+
+// sentEvent is a room event recorded by the fake homeserver.
+type sentEvent struct {
+	RoomID  string
+	Type    string
+	Content map[string]any
+}
+
+// fakeHomeserver serves a minimal subset of the Matrix client-server API.
+type fakeHomeserver struct {
+	t      *testing.T
+	server *httptest.Server
+	batch  apiSyncResponse
+
+	mu       sync.Mutex
+	sent     []sentEvent
+	joined   []string
+	created  []apiCreateRoom
+	direct   map[string][]string
+	uploads  int
+	redacted []string
+}
+
+func newFakeHomeserver(t *testing.T, batch apiSyncResponse) *fakeHomeserver {
+	t.Helper()
+	f := &fakeHomeserver{t: t, batch: batch}
+	f.server = httptest.NewServer(http.HandlerFunc(f.serve))
+	t.Cleanup(f.server.Close)
+	return f
+}
+
+func (f *fakeHomeserver) adapter() *MatrixAdapter {
+	adapter := NewMatrixAdapter(nil)
+	adapter.httpClient = f.server.Client()
+	return adapter
+}
+
+func (f *fakeHomeserver) config() channel.ChannelConfig {
+	return channel.ChannelConfig{
+		ID:          "cfg-1",
+		BotID:       "bot-1",
+		ChannelType: Type,
+		Credentials: map[string]any{"homeserverUrl": f.server.URL, "accessToken": "token-1"},
+	}
+}
+
+func writeJSON(w http.ResponseWriter, status int, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
+
+func notFound(w http.ResponseWriter) {
+	writeJSON(w, http.StatusNotFound, map[string]string{"errcode": "M_NOT_FOUND", "error": "not found"})
+}
+
+func (f *fakeHomeserver) serve(w http.ResponseWriter, r *http.Request) {
+	if r.Header.Get("Authorization") != "Bearer token-1" {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"errcode": "M_UNKNOWN_TOKEN", "error": "Invalid access token"})
+		return
+	}
+	path := r.URL.EscapedPath()
+	unescape := func(s string) string {
+		v, _ := url.PathUnescape(s)
+		return v
+	}
+	switch {
+	case path == clientPrefix+"/account/whoami":
+		writeJSON(w, http.StatusOK, map[string]string{"user_id": "@bot:example.com"})
+	case path == clientPrefix+"/sync":
+		f.serveSync(w, r)
+	case r.Method == http.MethodPost && strings.HasSuffix(path, "/join"):
+		roomID := unescape(strings.TrimSuffix(strings.TrimPrefix(path, clientPrefix+"/rooms/"), "/join"))
+		f.mu.Lock()
+		f.joined = append(f.joined, roomID)
+		f.mu.Unlock()
+		writeJSON(w, http.StatusOK, map[string]string{"room_id": roomID})
+	case r.Method == http.MethodPut && strings.Contains(path, "/send/"):
+		parts := strings.Split(strings.TrimPrefix(path, clientPrefix+"/rooms/"), "/")
+		var content map[string]any
+		_ = json.NewDecoder(r.Body).Decode(&content)
+		f.mu.Lock()
+		f.sent = append(f.sent, sentEvent{RoomID: unescape(parts[0]), Type: unescape(parts[2]), Content: content})
+		id := len(f.sent)
+		f.mu.Unlock()
+		writeJSON(w, http.StatusOK, map[string]string{"event_id": "$sent" + strings.Repeat("x", id)})
+	case r.Method == http.MethodPut && strings.Contains(path, "/redact/"):
+		parts := strings.Split(strings.TrimPrefix(path, clientPrefix+"/rooms/"), "/")
+		f.mu.Lock()
+		f.redacted = append(f.redacted, unescape(parts[0])+"/"+unescape(parts[2]))
+		f.mu.Unlock()
+		writeJSON(w, http.StatusOK, map[string]string{"event_id": "$redaction"})
+	case path == clientPrefix+"/joined_rooms":
+		writeJSON(w, http.StatusOK, map[string][]string{"joined_rooms": {"!dm:example.com", "!grp:example.com"}})
+	case strings.HasSuffix(path, "/joined_members"):
+		roomID := unescape(strings.TrimSuffix(strings.TrimPrefix(path, clientPrefix+"/rooms/"), "/joined_members"))
+		members := map[string]apiJoinedMember{
+			"@bot:example.com":   {DisplayName: "Memoh"},
+			"@alice:example.com": {DisplayName: "Alice", AvatarURL: "mxc://example.com/avatar"},
+		}
+		if roomID == "!grp:example.com" {
+			members["@bob:example.com"] = apiJoinedMember{DisplayName: "Bob"}
+			members["@carol:example.com"] = apiJoinedMember{}
+		}
+		writeJSON(w, http.StatusOK, map[string]any{"joined": members})
+	case path == clientPrefix+"/rooms/"+url.PathEscape("!grp:example.com")+"/state/m.room.name":
+		writeJSON(w, http.StatusOK, map[string]string{"name": "General"})
+	case path == clientPrefix+"/rooms/"+url.PathEscape("!grp:example.com")+"/state/m.room.canonical_alias":
+		writeJSON(w, http.StatusOK, map[string]string{"alias": "#general:example.com"})
+	case strings.Contains(path, "/state/"):
+		notFound(w)
+	case strings.HasPrefix(path, clientPrefix+"/directory/room/"):
+		if unescape(strings.TrimPrefix(path, clientPrefix+"/directory/room/")) != "#general:example.com" {
+			notFound(w)
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]string{"room_id": "!grp:example.com"})
+	case path == clientPrefix+"/createRoom":
+		var body apiCreateRoom
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		f.mu.Lock()
+		f.created = append(f.created, body)
+		f.mu.Unlock()
+		writeJSON(w, http.StatusOK, map[string]string{"room_id": "!new:example.com"})
+	case strings.HasSuffix(path, "/account_data/m.direct"):
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		if r.Method == http.MethodPut {
+			_ = json.NewDecoder(r.Body).Decode(&f.direct)
+			writeJSON(w, http.StatusOK, map[string]any{})
+			return
+		}
+		if f.direct == nil {
+			notFound(w)
+			return
+		}
+		writeJSON(w, http.StatusOK, f.direct)
+	case path == mediaPrefix+"/upload":
+		_, _ = io.Copy(io.Discard, r.Body)
+		f.mu.Lock()
+		f.uploads++
+		f.mu.Unlock()
+		writeJSON(w, http.StatusOK, map[string]string{"content_uri": "mxc://example.com/uploaded"})
+	default:
+		writeJSON(w, http.StatusNotFound, map[string]string{"errcode": "M_UNRECOGNIZED", "error": "Unrecognized request"})
+	}
+}
+
+func (f *fakeHomeserver) serveSync(w http.ResponseWriter, r *http.Request) {
+	switch r.URL.Query().Get("since") {
+	case "":
+		// The initial batch carries history that must not be replayed, plus a pending invite.
+		initial := apiSyncResponse{NextBatch: "s1"}
+		initial.Rooms.Invite = map[string]json.RawMessage{"!invite:example.com": json.RawMessage(`{}`)}
+		initial.Rooms.Join = map[string]apiJoinedRoom{
+			"!dm:example.com": {Timeline: apiEventList{Events: []apiEvent{
+				textEvent("$old", "@alice:example.com", map[string]any{"msgtype": "m.text", "body": "old history"}),
+			}}},
+		}
+		writeJSON(w, http.StatusOK, initial)
+	case "s1":
+		writeJSON(w, http.StatusOK, f.batch)
+	default:
+		select {
+		case <-r.Context().Done():
+		case <-time.After(50 * time.Millisecond):
+		}
+		writeJSON(w, http.StatusOK, apiSyncResponse{NextBatch: "s2"})
+	}
+}
+
+func textEvent(id, sender string, content map[string]any) apiEvent {
+	raw, _ := json.Marshal(content)
+	return apiEvent{Type: "m.room.message", EventID: id, Sender: sender, OriginServerTS: 1735787045000, Content: raw}
+}
+
+func memberEvent(userID, displayName string) apiEvent {
+	raw, _ := json.Marshal(apiMemberContent{Membership: "join", DisplayName: displayName})
+	return apiEvent{Type: "m.room.member", Sender: userID, StateKey: &userID, Content: raw}
+}
+
+func intPtr(v int) *int {
+	return &v
+}
+
+func TestMatrixConnectReceivesMessages(t *testing.T) {
+	t.Parallel()
+
+	batch := apiSyncResponse{NextBatch: "s2"}
+	batch.Rooms.Join = map[string]apiJoinedRoom{
+		"!dm:example.com": {
+			Summary: apiRoomSummary{JoinedMemberCount: intPtr(2)},
+			State:   apiEventList{Events: []apiEvent{memberEvent("@alice:example.com", "Alice")}},
+			Timeline: apiEventList{Events: []apiEvent{
+				textEvent("$own", "@bot:example.com", map[string]any{"msgtype": "m.text", "body": "echo"}),
+				textEvent("$notice", "@alice:example.com", map[string]any{"msgtype": "m.notice", "body": "automated"}),
+				textEvent("$edit", "@alice:example.com", map[string]any{
+					"msgtype":       "m.text",
+					"body":          "* fixed",
+					"m.relates_to":  map[string]any{"rel_type": "m.replace", "event_id": "$hello"},
+					"m.new_content": map[string]any{"msgtype": "m.text", "body": "fixed"},
+				}),
+				textEvent("$hello", "@alice:example.com", map[string]any{
+					"msgtype":      "m.text",
+					"body":         "> <@bot:example.com> earlier\n\nhello bot",
+					"m.relates_to": map[string]any{"m.in_reply_to": map[string]any{"event_id": "$earlier"}},
+				}),
+				{Type: "m.room.encrypted", EventID: "$enc", Sender: "@alice:example.com", Content: json.RawMessage(`{}`)},
+			}},
+		},
+		"!grp:example.com": {
+			Summary: apiRoomSummary{JoinedMemberCount: intPtr(5)},
+			State: apiEventList{Events: []apiEvent{
+				{Type: "m.room.name", StateKey: new(string), Content: json.RawMessage(`{"name":"General"}`)},
+			}},
+			Timeline: apiEventList{Events: []apiEvent{
+				textEvent("$threaded", "@bob:example.com", map[string]any{
+					"msgtype": "m.text",
+					"body":    "in thread",
+					"m.relates_to": map[string]any{
+						"rel_type":        "m.thread",
+						"event_id":        "$root",
+						"is_falling_back": true,
+						"m.in_reply_to":   map[string]any{"event_id": "$root"},
+					},
+				}),
+				textEvent("$image", "@bob:example.com", map[string]any{
+					"msgtype":  "m.image",
+					"body":     "look at this",
+					"filename": "cat.png",
+					"url":      "mxc://example.com/cat",
+					"info":     map[string]any{"mimetype": "image/png", "size": 1234, "w": 10, "h": 20},
+				}),
+			}},
+		},
+	}
+	fake := newFakeHomeserver(t, batch)
+	adapter := fake.adapter()
+	received := make(chan channel.InboundMessage, 8)
+	conn, err := adapter.Connect(context.Background(), fake.config(), func(ctx context.Context, cfg channel.ChannelConfig, msg channel.InboundMessage) error {
+		received <- msg
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("connect failed: %v", err)
+	}
+	defer func() {
+		stopCtx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+		defer cancel()
+		if err := conn.Stop(stopCtx); err != nil {
+			t.Errorf("stop failed: %v", err)
+		}
+	}()
+
+	messages := map[string]channel.InboundMessage{}
+	for len(messages) < 3 {
+		select {
+		case msg := <-received:
+			messages[msg.Message.ID] = msg
+		case <-time.After(5 * time.Second):
+			t.Fatalf("timed out waiting for inbound messages, got %d", len(messages))
+		}
+	}
+	select {
+	case extra := <-received:
+		t.Fatalf("unexpected extra message: %#v", extra.Message)
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	hello, ok := messages["$hello"]
+	if !ok {
+		t.Fatalf("missing direct message: %#v", messages)
+	}
+	if hello.Message.Text != "hello bot" || hello.Conversation.Type != "p2p" || hello.ReplyTarget != "room:!dm:example.com" {
+		t.Fatalf("unexpected direct message: %#v", hello)
+	}
+	if hello.Sender.ExternalID != "@alice:example.com" || hello.Sender.DisplayName != "Alice" {
+		t.Fatalf("unexpected sender: %#v", hello.Sender)
+	}
+	if hello.Message.Reply == nil || hello.Message.Reply.MessageID != "$earlier" {
+		t.Fatalf("expected reply ref, got %#v", hello.Message.Reply)
+	}
+	if !hello.ReceivedAt.Equal(time.UnixMilli(1735787045000)) {
+		t.Fatalf("unexpected received at: %s", hello.ReceivedAt)
+	}
+
+	threaded := messages["$threaded"]
+	if threaded.Conversation.Type != "group" || threaded.Conversation.Name != "General" {
+		t.Fatalf("unexpected group conversation: %#v", threaded.Conversation)
+	}
+	if threaded.Message.Thread == nil || threaded.Message.Thread.ID != "$root" || threaded.Conversation.ThreadID != "$root" {
+		t.Fatalf("expected thread ref, got %#v", threaded.Message.Thread)
+	}
+	if threaded.Message.Reply != nil {
+		t.Fatalf("thread fallback should not be a reply: %#v", threaded.Message.Reply)
+	}
+	if threaded.ReplyTarget != "room:!grp:example.com/$root" {
+		t.Fatalf("unexpected reply target: %s", threaded.ReplyTarget)
+	}
+
+	image := messages["$image"]
+	if len(image.Message.Attachments) != 1 {
+		t.Fatalf("expected one attachment, got %#v", image.Message.Attachments)
+	}
+	att := image.Message.Attachments[0]
+	if att.Type != channel.AttachmentImage || att.Name != "cat.png" || att.Mime != "image/png" || att.Width != 10 {
+		t.Fatalf("unexpected attachment: %#v", att)
+	}
+	if att.URL != fake.server.URL+mediaPrefix+"/download/example.com/cat" || att.Metadata["mxc_url"] != "mxc://example.com/cat" {
+		t.Fatalf("unexpected attachment url: %#v", att)
+	}
+	if image.Message.Text != "look at this" {
+		t.Fatalf("expected caption text, got %q", image.Message.Text)
+	}
+
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+	if len(fake.joined) != 1 || fake.joined[0] != "!invite:example.com" {
+		t.Fatalf("expected invite to be joined, got %#v", fake.joined)
+	}
+}
+
+func TestMatrixSend(t *testing.T) {
+	t.Parallel()
+
+	fake := newFakeHomeserver(t, apiSyncResponse{})
+	adapter := fake.adapter()
+	ctx := context.Background()
+	result, err := adapter.Send(ctx, fake.config(), channel.OutboundMessage{
+		Target: "room:!grp:example.com/$root",
+		Message: channel.Message{
+			Format:      channel.MessageFormatMarkdown,
+			Text:        "**hi** there",
+			Attachments: []channel.Attachment{{Type: channel.AttachmentFile, URL: "data:text/plain;base64,aGVsbG8=", Name: "note.txt"}},
+		},
+	})
+	if err != nil {
+		t.Fatalf("send failed: %v", err)
+	}
+	if len(result.MessageIDs) != 2 || result.MessageIDs[0] != "$sentx" || result.MessageIDs[1] != "$sentxx" {
+		t.Fatalf("unexpected message ids: %#v", result.MessageIDs)
+	}
+	for i := 0; i < 2; i++ {
+		if _, err := adapter.Send(ctx, fake.config(), channel.OutboundMessage{
+			Target:  "user:@alice:example.com",
+			Message: channel.Message{Text: "dm", Reply: &channel.ReplyRef{MessageID: "$prev"}},
+		}); err != nil {
+			t.Fatalf("send dm failed: %v", err)
+		}
+	}
+	if _, err := adapter.Send(ctx, fake.config(), channel.OutboundMessage{
+		Target:  "room:#general:example.com",
+		Message: channel.Message{Text: "via alias"},
+	}); err != nil {
+		t.Fatalf("send via alias failed: %v", err)
+	}
+
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+	if len(fake.sent) != 5 {
+		t.Fatalf("unexpected sent events: %#v", fake.sent)
+	}
+	text := fake.sent[0]
+	if text.RoomID != "!grp:example.com" || text.Content["body"] != "**hi** there" || text.Content["format"] != "org.matrix.custom.html" {
+		t.Fatalf("unexpected text event: %#v", text)
+	}
+	if body, _ := text.Content["formatted_body"].(string); !strings.Contains(body, "<strong>hi</strong>") {
+		t.Fatalf("unexpected formatted body: %#v", text.Content["formatted_body"])
+	}
+	relation, _ := text.Content["m.relates_to"].(map[string]any)
+	if relation["rel_type"] != "m.thread" || relation["event_id"] != "$root" || relation["is_falling_back"] != true {
+		t.Fatalf("unexpected thread relation: %#v", relation)
+	}
+	file := fake.sent[1]
+	if file.Content["msgtype"] != "m.file" || file.Content["url"] != "mxc://example.com/uploaded" || file.Content["body"] != "note.txt" {
+		t.Fatalf("unexpected file event: %#v", file)
+	}
+	if fake.uploads != 1 {
+		t.Fatalf("expected one upload, got %d", fake.uploads)
+	}
+	dm := fake.sent[2]
+	if dm.RoomID != "!new:example.com" {
+		t.Fatalf("unexpected dm room: %#v", dm)
+	}
+	dmRelation, _ := dm.Content["m.relates_to"].(map[string]any)
+	if reply, _ := dmRelation["m.in_reply_to"].(map[string]any); reply["event_id"] != "$prev" {
+		t.Fatalf("unexpected reply relation: %#v", dmRelation)
+	}
+	if len(fake.created) != 1 || !fake.created[0].IsDirect || fake.created[0].Invite[0] != "@alice:example.com" {
+		t.Fatalf("expected a single direct room, got %#v", fake.created)
+	}
+	if rooms := fake.direct["@alice:example.com"]; len(rooms) != 1 || rooms[0] != "!new:example.com" {
+		t.Fatalf("expected m.direct to record the room, got %#v", fake.direct)
+	}
+	if fake.sent[4].RoomID != "!grp:example.com" {
+		t.Fatalf("expected alias to resolve, got %#v", fake.sent[4])
+	}
+}
+
+func TestMatrixEditAndReact(t *testing.T) {
+	t.Parallel()
+
+	fake := newFakeHomeserver(t, apiSyncResponse{})
+	adapter := fake.adapter()
+	ctx := context.Background()
+	if err := adapter.Edit(ctx, fake.config(), "room:!grp:example.com", "$orig", channel.Message{Text: "updated"}); err != nil {
+		t.Fatalf("edit failed: %v", err)
+	}
+	if err := adapter.React(ctx, fake.config(), "room:!grp:example.com", "$orig", "👍"); err != nil {
+		t.Fatalf("react failed: %v", err)
+	}
+
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+	if len(fake.sent) != 2 {
+		t.Fatalf("unexpected sent events: %#v", fake.sent)
+	}
+	edit := fake.sent[0]
+	relation, _ := edit.Content["m.relates_to"].(map[string]any)
+	newContent, _ := edit.Content["m.new_content"].(map[string]any)
+	if edit.Content["body"] != "* updated" || relation["rel_type"] != "m.replace" || relation["event_id"] != "$orig" || newContent["body"] != "updated" {
+		t.Fatalf("unexpected edit event: %#v", edit)
+	}
+	reaction := fake.sent[1]
+	relation, _ = reaction.Content["m.relates_to"].(map[string]any)
+	if reaction.Type != "m.reaction" || relation["rel_type"] != "m.annotation" || relation["key"] != "👍" {
+		t.Fatalf("unexpected reaction event: %#v", reaction)
+	}
+}
+
+func TestMatrixUnsend(t *testing.T) {
+	t.Parallel()
+
+	fake := newFakeHomeserver(t, apiSyncResponse{})
+	adapter := fake.adapter()
+	if err := adapter.Unsend(context.Background(), fake.config(), "room:!grp:example.com", "$orig"); err != nil {
+		t.Fatalf("unsend failed: %v", err)
+	}
+	if err := adapter.Unsend(context.Background(), fake.config(), "room:!grp:example.com", " "); err == nil {
+		t.Fatalf("expected error for empty message id")
+	}
+
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+	if len(fake.redacted) != 1 || fake.redacted[0] != "!grp:example.com/$orig" {
+		t.Fatalf("unexpected redactions: %#v", fake.redacted)
+	}
+}
+
+func TestMatrixSendAPIError(t *testing.T) {
+	t.Parallel()
+
+	fake := newFakeHomeserver(t, apiSyncResponse{})
+	cfg := fake.config()
+	cfg.Credentials = map[string]any{"homeserverUrl": fake.server.URL, "accessToken": "wrong"}
+	_, err := fake.adapter().Send(context.Background(), cfg, channel.OutboundMessage{
+		Target:  "room:!grp:example.com",
+		Message: channel.Message{Text: "hi"},
+	})
+	if err == nil || !strings.Contains(err.Error(), "M_UNKNOWN_TOKEN") {
+		t.Fatalf("expected unknown token error, got %v", err)
+	}
+}
+
+func TestMatrixDirectory(t *testing.T) {
+	t.Parallel()
+
+	fake := newFakeHomeserver(t, apiSyncResponse{})
+	dir := fake.adapter().Directory()
+	ctx := context.Background()
+	cfg := fake.config()
+
+	groups, err := dir.ListGroups(ctx, cfg, channel.DirectoryQuery{})
+	if err != nil {
+		t.Fatalf("list groups failed: %v", err)
+	}
+	if len(groups) != 2 || groups[1].ID != "room:!grp:example.com" || groups[1].Name != "General" || groups[1].Handle != "#general:example.com" {
+		t.Fatalf("unexpected groups: %#v", groups)
+	}
+	members, err := dir.ListGroupMembers(ctx, cfg, "room:#general:example.com", channel.DirectoryQuery{})
+	if err != nil {
+		t.Fatalf("list members failed: %v", err)
+	}
+	if len(members) != 4 || members[0].ID != "user:@alice:example.com" || members[0].AvatarURL == "" {
+		t.Fatalf("unexpected members: %#v", members)
+	}
+	peers, err := dir.ListPeers(ctx, cfg, channel.DirectoryQuery{})
+	if err != nil {
+		t.Fatalf("list peers failed: %v", err)
+	}
+	if len(peers) != 3 {
+		t.Fatalf("expected self to be excluded and peers deduplicated, got %#v", peers)
+	}
+	entry, err := dir.ResolveTarget(ctx, cfg, "bob", channel.DirectoryEntryUser)
+	if err != nil || entry.ID != "user:@bob:example.com" {
+		t.Fatalf("unexpected entry: %#v %v", entry, err)
+	}
+	entry, err = dir.ResolveTarget(ctx, cfg, "#general:example.com", channel.DirectoryEntryGroup)
+	if err != nil || entry.ID != "room:!grp:example.com" {
+		t.Fatalf("unexpected alias entry: %#v %v", entry, err)
+	}
+	if _, err := dir.ResolveTarget(ctx, cfg, "nobody", channel.DirectoryEntryUser); err == nil {
+		t.Fatalf("expected not found error")
+	}
+}
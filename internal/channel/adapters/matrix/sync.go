@@ -0,0 +1,198 @@
+package matrix
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"time"
+)
+
+const (
+	// syncTimeout is the long-poll duration; it must stay below the HTTP client timeout.
+	syncTimeout    = 20 * time.Second
+	syncMinBackoff = time.Second
+	syncMaxBackoff = time.Minute
+)
+
+// roomInfo is the per-room state tracked from sync responses.
+type roomInfo struct {
+	Name        string
+	MemberCount int
+	Members     map[string]string // user ID -> display name
+}
+
+// isDirect reports whether the room should be treated as a one-to-one conversation.
+func (r roomInfo) isDirect() bool {
+	return r.MemberCount > 0 && r.MemberCount <= 2
+}
+
+// roomMessage is an m.room.message event delivered to the adapter.
+type roomMessage struct {
+	RoomID  string
+	Room    roomInfo
+	Event   apiEvent
+	Content apiMessageContent
+}
+
+// syncer follows the /sync stream for one access token until its context is cancelled.
+// The first sync only establishes the stream position, so room history is not replayed.
+type syncer struct {
+	rest      *restClient
+	selfID    string
+	logger    *slog.Logger
+	onMessage func(roomMessage)
+
+	since string
+	rooms map[string]*roomInfo
+}
+
+func newSyncer(rest *restClient, selfID string, logger *slog.Logger, onMessage func(roomMessage)) *syncer {
+	return &syncer{
+		rest:      rest,
+		selfID:    selfID,
+		logger:    logger,
+		onMessage: onMessage,
+		rooms:     map[string]*roomInfo{},
+	}
+}
+
+func (s *syncer) run(ctx context.Context) {
+	backoff := syncMinBackoff
+	for {
+		timeout := syncTimeout
+		if s.since == "" {
+			timeout = 0
+		}
+		resp, err := s.rest.sync(ctx, s.since, timeout)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			if s.logger != nil {
+				s.logger.Warn("sync failed", slog.Any("error", err), slog.Duration("backoff", backoff))
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(backoff):
+			}
+			backoff *= 2
+			if backoff > syncMaxBackoff {
+				backoff = syncMaxBackoff
+			}
+			continue
+		}
+		backoff = syncMinBackoff
+		initial := s.since == ""
+		s.since = resp.NextBatch
+		s.handle(ctx, resp, initial)
+	}
+}
+
+func (s *syncer) handle(ctx context.Context, resp apiSyncResponse, initial bool) {
+	for roomID := range resp.Rooms.Invite {
+		if err := s.rest.joinRoom(ctx, roomID); err != nil {
+			if s.logger != nil {
+				s.logger.Warn("join invited room failed", slog.String("room_id", roomID), slog.Any("error", err))
+			}
+			continue
+		}
+		if s.logger != nil {
+			s.logger.Info("joined invited room", slog.String("room_id", roomID))
+		}
+	}
+	for roomID, joined := range resp.Rooms.Join {
+		room := s.room(roomID)
+		if joined.Summary.JoinedMemberCount != nil {
+			room.MemberCount = *joined.Summary.JoinedMemberCount
+		}
+		for _, ev := range joined.State.Events {
+			room.apply(ev)
+		}
+		for _, ev := range joined.Timeline.Events {
+			if ev.StateKey != nil {
+				room.apply(ev)
+				continue
+			}
+			if initial {
+				continue
+			}
+			switch ev.Type {
+			case "m.room.message":
+				s.dispatch(ctx, roomID, room, ev)
+			case "m.room.encrypted":
+				if s.logger != nil {
+					s.logger.Debug("encrypted event skipped", slog.String("room_id", roomID), slog.String("event_id", ev.EventID))
+				}
+			}
+		}
+	}
+}
+
+func (s *syncer) room(roomID string) *roomInfo {
+	room, ok := s.rooms[roomID]
+	if !ok {
+		room = &roomInfo{Members: map[string]string{}}
+		s.rooms[roomID] = room
+	}
+	return room
+}
+
+func (s *syncer) dispatch(ctx context.Context, roomID string, room *roomInfo, ev apiEvent) {
+	if ev.Sender == s.selfID {
+		return
+	}
+	var content apiMessageContent
+	if err := json.Unmarshal(ev.Content, &content); err != nil {
+		return
+	}
+	// Notices are how bots talk to each other; answering them risks reply loops.
+	if content.MsgType == "m.notice" {
+		return
+	}
+	if content.RelatesTo != nil && content.RelatesTo.RelType == "m.replace" {
+		return
+	}
+	if room.MemberCount == 0 {
+		members, err := s.rest.joinedMembers(ctx, roomID)
+		if err != nil {
+			if s.logger != nil {
+				s.logger.Warn("fetch room members failed", slog.String("room_id", roomID), slog.Any("error", err))
+			}
+		} else {
+			room.MemberCount = len(members)
+			for userID, member := range members {
+				if member.DisplayName != "" {
+					room.Members[userID] = member.DisplayName
+				}
+			}
+		}
+	}
+	s.onMessage(roomMessage{RoomID: roomID, Room: *room, Event: ev, Content: content})
+}
+
+// apply updates the tracked room state from a state event.
+func (r *roomInfo) apply(ev apiEvent) {
+	switch ev.Type {
+	case "m.room.name":
+		var content struct {
+			Name string `json:"name"`
+		}
+		if json.Unmarshal(ev.Content, &content) == nil {
+			r.Name = content.Name
+		}
+	case "m.room.member":
+		if ev.StateKey == nil {
+			return
+		}
+		var content apiMemberContent
+		if json.Unmarshal(ev.Content, &content) != nil {
+			return
+		}
+		if content.Membership == "join" && content.DisplayName != "" {
+			r.Members[*ev.StateKey] = content.DisplayName
+		} else if content.Membership != "join" {
+			delete(r.Members, *ev.StateKey)
+		}
+	}
+}
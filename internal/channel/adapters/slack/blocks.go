@@ -0,0 +1,206 @@
+package slack
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	slackapi "github.com/slack-go/slack"
+
+	"github.com/memohai/memoh/internal/channel"
+)
+
+// sectionTextLimit is the maximum length of a section block's text object.
+const sectionTextLimit = 3000
+
+// maxActionElements is the maximum number of elements in a single actions block.
+const maxActionElements = 25
+
+var (
+	markdownLinkPattern   = regexp.MustCompile(`\[([^\]]+)\]\(([^)\s]+)\)`)
+	markdownBoldPattern   = regexp.MustCompile(`\*\*([^*\n]+)\*\*|__([^_\n]+)__`)
+	markdownStrikePattern = regexp.MustCompile(`~~([^~\n]+)~~`)
+	markdownHeadPattern   = regexp.MustCompile(`(?m)^#{1,6}\s+(.+)$`)
+)
+
+// buildBlocks renders a channel message as Block Kit blocks.
+func buildBlocks(msg channel.Message) []slackapi.Block {
+	blocks := make([]slackapi.Block, 0, 4)
+	for _, chunk := range splitSectionText(renderMrkdwn(msg)) {
+		blocks = append(blocks, slackapi.NewSectionBlock(
+			slackapi.NewTextBlockObject(slackapi.MarkdownType, chunk, false, false),
+			nil, nil,
+		))
+	}
+	links := make([]string, 0)
+	for _, att := range msg.Attachments {
+		url := strings.TrimSpace(att.URL)
+		if url == "" {
+			continue
+		}
+		alt := strings.TrimSpace(att.Caption)
+		if alt == "" {
+			alt = strings.TrimSpace(att.Name)
+		}
+		if alt == "" {
+			alt = string(att.Type)
+		}
+		if att.Type == channel.AttachmentImage || att.Type == channel.AttachmentGIF {
+			blocks = append(blocks, slackapi.NewImageBlock(url, alt, "", nil))
+			continue
+		}
+		links = append(links, fmt.Sprintf("<%s|%s>", url, escapeMrkdwn(alt)))
+	}
+	if len(links) > 0 {
+		blocks = append(blocks, slackapi.NewSectionBlock(
+			slackapi.NewTextBlockObject(slackapi.MarkdownType, strings.Join(links, "\n"), false, false),
+			nil, nil,
+		))
+	}
+	elements := make([]slackapi.BlockElement, 0, len(msg.Actions))
+	for i, action := range msg.Actions {
+		if len(elements) >= maxActionElements {
+			break
+		}
+		label := strings.TrimSpace(action.Label)
+		if label == "" {
+			label = strings.TrimSpace(action.Value)
+		}
+		if label == "" {
+			continue
+		}
+		button := slackapi.NewButtonBlockElement(
+			fmt.Sprintf("action_%d", i),
+			action.Value,
+			slackapi.NewTextBlockObject(slackapi.PlainTextType, label, false, false),
+		)
+		if url := strings.TrimSpace(action.URL); url != "" {
+			button.URL = url
+		}
+		elements = append(elements, button)
+	}
+	if len(elements) > 0 {
+		blocks = append(blocks, slackapi.NewActionBlock("actions", elements...))
+	}
+	return blocks
+}
+
+// renderMrkdwn converts message text or parts into Slack's mrkdwn dialect.
+func renderMrkdwn(msg channel.Message) string {
+	if strings.TrimSpace(msg.Text) == "" && len(msg.Parts) > 0 {
+		return strings.TrimSpace(renderParts(msg.Parts))
+	}
+	text := strings.TrimSpace(msg.Text)
+	if msg.Format == channel.MessageFormatMarkdown {
+		return markdownToMrkdwn(text)
+	}
+	return escapeMrkdwn(text)
+}
+
+func renderParts(parts []channel.MessagePart) string {
+	var b strings.Builder
+	for _, part := range parts {
+		switch part.Type {
+		case channel.MessagePartText:
+			b.WriteString(applyStyles(escapeMrkdwn(part.Text), part.Styles))
+		case channel.MessagePartLink:
+			url := strings.TrimSpace(part.URL)
+			label := strings.TrimSpace(part.Text)
+			switch {
+			case url == "":
+				b.WriteString(escapeMrkdwn(label))
+			case label == "":
+				b.WriteString("<" + url + ">")
+			default:
+				b.WriteString("<" + url + "|" + escapeMrkdwn(label) + ">")
+			}
+		case channel.MessagePartCodeBlock:
+			b.WriteString("\n```\n" + strings.Trim(part.Text, "\n") + "\n```\n")
+		case channel.MessagePartMention:
+			if id := strings.TrimSpace(part.UserID); id != "" {
+				b.WriteString("<@" + id + ">")
+			} else {
+				b.WriteString(escapeMrkdwn(part.Text))
+			}
+		case channel.MessagePartEmoji:
+			name := strings.Trim(strings.TrimSpace(part.Emoji), ":")
+			if name != "" {
+				b.WriteString(":" + name + ":")
+			} else {
+				b.WriteString(part.Text)
+			}
+		}
+	}
+	return b.String()
+}
+
+func applyStyles(text string, styles []channel.MessageTextStyle) string {
+	if strings.TrimSpace(text) == "" {
+		return text
+	}
+	for _, style := range styles {
+		switch style {
+		case channel.MessageStyleBold:
+			text = "*" + text + "*"
+		case channel.MessageStyleItalic:
+			text = "_" + text + "_"
+		case channel.MessageStyleStrikethrough:
+			text = "~" + text + "~"
+		case channel.MessageStyleCode:
+			text = "`" + text + "`"
+		}
+	}
+	return text
+}
+
+// markdownToMrkdwn rewrites the common Markdown constructs that differ in mrkdwn.
+// Fenced code blocks are passed through untouched.
+func markdownToMrkdwn(text string) string {
+	segments := strings.Split(text, "```")
+	for i := range segments {
+		if i%2 == 1 {
+			continue
+		}
+		segment := escapeMrkdwn(segments[i])
+		segment = markdownLinkPattern.ReplaceAllString(segment, "<$2|$1>")
+		segment = markdownBoldPattern.ReplaceAllStringFunc(segment, func(match string) string {
+			return "*" + match[2:len(match)-2] + "*"
+		})
+		segment = markdownStrikePattern.ReplaceAllString(segment, "~$1~")
+		segment = markdownHeadPattern.ReplaceAllString(segment, "*$1*")
+		segments[i] = segment
+	}
+	return strings.Join(segments, "```")
+}
+
+func escapeMrkdwn(text string) string {
+	replacer := strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+	return replacer.Replace(text)
+}
+
+func splitSectionText(text string) []string {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return nil
+	}
+	chunks := make([]string, 0, 1)
+	for len(text) > sectionTextLimit {
+		cut := strings.LastIndex(text[:sectionTextLimit], "\n")
+		if cut <= 0 {
+			cut = sectionTextLimit
+			for cut > 0 && !isRuneStart(text[cut]) {
+				cut--
+			}
+		}
+		chunks = append(chunks, strings.TrimSpace(text[:cut]))
+		text = strings.TrimSpace(text[cut:])
+	}
+	if text != "" {
+		chunks = append(chunks, text)
+	}
+	return chunks
+}
+
+func isRuneStart(b byte) bool {
+	return b&0xC0 != 0x80
+}
@@ -0,0 +1,206 @@
+package slack
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/memohai/memoh/internal/channel"
+)
+
+// Config holds the Slack app credentials extracted from a channel configuration.
+type Config struct {
+	BotToken string
+	AppToken string
+}
+
+// UserConfig holds the identifiers used to target a Slack user or conversation.
+type UserConfig struct {
+	UserID    string
+	Username  string
+	ChannelID string
+}
+
+func normalizeConfig(raw map[string]any) (map[string]any, error) {
+	cfg, err := parseConfig(raw)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]any{
+		"botToken": cfg.BotToken,
+		"appToken": cfg.AppToken,
+	}, nil
+}
+
+func normalizeUserConfig(raw map[string]any) (map[string]any, error) {
+	cfg, err := parseUserConfig(raw)
+	if err != nil {
+		return nil, err
+	}
+	result := map[string]any{}
+	if cfg.UserID != "" {
+		result["user_id"] = cfg.UserID
+	}
+	if cfg.Username != "" {
+		result["username"] = cfg.Username
+	}
+	if cfg.ChannelID != "" {
+		result["channel_id"] = cfg.ChannelID
+	}
+	return result, nil
+}
+
+func resolveTarget(raw map[string]any) (string, error) {
+	cfg, err := parseUserConfig(raw)
+	if err != nil {
+		return "", err
+	}
+	if cfg.ChannelID != "" {
+		return "channel:" + cfg.ChannelID, nil
+	}
+	if cfg.UserID != "" {
+		return "user:" + cfg.UserID, nil
+	}
+	return "", fmt.Errorf("slack binding is incomplete")
+}
+
+func matchBinding(raw map[string]any, criteria channel.BindingCriteria) bool {
+	cfg, err := parseUserConfig(raw)
+	if err != nil {
+		return false
+	}
+	if value := strings.TrimSpace(criteria.Attribute("user_id")); value != "" && value == cfg.UserID {
+		return true
+	}
+	if value := strings.TrimSpace(criteria.Attribute("username")); value != "" && strings.EqualFold(value, cfg.Username) {
+		return true
+	}
+	if criteria.ExternalID != "" && criteria.ExternalID == cfg.UserID {
+		return true
+	}
+	return false
+}
+
+func buildUserConfig(identity channel.Identity) map[string]any {
+	result := map[string]any{}
+	if value := strings.TrimSpace(identity.Attribute("user_id")); value != "" {
+		result["user_id"] = value
+	}
+	if value := strings.TrimSpace(identity.Attribute("username")); value != "" {
+		result["username"] = value
+	}
+	return result
+}
+
+func parseConfig(raw map[string]any) (Config, error) {
+	botToken := strings.TrimSpace(channel.ReadString(raw, "botToken", "bot_token"))
+	appToken := strings.TrimSpace(channel.ReadString(raw, "appToken", "app_token"))
+	if botToken == "" {
+		return Config{}, fmt.Errorf("slack botToken is required")
+	}
+	if appToken == "" {
+		return Config{}, fmt.Errorf("slack appToken is required")
+	}
+	return Config{BotToken: botToken, AppToken: appToken}, nil
+}
+
+func parseUserConfig(raw map[string]any) (UserConfig, error) {
+	userID := strings.TrimSpace(channel.ReadString(raw, "userId", "user_id"))
+	username := strings.TrimSpace(channel.ReadString(raw, "username"))
+	channelID := strings.TrimSpace(channel.ReadString(raw, "channelId", "channel_id"))
+	if userID == "" && username == "" && channelID == "" {
+		return UserConfig{}, fmt.Errorf("slack user config requires user_id, username, or channel_id")
+	}
+	return UserConfig{
+		UserID:    userID,
+		Username:  username,
+		ChannelID: channelID,
+	}, nil
+}
+
+// slackTarget is a parsed delivery target. ThreadTS is only set for channel targets.
+type slackTarget struct {
+	Kind     string
+	ID       string
+	ThreadTS string
+}
+
+func (t slackTarget) String() string {
+	value := t.Kind + ":" + t.ID
+	if t.ThreadTS != "" {
+		value += "/" + t.ThreadTS
+	}
+	return value
+}
+
+// normalizeTarget converts raw targets into "channel:<id>[/<thread_ts>]" or "user:<id>".
+func normalizeTarget(raw string) string {
+	target, ok := parseTarget(raw)
+	if !ok {
+		return ""
+	}
+	return target.String()
+}
+
+func parseTarget(raw string) (slackTarget, bool) {
+	value := strings.TrimSpace(raw)
+	value = strings.TrimPrefix(value, "slack:")
+	if value == "" {
+		return slackTarget{}, false
+	}
+	switch {
+	case strings.HasPrefix(value, "channel:"):
+		rest := strings.TrimSpace(strings.TrimPrefix(value, "channel:"))
+		id, threadTS, _ := strings.Cut(rest, "/")
+		id = strings.TrimSpace(id)
+		if !isConversationID(id) {
+			return slackTarget{}, false
+		}
+		return slackTarget{Kind: "channel", ID: id, ThreadTS: strings.TrimSpace(threadTS)}, true
+	case strings.HasPrefix(value, "user:"):
+		id := strings.TrimSpace(strings.TrimPrefix(value, "user:"))
+		if !isUserID(id) {
+			return slackTarget{}, false
+		}
+		return slackTarget{Kind: "user", ID: id}, true
+	case strings.HasPrefix(value, "<@") && strings.HasSuffix(value, ">"):
+		id, _, _ := strings.Cut(strings.TrimSuffix(strings.TrimPrefix(value, "<@"), ">"), "|")
+		if !isUserID(id) {
+			return slackTarget{}, false
+		}
+		return slackTarget{Kind: "user", ID: id}, true
+	case strings.HasPrefix(value, "<#") && strings.HasSuffix(value, ">"):
+		id, _, _ := strings.Cut(strings.TrimSuffix(strings.TrimPrefix(value, "<#"), ">"), "|")
+		if !isConversationID(id) {
+			return slackTarget{}, false
+		}
+		return slackTarget{Kind: "channel", ID: id}, true
+	case isUserID(value):
+		return slackTarget{Kind: "user", ID: value}, true
+	case isConversationID(value):
+		return slackTarget{Kind: "channel", ID: value}, true
+	default:
+		return slackTarget{}, false
+	}
+}
+
+// isConversationID reports whether value looks like a channel, private group, or DM ID.
+func isConversationID(value string) bool {
+	return isSlackID(value, "CGD")
+}
+
+// isUserID reports whether value looks like a user or enterprise user ID.
+func isUserID(value string) bool {
+	return isSlackID(value, "UW")
+}
+
+func isSlackID(value, prefixes string) bool {
+	if len(value) < 2 || !strings.ContainsRune(prefixes, rune(value[0])) {
+		return false
+	}
+	for _, r := range value {
+		if (r < 'A' || r > 'Z') && (r < '0' || r > '9') {
+			return false
+		}
+	}
+	return true
+}
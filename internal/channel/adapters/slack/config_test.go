@@ -0,0 +1,82 @@
+package slack
+
+import (
+	"testing"
+
+	"github.com/memohai/memoh/internal/channel"
+)
+
+func TestNormalizeConfig(t *testing.T) {
+	t.Parallel()
+
+	got, err := normalizeConfig(map[string]any{
+		"bot_token": "xoxb-1",
+		"app_token": "xapp-1",
+	})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if got["botToken"] != "xoxb-1" || got["appToken"] != "xapp-1" {
+		t.Fatalf("unexpected config: %#v", got)
+	}
+}
+
+func TestNormalizeConfigRequiresTokens(t *testing.T) {
+	t.Parallel()
+
+	if _, err := normalizeConfig(map[string]any{"botToken": "xoxb-1"}); err == nil {
+		t.Fatalf("expected error for missing app token")
+	}
+	if _, err := normalizeConfig(map[string]any{"appToken": "xapp-1"}); err == nil {
+		t.Fatalf("expected error for missing bot token")
+	}
+}
+
+func TestResolveTarget(t *testing.T) {
+	t.Parallel()
+
+	target, err := resolveTarget(map[string]any{"user_id": "U123"})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if target != "user:U123" {
+		t.Fatalf("unexpected target: %s", target)
+	}
+	if _, err := resolveTarget(map[string]any{"username": "alice"}); err == nil {
+		t.Fatalf("expected error for username-only binding")
+	}
+}
+
+func TestNormalizeTarget(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]string{
+		"C123":                       "channel:C123",
+		"U123":                       "user:U123",
+		"slack:channel:C123/17.0001": "channel:C123/17.0001",
+		"<@U123|alice>":              "user:U123",
+		"<#C123|general>":            "channel:C123",
+		"channel:general":            "",
+		"user:C123":                  "",
+	}
+	for raw, want := range cases {
+		if got := normalizeTarget(raw); got != want {
+			t.Fatalf("normalizeTarget(%q) = %q, want %q", raw, got, want)
+		}
+	}
+}
+
+func TestMatchBinding(t *testing.T) {
+	t.Parallel()
+
+	cfg := map[string]any{"user_id": "U123", "username": "alice"}
+	if !matchBinding(cfg, channel.BindingCriteria{ExternalID: "U123"}) {
+		t.Fatalf("expected external id match")
+	}
+	if !matchBinding(cfg, channel.BindingCriteria{Attributes: map[string]string{"username": "ALICE"}}) {
+		t.Fatalf("expected username match")
+	}
+	if matchBinding(cfg, channel.BindingCriteria{ExternalID: "U999"}) {
+		t.Fatalf("unexpected match")
+	}
+}
@@ -0,0 +1,584 @@
+package slack
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	slackapi "github.com/slack-go/slack"
+	"github.com/slack-go/slack/slackevents"
+	"github.com/slack-go/slack/socketmode"
+
+	"github.com/memohai/memoh/internal/channel"
+	"github.com/memohai/memoh/internal/channel/adapters/common"
+)
+
+const (
+	reconnectMinBackoff = time.Second
+	reconnectMaxBackoff = time.Minute
+)
+
+// SlackAdapter implements the channel.Adapter, channel.Sender, and channel.Receiver interfaces for Slack.
+type SlackAdapter struct {
+	logger *slog.Logger
+	apiURL string
+	mu     sync.RWMutex
+	users  map[string]slackapi.User // keyed by bot token + user ID
+}
+
+// NewSlackAdapter creates a SlackAdapter with the given logger.
+func NewSlackAdapter(log *slog.Logger) *SlackAdapter {
+	if log == nil {
+		log = slog.Default()
+	}
+	return &SlackAdapter{
+		logger: log.With(slog.String("adapter", "slack")),
+		users:  make(map[string]slackapi.User),
+	}
+}
+
+func (a *SlackAdapter) newClient(cfg Config) *slackapi.Client {
+	options := []slackapi.Option{slackapi.OptionAppLevelToken(cfg.AppToken)}
+	if a.apiURL != "" {
+		options = append(options, slackapi.OptionAPIURL(a.apiURL))
+	}
+	return slackapi.New(cfg.BotToken, options...)
+}
+
+// Type returns the Slack channel type.
+func (a *SlackAdapter) Type() channel.ChannelType {
+	return Type
+}
+
+// Descriptor returns the Slack channel metadata.
+func (a *SlackAdapter) Descriptor() channel.Descriptor {
+	return channel.Descriptor{
+		Type:        Type,
+		DisplayName: "Slack",
+		Capabilities: channel.ChannelCapabilities{
+			Text:        true,
+			Markdown:    true,
+			RichText:    true,
+			Attachments: true,
+			Reply:       true,
+			Threads:     true,
+			Buttons:     true,
+			Reactions:   true,
+			Edit:        true,
+			Unsend:      true,
+			Streaming:   true,
+		},
+		OutboundPolicy: channel.OutboundPolicy{
+			TextChunkLimit: 4000,
+			ChunkerMode:    channel.ChunkerModeMarkdown,
+		},
+		ConfigSchema: channel.ConfigSchema{
+			Version: 1,
+			Fields: map[string]channel.FieldSchema{
+				"botToken": {
+					Type:        channel.FieldSecret,
+					Required:    true,
+					Title:       "Bot Token",
+					Description: "Bot user OAuth token",
+					Example:     "xoxb-...",
+				},
+				"appToken": {
+					Type:        channel.FieldSecret,
+					Required:    true,
+					Title:       "App Token",
+					Description: "App-level token with the connections:write scope, used for Socket Mode",
+					Example:     "xapp-...",
+				},
+			},
+		},
+		UserConfigSchema: channel.ConfigSchema{
+			Version: 1,
+			Fields: map[string]channel.FieldSchema{
+				"user_id":    {Type: channel.FieldString},
+				"username":   {Type: channel.FieldString},
+				"channel_id": {Type: channel.FieldString},
+			},
+		},
+		TargetSpec: channel.TargetSpec{
+			Format: "channel:<id>[/<thread_ts>] | user:<id>",
+			Hints: []channel.TargetHint{
+				{Label: "Channel ID", Example: "channel:C0123456789"},
+				{Label: "Thread", Example: "channel:C0123456789/1700000000.000100"},
+				{Label: "User ID", Example: "user:U0123456789"},
+			},
+		},
+	}
+}
+
+// NormalizeConfig validates and normalizes a Slack channel configuration map.
+func (a *SlackAdapter) NormalizeConfig(raw map[string]any) (map[string]any, error) {
+	return normalizeConfig(raw)
+}
+
+// NormalizeUserConfig validates and normalizes a Slack user-binding configuration map.
+func (a *SlackAdapter) NormalizeUserConfig(raw map[string]any) (map[string]any, error) {
+	return normalizeUserConfig(raw)
+}
+
+// NormalizeTarget normalizes a Slack delivery target string.
+func (a *SlackAdapter) NormalizeTarget(raw string) string {
+	return normalizeTarget(raw)
+}
+
+// ResolveTarget derives a delivery target from a Slack user-binding configuration.
+func (a *SlackAdapter) ResolveTarget(userConfig map[string]any) (string, error) {
+	return resolveTarget(userConfig)
+}
+
+// MatchBinding reports whether a Slack user binding matches the given criteria.
+func (a *SlackAdapter) MatchBinding(config map[string]any, criteria channel.BindingCriteria) bool {
+	return matchBinding(config, criteria)
+}
+
+// BuildUserConfig constructs a Slack user-binding config from an Identity.
+func (a *SlackAdapter) BuildUserConfig(identity channel.Identity) map[string]any {
+	return buildUserConfig(identity)
+}
+
+// Connect opens a Socket Mode connection and forwards incoming messages to the handler.
+func (a *SlackAdapter) Connect(ctx context.Context, cfg channel.ChannelConfig, handler channel.InboundHandler) (channel.Connection, error) {
+	if a.logger != nil {
+		a.logger.Info("start", slog.String("config_id", cfg.ID))
+	}
+	slackCfg, err := parseConfig(cfg.Credentials)
+	if err != nil {
+		if a.logger != nil {
+			a.logger.Error("decode config failed", slog.String("config_id", cfg.ID), slog.Any("error", err))
+		}
+		return nil, err
+	}
+	api := a.newClient(slackCfg)
+	auth, err := api.AuthTestContext(ctx)
+	if err != nil {
+		if a.logger != nil {
+			a.logger.Error("auth test failed", slog.String("config_id", cfg.ID), slog.Any("error", err))
+		}
+		return nil, err
+	}
+	client := socketmode.New(api, socketmode.OptionLog(newSlackSlogLogger(a.logger)))
+	connCtx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+
+	go func() {
+		backoff := reconnectMinBackoff
+		for {
+			err := client.RunContext(connCtx)
+			if connCtx.Err() != nil {
+				return
+			}
+			if a.logger != nil {
+				a.logger.Warn("socket mode stopped", slog.String("config_id", cfg.ID), slog.Any("error", err), slog.Duration("retry_in", backoff))
+			}
+			select {
+			case <-connCtx.Done():
+				return
+			case <-time.After(backoff):
+			}
+			backoff = min(backoff*2, reconnectMaxBackoff)
+		}
+	}()
+
+	go func() {
+		defer close(done)
+		for {
+			select {
+			case <-connCtx.Done():
+				return
+			case evt := <-client.Events:
+				switch evt.Type {
+				case socketmode.EventTypeConnected:
+					if a.logger != nil {
+						a.logger.Info("socket mode connected", slog.String("config_id", cfg.ID))
+					}
+				case socketmode.EventTypeConnectionError:
+					if a.logger != nil {
+						a.logger.Warn("socket mode connection error", slog.String("config_id", cfg.ID), slog.Any("event", evt.Data))
+					}
+				case socketmode.EventTypeEventsAPI:
+					if evt.Request != nil {
+						client.Ack(*evt.Request)
+					}
+					event, ok := evt.Data.(slackevents.EventsAPIEvent)
+					if !ok {
+						continue
+					}
+					message, ok := event.InnerEvent.Data.(*slackevents.MessageEvent)
+					if !ok {
+						continue
+					}
+					msg, ok := a.buildInboundMessage(connCtx, api, slackCfg, cfg, message, auth.UserID, auth.BotID)
+					if !ok {
+						continue
+					}
+					if a.logger != nil {
+						a.logger.Info(
+							"inbound received",
+							slog.String("config_id", cfg.ID),
+							slog.String("chat_type", msg.Conversation.Type),
+							slog.String("chat_id", msg.Conversation.ID),
+							slog.String("thread_id", msg.Conversation.ThreadID),
+							slog.String("user_id", msg.Sender.Attribute("user_id")),
+							slog.String("text", common.SummarizeText(msg.Message.Text)),
+						)
+					}
+					go func() {
+						if err := handler(connCtx, cfg, msg); err != nil && a.logger != nil {
+							a.logger.Error("handle inbound failed", slog.String("config_id", cfg.ID), slog.Any("error", err))
+						}
+					}()
+				case socketmode.EventTypeInteractive, socketmode.EventTypeSlashCommand:
+					if evt.Request != nil {
+						client.Ack(*evt.Request)
+					}
+				}
+			}
+		}
+	}()
+
+	stop := func(stopCtx context.Context) error {
+		if a.logger != nil {
+			a.logger.Info("stop", slog.String("config_id", cfg.ID))
+		}
+		cancel()
+		select {
+		case <-done:
+		case <-stopCtx.Done():
+			return stopCtx.Err()
+		}
+		return nil
+	}
+	return channel.NewConnection(cfg, stop), nil
+}
+
+func (a *SlackAdapter) buildInboundMessage(ctx context.Context, api *slackapi.Client, slackCfg Config, cfg channel.ChannelConfig, event *slackevents.MessageEvent, selfUserID, selfBotID string) (channel.InboundMessage, bool) {
+	if event == nil || event.Message == nil {
+		return channel.InboundMessage{}, false
+	}
+	switch event.SubType {
+	case "", "file_share", "thread_broadcast":
+	default:
+		return channel.InboundMessage{}, false
+	}
+	raw := event.Message
+	userID := strings.TrimSpace(raw.User)
+	if userID == "" || raw.BotID != "" || userID == selfUserID || (selfBotID != "" && event.BotID == selfBotID) {
+		return channel.InboundMessage{}, false
+	}
+	text := strings.TrimSpace(raw.Text)
+	attachments := buildSlackAttachments(raw.Files)
+	if text == "" && len(attachments) == 0 {
+		return channel.InboundMessage{}, false
+	}
+	channelID := strings.TrimSpace(event.Channel)
+	threadTS := strings.TrimSpace(event.ThreadTimeStamp)
+	chatType := "group"
+	if event.ChannelType == "im" {
+		chatType = "p2p"
+	}
+	attrs := map[string]string{"user_id": userID}
+	displayName := userID
+	if user, ok := a.lookupUser(ctx, api, slackCfg.BotToken, userID); ok {
+		if user.Name != "" {
+			attrs["username"] = user.Name
+		}
+		if user.TeamID != "" {
+			attrs["team_id"] = user.TeamID
+		}
+		displayName = firstNonEmpty(user.Profile.DisplayName, user.RealName, user.Name, userID)
+	}
+	replyTarget := slackTarget{Kind: "channel", ID: channelID, ThreadTS: threadTS}
+	msg := channel.InboundMessage{
+		Channel: Type,
+		Message: channel.Message{
+			ID:          strings.TrimSpace(event.TimeStamp),
+			Format:      channel.MessageFormatPlain,
+			Text:        text,
+			Attachments: attachments,
+		},
+		BotID:       cfg.BotID,
+		ReplyTarget: replyTarget.String(),
+		Sender: channel.Identity{
+			ExternalID:  userID,
+			DisplayName: displayName,
+			Attributes:  attrs,
+		},
+		Conversation: channel.Conversation{
+			ID:       channelID,
+			Type:     chatType,
+			ThreadID: threadTS,
+		},
+		ReceivedAt: parseSlackTimestamp(event.TimeStamp),
+		Source:     "slack",
+	}
+	if selfUserID != "" {
+		msg.Metadata = map[string]any{
+			channel.MetadataKeyMentioned: strings.Contains(text, "<@"+selfUserID+">"),
+		}
+	}
+	if threadTS != "" {
+		msg.Message.Thread = &channel.ThreadRef{ID: threadTS}
+		if threadTS != event.TimeStamp {
+			msg.Message.Reply = &channel.ReplyRef{MessageID: threadTS, Target: msg.ReplyTarget}
+		}
+	}
+	return msg, true
+}
+
+func (a *SlackAdapter) lookupUser(ctx context.Context, api *slackapi.Client, token, userID string) (slackapi.User, bool) {
+	key := token + ":" + userID
+	a.mu.RLock()
+	user, ok := a.users[key]
+	a.mu.RUnlock()
+	if ok {
+		return user, true
+	}
+	info, err := api.GetUserInfoContext(ctx, userID)
+	if err != nil || info == nil {
+		if a.logger != nil {
+			a.logger.Warn("lookup user failed", slog.String("user_id", userID), slog.Any("error", err))
+		}
+		return slackapi.User{}, false
+	}
+	a.mu.Lock()
+	a.users[key] = *info
+	a.mu.Unlock()
+	return *info, true
+}
+
+func buildSlackAttachments(files []slackapi.File) []channel.Attachment {
+	if len(files) == 0 {
+		return nil
+	}
+	attachments := make([]channel.Attachment, 0, len(files))
+	for _, file := range files {
+		url := strings.TrimSpace(file.URLPrivateDownload)
+		if url == "" {
+			url = strings.TrimSpace(file.URLPrivate)
+		}
+		att := channel.Attachment{
+			Type:     resolveAttachmentType(file.Mimetype),
+			URL:      url,
+			Name:     strings.TrimSpace(file.Name),
+			Size:     int64(file.Size),
+			Mime:     strings.TrimSpace(file.Mimetype),
+			Width:    file.OriginalW,
+			Height:   file.OriginalH,
+			Metadata: map[string]any{},
+		}
+		if file.ID != "" {
+			att.Metadata["file_id"] = file.ID
+		}
+		attachments = append(attachments, att)
+	}
+	return attachments
+}
+
+func resolveAttachmentType(mime string) channel.AttachmentType {
+	mime = strings.ToLower(strings.TrimSpace(mime))
+	switch {
+	case mime == "image/gif":
+		return channel.AttachmentGIF
+	case strings.HasPrefix(mime, "image/"):
+		return channel.AttachmentImage
+	case strings.HasPrefix(mime, "video/"):
+		return channel.AttachmentVideo
+	case strings.HasPrefix(mime, "audio/"):
+		return channel.AttachmentAudio
+	default:
+		return channel.AttachmentFile
+	}
+}
+
+// Send delivers an outbound message to Slack, rendering it as Block Kit.
+func (a *SlackAdapter) Send(ctx context.Context, cfg channel.ChannelConfig, msg channel.OutboundMessage) (channel.SendResult, error) {
+	slackCfg, err := parseConfig(cfg.Credentials)
+	if err != nil {
+		if a.logger != nil {
+			a.logger.Error("decode config failed", slog.String("config_id", cfg.ID), slog.Any("error", err))
+		}
+		return channel.SendResult{}, err
+	}
+	if msg.Message.IsEmpty() {
+		return channel.SendResult{}, fmt.Errorf("message is required")
+	}
+	api := a.newClient(slackCfg)
+	target, channelID, err := a.resolveChannel(ctx, api, msg.Target)
+	if err != nil {
+		return channel.SendResult{}, err
+	}
+	options := buildMessageOptions(msg.Message)
+	if threadTS := resolveThreadTS(msg.Message, target); threadTS != "" {
+		options = append(options, slackapi.MsgOptionTS(threadTS))
+	}
+	_, ts, err := api.PostMessageContext(ctx, channelID, options...)
+	if err != nil {
+		if a.logger != nil {
+			a.logger.Error("send message failed", slog.String("config_id", cfg.ID), slog.Any("error", err))
+		}
+		return channel.SendResult{}, err
+	}
+	return channel.SendResult{MessageIDs: []string{ts}}, nil
+}
+
+// SendEditable posts a message and returns its timestamp so it can be edited later.
+func (a *SlackAdapter) SendEditable(ctx context.Context, cfg channel.ChannelConfig, msg channel.OutboundMessage) (string, error) {
+	slackCfg, err := parseConfig(cfg.Credentials)
+	if err != nil {
+		return "", err
+	}
+	if msg.Message.IsEmpty() {
+		return "", fmt.Errorf("message is required")
+	}
+	api := a.newClient(slackCfg)
+	target, channelID, err := a.resolveChannel(ctx, api, msg.Target)
+	if err != nil {
+		return "", err
+	}
+	options := buildMessageOptions(msg.Message)
+	if threadTS := resolveThreadTS(msg.Message, target); threadTS != "" {
+		options = append(options, slackapi.MsgOptionTS(threadTS))
+	}
+	_, ts, err := api.PostMessageContext(ctx, channelID, options...)
+	if err != nil {
+		return "", err
+	}
+	return ts, nil
+}
+
+// Edit replaces the content of a previously sent Slack message.
+func (a *SlackAdapter) Edit(ctx context.Context, cfg channel.ChannelConfig, target, messageID string, msg channel.Message) error {
+	slackCfg, err := parseConfig(cfg.Credentials)
+	if err != nil {
+		return err
+	}
+	if strings.TrimSpace(messageID) == "" {
+		return fmt.Errorf("slack message id is required")
+	}
+	api := a.newClient(slackCfg)
+	_, channelID, err := a.resolveChannel(ctx, api, target)
+	if err != nil {
+		return err
+	}
+	_, _, _, err = api.UpdateMessageContext(ctx, channelID, strings.TrimSpace(messageID), buildMessageOptions(msg)...)
+	return err
+}
+
+// DownloadAttachment streams a private Slack file using the bot token.
+func (a *SlackAdapter) DownloadAttachment(ctx context.Context, cfg channel.ChannelConfig, att channel.Attachment) (io.ReadCloser, error) {
+	slackCfg, err := parseConfig(cfg.Credentials)
+	if err != nil {
+		return nil, err
+	}
+	url := strings.TrimSpace(att.URL)
+	if url == "" {
+		return nil, fmt.Errorf("slack attachment url is required")
+	}
+	api := a.newClient(slackCfg)
+	reader, writer := io.Pipe()
+	go func() {
+		writer.CloseWithError(api.GetFileContext(ctx, url, writer))
+	}()
+	return reader, nil
+}
+
+// React adds an emoji reaction to a Slack message.
+func (a *SlackAdapter) React(ctx context.Context, cfg channel.ChannelConfig, target, messageID, emoji string) error {
+	slackCfg, err := parseConfig(cfg.Credentials)
+	if err != nil {
+		return err
+	}
+	name := strings.Trim(strings.TrimSpace(emoji), ":")
+	if name == "" {
+		return fmt.Errorf("slack reaction emoji is required")
+	}
+	api := a.newClient(slackCfg)
+	_, channelID, err := a.resolveChannel(ctx, api, target)
+	if err != nil {
+		return err
+	}
+	return api.AddReactionContext(ctx, name, slackapi.NewRefToMessage(channelID, strings.TrimSpace(messageID)))
+}
+
+// Unsend deletes a message the bot has posted, identified by its timestamp.
+func (a *SlackAdapter) Unsend(ctx context.Context, cfg channel.ChannelConfig, target, messageID string) error {
+	slackCfg, err := parseConfig(cfg.Credentials)
+	if err != nil {
+		return err
+	}
+	if strings.TrimSpace(messageID) == "" {
+		return fmt.Errorf("slack message id is required")
+	}
+	api := a.newClient(slackCfg)
+	_, channelID, err := a.resolveChannel(ctx, api, target)
+	if err != nil {
+		return err
+	}
+	_, _, err = api.DeleteMessageContext(ctx, channelID, strings.TrimSpace(messageID))
+	return err
+}
+
+// resolveChannel parses a target and opens a DM conversation for user targets.
+func (a *SlackAdapter) resolveChannel(ctx context.Context, api *slackapi.Client, raw string) (slackTarget, string, error) {
+	target, ok := parseTarget(raw)
+	if !ok {
+		return slackTarget{}, "", fmt.Errorf("slack target must be channel:<id> or user:<id>")
+	}
+	if target.Kind == "channel" {
+		return target, target.ID, nil
+	}
+	conversation, _, _, err := api.OpenConversationContext(ctx, &slackapi.OpenConversationParameters{Users: []string{target.ID}})
+	if err != nil {
+		return slackTarget{}, "", err
+	}
+	return target, conversation.ID, nil
+}
+
+func buildMessageOptions(msg channel.Message) []slackapi.MsgOption {
+	options := []slackapi.MsgOption{slackapi.MsgOptionText(msg.PlainText(), false)}
+	if blocks := buildBlocks(msg); len(blocks) > 0 {
+		options = append(options, slackapi.MsgOptionBlocks(blocks...))
+	}
+	return options
+}
+
+// resolveThreadTS picks the thread to post into: explicit thread, target thread, then reply parent.
+func resolveThreadTS(msg channel.Message, target slackTarget) string {
+	if msg.Thread != nil && strings.TrimSpace(msg.Thread.ID) != "" {
+		return strings.TrimSpace(msg.Thread.ID)
+	}
+	if target.ThreadTS != "" {
+		return target.ThreadTS
+	}
+	if msg.Reply != nil {
+		return strings.TrimSpace(msg.Reply.MessageID)
+	}
+	return ""
+}
+
+func parseSlackTimestamp(ts string) time.Time {
+	seconds, _, _ := strings.Cut(strings.TrimSpace(ts), ".")
+	value, err := strconv.ParseInt(seconds, 10, 64)
+	if err != nil || value <= 0 {
+		return time.Now().UTC()
+	}
+	return time.Unix(value, 0).UTC()
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if strings.TrimSpace(value) != "" {
+			return strings.TrimSpace(value)
+		}
+	}
+	return ""
+}
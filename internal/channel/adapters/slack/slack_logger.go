@@ -0,0 +1,23 @@
+package slack
+
+import (
+	"log/slog"
+	"strings"
+)
+
+// slackSlogLogger adapts slog to the Output-style logger expected by slack-go.
+type slackSlogLogger struct {
+	logger *slog.Logger
+}
+
+func newSlackSlogLogger(logger *slog.Logger) *slackSlogLogger {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &slackSlogLogger{logger: logger}
+}
+
+func (l *slackSlogLogger) Output(_ int, msg string) error {
+	l.logger.Debug("slack sdk", slog.String("detail", strings.TrimSpace(msg)))
+	return nil
+}
@@ -0,0 +1,355 @@
+package slack
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	"github.com/memohai/memoh/internal/channel"
+)
+
+// fakeSlack serves a minimal subset of the Slack Web API and a Socket Mode endpoint.
+type fakeSlack struct {
+	server *httptest.Server
+	events []map[string]any
+
+	mu    sync.Mutex
+	calls map[string][]url.Values
+	acks  []string
+}
+
+func newFakeSlack(t *testing.T, events ...map[string]any) *fakeSlack {
+	t.Helper()
+	f := &fakeSlack{events: events, calls: map[string][]url.Values{}}
+	f.server = httptest.NewServer(http.HandlerFunc(f.serve))
+	t.Cleanup(f.server.Close)
+	return f
+}
+
+func (f *fakeSlack) adapter() *SlackAdapter {
+	adapter := NewSlackAdapter(nil)
+	adapter.apiURL = f.server.URL + "/api/"
+	return adapter
+}
+
+func (f *fakeSlack) recorded(method string) []url.Values {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]url.Values(nil), f.calls[method]...)
+}
+
+func (f *fakeSlack) serve(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path == "/socket" {
+		f.serveSocket(w, r)
+		return
+	}
+	if strings.HasPrefix(r.URL.Path, "/files/") {
+		if r.Header.Get("Authorization") != "Bearer xoxb-1" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		_, _ = w.Write([]byte("file-bytes"))
+		return
+	}
+	method := strings.TrimPrefix(r.URL.Path, "/api/")
+	_ = r.ParseForm()
+	f.mu.Lock()
+	f.calls[method] = append(f.calls[method], r.Form)
+	f.mu.Unlock()
+	auth := r.Header.Get("Authorization")
+	if token := r.Form.Get("token"); token != "" {
+		auth = "Bearer " + token
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if method == "apps.connections.open" {
+		if auth != "Bearer xapp-1" {
+			_ = json.NewEncoder(w).Encode(map[string]any{"ok": false, "error": "invalid_auth"})
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{"ok": true, "url": "ws" + strings.TrimPrefix(f.server.URL, "http") + "/socket"})
+		return
+	}
+	if auth != "Bearer xoxb-1" {
+		_ = json.NewEncoder(w).Encode(map[string]any{"ok": false, "error": "invalid_auth"})
+		return
+	}
+	switch method {
+	case "auth.test":
+		_ = json.NewEncoder(w).Encode(map[string]any{"ok": true, "user_id": "UBOT", "bot_id": "BBOT"})
+	case "users.info":
+		_ = json.NewEncoder(w).Encode(map[string]any{"ok": true, "user": map[string]any{
+			"id": r.Form.Get("user"), "name": "alice", "real_name": "Alice Liddell", "team_id": "T1",
+			"profile": map[string]any{"display_name": "Alice"},
+		}})
+	case "conversations.open":
+		_ = json.NewEncoder(w).Encode(map[string]any{"ok": true, "channel": map[string]any{"id": "D999"}})
+	case "chat.postMessage", "chat.update":
+		_ = json.NewEncoder(w).Encode(map[string]any{"ok": true, "channel": r.Form.Get("channel"), "ts": "1700000000.000200"})
+	case "reactions.add", "chat.delete":
+		_ = json.NewEncoder(w).Encode(map[string]any{"ok": true})
+	default:
+		_ = json.NewEncoder(w).Encode(map[string]any{"ok": false, "error": "unknown_method"})
+	}
+}
+
+func (f *fakeSlack) serveSocket(w http.ResponseWriter, r *http.Request) {
+	upgrader := websocket.Upgrader{CheckOrigin: func(*http.Request) bool { return true }}
+	conn, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+	if err := conn.WriteJSON(map[string]any{"type": "hello", "num_connections": 1}); err != nil {
+		return
+	}
+	for _, event := range f.events {
+		if err := conn.WriteJSON(event); err != nil {
+			return
+		}
+	}
+	for {
+		var ack struct {
+			EnvelopeID string `json:"envelope_id"`
+		}
+		if err := conn.ReadJSON(&ack); err != nil {
+			return
+		}
+		f.mu.Lock()
+		f.acks = append(f.acks, ack.EnvelopeID)
+		f.mu.Unlock()
+	}
+}
+
+func messageEnvelope(id string, event map[string]any) map[string]any {
+	return map[string]any{
+		"type":        "events_api",
+		"envelope_id": id,
+		"payload": map[string]any{
+			"type":    "event_callback",
+			"team_id": "T1",
+			"event":   event,
+		},
+	}
+}
+
+func testConfig() channel.ChannelConfig {
+	return channel.ChannelConfig{
+		ID:          "cfg-1",
+		BotID:       "bot-1",
+		ChannelType: Type,
+		Credentials: map[string]any{"botToken": "xoxb-1", "appToken": "xapp-1"},
+	}
+}
+
+func TestSlackConnectReceivesThreadMessages(t *testing.T) {
+	t.Parallel()
+
+	fake := newFakeSlack(t,
+		messageEnvelope("env-1", map[string]any{
+			"type": "message", "channel": "C1", "channel_type": "channel", "user": "UBOT",
+			"text": "own message", "ts": "1700000000.000050",
+		}),
+		messageEnvelope("env-2", map[string]any{
+			"type": "message", "channel": "C1", "channel_type": "channel", "user": "U1",
+			"text": "hello in thread", "ts": "1700000000.000100", "thread_ts": "1700000000.000001",
+		}),
+	)
+	adapter := fake.adapter()
+	received := make(chan channel.InboundMessage, 4)
+	conn, err := adapter.Connect(context.Background(), testConfig(), func(ctx context.Context, cfg channel.ChannelConfig, msg channel.InboundMessage) error {
+		received <- msg
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("connect failed: %v", err)
+	}
+	defer func() {
+		stopCtx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+		defer cancel()
+		if err := conn.Stop(stopCtx); err != nil {
+			t.Errorf("stop failed: %v", err)
+		}
+	}()
+
+	var msg channel.InboundMessage
+	select {
+	case msg = <-received:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("timed out waiting for inbound message")
+	}
+	if msg.Message.Text != "hello in thread" || msg.Message.ID != "1700000000.000100" {
+		t.Fatalf("unexpected message: %#v", msg.Message)
+	}
+	if msg.Conversation.ID != "C1" || msg.Conversation.Type != "group" || msg.Conversation.ThreadID != "1700000000.000001" {
+		t.Fatalf("unexpected conversation: %#v", msg.Conversation)
+	}
+	if msg.Message.Thread == nil || msg.Message.Thread.ID != "1700000000.000001" {
+		t.Fatalf("expected thread ref, got %#v", msg.Message.Thread)
+	}
+	if msg.ReplyTarget != "channel:C1/1700000000.000001" {
+		t.Fatalf("unexpected reply target: %s", msg.ReplyTarget)
+	}
+	if msg.Sender.ExternalID != "U1" || msg.Sender.DisplayName != "Alice" || msg.Sender.Attribute("username") != "alice" {
+		t.Fatalf("unexpected sender: %#v", msg.Sender)
+	}
+	select {
+	case extra := <-received:
+		t.Fatalf("unexpected extra message: %#v", extra.Message)
+	case <-time.After(100 * time.Millisecond):
+	}
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		fake.mu.Lock()
+		acks := len(fake.acks)
+		fake.mu.Unlock()
+		if acks == 2 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("expected both envelopes to be acked, got %d", acks)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestSlackSendRendersBlocksInThread(t *testing.T) {
+	t.Parallel()
+
+	fake := newFakeSlack(t)
+	adapter := fake.adapter()
+	result, err := adapter.Send(context.Background(), testConfig(), channel.OutboundMessage{
+		Target: "channel:C1/1700000000.000001",
+		Message: channel.Message{
+			Format: channel.MessageFormatMarkdown,
+			Text:   "**done** see [docs](https://example.com)",
+			Actions: []channel.Action{
+				{Type: "button", Label: "Retry", Value: "retry"},
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("send failed: %v", err)
+	}
+	if len(result.MessageIDs) != 1 || result.MessageIDs[0] != "1700000000.000200" {
+		t.Fatalf("unexpected message ids: %#v", result.MessageIDs)
+	}
+	calls := fake.recorded("chat.postMessage")
+	if len(calls) != 1 {
+		t.Fatalf("expected one post, got %d", len(calls))
+	}
+	form := calls[0]
+	if form.Get("channel") != "C1" || form.Get("thread_ts") != "1700000000.000001" {
+		t.Fatalf("unexpected post form: %#v", form)
+	}
+	var blocks []map[string]any
+	if err := json.Unmarshal([]byte(form.Get("blocks")), &blocks); err != nil {
+		t.Fatalf("decode blocks: %v", err)
+	}
+	if len(blocks) != 2 || blocks[0]["type"] != "section" || blocks[1]["type"] != "actions" {
+		t.Fatalf("unexpected blocks: %s", form.Get("blocks"))
+	}
+	section, _ := blocks[0]["text"].(map[string]any)
+	if section["text"] != "*done* see <https://example.com|docs>" {
+		t.Fatalf("unexpected section text: %#v", section["text"])
+	}
+}
+
+func TestSlackSendToUserOpensConversation(t *testing.T) {
+	t.Parallel()
+
+	fake := newFakeSlack(t)
+	adapter := fake.adapter()
+	_, err := adapter.Send(context.Background(), testConfig(), channel.OutboundMessage{
+		Target:  "user:U1",
+		Message: channel.Message{Text: "hi"},
+	})
+	if err != nil {
+		t.Fatalf("send failed: %v", err)
+	}
+	opens := fake.recorded("conversations.open")
+	if len(opens) != 1 || opens[0].Get("users") != "U1" {
+		t.Fatalf("unexpected conversations.open calls: %#v", opens)
+	}
+	posts := fake.recorded("chat.postMessage")
+	if len(posts) != 1 || posts[0].Get("channel") != "D999" || posts[0].Get("thread_ts") != "" {
+		t.Fatalf("unexpected posts: %#v", posts)
+	}
+}
+
+func TestSlackEditAndReact(t *testing.T) {
+	t.Parallel()
+
+	fake := newFakeSlack(t)
+	adapter := fake.adapter()
+	ctx := context.Background()
+	if err := adapter.Edit(ctx, testConfig(), "channel:C1", "1700000000.000200", channel.Message{Text: "updated"}); err != nil {
+		t.Fatalf("edit failed: %v", err)
+	}
+	if err := adapter.React(ctx, testConfig(), "channel:C1", "1700000000.000200", ":thumbsup:"); err != nil {
+		t.Fatalf("react failed: %v", err)
+	}
+	updates := fake.recorded("chat.update")
+	if len(updates) != 1 || updates[0].Get("ts") != "1700000000.000200" || updates[0].Get("text") != "updated" {
+		t.Fatalf("unexpected updates: %#v", updates)
+	}
+	reactions := fake.recorded("reactions.add")
+	if len(reactions) != 1 || reactions[0].Get("name") != "thumbsup" || reactions[0].Get("timestamp") != "1700000000.000200" {
+		t.Fatalf("unexpected reactions: %#v", reactions)
+	}
+}
+
+func TestSlackUnsend(t *testing.T) {
+	t.Parallel()
+
+	fake := newFakeSlack(t)
+	adapter := fake.adapter()
+	if err := adapter.Unsend(context.Background(), testConfig(), "channel:C1", "1700000000.000200"); err != nil {
+		t.Fatalf("unsend failed: %v", err)
+	}
+	deletes := fake.recorded("chat.delete")
+	if len(deletes) != 1 || deletes[0].Get("channel") != "C1" || deletes[0].Get("ts") != "1700000000.000200" {
+		t.Fatalf("unexpected deletes: %#v", deletes)
+	}
+}
+
+func TestSlackDownloadAttachment(t *testing.T) {
+	t.Parallel()
+
+	fake := newFakeSlack(t)
+	adapter := fake.adapter()
+	body, err := adapter.DownloadAttachment(context.Background(), testConfig(), channel.Attachment{URL: fake.server.URL + "/files/F1/report.pdf"})
+	if err != nil {
+		t.Fatalf("download failed: %v", err)
+	}
+	defer body.Close()
+	data, err := io.ReadAll(body)
+	if err != nil {
+		t.Fatalf("read failed: %v", err)
+	}
+	if string(data) != "file-bytes" {
+		t.Fatalf("unexpected content: %q", data)
+	}
+}
+
+func TestRenderParts(t *testing.T) {
+	t.Parallel()
+
+	got := renderMrkdwn(channel.Message{Parts: []channel.MessagePart{
+		{Type: channel.MessagePartText, Text: "hi ", Styles: []channel.MessageTextStyle{channel.MessageStyleBold}},
+		{Type: channel.MessagePartMention, UserID: "U1"},
+		{Type: channel.MessagePartText, Text: " a<b"},
+		{Type: channel.MessagePartEmoji, Emoji: "wave"},
+	}})
+	if got != "*hi *<@U1> a&lt;b:wave:" {
+		t.Fatalf("unexpected mrkdwn: %q", got)
+	}
+}
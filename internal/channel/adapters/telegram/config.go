@@ -7,9 +7,18 @@ import (
 	"github.com/memohai/memoh/internal/channel"
 )
 
+// Update delivery modes supported by the Telegram adapter.
+const (
+	ModePolling = "polling"
+	ModeWebhook = "webhook"
+)
+
 // Config holds the Telegram bot credentials extracted from a channel configuration.
 type Config struct {
-	BotToken string
+	BotToken       string
+	Mode           string
+	WebhookBaseURL string
+	WebhookSecret  string
 }
 
 // UserConfig holds the identifiers used to target a Telegram user or group.
@@ -24,9 +33,17 @@ func normalizeConfig(raw map[string]any) (map[string]any, error) {
 	if err != nil {
 		return nil, err
 	}
-	return map[string]any{
+	result := map[string]any{
 		"botToken": cfg.BotToken,
-	}, nil
+	}
+	if cfg.Mode == ModeWebhook {
+		result["mode"] = cfg.Mode
+		result["webhookBaseUrl"] = cfg.WebhookBaseURL
+		if cfg.WebhookSecret != "" {
+			result["webhookSecret"] = cfg.WebhookSecret
+		}
+	}
+	return result, nil
 }
 
 func normalizeUserConfig(raw map[string]any) (map[string]any, error) {
@@ -109,7 +126,44 @@ func parseConfig(raw map[string]any) (Config, error) {
 	if token == "" {
 		return Config{}, fmt.Errorf("telegram botToken is required")
 	}
-	return Config{BotToken: token}, nil
+	mode := strings.ToLower(strings.TrimSpace(channel.ReadString(raw, "mode")))
+	switch mode {
+	case "", ModePolling:
+		return Config{BotToken: token, Mode: ModePolling}, nil
+	case ModeWebhook:
+	default:
+		return Config{}, fmt.Errorf("telegram mode must be %q or %q", ModePolling, ModeWebhook)
+	}
+	baseURL := strings.TrimRight(strings.TrimSpace(channel.ReadString(raw, "webhookBaseUrl", "webhook_base_url")), "/")
+	if baseURL == "" {
+		return Config{}, fmt.Errorf("telegram webhookBaseUrl is required in webhook mode")
+	}
+	if !strings.HasPrefix(baseURL, "https://") && !strings.HasPrefix(baseURL, "http://") {
+		return Config{}, fmt.Errorf("telegram webhookBaseUrl must be an http(s) URL")
+	}
+	secret := strings.TrimSpace(channel.ReadString(raw, "webhookSecret", "webhook_secret"))
+	if secret != "" && !isValidSecretToken(secret) {
+		return Config{}, fmt.Errorf("telegram webhookSecret must be 1-256 characters of A-Z, a-z, 0-9, _ or -")
+	}
+	return Config{
+		BotToken:       token,
+		Mode:           ModeWebhook,
+		WebhookBaseURL: baseURL,
+		WebhookSecret:  secret,
+	}, nil
+}
+
+// isValidSecretToken reports whether value is accepted by setWebhook's secret_token.
+func isValidSecretToken(value string) bool {
+	if len(value) == 0 || len(value) > 256 {
+		return false
+	}
+	for _, r := range value {
+		if (r < 'a' || r > 'z') && (r < 'A' || r > 'Z') && (r < '0' || r > '9') && r != '_' && r != '-' {
+			return false
+		}
+	}
+	return true
 }
 
 func parseUserConfig(raw map[string]any) (UserConfig, error) {
@@ -25,6 +25,47 @@ func TestNormalizeConfigRequiresToken(t *testing.T) {
 	}
 }
 
+func TestNormalizeConfigWebhookMode(t *testing.T) {
+	t.Parallel()
+
+	got, err := normalizeConfig(map[string]any{
+		"botToken":         "token-123",
+		"mode":             "Webhook",
+		"webhook_base_url": "https://memoh.example.com/",
+		"webhookSecret":    "s3cret_token",
+	})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if got["mode"] != ModeWebhook || got["webhookBaseUrl"] != "https://memoh.example.com" || got["webhookSecret"] != "s3cret_token" {
+		t.Fatalf("unexpected config: %#v", got)
+	}
+
+	got, err = normalizeConfig(map[string]any{"botToken": "token-123", "mode": "polling"})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if _, ok := got["mode"]; ok {
+		t.Fatalf("polling mode should not be persisted: %#v", got)
+	}
+}
+
+func TestNormalizeConfigWebhookValidation(t *testing.T) {
+	t.Parallel()
+
+	cases := []map[string]any{
+		{"botToken": "token-123", "mode": "push"},
+		{"botToken": "token-123", "mode": "webhook"},
+		{"botToken": "token-123", "mode": "webhook", "webhookBaseUrl": "memoh.example.com"},
+		{"botToken": "token-123", "mode": "webhook", "webhookBaseUrl": "https://memoh.example.com", "webhookSecret": "not valid!"},
+	}
+	for _, raw := range cases {
+		if _, err := normalizeConfig(raw); err == nil {
+			t.Fatalf("expected error for %#v", raw)
+		}
+	}
+}
+
 func TestNormalizeUserConfig(t *testing.T) {
 	t.Parallel()
 
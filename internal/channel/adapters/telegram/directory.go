@@ -0,0 +1,158 @@
+package telegram
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"github.com/memohai/memoh/internal/channel"
+)
+
+// telegramDirectory implements channel.ChannelDirectoryAdapter on top of the Bot API.
+// Bots cannot enumerate users or the chats they are in, so peer and group listing is left
+// to the local directory; chats can still be looked up by @username or ID.
+type telegramDirectory struct {
+	adapter *TelegramAdapter
+}
+
+// Directory returns the Telegram directory adapter.
+func (a *TelegramAdapter) Directory() channel.ChannelDirectoryAdapter {
+	return &telegramDirectory{adapter: a}
+}
+
+// ListPeers is not supported by the Bot API.
+func (d *telegramDirectory) ListPeers(ctx context.Context, cfg channel.ChannelConfig, query channel.DirectoryQuery) ([]channel.DirectoryEntry, error) {
+	return nil, channel.ErrDirectoryNotSupported
+}
+
+// ListGroups is not supported by the Bot API.
+func (d *telegramDirectory) ListGroups(ctx context.Context, cfg channel.ChannelConfig, query channel.DirectoryQuery) ([]channel.DirectoryEntry, error) {
+	return nil, channel.ErrDirectoryNotSupported
+}
+
+// ListGroupMembers lists the administrators of a group, the only members a bot can enumerate.
+func (d *telegramDirectory) ListGroupMembers(ctx context.Context, cfg channel.ChannelConfig, groupID string, query channel.DirectoryQuery) ([]channel.DirectoryEntry, error) {
+	bot, err := d.bot(cfg)
+	if err != nil {
+		return nil, err
+	}
+	chatCfg, ok := telegramChatConfig(groupID)
+	if !ok {
+		return nil, fmt.Errorf("telegram group must be @username or chat_id")
+	}
+	members, err := bot.GetChatAdministrators(tgbotapi.ChatAdministratorsConfig{ChatConfig: chatCfg})
+	if err != nil {
+		return nil, err
+	}
+	needle := strings.ToLower(strings.TrimSpace(query.Query))
+	results := make([]channel.DirectoryEntry, 0, len(members))
+	for _, member := range members {
+		if member.User == nil {
+			continue
+		}
+		entry := telegramUserEntry(member.User)
+		entry.Metadata = map[string]any{"status": member.Status}
+		if needle != "" && !strings.Contains(strings.ToLower(entry.Name), needle) && !strings.Contains(strings.ToLower(entry.Handle), needle) {
+			continue
+		}
+		results = append(results, entry)
+		if query.Limit > 0 && len(results) >= query.Limit {
+			break
+		}
+	}
+	return results, nil
+}
+
+// ResolveTarget looks up a chat by @username or numeric ID. Plain names are not supported.
+func (d *telegramDirectory) ResolveTarget(ctx context.Context, cfg channel.ChannelConfig, input string, kind channel.DirectoryEntryKind) (channel.DirectoryEntry, error) {
+	chatCfg, ok := telegramChatConfig(input)
+	if !ok {
+		return channel.DirectoryEntry{}, channel.ErrDirectoryNotSupported
+	}
+	bot, err := d.bot(cfg)
+	if err != nil {
+		return channel.DirectoryEntry{}, err
+	}
+	chat, err := bot.GetChat(tgbotapi.ChatInfoConfig{ChatConfig: chatCfg})
+	if err != nil {
+		var apiErr *tgbotapi.Error
+		if errors.As(err, &apiErr) && apiErr.Code == 400 {
+			return channel.DirectoryEntry{}, channel.ErrDirectoryEntryNotFound
+		}
+		return channel.DirectoryEntry{}, err
+	}
+	entry := telegramChatEntry(chat)
+	if kind != "" && entry.Kind != kind {
+		return channel.DirectoryEntry{}, channel.ErrDirectoryEntryNotFound
+	}
+	return entry, nil
+}
+
+func (d *telegramDirectory) bot(cfg channel.ChannelConfig) (*tgbotapi.BotAPI, error) {
+	telegramCfg, err := parseConfig(cfg.Credentials)
+	if err != nil {
+		return nil, err
+	}
+	return d.adapter.getOrCreateBot(telegramCfg.BotToken, cfg.ID)
+}
+
+// telegramChatConfig converts a chat ID, @username or t.me link into a chat reference.
+// Anything else is treated as a display name, which the Bot API cannot look up.
+func telegramChatConfig(raw string) (tgbotapi.ChatConfig, bool) {
+	value := strings.TrimSpace(raw)
+	if chatID, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return tgbotapi.ChatConfig{ChatID: chatID}, true
+	}
+	explicit := false
+	for _, prefix := range []string{"@", "tg:", "telegram:", "t.me/", "https://t.me/", "http://t.me/"} {
+		if strings.HasPrefix(value, prefix) {
+			explicit = true
+			break
+		}
+	}
+	if !explicit {
+		return tgbotapi.ChatConfig{}, false
+	}
+	target := normalizeTarget(value)
+	if !strings.HasPrefix(target, "@") || strings.ContainsAny(target, " /") {
+		if chatID, err := strconv.ParseInt(target, 10, 64); err == nil {
+			return tgbotapi.ChatConfig{ChatID: chatID}, true
+		}
+		return tgbotapi.ChatConfig{}, false
+	}
+	return tgbotapi.ChatConfig{SuperGroupUsername: target}, true
+}
+
+func telegramUserEntry(user *tgbotapi.User) channel.DirectoryEntry {
+	name := strings.TrimSpace(strings.TrimSpace(user.FirstName) + " " + strings.TrimSpace(user.LastName))
+	entry := channel.DirectoryEntry{
+		Kind: channel.DirectoryEntryUser,
+		ID:   strconv.FormatInt(user.ID, 10),
+		Name: name,
+	}
+	if user.UserName != "" {
+		entry.Handle = "@" + user.UserName
+	}
+	return entry
+}
+
+func telegramChatEntry(chat tgbotapi.Chat) channel.DirectoryEntry {
+	entry := channel.DirectoryEntry{
+		Kind:     channel.DirectoryEntryGroup,
+		ID:       strconv.FormatInt(chat.ID, 10),
+		Name:     strings.TrimSpace(chat.Title),
+		Metadata: map[string]any{"chat_type": chat.Type},
+	}
+	if chat.IsPrivate() {
+		entry.Kind = channel.DirectoryEntryUser
+		entry.Name = strings.TrimSpace(strings.TrimSpace(chat.FirstName) + " " + strings.TrimSpace(chat.LastName))
+	}
+	if chat.UserName != "" {
+		entry.Handle = "@" + chat.UserName
+	}
+	return entry
+}
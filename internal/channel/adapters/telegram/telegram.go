@@ -2,8 +2,10 @@ package telegram
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
+	"net/http"
 	"strconv"
 	"strings"
 	"sync"
@@ -15,11 +17,17 @@ import (
 	"github.com/memohai/memoh/internal/channel/adapters/common"
 )
 
-// TelegramAdapter implements the channel.Adapter, channel.Sender, and channel.Receiver interfaces for Telegram.
+// telegramCallbackDataLimit is the maximum size of inline keyboard callback data in bytes.
+const telegramCallbackDataLimit = 64
+
+// TelegramAdapter implements the channel.Adapter, channel.Sender, channel.Receiver, and
+// channel.WebhookReceiver interfaces for Telegram.
 type TelegramAdapter struct {
-	logger *slog.Logger
-	mu     sync.RWMutex
-	bots   map[string]*tgbotapi.BotAPI // keyed by bot token
+	logger      *slog.Logger
+	apiEndpoint string
+	mu          sync.RWMutex
+	bots        map[string]*tgbotapi.BotAPI // keyed by bot token
+	webhooks    map[string]*telegramWebhook // keyed by config ID
 }
 
 // NewTelegramAdapter creates a TelegramAdapter with the given logger.
@@ -28,11 +36,17 @@ func NewTelegramAdapter(log *slog.Logger) *TelegramAdapter {
 		log = slog.Default()
 	}
 	return &TelegramAdapter{
-		logger: log.With(slog.String("adapter", "telegram")),
-		bots:   make(map[string]*tgbotapi.BotAPI),
+		logger:      log.With(slog.String("adapter", "telegram")),
+		apiEndpoint: tgbotapi.APIEndpoint,
+		bots:        make(map[string]*tgbotapi.BotAPI),
+		webhooks:    make(map[string]*telegramWebhook),
 	}
 }
 
+func (a *TelegramAdapter) newBot(token string) (*tgbotapi.BotAPI, error) {
+	return tgbotapi.NewBotAPIWithClient(token, a.apiEndpoint, &http.Client{})
+}
+
 func (a *TelegramAdapter) getOrCreateBot(token, configID string) (*tgbotapi.BotAPI, error) {
 	a.mu.RLock()
 	bot, ok := a.bots[token]
@@ -45,7 +59,7 @@ func (a *TelegramAdapter) getOrCreateBot(token, configID string) (*tgbotapi.BotA
 	if bot, ok := a.bots[token]; ok {
 		return bot, nil
 	}
-	bot, err := tgbotapi.NewBotAPI(token)
+	bot, err := a.newBot(token)
 	if err != nil {
 		if a.logger != nil {
 			a.logger.Error("create bot failed", slog.String("config_id", configID), slog.Any("error", err))
@@ -67,11 +81,20 @@ func (a *TelegramAdapter) Descriptor() channel.Descriptor {
 		Type:        Type,
 		DisplayName: "Telegram",
 		Capabilities: channel.ChannelCapabilities{
-			Text:        true,
-			Markdown:    true,
-			Reply:       true,
-			Attachments: true,
-			Media:       true,
+			Text:           true,
+			Markdown:       true,
+			Reply:          true,
+			Attachments:    true,
+			Media:          true,
+			Buttons:        true,
+			Edit:           true,
+			Unsend:         true,
+			Streaming:      true,
+			Presence:       true,
+			NativeCommands: true,
+		},
+		OutboundPolicy: channel.OutboundPolicy{
+			PresenceRefreshMs: 4000,
 		},
 		ConfigSchema: channel.ConfigSchema{
 			Version: 1,
@@ -81,6 +104,24 @@ func (a *TelegramAdapter) Descriptor() channel.Descriptor {
 					Required: true,
 					Title:    "Bot Token",
 				},
+				"mode": {
+					Type:        channel.FieldEnum,
+					Title:       "Update Mode",
+					Description: "polling uses getUpdates; webhook has Telegram push updates to this server",
+					Enum:        []string{ModePolling, ModeWebhook},
+					Example:     ModePolling,
+				},
+				"webhookBaseUrl": {
+					Type:        channel.FieldString,
+					Title:       "Webhook Base URL",
+					Description: "Public base URL of this server, required in webhook mode",
+					Example:     "https://memoh.example.com",
+				},
+				"webhookSecret": {
+					Type:        channel.FieldSecret,
+					Title:       "Webhook Secret",
+					Description: "Secret token Telegram sends with each webhook request; generated when empty",
+				},
 			},
 		},
 		UserConfigSchema: channel.ConfigSchema{
@@ -131,7 +172,8 @@ func (a *TelegramAdapter) BuildUserConfig(identity channel.Identity) map[string]
 	return buildUserConfig(identity)
 }
 
-// Connect starts long-polling for Telegram updates and forwards messages to the handler.
+// Connect starts receiving Telegram updates, either by long-polling or through a registered
+// webhook, and forwards messages to the handler.
 func (a *TelegramAdapter) Connect(ctx context.Context, cfg channel.ChannelConfig, handler channel.InboundHandler) (channel.Connection, error) {
 	if a.logger != nil {
 		a.logger.Info("start", slog.String("config_id", cfg.ID))
@@ -143,18 +185,31 @@ func (a *TelegramAdapter) Connect(ctx context.Context, cfg channel.ChannelConfig
 		}
 		return nil, err
 	}
-	bot, err := tgbotapi.NewBotAPI(telegramCfg.BotToken)
+	bot, err := a.newBot(telegramCfg.BotToken)
 	if err != nil {
 		if a.logger != nil {
 			a.logger.Error("create bot failed", slog.String("config_id", cfg.ID), slog.Any("error", err))
 		}
 		return nil, err
 	}
+	if telegramCfg.Mode == ModeWebhook {
+		return a.connectWebhook(ctx, cfg, telegramCfg, bot, handler)
+	}
 	updateConfig := tgbotapi.NewUpdate(0)
 	updateConfig.Timeout = 30
 	updates := bot.GetUpdatesChan(updateConfig)
 	connCtx, cancel := context.WithCancel(ctx)
 
+	stop := func(context.Context) error {
+		if a.logger != nil {
+			a.logger.Info("stop", slog.String("config_id", cfg.ID))
+		}
+		cancel()
+		bot.StopReceivingUpdates()
+		return nil
+	}
+	conn := channel.NewConnection(cfg, stop)
+
 	go func() {
 		for {
 			select {
@@ -169,110 +224,171 @@ func (a *TelegramAdapter) Connect(ctx context.Context, cfg channel.ChannelConfig
 					if a.logger != nil {
 						a.logger.Info("updates channel closed", slog.String("config_id", cfg.ID))
 					}
+					if connCtx.Err() == nil {
+						conn.Fail(errors.New("telegram updates channel closed"))
+					}
 					return
 				}
-				if update.Message == nil {
-					continue
-				}
-				text := strings.TrimSpace(update.Message.Text)
-				caption := strings.TrimSpace(update.Message.Caption)
-				if text == "" && caption != "" {
-					text = caption
-				}
-				attachments := a.collectTelegramAttachments(bot, update.Message)
-				if text == "" && len(attachments) == 0 {
-					continue
-				}
-				externalID, displayName, attrs := resolveTelegramSender(update.Message)
-				chatID := ""
-				chatType := ""
-				chatName := ""
-				if update.Message.Chat != nil {
-					chatID = strconv.FormatInt(update.Message.Chat.ID, 10)
-					chatType = strings.TrimSpace(update.Message.Chat.Type)
-					chatName = strings.TrimSpace(update.Message.Chat.Title)
-				}
-				replyRef := buildTelegramReplyRef(update.Message, chatID)
-				msg := channel.InboundMessage{
-					Channel: Type,
-					Message: channel.Message{
-						ID:          strconv.Itoa(update.Message.MessageID),
-						Format:      channel.MessageFormatPlain,
-						Text:        text,
-						Attachments: attachments,
-						Reply:       replyRef,
-					},
-					BotID:       cfg.BotID,
-					ReplyTarget: chatID,
-					Sender: channel.Identity{
-						ExternalID:  externalID,
-						DisplayName: displayName,
-						Attributes:  attrs,
-					},
-					Conversation: channel.Conversation{
-						ID:   chatID,
-						Type: chatType,
-						Name: chatName,
-					},
-					ReceivedAt: time.Unix(int64(update.Message.Date), 0).UTC(),
-					Source:     "telegram",
-				}
-				if a.logger != nil {
-					a.logger.Info(
-						"inbound received",
-						slog.String("config_id", cfg.ID),
-						slog.String("chat_type", msg.Conversation.Type),
-						slog.String("chat_id", msg.Conversation.ID),
-						slog.String("user_id", attrs["user_id"]),
-						slog.String("username", attrs["username"]),
-						slog.String("text", common.SummarizeText(text)),
-					)
-				}
-				go func() {
-					if err := handler(connCtx, cfg, msg); err != nil && a.logger != nil {
-						a.logger.Error("handle inbound failed", slog.String("config_id", cfg.ID), slog.Any("error", err))
-					}
-				}()
+				a.handleUpdate(connCtx, bot, cfg, handler, update)
 			}
 		}
 	}()
+	return conn, nil
+}
 
-	stop := func(context.Context) error {
-		if a.logger != nil {
-			a.logger.Info("stop", slog.String("config_id", cfg.ID))
+// handleUpdate converts a Telegram update into an inbound message and dispatches it to the handler.
+func (a *TelegramAdapter) handleUpdate(ctx context.Context, bot *tgbotapi.BotAPI, cfg channel.ChannelConfig, handler channel.InboundHandler, update tgbotapi.Update) {
+	if update.CallbackQuery != nil {
+		a.handleCallbackQuery(ctx, bot, cfg, handler, update.CallbackQuery)
+		return
+	}
+	if update.Message == nil {
+		return
+	}
+	text := strings.TrimSpace(update.Message.Text)
+	caption := strings.TrimSpace(update.Message.Caption)
+	if text == "" && caption != "" {
+		text = caption
+	}
+	attachments := a.collectTelegramAttachments(bot, update.Message)
+	if text == "" && len(attachments) == 0 {
+		return
+	}
+	externalID, displayName, attrs := resolveTelegramSender(update.Message)
+	chatID := ""
+	chatType := ""
+	chatName := ""
+	if update.Message.Chat != nil {
+		chatID = strconv.FormatInt(update.Message.Chat.ID, 10)
+		chatType = strings.TrimSpace(update.Message.Chat.Type)
+		chatName = strings.TrimSpace(update.Message.Chat.Title)
+	}
+	replyRef := buildTelegramReplyRef(update.Message, chatID)
+	msg := channel.InboundMessage{
+		Channel: Type,
+		Message: channel.Message{
+			ID:          strconv.Itoa(update.Message.MessageID),
+			Format:      channel.MessageFormatPlain,
+			Text:        text,
+			Attachments: attachments,
+			Reply:       replyRef,
+		},
+		BotID:       cfg.BotID,
+		ReplyTarget: chatID,
+		Sender: channel.Identity{
+			ExternalID:  externalID,
+			DisplayName: displayName,
+			Attributes:  attrs,
+		},
+		Conversation: channel.Conversation{
+			ID:   chatID,
+			Type: chatType,
+			Name: chatName,
+		},
+		ReceivedAt: time.Unix(int64(update.Message.Date), 0).UTC(),
+		Source:     "telegram",
+		Metadata:   telegramAddressMetadata(bot.Self, update.Message),
+	}
+	if a.logger != nil {
+		a.logger.Info(
+			"inbound received",
+			slog.String("config_id", cfg.ID),
+			slog.String("chat_type", msg.Conversation.Type),
+			slog.String("chat_id", msg.Conversation.ID),
+			slog.String("user_id", attrs["user_id"]),
+			slog.String("username", attrs["username"]),
+			slog.String("text", common.SummarizeText(text)),
+		)
+	}
+	go func() {
+		if err := handler(ctx, cfg, msg); err != nil && a.logger != nil {
+			a.logger.Error("handle inbound failed", slog.String("config_id", cfg.ID), slog.Any("error", err))
 		}
-		cancel()
-		bot.StopReceivingUpdates()
-		return nil
+	}()
+}
+
+// handleCallbackQuery acknowledges an inline keyboard button press and dispatches it as an
+// inbound action on the chat the button was sent to.
+func (a *TelegramAdapter) handleCallbackQuery(ctx context.Context, bot *tgbotapi.BotAPI, cfg channel.ChannelConfig, handler channel.InboundHandler, query *tgbotapi.CallbackQuery) {
+	if _, err := bot.Request(tgbotapi.NewCallback(query.ID, "")); err != nil && a.logger != nil {
+		a.logger.Warn("answer callback query failed", slog.String("config_id", cfg.ID), slog.Any("error", err))
+	}
+	data := strings.TrimSpace(query.Data)
+	if data == "" || query.Message == nil || query.Message.Chat == nil || query.From == nil {
+		return
+	}
+	externalID, displayName, attrs := resolveTelegramSender(&tgbotapi.Message{From: query.From, Chat: query.Message.Chat})
+	chatID := strconv.FormatInt(query.Message.Chat.ID, 10)
+	msg := channel.InboundMessage{
+		Channel: Type,
+		Message: channel.Message{
+			ID:     query.ID,
+			Format: channel.MessageFormatPlain,
+		},
+		BotID:       cfg.BotID,
+		ReplyTarget: chatID,
+		Sender: channel.Identity{
+			ExternalID:  externalID,
+			DisplayName: displayName,
+			Attributes:  attrs,
+		},
+		Conversation: channel.Conversation{
+			ID:   chatID,
+			Type: strings.TrimSpace(query.Message.Chat.Type),
+			Name: strings.TrimSpace(query.Message.Chat.Title),
+		},
+		ReceivedAt: time.Now().UTC(),
+		Source:     "telegram",
+		Action: &channel.InboundAction{
+			Value:     data,
+			Label:     telegramButtonLabel(query.Message.ReplyMarkup, data),
+			MessageID: strconv.Itoa(query.Message.MessageID),
+		},
+	}
+	if a.logger != nil {
+		a.logger.Info(
+			"inbound action received",
+			slog.String("config_id", cfg.ID),
+			slog.String("chat_id", chatID),
+			slog.String("user_id", attrs["user_id"]),
+			slog.String("value", common.SummarizeText(data)),
+		)
 	}
-	return channel.NewConnection(cfg, stop), nil
+	go func() {
+		if err := handler(ctx, cfg, msg); err != nil && a.logger != nil {
+			a.logger.Error("handle inbound failed", slog.String("config_id", cfg.ID), slog.Any("error", err))
+		}
+	}()
 }
 
 // Send delivers an outbound message to Telegram, handling text, attachments, and replies.
-func (a *TelegramAdapter) Send(ctx context.Context, cfg channel.ChannelConfig, msg channel.OutboundMessage) error {
+func (a *TelegramAdapter) Send(ctx context.Context, cfg channel.ChannelConfig, msg channel.OutboundMessage) (channel.SendResult, error) {
 	telegramCfg, err := parseConfig(cfg.Credentials)
 	if err != nil {
 		if a.logger != nil {
 			a.logger.Error("decode config failed", slog.String("config_id", cfg.ID), slog.Any("error", err))
 		}
-		return err
+		return channel.SendResult{}, err
 	}
 	to := strings.TrimSpace(msg.Target)
 	if to == "" {
-		return fmt.Errorf("telegram target is required")
+		return channel.SendResult{}, fmt.Errorf("telegram target is required")
 	}
 	bot, err := a.getOrCreateBot(telegramCfg.BotToken, cfg.ID)
 	if err != nil {
-		return err
+		return channel.SendResult{}, err
 	}
 	if msg.Message.IsEmpty() {
-		return fmt.Errorf("message is required")
+		return channel.SendResult{}, fmt.Errorf("message is required")
 	}
 	text := strings.TrimSpace(msg.Message.PlainText())
 	parseMode := resolveTelegramParseMode(msg.Message.Format)
 	replyTo := parseReplyToMessageID(msg.Message.Reply)
+	keyboard := buildTelegramKeyboard(msg.Message.Actions)
+	var result channel.SendResult
 	if len(msg.Message.Attachments) > 0 {
-		usedCaption := false
+		// The keyboard goes on the text message, so the text is not used as a caption.
+		usedCaption := keyboard != nil
 		for i, att := range msg.Message.Attachments {
 			caption := ""
 			if !usedCaption && text != "" {
@@ -283,19 +399,185 @@ func (a *TelegramAdapter) Send(ctx context.Context, cfg channel.ChannelConfig, m
 			if i > 0 {
 				applyReply = 0
 			}
-			if err := sendTelegramAttachment(bot, to, att, caption, applyReply, parseMode); err != nil {
+			messageID, err := sendTelegramAttachment(bot, to, att, caption, applyReply, parseMode)
+			if err != nil {
 				if a.logger != nil {
 					a.logger.Error("send attachment failed", slog.String("config_id", cfg.ID), slog.Any("error", err))
 				}
-				return err
+				return result, err
 			}
+			result.MessageIDs = append(result.MessageIDs, strconv.Itoa(messageID))
 		}
-		if text != "" && !usedCaption {
-			return sendTelegramText(bot, to, text, replyTo, parseMode)
+		if text == "" || (usedCaption && keyboard == nil) {
+			return result, nil
 		}
-		return nil
 	}
-	return sendTelegramText(bot, to, text, replyTo, parseMode)
+	messageID, err := sendTelegramText(bot, to, text, replyTo, parseMode, keyboard)
+	if err != nil {
+		return result, err
+	}
+	result.MessageIDs = append(result.MessageIDs, strconv.Itoa(messageID))
+	return result, nil
+}
+
+// SendEditable sends a text message and returns its message ID so it can be edited later.
+func (a *TelegramAdapter) SendEditable(ctx context.Context, cfg channel.ChannelConfig, msg channel.OutboundMessage) (string, error) {
+	telegramCfg, err := parseConfig(cfg.Credentials)
+	if err != nil {
+		return "", err
+	}
+	to := strings.TrimSpace(msg.Target)
+	if to == "" {
+		return "", fmt.Errorf("telegram target is required")
+	}
+	text := strings.TrimSpace(msg.Message.PlainText())
+	if text == "" {
+		return "", fmt.Errorf("message is required")
+	}
+	bot, err := a.getOrCreateBot(telegramCfg.BotToken, cfg.ID)
+	if err != nil {
+		return "", err
+	}
+	messageID, err := sendTelegramText(bot, to, text, parseReplyToMessageID(msg.Message.Reply), resolveTelegramParseMode(msg.Message.Format), buildTelegramKeyboard(msg.Message.Actions))
+	if err != nil {
+		return "", err
+	}
+	return strconv.Itoa(messageID), nil
+}
+
+// Edit replaces the text of a previously sent Telegram message.
+func (a *TelegramAdapter) Edit(ctx context.Context, cfg channel.ChannelConfig, target, messageID string, msg channel.Message) error {
+	telegramCfg, err := parseConfig(cfg.Credentials)
+	if err != nil {
+		return err
+	}
+	id, err := strconv.Atoi(strings.TrimSpace(messageID))
+	if err != nil {
+		return fmt.Errorf("telegram message id must be numeric")
+	}
+	text := strings.TrimSpace(msg.PlainText())
+	if text == "" {
+		return fmt.Errorf("message is required")
+	}
+	bot, err := a.getOrCreateBot(telegramCfg.BotToken, cfg.ID)
+	if err != nil {
+		return err
+	}
+	to := strings.TrimSpace(target)
+	var edit tgbotapi.EditMessageTextConfig
+	if strings.HasPrefix(to, "@") {
+		edit = tgbotapi.EditMessageTextConfig{
+			BaseEdit: tgbotapi.BaseEdit{ChannelUsername: to, MessageID: id},
+			Text:     text,
+		}
+	} else {
+		chatID, err := strconv.ParseInt(to, 10, 64)
+		if err != nil {
+			return fmt.Errorf("telegram target must be @username or chat_id")
+		}
+		edit = tgbotapi.NewEditMessageText(chatID, id, text)
+	}
+	edit.ParseMode = resolveTelegramParseMode(msg.Format)
+	if _, err := bot.Request(edit); err != nil {
+		// Telegram rejects edits that leave the message unchanged; the message already shows the text.
+		if strings.Contains(err.Error(), "message is not modified") {
+			return nil
+		}
+		return err
+	}
+	return nil
+}
+
+// Unsend deletes a previously sent Telegram message.
+func (a *TelegramAdapter) Unsend(ctx context.Context, cfg channel.ChannelConfig, target, messageID string) error {
+	telegramCfg, err := parseConfig(cfg.Credentials)
+	if err != nil {
+		return err
+	}
+	id, err := strconv.Atoi(strings.TrimSpace(messageID))
+	if err != nil {
+		return fmt.Errorf("telegram message id must be numeric")
+	}
+	bot, err := a.getOrCreateBot(telegramCfg.BotToken, cfg.ID)
+	if err != nil {
+		return err
+	}
+	to := strings.TrimSpace(target)
+	var deletion tgbotapi.DeleteMessageConfig
+	if strings.HasPrefix(to, "@") {
+		deletion = tgbotapi.DeleteMessageConfig{ChannelUsername: to, MessageID: id}
+	} else {
+		chatID, err := strconv.ParseInt(to, 10, 64)
+		if err != nil {
+			return fmt.Errorf("telegram target must be @username or chat_id")
+		}
+		deletion = tgbotapi.NewDeleteMessage(chatID, id)
+	}
+	_, err = bot.Request(deletion)
+	return err
+}
+
+// SendPresence shows the typing indicator in the chat the message came from.
+// Telegram clears it after about five seconds, so the caller refreshes it.
+func (a *TelegramAdapter) SendPresence(ctx context.Context, cfg channel.ChannelConfig, msg channel.InboundMessage) (string, error) {
+	telegramCfg, err := parseConfig(cfg.Credentials)
+	if err != nil {
+		return "", err
+	}
+	to := strings.TrimSpace(msg.ReplyTarget)
+	if to == "" {
+		return "", fmt.Errorf("telegram target is required")
+	}
+	bot, err := a.getOrCreateBot(telegramCfg.BotToken, cfg.ID)
+	if err != nil {
+		return "", err
+	}
+	var action tgbotapi.ChatActionConfig
+	if strings.HasPrefix(to, "@") {
+		action = tgbotapi.ChatActionConfig{
+			BaseChat: tgbotapi.BaseChat{ChannelUsername: to},
+			Action:   tgbotapi.ChatTyping,
+		}
+	} else {
+		chatID, err := strconv.ParseInt(to, 10, 64)
+		if err != nil {
+			return "", fmt.Errorf("telegram target must be @username or chat_id")
+		}
+		action = tgbotapi.NewChatAction(chatID, tgbotapi.ChatTyping)
+	}
+	_, err = bot.Request(action)
+	return "", err
+}
+
+// ClearPresence is a no-op: the typing indicator ends when the reply is sent or times out.
+func (a *TelegramAdapter) ClearPresence(ctx context.Context, cfg channel.ChannelConfig, msg channel.InboundMessage, presenceID string) error {
+	return nil
+}
+
+// RegisterCommands publishes the commands to the bot's command menu with setMyCommands.
+func (a *TelegramAdapter) RegisterCommands(ctx context.Context, cfg channel.ChannelConfig, commands []channel.Command) error {
+	telegramCfg, err := parseConfig(cfg.Credentials)
+	if err != nil {
+		return err
+	}
+	bot, err := a.getOrCreateBot(telegramCfg.BotToken, cfg.ID)
+	if err != nil {
+		return err
+	}
+	items := make([]tgbotapi.BotCommand, 0, len(commands))
+	for _, command := range commands {
+		name := strings.ToLower(strings.TrimPrefix(strings.TrimSpace(command.Name), "/"))
+		if name == "" {
+			continue
+		}
+		description := strings.TrimSpace(command.Description)
+		if description == "" {
+			description = name
+		}
+		items = append(items, tgbotapi.BotCommand{Command: name, Description: description})
+	}
+	_, err = bot.Request(tgbotapi.NewSetMyCommands(items...))
+	return err
 }
 
 func resolveTelegramSender(msg *tgbotapi.Message) (string, string, map[string]string) {
@@ -367,32 +649,34 @@ func parseReplyToMessageID(reply *channel.ReplyRef) int {
 	return value
 }
 
-func sendTelegramText(bot *tgbotapi.BotAPI, target string, text string, replyTo int, parseMode string) error {
+func sendTelegramText(bot *tgbotapi.BotAPI, target string, text string, replyTo int, parseMode string, keyboard *tgbotapi.InlineKeyboardMarkup) (int, error) {
+	var message tgbotapi.MessageConfig
 	if strings.HasPrefix(target, "@") {
-		message := tgbotapi.NewMessageToChannel(target, text)
-		message.ParseMode = parseMode
-		if replyTo > 0 {
-			message.ReplyToMessageID = replyTo
+		message = tgbotapi.NewMessageToChannel(target, text)
+	} else {
+		chatID, err := strconv.ParseInt(target, 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("telegram target must be @username or chat_id")
 		}
-		_, err := bot.Send(message)
-		return err
-	}
-	chatID, err := strconv.ParseInt(target, 10, 64)
-	if err != nil {
-		return fmt.Errorf("telegram target must be @username or chat_id")
+		message = tgbotapi.NewMessage(chatID, text)
 	}
-	message := tgbotapi.NewMessage(chatID, text)
 	message.ParseMode = parseMode
 	if replyTo > 0 {
 		message.ReplyToMessageID = replyTo
 	}
-	_, err = bot.Send(message)
-	return err
+	if keyboard != nil {
+		message.ReplyMarkup = *keyboard
+	}
+	sent, err := bot.Send(message)
+	if err != nil {
+		return 0, err
+	}
+	return sent.MessageID, nil
 }
 
-func sendTelegramAttachment(bot *tgbotapi.BotAPI, target string, att channel.Attachment, caption string, replyTo int, parseMode string) error {
+func sendTelegramAttachment(bot *tgbotapi.BotAPI, target string, att channel.Attachment, caption string, replyTo int, parseMode string) (int, error) {
 	if strings.TrimSpace(att.URL) == "" {
-		return fmt.Errorf("attachment url is required")
+		return 0, fmt.Errorf("attachment url is required")
 	}
 	if strings.TrimSpace(caption) == "" && strings.TrimSpace(att.Caption) != "" {
 		caption = strings.TrimSpace(att.Caption)
@@ -407,7 +691,7 @@ func sendTelegramAttachment(bot *tgbotapi.BotAPI, target string, att channel.Att
 		} else {
 			chatID, err := strconv.ParseInt(target, 10, 64)
 			if err != nil {
-				return fmt.Errorf("telegram target must be @username or chat_id")
+				return 0, fmt.Errorf("telegram target must be @username or chat_id")
 			}
 			photo = tgbotapi.NewPhoto(chatID, file)
 		}
@@ -416,8 +700,8 @@ func sendTelegramAttachment(bot *tgbotapi.BotAPI, target string, att channel.Att
 		if replyTo > 0 {
 			photo.ReplyToMessageID = replyTo
 		}
-		_, err := bot.Send(photo)
-		return err
+		sent, err := bot.Send(photo)
+		return sent.MessageID, err
 	case channel.AttachmentFile, "":
 		var document tgbotapi.DocumentConfig
 		if isChannel {
@@ -430,7 +714,7 @@ func sendTelegramAttachment(bot *tgbotapi.BotAPI, target string, att channel.Att
 		} else {
 			chatID, err := strconv.ParseInt(target, 10, 64)
 			if err != nil {
-				return fmt.Errorf("telegram target must be @username or chat_id")
+				return 0, fmt.Errorf("telegram target must be @username or chat_id")
 			}
 			document = tgbotapi.NewDocument(chatID, file)
 		}
@@ -439,59 +723,133 @@ func sendTelegramAttachment(bot *tgbotapi.BotAPI, target string, att channel.Att
 		if replyTo > 0 {
 			document.ReplyToMessageID = replyTo
 		}
-		_, sendErr := bot.Send(document)
-		return sendErr
+		sent, err := bot.Send(document)
+		return sent.MessageID, err
 	case channel.AttachmentAudio:
 		audio, err := buildTelegramAudio(target, file)
 		if err != nil {
-			return err
+			return 0, err
 		}
 		audio.Caption = caption
 		audio.ParseMode = parseMode
 		if replyTo > 0 {
 			audio.ReplyToMessageID = replyTo
 		}
-		_, err = bot.Send(audio)
-		return err
+		sent, err := bot.Send(audio)
+		return sent.MessageID, err
 	case channel.AttachmentVoice:
 		voice, err := buildTelegramVoice(target, file)
 		if err != nil {
-			return err
+			return 0, err
 		}
 		voice.Caption = caption
 		voice.ParseMode = parseMode
 		if replyTo > 0 {
 			voice.ReplyToMessageID = replyTo
 		}
-		_, err = bot.Send(voice)
-		return err
+		sent, err := bot.Send(voice)
+		return sent.MessageID, err
 	case channel.AttachmentVideo:
 		video, err := buildTelegramVideo(target, file)
 		if err != nil {
-			return err
+			return 0, err
 		}
 		video.Caption = caption
 		video.ParseMode = parseMode
 		if replyTo > 0 {
 			video.ReplyToMessageID = replyTo
 		}
-		_, err = bot.Send(video)
-		return err
+		sent, err := bot.Send(video)
+		return sent.MessageID, err
 	case channel.AttachmentGIF:
 		animation, err := buildTelegramAnimation(target, file)
 		if err != nil {
-			return err
+			return 0, err
 		}
 		animation.Caption = caption
 		animation.ParseMode = parseMode
 		if replyTo > 0 {
 			animation.ReplyToMessageID = replyTo
 		}
-		_, err = bot.Send(animation)
-		return err
+		sent, err := bot.Send(animation)
+		return sent.MessageID, err
 	default:
-		return fmt.Errorf("unsupported attachment type: %s", att.Type)
+		return 0, fmt.Errorf("unsupported attachment type: %s", att.Type)
+	}
+}
+
+// telegramAddressMetadata records whether a message @mentions the bot or replies to one of its messages.
+func telegramAddressMetadata(self tgbotapi.User, msg *tgbotapi.Message) map[string]any {
+	mentioned := false
+	if username := strings.ToLower(strings.TrimSpace(self.UserName)); username != "" {
+		text := strings.ToLower(msg.Text + " " + msg.Caption)
+		mentioned = strings.Contains(text, "@"+username)
+	}
+	for _, entity := range append(msg.Entities, msg.CaptionEntities...) {
+		if entity.Type == "text_mention" && entity.User != nil && entity.User.ID == self.ID {
+			mentioned = true
+		}
+	}
+	replyToBot := msg.ReplyToMessage != nil && msg.ReplyToMessage.From != nil && msg.ReplyToMessage.From.ID == self.ID
+	return map[string]any{
+		channel.MetadataKeyMentioned:  mentioned,
+		channel.MetadataKeyReplyToBot: replyToBot,
+	}
+}
+
+// buildTelegramKeyboard renders message actions as an inline keyboard, three buttons per row.
+// Actions with a URL become link buttons; the others send their value back as callback data,
+// which Telegram limits to 64 bytes.
+func buildTelegramKeyboard(actions []channel.Action) *tgbotapi.InlineKeyboardMarkup {
+	var rows [][]tgbotapi.InlineKeyboardButton
+	var row []tgbotapi.InlineKeyboardButton
+	for _, action := range actions {
+		label := strings.TrimSpace(action.Label)
+		value := strings.TrimSpace(action.Value)
+		if label == "" {
+			label = value
+		}
+		if label == "" {
+			continue
+		}
+		var button tgbotapi.InlineKeyboardButton
+		switch {
+		case strings.TrimSpace(action.URL) != "":
+			button = tgbotapi.NewInlineKeyboardButtonURL(label, strings.TrimSpace(action.URL))
+		case value != "" && len(value) <= telegramCallbackDataLimit:
+			button = tgbotapi.NewInlineKeyboardButtonData(label, value)
+		default:
+			continue
+		}
+		row = append(row, button)
+		if len(row) == 3 {
+			rows = append(rows, row)
+			row = nil
+		}
+	}
+	if len(row) > 0 {
+		rows = append(rows, row)
+	}
+	if len(rows) == 0 {
+		return nil
+	}
+	keyboard := tgbotapi.NewInlineKeyboardMarkup(rows...)
+	return &keyboard
+}
+
+// telegramButtonLabel finds the text of the inline keyboard button that sent data.
+func telegramButtonLabel(markup *tgbotapi.InlineKeyboardMarkup, data string) string {
+	if markup == nil {
+		return ""
+	}
+	for _, row := range markup.InlineKeyboard {
+		for _, button := range row {
+			if button.CallbackData != nil && *button.CallbackData == data {
+				return strings.TrimSpace(button.Text)
+			}
+		}
 	}
+	return ""
 }
 
 func buildTelegramReplyRef(msg *tgbotapi.Message, chatID string) *channel.ReplyRef {
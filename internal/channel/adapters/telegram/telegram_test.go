@@ -1,9 +1,16 @@
 package telegram
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
 	"testing"
+	"time"
 
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"github.com/memohai/memoh/internal/channel"
 )
 
 func TestResolveTelegramSender(t *testing.T) {
@@ -24,3 +31,280 @@ func TestResolveTelegramSender(t *testing.T) {
 		t.Fatalf("unexpected attrs: %#v", attrs)
 	}
 }
+
+func TestTelegramAddressMetadata(t *testing.T) {
+	t.Parallel()
+
+	self := tgbotapi.User{ID: 99, UserName: "memoh_bot"}
+	meta := telegramAddressMetadata(self, &tgbotapi.Message{Text: "hi @Memoh_Bot"})
+	if meta[channel.MetadataKeyMentioned] != true || meta[channel.MetadataKeyReplyToBot] != false {
+		t.Fatalf("unexpected metadata for username mention: %#v", meta)
+	}
+	meta = telegramAddressMetadata(self, &tgbotapi.Message{
+		Text:     "hi",
+		Entities: []tgbotapi.MessageEntity{{Type: "text_mention", User: &tgbotapi.User{ID: 99}}},
+	})
+	if meta[channel.MetadataKeyMentioned] != true {
+		t.Fatalf("expected text mention to count: %#v", meta)
+	}
+	meta = telegramAddressMetadata(self, &tgbotapi.Message{
+		Text:           "sure",
+		ReplyToMessage: &tgbotapi.Message{From: &tgbotapi.User{ID: 99}},
+	})
+	if meta[channel.MetadataKeyMentioned] != false || meta[channel.MetadataKeyReplyToBot] != true {
+		t.Fatalf("unexpected metadata for reply: %#v", meta)
+	}
+}
+
+func TestTelegramSendEditableAndEdit(t *testing.T) {
+	t.Parallel()
+
+	fake := newFakeTelegram(t)
+	adapter := fake.adapter()
+	cfg := channel.ChannelConfig{
+		ID:          "cfg-edit",
+		BotID:       "bot-1",
+		ChannelType: Type,
+		Credentials: map[string]any{"botToken": "token-1"},
+	}
+	ctx := context.Background()
+	messageID, err := adapter.SendEditable(ctx, cfg, channel.OutboundMessage{
+		Target:  "100",
+		Message: channel.Message{Text: "draft"},
+	})
+	if err != nil {
+		t.Fatalf("send editable failed: %v", err)
+	}
+	if messageID != "77" {
+		t.Fatalf("unexpected message id: %q", messageID)
+	}
+	if err := adapter.Edit(ctx, cfg, "100", messageID, channel.Message{Text: "*final*", Format: channel.MessageFormatMarkdown}); err != nil {
+		t.Fatalf("edit failed: %v", err)
+	}
+	if err := adapter.Edit(ctx, cfg, "100", messageID, channel.Message{Text: "unchanged"}); err != nil {
+		t.Fatalf("expected unchanged edit to be ignored, got %v", err)
+	}
+	if err := adapter.Edit(ctx, cfg, "100", "abc", channel.Message{Text: "x"}); err == nil {
+		t.Fatalf("expected error for non-numeric message id")
+	}
+	edits := fake.recorded("editMessageText")
+	if len(edits) != 2 {
+		t.Fatalf("unexpected edit calls: %#v", edits)
+	}
+	if edits[0].Get("chat_id") != "100" || edits[0].Get("message_id") != "77" || edits[0].Get("parse_mode") != "Markdown" {
+		t.Fatalf("unexpected edit payload: %#v", edits[0])
+	}
+}
+
+func TestTelegramUnsend(t *testing.T) {
+	t.Parallel()
+
+	fake := newFakeTelegram(t)
+	adapter := fake.adapter()
+	cfg := channel.ChannelConfig{
+		ID:          "cfg-unsend",
+		BotID:       "bot-1",
+		ChannelType: Type,
+		Credentials: map[string]any{"botToken": "token-1"},
+	}
+	ctx := context.Background()
+	if err := adapter.Unsend(ctx, cfg, "100", "77"); err != nil {
+		t.Fatalf("unsend failed: %v", err)
+	}
+	if err := adapter.Unsend(ctx, cfg, "@memoh_channel", "78"); err != nil {
+		t.Fatalf("unsend by channel username failed: %v", err)
+	}
+	if err := adapter.Unsend(ctx, cfg, "100", "abc"); err == nil {
+		t.Fatalf("expected error for non-numeric message id")
+	}
+	deletes := fake.recorded("deleteMessage")
+	if len(deletes) != 2 {
+		t.Fatalf("unexpected delete calls: %#v", deletes)
+	}
+	if deletes[0].Get("chat_id") != "100" || deletes[0].Get("message_id") != "77" || deletes[1].Get("chat_id") != "@memoh_channel" {
+		t.Fatalf("unexpected delete payload: %#v", deletes)
+	}
+}
+
+func TestTelegramSendPresence(t *testing.T) {
+	t.Parallel()
+
+	fake := newFakeTelegram(t)
+	adapter := fake.adapter()
+	cfg := channel.ChannelConfig{
+		ID:          "cfg-presence",
+		BotID:       "bot-1",
+		ChannelType: Type,
+		Credentials: map[string]any{"botToken": "token-1"},
+	}
+	if _, err := adapter.SendPresence(context.Background(), cfg, channel.InboundMessage{ReplyTarget: "100"}); err != nil {
+		t.Fatalf("send presence failed: %v", err)
+	}
+	if _, err := adapter.SendPresence(context.Background(), cfg, channel.InboundMessage{ReplyTarget: "not-a-chat"}); err == nil {
+		t.Fatalf("expected error for invalid target")
+	}
+	actions := fake.recorded("sendChatAction")
+	if len(actions) != 1 || actions[0].Get("chat_id") != "100" || actions[0].Get("action") != "typing" {
+		t.Fatalf("unexpected chat actions: %#v", actions)
+	}
+}
+
+func TestTelegramDirectory(t *testing.T) {
+	t.Parallel()
+
+	fake := newFakeTelegram(t)
+	directory := fake.adapter().Directory()
+	cfg := channel.ChannelConfig{
+		ID:          "cfg-directory",
+		BotID:       "bot-1",
+		ChannelType: Type,
+		Credentials: map[string]any{"botToken": "token-1"},
+	}
+	ctx := context.Background()
+
+	group, err := directory.ResolveTarget(ctx, cfg, "t.me/memoh_group", "")
+	if err != nil {
+		t.Fatalf("resolve group failed: %v", err)
+	}
+	if group.Kind != channel.DirectoryEntryGroup || group.ID != "-1001" || group.Name != "Memoh Group" || group.Handle != "@memoh_group" {
+		t.Fatalf("unexpected group entry: %+v", group)
+	}
+	user, err := directory.ResolveTarget(ctx, cfg, "100", channel.DirectoryEntryUser)
+	if err != nil {
+		t.Fatalf("resolve user failed: %v", err)
+	}
+	if user.Kind != channel.DirectoryEntryUser || user.Name != "Alice Liddell" || user.Handle != "@alice" {
+		t.Fatalf("unexpected user entry: %+v", user)
+	}
+	if _, err := directory.ResolveTarget(ctx, cfg, "@missing", ""); !errors.Is(err, channel.ErrDirectoryEntryNotFound) {
+		t.Fatalf("expected not found, got %v", err)
+	}
+	if _, err := directory.ResolveTarget(ctx, cfg, "Alice", ""); !errors.Is(err, channel.ErrDirectoryNotSupported) {
+		t.Fatalf("expected plain names to be unsupported, got %v", err)
+	}
+	if _, err := directory.ListPeers(ctx, cfg, channel.DirectoryQuery{}); !errors.Is(err, channel.ErrDirectoryNotSupported) {
+		t.Fatalf("expected peer listing to be unsupported, got %v", err)
+	}
+
+	members, err := directory.ListGroupMembers(ctx, cfg, "-1001", channel.DirectoryQuery{Query: "bob"})
+	if err != nil {
+		t.Fatalf("list members failed: %v", err)
+	}
+	if len(members) != 1 || members[0].ID != "101" || members[0].Metadata["status"] != "administrator" {
+		t.Fatalf("unexpected members: %+v", members)
+	}
+}
+
+func TestTelegramRegisterCommands(t *testing.T) {
+	t.Parallel()
+
+	fake := newFakeTelegram(t)
+	adapter := fake.adapter()
+	cfg := channel.ChannelConfig{ID: "cfg-1", Credentials: map[string]any{"botToken": "token"}}
+	err := adapter.RegisterCommands(context.Background(), cfg, []channel.Command{
+		{Name: "/New", Description: "Start a new session"},
+		{Name: "whoami"},
+		{Name: " "},
+	})
+	if err != nil {
+		t.Fatalf("register commands failed: %v", err)
+	}
+	calls := fake.recorded("setMyCommands")
+	if len(calls) != 1 {
+		t.Fatalf("unexpected setMyCommands calls: %#v", calls)
+	}
+	var commands []tgbotapi.BotCommand
+	if err := json.Unmarshal([]byte(calls[0].Get("commands")), &commands); err != nil {
+		t.Fatalf("decode commands: %v", err)
+	}
+	if len(commands) != 2 || commands[0].Command != "new" || commands[1].Command != "whoami" || commands[1].Description != "whoami" {
+		t.Fatalf("unexpected commands: %#v", commands)
+	}
+}
+
+func TestTelegramSendInlineKeyboard(t *testing.T) {
+	t.Parallel()
+
+	fake := newFakeTelegram(t)
+	adapter := fake.adapter()
+	cfg := channel.ChannelConfig{ID: "cfg-1", Credentials: map[string]any{"botToken": "token"}}
+	result, err := adapter.Send(context.Background(), cfg, channel.OutboundMessage{
+		Target: "100",
+		Message: channel.Message{
+			Text: "Deploy to production?",
+			Actions: []channel.Action{
+				{Type: "button", Label: "Approve", Value: "approve"},
+				{Type: "button", Label: "Reject", Value: "reject"},
+				{Type: "button", Label: "Docs", URL: "https://memoh.example.com/docs"},
+				{Type: "button", Label: "Too long", Value: strings.Repeat("x", 65)},
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("send failed: %v", err)
+	}
+	if len(result.MessageIDs) != 1 || result.MessageIDs[0] != "77" {
+		t.Fatalf("unexpected message ids: %#v", result.MessageIDs)
+	}
+	calls := fake.recorded("sendMessage")
+	if len(calls) != 1 {
+		t.Fatalf("unexpected sendMessage calls: %#v", calls)
+	}
+	var markup tgbotapi.InlineKeyboardMarkup
+	if err := json.Unmarshal([]byte(calls[0].Get("reply_markup")), &markup); err != nil {
+		t.Fatalf("decode reply markup: %v", err)
+	}
+	if len(markup.InlineKeyboard) != 1 || len(markup.InlineKeyboard[0]) != 3 {
+		t.Fatalf("unexpected keyboard: %#v", markup)
+	}
+	row := markup.InlineKeyboard[0]
+	if row[0].CallbackData == nil || *row[0].CallbackData != "approve" || row[2].URL == nil || *row[2].URL != "https://memoh.example.com/docs" {
+		t.Fatalf("unexpected buttons: %#v", row)
+	}
+}
+
+func TestTelegramCallbackQuery(t *testing.T) {
+	t.Parallel()
+
+	fake := newFakeTelegram(t)
+	adapter := fake.adapter()
+	cfg := channel.ChannelConfig{ID: "cfg-1", BotID: "bot-1", Credentials: map[string]any{"botToken": "token"}}
+	bot, err := adapter.getOrCreateBot("token", "cfg-1")
+	if err != nil {
+		t.Fatalf("create bot failed: %v", err)
+	}
+	approve := "approve"
+	query := &tgbotapi.CallbackQuery{
+		ID:   "cb-1",
+		From: &tgbotapi.User{ID: 123, UserName: "alice"},
+		Data: approve,
+		Message: &tgbotapi.Message{
+			MessageID: 77,
+			Chat:      &tgbotapi.Chat{ID: -100, Type: "group", Title: "Team"},
+			ReplyMarkup: &tgbotapi.InlineKeyboardMarkup{InlineKeyboard: [][]tgbotapi.InlineKeyboardButton{
+				{{Text: "Approve", CallbackData: &approve}},
+			}},
+		},
+	}
+	received := make(chan channel.InboundMessage, 1)
+	adapter.handleUpdate(context.Background(), bot, cfg, func(ctx context.Context, cfg channel.ChannelConfig, msg channel.InboundMessage) error {
+		received <- msg
+		return nil
+	}, tgbotapi.Update{CallbackQuery: query})
+
+	select {
+	case msg := <-received:
+		if msg.Action == nil || msg.Action.Value != "approve" || msg.Action.Label != "Approve" || msg.Action.MessageID != "77" {
+			t.Fatalf("unexpected action: %#v", msg.Action)
+		}
+		if msg.ReplyTarget != "-100" || msg.Conversation.Type != "group" || msg.Sender.ExternalID != "123" || msg.BotID != "bot-1" {
+			t.Fatalf("unexpected inbound: %#v", msg)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatalf("timed out waiting for inbound action")
+	}
+	answers := fake.recorded("answerCallbackQuery")
+	if len(answers) != 1 || answers[0].Get("callback_query_id") != "cb-1" {
+		t.Fatalf("unexpected answerCallbackQuery calls: %#v", answers)
+	}
+}
@@ -0,0 +1,135 @@
+package telegram
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"github.com/memohai/memoh/internal/channel"
+)
+
+// secretTokenHeader carries the secret_token registered with setWebhook.
+const secretTokenHeader = "X-Telegram-Bot-Api-Secret-Token"
+
+// maxWebhookBodyBytes bounds the size of a single webhook update payload.
+const maxWebhookBodyBytes = 1 << 20
+
+// telegramWebhook is an active webhook-mode connection.
+type telegramWebhook struct {
+	ctx     context.Context
+	cfg     channel.ChannelConfig
+	bot     *tgbotapi.BotAPI
+	secret  string
+	handler channel.InboundHandler
+}
+
+// webhookPath returns the server route that receives updates for a channel config.
+func webhookPath(configID string) string {
+	return "/channels/" + Type.String() + "/webhook/" + configID
+}
+
+func (a *TelegramAdapter) connectWebhook(ctx context.Context, cfg channel.ChannelConfig, telegramCfg Config, bot *tgbotapi.BotAPI, handler channel.InboundHandler) (channel.Connection, error) {
+	secret := telegramCfg.WebhookSecret
+	if secret == "" {
+		generated, err := generateSecretToken()
+		if err != nil {
+			return nil, err
+		}
+		secret = generated
+	}
+	connCtx, cancel := context.WithCancel(ctx)
+	webhook := &telegramWebhook{
+		ctx:     connCtx,
+		cfg:     cfg,
+		bot:     bot,
+		secret:  secret,
+		handler: handler,
+	}
+	a.mu.Lock()
+	a.webhooks[cfg.ID] = webhook
+	a.mu.Unlock()
+
+	url := telegramCfg.WebhookBaseURL + webhookPath(cfg.ID)
+	if _, err := bot.MakeRequest("setWebhook", tgbotapi.Params{
+		"url":          url,
+		"secret_token": secret,
+	}); err != nil {
+		a.removeWebhook(cfg.ID, webhook)
+		cancel()
+		if a.logger != nil {
+			a.logger.Error("set webhook failed", slog.String("config_id", cfg.ID), slog.Any("error", err))
+		}
+		return nil, err
+	}
+	if a.logger != nil {
+		a.logger.Info("webhook registered", slog.String("config_id", cfg.ID), slog.String("url", url))
+	}
+
+	stop := func(context.Context) error {
+		if a.logger != nil {
+			a.logger.Info("stop", slog.String("config_id", cfg.ID))
+		}
+		a.removeWebhook(cfg.ID, webhook)
+		cancel()
+		if _, err := bot.Request(tgbotapi.DeleteWebhookConfig{}); err != nil {
+			if a.logger != nil {
+				a.logger.Error("delete webhook failed", slog.String("config_id", cfg.ID), slog.Any("error", err))
+			}
+			return err
+		}
+		return nil
+	}
+	return channel.NewConnection(cfg, stop), nil
+}
+
+// removeWebhook unregisters webhook unless it has already been replaced by a newer connection.
+func (a *TelegramAdapter) removeWebhook(configID string, webhook *telegramWebhook) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.webhooks[configID] == webhook {
+		delete(a.webhooks, configID)
+	}
+}
+
+// HandleWebhook verifies and dispatches a Telegram update pushed to the webhook route.
+func (a *TelegramAdapter) HandleWebhook(ctx context.Context, configID string, req *http.Request) error {
+	a.mu.RLock()
+	webhook, ok := a.webhooks[configID]
+	a.mu.RUnlock()
+	if !ok {
+		return channel.ErrWebhookNotFound
+	}
+	token := req.Header.Get(secretTokenHeader)
+	if subtle.ConstantTimeCompare([]byte(token), []byte(webhook.secret)) != 1 {
+		if a.logger != nil {
+			a.logger.Warn("webhook secret mismatch", slog.String("config_id", configID))
+		}
+		return channel.ErrWebhookUnauthorized
+	}
+	body, err := io.ReadAll(io.LimitReader(req.Body, maxWebhookBodyBytes))
+	if err != nil {
+		return fmt.Errorf("read telegram update: %w", err)
+	}
+	var update tgbotapi.Update
+	if err := json.Unmarshal(body, &update); err != nil {
+		return fmt.Errorf("decode telegram update: %w", err)
+	}
+	a.handleUpdate(webhook.ctx, webhook.bot, webhook.cfg, webhook.handler, update)
+	return nil
+}
+
+func generateSecretToken() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("generate webhook secret: %w", err)
+	}
+	return hex.EncodeToString(buf), nil
+}
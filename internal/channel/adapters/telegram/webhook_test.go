@@ -0,0 +1,193 @@
+package telegram
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/memohai/memoh/internal/channel"
+)
+
+// fakeTelegram serves the Bot API methods used in webhook mode, editable replies, presence
+// and directory lookups.
+type fakeTelegram struct {
+	server *httptest.Server
+
+	mu    sync.Mutex
+	calls map[string][]url.Values
+}
+
+func newFakeTelegram(t *testing.T) *fakeTelegram {
+	t.Helper()
+	f := &fakeTelegram{calls: map[string][]url.Values{}}
+	f.server = httptest.NewServer(http.HandlerFunc(f.serve))
+	t.Cleanup(f.server.Close)
+	return f
+}
+
+func (f *fakeTelegram) adapter() *TelegramAdapter {
+	adapter := NewTelegramAdapter(nil)
+	adapter.apiEndpoint = f.server.URL + "/bot%s/%s"
+	return adapter
+}
+
+func (f *fakeTelegram) recorded(method string) []url.Values {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]url.Values(nil), f.calls[method]...)
+}
+
+func (f *fakeTelegram) serve(w http.ResponseWriter, r *http.Request) {
+	_ = r.ParseForm()
+	method := r.URL.Path[strings.LastIndex(r.URL.Path, "/")+1:]
+	f.mu.Lock()
+	f.calls[method] = append(f.calls[method], r.Form)
+	f.mu.Unlock()
+	w.Header().Set("Content-Type", "application/json")
+	switch method {
+	case "getMe":
+		_ = json.NewEncoder(w).Encode(map[string]any{"ok": true, "result": map[string]any{
+			"id": 42, "is_bot": true, "first_name": "Memoh", "username": "memoh_bot",
+		}})
+	case "setWebhook", "deleteWebhook", "sendChatAction", "setMyCommands", "answerCallbackQuery", "deleteMessage":
+		_ = json.NewEncoder(w).Encode(map[string]any{"ok": true, "result": true})
+	case "sendMessage":
+		_ = json.NewEncoder(w).Encode(map[string]any{"ok": true, "result": map[string]any{
+			"message_id": 77, "date": 0, "chat": map[string]any{"id": 100, "type": "private"}, "text": r.Form.Get("text"),
+		}})
+	case "editMessageText":
+		if r.Form.Get("text") == "unchanged" {
+			_ = json.NewEncoder(w).Encode(map[string]any{"ok": false, "error_code": 400, "description": "Bad Request: message is not modified"})
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{"ok": true, "result": true})
+	case "getChat":
+		switch r.Form.Get("chat_id") {
+		case "@memoh_group":
+			_ = json.NewEncoder(w).Encode(map[string]any{"ok": true, "result": map[string]any{
+				"id": -1001, "type": "supergroup", "title": "Memoh Group", "username": "memoh_group",
+			}})
+		case "100":
+			_ = json.NewEncoder(w).Encode(map[string]any{"ok": true, "result": map[string]any{
+				"id": 100, "type": "private", "first_name": "Alice", "last_name": "Liddell", "username": "alice",
+			}})
+		default:
+			_ = json.NewEncoder(w).Encode(map[string]any{"ok": false, "error_code": 400, "description": "Bad Request: chat not found"})
+		}
+	case "getChatAdministrators":
+		_ = json.NewEncoder(w).Encode(map[string]any{"ok": true, "result": []map[string]any{
+			{"status": "creator", "user": map[string]any{"id": 100, "is_bot": false, "first_name": "Alice", "username": "alice"}},
+			{"status": "administrator", "user": map[string]any{"id": 101, "is_bot": false, "first_name": "Bob"}},
+		}})
+	default:
+		_ = json.NewEncoder(w).Encode(map[string]any{"ok": false, "error_code": 404, "description": "Not Found"})
+	}
+}
+
+func webhookRequest(secret, body string) *http.Request {
+	req := httptest.NewRequest(http.MethodPost, webhookPath("cfg-1"), strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	if secret != "" {
+		req.Header.Set(secretTokenHeader, secret)
+	}
+	return req
+}
+
+func TestTelegramWebhookLifecycle(t *testing.T) {
+	t.Parallel()
+
+	fake := newFakeTelegram(t)
+	adapter := fake.adapter()
+	cfg := channel.ChannelConfig{
+		ID:          "cfg-1",
+		BotID:       "bot-1",
+		ChannelType: Type,
+		Credentials: map[string]any{
+			"botToken":       "token-123",
+			"mode":           "webhook",
+			"webhookBaseUrl": "https://memoh.example.com",
+			"webhookSecret":  "s3cret",
+		},
+	}
+	received := make(chan channel.InboundMessage, 1)
+	conn, err := adapter.Connect(context.Background(), cfg, func(ctx context.Context, cfg channel.ChannelConfig, msg channel.InboundMessage) error {
+		received <- msg
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("connect failed: %v", err)
+	}
+	sets := fake.recorded("setWebhook")
+	if len(sets) != 1 || sets[0].Get("url") != "https://memoh.example.com/channels/telegram/webhook/cfg-1" || sets[0].Get("secret_token") != "s3cret" {
+		t.Fatalf("unexpected setWebhook calls: %#v", sets)
+	}
+
+	update := `{"update_id":1,"message":{"message_id":7,"date":1700000000,"text":"hello","chat":{"id":-100,"type":"group","title":"Team"},"from":{"id":123,"username":"alice"}}}`
+	if err := adapter.HandleWebhook(context.Background(), "cfg-1", webhookRequest("wrong", update)); !errors.Is(err, channel.ErrWebhookUnauthorized) {
+		t.Fatalf("expected unauthorized, got %v", err)
+	}
+	if err := adapter.HandleWebhook(context.Background(), "cfg-1", webhookRequest("", update)); !errors.Is(err, channel.ErrWebhookUnauthorized) {
+		t.Fatalf("expected unauthorized without header, got %v", err)
+	}
+	if err := adapter.HandleWebhook(context.Background(), "cfg-1", webhookRequest("s3cret", update)); err != nil {
+		t.Fatalf("handle webhook failed: %v", err)
+	}
+	select {
+	case msg := <-received:
+		if msg.Message.Text != "hello" || msg.Message.ID != "7" || msg.ReplyTarget != "-100" {
+			t.Fatalf("unexpected message: %#v", msg)
+		}
+		if msg.Conversation.Type != "group" || msg.Sender.ExternalID != "123" || msg.BotID != "bot-1" {
+			t.Fatalf("unexpected inbound: %#v", msg)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatalf("timed out waiting for inbound message")
+	}
+
+	if err := conn.Stop(context.Background()); err != nil {
+		t.Fatalf("stop failed: %v", err)
+	}
+	if len(fake.recorded("deleteWebhook")) != 1 {
+		t.Fatalf("expected deleteWebhook on stop")
+	}
+	if err := adapter.HandleWebhook(context.Background(), "cfg-1", webhookRequest("s3cret", update)); !errors.Is(err, channel.ErrWebhookNotFound) {
+		t.Fatalf("expected not found after stop, got %v", err)
+	}
+}
+
+func TestTelegramWebhookGeneratesSecret(t *testing.T) {
+	t.Parallel()
+
+	fake := newFakeTelegram(t)
+	adapter := fake.adapter()
+	cfg := channel.ChannelConfig{
+		ID:          "cfg-1",
+		ChannelType: Type,
+		Credentials: map[string]any{
+			"botToken":       "token-123",
+			"mode":           "webhook",
+			"webhookBaseUrl": "https://memoh.example.com",
+		},
+	}
+	conn, err := adapter.Connect(context.Background(), cfg, func(context.Context, channel.ChannelConfig, channel.InboundMessage) error {
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("connect failed: %v", err)
+	}
+	defer func() { _ = conn.Stop(context.Background()) }()
+	sets := fake.recorded("setWebhook")
+	if len(sets) != 1 || len(sets[0].Get("secret_token")) != 64 {
+		t.Fatalf("expected generated secret, got %#v", sets)
+	}
+	if err := adapter.HandleWebhook(context.Background(), "cfg-1", webhookRequest(sets[0].Get("secret_token"), `{"update_id":2}`)); err != nil {
+		t.Fatalf("handle webhook failed: %v", err)
+	}
+}
@@ -0,0 +1,208 @@
+package webhook
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/memohai/memoh/internal/channel"
+)
+
+// Config holds the webhook endpoint settings extracted from a channel configuration.
+type Config struct {
+	SigningSecret string
+	TargetURL     string
+	Headers       map[string]string
+}
+
+// UserConfig holds the identifiers used to target a user of the remote system.
+type UserConfig struct {
+	UserID string
+	Target string
+}
+
+func normalizeConfig(raw map[string]any) (map[string]any, error) {
+	cfg, err := parseConfig(raw)
+	if err != nil {
+		return nil, err
+	}
+	result := map[string]any{
+		"signingSecret": cfg.SigningSecret,
+		"targetUrl":     cfg.TargetURL,
+	}
+	if len(cfg.Headers) > 0 {
+		headers := make(map[string]any, len(cfg.Headers))
+		for key, value := range cfg.Headers {
+			headers[key] = value
+		}
+		result["headers"] = headers
+	}
+	return result, nil
+}
+
+func normalizeUserConfig(raw map[string]any) (map[string]any, error) {
+	cfg, err := parseUserConfig(raw)
+	if err != nil {
+		return nil, err
+	}
+	result := map[string]any{}
+	if cfg.UserID != "" {
+		result["user_id"] = cfg.UserID
+	}
+	if cfg.Target != "" {
+		result["target"] = cfg.Target
+	}
+	return result, nil
+}
+
+func resolveTarget(raw map[string]any) (string, error) {
+	cfg, err := parseUserConfig(raw)
+	if err != nil {
+		return "", err
+	}
+	if cfg.Target != "" {
+		return cfg.Target, nil
+	}
+	return cfg.UserID, nil
+}
+
+func normalizeTarget(raw string) string {
+	value := strings.TrimSpace(raw)
+	if strings.HasPrefix(strings.ToLower(value), "webhook:") {
+		value = strings.TrimSpace(value[len("webhook:"):])
+	}
+	return value
+}
+
+func matchBinding(raw map[string]any, criteria channel.BindingCriteria) bool {
+	cfg, err := parseUserConfig(raw)
+	if err != nil || cfg.UserID == "" {
+		return false
+	}
+	if value := strings.TrimSpace(criteria.Attribute("user_id")); value != "" && value == cfg.UserID {
+		return true
+	}
+	return criteria.ExternalID != "" && criteria.ExternalID == cfg.UserID
+}
+
+func buildUserConfig(identity channel.Identity) map[string]any {
+	result := map[string]any{}
+	if value := strings.TrimSpace(identity.ExternalID); value != "" {
+		result["user_id"] = value
+	}
+	return result
+}
+
+func parseConfig(raw map[string]any) (Config, error) {
+	secret := strings.TrimSpace(channel.ReadString(raw, "signingSecret", "signing_secret"))
+	if secret == "" {
+		return Config{}, fmt.Errorf("webhook signingSecret is required")
+	}
+	targetURL := strings.TrimSpace(channel.ReadString(raw, "targetUrl", "target_url"))
+	if targetURL == "" {
+		return Config{}, fmt.Errorf("webhook targetUrl is required")
+	}
+	if !strings.HasPrefix(targetURL, "https://") && !strings.HasPrefix(targetURL, "http://") {
+		return Config{}, fmt.Errorf("webhook targetUrl must be an http(s) URL")
+	}
+	headers, err := parseHeaders(raw["headers"])
+	if err != nil {
+		return Config{}, err
+	}
+	return Config{
+		SigningSecret: secret,
+		TargetURL:     targetURL,
+		Headers:       headers,
+	}, nil
+}
+
+// parseHeaders accepts either a string map or "Name: value" lines.
+func parseHeaders(raw any) (map[string]string, error) {
+	pairs := map[string]string{}
+	switch v := raw.(type) {
+	case nil:
+		return nil, nil
+	case map[string]any:
+		for key, value := range v {
+			text, ok := value.(string)
+			if !ok {
+				return nil, fmt.Errorf("webhook header %q must be a string", key)
+			}
+			pairs[key] = text
+		}
+	case map[string]string:
+		for key, value := range v {
+			pairs[key] = value
+		}
+	case string:
+		for _, line := range strings.Split(v, "\n") {
+			line = strings.TrimSpace(line)
+			if line == "" {
+				continue
+			}
+			key, value, ok := strings.Cut(line, ":")
+			if !ok {
+				return nil, fmt.Errorf("webhook header %q must be in Name: value form", line)
+			}
+			pairs[key] = value
+		}
+	default:
+		return nil, fmt.Errorf("webhook headers must be an object")
+	}
+	headers := make(map[string]string, len(pairs))
+	keys := make([]string, 0, len(pairs))
+	for key := range pairs {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		name := http.CanonicalHeaderKey(strings.TrimSpace(key))
+		value := strings.TrimSpace(pairs[key])
+		if !isValidHeaderName(name) {
+			return nil, fmt.Errorf("webhook header name %q is invalid", key)
+		}
+		if strings.ContainsAny(value, "\r\n") {
+			return nil, fmt.Errorf("webhook header %q has an invalid value", name)
+		}
+		if isReservedHeader(name) {
+			return nil, fmt.Errorf("webhook header %q is reserved", name)
+		}
+		headers[name] = value
+	}
+	if len(headers) == 0 {
+		return nil, nil
+	}
+	return headers, nil
+}
+
+func isValidHeaderName(name string) bool {
+	if name == "" {
+		return false
+	}
+	for _, r := range name {
+		if r <= ' ' || r >= 0x7f || strings.ContainsRune("()<>@,;:\\\"/[]?={}", r) {
+			return false
+		}
+	}
+	return true
+}
+
+// isReservedHeader reports whether name is set by the adapter itself.
+func isReservedHeader(name string) bool {
+	switch name {
+	case "Content-Type", "Content-Length", "Host", signatureHeader, timestampHeader:
+		return true
+	default:
+		return false
+	}
+}
+
+func parseUserConfig(raw map[string]any) (UserConfig, error) {
+	userID := strings.TrimSpace(channel.ReadString(raw, "userId", "user_id"))
+	target := normalizeTarget(channel.ReadString(raw, "target"))
+	if userID == "" && target == "" {
+		return UserConfig{}, fmt.Errorf("webhook user config requires user_id or target")
+	}
+	return UserConfig{UserID: userID, Target: target}, nil
+}
@@ -0,0 +1,90 @@
+package webhook
+
+import (
+	"testing"
+
+	"github.com/memohai/memoh/internal/channel"
+)
+
+func TestNormalizeConfig(t *testing.T) {
+	t.Parallel()
+
+	got, err := normalizeConfig(map[string]any{
+		"signing_secret": "s3cret",
+		"target_url":     "https://chat.example.com/hook",
+		"headers":        map[string]any{"x-api-key": " abc "},
+	})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if got["signingSecret"] != "s3cret" || got["targetUrl"] != "https://chat.example.com/hook" {
+		t.Fatalf("unexpected config: %#v", got)
+	}
+	headers, _ := got["headers"].(map[string]any)
+	if len(headers) != 1 || headers["X-Api-Key"] != "abc" {
+		t.Fatalf("unexpected headers: %#v", got["headers"])
+	}
+}
+
+func TestNormalizeConfigHeaderLines(t *testing.T) {
+	t.Parallel()
+
+	cfg, err := parseConfig(map[string]any{
+		"signingSecret": "s3cret",
+		"targetUrl":     "http://localhost:9000/hook",
+		"headers":       "Authorization: Bearer t\n\nX-Team: core",
+	})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if cfg.Headers["Authorization"] != "Bearer t" || cfg.Headers["X-Team"] != "core" {
+		t.Fatalf("unexpected headers: %#v", cfg.Headers)
+	}
+}
+
+func TestNormalizeConfigValidation(t *testing.T) {
+	t.Parallel()
+
+	cases := []map[string]any{
+		{"targetUrl": "https://chat.example.com/hook"},
+		{"signingSecret": "s3cret"},
+		{"signingSecret": "s3cret", "targetUrl": "chat.example.com/hook"},
+		{"signingSecret": "s3cret", "targetUrl": "https://chat.example.com/hook", "headers": "no-colon"},
+		{"signingSecret": "s3cret", "targetUrl": "https://chat.example.com/hook", "headers": map[string]any{"Bad Name": "x"}},
+		{"signingSecret": "s3cret", "targetUrl": "https://chat.example.com/hook", "headers": map[string]any{"X-Memoh-Signature": "x"}},
+		{"signingSecret": "s3cret", "targetUrl": "https://chat.example.com/hook", "headers": map[string]any{"X-Count": 1.0}},
+	}
+	for _, raw := range cases {
+		if _, err := normalizeConfig(raw); err == nil {
+			t.Fatalf("expected error for %#v", raw)
+		}
+	}
+}
+
+func TestResolveTarget(t *testing.T) {
+	t.Parallel()
+
+	target, err := resolveTarget(map[string]any{"user_id": "u-1"})
+	if err != nil || target != "u-1" {
+		t.Fatalf("unexpected target: %q %v", target, err)
+	}
+	target, err = resolveTarget(map[string]any{"user_id": "u-1", "target": "webhook:room-9"})
+	if err != nil || target != "room-9" {
+		t.Fatalf("unexpected target: %q %v", target, err)
+	}
+	if _, err := resolveTarget(map[string]any{}); err == nil {
+		t.Fatalf("expected error for empty binding")
+	}
+}
+
+func TestMatchBinding(t *testing.T) {
+	t.Parallel()
+
+	cfg := map[string]any{"user_id": "u-1"}
+	if !matchBinding(cfg, channel.BindingCriteria{ExternalID: "u-1"}) {
+		t.Fatalf("expected external id match")
+	}
+	if matchBinding(cfg, channel.BindingCriteria{ExternalID: "u-2"}) {
+		t.Fatalf("unexpected match")
+	}
+}
@@ -0,0 +1,7 @@
+// Package webhook implements a generic HTTP webhook channel adapter for custom chat systems.
+package webhook
+
+import "github.com/memohai/memoh/internal/channel"
+
+// Type is the registered ChannelType identifier for generic webhooks.
+const Type channel.ChannelType = "webhook"
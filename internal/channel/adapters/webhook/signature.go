@@ -0,0 +1,50 @@
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// signatureHeader carries "sha256=" followed by the hex HMAC of timestamp + "." + body.
+	signatureHeader = "X-Memoh-Signature"
+	// timestampHeader carries the Unix time in seconds at which the request was signed.
+	timestampHeader = "X-Memoh-Timestamp"
+	// signatureTolerance bounds clock skew and replay of captured requests.
+	signatureTolerance = 5 * time.Minute
+)
+
+// sign computes the signature header value for a body sent at timestamp.
+func sign(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// verify checks a signature produced by sign and rejects timestamps outside the tolerance window.
+func verify(secret, timestamp, signature string, body []byte, now time.Time) error {
+	timestamp = strings.TrimSpace(timestamp)
+	signature = strings.TrimSpace(signature)
+	if timestamp == "" || signature == "" {
+		return fmt.Errorf("missing signature")
+	}
+	seconds, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid timestamp")
+	}
+	skew := now.Sub(time.Unix(seconds, 0))
+	if skew < -signatureTolerance || skew > signatureTolerance {
+		return fmt.Errorf("timestamp outside tolerance")
+	}
+	if !hmac.Equal([]byte(signature), []byte(sign(secret, timestamp, body))) {
+		return fmt.Errorf("signature mismatch")
+	}
+	return nil
+}
@@ -0,0 +1,370 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/memohai/memoh/internal/channel"
+	"github.com/memohai/memoh/internal/channel/adapters/common"
+)
+
+// maxBodyBytes bounds the size of inbound payloads and of error bodies read from the target.
+const maxBodyBytes = 1 << 20
+
+// botIDHeader identifies the bot an outbound message is sent on behalf of.
+const botIDHeader = "X-Memoh-Bot-Id"
+
+// inboundPayload is the JSON body accepted by the inbound endpoint.
+// Channel, bot and source fields are filled in by the adapter.
+type inboundPayload struct {
+	Message      channel.Message     `json:"message"`
+	ReplyTarget  string              `json:"reply_target,omitempty"`
+	SessionKey   string              `json:"session_key,omitempty"`
+	Sender       senderPayload       `json:"sender"`
+	Conversation conversationPayload `json:"conversation"`
+	ReceivedAt   *time.Time          `json:"received_at,omitempty"`
+	Metadata     map[string]any      `json:"metadata,omitempty"`
+}
+
+type senderPayload struct {
+	ExternalID  string            `json:"external_id"`
+	DisplayName string            `json:"display_name,omitempty"`
+	Attributes  map[string]string `json:"attributes,omitempty"`
+}
+
+type conversationPayload struct {
+	ID       string         `json:"id,omitempty"`
+	Type     string         `json:"type,omitempty"`
+	Name     string         `json:"name,omitempty"`
+	ThreadID string         `json:"thread_id,omitempty"`
+	Metadata map[string]any `json:"metadata,omitempty"`
+}
+
+// endpoint is an active inbound connection.
+type endpoint struct {
+	ctx     context.Context
+	cfg     channel.ChannelConfig
+	secret  string
+	handler channel.InboundHandler
+}
+
+// WebhookAdapter implements the channel.Adapter, channel.Sender, channel.Receiver, and
+// channel.WebhookReceiver interfaces for generic HTTP webhooks.
+type WebhookAdapter struct {
+	logger     *slog.Logger
+	httpClient *http.Client
+	now        func() time.Time
+	mu         sync.RWMutex
+	endpoints  map[string]*endpoint // keyed by config ID
+}
+
+// NewWebhookAdapter creates a WebhookAdapter with the given logger.
+func NewWebhookAdapter(log *slog.Logger) *WebhookAdapter {
+	if log == nil {
+		log = slog.Default()
+	}
+	return &WebhookAdapter{
+		logger:     log.With(slog.String("adapter", "webhook")),
+		httpClient: &http.Client{Timeout: 30 * time.Second},
+		now:        time.Now,
+		endpoints:  make(map[string]*endpoint),
+	}
+}
+
+// Type returns the webhook channel type.
+func (a *WebhookAdapter) Type() channel.ChannelType {
+	return Type
+}
+
+// Descriptor returns the webhook channel metadata.
+func (a *WebhookAdapter) Descriptor() channel.Descriptor {
+	return channel.Descriptor{
+		Type:        Type,
+		DisplayName: "Webhook",
+		Capabilities: channel.ChannelCapabilities{
+			Text:        true,
+			Markdown:    true,
+			RichText:    true,
+			Attachments: true,
+			Media:       true,
+			Buttons:     true,
+			Reply:       true,
+			Threads:     true,
+		},
+		OutboundPolicy: channel.OutboundPolicy{
+			TextChunkLimit: 16000,
+			ChunkerMode:    channel.ChunkerModeMarkdown,
+			RetryMax:       5,
+			RetryBackoffMs: 1000,
+		},
+		ConfigSchema: channel.ConfigSchema{
+			Version: 1,
+			Fields: map[string]channel.FieldSchema{
+				"signingSecret": {
+					Type:        channel.FieldSecret,
+					Required:    true,
+					Title:       "Signing Secret",
+					Description: "HMAC-SHA256 key used to sign outbound requests and verify inbound ones",
+				},
+				"targetUrl": {
+					Type:        channel.FieldString,
+					Required:    true,
+					Title:       "Target URL",
+					Description: "Outbound messages are POSTed here as JSON",
+					Example:     "https://chat.example.com/hooks/memoh",
+				},
+				"headers": {
+					Type:        channel.FieldMap,
+					Title:       "Custom Headers",
+					Description: "Extra HTTP headers added to every outbound request",
+					Example:     map[string]string{"Authorization": "Bearer token"},
+				},
+			},
+		},
+		UserConfigSchema: channel.ConfigSchema{
+			Version: 1,
+			Fields: map[string]channel.FieldSchema{
+				"user_id": {Type: channel.FieldString},
+				"target":  {Type: channel.FieldString},
+			},
+		},
+		TargetSpec: channel.TargetSpec{
+			Format: "free-form target understood by the remote system",
+			Hints: []channel.TargetHint{
+				{Label: "Conversation ID", Example: "room-42"},
+			},
+		},
+	}
+}
+
+// NormalizeConfig validates and normalizes a webhook channel configuration map.
+func (a *WebhookAdapter) NormalizeConfig(raw map[string]any) (map[string]any, error) {
+	return normalizeConfig(raw)
+}
+
+// NormalizeUserConfig validates and normalizes a webhook user-binding configuration map.
+func (a *WebhookAdapter) NormalizeUserConfig(raw map[string]any) (map[string]any, error) {
+	return normalizeUserConfig(raw)
+}
+
+// NormalizeTarget normalizes a webhook delivery target string.
+func (a *WebhookAdapter) NormalizeTarget(raw string) string {
+	return normalizeTarget(raw)
+}
+
+// ResolveTarget derives a delivery target from a webhook user-binding configuration.
+func (a *WebhookAdapter) ResolveTarget(userConfig map[string]any) (string, error) {
+	return resolveTarget(userConfig)
+}
+
+// MatchBinding reports whether a webhook user binding matches the given criteria.
+func (a *WebhookAdapter) MatchBinding(config map[string]any, criteria channel.BindingCriteria) bool {
+	return matchBinding(config, criteria)
+}
+
+// BuildUserConfig constructs a webhook user-binding config from an Identity.
+func (a *WebhookAdapter) BuildUserConfig(identity channel.Identity) map[string]any {
+	return buildUserConfig(identity)
+}
+
+// Connect activates the inbound endpoint for the config until the connection is stopped.
+func (a *WebhookAdapter) Connect(ctx context.Context, cfg channel.ChannelConfig, handler channel.InboundHandler) (channel.Connection, error) {
+	if a.logger != nil {
+		a.logger.Info("start", slog.String("config_id", cfg.ID))
+	}
+	webhookCfg, err := parseConfig(cfg.Credentials)
+	if err != nil {
+		if a.logger != nil {
+			a.logger.Error("decode config failed", slog.String("config_id", cfg.ID), slog.Any("error", err))
+		}
+		return nil, err
+	}
+	connCtx, cancel := context.WithCancel(ctx)
+	ep := &endpoint{
+		ctx:     connCtx,
+		cfg:     cfg,
+		secret:  webhookCfg.SigningSecret,
+		handler: handler,
+	}
+	a.mu.Lock()
+	a.endpoints[cfg.ID] = ep
+	a.mu.Unlock()
+
+	stop := func(context.Context) error {
+		if a.logger != nil {
+			a.logger.Info("stop", slog.String("config_id", cfg.ID))
+		}
+		a.mu.Lock()
+		if a.endpoints[cfg.ID] == ep {
+			delete(a.endpoints, cfg.ID)
+		}
+		a.mu.Unlock()
+		cancel()
+		return nil
+	}
+	return channel.NewConnection(cfg, stop), nil
+}
+
+// HandleWebhook verifies a signed inbound request and dispatches its message to the handler.
+func (a *WebhookAdapter) HandleWebhook(ctx context.Context, configID string, req *http.Request) error {
+	a.mu.RLock()
+	ep, ok := a.endpoints[configID]
+	a.mu.RUnlock()
+	if !ok {
+		return channel.ErrWebhookNotFound
+	}
+	body, err := io.ReadAll(io.LimitReader(req.Body, maxBodyBytes+1))
+	if err != nil {
+		return fmt.Errorf("read webhook body: %w", err)
+	}
+	if len(body) > maxBodyBytes {
+		return fmt.Errorf("webhook body exceeds %d bytes", maxBodyBytes)
+	}
+	if err := verify(ep.secret, req.Header.Get(timestampHeader), req.Header.Get(signatureHeader), body, a.now()); err != nil {
+		if a.logger != nil {
+			a.logger.Warn("webhook verification failed", slog.String("config_id", configID), slog.Any("error", err))
+		}
+		return fmt.Errorf("%w: %s", channel.ErrWebhookUnauthorized, err.Error())
+	}
+	var payload inboundPayload
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return fmt.Errorf("decode webhook payload: %w", err)
+	}
+	msg, err := buildInboundMessage(ep.cfg, payload, a.now())
+	if err != nil {
+		return err
+	}
+	if a.logger != nil {
+		a.logger.Info(
+			"inbound received",
+			slog.String("config_id", configID),
+			slog.String("chat_type", msg.Conversation.Type),
+			slog.String("chat_id", msg.Conversation.ID),
+			slog.String("user_id", msg.Sender.ExternalID),
+			slog.String("text", common.SummarizeText(msg.Message.PlainText())),
+		)
+	}
+	go func() {
+		if err := ep.handler(ep.ctx, ep.cfg, msg); err != nil && a.logger != nil {
+			a.logger.Error("handle inbound failed", slog.String("config_id", configID), slog.Any("error", err))
+		}
+	}()
+	return nil
+}
+
+func buildInboundMessage(cfg channel.ChannelConfig, payload inboundPayload, now time.Time) (channel.InboundMessage, error) {
+	if payload.Message.IsEmpty() {
+		return channel.InboundMessage{}, fmt.Errorf("webhook message is required")
+	}
+	senderID := strings.TrimSpace(payload.Sender.ExternalID)
+	if senderID == "" {
+		return channel.InboundMessage{}, fmt.Errorf("webhook sender.external_id is required")
+	}
+	conversation := channel.Conversation{
+		ID:       strings.TrimSpace(payload.Conversation.ID),
+		Type:     strings.TrimSpace(payload.Conversation.Type),
+		Name:     strings.TrimSpace(payload.Conversation.Name),
+		ThreadID: strings.TrimSpace(payload.Conversation.ThreadID),
+		Metadata: payload.Conversation.Metadata,
+	}
+	if conversation.ID == "" {
+		conversation.ID = senderID
+	}
+	if conversation.Type == "" {
+		conversation.Type = "p2p"
+	}
+	replyTarget := normalizeTarget(payload.ReplyTarget)
+	if replyTarget == "" {
+		replyTarget = conversation.ID
+	}
+	receivedAt := now.UTC()
+	if payload.ReceivedAt != nil && !payload.ReceivedAt.IsZero() {
+		receivedAt = payload.ReceivedAt.UTC()
+	}
+	return channel.InboundMessage{
+		Channel:     Type,
+		Message:     payload.Message,
+		BotID:       cfg.BotID,
+		ReplyTarget: replyTarget,
+		SessionKey:  strings.TrimSpace(payload.SessionKey),
+		Sender: channel.Identity{
+			ExternalID:  senderID,
+			DisplayName: strings.TrimSpace(payload.Sender.DisplayName),
+			Attributes:  payload.Sender.Attributes,
+		},
+		Conversation: conversation,
+		ReceivedAt:   receivedAt,
+		Source:       "webhook",
+		Metadata:     payload.Metadata,
+	}, nil
+}
+
+// Send POSTs the outbound message as signed JSON to the configured target URL.
+// Failed deliveries return an error so the channel manager retries per the OutboundPolicy.
+// A message_id in the target's JSON response is reported as the platform message ID.
+func (a *WebhookAdapter) Send(ctx context.Context, cfg channel.ChannelConfig, msg channel.OutboundMessage) (channel.SendResult, error) {
+	webhookCfg, err := parseConfig(cfg.Credentials)
+	if err != nil {
+		if a.logger != nil {
+			a.logger.Error("decode config failed", slog.String("config_id", cfg.ID), slog.Any("error", err))
+		}
+		return channel.SendResult{}, err
+	}
+	target := normalizeTarget(msg.Target)
+	if target == "" {
+		return channel.SendResult{}, fmt.Errorf("webhook target is required")
+	}
+	if msg.Message.IsEmpty() {
+		return channel.SendResult{}, fmt.Errorf("message is required")
+	}
+	body, err := json.Marshal(channel.OutboundMessage{Target: target, Message: msg.Message})
+	if err != nil {
+		return channel.SendResult{}, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhookCfg.TargetURL, bytes.NewReader(body))
+	if err != nil {
+		return channel.SendResult{}, err
+	}
+	for name, value := range webhookCfg.Headers {
+		req.Header.Set(name, value)
+	}
+	timestamp := strconv.FormatInt(a.now().Unix(), 10)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(timestampHeader, timestamp)
+	req.Header.Set(signatureHeader, sign(webhookCfg.SigningSecret, timestamp, body))
+	if cfg.BotID != "" {
+		req.Header.Set(botIDHeader, cfg.BotID)
+	}
+	resp, err := a.httpClient.Do(req)
+	if err != nil {
+		return channel.SendResult{}, fmt.Errorf("webhook post: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		detail, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return channel.SendResult{}, fmt.Errorf("webhook target returned %d: %s", resp.StatusCode, strings.TrimSpace(string(detail)))
+	}
+	return channel.SendResult{MessageIDs: readDeliveryMessageID(resp.Body)}, nil
+}
+
+// readDeliveryMessageID reads the optional {"message_id": "..."} a target may answer with,
+// so the delivery can be referenced later. Any other response body is ignored.
+func readDeliveryMessageID(body io.Reader) []string {
+	var receipt struct {
+		MessageID string `json:"message_id"`
+	}
+	data, _ := io.ReadAll(io.LimitReader(body, maxBodyBytes))
+	if err := json.Unmarshal(data, &receipt); err != nil || strings.TrimSpace(receipt.MessageID) == "" {
+		return nil
+	}
+	return []string{strings.TrimSpace(receipt.MessageID)}
+}
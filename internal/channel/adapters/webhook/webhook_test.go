@@ -0,0 +1,160 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/memohai/memoh/internal/channel"
+)
+
+var fixedNow = time.Unix(1700000000, 0)
+
+func newTestAdapter() *WebhookAdapter {
+	adapter := NewWebhookAdapter(nil)
+	adapter.now = func() time.Time { return fixedNow }
+	return adapter
+}
+
+func testConfig(targetURL string) channel.ChannelConfig {
+	return channel.ChannelConfig{
+		ID:          "cfg-1",
+		BotID:       "bot-1",
+		ChannelType: Type,
+		Credentials: map[string]any{
+			"signingSecret": "s3cret",
+			"targetUrl":     targetURL,
+			"headers":       map[string]any{"Authorization": "Bearer t"},
+		},
+	}
+}
+
+func signedRequest(secret string, at time.Time, body string) *http.Request {
+	req := httptest.NewRequest(http.MethodPost, "/channels/webhook/webhook/cfg-1", strings.NewReader(body))
+	timestamp := strconv.FormatInt(at.Unix(), 10)
+	req.Header.Set(timestampHeader, timestamp)
+	req.Header.Set(signatureHeader, sign(secret, timestamp, []byte(body)))
+	return req
+}
+
+func TestWebhookInbound(t *testing.T) {
+	t.Parallel()
+
+	adapter := newTestAdapter()
+	received := make(chan channel.InboundMessage, 1)
+	conn, err := adapter.Connect(context.Background(), testConfig("https://chat.example.com/hook"), func(ctx context.Context, cfg channel.ChannelConfig, msg channel.InboundMessage) error {
+		received <- msg
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("connect failed: %v", err)
+	}
+	body := `{"message":{"id":"m1","text":"hello"},"sender":{"external_id":"u-1","display_name":"Alice"},"conversation":{"id":"room-9","type":"group"}}`
+
+	if err := adapter.HandleWebhook(context.Background(), "cfg-1", signedRequest("wrong", fixedNow, body)); !errors.Is(err, channel.ErrWebhookUnauthorized) {
+		t.Fatalf("expected unauthorized for bad signature, got %v", err)
+	}
+	if err := adapter.HandleWebhook(context.Background(), "cfg-1", signedRequest("s3cret", fixedNow.Add(-10*time.Minute), body)); !errors.Is(err, channel.ErrWebhookUnauthorized) {
+		t.Fatalf("expected unauthorized for stale timestamp, got %v", err)
+	}
+	if err := adapter.HandleWebhook(context.Background(), "cfg-1", signedRequest("s3cret", fixedNow, `{"message":{},"sender":{"external_id":"u-1"}}`)); err == nil {
+		t.Fatalf("expected error for empty message")
+	}
+	if err := adapter.HandleWebhook(context.Background(), "cfg-1", signedRequest("s3cret", fixedNow, body)); err != nil {
+		t.Fatalf("handle webhook failed: %v", err)
+	}
+	select {
+	case msg := <-received:
+		if msg.Channel != Type || msg.BotID != "bot-1" || msg.Message.Text != "hello" || msg.ReplyTarget != "room-9" {
+			t.Fatalf("unexpected message: %#v", msg)
+		}
+		if msg.Conversation.Type != "group" || msg.Sender.ExternalID != "u-1" || msg.Sender.DisplayName != "Alice" {
+			t.Fatalf("unexpected inbound: %#v", msg)
+		}
+		if !msg.ReceivedAt.Equal(fixedNow) {
+			t.Fatalf("unexpected received_at: %v", msg.ReceivedAt)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatalf("timed out waiting for inbound message")
+	}
+
+	if err := conn.Stop(context.Background()); err != nil {
+		t.Fatalf("stop failed: %v", err)
+	}
+	if err := adapter.HandleWebhook(context.Background(), "cfg-1", signedRequest("s3cret", fixedNow, body)); !errors.Is(err, channel.ErrWebhookNotFound) {
+		t.Fatalf("expected not found after stop, got %v", err)
+	}
+}
+
+func TestWebhookSendSignsPayload(t *testing.T) {
+	t.Parallel()
+
+	var (
+		mu      sync.Mutex
+		headers http.Header
+		body    []byte
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		headers = r.Header.Clone()
+		body, _ = io.ReadAll(r.Body)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusAccepted)
+		_, _ = w.Write([]byte(`{"message_id":"evt-1"}`))
+	}))
+	defer server.Close()
+
+	adapter := newTestAdapter()
+	result, err := adapter.Send(context.Background(), testConfig(server.URL), channel.OutboundMessage{
+		Target:  "room-9",
+		Message: channel.Message{Format: channel.MessageFormatMarkdown, Text: "**hi**"},
+	})
+	if err != nil {
+		t.Fatalf("send failed: %v", err)
+	}
+	if len(result.MessageIDs) != 1 || result.MessageIDs[0] != "evt-1" {
+		t.Fatalf("unexpected message ids: %#v", result.MessageIDs)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if headers.Get("Authorization") != "Bearer t" || headers.Get(botIDHeader) != "bot-1" {
+		t.Fatalf("unexpected headers: %#v", headers)
+	}
+	if err := verify("s3cret", headers.Get(timestampHeader), headers.Get(signatureHeader), body, fixedNow); err != nil {
+		t.Fatalf("signature does not verify: %v", err)
+	}
+	var out channel.OutboundMessage
+	if err := json.Unmarshal(body, &out); err != nil {
+		t.Fatalf("decode body: %v", err)
+	}
+	if out.Target != "room-9" || out.Message.Text != "**hi**" || out.Message.Format != channel.MessageFormatMarkdown {
+		t.Fatalf("unexpected body: %s", body)
+	}
+}
+
+func TestWebhookSendReportsFailure(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "busy", http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	adapter := newTestAdapter()
+	_, err := adapter.Send(context.Background(), testConfig(server.URL), channel.OutboundMessage{
+		Target:  "room-9",
+		Message: channel.Message{Text: "hi"},
+	})
+	if err == nil || !strings.Contains(err.Error(), "503") {
+		t.Fatalf("expected 503 error, got %v", err)
+	}
+}
@@ -18,5 +18,6 @@ type ChannelCapabilities struct {
 	Unsend         bool     `json:"unsend"`
 	NativeCommands bool     `json:"native_commands"`
 	BlockStreaming bool     `json:"block_streaming"`
+	Presence       bool     `json:"presence"`
 	ChatTypes      []string `json:"chat_types,omitempty"`
 }
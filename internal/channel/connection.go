@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"log/slog"
 	"strings"
+	"time"
 )
 
 type connectionEntry struct {
@@ -50,7 +51,7 @@ func (m *Manager) reconcile(ctx context.Context, configs []ChannelConfig) {
 	}
 
 	m.mu.Lock()
-	defer m.mu.Unlock()
+	stopped := make([]ChannelConfig, 0)
 	for id, entry := range m.connections {
 		if _, ok := active[id]; ok {
 			continue
@@ -62,9 +63,16 @@ func (m *Manager) reconcile(ctx context.Context, configs []ChannelConfig) {
 			if err := entry.connection.Stop(ctx); err != nil && !errors.Is(err, ErrStopNotSupported) && m.logger != nil {
 				m.logger.Warn("adapter stop failed", slog.String("config_id", id), slog.Any("error", err))
 			}
+			stopped = append(stopped, entry.config)
 		}
 		delete(m.connections, id)
 	}
+	m.mu.Unlock()
+	for _, cfg := range stopped {
+		m.markConnectionStopped(cfg)
+		m.recordConnectionEvent(ctx, cfg, ConnectionEventStopped, "channel config is no longer active")
+	}
+	m.stopInactiveBackoff(active)
 }
 
 func (m *Manager) ensureConnection(ctx context.Context, cfg ChannelConfig) error {
@@ -74,13 +82,28 @@ func (m *Manager) ensureConnection(ctx context.Context, cfg ChannelConfig) error
 	}
 
 	m.mu.Lock()
+	if stoppedAt, ok := m.stopped[cfg.ID]; ok {
+		if !cfg.UpdatedAt.After(stoppedAt) {
+			m.mu.Unlock()
+			return nil
+		}
+		delete(m.stopped, cfg.ID)
+	}
 	entry := m.connections[cfg.ID]
 	if entry != nil && !entry.config.UpdatedAt.Before(cfg.UpdatedAt) {
+		if entry.connection == nil || entry.connection.Running() {
+			m.mu.Unlock()
+			return nil
+		}
+		// The connection stopped on its own: reconnect it, backing off while it keeps failing.
+		delete(m.connections, cfg.ID)
+		m.mu.Unlock()
+		m.markConnectionFailed(ctx, entry.config, ConnectionEventDropped, droppedConnectionError(entry.connection))
+		entry = nil
+	} else {
 		m.mu.Unlock()
-		return nil
 	}
 	if entry != nil {
-		m.mu.Unlock()
 		if m.logger != nil {
 			m.logger.Info("adapter restart", slog.String("channel", cfg.ChannelType.String()), slog.String("config_id", cfg.ID))
 		}
@@ -96,8 +119,9 @@ func (m *Manager) ensureConnection(ctx context.Context, cfg ChannelConfig) error
 		m.mu.Lock()
 		delete(m.connections, cfg.ID)
 		m.mu.Unlock()
-	} else {
-		m.mu.Unlock()
+	}
+	if !m.connectAllowed(cfg, time.Now()) {
+		return nil
 	}
 
 	receiver, ok := m.registry.GetReceiver(cfg.ChannelType)
@@ -108,12 +132,17 @@ func (m *Manager) ensureConnection(ctx context.Context, cfg ChannelConfig) error
 	if m.logger != nil {
 		m.logger.Info("adapter start", slog.String("channel", cfg.ChannelType.String()), slog.String("config_id", cfg.ID))
 	}
-	handler := m.handleInbound
-	for i := len(m.middlewares) - 1; i >= 0; i-- {
-		handler = m.middlewares[i](handler)
+	dispatch := m.dispatchInbound
+	if m.inboundStore != nil {
+		dispatch = m.HandleInbound
+	}
+	handler := func(ctx context.Context, cfg ChannelConfig, msg InboundMessage) error {
+		m.noteInbound(cfg)
+		return dispatch(ctx, cfg, msg)
 	}
 	conn, err := receiver.Connect(ctx, cfg, handler)
 	if err != nil {
+		m.markConnectionFailed(ctx, cfg, ConnectionEventFailed, err)
 		return err
 	}
 	m.mu.Lock()
@@ -122,9 +151,29 @@ func (m *Manager) ensureConnection(ctx context.Context, cfg ChannelConfig) error
 		connection: conn,
 	}
 	m.mu.Unlock()
+	m.markConnected(ctx, cfg)
+	m.registerCommands(ctx, cfg)
 	return nil
 }
 
+// registerCommands publishes the bot commands to the platform's command menu. Failures are
+// logged only; commands typed by hand still work.
+func (m *Manager) registerCommands(ctx context.Context, cfg ChannelConfig) {
+	if len(m.commands) == 0 {
+		return
+	}
+	if caps, ok := m.registry.GetCapabilities(cfg.ChannelType); !ok || !caps.NativeCommands {
+		return
+	}
+	registrar, ok := m.registry.GetCommandRegistrar(cfg.ChannelType)
+	if !ok {
+		return
+	}
+	if err := registrar.RegisterCommands(ctx, cfg, m.commands); err != nil && m.logger != nil {
+		m.logger.Warn("register commands failed", slog.String("channel", cfg.ChannelType.String()), slog.String("config_id", cfg.ID), slog.Any("error", err))
+	}
+}
+
 func (m *Manager) stopAll(ctx context.Context) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
@@ -136,12 +185,14 @@ func (m *Manager) stopAll(ctx context.Context) {
 			if err := entry.connection.Stop(ctx); err != nil && !errors.Is(err, ErrStopNotSupported) && m.logger != nil {
 				m.logger.Warn("adapter stop failed", slog.String("config_id", id), slog.Any("error", err))
 			}
+			m.markConnectionStopped(entry.config)
 		}
 		delete(m.connections, id)
 	}
 }
 
-// Stop terminates the connection identified by the given config ID.
+// Stop terminates the connection identified by the given config ID. It stays stopped until
+// the config is updated.
 func (m *Manager) Stop(ctx context.Context, configID string) error {
 	configID = strings.TrimSpace(configID)
 	if configID == "" {
@@ -149,14 +200,18 @@ func (m *Manager) Stop(ctx context.Context, configID string) error {
 	}
 	m.mu.Lock()
 	entry := m.connections[configID]
+	delete(m.connections, configID)
+	m.stopped[configID] = time.Now()
 	m.mu.Unlock()
 	if entry == nil || entry.connection == nil {
 		return nil
 	}
+	m.markConnectionStopped(entry.config)
 	return entry.connection.Stop(ctx)
 }
 
-// StopByBot terminates all connections belonging to the given bot.
+// StopByBot terminates all connections belonging to the given bot. They stay stopped until
+// their configs are updated.
 func (m *Manager) StopByBot(ctx context.Context, botID string) error {
 	botID = strings.TrimSpace(botID)
 	if botID == "" {
@@ -169,7 +224,9 @@ func (m *Manager) StopByBot(ctx context.Context, botID string) error {
 			if entry.connection != nil {
 				_ = entry.connection.Stop(ctx)
 			}
+			m.markConnectionStopped(entry.config)
 			delete(m.connections, id)
+			m.stopped[id] = time.Now()
 		}
 	}
 	return nil
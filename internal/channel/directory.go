@@ -1,6 +1,18 @@
 package channel
 
-import "context"
+import (
+	"context"
+	"errors"
+)
+
+var (
+	// ErrDirectoryNotSupported is returned when a platform cannot serve a directory lookup.
+	ErrDirectoryNotSupported = errors.New("directory operation unsupported")
+	// ErrDirectoryEntryNotFound is returned when a directory lookup has no match.
+	ErrDirectoryEntryNotFound = errors.New("directory entry not found")
+	// ErrDirectoryEntryAmbiguous is returned when a directory lookup matches more than one entry.
+	ErrDirectoryEntryAmbiguous = errors.New("directory entry ambiguous")
+)
 
 // DirectoryEntryKind classifies a directory entry as a user or a group.
 type DirectoryEntryKind string
@@ -34,3 +46,9 @@ type ChannelDirectoryAdapter interface {
 	ListGroupMembers(ctx context.Context, cfg ChannelConfig, groupID string, query DirectoryQuery) ([]DirectoryEntry, error)
 	ResolveTarget(ctx context.Context, cfg ChannelConfig, input string, kind DirectoryEntryKind) (DirectoryEntry, error)
 }
+
+// DirectoryProvider is an adapter that exposes a ChannelDirectoryAdapter for its platform.
+// The directory is a separate value because its ResolveTarget differs from TargetResolver's.
+type DirectoryProvider interface {
+	Directory() ChannelDirectoryAdapter
+}
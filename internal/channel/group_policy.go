@@ -0,0 +1,151 @@
+package channel
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Inbound metadata keys set by adapters that can tell whether a group message addresses the bot.
+const (
+	MetadataKeyMentioned  = "mentioned"
+	MetadataKeyReplyToBot = "reply_to_bot"
+)
+
+// GroupTrigger names a condition under which the bot answers a group message.
+type GroupTrigger string
+
+const (
+	GroupTriggerAlways  GroupTrigger = "always"
+	GroupTriggerMention GroupTrigger = "mention"
+	GroupTriggerReply   GroupTrigger = "reply"
+	GroupTriggerKeyword GroupTrigger = "keyword"
+)
+
+// GroupPolicy decides which group messages the bot answers; the others are only recorded as
+// ambient context. It is read from the "group_policy" key of ChannelConfig.Routing and can be
+// overridden for a single conversation under "conversations" keyed by conversation ID:
+//
+//	{"group_policy": {"triggers": ["mention", "reply"]},
+//	 "conversations": {"-1001": {"group_policy": {"triggers": ["keyword"], "prefixes": ["/ask"]}}}}
+type GroupPolicy struct {
+	Triggers []GroupTrigger `json:"triggers,omitempty"`
+	Keywords []string       `json:"keywords,omitempty"`
+	Prefixes []string       `json:"prefixes,omitempty"`
+}
+
+// DefaultGroupPolicy answers every group message.
+func DefaultGroupPolicy() GroupPolicy {
+	return GroupPolicy{Triggers: []GroupTrigger{GroupTriggerAlways}}
+}
+
+// Has reports whether the policy includes the trigger.
+func (p GroupPolicy) Has(trigger GroupTrigger) bool {
+	for _, item := range p.Triggers {
+		if item == trigger {
+			return true
+		}
+	}
+	return false
+}
+
+// MatchesKeyword reports whether text starts with one of the prefixes or contains one of the
+// keywords, ignoring case.
+func (p GroupPolicy) MatchesKeyword(text string) bool {
+	lower := strings.ToLower(strings.TrimSpace(text))
+	if lower == "" {
+		return false
+	}
+	for _, prefix := range p.Prefixes {
+		prefix = strings.ToLower(strings.TrimSpace(prefix))
+		if prefix != "" && strings.HasPrefix(lower, prefix) {
+			return true
+		}
+	}
+	for _, keyword := range p.Keywords {
+		keyword = strings.ToLower(strings.TrimSpace(keyword))
+		if keyword != "" && strings.Contains(lower, keyword) {
+			return true
+		}
+	}
+	return false
+}
+
+// ResolveGroupPolicy returns the group policy for a conversation: the conversation override
+// if present, else the config-wide policy, else DefaultGroupPolicy.
+// Invalid policies are ignored here; ValidateRouting rejects them when configs are saved.
+func ResolveGroupPolicy(routing map[string]any, conversationID string) GroupPolicy {
+	if conversations, ok := routing["conversations"].(map[string]any); ok && strings.TrimSpace(conversationID) != "" {
+		if override, ok := conversations[strings.TrimSpace(conversationID)].(map[string]any); ok {
+			if policy, ok, err := decodeGroupPolicy(override["group_policy"]); ok && err == nil {
+				return policy
+			}
+		}
+	}
+	if policy, ok, err := decodeGroupPolicy(routing["group_policy"]); ok && err == nil {
+		return policy
+	}
+	return DefaultGroupPolicy()
+}
+
+// ValidateRouting checks the group policies in a routing config.
+func ValidateRouting(routing map[string]any) error {
+	if _, _, err := decodeGroupPolicy(routing["group_policy"]); err != nil {
+		return err
+	}
+	raw, ok := routing["conversations"]
+	if !ok || raw == nil {
+		return nil
+	}
+	conversations, ok := raw.(map[string]any)
+	if !ok {
+		return fmt.Errorf("routing conversations must be an object")
+	}
+	for id, value := range conversations {
+		override, ok := value.(map[string]any)
+		if !ok {
+			return fmt.Errorf("routing conversation %s must be an object", id)
+		}
+		if _, _, err := decodeGroupPolicy(override["group_policy"]); err != nil {
+			return fmt.Errorf("conversation %s: %w", id, err)
+		}
+	}
+	return nil
+}
+
+func decodeGroupPolicy(raw any) (GroupPolicy, bool, error) {
+	if raw == nil {
+		return GroupPolicy{}, false, nil
+	}
+	payload, err := json.Marshal(raw)
+	if err != nil {
+		return GroupPolicy{}, false, err
+	}
+	var policy GroupPolicy
+	if err := json.Unmarshal(payload, &policy); err != nil {
+		return GroupPolicy{}, false, fmt.Errorf("invalid group policy: %w", err)
+	}
+	if len(policy.Triggers) == 0 {
+		return GroupPolicy{}, false, fmt.Errorf("group policy triggers are required")
+	}
+	for i, trigger := range policy.Triggers {
+		trigger = GroupTrigger(strings.ToLower(strings.TrimSpace(string(trigger))))
+		switch trigger {
+		case GroupTriggerAlways, GroupTriggerMention, GroupTriggerReply:
+		case GroupTriggerKeyword:
+			if len(policy.Keywords) == 0 && len(policy.Prefixes) == 0 {
+				return GroupPolicy{}, false, fmt.Errorf("keyword trigger requires keywords or prefixes")
+			}
+		default:
+			return GroupPolicy{}, false, fmt.Errorf("unsupported group trigger: %s", trigger)
+		}
+		policy.Triggers[i] = trigger
+	}
+	return policy, true, nil
+}
+
+// IsGroupConversation reports whether a conversation type denotes a multi-user chat.
+func IsGroupConversation(conversationType string) bool {
+	value := strings.ToLower(strings.TrimSpace(conversationType))
+	return strings.Contains(value, "group") || value == "channel"
+}
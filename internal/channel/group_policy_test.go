@@ -0,0 +1,76 @@
+package channel_test
+
+import (
+	"testing"
+
+	"github.com/memohai/memoh/internal/channel"
+)
+
+func TestResolveGroupPolicy(t *testing.T) {
+	t.Parallel()
+
+	if policy := channel.ResolveGroupPolicy(nil, "-1001"); !policy.Has(channel.GroupTriggerAlways) {
+		t.Fatalf("默认策略应为 always: %+v", policy)
+	}
+	routing := map[string]any{
+		"group_policy": map[string]any{"triggers": []any{"mention"}},
+		"conversations": map[string]any{
+			"-1001": map[string]any{"group_policy": map[string]any{"triggers": []any{"Keyword"}, "keywords": []any{"memoh"}}},
+		},
+	}
+	policy := channel.ResolveGroupPolicy(routing, "-1002")
+	if !policy.Has(channel.GroupTriggerMention) || policy.Has(channel.GroupTriggerAlways) {
+		t.Fatalf("应使用配置级策略: %+v", policy)
+	}
+	policy = channel.ResolveGroupPolicy(routing, "-1001")
+	if !policy.Has(channel.GroupTriggerKeyword) || policy.Has(channel.GroupTriggerMention) {
+		t.Fatalf("应使用会话级覆盖策略: %+v", policy)
+	}
+	if !policy.MatchesKeyword("hey MEMOH, help") || policy.MatchesKeyword("hello") {
+		t.Fatalf("关键词匹配错误: %+v", policy)
+	}
+}
+
+func TestValidateRouting(t *testing.T) {
+	t.Parallel()
+
+	valid := map[string]any{
+		"group_policy": map[string]any{"triggers": []any{"mention", "reply"}},
+		"conversations": map[string]any{
+			"-1001": map[string]any{"group_policy": map[string]any{"triggers": []any{"keyword"}, "prefixes": []any{"/ask"}}},
+		},
+	}
+	if err := channel.ValidateRouting(valid); err != nil {
+		t.Fatalf("合法配置不应报错: %v", err)
+	}
+	if err := channel.ValidateRouting(map[string]any{}); err != nil {
+		t.Fatalf("空配置不应报错: %v", err)
+	}
+	invalid := []map[string]any{
+		{"group_policy": map[string]any{"triggers": []any{"sometimes"}}},
+		{"group_policy": map[string]any{"triggers": []any{}}},
+		{"group_policy": map[string]any{"triggers": []any{"keyword"}}},
+		{"conversations": "oops"},
+		{"conversations": map[string]any{"-1001": map[string]any{"group_policy": map[string]any{"triggers": []any{"never"}}}}},
+	}
+	for _, routing := range invalid {
+		if err := channel.ValidateRouting(routing); err == nil {
+			t.Fatalf("非法配置应报错: %+v", routing)
+		}
+	}
+}
+
+func TestIsGroupConversation(t *testing.T) {
+	t.Parallel()
+
+	for _, value := range []string{"group", "supergroup", "channel", "Group"} {
+		if !channel.IsGroupConversation(value) {
+			t.Fatalf("%s 应视为群聊", value)
+		}
+	}
+	for _, value := range []string{"", "p2p", "private"} {
+		if channel.IsGroupConversation(value) {
+			t.Fatalf("%s 不应视为群聊", value)
+		}
+	}
+}
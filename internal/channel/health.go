@@ -0,0 +1,204 @@
+package channel
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+)
+
+// connectionHealth tracks the connection of one channel config across reconnects.
+type connectionHealth struct {
+	config       ChannelConfig
+	state        string
+	lastError    string
+	lastErrorAt  time.Time
+	connectedAt  time.Time
+	lastInbound  time.Time
+	lastOutbound time.Time
+	connects     int
+	failures     int
+	nextRetry    time.Time
+}
+
+// SetConnectionEventLog persists connection lifecycle events in the store.
+func (m *Manager) SetConnectionEventLog(store ConnectionEventStore) {
+	m.connectionEvents = store
+}
+
+// ConnectionStatus returns the health of the connection of a channel config.
+func (m *Manager) ConnectionStatus(cfg ChannelConfig) ConnectionStatus {
+	status := ConnectionStatus{
+		ConfigID:    cfg.ID,
+		BotID:       cfg.BotID,
+		ChannelType: cfg.ChannelType,
+		State:       ConnectionStateDisconnected,
+	}
+	if _, ok := m.registry.GetReceiver(cfg.ChannelType); !ok {
+		status.State = ConnectionStateSendOnly
+	}
+	m.mu.Lock()
+	entry := m.connections[cfg.ID]
+	m.mu.Unlock()
+
+	m.healthMu.Lock()
+	if h := m.health[cfg.ID]; h != nil {
+		if h.state != "" {
+			status.State = h.state
+		}
+		status.LastError = h.lastError
+		status.LastErrorAt = optionalTime(h.lastErrorAt)
+		status.ConnectedAt = optionalTime(h.connectedAt)
+		status.LastInboundAt = optionalTime(h.lastInbound)
+		status.LastOutboundAt = optionalTime(h.lastOutbound)
+		status.RestartCount = max(h.connects-1, 0)
+		status.ConsecutiveFailures = h.failures
+		status.NextRetryAt = optionalTime(h.nextRetry)
+	}
+	m.healthMu.Unlock()
+
+	// A connection that stopped on its own is only noticed by the next refresh.
+	if status.State == ConnectionStateConnected && entry != nil && entry.connection != nil && !entry.connection.Running() {
+		status.State = ConnectionStateDisconnected
+		if err := droppedConnectionError(entry.connection); err != nil {
+			status.LastError = err.Error()
+		}
+	}
+	status.Connected = status.State == ConnectionStateConnected
+	if status.Connected {
+		status.ConsecutiveFailures = 0
+	}
+	return status
+}
+
+// connectAllowed reports whether a config may be connected now, or is waiting out its backoff.
+// An edited config, such as one with a new token, is tried right away.
+func (m *Manager) connectAllowed(cfg ChannelConfig, now time.Time) bool {
+	m.healthMu.Lock()
+	defer m.healthMu.Unlock()
+	h := m.health[cfg.ID]
+	if h == nil || h.nextRetry.IsZero() {
+		return true
+	}
+	if cfg.UpdatedAt.After(h.config.UpdatedAt) {
+		h.config = cfg
+		h.failures = 0
+		h.nextRetry = time.Time{}
+		return true
+	}
+	return !now.Before(h.nextRetry)
+}
+
+func (m *Manager) markConnected(ctx context.Context, cfg ChannelConfig) {
+	m.healthMu.Lock()
+	h := m.healthEntry(cfg)
+	h.config = cfg
+	h.state = ConnectionStateConnected
+	h.connectedAt = time.Now()
+	h.nextRetry = time.Time{}
+	h.connects++
+	m.healthMu.Unlock()
+	m.recordConnectionEvent(ctx, cfg, ConnectionEventConnected, "")
+}
+
+// markConnectionFailed puts a config that failed to connect or dropped its connection into
+// exponential backoff. Failures only reset once a connection stayed up for
+// connectStableAfter, so a connection that keeps dropping right after connecting backs off too.
+func (m *Manager) markConnectionFailed(ctx context.Context, cfg ChannelConfig, event string, err error) {
+	now := time.Now()
+	m.healthMu.Lock()
+	h := m.healthEntry(cfg)
+	if event == ConnectionEventDropped && !h.connectedAt.IsZero() && now.Sub(h.connectedAt) >= m.connectStableAfter {
+		h.failures = 0
+	}
+	h.failures++
+	delay := inboundRetryDelay(h.failures, m.connectRetryBase, m.connectRetryMax)
+	h.config = cfg
+	h.state = ConnectionStateBackoff
+	h.lastError = err.Error()
+	h.lastErrorAt = now
+	h.nextRetry = now.Add(delay)
+	failures := h.failures
+	m.healthMu.Unlock()
+	if m.logger != nil {
+		m.logger.Warn("channel connection "+event, slog.String("channel", cfg.ChannelType.String()), slog.String("config_id", cfg.ID), slog.Int("failures", failures), slog.Duration("retry_in", delay), slog.Any("error", err))
+	}
+	m.recordConnectionEvent(ctx, cfg, event, fmt.Sprintf("%s (retry in %s)", err.Error(), delay))
+}
+
+func (m *Manager) markConnectionStopped(cfg ChannelConfig) {
+	m.healthMu.Lock()
+	defer m.healthMu.Unlock()
+	h := m.healthEntry(cfg)
+	h.state = ConnectionStateStopped
+	h.nextRetry = time.Time{}
+}
+
+// stopInactiveBackoff stops retrying configs that are no longer active.
+func (m *Manager) stopInactiveBackoff(active map[string]ChannelConfig) {
+	m.healthMu.Lock()
+	defer m.healthMu.Unlock()
+	for id, h := range m.health {
+		if _, ok := active[id]; !ok && h.state == ConnectionStateBackoff {
+			h.state = ConnectionStateStopped
+			h.nextRetry = time.Time{}
+		}
+	}
+}
+
+func (m *Manager) noteInbound(cfg ChannelConfig) {
+	m.healthMu.Lock()
+	defer m.healthMu.Unlock()
+	m.healthEntry(cfg).lastInbound = time.Now()
+}
+
+func (m *Manager) noteOutbound(cfg ChannelConfig) {
+	m.healthMu.Lock()
+	defer m.healthMu.Unlock()
+	m.healthEntry(cfg).lastOutbound = time.Now()
+}
+
+// healthEntry returns the health of a config. The caller must hold healthMu.
+func (m *Manager) healthEntry(cfg ChannelConfig) *connectionHealth {
+	h := m.health[cfg.ID]
+	if h == nil {
+		h = &connectionHealth{config: cfg}
+		m.health[cfg.ID] = h
+	}
+	return h
+}
+
+// recordConnectionEvent writes a lifecycle event to the connection event log. The log is for
+// inspection only, so failing to write it is only logged.
+func (m *Manager) recordConnectionEvent(ctx context.Context, cfg ChannelConfig, eventType, message string) {
+	if m.connectionEvents == nil {
+		return
+	}
+	err := m.connectionEvents.RecordConnectionEvent(context.WithoutCancel(ctx), ConnectionEvent{
+		BotID:           cfg.BotID,
+		ChannelConfigID: cfg.ID,
+		ChannelType:     cfg.ChannelType,
+		EventType:       eventType,
+		Message:         message,
+	})
+	if err != nil && m.logger != nil {
+		m.logger.Warn("record connection event failed", slog.String("config_id", cfg.ID), slog.String("event", eventType), slog.Any("error", err))
+	}
+}
+
+func droppedConnectionError(conn Connection) error {
+	if reporter, ok := conn.(ConnectionErrorReporter); ok {
+		if err := reporter.Err(); err != nil {
+			return err
+		}
+	}
+	return errors.New("connection stopped")
+}
+
+func optionalTime(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	return &t
+}
@@ -4,15 +4,22 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
+	"strings"
+	"time"
 )
 
 type inboundTask struct {
-	ctx context.Context
-	cfg ChannelConfig
-	msg InboundMessage
+	ctx        context.Context
+	cfg        ChannelConfig
+	msg        InboundMessage
+	botID      string
+	key        string
+	receivedAt time.Time
 }
 
 // HandleInbound enqueues an inbound message for asynchronous processing by the worker pool.
+// With a durable queue configured the message is persisted before HandleInbound returns.
+// Messages of the same session are processed one at a time in arrival order.
 func (m *Manager) HandleInbound(ctx context.Context, cfg ChannelConfig, msg InboundMessage) error {
 	if m.processor == nil {
 		return fmt.Errorf("inbound processor not configured")
@@ -24,24 +31,44 @@ func (m *Manager) HandleInbound(ctx context.Context, cfg ChannelConfig, msg Inbo
 	if m.inboundCtx != nil && m.inboundCtx.Err() != nil {
 		return fmt.Errorf("inbound dispatcher stopped")
 	}
-	task := inboundTask{
-		ctx: context.WithoutCancel(ctx),
-		cfg: cfg,
-		msg: msg,
-	}
-	select {
-	case m.inboundQueue <- task:
+	botID, key := m.inboundSessionKey(ctx, cfg, msg)
+	if m.inboundStore != nil {
+		queued, err := m.inboundStore.EnqueueInbound(ctx, cfg, msg, key)
+		if err != nil {
+			return fmt.Errorf("enqueue inbound message: %w", err)
+		}
+		m.noteQueuedArrival(botID, queued.SessionKey)
+		m.wakeInbound()
 		return nil
-	default:
-		return fmt.Errorf("inbound queue full")
 	}
+	return m.enqueueSessionTask(inboundTask{
+		ctx:        context.WithoutCancel(ctx),
+		cfg:        cfg,
+		msg:        msg,
+		botID:      botID,
+		key:        key,
+		receivedAt: time.Now(),
+	})
+}
+
+// dispatchInbound runs the middleware chain and the inbound processor for one message.
+func (m *Manager) dispatchInbound(ctx context.Context, cfg ChannelConfig, msg InboundMessage) error {
+	handler := m.handleInbound
+	for i := len(m.middlewares) - 1; i >= 0; i-- {
+		handler = m.middlewares[i](handler)
+	}
+	return handler(ctx, cfg, msg)
 }
 
 func (m *Manager) handleInbound(ctx context.Context, cfg ChannelConfig, msg InboundMessage) error {
 	if m.processor == nil {
 		return fmt.Errorf("inbound processor not configured")
 	}
-	sender := m.newReplySender(cfg, msg.Channel)
+	keyed := msg
+	if strings.TrimSpace(keyed.BotID) == "" {
+		keyed.BotID = cfg.BotID
+	}
+	sender := m.newReplySender(cfg, msg.Channel, keyed.SessionID())
 	if err := m.processor.HandleInbound(ctx, cfg, msg, sender); err != nil {
 		if m.logger != nil {
 			m.logger.Error("inbound processing failed", slog.String("channel", msg.Channel.String()), slog.Any("error", err))
@@ -58,6 +85,10 @@ func (m *Manager) startInboundWorkers(ctx context.Context) {
 			workerCtx = context.Background()
 		}
 		m.inboundCtx, m.inboundCancel = context.WithCancel(workerCtx)
+		if m.inboundStore != nil {
+			go m.runInboundQueue(m.inboundCtx)
+			return
+		}
 		for i := 0; i < m.inboundWorkers; i++ {
 			go m.runInboundWorker(m.inboundCtx)
 		}
@@ -70,11 +101,140 @@ func (m *Manager) runInboundWorker(ctx context.Context) {
 		case <-ctx.Done():
 			return
 		case task := <-m.inboundQueue:
-			if err := m.handleInbound(task.ctx, task.cfg, task.msg); err != nil {
-				if m.logger != nil {
-					m.logger.Error("inbound processing failed", slog.String("channel", task.msg.Channel.String()), slog.Any("error", err))
-				}
+			m.runSessionTasks(task)
+		}
+	}
+}
+
+// runInboundQueue polls the durable queue and processes claimed messages with up to
+// inboundWorkers in flight. Claims older than inboundLockTimeout are considered abandoned
+// and released, on start and then periodically; younger ones may belong to another
+// replica still working on them.
+func (m *Manager) runInboundQueue(ctx context.Context) {
+	m.releaseStaleInbound(ctx, time.Now().Add(-m.inboundLockTimeout))
+	lastRelease := time.Now()
+	slots := make(chan struct{}, m.inboundWorkers)
+	ticker := time.NewTicker(m.inboundPollInterval)
+	defer ticker.Stop()
+	for {
+		if time.Since(lastRelease) >= m.inboundLockTimeout {
+			m.releaseStaleInbound(ctx, time.Now().Add(-m.inboundLockTimeout))
+			lastRelease = time.Now()
+		}
+		m.claimInbound(ctx, slots)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-m.inboundWake:
+		}
+	}
+}
+
+func (m *Manager) claimInbound(ctx context.Context, slots chan struct{}) {
+	free := cap(slots) - len(slots)
+	if free <= 0 {
+		return
+	}
+	items, err := m.inboundStore.ClaimInbound(ctx, free)
+	if err != nil {
+		if ctx.Err() == nil && m.logger != nil {
+			m.logger.Error("claim inbound messages failed", slog.Any("error", err))
+		}
+		return
+	}
+	for _, item := range items {
+		slots <- struct{}{}
+		go func(item QueuedInbound) {
+			defer func() {
+				<-slots
+				m.wakeInbound()
+			}()
+			m.processQueuedInbound(context.WithoutCancel(ctx), item)
+		}(item)
+	}
+}
+
+// processQueuedInbound dispatches a claimed message and records the outcome: processed
+// messages are removed, together with the messages the processor drained from the session
+// inbox, failures are retried with exponential backoff until inboundMaxAttempts is reached,
+// after which the message is dead-lettered.
+func (m *Manager) processQueuedInbound(ctx context.Context, item QueuedInbound) {
+	lane := m.openQueuedLane(item)
+	defer m.closeQueuedLane(lane)
+	inbox := &sessionInbox{manager: m, lane: lane, head: item.ID}
+	cfg, err := m.resolveQueuedConfig(ctx, item)
+	if err == nil {
+		err = m.dispatchInbound(WithSessionInbox(ctx, inbox), cfg, item.Message)
+	}
+	if err == nil {
+		// Drained messages go first: while the head is unfinished none of them can be claimed.
+		for _, id := range append(inbox.drained, item.ID) {
+			if err := m.inboundStore.CompleteInbound(ctx, id); err != nil && m.logger != nil {
+				m.logger.Error("complete inbound message failed", slog.String("id", id), slog.Any("error", err))
 			}
 		}
+		return
+	}
+	reason := err.Error()
+	if item.Attempts >= m.inboundMaxAttempts {
+		if m.logger != nil {
+			m.logger.Warn("inbound message dead-lettered", slog.String("id", item.ID), slog.String("channel", item.ChannelType.String()), slog.Int("attempts", item.Attempts), slog.String("error", reason))
+		}
+		if err := m.inboundStore.DeadLetterInbound(ctx, item.ID, reason); err != nil && m.logger != nil {
+			m.logger.Error("dead-letter inbound message failed", slog.String("id", item.ID), slog.Any("error", err))
+		}
+		return
+	}
+	delay := inboundRetryDelay(item.Attempts, m.inboundRetryBase, m.inboundRetryMax)
+	if err := m.inboundStore.RetryInbound(ctx, item.ID, time.Now().Add(delay), reason); err != nil && m.logger != nil {
+		m.logger.Error("retry inbound message failed", slog.String("id", item.ID), slog.Any("error", err))
+	}
+}
+
+// resolveQueuedConfig prefers the config of the live connection that received the message,
+// so queued messages are handled with the same credentials, and falls back to the stored config.
+func (m *Manager) resolveQueuedConfig(ctx context.Context, item QueuedInbound) (ChannelConfig, error) {
+	m.mu.Lock()
+	entry := m.connections[item.ChannelConfigID]
+	m.mu.Unlock()
+	if entry != nil {
+		return entry.config, nil
+	}
+	if m.service == nil {
+		return ChannelConfig{}, fmt.Errorf("channel manager not configured")
+	}
+	return m.service.ResolveEffectiveConfig(ctx, item.BotID, item.ChannelType)
+}
+
+func (m *Manager) releaseStaleInbound(ctx context.Context, lockedBefore time.Time) {
+	released, err := m.inboundStore.ReleaseStaleInbound(ctx, lockedBefore)
+	if err != nil {
+		if ctx.Err() == nil && m.logger != nil {
+			m.logger.Error("release stale inbound messages failed", slog.Any("error", err))
+		}
+		return
+	}
+	if released > 0 && m.logger != nil {
+		m.logger.Warn("released stale inbound messages", slog.Int64("count", released))
+	}
+}
+
+func (m *Manager) wakeInbound() {
+	select {
+	case m.inboundWake <- struct{}{}:
+	default:
+	}
+}
+
+// inboundRetryDelay doubles the delay for every failed attempt, capped at max.
+func inboundRetryDelay(attempts int, base, max time.Duration) time.Duration {
+	delay := base
+	for i := 1; i < attempts && delay < max; i++ {
+		delay *= 2
+	}
+	if delay > max {
+		delay = max
 	}
+	return delay
 }
@@ -0,0 +1,291 @@
+package channel
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+	"time"
+)
+
+// sessionLane serializes the messages of one session: while a message of the session is
+// being processed, later ones wait in the lane and are processed in arrival order by the
+// same worker, so sessions never run concurrently with themselves but run in parallel
+// with each other. With a durable queue the store already claims one message per session;
+// the lane then only carries arrival signals for the message in flight.
+type sessionLane struct {
+	key      string
+	botID    string
+	queue    []inboundTask
+	arrivals chan struct{}
+}
+
+type inboundBotStats struct {
+	waiting    int64
+	dispatched int64
+	timed      int64
+	totalWait  time.Duration
+	maxWait    time.Duration
+	lastWait   time.Duration
+}
+
+func newSessionLane(key, botID string) *sessionLane {
+	return &sessionLane{key: key, botID: botID, arrivals: make(chan struct{}, 1)}
+}
+
+func (l *sessionLane) signal() {
+	select {
+	case l.arrivals <- struct{}{}:
+	default:
+	}
+}
+
+// inboundSessionKey returns the bot and the session key of a message, the same key the
+// durable queue serializes on.
+func (m *Manager) inboundSessionKey(ctx context.Context, cfg ChannelConfig, msg InboundMessage) (string, string) {
+	keyed := msg
+	if strings.TrimSpace(keyed.BotID) == "" {
+		keyed.BotID = cfg.BotID
+	}
+	if m.sessionKeys != nil {
+		if key := strings.TrimSpace(m.sessionKeys(ctx, cfg, keyed)); key != "" {
+			return keyed.BotID, key
+		}
+	}
+	return keyed.BotID, keyed.SessionID()
+}
+
+// enqueueSessionTask hands a message to the worker pool, or queues it behind the message
+// of its session being processed.
+func (m *Manager) enqueueSessionTask(task inboundTask) error {
+	m.sessionMu.Lock()
+	defer m.sessionMu.Unlock()
+	if lane := m.lanes[task.key]; lane != nil {
+		if m.laneWaiting >= m.maxLaneWaiting {
+			return fmt.Errorf("inbound queue full")
+		}
+		m.laneWaiting++
+		lane.queue = append(lane.queue, task)
+		m.botStats(task.botID).waiting++
+		lane.signal()
+		return nil
+	}
+	select {
+	case m.inboundQueue <- task:
+		m.lanes[task.key] = newSessionLane(task.key, task.botID)
+		m.botStats(task.botID).waiting++
+		return nil
+	default:
+		return fmt.Errorf("inbound queue full")
+	}
+}
+
+// runSessionTasks processes a message and then the messages queued behind it in its
+// session, one at a time.
+func (m *Manager) runSessionTasks(task inboundTask) {
+	m.sessionMu.Lock()
+	lane := m.lanes[task.key]
+	if lane == nil {
+		lane = newSessionLane(task.key, task.botID)
+		m.lanes[task.key] = lane
+	}
+	m.recordDispatch(task.botID, task.receivedAt)
+	m.sessionMu.Unlock()
+	for {
+		inbox := &sessionInbox{manager: m, lane: lane}
+		if err := m.dispatchInbound(WithSessionInbox(task.ctx, inbox), task.cfg, task.msg); err != nil {
+			if m.logger != nil {
+				m.logger.Error("inbound processing failed", slog.String("channel", task.msg.Channel.String()), slog.Any("error", err))
+			}
+		}
+		next, ok := m.nextSessionTask(lane)
+		if !ok {
+			return
+		}
+		task = next
+	}
+}
+
+func (m *Manager) nextSessionTask(lane *sessionLane) (inboundTask, bool) {
+	m.sessionMu.Lock()
+	defer m.sessionMu.Unlock()
+	if len(lane.queue) == 0 {
+		delete(m.lanes, lane.key)
+		return inboundTask{}, false
+	}
+	task := lane.queue[0]
+	lane.queue = lane.queue[1:]
+	m.laneWaiting--
+	m.recordDispatch(task.botID, task.receivedAt)
+	return task, true
+}
+
+// openQueuedLane registers the lane of a message claimed from the durable queue, so later
+// messages of its session signal the processor while it runs.
+func (m *Manager) openQueuedLane(item QueuedInbound) *sessionLane {
+	m.sessionMu.Lock()
+	defer m.sessionMu.Unlock()
+	lane := newSessionLane(item.SessionKey, item.BotID)
+	m.lanes[item.SessionKey] = lane
+	if item.Attempts <= 1 {
+		m.recordDispatch(item.BotID, item.CreatedAt)
+	}
+	return lane
+}
+
+func (m *Manager) closeQueuedLane(lane *sessionLane) {
+	m.sessionMu.Lock()
+	defer m.sessionMu.Unlock()
+	if m.lanes[lane.key] == lane {
+		delete(m.lanes, lane.key)
+	}
+}
+
+// noteQueuedArrival counts a message persisted in the durable queue and signals the message
+// of its session in flight, if any.
+func (m *Manager) noteQueuedArrival(botID, key string) {
+	m.sessionMu.Lock()
+	defer m.sessionMu.Unlock()
+	m.botStats(botID).waiting++
+	if lane := m.lanes[key]; lane != nil {
+		lane.signal()
+	}
+}
+
+// botStats returns the counters of a bot. The caller must hold sessionMu.
+func (m *Manager) botStats(botID string) *inboundBotStats {
+	stats := m.inboundStats[botID]
+	if stats == nil {
+		stats = &inboundBotStats{}
+		m.inboundStats[botID] = stats
+	}
+	return stats
+}
+
+// recordDispatch counts a message handed to the processor and how long it waited. The
+// caller must hold sessionMu.
+func (m *Manager) recordDispatch(botID string, receivedAt time.Time) {
+	stats := m.botStats(botID)
+	if stats.waiting > 0 {
+		stats.waiting--
+	}
+	stats.dispatched++
+	if receivedAt.IsZero() {
+		return
+	}
+	wait := max(time.Since(receivedAt), 0)
+	stats.timed++
+	stats.totalWait += wait
+	stats.lastWait = wait
+	stats.maxWait = max(stats.maxWait, wait)
+}
+
+// InboundStats returns the inbound backlog and wait times of a bot, or of all bots when
+// botID is empty.
+func (m *Manager) InboundStats(botID string) []InboundStats {
+	botID = strings.TrimSpace(botID)
+	m.sessionMu.Lock()
+	defer m.sessionMu.Unlock()
+	active := map[string]int{}
+	for _, lane := range m.lanes {
+		active[lane.botID]++
+	}
+	items := make([]InboundStats, 0, len(m.inboundStats))
+	for id, stats := range m.inboundStats {
+		if botID != "" && id != botID {
+			continue
+		}
+		item := InboundStats{
+			BotID:          id,
+			Waiting:        stats.waiting,
+			ActiveSessions: active[id],
+			Dispatched:     stats.dispatched,
+			MaxWaitMs:      stats.maxWait.Milliseconds(),
+			LastWaitMs:     stats.lastWait.Milliseconds(),
+		}
+		if stats.timed > 0 {
+			item.AvgWaitMs = float64(stats.totalWait.Milliseconds()) / float64(stats.timed)
+		}
+		items = append(items, item)
+	}
+	sort.Slice(items, func(i, j int) bool { return items[i].BotID < items[j].BotID })
+	return items
+}
+
+// sessionInbox is the SessionInbox of the message being processed in a lane.
+type sessionInbox struct {
+	manager *Manager
+	lane    *sessionLane
+	head    string
+	drained []string
+}
+
+func (b *sessionInbox) Arrivals() <-chan struct{} {
+	return b.lane.arrivals
+}
+
+func (b *sessionInbox) Drain(ctx context.Context, accept func(InboundMessage) bool) ([]InboundMessage, error) {
+	if b.manager.inboundStore != nil {
+		return b.drainQueued(ctx, accept)
+	}
+	m := b.manager
+	m.sessionMu.Lock()
+	pending := append([]inboundTask(nil), b.lane.queue...)
+	m.sessionMu.Unlock()
+	var taken []InboundMessage
+	for _, task := range pending {
+		if accept != nil && !accept(task.msg) {
+			break
+		}
+		taken = append(taken, task.msg)
+	}
+	if len(taken) == 0 {
+		return nil, nil
+	}
+	// Only the lane's own worker removes tasks, so the taken ones are still at the front.
+	m.sessionMu.Lock()
+	for _, task := range b.lane.queue[:len(taken)] {
+		m.recordDispatch(task.botID, task.receivedAt)
+	}
+	b.lane.queue = b.lane.queue[len(taken):]
+	m.laneWaiting -= len(taken)
+	m.sessionMu.Unlock()
+	return taken, nil
+}
+
+// drainQueued takes pending messages of the session from the durable queue. They stay in
+// the store until the message being processed completes, so a failed turn retries them too.
+func (b *sessionInbox) drainQueued(ctx context.Context, accept func(InboundMessage) bool) ([]InboundMessage, error) {
+	m := b.manager
+	items, err := m.inboundStore.ListSessionInbound(ctx, b.lane.key)
+	if err != nil {
+		return nil, err
+	}
+	seen := map[string]bool{b.head: true}
+	for _, id := range b.drained {
+		seen[id] = true
+	}
+	var taken []QueuedInbound
+	for _, item := range items {
+		if seen[item.ID] {
+			continue
+		}
+		if accept != nil && !accept(item.Message) {
+			break
+		}
+		taken = append(taken, item)
+	}
+	if len(taken) == 0 {
+		return nil, nil
+	}
+	msgs := make([]InboundMessage, 0, len(taken))
+	m.sessionMu.Lock()
+	for _, item := range taken {
+		b.drained = append(b.drained, item.ID)
+		msgs = append(msgs, item.Message)
+		m.recordDispatch(item.BotID, item.CreatedAt)
+	}
+	m.sessionMu.Unlock()
+	return msgs, nil
+}
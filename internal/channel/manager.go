@@ -43,6 +43,39 @@ type ConfigStore interface {
 	UpsertUserConfig(ctx context.Context, actorUserID string, channelType ChannelType, req UpsertUserConfigRequest) (ChannelUserBinding, error)
 }
 
+// InboundQueueStore persists inbound messages so they survive restarts and processor failures.
+type InboundQueueStore interface {
+	EnqueueInbound(ctx context.Context, cfg ChannelConfig, msg InboundMessage, sessionKey string) (QueuedInbound, error)
+	ClaimInbound(ctx context.Context, limit int) ([]QueuedInbound, error)
+	ListSessionInbound(ctx context.Context, sessionKey string) ([]QueuedInbound, error)
+	CompleteInbound(ctx context.Context, id string) error
+	RetryInbound(ctx context.Context, id string, availableAt time.Time, reason string) error
+	DeadLetterInbound(ctx context.Context, id string, reason string) error
+	ReleaseStaleInbound(ctx context.Context, lockedBefore time.Time) (int64, error)
+}
+
+// SessionKeyFunc returns the conversation an inbound message belongs to, or "" for its
+// channel session. Messages with the same key are processed one at a time.
+type SessionKeyFunc func(ctx context.Context, cfg ChannelConfig, msg InboundMessage) string
+
+// OutboundLogStore records outbound messages with their platform message IDs, so deliveries
+// can be audited and later edited or unsent. Used for outbound sending.
+type OutboundLogStore interface {
+	RecordOutbound(ctx context.Context, record OutboundRecord) (OutboundRecord, error)
+	GetOutbound(ctx context.Context, id string) (OutboundRecord, error)
+	UpdateOutboundStatus(ctx context.Context, id string, status string, msg *Message, reason string) (OutboundRecord, error)
+}
+
+// ConnectionEventStore persists connection lifecycle events. Used by connection lifecycle.
+type ConnectionEventStore interface {
+	RecordConnectionEvent(ctx context.Context, event ConnectionEvent) error
+}
+
+// TargetDirectory resolves human-friendly recipients to delivery targets. Used for outbound sending.
+type TargetDirectory interface {
+	ResolveTargetID(ctx context.Context, botID string, channelType ChannelType, input string) (string, error)
+}
+
 // Middleware wraps an InboundHandler to add cross-cutting behavior.
 type Middleware func(next InboundHandler) InboundHandler
 
@@ -53,8 +86,8 @@ type ManagerStore interface {
 }
 
 // Manager coordinates channel adapters, connection lifecycle, and message dispatch.
-// Connection lifecycle lives in connection.go, inbound dispatch in inbound.go,
-// and outbound pipeline in outbound.go.
+// Connection lifecycle lives in connection.go and health.go, inbound dispatch in
+// inbound.go and inbound_session.go, and outbound pipeline in outbound.go.
 type Manager struct {
 	registry        *Registry
 	service         ManagerStore
@@ -70,6 +103,37 @@ type Manager struct {
 	inboundCancel  context.CancelFunc
 	mu             sync.Mutex
 	connections    map[string]*connectionEntry
+	// stopped holds when configs were stopped with Stop or StopByBot. Refreshes leave them
+	// disconnected until the config is updated.
+	stopped map[string]time.Time
+
+	directory   TargetDirectory
+	commands    []Command
+	outboundLog OutboundLogStore
+
+	inboundStore        InboundQueueStore
+	inboundWake         chan struct{}
+	inboundPollInterval time.Duration
+	inboundLockTimeout  time.Duration
+	inboundMaxAttempts  int
+	inboundRetryBase    time.Duration
+	inboundRetryMax     time.Duration
+
+	sessionKeys  SessionKeyFunc
+	sessionMu    sync.Mutex
+	lanes        map[string]*sessionLane
+	inboundStats map[string]*inboundBotStats
+	// laneWaiting counts the tasks queued behind a running message of their session, at most
+	// maxLaneWaiting across all lanes.
+	laneWaiting    int
+	maxLaneWaiting int
+
+	connectionEvents   ConnectionEventStore
+	healthMu           sync.Mutex
+	health             map[string]*connectionHealth
+	connectRetryBase   time.Duration
+	connectRetryMax    time.Duration
+	connectStableAfter time.Duration
 }
 
 // NewManager creates a Manager with the given logger, registry, config store, and inbound processor.
@@ -86,13 +150,59 @@ func NewManager(log *slog.Logger, registry *Registry, service ManagerStore, proc
 		processor:       processor,
 		refreshInterval: 30 * time.Second,
 		connections:     map[string]*connectionEntry{},
+		stopped:         map[string]time.Time{},
 		logger:          log.With(slog.String("component", "channel")),
 		middlewares:     []Middleware{},
 		inboundQueue:    make(chan inboundTask, 256),
 		inboundWorkers:  4,
+
+		inboundWake:         make(chan struct{}, 1),
+		inboundPollInterval: time.Second,
+		inboundLockTimeout:  15 * time.Minute,
+		inboundMaxAttempts:  5,
+		inboundRetryBase:    5 * time.Second,
+		inboundRetryMax:     5 * time.Minute,
+
+		lanes:          map[string]*sessionLane{},
+		inboundStats:   map[string]*inboundBotStats{},
+		maxLaneWaiting: 256,
+
+		health:             map[string]*connectionHealth{},
+		connectRetryBase:   30 * time.Second,
+		connectRetryMax:    30 * time.Minute,
+		connectStableAfter: 5 * time.Minute,
 	}
 }
 
+// SetInboundQueue makes inbound processing durable: messages are persisted in the store
+// before they are acknowledged and removed only once processed. It must be called before Start.
+func (m *Manager) SetInboundQueue(store InboundQueueStore) {
+	m.inboundStore = store
+}
+
+// SetSessionKeys serializes inbound processing on the conversation returned by fn instead of
+// the channel session, so a conversation shared by several channels never runs twice at once.
+// It must be called before Start.
+func (m *Manager) SetSessionKeys(fn SessionKeyFunc) {
+	m.sessionKeys = fn
+}
+
+// SetDirectory enables sending to SendRequest.To by resolving it through the directory.
+func (m *Manager) SetDirectory(directory TargetDirectory) {
+	m.directory = directory
+}
+
+// SetOutboundLog records every outbound send, including replies, in the store.
+func (m *Manager) SetOutboundLog(store OutboundLogStore) {
+	m.outboundLog = store
+}
+
+// SetCommands sets the bot commands published to platforms with native command menus
+// when their connections start. It must be called before Start.
+func (m *Manager) SetCommands(commands []Command) {
+	m.commands = commands
+}
+
 // Registry returns the adapter registry used by this manager.
 func (m *Manager) Registry() *Registry {
 	return m.registry
@@ -173,41 +283,51 @@ func (m *Manager) Start(ctx context.Context) {
 }
 
 // Send delivers an outbound message to the specified channel, resolving target and config automatically.
-func (m *Manager) Send(ctx context.Context, botID string, channelType ChannelType, req SendRequest) error {
+// It returns the outbound log entry of the delivery, which carries the platform message IDs.
+func (m *Manager) Send(ctx context.Context, botID string, channelType ChannelType, req SendRequest) (OutboundRecord, error) {
 	if m.service == nil {
-		return fmt.Errorf("channel manager not configured")
+		return OutboundRecord{}, fmt.Errorf("channel manager not configured")
 	}
 	sender, ok := m.registry.GetSender(channelType)
 	if !ok {
-		return fmt.Errorf("unsupported channel type: %s", channelType)
+		return OutboundRecord{}, fmt.Errorf("unsupported channel type: %s", channelType)
 	}
 	config, err := m.service.ResolveEffectiveConfig(ctx, botID, channelType)
 	if err != nil {
-		return err
+		return OutboundRecord{}, err
 	}
 	target := strings.TrimSpace(req.Target)
+	if target == "" && strings.TrimSpace(req.UserID) == "" && strings.TrimSpace(req.To) != "" {
+		if m.directory == nil {
+			return OutboundRecord{}, fmt.Errorf("channel directory not configured")
+		}
+		target, err = m.directory.ResolveTargetID(ctx, botID, channelType, strings.TrimSpace(req.To))
+		if err != nil {
+			return OutboundRecord{}, fmt.Errorf("resolve recipient %q: %w", strings.TrimSpace(req.To), err)
+		}
+	}
 	if target == "" {
 		targetUserID := strings.TrimSpace(req.UserID)
 		if targetUserID == "" {
-			return fmt.Errorf("target or user_id is required")
+			return OutboundRecord{}, fmt.Errorf("target, user_id or to is required")
 		}
 		userCfg, err := m.service.GetUserConfig(ctx, targetUserID, channelType)
 		if err != nil {
 			if m.logger != nil {
 				m.logger.Warn("channel binding missing", slog.String("channel", channelType.String()), slog.String("user_id", targetUserID))
 			}
-			return fmt.Errorf("channel binding required")
+			return OutboundRecord{}, fmt.Errorf("channel binding required")
 		}
 		target, err = m.registry.ResolveTargetFromUserConfig(channelType, userCfg.Config)
 		if err != nil {
-			return err
+			return OutboundRecord{}, err
 		}
 	}
 	if normalized, ok := m.registry.NormalizeTarget(channelType, target); ok {
 		target = normalized
 	}
 	if req.Message.IsEmpty() {
-		return fmt.Errorf("message is required")
+		return OutboundRecord{}, fmt.Errorf("message is required")
 	}
 	if m.logger != nil {
 		m.logger.Info("send outbound", slog.String("channel", channelType.String()), slog.String("bot_id", botID))
@@ -218,17 +338,92 @@ func (m *Manager) Send(ctx context.Context, botID string, channelType ChannelTyp
 		Message: req.Message,
 	}, policy)
 	if err != nil {
-		return err
+		return OutboundRecord{}, err
 	}
-	for _, item := range outbound {
-		if err := m.sendWithConfig(ctx, sender, config, item, policy); err != nil {
-			if m.logger != nil {
-				m.logger.Error("send outbound failed", slog.String("channel", channelType.String()), slog.String("bot_id", botID), slog.Any("error", err))
-			}
-			return err
+	delivery := m.deliverOutbound(ctx, sender, config, outbound, policy)
+	record := m.recordOutbound(ctx, config, req.SessionID, target, req.Message, delivery)
+	if delivery.err != nil {
+		if m.logger != nil {
+			m.logger.Error("send outbound failed", slog.String("channel", channelType.String()), slog.String("bot_id", botID), slog.Any("error", delivery.err))
 		}
+		return record, delivery.err
 	}
-	return nil
+	return record, nil
+}
+
+// EditMessage replaces the content of a delivered message, identified by its outbound log
+// entry, and marks the entry edited. Messages delivered in several parts cannot be edited.
+func (m *Manager) EditMessage(ctx context.Context, botID string, channelType ChannelType, id string, msg Message) (OutboundRecord, error) {
+	editor, ok := m.registry.GetMessageEditor(channelType)
+	if !ok {
+		return OutboundRecord{}, ErrEditNotSupported
+	}
+	record, config, err := m.loadOutbound(ctx, botID, channelType, id)
+	if err != nil {
+		return OutboundRecord{}, err
+	}
+	if len(record.MessageIDs) != 1 {
+		return OutboundRecord{}, fmt.Errorf("message was delivered in %d parts and cannot be edited", len(record.MessageIDs))
+	}
+	if msg.IsEmpty() {
+		return OutboundRecord{}, fmt.Errorf("message is required")
+	}
+	normalized := normalizeOutboundMessage(msg)
+	if err := validateMessageCapabilities(m.registry, channelType, normalized); err != nil {
+		return OutboundRecord{}, err
+	}
+	if err := editor.Edit(ctx, config, record.Target, record.MessageIDs[0], normalized); err != nil {
+		return OutboundRecord{}, err
+	}
+	return m.outboundLog.UpdateOutboundStatus(ctx, record.ID, OutboundStatusEdited, &normalized, "")
+}
+
+// UnsendMessage deletes every platform message of a delivered outbound message and marks its
+// outbound log entry deleted.
+func (m *Manager) UnsendMessage(ctx context.Context, botID string, channelType ChannelType, id string) (OutboundRecord, error) {
+	unsender, ok := m.registry.GetMessageUnsender(channelType)
+	if !ok {
+		return OutboundRecord{}, ErrUnsendNotSupported
+	}
+	record, config, err := m.loadOutbound(ctx, botID, channelType, id)
+	if err != nil {
+		return OutboundRecord{}, err
+	}
+	if len(record.MessageIDs) == 0 {
+		return OutboundRecord{}, fmt.Errorf("message has no platform message id")
+	}
+	for _, messageID := range record.MessageIDs {
+		if err := unsender.Unsend(ctx, config, record.Target, messageID); err != nil {
+			return OutboundRecord{}, err
+		}
+	}
+	return m.outboundLog.UpdateOutboundStatus(ctx, record.ID, OutboundStatusDeleted, nil, "")
+}
+
+// loadOutbound returns a delivered outbound log entry of the bot and channel together with
+// the config it was sent with.
+func (m *Manager) loadOutbound(ctx context.Context, botID string, channelType ChannelType, id string) (OutboundRecord, ChannelConfig, error) {
+	if m.service == nil || m.outboundLog == nil {
+		return OutboundRecord{}, ChannelConfig{}, fmt.Errorf("outbound log not configured")
+	}
+	record, err := m.outboundLog.GetOutbound(ctx, strings.TrimSpace(id))
+	if err != nil {
+		return OutboundRecord{}, ChannelConfig{}, err
+	}
+	if record.BotID != botID || record.ChannelType != channelType {
+		return OutboundRecord{}, ChannelConfig{}, ErrOutboundNotFound
+	}
+	switch record.Status {
+	case OutboundStatusFailed:
+		return OutboundRecord{}, ChannelConfig{}, fmt.Errorf("message was not delivered")
+	case OutboundStatusDeleted:
+		return OutboundRecord{}, ChannelConfig{}, fmt.Errorf("message was already deleted")
+	}
+	config, err := m.service.ResolveEffectiveConfig(ctx, botID, channelType)
+	if err != nil {
+		return OutboundRecord{}, ChannelConfig{}, err
+	}
+	return record, config, nil
 }
 
 // Shutdown cancels the inbound worker pool and stops all active connections.
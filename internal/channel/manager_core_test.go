@@ -4,7 +4,9 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
+	"sync"
 	"testing"
+	"time"
 )
 
 // mockAdapter 专门用于 Manager 路由测试
@@ -16,9 +18,9 @@ func (m *mockAdapter) Type() ChannelType { return ChannelType("test") }
 func (m *mockAdapter) Descriptor() Descriptor {
 	return Descriptor{Type: ChannelType("test"), DisplayName: "Test", Capabilities: ChannelCapabilities{Text: true}}
 }
-func (m *mockAdapter) Send(ctx context.Context, cfg ChannelConfig, msg OutboundMessage) error {
+func (m *mockAdapter) Send(ctx context.Context, cfg ChannelConfig, msg OutboundMessage) (SendResult, error) {
 	m.sentMessages = append(m.sentMessages, msg)
-	return nil
+	return SendResult{}, nil
 }
 
 type fakeInboundProcessor struct {
@@ -126,3 +128,577 @@ func TestManager_HandleInbound_CoreLogic(t *testing.T) {
 		}
 	})
 }
+
+// presenceAdapter 记录输入状态的发送与清除
+type presenceAdapter struct {
+	mockAdapter
+	mu      sync.Mutex
+	sends   int
+	cleared []string
+}
+
+func (p *presenceAdapter) Type() ChannelType { return ChannelType("presence-test") }
+func (p *presenceAdapter) Descriptor() Descriptor {
+	return Descriptor{
+		Type:           ChannelType("presence-test"),
+		DisplayName:    "Presence",
+		Capabilities:   ChannelCapabilities{Text: true, Presence: true},
+		OutboundPolicy: OutboundPolicy{PresenceRefreshMs: 5},
+	}
+}
+func (p *presenceAdapter) SendPresence(ctx context.Context, cfg ChannelConfig, msg InboundMessage) (string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.sends++
+	return fmt.Sprintf("presence-%d", p.sends), nil
+}
+func (p *presenceAdapter) ClearPresence(ctx context.Context, cfg ChannelConfig, msg InboundMessage, presenceID string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.cleared = append(p.cleared, presenceID)
+	return nil
+}
+
+type presenceInboundProcessor struct{}
+
+func (presenceInboundProcessor) HandleInbound(ctx context.Context, cfg ChannelConfig, msg InboundMessage, sender ReplySender) error {
+	presence, ok := sender.(PresenceReplySender)
+	if !ok {
+		return fmt.Errorf("sender does not support presence")
+	}
+	stop := presence.StartPresence(ctx, msg)
+	time.Sleep(40 * time.Millisecond)
+	stop()
+	return nil
+}
+
+func TestManager_HandleInbound_RefreshesPresence(t *testing.T) {
+	reg := NewRegistry()
+	m := NewManager(slog.Default(), reg, &fakeConfigStore{}, presenceInboundProcessor{})
+	adapter := &presenceAdapter{}
+	m.RegisterAdapter(adapter)
+
+	cfg := ChannelConfig{ID: "bot-1", BotID: "bot-1", ChannelType: adapter.Type()}
+	msg := InboundMessage{
+		Channel:     adapter.Type(),
+		Message:     Message{Text: "你好"},
+		ReplyTarget: "target-id",
+	}
+	if err := m.handleInbound(context.Background(), cfg, msg); err != nil {
+		t.Fatalf("不应报错: %v", err)
+	}
+
+	adapter.mu.Lock()
+	defer adapter.mu.Unlock()
+	if adapter.sends < 2 {
+		t.Fatalf("输入状态应定期刷新，实际发送: %d", adapter.sends)
+	}
+	if len(adapter.cleared) != 1 || adapter.cleared[0] != fmt.Sprintf("presence-%d", adapter.sends) {
+		t.Fatalf("应使用最后一次的 ID 清除输入状态，实际: %v", adapter.cleared)
+	}
+}
+
+// memoryInboundQueue 是持久化入站队列的内存实现，仅用于测试
+type memoryInboundQueue struct {
+	mu    sync.Mutex
+	seq   int
+	items []*QueuedInbound
+	done  []string
+	// released 记录每次释放超时认领的截止时间
+	released []time.Time
+}
+
+func (q *memoryInboundQueue) EnqueueInbound(ctx context.Context, cfg ChannelConfig, msg InboundMessage, sessionKey string) (QueuedInbound, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.seq++
+	item := &QueuedInbound{
+		ID:              fmt.Sprintf("msg-%d", q.seq),
+		BotID:           cfg.BotID,
+		ChannelConfigID: cfg.ID,
+		ChannelType:     msg.Channel,
+		SessionKey:      sessionKey,
+		Message:         msg,
+		Status:          InboundStatusPending,
+		AvailableAt:     time.Now(),
+	}
+	q.items = append(q.items, item)
+	return *item, nil
+}
+
+func (q *memoryInboundQueue) ClaimInbound(ctx context.Context, limit int) ([]QueuedInbound, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	seen := map[string]bool{}
+	var claimed []QueuedInbound
+	for _, item := range q.items {
+		if item.Status == InboundStatusDead || seen[item.SessionKey] {
+			continue
+		}
+		seen[item.SessionKey] = true
+		if item.Status != InboundStatusPending || item.AvailableAt.After(time.Now()) || len(claimed) >= limit {
+			continue
+		}
+		item.Status = InboundStatusProcessing
+		item.Attempts++
+		claimed = append(claimed, *item)
+	}
+	return claimed, nil
+}
+
+func (q *memoryInboundQueue) ListSessionInbound(ctx context.Context, sessionKey string) ([]QueuedInbound, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	var items []QueuedInbound
+	for _, item := range q.items {
+		if item.SessionKey == sessionKey && item.Status == InboundStatusPending {
+			items = append(items, *item)
+		}
+	}
+	return items, nil
+}
+
+func (q *memoryInboundQueue) find(id string) *QueuedInbound {
+	for _, item := range q.items {
+		if item.ID == id {
+			return item
+		}
+	}
+	return nil
+}
+
+func (q *memoryInboundQueue) CompleteInbound(ctx context.Context, id string) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for i, item := range q.items {
+		if item.ID == id {
+			q.items = append(q.items[:i], q.items[i+1:]...)
+			q.done = append(q.done, id)
+			return nil
+		}
+	}
+	return nil
+}
+
+func (q *memoryInboundQueue) RetryInbound(ctx context.Context, id string, availableAt time.Time, reason string) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if item := q.find(id); item != nil {
+		item.Status = InboundStatusPending
+		item.AvailableAt = availableAt
+		item.LastError = reason
+	}
+	return nil
+}
+
+func (q *memoryInboundQueue) DeadLetterInbound(ctx context.Context, id string, reason string) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if item := q.find(id); item != nil {
+		item.Status = InboundStatusDead
+		item.LastError = reason
+	}
+	return nil
+}
+
+func (q *memoryInboundQueue) ReleaseStaleInbound(ctx context.Context, lockedBefore time.Time) (int64, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.released = append(q.released, lockedBefore)
+	return 0, nil
+}
+
+// flakyInboundProcessor 按文本决定失败次数，并记录处理顺序
+type flakyInboundProcessor struct {
+	mu       sync.Mutex
+	failures map[string]int
+	handled  []string
+}
+
+func (p *flakyInboundProcessor) HandleInbound(ctx context.Context, cfg ChannelConfig, msg InboundMessage, sender ReplySender) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.failures[msg.Message.Text] != 0 {
+		p.failures[msg.Message.Text]--
+		return fmt.Errorf("processor failed: %s", msg.Message.Text)
+	}
+	p.handled = append(p.handled, msg.Message.Text)
+	return nil
+}
+
+func TestManager_DurableInboundQueue(t *testing.T) {
+	queue := &memoryInboundQueue{}
+	processor := &flakyInboundProcessor{failures: map[string]int{"第一条": 1, "坏消息": 100}}
+	m := NewManager(slog.Default(), NewRegistry(), &fakeConfigStore{}, processor)
+	m.RegisterAdapter(&mockAdapter{})
+	m.SetInboundQueue(queue)
+	m.inboundPollInterval = 5 * time.Millisecond
+	m.inboundRetryBase = time.Millisecond
+	m.inboundRetryMax = 5 * time.Millisecond
+	m.inboundMaxAttempts = 3
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	m.startInboundWorkers(ctx)
+
+	cfg := ChannelConfig{ID: "cfg-1", BotID: "bot-1", ChannelType: ChannelType("test")}
+	for _, text := range []string{"第一条", "第二条", "坏消息"} {
+		session := "chat-1"
+		if text == "坏消息" {
+			session = "chat-2"
+		}
+		msg := InboundMessage{
+			Channel:      ChannelType("test"),
+			Message:      Message{Text: text},
+			ReplyTarget:  "target-id",
+			Conversation: Conversation{ID: session, Type: "p2p"},
+		}
+		if err := m.HandleInbound(context.Background(), cfg, msg); err != nil {
+			t.Fatalf("入队不应报错: %v", err)
+		}
+	}
+
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		queue.mu.Lock()
+		remaining := len(queue.items)
+		dead := remaining == 1 && queue.items[0].Status == InboundStatusDead
+		queue.mu.Unlock()
+		if dead {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("队列未在期限内处理完毕，剩余: %d", remaining)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	processor.mu.Lock()
+	handled := append([]string(nil), processor.handled...)
+	processor.mu.Unlock()
+	if len(handled) != 2 || handled[0] != "第一条" || handled[1] != "第二条" {
+		t.Fatalf("同一会话应按顺序处理且失败后重试，实际: %v", handled)
+	}
+	queue.mu.Lock()
+	defer queue.mu.Unlock()
+	if item := queue.items[0]; item.Attempts != 3 || item.LastError == "" {
+		t.Fatalf("应在达到最大次数后进入死信，实际: %+v", item)
+	}
+	// 启动时也只释放超过锁超时的认领，其他副本正在处理的消息不受影响
+	if len(queue.released) == 0 || time.Since(queue.released[0]) < m.inboundLockTimeout {
+		t.Fatalf("启动时只应释放超时的认领，实际: %v", queue.released)
+	}
+}
+
+// sessionOrderProcessor 记录每个会话的处理顺序，并检测同一会话是否被并发处理
+type sessionOrderProcessor struct {
+	mu      sync.Mutex
+	running map[string]int
+	overlap bool
+	order   map[string][]string
+	started chan string
+	release chan struct{}
+	hold    string
+}
+
+func (p *sessionOrderProcessor) HandleInbound(ctx context.Context, cfg ChannelConfig, msg InboundMessage, sender ReplySender) error {
+	session := msg.Conversation.ID
+	p.mu.Lock()
+	p.running[session]++
+	if p.running[session] > 1 {
+		p.overlap = true
+	}
+	p.order[session] = append(p.order[session], msg.Message.Text)
+	p.mu.Unlock()
+	p.started <- msg.Message.Text
+	if msg.Message.Text == p.hold {
+		<-p.release
+	}
+	p.mu.Lock()
+	p.running[session]--
+	p.mu.Unlock()
+	return nil
+}
+
+func sessionTestMessage(session, text string) InboundMessage {
+	return InboundMessage{
+		Channel:      ChannelType("test"),
+		Message:      Message{Text: text},
+		ReplyTarget:  "target-id",
+		Conversation: Conversation{ID: session, Type: "p2p"},
+	}
+}
+
+func TestManager_InboundSerializedPerSession(t *testing.T) {
+	processor := &sessionOrderProcessor{
+		running: map[string]int{},
+		order:   map[string][]string{},
+		started: make(chan string, 8),
+		release: make(chan struct{}),
+		hold:    "A1",
+	}
+	m := NewManager(slog.Default(), NewRegistry(), &fakeConfigStore{}, processor)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	m.startInboundWorkers(ctx)
+
+	cfg := ChannelConfig{ID: "cfg-1", BotID: "bot-1", ChannelType: ChannelType("test")}
+	for _, item := range [][2]string{{"chat-a", "A1"}, {"chat-a", "A2"}, {"chat-a", "A3"}, {"chat-b", "B1"}} {
+		if err := m.HandleInbound(context.Background(), cfg, sessionTestMessage(item[0], item[1])); err != nil {
+			t.Fatalf("入队不应报错: %v", err)
+		}
+	}
+
+	started := map[string]bool{}
+	for len(started) < 2 {
+		select {
+		case text := <-processor.started:
+			started[text] = true
+		case <-time.After(time.Second):
+			t.Fatalf("不同会话应并行处理，已开始: %v", started)
+		}
+	}
+	if !started["A1"] || !started["B1"] {
+		t.Fatalf("同一会话的后续消息不应在前一条完成前开始，已开始: %v", started)
+	}
+	stats := m.InboundStats("bot-1")
+	if len(stats) != 1 || stats[0].Waiting != 2 || stats[0].Dispatched != 2 {
+		t.Fatalf("应有两条消息在会话中等待，实际: %+v", stats)
+	}
+
+	close(processor.release)
+	for i := 0; i < 2; i++ {
+		select {
+		case <-processor.started:
+		case <-time.After(time.Second):
+			t.Fatal("会话中的后续消息未被处理")
+		}
+	}
+	deadline := time.Now().Add(time.Second)
+	for m.InboundStats("bot-1")[0].ActiveSessions > 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("处理完成后会话应释放")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	processor.mu.Lock()
+	defer processor.mu.Unlock()
+	if order := processor.order["chat-a"]; len(order) != 3 || order[0] != "A1" || order[1] != "A2" || order[2] != "A3" {
+		t.Fatalf("同一会话应按到达顺序处理，实际: %v", order)
+	}
+	if processor.overlap {
+		t.Fatal("同一会话不应被并发处理")
+	}
+	stats = m.InboundStats("")
+	if stats[0].Waiting != 0 || stats[0].Dispatched != 4 || stats[0].ActiveSessions != 0 {
+		t.Fatalf("处理完成后统计错误: %+v", stats)
+	}
+}
+
+func TestManager_InboundSerializedPerSessionKey(t *testing.T) {
+	processor := &sessionOrderProcessor{
+		running: map[string]int{},
+		order:   map[string][]string{},
+		started: make(chan string, 8),
+		release: make(chan struct{}),
+		hold:    "A1",
+	}
+	m := NewManager(slog.Default(), NewRegistry(), &fakeConfigStore{}, processor)
+	// 两个渠道会话属于同一联系人会话
+	m.SetSessionKeys(func(ctx context.Context, cfg ChannelConfig, msg InboundMessage) string {
+		return "contact:bot-1:contact-1"
+	})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	m.startInboundWorkers(ctx)
+
+	cfg := ChannelConfig{ID: "cfg-1", BotID: "bot-1", ChannelType: ChannelType("test")}
+	for _, item := range [][2]string{{"chat-a", "A1"}, {"chat-b", "B1"}} {
+		if err := m.HandleInbound(context.Background(), cfg, sessionTestMessage(item[0], item[1])); err != nil {
+			t.Fatalf("入队不应报错: %v", err)
+		}
+	}
+	if text := <-processor.started; text != "A1" {
+		t.Fatalf("应先处理 A1，实际: %s", text)
+	}
+	select {
+	case text := <-processor.started:
+		t.Fatalf("同一联系人会话的消息不应并发处理: %s", text)
+	case <-time.After(50 * time.Millisecond):
+	}
+	close(processor.release)
+	select {
+	case text := <-processor.started:
+		if text != "B1" {
+			t.Fatalf("应接着处理 B1，实际: %s", text)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("前一条完成后应处理下一条")
+	}
+}
+
+func TestManager_InboundLaneWaitingCapped(t *testing.T) {
+	processor := &sessionOrderProcessor{
+		running: map[string]int{},
+		order:   map[string][]string{},
+		started: make(chan string, 8),
+		release: make(chan struct{}),
+		hold:    "A1",
+	}
+	m := NewManager(slog.Default(), NewRegistry(), &fakeConfigStore{}, processor)
+	m.maxLaneWaiting = 2
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	m.startInboundWorkers(ctx)
+
+	cfg := ChannelConfig{ID: "cfg-1", BotID: "bot-1", ChannelType: ChannelType("test")}
+	if err := m.HandleInbound(context.Background(), cfg, sessionTestMessage("chat-a", "A1")); err != nil {
+		t.Fatalf("入队不应报错: %v", err)
+	}
+	<-processor.started
+	// 正在处理的会话后面最多排队 maxLaneWaiting 条
+	for _, text := range []string{"A2", "A3"} {
+		if err := m.HandleInbound(context.Background(), cfg, sessionTestMessage("chat-a", text)); err != nil {
+			t.Fatalf("未达上限时入队不应报错: %v", err)
+		}
+	}
+	if err := m.HandleInbound(context.Background(), cfg, sessionTestMessage("chat-a", "A4")); err == nil {
+		t.Fatal("排队消息达到上限时应返回错误")
+	}
+	close(processor.release)
+	for i := 0; i < 2; i++ {
+		select {
+		case <-processor.started:
+		case <-time.After(time.Second):
+			t.Fatal("排队的消息未被处理")
+		}
+	}
+	deadline := time.Now().Add(time.Second)
+	for {
+		m.sessionMu.Lock()
+		waiting := m.laneWaiting
+		m.sessionMu.Unlock()
+		if waiting == 0 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("处理完成后排队计数应归零，实际: %d", waiting)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+// drainingProcessor 在处理首条消息时等待后续消息到达，并从会话队列中取出它们
+type drainingProcessor struct {
+	mu      sync.Mutex
+	handled []string
+	drained []string
+	started chan struct{}
+	release chan struct{}
+}
+
+func (p *drainingProcessor) HandleInbound(ctx context.Context, cfg ChannelConfig, msg InboundMessage, sender ReplySender) error {
+	p.mu.Lock()
+	p.handled = append(p.handled, msg.Message.Text)
+	p.mu.Unlock()
+	if msg.Message.Text != "首条" {
+		return nil
+	}
+	inbox, ok := SessionInboxFromContext(ctx)
+	if !ok {
+		return fmt.Errorf("session inbox missing")
+	}
+	close(p.started)
+	<-p.release
+	select {
+	case <-inbox.Arrivals():
+	case <-time.After(time.Second):
+		return fmt.Errorf("arrival not signaled")
+	}
+	items, err := inbox.Drain(ctx, func(InboundMessage) bool { return true })
+	if err != nil {
+		return err
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for _, item := range items {
+		p.drained = append(p.drained, item.Message.Text)
+	}
+	return nil
+}
+
+func TestManager_SessionInboxDrain(t *testing.T) {
+	for _, durable := range []bool{false, true} {
+		t.Run(fmt.Sprintf("durable=%v", durable), func(t *testing.T) {
+			processor := &drainingProcessor{started: make(chan struct{}), release: make(chan struct{})}
+			m := NewManager(slog.Default(), NewRegistry(), &fakeConfigStore{}, processor)
+			queue := &memoryInboundQueue{}
+			if durable {
+				m.SetInboundQueue(queue)
+				m.inboundPollInterval = 5 * time.Millisecond
+			}
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			m.startInboundWorkers(ctx)
+
+			cfg := ChannelConfig{ID: "cfg-1", BotID: "bot-1", ChannelType: ChannelType("test")}
+			if err := m.HandleInbound(context.Background(), cfg, sessionTestMessage("chat-1", "首条")); err != nil {
+				t.Fatalf("入队不应报错: %v", err)
+			}
+			select {
+			case <-processor.started:
+			case <-time.After(time.Second):
+				t.Fatal("首条消息未被处理")
+			}
+			for _, text := range []string{"第二条", "第三条"} {
+				if err := m.HandleInbound(context.Background(), cfg, sessionTestMessage("chat-1", text)); err != nil {
+					t.Fatalf("入队不应报错: %v", err)
+				}
+			}
+			close(processor.release)
+
+			deadline := time.Now().Add(2 * time.Second)
+			for {
+				stats := m.InboundStats("bot-1")
+				queue.mu.Lock()
+				remaining := len(queue.items)
+				queue.mu.Unlock()
+				if len(stats) == 1 && stats[0].ActiveSessions == 0 && remaining == 0 {
+					break
+				}
+				if time.Now().After(deadline) {
+					t.Fatalf("会话未在期限内处理完毕: %+v, 队列剩余: %d", stats, remaining)
+				}
+				time.Sleep(5 * time.Millisecond)
+			}
+			time.Sleep(20 * time.Millisecond)
+
+			processor.mu.Lock()
+			defer processor.mu.Unlock()
+			if len(processor.drained) != 2 || processor.drained[0] != "第二条" || processor.drained[1] != "第三条" {
+				t.Fatalf("应按顺序取出后续消息，实际: %v", processor.drained)
+			}
+			if len(processor.handled) != 1 {
+				t.Fatalf("已取出的消息不应再次处理，实际: %v", processor.handled)
+			}
+			if stats := m.InboundStats("bot-1"); stats[0].Waiting != 0 || stats[0].Dispatched != 3 {
+				t.Fatalf("统计错误: %+v", stats)
+			}
+			if durable && (len(queue.done) != 3 || queue.done[2] != "msg-1") {
+				t.Fatalf("取出的消息应先于首条消息完成，实际: %v", queue.done)
+			}
+		})
+	}
+}
+
+func TestInboundRetryDelay(t *testing.T) {
+	base := 5 * time.Second
+	max := time.Minute
+	cases := map[int]time.Duration{1: 5 * time.Second, 2: 10 * time.Second, 3: 20 * time.Second, 5: time.Minute, 10: time.Minute}
+	for attempts, want := range cases {
+		if got := inboundRetryDelay(attempts, base, max); got != want {
+			t.Errorf("attempts=%d 期望 %s，实际 %s", attempts, want, got)
+		}
+	}
+}
@@ -2,6 +2,7 @@ package channel
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"log/slog"
@@ -91,6 +92,8 @@ type fakeAdapter struct {
 	started     []ChannelConfig
 	sent        []OutboundMessage
 	stops       int
+	connectErr  error
+	conns       []*BaseConnection
 }
 
 func (f *fakeAdapter) Type() ChannelType {
@@ -113,22 +116,28 @@ func (f *fakeAdapter) NormalizeTarget(raw string) string { return strings.TrimSp
 
 func (f *fakeAdapter) Connect(ctx context.Context, cfg ChannelConfig, handler InboundHandler) (Connection, error) {
 	f.mu.Lock()
+	defer f.mu.Unlock()
 	f.started = append(f.started, cfg)
-	f.mu.Unlock()
+	if f.connectErr != nil {
+		return nil, f.connectErr
+	}
 	stop := func(context.Context) error {
 		f.mu.Lock()
 		f.stops++
 		f.mu.Unlock()
 		return nil
 	}
-	return NewConnection(cfg, stop), nil
+	conn := NewConnection(cfg, stop)
+	f.conns = append(f.conns, conn)
+	return conn, nil
 }
 
-func (f *fakeAdapter) Send(ctx context.Context, cfg ChannelConfig, msg OutboundMessage) error {
+func (f *fakeAdapter) Send(ctx context.Context, cfg ChannelConfig, msg OutboundMessage) (SendResult, error) {
 	f.mu.Lock()
 	f.sent = append(f.sent, msg)
+	id := fmt.Sprintf("m%d", len(f.sent))
 	f.mu.Unlock()
-	return nil
+	return SendResult{MessageIDs: []string{id}}, nil
 }
 
 func TestManagerHandleInboundIntegratesAdapter(t *testing.T) {
@@ -212,7 +221,7 @@ func TestManagerSendUsesBinding(t *testing.T) {
 	manager := NewManager(log, reg, store, &fakeInboundProcessorIntegration{})
 	manager.RegisterAdapter(adapter)
 
-	err := manager.Send(context.Background(), "bot-1", ChannelType("test"), SendRequest{
+	_, err := manager.Send(context.Background(), "bot-1", ChannelType("test"), SendRequest{
 		UserID: "user-1",
 		Message: Message{
 			Text: "hello",
@@ -232,6 +241,226 @@ func TestManagerSendUsesBinding(t *testing.T) {
 	}
 }
 
+type fakeTargetDirectory struct {
+	targets map[string]string
+}
+
+func (f *fakeTargetDirectory) ResolveTargetID(ctx context.Context, botID string, channelType ChannelType, input string) (string, error) {
+	target, ok := f.targets[input]
+	if !ok {
+		return "", ErrDirectoryEntryNotFound
+	}
+	return target, nil
+}
+
+func TestManagerSendResolvesRecipientThroughDirectory(t *testing.T) {
+	t.Parallel()
+
+	log := slog.New(slog.NewTextHandler(io.Discard, &slog.HandlerOptions{}))
+	store := &fakeConfigStore{
+		effectiveConfig: ChannelConfig{
+			ID:          "cfg-1",
+			BotID:       "bot-1",
+			ChannelType: ChannelType("test"),
+		},
+	}
+	reg := NewRegistry()
+	adapter := &fakeAdapter{channelType: ChannelType("test")}
+	manager := NewManager(log, reg, store, &fakeInboundProcessorIntegration{})
+	manager.RegisterAdapter(adapter)
+
+	req := SendRequest{To: "Alice", Message: Message{Text: "hello"}}
+	if _, err := manager.Send(context.Background(), "bot-1", ChannelType("test"), req); err == nil {
+		t.Fatalf("expected error without directory")
+	}
+	manager.SetDirectory(&fakeTargetDirectory{targets: map[string]string{"Alice": "100"}})
+	if _, err := manager.Send(context.Background(), "bot-1", ChannelType("test"), req); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	_, err := manager.Send(context.Background(), "bot-1", ChannelType("test"), SendRequest{To: "Bob", Message: Message{Text: "hello"}})
+	if !errors.Is(err, ErrDirectoryEntryNotFound) {
+		t.Fatalf("expected not found, got %v", err)
+	}
+
+	adapter.mu.Lock()
+	defer adapter.mu.Unlock()
+	if len(adapter.sent) != 1 {
+		t.Fatalf("expected 1 send, got %d", len(adapter.sent))
+	}
+	if adapter.sent[0].Target != "100" {
+		t.Fatalf("unexpected outbound message: %+v", adapter.sent[0])
+	}
+}
+
+type fakeOutboundLog struct {
+	mu      sync.Mutex
+	records map[string]OutboundRecord
+}
+
+func (f *fakeOutboundLog) RecordOutbound(ctx context.Context, record OutboundRecord) (OutboundRecord, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.records == nil {
+		f.records = map[string]OutboundRecord{}
+	}
+	record.ID = fmt.Sprintf("out-%d", len(f.records)+1)
+	f.records[record.ID] = record
+	return record, nil
+}
+
+func (f *fakeOutboundLog) GetOutbound(ctx context.Context, id string) (OutboundRecord, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	record, ok := f.records[id]
+	if !ok {
+		return OutboundRecord{}, ErrOutboundNotFound
+	}
+	return record, nil
+}
+
+func (f *fakeOutboundLog) UpdateOutboundStatus(ctx context.Context, id string, status string, msg *Message, reason string) (OutboundRecord, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	record, ok := f.records[id]
+	if !ok {
+		return OutboundRecord{}, ErrOutboundNotFound
+	}
+	record.Status = status
+	record.LastError = reason
+	if msg != nil {
+		record.Message = *msg
+	}
+	f.records[id] = record
+	return record, nil
+}
+
+type fakeEditableAdapter struct {
+	fakeAdapter
+	edited   map[string]string
+	unsent   []string
+	draftSeq int
+}
+
+func (f *fakeEditableAdapter) SendEditable(ctx context.Context, cfg ChannelConfig, msg OutboundMessage) (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.draftSeq++
+	return fmt.Sprintf("draft-%d", f.draftSeq), nil
+}
+
+func (f *fakeEditableAdapter) Edit(ctx context.Context, cfg ChannelConfig, target, messageID string, msg Message) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.edited == nil {
+		f.edited = map[string]string{}
+	}
+	f.edited[messageID] = msg.PlainText()
+	return nil
+}
+
+func (f *fakeEditableAdapter) Unsend(ctx context.Context, cfg ChannelConfig, target, messageID string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.unsent = append(f.unsent, target+"/"+messageID)
+	return nil
+}
+
+func TestManagerSendRecordsOutboundLog(t *testing.T) {
+	t.Parallel()
+
+	log := slog.New(slog.NewTextHandler(io.Discard, &slog.HandlerOptions{}))
+	store := &fakeConfigStore{
+		effectiveConfig: ChannelConfig{
+			ID:          "cfg-1",
+			BotID:       "bot-1",
+			ChannelType: ChannelType("test"),
+		},
+	}
+	reg := NewRegistry()
+	adapter := &fakeEditableAdapter{fakeAdapter: fakeAdapter{channelType: ChannelType("test")}}
+	outboundLog := &fakeOutboundLog{}
+	manager := NewManager(log, reg, store, &fakeInboundProcessorIntegration{})
+	manager.RegisterAdapter(adapter)
+	manager.SetOutboundLog(outboundLog)
+
+	record, err := manager.Send(context.Background(), "bot-1", ChannelType("test"), SendRequest{
+		Target:    "100",
+		SessionID: "test:bot-1:100",
+		Message:   Message{Text: "hello"},
+	})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if record.ID == "" || record.Status != OutboundStatusSent || record.Attempts != 1 || record.SessionID != "test:bot-1:100" {
+		t.Fatalf("unexpected outbound record: %+v", record)
+	}
+	if len(record.MessageIDs) != 1 || record.MessageIDs[0] != "m1" {
+		t.Fatalf("expected platform message id, got %+v", record.MessageIDs)
+	}
+
+	edited, err := manager.EditMessage(context.Background(), "bot-1", ChannelType("test"), record.ID, Message{Text: "hello again"})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if edited.Status != OutboundStatusEdited || edited.Message.PlainText() != "hello again" || adapter.edited["m1"] != "hello again" {
+		t.Fatalf("unexpected edit: %+v %+v", edited, adapter.edited)
+	}
+
+	if _, err := manager.UnsendMessage(context.Background(), "bot-2", ChannelType("test"), record.ID); !errors.Is(err, ErrOutboundNotFound) {
+		t.Fatalf("expected not found for another bot, got %v", err)
+	}
+	deleted, err := manager.UnsendMessage(context.Background(), "bot-1", ChannelType("test"), record.ID)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if deleted.Status != OutboundStatusDeleted || len(adapter.unsent) != 1 || adapter.unsent[0] != "100/m1" {
+		t.Fatalf("unexpected unsend: %+v %+v", deleted, adapter.unsent)
+	}
+	if _, err := manager.EditMessage(context.Background(), "bot-1", ChannelType("test"), record.ID, Message{Text: "late"}); err == nil {
+		t.Fatalf("expected error editing a deleted message")
+	}
+}
+
+func TestManagerReplySenderRecordsSession(t *testing.T) {
+	t.Parallel()
+
+	log := slog.New(slog.NewTextHandler(io.Discard, &slog.HandlerOptions{}))
+	reg := NewRegistry()
+	adapter := &fakeEditableAdapter{fakeAdapter: fakeAdapter{channelType: ChannelType("test")}}
+	outboundLog := &fakeOutboundLog{}
+	manager := NewManager(log, reg, &fakeConfigStore{}, &fakeInboundProcessorIntegration{})
+	manager.RegisterAdapter(adapter)
+	manager.SetOutboundLog(outboundLog)
+
+	cfg := ChannelConfig{ID: "cfg-1", BotID: "bot-1", ChannelType: ChannelType("test")}
+	sender := manager.newReplySender(cfg, ChannelType("test"), "test:bot-1:chat-1").(StreamReplySender)
+	messageID, err := sender.SendEditable(context.Background(), OutboundMessage{Target: "chat-1", Message: Message{Text: "draft"}})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if err := sender.Edit(context.Background(), "chat-1", messageID, Message{Text: "final"}); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if err := sender.Send(context.Background(), OutboundMessage{Target: "chat-1", Message: Message{Text: "more"}}); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	draft, err := outboundLog.GetOutbound(context.Background(), "out-1")
+	if err != nil {
+		t.Fatalf("expected draft record, got %v", err)
+	}
+	if draft.SessionID != "test:bot-1:chat-1" || draft.Message.PlainText() != "final" || draft.Status != OutboundStatusSent || draft.MessageIDs[0] != messageID {
+		t.Fatalf("unexpected draft record: %+v", draft)
+	}
+	reply, err := outboundLog.GetOutbound(context.Background(), "out-2")
+	if err != nil {
+		t.Fatalf("expected reply record, got %v", err)
+	}
+	if reply.SessionID != "test:bot-1:chat-1" || reply.Target != "chat-1" || len(reply.MessageIDs) != 1 {
+		t.Fatalf("unexpected reply record: %+v", reply)
+	}
+}
+
 func TestManagerReconcileStartsAndStops(t *testing.T) {
 	t.Parallel()
 
@@ -261,3 +490,153 @@ func TestManagerReconcileStartsAndStops(t *testing.T) {
 		t.Fatalf("expected 1 stop, got %d", adapter.stops)
 	}
 }
+
+func TestManagerStopKeepsConfigStopped(t *testing.T) {
+	t.Parallel()
+
+	log := slog.New(slog.NewTextHandler(io.Discard, &slog.HandlerOptions{}))
+	adapter := &fakeAdapter{channelType: ChannelType("test")}
+	manager := NewManager(log, NewRegistry(), &fakeConfigStore{}, &fakeInboundProcessorIntegration{})
+	manager.RegisterAdapter(adapter)
+	cfg := ChannelConfig{ID: "cfg-1", BotID: "bot-1", ChannelType: ChannelType("test"), UpdatedAt: time.Now().Add(-time.Minute)}
+
+	manager.reconcile(context.Background(), []ChannelConfig{cfg})
+	if err := manager.Stop(context.Background(), cfg.ID); err != nil {
+		t.Fatalf("stop failed: %v", err)
+	}
+	manager.reconcile(context.Background(), []ChannelConfig{cfg})
+	if len(adapter.started) != 1 {
+		t.Fatalf("a stopped config should not reconnect on refresh, got %d starts", len(adapter.started))
+	}
+
+	cfg.UpdatedAt = time.Now().Add(time.Second)
+	manager.reconcile(context.Background(), []ChannelConfig{cfg})
+	if len(adapter.started) != 2 {
+		t.Fatalf("an updated config should reconnect, got %d starts", len(adapter.started))
+	}
+}
+
+type fakeConnectionEventLog struct {
+	mu     sync.Mutex
+	events []ConnectionEvent
+}
+
+func (f *fakeConnectionEventLog) RecordConnectionEvent(ctx context.Context, event ConnectionEvent) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.events = append(f.events, event)
+	return nil
+}
+
+func (f *fakeConnectionEventLog) types() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	items := make([]string, 0, len(f.events))
+	for _, event := range f.events {
+		items = append(items, event.EventType)
+	}
+	return items
+}
+
+func TestManagerConnectionHealthAndBackoff(t *testing.T) {
+	t.Parallel()
+
+	log := slog.New(slog.NewTextHandler(io.Discard, &slog.HandlerOptions{}))
+	adapter := &fakeAdapter{channelType: ChannelType("test"), connectErr: errors.New("invalid token")}
+	events := &fakeConnectionEventLog{}
+	cfg := ChannelConfig{ID: "cfg-1", BotID: "bot-1", ChannelType: ChannelType("test"), UpdatedAt: time.Now()}
+	manager := NewManager(log, NewRegistry(), &fakeConfigStore{effectiveConfig: cfg}, &fakeInboundProcessorIntegration{})
+	manager.RegisterAdapter(adapter)
+	manager.SetConnectionEventLog(events)
+	manager.connectRetryBase = time.Hour
+
+	manager.reconcile(context.Background(), []ChannelConfig{cfg})
+	status := manager.ConnectionStatus(cfg)
+	if status.State != ConnectionStateBackoff || status.LastError != "invalid token" || status.ConsecutiveFailures != 1 || status.NextRetryAt == nil {
+		t.Fatalf("failed connect should back off, got %+v", status)
+	}
+
+	manager.reconcile(context.Background(), []ChannelConfig{cfg})
+	if len(adapter.started) != 1 {
+		t.Fatalf("should not reconnect during backoff, got %d attempts", len(adapter.started))
+	}
+
+	adapter.mu.Lock()
+	adapter.connectErr = nil
+	adapter.mu.Unlock()
+	cfg.UpdatedAt = cfg.UpdatedAt.Add(time.Second)
+	manager.reconcile(context.Background(), []ChannelConfig{cfg})
+	status = manager.ConnectionStatus(cfg)
+	if !status.Connected || status.ConnectedAt == nil || status.RestartCount != 0 {
+		t.Fatalf("updated config should connect right away, got %+v", status)
+	}
+
+	if _, err := manager.Send(context.Background(), "bot-1", ChannelType("test"), SendRequest{Target: "t1", Message: Message{Text: "hi"}}); err != nil {
+		t.Fatalf("send failed: %v", err)
+	}
+	if status = manager.ConnectionStatus(cfg); status.LastOutboundAt == nil {
+		t.Fatalf("send should update last outbound time, got %+v", status)
+	}
+
+	adapter.conns[0].Fail(errors.New("websocket closed"))
+	if status = manager.ConnectionStatus(cfg); status.Connected || status.LastError != "websocket closed" {
+		t.Fatalf("failed connection should report the error, got %+v", status)
+	}
+	manager.reconcile(context.Background(), []ChannelConfig{cfg})
+	manager.reconcile(context.Background(), []ChannelConfig{cfg})
+	status = manager.ConnectionStatus(cfg)
+	if status.State != ConnectionStateBackoff || status.ConsecutiveFailures != 1 || status.NextRetryAt == nil {
+		t.Fatalf("dropped connection should back off, got %+v", status)
+	}
+	if len(adapter.started) != 2 {
+		t.Fatalf("should not reconnect during backoff, got %d attempts", len(adapter.started))
+	}
+
+	manager.reconcile(context.Background(), nil)
+	got := events.types()
+	want := []string{ConnectionEventFailed, ConnectionEventConnected, ConnectionEventDropped}
+	if len(got) != len(want) {
+		t.Fatalf("unexpected events: %v", got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("unexpected events: %v", got)
+		}
+	}
+}
+
+type fakeCommandAdapter struct {
+	fakeAdapter
+	registered [][]Command
+}
+
+func (f *fakeCommandAdapter) Descriptor() Descriptor {
+	return Descriptor{Type: f.channelType, DisplayName: "Fake", Capabilities: ChannelCapabilities{Text: true, NativeCommands: true}}
+}
+
+func (f *fakeCommandAdapter) RegisterCommands(ctx context.Context, cfg ChannelConfig, commands []Command) error {
+	f.mu.Lock()
+	f.registered = append(f.registered, commands)
+	f.mu.Unlock()
+	return nil
+}
+
+func TestManagerRegistersCommandsOnConnect(t *testing.T) {
+	t.Parallel()
+
+	log := slog.New(slog.NewTextHandler(io.Discard, &slog.HandlerOptions{}))
+	reg := NewRegistry()
+	adapter := &fakeCommandAdapter{fakeAdapter: fakeAdapter{channelType: ChannelType("test")}}
+	manager := NewManager(log, reg, &fakeConfigStore{}, &fakeInboundProcessorIntegration{})
+	manager.RegisterAdapter(adapter)
+	manager.SetCommands([]Command{{Name: "new", Description: "Start a new session"}})
+
+	cfg := ChannelConfig{ID: "cfg-1", BotID: "bot-1", ChannelType: ChannelType("test"), UpdatedAt: time.Now()}
+	manager.reconcile(context.Background(), []ChannelConfig{cfg})
+
+	adapter.mu.Lock()
+	defer adapter.mu.Unlock()
+	if len(adapter.registered) != 1 || len(adapter.registered[0]) != 1 || adapter.registered[0][0].Name != "new" {
+		t.Fatalf("expected commands to be registered once, got %+v", adapter.registered)
+	}
+}
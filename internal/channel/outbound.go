@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"log/slog"
 	"strings"
+	"sync"
 	"time"
 )
 
@@ -35,6 +36,12 @@ type OutboundPolicy struct {
 	MediaOrder     OutboundOrder `json:"media_order,omitempty"`
 	RetryMax       int           `json:"retry_max,omitempty"`
 	RetryBackoffMs int           `json:"retry_backoff_ms,omitempty"`
+	// StreamEditIntervalMs throttles draft edits while a reply is streamed.
+	StreamEditIntervalMs int `json:"stream_edit_interval_ms,omitempty"`
+	// StreamMaxEdits caps intermediate draft edits per message; zero means unlimited.
+	StreamMaxEdits int `json:"stream_max_edits,omitempty"`
+	// PresenceRefreshMs re-sends the presence indicator while a reply is prepared; zero sends it once.
+	PresenceRefreshMs int `json:"presence_refresh_ms,omitempty"`
 }
 
 // NormalizeOutboundPolicy fills zero-value fields with sensible defaults.
@@ -54,6 +61,9 @@ func NormalizeOutboundPolicy(policy OutboundPolicy) OutboundPolicy {
 	if policy.RetryBackoffMs <= 0 {
 		policy.RetryBackoffMs = 500
 	}
+	if policy.StreamEditIntervalMs <= 0 {
+		policy.StreamEditIntervalMs = 1000
+	}
 	if policy.Chunker == nil {
 		policy.Chunker = DefaultChunker(policy.ChunkerMode)
 	}
@@ -305,25 +315,28 @@ func validateMessageCapabilities(registry *Registry, channelType ChannelType, ms
 	return nil
 }
 
-func (m *Manager) sendWithConfig(ctx context.Context, sender Sender, cfg ChannelConfig, msg OutboundMessage, policy OutboundPolicy) error {
+// sendWithConfig sends a single message, retrying per the policy. It also reports how many
+// attempts were made, for the outbound log.
+func (m *Manager) sendWithConfig(ctx context.Context, sender Sender, cfg ChannelConfig, msg OutboundMessage, policy OutboundPolicy) (SendResult, int, error) {
 	if sender == nil {
-		return fmt.Errorf("unsupported channel type: %s", cfg.ChannelType)
+		return SendResult{}, 0, fmt.Errorf("unsupported channel type: %s", cfg.ChannelType)
 	}
 	target := strings.TrimSpace(msg.Target)
 	if target == "" {
-		return fmt.Errorf("target is required")
+		return SendResult{}, 0, fmt.Errorf("target is required")
 	}
 	if msg.Message.IsEmpty() {
-		return fmt.Errorf("message is required")
+		return SendResult{}, 0, fmt.Errorf("message is required")
 	}
 	if err := validateMessageCapabilities(m.registry, cfg.ChannelType, msg.Message); err != nil {
-		return err
+		return SendResult{}, 0, err
 	}
 	var lastErr error
 	for i := 0; i < policy.RetryMax; i++ {
-		err := sender.Send(ctx, cfg, OutboundMessage{Target: target, Message: msg.Message})
+		result, err := sender.Send(ctx, cfg, OutboundMessage{Target: target, Message: msg.Message})
 		if err == nil {
-			return nil
+			m.noteOutbound(cfg)
+			return result, i + 1, nil
 		}
 		lastErr = err
 		if m.logger != nil {
@@ -334,7 +347,60 @@ func (m *Manager) sendWithConfig(ctx context.Context, sender Sender, cfg Channel
 		}
 		time.Sleep(time.Duration(i+1) * time.Duration(policy.RetryBackoffMs) * time.Millisecond)
 	}
-	return fmt.Errorf("send outbound failed after retries: %w", lastErr)
+	return SendResult{}, policy.RetryMax, fmt.Errorf("send outbound failed after retries: %w", lastErr)
+}
+
+// outboundDelivery is the outcome of sending the messages an outbound message was split into.
+type outboundDelivery struct {
+	messageIDs []string
+	attempts   int
+	err        error
+}
+
+// deliverOutbound sends the parts of an outbound message in order and stops at the first failure.
+func (m *Manager) deliverOutbound(ctx context.Context, sender Sender, cfg ChannelConfig, items []OutboundMessage, policy OutboundPolicy) outboundDelivery {
+	var delivery outboundDelivery
+	for _, item := range items {
+		result, attempts, err := m.sendWithConfig(ctx, sender, cfg, item, policy)
+		delivery.attempts += attempts
+		delivery.messageIDs = append(delivery.messageIDs, result.MessageIDs...)
+		if err != nil {
+			delivery.err = err
+			break
+		}
+	}
+	return delivery
+}
+
+// recordOutbound writes a delivery to the outbound log and returns the entry. The log is an
+// audit trail, so failing to write it is only logged and does not fail the send.
+func (m *Manager) recordOutbound(ctx context.Context, cfg ChannelConfig, sessionID string, target string, msg Message, delivery outboundDelivery) OutboundRecord {
+	record := OutboundRecord{
+		BotID:           cfg.BotID,
+		ChannelConfigID: cfg.ID,
+		ChannelType:     cfg.ChannelType,
+		SessionID:       strings.TrimSpace(sessionID),
+		Target:          target,
+		MessageIDs:      delivery.messageIDs,
+		Message:         msg,
+		Status:          OutboundStatusSent,
+		Attempts:        delivery.attempts,
+	}
+	if delivery.err != nil {
+		record.Status = OutboundStatusFailed
+		record.LastError = delivery.err.Error()
+	}
+	if m.outboundLog == nil {
+		return record
+	}
+	saved, err := m.outboundLog.RecordOutbound(ctx, record)
+	if err != nil {
+		if m.logger != nil {
+			m.logger.Warn("record outbound failed", slog.String("channel", cfg.ChannelType.String()), slog.String("bot_id", cfg.BotID), slog.Any("error", err))
+		}
+		return record
+	}
+	return saved
 }
 
 func requiresMedia(attachments []Attachment) bool {
@@ -349,21 +415,35 @@ func requiresMedia(attachments []Attachment) bool {
 	return false
 }
 
-func (m *Manager) newReplySender(cfg ChannelConfig, channelType ChannelType) ReplySender {
+func (m *Manager) newReplySender(cfg ChannelConfig, channelType ChannelType, sessionID string) ReplySender {
 	sender, _ := m.registry.GetSender(channelType)
+	editor, _ := m.registry.GetMessageEditor(channelType)
+	presence, _ := m.registry.GetPresenceSender(channelType)
 	return &managerReplySender{
 		manager:     m,
 		sender:      sender,
+		editor:      editor,
+		presence:    presence,
 		channelType: channelType,
 		config:      cfg,
+		sessionID:   sessionID,
+		drafts:      map[string]string{},
 	}
 }
 
 type managerReplySender struct {
 	manager     *Manager
 	sender      Sender
+	editor      MessageEditor
+	presence    PresenceSender
 	channelType ChannelType
 	config      ChannelConfig
+	sessionID   string
+
+	// drafts maps the platform IDs of messages posted with SendEditable to their outbound
+	// log entries, so edits of a streamed reply keep the logged content current.
+	draftsMu sync.Mutex
+	drafts   map[string]string
 }
 
 func (s *managerReplySender) Send(ctx context.Context, msg OutboundMessage) error {
@@ -375,10 +455,126 @@ func (s *managerReplySender) Send(ctx context.Context, msg OutboundMessage) erro
 	if err != nil {
 		return err
 	}
-	for _, item := range outbound {
-		if err := s.manager.sendWithConfig(ctx, s.sender, s.config, item, policy); err != nil {
-			return err
+	delivery := s.manager.deliverOutbound(ctx, s.sender, s.config, outbound, policy)
+	s.manager.recordOutbound(ctx, s.config, s.sessionID, strings.TrimSpace(msg.Target), msg.Message, delivery)
+	return delivery.err
+}
+
+// SendEditable posts a single message without chunking and returns its platform message ID.
+// Callers are expected to keep the message within the channel's text chunk limit.
+func (s *managerReplySender) SendEditable(ctx context.Context, msg OutboundMessage) (string, error) {
+	if s.manager == nil {
+		return "", fmt.Errorf("channel manager not configured")
+	}
+	if s.editor == nil {
+		return "", ErrEditNotSupported
+	}
+	target := strings.TrimSpace(msg.Target)
+	if target == "" {
+		return "", fmt.Errorf("target is required")
+	}
+	if msg.Message.IsEmpty() {
+		return "", fmt.Errorf("message is required")
+	}
+	normalized := normalizeOutboundMessage(msg.Message)
+	if err := validateMessageCapabilities(s.manager.registry, s.channelType, normalized); err != nil {
+		return "", err
+	}
+	messageID, err := s.editor.SendEditable(ctx, s.config, OutboundMessage{Target: target, Message: normalized})
+	delivery := outboundDelivery{attempts: 1, err: err}
+	if err == nil {
+		delivery.messageIDs = []string{messageID}
+	}
+	record := s.manager.recordOutbound(ctx, s.config, s.sessionID, target, normalized, delivery)
+	if err == nil && record.ID != "" {
+		s.draftsMu.Lock()
+		s.drafts[messageID] = record.ID
+		s.draftsMu.Unlock()
+	}
+	return messageID, err
+}
+
+// Edit replaces the content of a message previously posted with SendEditable.
+func (s *managerReplySender) Edit(ctx context.Context, target, messageID string, msg Message) error {
+	if s.manager == nil {
+		return fmt.Errorf("channel manager not configured")
+	}
+	if s.editor == nil {
+		return ErrEditNotSupported
+	}
+	if strings.TrimSpace(messageID) == "" {
+		return fmt.Errorf("message id is required")
+	}
+	if msg.IsEmpty() {
+		return fmt.Errorf("message is required")
+	}
+	normalized := normalizeOutboundMessage(msg)
+	if err := validateMessageCapabilities(s.manager.registry, s.channelType, normalized); err != nil {
+		return err
+	}
+	messageID = strings.TrimSpace(messageID)
+	if err := s.editor.Edit(ctx, s.config, strings.TrimSpace(target), messageID, normalized); err != nil {
+		return err
+	}
+	s.draftsMu.Lock()
+	recordID := s.drafts[messageID]
+	s.draftsMu.Unlock()
+	if recordID != "" && s.manager.outboundLog != nil {
+		if _, err := s.manager.outboundLog.UpdateOutboundStatus(ctx, recordID, OutboundStatusSent, &normalized, ""); err != nil && s.manager.logger != nil {
+			s.manager.logger.Warn("update outbound failed", slog.String("channel", s.channelType.String()), slog.Any("error", err))
 		}
 	}
 	return nil
 }
+
+// StartPresence shows the presence indicator for msg, refreshing it per the outbound policy
+// until stop is called. Presence is best effort, so failures are only logged.
+func (s *managerReplySender) StartPresence(ctx context.Context, msg InboundMessage) func() {
+	if s.manager == nil || s.presence == nil {
+		return func() {}
+	}
+	policy := s.manager.resolveOutboundPolicy(s.channelType)
+	presenceCtx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		presenceID := ""
+		send := func() {
+			id, err := s.presence.SendPresence(presenceCtx, s.config, msg)
+			if err != nil {
+				if presenceCtx.Err() == nil && s.manager.logger != nil {
+					s.manager.logger.Warn("send presence failed", slog.String("channel", s.channelType.String()), slog.Any("error", err))
+				}
+				return
+			}
+			if id != "" {
+				presenceID = id
+			}
+		}
+		send()
+		if policy.PresenceRefreshMs > 0 {
+			ticker := time.NewTicker(time.Duration(policy.PresenceRefreshMs) * time.Millisecond)
+			defer ticker.Stop()
+		loop:
+			for {
+				select {
+				case <-presenceCtx.Done():
+					break loop
+				case <-ticker.C:
+					send()
+				}
+			}
+		} else {
+			<-presenceCtx.Done()
+		}
+		clearCtx, clearCancel := context.WithTimeout(context.WithoutCancel(ctx), 10*time.Second)
+		defer clearCancel()
+		if err := s.presence.ClearPresence(clearCtx, s.config, msg, presenceID); err != nil && s.manager.logger != nil {
+			s.manager.logger.Warn("clear presence failed", slog.String("channel", s.channelType.String()), slog.Any("error", err))
+		}
+	}()
+	return func() {
+		cancel()
+		<-done
+	}
+}
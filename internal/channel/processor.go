@@ -6,3 +6,27 @@ import "context"
 type InboundProcessor interface {
 	HandleInbound(ctx context.Context, cfg ChannelConfig, msg InboundMessage, sender ReplySender) error
 }
+
+// SessionInbox exposes the messages of a session that arrived while one of its messages is
+// being processed. Dispatch holds them back until the processor returns, so the processor
+// may fold them into the message it is handling.
+type SessionInbox interface {
+	// Arrivals is signaled when another message of the session is queued.
+	Arrivals() <-chan struct{}
+	// Drain takes queued messages in arrival order for as long as accept returns true. Taken
+	// messages are not dispatched again once the message being processed is done.
+	Drain(ctx context.Context, accept func(InboundMessage) bool) ([]InboundMessage, error)
+}
+
+type sessionInboxContextKey struct{}
+
+// WithSessionInbox returns a context carrying the inbox of the session being processed.
+func WithSessionInbox(ctx context.Context, inbox SessionInbox) context.Context {
+	return context.WithValue(ctx, sessionInboxContextKey{}, inbox)
+}
+
+// SessionInboxFromContext returns the inbox of the session being processed, if any.
+func SessionInboxFromContext(ctx context.Context) (SessionInbox, bool) {
+	inbox, ok := ctx.Value(sessionInboxContextKey{}).(SessionInbox)
+	return inbox, ok && inbox != nil
+}
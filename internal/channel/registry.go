@@ -195,6 +195,80 @@ func (r *Registry) GetReceiver(channelType ChannelType) (Receiver, bool) {
 	return receiver, ok
 }
 
+// GetWebhookReceiver returns the WebhookReceiver for the given channel type, or nil if unsupported.
+func (r *Registry) GetWebhookReceiver(channelType ChannelType) (WebhookReceiver, bool) {
+	adapter, ok := r.Get(channelType)
+	if !ok {
+		return nil, false
+	}
+	receiver, ok := adapter.(WebhookReceiver)
+	return receiver, ok
+}
+
+// GetMessageEditor returns the MessageEditor for the given channel type, or nil if unsupported.
+func (r *Registry) GetMessageEditor(channelType ChannelType) (MessageEditor, bool) {
+	adapter, ok := r.Get(channelType)
+	if !ok {
+		return nil, false
+	}
+	editor, ok := adapter.(MessageEditor)
+	return editor, ok
+}
+
+// GetMessageUnsender returns the MessageUnsender for the given channel type, or nil if unsupported.
+func (r *Registry) GetMessageUnsender(channelType ChannelType) (MessageUnsender, bool) {
+	adapter, ok := r.Get(channelType)
+	if !ok {
+		return nil, false
+	}
+	unsender, ok := adapter.(MessageUnsender)
+	return unsender, ok
+}
+
+// GetPresenceSender returns the PresenceSender for the given channel type, or nil if unsupported.
+func (r *Registry) GetPresenceSender(channelType ChannelType) (PresenceSender, bool) {
+	adapter, ok := r.Get(channelType)
+	if !ok {
+		return nil, false
+	}
+	presence, ok := adapter.(PresenceSender)
+	return presence, ok
+}
+
+// GetDirectory returns the ChannelDirectoryAdapter for the given channel type, or nil if unsupported.
+func (r *Registry) GetDirectory(channelType ChannelType) (ChannelDirectoryAdapter, bool) {
+	adapter, ok := r.Get(channelType)
+	if !ok {
+		return nil, false
+	}
+	provider, ok := adapter.(DirectoryProvider)
+	if !ok {
+		return nil, false
+	}
+	directory := provider.Directory()
+	return directory, directory != nil
+}
+
+// GetAttachmentDownloader returns the AttachmentDownloader for the given channel type, or nil if unsupported.
+func (r *Registry) GetAttachmentDownloader(channelType ChannelType) (AttachmentDownloader, bool) {
+	adapter, ok := r.Get(channelType)
+	if !ok {
+		return nil, false
+	}
+	downloader, ok := adapter.(AttachmentDownloader)
+	return downloader, ok
+}
+
+// GetCommandRegistrar returns the CommandRegistrar for the given channel type, or nil if unsupported.
+func (r *Registry) GetCommandRegistrar(channelType ChannelType) (CommandRegistrar, bool) {
+	adapter, ok := r.Get(channelType)
+	if !ok {
+		return nil, false
+	}
+	registrar, ok := adapter.(CommandRegistrar)
+	return registrar, ok
+}
+
 // --- Dispatch methods (replace former global functions in config.go / target.go) ---
 
 // NormalizeConfig validates and normalizes a channel configuration map.
@@ -9,6 +9,7 @@ const (
 	FieldBool   FieldType = "bool"
 	FieldNumber FieldType = "number"
 	FieldEnum   FieldType = "enum"
+	FieldMap    FieldType = "map"
 )
 
 // FieldSchema describes a single configuration field.
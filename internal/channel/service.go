@@ -61,6 +61,9 @@ func (s *Service) UpsertConfig(ctx context.Context, botID string, channelType Ch
 	if routing == nil {
 		routing = map[string]any{}
 	}
+	if err := ValidateRouting(routing); err != nil {
+		return ChannelConfig{}, err
+	}
 	routingPayload, err := json.Marshal(routing)
 	if err != nil {
 		return ChannelConfig{}, err
@@ -364,6 +367,364 @@ func (s *Service) ResolveUserBinding(ctx context.Context, channelType ChannelTyp
 	return "", fmt.Errorf("channel user binding not found")
 }
 
+// EnqueueInbound persists an inbound message in the durable inbound queue. Messages with the
+// same session key are claimed one at a time; it defaults to the channel session.
+func (s *Service) EnqueueInbound(ctx context.Context, cfg ChannelConfig, msg InboundMessage, sessionKey string) (QueuedInbound, error) {
+	if s.queries == nil {
+		return QueuedInbound{}, fmt.Errorf("channel queries not configured")
+	}
+	botID := strings.TrimSpace(msg.BotID)
+	if botID == "" {
+		botID = strings.TrimSpace(cfg.BotID)
+	}
+	botUUID, err := db.ParseUUID(botID)
+	if err != nil {
+		return QueuedInbound{}, err
+	}
+	keyed := msg
+	keyed.BotID = botID
+	payload, err := json.Marshal(keyed)
+	if err != nil {
+		return QueuedInbound{}, err
+	}
+	if strings.TrimSpace(sessionKey) == "" {
+		sessionKey = keyed.SessionID()
+	}
+	row, err := s.queries.EnqueueInboundMessage(ctx, sqlc.EnqueueInboundMessageParams{
+		BotID:           botUUID,
+		ChannelConfigID: strings.TrimSpace(cfg.ID),
+		ChannelType:     msg.Channel.String(),
+		SessionKey:      sessionKey,
+		Payload:         payload,
+	})
+	if err != nil {
+		return QueuedInbound{}, err
+	}
+	return normalizeQueuedInbound(row)
+}
+
+// ClaimInbound marks up to limit queued messages as processing and returns them.
+// At most one message per session is claimed, and only once the earlier ones are done.
+func (s *Service) ClaimInbound(ctx context.Context, limit int) ([]QueuedInbound, error) {
+	if s.queries == nil {
+		return nil, fmt.Errorf("channel queries not configured")
+	}
+	if limit <= 0 {
+		return nil, nil
+	}
+	rows, err := s.queries.ClaimInboundMessages(ctx, int32(limit))
+	if err != nil {
+		return nil, err
+	}
+	return normalizeQueuedInboundRows(rows)
+}
+
+// ListSessionInbound returns the pending messages of a session in arrival order.
+func (s *Service) ListSessionInbound(ctx context.Context, sessionKey string) ([]QueuedInbound, error) {
+	if s.queries == nil {
+		return nil, fmt.Errorf("channel queries not configured")
+	}
+	rows, err := s.queries.ListSessionPendingInbound(ctx, sqlc.ListSessionPendingInboundParams{
+		SessionKey: sessionKey,
+		MaxItems:   100,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return normalizeQueuedInboundRows(rows)
+}
+
+// CompleteInbound removes a processed message from the inbound queue.
+func (s *Service) CompleteInbound(ctx context.Context, id string) error {
+	if s.queries == nil {
+		return fmt.Errorf("channel queries not configured")
+	}
+	pgID, err := db.ParseUUID(id)
+	if err != nil {
+		return err
+	}
+	return s.queries.DeleteInboundMessage(ctx, pgID)
+}
+
+// RetryInbound returns a failed message to the queue to be retried at availableAt.
+func (s *Service) RetryInbound(ctx context.Context, id string, availableAt time.Time, reason string) error {
+	if s.queries == nil {
+		return fmt.Errorf("channel queries not configured")
+	}
+	pgID, err := db.ParseUUID(id)
+	if err != nil {
+		return err
+	}
+	return s.queries.RetryInboundMessage(ctx, sqlc.RetryInboundMessageParams{
+		LastError:   pgtype.Text{String: reason, Valid: true},
+		AvailableAt: pgtype.Timestamptz{Time: availableAt.UTC(), Valid: true},
+		ID:          pgID,
+	})
+}
+
+// DeadLetterInbound moves a message that keeps failing to the dead-letter state.
+func (s *Service) DeadLetterInbound(ctx context.Context, id string, reason string) error {
+	if s.queries == nil {
+		return fmt.Errorf("channel queries not configured")
+	}
+	pgID, err := db.ParseUUID(id)
+	if err != nil {
+		return err
+	}
+	return s.queries.DeadLetterInboundMessage(ctx, sqlc.DeadLetterInboundMessageParams{
+		LastError: pgtype.Text{String: reason, Valid: true},
+		ID:        pgID,
+	})
+}
+
+// ReleaseStaleInbound returns messages claimed before lockedBefore to the pending state.
+func (s *Service) ReleaseStaleInbound(ctx context.Context, lockedBefore time.Time) (int64, error) {
+	if s.queries == nil {
+		return 0, fmt.Errorf("channel queries not configured")
+	}
+	return s.queries.ReleaseStaleInboundMessages(ctx, pgtype.Timestamptz{Time: lockedBefore.UTC(), Valid: true})
+}
+
+// ListInbound returns queued inbound messages, optionally filtered by status and bot.
+func (s *Service) ListInbound(ctx context.Context, status, botID string, limit int) ([]QueuedInbound, error) {
+	if s.queries == nil {
+		return nil, fmt.Errorf("channel queries not configured")
+	}
+	status = strings.TrimSpace(status)
+	switch status {
+	case "", InboundStatusPending, InboundStatusProcessing, InboundStatusDead:
+	default:
+		return nil, fmt.Errorf("invalid inbound status: %s", status)
+	}
+	var botUUID pgtype.UUID
+	if strings.TrimSpace(botID) != "" {
+		parsed, err := db.ParseUUID(botID)
+		if err != nil {
+			return nil, err
+		}
+		botUUID = parsed
+	}
+	if limit <= 0 || limit > 500 {
+		limit = 100
+	}
+	rows, err := s.queries.ListInboundMessages(ctx, sqlc.ListInboundMessagesParams{
+		Status:   status,
+		BotID:    botUUID,
+		MaxItems: int32(limit),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return normalizeQueuedInboundRows(rows)
+}
+
+// ReplayInbound resets a dead or waiting message so it is processed again right away.
+func (s *Service) ReplayInbound(ctx context.Context, id string) (QueuedInbound, error) {
+	if s.queries == nil {
+		return QueuedInbound{}, fmt.Errorf("channel queries not configured")
+	}
+	pgID, err := db.ParseUUID(id)
+	if err != nil {
+		return QueuedInbound{}, err
+	}
+	row, err := s.queries.ReplayInboundMessage(ctx, pgID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return QueuedInbound{}, fmt.Errorf("inbound message not found or in progress")
+		}
+		return QueuedInbound{}, err
+	}
+	return normalizeQueuedInbound(row)
+}
+
+// RecordOutbound appends a delivery to the outbound message log.
+func (s *Service) RecordOutbound(ctx context.Context, record OutboundRecord) (OutboundRecord, error) {
+	if s.queries == nil {
+		return OutboundRecord{}, fmt.Errorf("channel queries not configured")
+	}
+	botUUID, err := db.ParseUUID(record.BotID)
+	if err != nil {
+		return OutboundRecord{}, err
+	}
+	payload, err := json.Marshal(record.Message)
+	if err != nil {
+		return OutboundRecord{}, err
+	}
+	messageIDs := record.MessageIDs
+	if messageIDs == nil {
+		messageIDs = []string{}
+	}
+	lastError := pgtype.Text{}
+	if strings.TrimSpace(record.LastError) != "" {
+		lastError = pgtype.Text{String: record.LastError, Valid: true}
+	}
+	row, err := s.queries.CreateOutboundMessage(ctx, sqlc.CreateOutboundMessageParams{
+		BotID:              botUUID,
+		ChannelConfigID:    strings.TrimSpace(record.ChannelConfigID),
+		ChannelType:        record.ChannelType.String(),
+		SessionID:          strings.TrimSpace(record.SessionID),
+		Target:             strings.TrimSpace(record.Target),
+		PlatformMessageIds: messageIDs,
+		Payload:            payload,
+		Status:             record.Status,
+		Attempts:           int32(record.Attempts),
+		LastError:          lastError,
+	})
+	if err != nil {
+		return OutboundRecord{}, err
+	}
+	return normalizeOutboundRecord(row)
+}
+
+// GetOutbound returns an outbound message log entry.
+func (s *Service) GetOutbound(ctx context.Context, id string) (OutboundRecord, error) {
+	if s.queries == nil {
+		return OutboundRecord{}, fmt.Errorf("channel queries not configured")
+	}
+	pgID, err := db.ParseUUID(id)
+	if err != nil {
+		return OutboundRecord{}, err
+	}
+	row, err := s.queries.GetOutboundMessage(ctx, pgID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return OutboundRecord{}, ErrOutboundNotFound
+		}
+		return OutboundRecord{}, err
+	}
+	return normalizeOutboundRecord(row)
+}
+
+// UpdateOutboundStatus sets the status of an outbound log entry. A non-nil msg replaces the
+// logged content, as after an edit.
+func (s *Service) UpdateOutboundStatus(ctx context.Context, id string, status string, msg *Message, reason string) (OutboundRecord, error) {
+	if s.queries == nil {
+		return OutboundRecord{}, fmt.Errorf("channel queries not configured")
+	}
+	pgID, err := db.ParseUUID(id)
+	if err != nil {
+		return OutboundRecord{}, err
+	}
+	var payload []byte
+	if msg != nil {
+		payload, err = json.Marshal(msg)
+		if err != nil {
+			return OutboundRecord{}, err
+		}
+	}
+	lastError := pgtype.Text{}
+	if strings.TrimSpace(reason) != "" {
+		lastError = pgtype.Text{String: reason, Valid: true}
+	}
+	row, err := s.queries.UpdateOutboundMessageStatus(ctx, sqlc.UpdateOutboundMessageStatusParams{
+		Status:    status,
+		Payload:   payload,
+		LastError: lastError,
+		ID:        pgID,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return OutboundRecord{}, ErrOutboundNotFound
+		}
+		return OutboundRecord{}, err
+	}
+	return normalizeOutboundRecord(row)
+}
+
+// ListOutbound returns the most recent outbound messages of a bot on a channel, optionally
+// filtered by session and status.
+func (s *Service) ListOutbound(ctx context.Context, botID string, channelType ChannelType, sessionID, status string, limit int) ([]OutboundRecord, error) {
+	if s.queries == nil {
+		return nil, fmt.Errorf("channel queries not configured")
+	}
+	status = strings.TrimSpace(status)
+	switch status {
+	case "", OutboundStatusSent, OutboundStatusFailed, OutboundStatusEdited, OutboundStatusDeleted:
+	default:
+		return nil, fmt.Errorf("invalid outbound status: %s", status)
+	}
+	botUUID, err := db.ParseUUID(botID)
+	if err != nil {
+		return nil, err
+	}
+	if limit <= 0 {
+		limit = 100
+	}
+	if limit > 500 {
+		limit = 500
+	}
+	rows, err := s.queries.ListOutboundMessages(ctx, sqlc.ListOutboundMessagesParams{
+		BotID:       botUUID,
+		ChannelType: channelType.String(),
+		SessionID:   strings.TrimSpace(sessionID),
+		Status:      status,
+		MaxItems:    int32(limit),
+	})
+	if err != nil {
+		return nil, err
+	}
+	items := make([]OutboundRecord, 0, len(rows))
+	for _, row := range rows {
+		item, err := normalizeOutboundRecord(row)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+// RecordConnectionEvent appends a lifecycle event to the connection event log.
+func (s *Service) RecordConnectionEvent(ctx context.Context, event ConnectionEvent) error {
+	if s.queries == nil {
+		return fmt.Errorf("channel queries not configured")
+	}
+	botUUID, err := db.ParseUUID(event.BotID)
+	if err != nil {
+		return err
+	}
+	return s.queries.CreateConnectionEvent(ctx, sqlc.CreateConnectionEventParams{
+		BotID:           botUUID,
+		ChannelConfigID: event.ChannelConfigID,
+		ChannelType:     event.ChannelType.String(),
+		EventType:       event.EventType,
+		Message:         event.Message,
+	})
+}
+
+// ListConnectionEvents returns the most recent lifecycle events of a channel config.
+func (s *Service) ListConnectionEvents(ctx context.Context, configID string, limit int) ([]ConnectionEvent, error) {
+	if s.queries == nil {
+		return nil, fmt.Errorf("channel queries not configured")
+	}
+	if limit <= 0 {
+		limit = 20
+	}
+	if limit > 500 {
+		limit = 500
+	}
+	rows, err := s.queries.ListConnectionEvents(ctx, sqlc.ListConnectionEventsParams{
+		ChannelConfigID: strings.TrimSpace(configID),
+		MaxItems:        int32(limit),
+	})
+	if err != nil {
+		return nil, err
+	}
+	items := make([]ConnectionEvent, 0, len(rows))
+	for _, row := range rows {
+		items = append(items, ConnectionEvent{
+			ID:              db.UUIDToString(row.ID),
+			BotID:           db.UUIDToString(row.BotID),
+			ChannelConfigID: row.ChannelConfigID,
+			ChannelType:     ChannelType(row.ChannelType),
+			EventType:       row.EventType,
+			Message:         row.Message,
+			CreatedAt:       db.TimeFromPg(row.CreatedAt),
+		})
+	}
+	return items, nil
+}
+
 func normalizeChannelConfig(row sqlc.BotChannelConfig) (ChannelConfig, error) {
 	credentials, err := DecodeConfigMap(row.Credentials)
 	if err != nil {
@@ -435,5 +796,61 @@ func normalizeChannelSession(row sqlc.ChannelSession) (ChannelSession, error) {
 	}, nil
 }
 
+func normalizeQueuedInbound(row sqlc.ChannelInboundMessage) (QueuedInbound, error) {
+	var msg InboundMessage
+	if err := json.Unmarshal(row.Payload, &msg); err != nil {
+		return QueuedInbound{}, fmt.Errorf("decode inbound message: %w", err)
+	}
+	return QueuedInbound{
+		ID:              db.UUIDToString(row.ID),
+		BotID:           db.UUIDToString(row.BotID),
+		ChannelConfigID: row.ChannelConfigID,
+		ChannelType:     ChannelType(row.ChannelType),
+		SessionKey:      row.SessionKey,
+		Message:         msg,
+		Status:          row.Status,
+		Attempts:        int(row.Attempts),
+		LastError:       strings.TrimSpace(row.LastError.String),
+		AvailableAt:     db.TimeFromPg(row.AvailableAt),
+		CreatedAt:       db.TimeFromPg(row.CreatedAt),
+		UpdatedAt:       db.TimeFromPg(row.UpdatedAt),
+	}, nil
+}
 
+func normalizeQueuedInboundRows(rows []sqlc.ChannelInboundMessage) ([]QueuedInbound, error) {
+	items := make([]QueuedInbound, 0, len(rows))
+	for _, row := range rows {
+		item, err := normalizeQueuedInbound(row)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
 
+func normalizeOutboundRecord(row sqlc.ChannelOutboundMessage) (OutboundRecord, error) {
+	var msg Message
+	if err := json.Unmarshal(row.Payload, &msg); err != nil {
+		return OutboundRecord{}, fmt.Errorf("decode outbound message: %w", err)
+	}
+	messageIDs := row.PlatformMessageIds
+	if messageIDs == nil {
+		messageIDs = []string{}
+	}
+	return OutboundRecord{
+		ID:              db.UUIDToString(row.ID),
+		BotID:           db.UUIDToString(row.BotID),
+		ChannelConfigID: row.ChannelConfigID,
+		ChannelType:     ChannelType(row.ChannelType),
+		SessionID:       row.SessionID,
+		Target:          row.Target,
+		MessageIDs:      messageIDs,
+		Message:         msg,
+		Status:          row.Status,
+		Attempts:        int(row.Attempts),
+		LastError:       strings.TrimSpace(row.LastError.String),
+		CreatedAt:       db.TimeFromPg(row.CreatedAt),
+		UpdatedAt:       db.TimeFromPg(row.UpdatedAt),
+	}, nil
+}
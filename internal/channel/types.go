@@ -51,6 +51,16 @@ type InboundMessage struct {
 	ReceivedAt   time.Time
 	Source       string
 	Metadata     map[string]any
+	// Action is set when the user pressed a button on a message the bot sent earlier.
+	Action *InboundAction
+}
+
+// InboundAction is a button press reported by a channel. Value is the Action.Value of the
+// pressed button.
+type InboundAction struct {
+	Value     string `json:"value"`
+	Label     string `json:"label,omitempty"`
+	MessageID string `json:"message_id,omitempty"`
 }
 
 // SessionID returns a stable identifier for the conversation session.
@@ -302,9 +312,129 @@ type ChannelSession struct {
 	UpdatedAt       time.Time
 }
 
+// Inbound queue states. Processed messages are removed from the queue.
+const (
+	InboundStatusPending    = "pending"
+	InboundStatusProcessing = "processing"
+	InboundStatusDead       = "dead"
+)
+
+// QueuedInbound is an inbound message held in the durable inbound queue.
+type QueuedInbound struct {
+	ID              string         `json:"id"`
+	BotID           string         `json:"bot_id"`
+	ChannelConfigID string         `json:"channel_config_id"`
+	ChannelType     ChannelType    `json:"channel_type"`
+	SessionKey      string         `json:"session_key"`
+	Message         InboundMessage `json:"message"`
+	Status          string         `json:"status"`
+	Attempts        int            `json:"attempts"`
+	LastError       string         `json:"last_error,omitempty"`
+	AvailableAt     time.Time      `json:"available_at"`
+	CreatedAt       time.Time      `json:"created_at"`
+	UpdatedAt       time.Time      `json:"updated_at"`
+}
+
+// InboundStats reports the inbound backlog of a bot as seen by this server since it started.
+// Waiting counts messages received but not yet handed to the processor, including those held
+// behind an earlier message of the same session; wait times run from receipt to hand-off.
+type InboundStats struct {
+	BotID          string  `json:"bot_id"`
+	Waiting        int64   `json:"waiting"`
+	ActiveSessions int     `json:"active_sessions"`
+	Dispatched     int64   `json:"dispatched"`
+	AvgWaitMs      float64 `json:"avg_wait_ms"`
+	MaxWaitMs      int64   `json:"max_wait_ms"`
+	LastWaitMs     int64   `json:"last_wait_ms"`
+}
+
+// Outbound log states. Failed messages exhausted their retries; edited and deleted messages
+// were changed through the channel after delivery.
+const (
+	OutboundStatusSent    = "sent"
+	OutboundStatusFailed  = "failed"
+	OutboundStatusEdited  = "edited"
+	OutboundStatusDeleted = "deleted"
+)
+
+// OutboundRecord is an entry in the outbound message log. MessageIDs holds the platform IDs
+// of every message the send was delivered as, such as text chunks and attachments.
+type OutboundRecord struct {
+	ID              string      `json:"id"`
+	BotID           string      `json:"bot_id"`
+	ChannelConfigID string      `json:"channel_config_id"`
+	ChannelType     ChannelType `json:"channel_type"`
+	SessionID       string      `json:"session_id,omitempty"`
+	Target          string      `json:"target"`
+	MessageIDs      []string    `json:"message_ids"`
+	Message         Message     `json:"message"`
+	Status          string      `json:"status"`
+	Attempts        int         `json:"attempts"`
+	LastError       string      `json:"last_error,omitempty"`
+	CreatedAt       time.Time   `json:"created_at"`
+	UpdatedAt       time.Time   `json:"updated_at"`
+}
+
+// Connection states. A connection in backoff failed to connect or dropped and is retried
+// once NextRetryAt has passed; send-only channels receive nothing and are never connected.
+const (
+	ConnectionStateConnected    = "connected"
+	ConnectionStateBackoff      = "backoff"
+	ConnectionStateStopped      = "stopped"
+	ConnectionStateDisconnected = "disconnected"
+	ConnectionStateSendOnly     = "send_only"
+)
+
+// Connection lifecycle events recorded in the connection event log.
+const (
+	ConnectionEventConnected = "connected"
+	ConnectionEventFailed    = "failed"
+	ConnectionEventDropped   = "dropped"
+	ConnectionEventStopped   = "stopped"
+)
+
+// ConnectionStatus is the health of the connection of a channel config as tracked by the
+// manager since the server started.
+type ConnectionStatus struct {
+	ConfigID            string      `json:"config_id"`
+	BotID               string      `json:"bot_id"`
+	ChannelType         ChannelType `json:"channel_type"`
+	State               string      `json:"state"`
+	Connected           bool        `json:"connected"`
+	LastError           string      `json:"last_error,omitempty"`
+	LastErrorAt         *time.Time  `json:"last_error_at,omitempty"`
+	ConnectedAt         *time.Time  `json:"connected_at,omitempty"`
+	LastInboundAt       *time.Time  `json:"last_inbound_at,omitempty"`
+	LastOutboundAt      *time.Time  `json:"last_outbound_at,omitempty"`
+	RestartCount        int         `json:"restart_count"`
+	ConsecutiveFailures int         `json:"consecutive_failures"`
+	NextRetryAt         *time.Time  `json:"next_retry_at,omitempty"`
+}
+
+// ConnectionEvent is a persisted connection lifecycle event of a channel config.
+type ConnectionEvent struct {
+	ID              string      `json:"id"`
+	BotID           string      `json:"bot_id"`
+	ChannelConfigID string      `json:"channel_config_id"`
+	ChannelType     ChannelType `json:"channel_type"`
+	EventType       string      `json:"event_type"`
+	Message         string      `json:"message,omitempty"`
+	CreatedAt       time.Time   `json:"created_at"`
+}
+
 // SendRequest is the input for sending an outbound message through a channel.
+// To is a human-friendly recipient, such as a contact or group name, that is resolved
+// through the channel directory when neither Target nor UserID is set. SessionID only
+// tags the outbound log entry with the conversation the message belongs to.
 type SendRequest struct {
-	Target  string  `json:"target,omitempty"`
-	UserID  string  `json:"user_id,omitempty"`
+	Target    string  `json:"target,omitempty"`
+	UserID    string  `json:"user_id,omitempty"`
+	To        string  `json:"to,omitempty"`
+	SessionID string  `json:"session_id,omitempty"`
+	Message   Message `json:"message"`
+}
+
+// EditMessageRequest replaces the content of a delivered outbound message.
+type EditMessageRequest struct {
 	Message Message `json:"message"`
 }
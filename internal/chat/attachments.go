@@ -0,0 +1,108 @@
+package chat
+
+import (
+	"context"
+	"encoding/base64"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/memohai/memoh/internal/embeddings"
+	"github.com/memohai/memoh/internal/memory"
+	"github.com/memohai/memoh/internal/models"
+)
+
+// prepareAttachments converts inbound attachments into gateway attachments. Every attachment
+// is copied into the bot container so the agent can open it with its tools; images and audio
+// are also passed inline when the chat model accepts that input.
+func (r *Resolver) prepareAttachments(ctx context.Context, req ChatRequest, chatModel models.GetResponse) []gatewayAttachment {
+	result := []gatewayAttachment{}
+	for _, att := range req.Attachments {
+		if len(att.Data) == 0 {
+			continue
+		}
+		mime := attachmentMime(att)
+		metadata := map[string]any{"type": att.Type, "mime": mime}
+		if name := strings.TrimSpace(att.Name); name != "" {
+			metadata["name"] = name
+		}
+		if caption := strings.TrimSpace(att.Caption); caption != "" {
+			metadata["caption"] = caption
+		}
+
+		path := ""
+		if r.attachmentStore != nil {
+			saved, err := r.attachmentStore.SaveAttachment(ctx, req.BotID, att.Name, att.Data)
+			if err != nil {
+				r.logger.Warn("save attachment failed", slog.String("bot_id", req.BotID), slog.Any("error", err))
+			} else {
+				path = saved
+				result = append(result, gatewayAttachment{Type: "file", Path: path, Metadata: metadata})
+			}
+		}
+
+		encoded := base64.StdEncoding.EncodeToString(att.Data)
+		image := isImageAttachment(att.Type, mime)
+		switch {
+		case image && slices.Contains(chatModel.Input, models.ModelInputImage):
+			result = append(result, gatewayAttachment{Type: "image", Base64: encoded, MediaType: mime, Metadata: metadata})
+		case isAudioAttachment(att.Type, mime) && slices.Contains(chatModel.Input, models.ModelInputAudio):
+			result = append(result, gatewayAttachment{Type: "audio", Base64: encoded, MediaType: mime, Metadata: metadata})
+		}
+
+		if image && r.memoryService != nil && r.memoryService.MultimodalEnabled() {
+			go r.embedAttachment(context.WithoutCancel(ctx), req, att, "data:"+mime+";base64,"+encoded, path)
+		}
+	}
+	return result
+}
+
+// embedAttachment stores an inbound image in memory using the multimodal embedding model.
+func (r *Resolver) embedAttachment(ctx context.Context, req ChatRequest, att Attachment, imageURL, path string) {
+	metadata := map[string]any{"platform": req.CurrentChannel}
+	if name := strings.TrimSpace(att.Name); name != "" {
+		metadata["name"] = name
+	}
+	if path != "" {
+		metadata["path"] = path
+	}
+	if _, err := r.memoryService.EmbedUpsert(ctx, memory.EmbedUpsertRequest{
+		Type: embeddings.TypeMultimodal,
+		Input: memory.EmbedInput{
+			Text:     strings.TrimSpace(att.Caption),
+			ImageURL: imageURL,
+		},
+		Source:    "channel_attachment",
+		BotID:     req.BotID,
+		SessionID: req.SessionID,
+		ContactID: req.ContactID,
+		Scope:     r.memoryScope(ctx, req.BotID),
+		Metadata:  metadata,
+	}); err != nil {
+		r.logger.Warn("embed attachment failed", slog.String("bot_id", req.BotID), slog.Any("error", err))
+	}
+}
+
+func attachmentMime(att Attachment) string {
+	mime := strings.ToLower(strings.TrimSpace(att.Mime))
+	if idx := strings.Index(mime, ";"); idx >= 0 {
+		mime = strings.TrimSpace(mime[:idx])
+	}
+	if mime == "" || mime == "application/octet-stream" {
+		detected := http.DetectContentType(att.Data)
+		if idx := strings.Index(detected, ";"); idx >= 0 {
+			detected = detected[:idx]
+		}
+		mime = detected
+	}
+	return mime
+}
+
+func isImageAttachment(attType, mime string) bool {
+	return attType == "image" || strings.HasPrefix(mime, "image/")
+}
+
+func isAudioAttachment(attType, mime string) bool {
+	return attType == "audio" || attType == "voice" || strings.HasPrefix(mime, "audio/")
+}
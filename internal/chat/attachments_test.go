@@ -0,0 +1,68 @@
+package chat
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/memohai/memoh/internal/models"
+)
+
+type fakeAttachmentStore struct {
+	saved []string
+}
+
+func (s *fakeAttachmentStore) SaveAttachment(ctx context.Context, botID, name string, data []byte) (string, error) {
+	s.saved = append(s.saved, name)
+	return "/data/attachments/" + name, nil
+}
+
+func TestPrepareAttachments(t *testing.T) {
+	store := &fakeAttachmentStore{}
+	resolver := NewResolver(slog.Default(), nil, nil, nil, nil, nil, nil, "", time.Second)
+	resolver.SetAttachmentStore(store)
+
+	req := ChatRequest{
+		BotID: "bot-1",
+		Attachments: []Attachment{
+			{Type: "image", Name: "cat.png", Mime: "image/png", Data: []byte("png-bytes")},
+			{Type: "voice", Name: "note.ogg", Mime: "audio/ogg", Data: []byte("ogg-bytes")},
+			{Type: "file", Name: "empty.txt"},
+		},
+	}
+	model := models.GetResponse{Model: models.Model{Input: []string{models.ModelInputText, models.ModelInputImage}}}
+
+	got := resolver.prepareAttachments(context.Background(), req, model)
+	if len(store.saved) != 2 {
+		t.Fatalf("expected 2 saved attachments, got %v", store.saved)
+	}
+	if len(got) != 3 {
+		t.Fatalf("expected 3 gateway attachments, got %+v", got)
+	}
+	if got[0].Type != "file" || got[0].Path != "/data/attachments/cat.png" {
+		t.Errorf("unexpected file attachment: %+v", got[0])
+	}
+	if got[1].Type != "image" || got[1].MediaType != "image/png" || got[1].Base64 != "cG5nLWJ5dGVz" {
+		t.Errorf("unexpected image attachment: %+v", got[1])
+	}
+	if got[2].Type != "file" || got[2].Path != "/data/attachments/note.ogg" {
+		t.Errorf("audio should only be passed as a file without audio input: %+v", got[2])
+	}
+
+	model.Input = append(model.Input, models.ModelInputAudio)
+	got = resolver.prepareAttachments(context.Background(), req, model)
+	if len(got) != 4 || got[3].Type != "audio" || got[3].MediaType != "audio/ogg" {
+		t.Fatalf("expected inline audio attachment, got %+v", got)
+	}
+}
+
+func TestAttachmentMimeDetection(t *testing.T) {
+	png := []byte("\x89PNG\r\n\x1a\n0000")
+	if got := attachmentMime(Attachment{Data: png}); got != "image/png" {
+		t.Fatalf("expected detected image/png, got %s", got)
+	}
+	if got := attachmentMime(Attachment{Mime: "Audio/OGG; codecs=opus", Data: png}); got != "audio/ogg" {
+		t.Fatalf("expected declared mime, got %s", got)
+	}
+}
@@ -9,6 +9,7 @@ import (
 	"io"
 	"log/slog"
 	"net/http"
+	"sort"
 	"strings"
 	"time"
 
@@ -38,6 +39,11 @@ type SkillLoader interface {
 	LoadSkills(ctx context.Context, botID string) ([]SkillEntry, error)
 }
 
+// AttachmentStore copies inbound attachments into a bot's container data directory.
+type AttachmentStore interface {
+	SaveAttachment(ctx context.Context, botID, name string, data []byte) (string, error)
+}
+
 // Resolver orchestrates chat with the agent gateway.
 type Resolver struct {
 	modelsService   *models.Service
@@ -47,6 +53,7 @@ type Resolver struct {
 	settingsService *settings.Service
 	mcpService      *mcp.ConnectionService
 	skillLoader     SkillLoader
+	attachmentStore AttachmentStore
 	gatewayBaseURL  string
 	timeout         time.Duration
 	logger          *slog.Logger
@@ -93,6 +100,11 @@ func (r *Resolver) SetSkillLoader(sl SkillLoader) {
 	r.skillLoader = sl
 }
 
+// SetAttachmentStore sets the store that makes inbound attachments available inside the bot container.
+func (r *Resolver) SetAttachmentStore(store AttachmentStore) {
+	r.attachmentStore = store
+}
+
 // --- gateway payload ---
 
 type gatewayModelConfig struct {
@@ -123,24 +135,49 @@ type gatewaySkill struct {
 	Metadata    map[string]any `json:"metadata,omitempty"`
 }
 
+// gatewayAttachment matches the agent gateway AttachmentModel.
+type gatewayAttachment struct {
+	Type      string         `json:"type"`
+	Base64    string         `json:"base64,omitempty"`
+	MediaType string         `json:"mediaType,omitempty"`
+	Path      string         `json:"path,omitempty"`
+	Metadata  map[string]any `json:"metadata,omitempty"`
+}
+
 type gatewayRequest struct {
-	Model             gatewayModelConfig `json:"model"`
-	ActiveContextTime int                `json:"activeContextTime"`
-	Channels          []string           `json:"channels"`
-	CurrentChannel    string             `json:"currentChannel"`
-	AllowedActions    []string           `json:"allowedActions,omitempty"`
-	MCPConnections    []map[string]any   `json:"mcpConnections"`
-	Messages          []ModelMessage     `json:"messages"`
-	Skills            []string           `json:"skills"`
-	UsableSkills      []gatewaySkill     `json:"usableSkills"`
-	Query             string             `json:"query"`
-	Identity          gatewayIdentity    `json:"identity"`
-	Attachments       []any              `json:"attachments"`
+	Model             gatewayModelConfig  `json:"model"`
+	ActiveContextTime int                 `json:"activeContextTime"`
+	Channels          []string            `json:"channels"`
+	CurrentChannel    string              `json:"currentChannel"`
+	AllowedActions    []string            `json:"allowedActions,omitempty"`
+	MCPConnections    []map[string]any    `json:"mcpConnections"`
+	Messages          []ModelMessage      `json:"messages"`
+	Skills            []string            `json:"skills"`
+	UsableSkills      []gatewaySkill      `json:"usableSkills"`
+	Query             string              `json:"query"`
+	Identity          gatewayIdentity     `json:"identity"`
+	Attachments       []gatewayAttachment `json:"attachments"`
 }
 
 type gatewayResponse struct {
 	Messages []ModelMessage `json:"messages"`
 	Skills   []string       `json:"skills"`
+	Usage    *GatewayUsage  `json:"usage"`
+}
+
+// GatewayUsage is the token usage object of the agent gateway (AI SDK LanguageModelUsage).
+type GatewayUsage struct {
+	InputTokens  int64 `json:"inputTokens"`
+	OutputTokens int64 `json:"outputTokens"`
+	TotalTokens  int64 `json:"totalTokens"`
+}
+
+// ToUsage converts the gateway usage to a Usage, or nil when none was reported.
+func (u *GatewayUsage) ToUsage() *Usage {
+	if u == nil {
+		return nil
+	}
+	return &Usage{InputTokens: u.InputTokens, OutputTokens: u.OutputTokens, TotalTokens: u.TotalTokens}
 }
 
 // gatewaySchedule matches the agent gateway ScheduleModel for /chat/trigger-schedule.
@@ -155,18 +192,18 @@ type gatewaySchedule struct {
 
 // triggerScheduleRequest is the payload for POST /chat/trigger-schedule.
 type triggerScheduleRequest struct {
-	Model             gatewayModelConfig `json:"model"`
-	ActiveContextTime int                `json:"activeContextTime"`
-	Channels          []string           `json:"channels"`
-	CurrentChannel    string             `json:"currentChannel"`
-	AllowedActions    []string           `json:"allowedActions,omitempty"`
-	MCPConnections    []map[string]any   `json:"mcpConnections"`
-	Messages          []ModelMessage     `json:"messages"`
-	Skills            []string           `json:"skills"`
-	UsableSkills      []gatewaySkill     `json:"usableSkills"`
-	Identity          gatewayIdentity    `json:"identity"`
-	Attachments       []any              `json:"attachments"`
-	Schedule          gatewaySchedule    `json:"schedule"`
+	Model             gatewayModelConfig  `json:"model"`
+	ActiveContextTime int                 `json:"activeContextTime"`
+	Channels          []string            `json:"channels"`
+	CurrentChannel    string              `json:"currentChannel"`
+	AllowedActions    []string            `json:"allowedActions,omitempty"`
+	MCPConnections    []map[string]any    `json:"mcpConnections"`
+	Messages          []ModelMessage      `json:"messages"`
+	Skills            []string            `json:"skills"`
+	UsableSkills      []gatewaySkill      `json:"usableSkills"`
+	Identity          gatewayIdentity     `json:"identity"`
+	Attachments       []gatewayAttachment `json:"attachments"`
+	Schedule          gatewaySchedule     `json:"schedule"`
 }
 
 // --- resolved context (shared by Chat / StreamChat / TriggerSchedule) ---
@@ -211,7 +248,7 @@ func (r *Resolver) resolve(ctx context.Context, req ChatRequest) (resolvedContex
 	var messages []ModelMessage
 	var historySkills []string
 	if !skipHistory {
-		messages, err = r.loadHistoryMessages(ctx, req.BotID, req.SessionID, maxCtx)
+		messages, err = r.loadHistoryMessages(ctx, req.BotID, req.SessionID, req.AmbientSessionID, maxCtx)
 		if err != nil {
 			return resolvedContext{}, err
 		}
@@ -293,7 +330,7 @@ func (r *Resolver) resolve(ctx context.Context, req ChatRequest) (resolvedContex
 			ReplyTarget:     req.ReplyTarget,
 			SessionToken:    req.SessionToken,
 		},
-		Attachments: []any{},
+		Attachments: r.prepareAttachments(ctx, req, chatModel),
 	}
 
 	return resolvedContext{payload: payload, model: chatModel, provider: provider}, nil
@@ -311,7 +348,7 @@ func (r *Resolver) Chat(ctx context.Context, req ChatRequest) (ChatResponse, err
 	if err != nil {
 		return ChatResponse{}, err
 	}
-	if err := r.storeRound(ctx, req.BotID, req.SessionID, req.Query, resp.Messages, resp.Skills); err != nil {
+	if err := r.storeRound(ctx, req.BotID, req.SessionID, req.ContactID, req.Query, resp.Messages, resp.Skills); err != nil {
 		return ChatResponse{}, err
 	}
 	return ChatResponse{
@@ -319,6 +356,7 @@ func (r *Resolver) Chat(ctx context.Context, req ChatRequest) (ChatResponse, err
 		Skills:   resp.Skills,
 		Model:    rc.model.ModelID,
 		Provider: rc.provider.ClientType,
+		Usage:    resp.Usage.ToUsage(),
 	}, nil
 }
 
@@ -379,7 +417,7 @@ func (r *Resolver) TriggerSchedule(ctx context.Context, botID string, payload sc
 	if err != nil {
 		return err
 	}
-	return r.storeRound(ctx, botID, sessionID, payload.Command, resp.Messages, resp.Skills)
+	return r.storeRound(ctx, botID, sessionID, "", payload.Command, resp.Messages, resp.Skills)
 }
 
 // --- StreamChat ---
@@ -407,7 +445,7 @@ func (r *Resolver) StreamChat(ctx context.Context, req ChatRequest) (<-chan Stre
 			errCh <- err
 			return
 		}
-		if err := r.streamChat(ctx, rc.payload, req.BotID, req.SessionID, req.Query, req.Token, chunkCh); err != nil {
+		if err := r.streamChat(ctx, rc.payload, req.BotID, req.SessionID, req.ContactID, req.Query, req.Token, chunkCh); err != nil {
 			r.logger.Error("gateway stream request failed",
 				slog.String("bot_id", req.BotID),
 				slog.String("session_id", req.SessionID),
@@ -502,7 +540,7 @@ func (r *Resolver) postTriggerSchedule(ctx context.Context, payload triggerSched
 	return parsed, nil
 }
 
-func (r *Resolver) streamChat(ctx context.Context, payload gatewayRequest, botID, sessionID, query, token string, chunkCh chan<- StreamChunk) error {
+func (r *Resolver) streamChat(ctx context.Context, payload gatewayRequest, botID, sessionID, contactID, query, token string, chunkCh chan<- StreamChunk) error {
 	body, err := json.Marshal(payload)
 	if err != nil {
 		return err
@@ -558,7 +596,7 @@ func (r *Resolver) streamChat(ctx context.Context, payload gatewayRequest, botID
 		if stored {
 			continue
 		}
-		if handled, storeErr := r.tryStoreStream(ctx, botID, sessionID, query, currentEvent, data); storeErr != nil {
+		if handled, storeErr := r.tryStoreStream(ctx, botID, sessionID, contactID, query, currentEvent, data); storeErr != nil {
 			return storeErr
 		} else if handled {
 			stored = true
@@ -568,12 +606,12 @@ func (r *Resolver) streamChat(ctx context.Context, payload gatewayRequest, botID
 }
 
 // tryStoreStream attempts to extract final messages from a stream event and persist them.
-func (r *Resolver) tryStoreStream(ctx context.Context, botID, sessionID, query, eventType, data string) (bool, error) {
+func (r *Resolver) tryStoreStream(ctx context.Context, botID, sessionID, contactID, query, eventType, data string) (bool, error) {
 	// event: done + data: {messages: [...]}
 	if eventType == "done" {
 		var resp gatewayResponse
 		if err := json.Unmarshal([]byte(data), &resp); err == nil && len(resp.Messages) > 0 {
-			return true, r.storeRound(ctx, botID, sessionID, query, resp.Messages, resp.Skills)
+			return true, r.storeRound(ctx, botID, sessionID, contactID, query, resp.Messages, resp.Skills)
 		}
 	}
 
@@ -586,12 +624,12 @@ func (r *Resolver) tryStoreStream(ctx context.Context, botID, sessionID, query,
 	}
 	if err := json.Unmarshal([]byte(data), &envelope); err == nil {
 		if envelope.Type == "agent_end" && len(envelope.Messages) > 0 {
-			return true, r.storeRound(ctx, botID, sessionID, query, envelope.Messages, envelope.Skills)
+			return true, r.storeRound(ctx, botID, sessionID, contactID, query, envelope.Messages, envelope.Skills)
 		}
 		if envelope.Type == "done" && len(envelope.Data) > 0 {
 			var resp gatewayResponse
 			if err := json.Unmarshal(envelope.Data, &resp); err == nil && len(resp.Messages) > 0 {
-				return true, r.storeRound(ctx, botID, sessionID, query, resp.Messages, resp.Skills)
+				return true, r.storeRound(ctx, botID, sessionID, contactID, query, resp.Messages, resp.Skills)
 			}
 		}
 	}
@@ -599,7 +637,7 @@ func (r *Resolver) tryStoreStream(ctx context.Context, botID, sessionID, query,
 	// fallback: data: {messages: [...]}
 	var resp gatewayResponse
 	if err := json.Unmarshal([]byte(data), &resp); err == nil && len(resp.Messages) > 0 {
-		return true, r.storeRound(ctx, botID, sessionID, query, resp.Messages, resp.Skills)
+		return true, r.storeRound(ctx, botID, sessionID, contactID, query, resp.Messages, resp.Skills)
 	}
 	return false, nil
 }
@@ -624,7 +662,7 @@ func (r *Resolver) resolveContainerID(ctx context.Context, botID, explicit strin
 
 // --- history helpers ---
 
-func (r *Resolver) loadHistoryMessages(ctx context.Context, botID, sessionID string, maxContextMinutes int) ([]ModelMessage, error) {
+func (r *Resolver) loadHistoryMessages(ctx context.Context, botID, sessionID, ambientSessionID string, maxContextMinutes int) ([]ModelMessage, error) {
 	if r.historyService == nil {
 		return nil, fmt.Errorf("history service not configured")
 	}
@@ -633,6 +671,16 @@ func (r *Resolver) loadHistoryMessages(ctx context.Context, botID, sessionID str
 	if err != nil {
 		return nil, err
 	}
+	if ambientSessionID = strings.TrimSpace(ambientSessionID); ambientSessionID != "" && ambientSessionID != strings.TrimSpace(sessionID) {
+		ambient, err := r.historyService.ListBySessionSince(ctx, botID, ambientSessionID, since)
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, ambient...)
+		sort.SliceStable(records, func(i, j int) bool {
+			return records[i].Timestamp.Before(records[j].Timestamp)
+		})
+	}
 	var messages []ModelMessage
 	for _, record := range records {
 		msgs, err := recordToMessages(record)
@@ -679,42 +727,72 @@ func recordToMessages(record history.Record) ([]ModelMessage, error) {
 
 // --- store helpers ---
 
-func (r *Resolver) storeRound(ctx context.Context, botID, sessionID, query string, messages []ModelMessage, skills []string) error {
-	if err := r.storeHistory(ctx, botID, sessionID, query, messages, skills); err != nil {
+func (r *Resolver) storeRound(ctx context.Context, botID, sessionID, contactID, query string, messages []ModelMessage, skills []string) error {
+	historyID, err := r.storeHistory(ctx, botID, sessionID, query, messages, skills)
+	if err != nil {
 		return err
 	}
-	r.storeMemory(ctx, botID, sessionID, query, messages)
+	r.storeMemory(ctx, botID, sessionID, contactID, historyID, query, messages)
 	return nil
 }
 
-func (r *Resolver) storeHistory(ctx context.Context, botID, sessionID, query string, messages []ModelMessage, skills []string) error {
+// storeHistory stores a round in the history and returns the ID of its record.
+func (r *Resolver) storeHistory(ctx context.Context, botID, sessionID, query string, messages []ModelMessage, skills []string) (string, error) {
 	if r.historyService == nil {
-		return fmt.Errorf("history service not configured")
+		return "", fmt.Errorf("history service not configured")
 	}
 	if strings.TrimSpace(botID) == "" || strings.TrimSpace(sessionID) == "" {
-		return fmt.Errorf("bot id and session id are required")
+		return "", fmt.Errorf("bot id and session id are required")
 	}
 	if strings.TrimSpace(query) == "" && len(messages) == 0 {
-		return nil
+		return "", nil
 	}
 	// Convert typed messages to []map[string]any for the history service.
 	raw, err := json.Marshal(messages)
 	if err != nil {
-		return err
+		return "", err
 	}
 	var rows []map[string]any
 	if err := json.Unmarshal(raw, &rows); err != nil {
-		return err
+		return "", err
 	}
-	_, err = r.historyService.Create(ctx, botID, strings.TrimSpace(sessionID), history.CreateRequest{
+	record, err := r.historyService.Create(ctx, botID, strings.TrimSpace(sessionID), history.CreateRequest{
 		Messages: rows,
 		Metadata: map[string]any{"query": strings.TrimSpace(query)},
 		Skills:   skills,
 	})
+	if err != nil {
+		return "", err
+	}
+	return record.ID, nil
+}
+
+// RecordAmbient stores a group message the bot observed without answering, so it can be
+// given as context once the bot is addressed in the conversation. It is not added to memory.
+func (r *Resolver) RecordAmbient(ctx context.Context, msg AmbientMessage) error {
+	if r.historyService == nil {
+		return fmt.Errorf("history service not configured")
+	}
+	if strings.TrimSpace(msg.BotID) == "" || strings.TrimSpace(msg.SessionID) == "" {
+		return fmt.Errorf("bot id and session id are required")
+	}
+	text := strings.TrimSpace(msg.Text)
+	if text == "" {
+		return nil
+	}
+	sender := strings.TrimSpace(msg.Sender)
+	content := text
+	if sender != "" {
+		content = sender + ": " + text
+	}
+	_, err := r.historyService.Create(ctx, msg.BotID, strings.TrimSpace(msg.SessionID), history.CreateRequest{
+		Messages: []map[string]any{{"role": "user", "content": content}},
+		Metadata: map[string]any{"query": text, "sender": sender, "ambient": true},
+	})
 	return err
 }
 
-func (r *Resolver) storeMemory(ctx context.Context, botID, sessionID, query string, messages []ModelMessage) {
+func (r *Resolver) storeMemory(ctx context.Context, botID, sessionID, contactID, historyID, query string, messages []ModelMessage) {
 	if r.memoryService == nil {
 		return
 	}
@@ -740,6 +818,9 @@ func (r *Resolver) storeMemory(ctx context.Context, botID, sessionID, query stri
 		Messages:  memMsgs,
 		BotID:     botID,
 		SessionID: strings.TrimSpace(sessionID),
+		HistoryID: historyID,
+		ContactID: strings.TrimSpace(contactID),
+		Scope:     r.memoryScope(ctx, botID),
 	}); err != nil {
 		r.logger.Warn("store memory failed", slog.Any("error", err))
 	}
@@ -846,11 +927,22 @@ func (r *Resolver) loadBotSettings(ctx context.Context, botID string) (settings.
 		return settings.Settings{
 			MaxContextLoadTime: settings.DefaultMaxContextLoadTime,
 			Language:           settings.DefaultLanguage,
+			MemoryScope:        settings.DefaultMemoryScope,
 		}, nil
 	}
 	return r.settingsService.GetBot(ctx, botID)
 }
 
+// memoryScope returns the scope new memories of the bot are stored with. Without bot
+// settings the memory service picks its own default.
+func (r *Resolver) memoryScope(ctx context.Context, botID string) string {
+	botSettings, err := r.loadBotSettings(ctx, botID)
+	if err != nil {
+		return ""
+	}
+	return botSettings.MemoryScope
+}
+
 // --- utility ---
 
 func normalizeClientType(clientType string) (string, error) {
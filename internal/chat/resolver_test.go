@@ -54,7 +54,7 @@ func TestPostTriggerSchedule_Endpoint(t *testing.T) {
 			ContactName: "Scheduler",
 			UserID:      "owner-user-1",
 		},
-		Attachments: []any{},
+		Attachments: []gatewayAttachment{},
 		Schedule: gatewaySchedule{
 			ID:          "sched-1",
 			Name:        "daily report",
@@ -119,7 +119,7 @@ func TestPostTriggerSchedule_NoAuth(t *testing.T) {
 		Channels:    []string{},
 		Messages:    []ModelMessage{},
 		Skills:      []string{},
-		Attachments: []any{},
+		Attachments: []gatewayAttachment{},
 		Schedule:    gatewaySchedule{ID: "s1", Command: "test"},
 	}
 
@@ -149,7 +149,7 @@ func TestPostTriggerSchedule_GatewayError(t *testing.T) {
 		Channels:    []string{},
 		Messages:    []ModelMessage{},
 		Skills:      []string{},
-		Attachments: []any{},
+		Attachments: []gatewayAttachment{},
 		Schedule:    gatewaySchedule{ID: "s1", Command: "test"},
 	}
 
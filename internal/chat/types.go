@@ -115,6 +115,9 @@ type ChatRequest struct {
 	ContactAlias   string `json:"-"`
 	ReplyTarget    string `json:"-"`
 	SessionToken   string `json:"-"`
+	// AmbientSessionID names the session holding group messages the bot observed without
+	// answering; they are merged into the history by time.
+	AmbientSessionID string `json:"-"`
 
 	Query              string         `json:"query"`
 	Model              string         `json:"model,omitempty"`
@@ -126,6 +129,24 @@ type ChatRequest struct {
 	Messages           []ModelMessage `json:"messages,omitempty"`
 	Skills             []string       `json:"skills,omitempty"`
 	AllowedActions     []string       `json:"allowed_actions,omitempty"`
+	Attachments        []Attachment   `json:"-"`
+}
+
+// AmbientMessage is a group message the bot observed without being addressed.
+type AmbientMessage struct {
+	BotID     string
+	SessionID string
+	Sender    string
+	Text      string
+}
+
+// Attachment is a downloaded inbound file forwarded to the agent with the query.
+type Attachment struct {
+	Type    string
+	Name    string
+	Mime    string
+	Caption string
+	Data    []byte
 }
 
 // ChatResponse is the output of a non-streaming chat call.
@@ -134,6 +155,26 @@ type ChatResponse struct {
 	Skills   []string       `json:"skills,omitempty"`
 	Model    string         `json:"model,omitempty"`
 	Provider string         `json:"provider,omitempty"`
+	Usage    *Usage         `json:"usage,omitempty"`
+}
+
+// Usage is the token usage of a chat round as reported by the agent gateway.
+type Usage struct {
+	InputTokens  int64 `json:"input_tokens"`
+	OutputTokens int64 `json:"output_tokens"`
+	TotalTokens  int64 `json:"total_tokens"`
+}
+
+// Tokens returns the total token count, summing input and output when the gateway did
+// not report a total.
+func (u *Usage) Tokens() int64 {
+	if u == nil {
+		return 0
+	}
+	if u.TotalTokens > 0 {
+		return u.TotalTokens
+	}
+	return u.InputTokens + u.OutputTokens
 }
 
 // StreamChunk is a raw JSON chunk from the streaming response.
@@ -23,6 +23,9 @@ const (
 	DefaultPGSSLMode        = "disable"
 	DefaultQdrantURL        = "http://127.0.0.1:6334"
 	DefaultQdrantCollection = "memory"
+
+	MemoryStoreQdrant   = "qdrant"
+	MemoryStorePgvector = "pgvector"
 )
 
 type Config struct {
@@ -35,6 +38,8 @@ type Config struct {
 	Postgres     PostgresConfig     `toml:"postgres"`
 	Qdrant       QdrantConfig       `toml:"qdrant"`
 	AgentGateway AgentGatewayConfig `toml:"agent_gateway"`
+	RateLimit    RateLimitConfig    `toml:"rate_limit"`
+	Memory       MemoryConfig       `toml:"memory"`
 }
 
 type LogConfig struct {
@@ -90,6 +95,32 @@ type AgentGatewayConfig struct {
 	Port int    `toml:"port"`
 }
 
+// RateLimitConfig limits inbound channel messages. Sender limits apply to each contact
+// (or session when the sender has no contact) of a bot, bot limits to all senders of a
+// bot together. A zero value disables the limit.
+type RateLimitConfig struct {
+	RejectMessage string          `toml:"reject_message"`
+	Sender        RateLimitPolicy `toml:"sender"`
+	Bot           RateLimitPolicy `toml:"bot"`
+}
+
+type RateLimitPolicy struct {
+	MessagesPerMinute int   `toml:"messages_per_minute"`
+	MaxConcurrent     int   `toml:"max_concurrent"`
+	DailyTokens       int64 `toml:"daily_tokens"`
+}
+
+// MemoryConfig selects the memory vector store, "qdrant" or "pgvector" (the Postgres database
+// with the pgvector extension), and tunes memory search. In hybrid mode, searches with
+// embeddings enabled also run the BM25 query and fuse both rankings with reciprocal rank
+// fusion, weighted per retriever.
+type MemoryConfig struct {
+	Store        string  `toml:"store"`
+	SearchMode   string  `toml:"search_mode"`
+	DenseWeight  float64 `toml:"dense_weight"`
+	SparseWeight float64 `toml:"sparse_weight"`
+}
+
 func (c AgentGatewayConfig) BaseURL() string {
 	host := c.Host
 	if host == "" {
@@ -143,6 +174,12 @@ func Load(path string) (Config, error) {
 			Host: "127.0.0.1",
 			Port: 8081,
 		},
+		Memory: MemoryConfig{
+			Store:        MemoryStoreQdrant,
+			SearchMode:   "hybrid",
+			DenseWeight:  1,
+			SparseWeight: 1,
+		},
 	}
 
 	if path == "" {
@@ -57,13 +57,60 @@ type BotPreauthKey struct {
 	ExpiresAt      pgtype.Timestamptz `json:"expires_at"`
 	UsedAt         pgtype.Timestamptz `json:"used_at"`
 	CreatedAt      pgtype.Timestamptz `json:"created_at"`
+	ContactID      pgtype.UUID        `json:"contact_id"`
 }
 
 type BotSetting struct {
-	BotID              pgtype.UUID `json:"bot_id"`
-	MaxContextLoadTime int32       `json:"max_context_load_time"`
-	Language           string      `json:"language"`
-	AllowGuest         bool        `json:"allow_guest"`
+	BotID                 pgtype.UUID `json:"bot_id"`
+	MaxContextLoadTime    int32       `json:"max_context_load_time"`
+	Language              string      `json:"language"`
+	AllowGuest            bool        `json:"allow_guest"`
+	UnifiedSession        bool        `json:"unified_session"`
+	MessageDebounceMs     int32       `json:"message_debounce_ms"`
+	InterruptOnNewMessage bool        `json:"interrupt_on_new_message"`
+	MemoryScope           string      `json:"memory_scope"`
+}
+
+type ChannelConnectionEvent struct {
+	ID              pgtype.UUID        `json:"id"`
+	BotID           pgtype.UUID        `json:"bot_id"`
+	ChannelConfigID string             `json:"channel_config_id"`
+	ChannelType     string             `json:"channel_type"`
+	EventType       string             `json:"event_type"`
+	Message         string             `json:"message"`
+	CreatedAt       pgtype.Timestamptz `json:"created_at"`
+}
+
+type ChannelInboundMessage struct {
+	ID              pgtype.UUID        `json:"id"`
+	BotID           pgtype.UUID        `json:"bot_id"`
+	ChannelConfigID string             `json:"channel_config_id"`
+	ChannelType     string             `json:"channel_type"`
+	SessionKey      string             `json:"session_key"`
+	Payload         []byte             `json:"payload"`
+	Status          string             `json:"status"`
+	Attempts        int32              `json:"attempts"`
+	LastError       pgtype.Text        `json:"last_error"`
+	AvailableAt     pgtype.Timestamptz `json:"available_at"`
+	LockedAt        pgtype.Timestamptz `json:"locked_at"`
+	CreatedAt       pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt       pgtype.Timestamptz `json:"updated_at"`
+}
+
+type ChannelOutboundMessage struct {
+	ID                 pgtype.UUID        `json:"id"`
+	BotID              pgtype.UUID        `json:"bot_id"`
+	ChannelConfigID    string             `json:"channel_config_id"`
+	ChannelType        string             `json:"channel_type"`
+	SessionID          string             `json:"session_id"`
+	Target             string             `json:"target"`
+	PlatformMessageIds []string           `json:"platform_message_ids"`
+	Payload            []byte             `json:"payload"`
+	Status             string             `json:"status"`
+	Attempts           int32              `json:"attempts"`
+	LastError          pgtype.Text        `json:"last_error"`
+	CreatedAt          pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt          pgtype.Timestamptz `json:"updated_at"`
 }
 
 type ChannelSession struct {
@@ -180,6 +227,33 @@ type McpConnection struct {
 	UpdatedAt pgtype.Timestamptz `json:"updated_at"`
 }
 
+type MemoryHistory struct {
+	ID           pgtype.UUID        `json:"id"`
+	MemoryID     string             `json:"memory_id"`
+	BotID        string             `json:"bot_id"`
+	Event        string             `json:"event"`
+	OldMemory    string             `json:"old_memory"`
+	NewMemory    string             `json:"new_memory"`
+	Payload      []byte             `json:"payload"`
+	SessionID    string             `json:"session_id"`
+	HistoryID    pgtype.UUID        `json:"history_id"`
+	Model        string             `json:"model"`
+	RestoredFrom pgtype.UUID        `json:"restored_from"`
+	CreatedAt    pgtype.Timestamptz `json:"created_at"`
+}
+
+type MemoryReindexJob struct {
+	ID         pgtype.UUID        `json:"id"`
+	BotID      string             `json:"bot_id"`
+	ModelID    string             `json:"model_id"`
+	Status     string             `json:"status"`
+	Total      int32              `json:"total"`
+	Processed  int32              `json:"processed"`
+	Error      string             `json:"error"`
+	StartedAt  pgtype.Timestamptz `json:"started_at"`
+	FinishedAt pgtype.Timestamptz `json:"finished_at"`
+}
+
 type Model struct {
 	ID            pgtype.UUID        `json:"id"`
 	ModelID       string             `json:"model_id"`
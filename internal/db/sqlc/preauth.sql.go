@@ -12,15 +12,16 @@ import (
 )
 
 const createBotPreauthKey = `-- name: CreateBotPreauthKey :one
-INSERT INTO bot_preauth_keys (bot_id, token, issued_by_user_id, expires_at)
-VALUES ($1, $2, $3, $4)
-RETURNING id, bot_id, token, issued_by_user_id, expires_at, used_at, created_at
+INSERT INTO bot_preauth_keys (bot_id, token, issued_by_user_id, contact_id, expires_at)
+VALUES ($1, $2, $3, $4, $5)
+RETURNING id, bot_id, token, issued_by_user_id, expires_at, used_at, created_at, contact_id
 `
 
 type CreateBotPreauthKeyParams struct {
 	BotID          pgtype.UUID        `json:"bot_id"`
 	Token          string             `json:"token"`
 	IssuedByUserID pgtype.UUID        `json:"issued_by_user_id"`
+	ContactID      pgtype.UUID        `json:"contact_id"`
 	ExpiresAt      pgtype.Timestamptz `json:"expires_at"`
 }
 
@@ -29,6 +30,7 @@ func (q *Queries) CreateBotPreauthKey(ctx context.Context, arg CreateBotPreauthK
 		arg.BotID,
 		arg.Token,
 		arg.IssuedByUserID,
+		arg.ContactID,
 		arg.ExpiresAt,
 	)
 	var i BotPreauthKey
@@ -40,12 +42,13 @@ func (q *Queries) CreateBotPreauthKey(ctx context.Context, arg CreateBotPreauthK
 		&i.ExpiresAt,
 		&i.UsedAt,
 		&i.CreatedAt,
+		&i.ContactID,
 	)
 	return i, err
 }
 
 const getBotPreauthKey = `-- name: GetBotPreauthKey :one
-SELECT id, bot_id, token, issued_by_user_id, expires_at, used_at, created_at
+SELECT id, bot_id, token, issued_by_user_id, expires_at, used_at, created_at, contact_id
 FROM bot_preauth_keys
 WHERE token = $1
 LIMIT 1
@@ -62,6 +65,7 @@ func (q *Queries) GetBotPreauthKey(ctx context.Context, token string) (BotPreaut
 		&i.ExpiresAt,
 		&i.UsedAt,
 		&i.CreatedAt,
+		&i.ContactID,
 	)
 	return i, err
 }
@@ -69,8 +73,8 @@ func (q *Queries) GetBotPreauthKey(ctx context.Context, token string) (BotPreaut
 const markBotPreauthKeyUsed = `-- name: MarkBotPreauthKeyUsed :one
 UPDATE bot_preauth_keys
 SET used_at = now()
-WHERE id = $1
-RETURNING id, bot_id, token, issued_by_user_id, expires_at, used_at, created_at
+WHERE id = $1 AND used_at IS NULL
+RETURNING id, bot_id, token, issued_by_user_id, expires_at, used_at, created_at, contact_id
 `
 
 func (q *Queries) MarkBotPreauthKeyUsed(ctx context.Context, id pgtype.UUID) (BotPreauthKey, error) {
@@ -84,6 +88,7 @@ func (q *Queries) MarkBotPreauthKeyUsed(ctx context.Context, id pgtype.UUID) (Bo
 		&i.ExpiresAt,
 		&i.UsedAt,
 		&i.CreatedAt,
+		&i.ContactID,
 	)
 	return i, err
 }
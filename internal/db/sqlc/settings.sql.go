@@ -54,7 +54,7 @@ func (q *Queries) GetBotModelConfigByBotID(ctx context.Context, botID pgtype.UUI
 }
 
 const getSettingsByBotID = `-- name: GetSettingsByBotID :one
-SELECT bot_id, max_context_load_time, language, allow_guest
+SELECT bot_id, max_context_load_time, language, allow_guest, unified_session, message_debounce_ms, interrupt_on_new_message, memory_scope
 FROM bot_settings
 WHERE bot_id = $1
 `
@@ -67,6 +67,10 @@ func (q *Queries) GetSettingsByBotID(ctx context.Context, botID pgtype.UUID) (Bo
 		&i.MaxContextLoadTime,
 		&i.Language,
 		&i.AllowGuest,
+		&i.UnifiedSession,
+		&i.MessageDebounceMs,
+		&i.InterruptOnNewMessage,
+		&i.MemoryScope,
 	)
 	return i, err
 }
@@ -133,20 +137,28 @@ func (q *Queries) UpsertBotModelConfig(ctx context.Context, arg UpsertBotModelCo
 }
 
 const upsertBotSettings = `-- name: UpsertBotSettings :one
-INSERT INTO bot_settings (bot_id, max_context_load_time, language, allow_guest)
-VALUES ($1, $2, $3, $4)
+INSERT INTO bot_settings (bot_id, max_context_load_time, language, allow_guest, unified_session, message_debounce_ms, interrupt_on_new_message, memory_scope)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
 ON CONFLICT (bot_id) DO UPDATE SET
   max_context_load_time = EXCLUDED.max_context_load_time,
   language = EXCLUDED.language,
-  allow_guest = EXCLUDED.allow_guest
-RETURNING bot_id, max_context_load_time, language, allow_guest
+  allow_guest = EXCLUDED.allow_guest,
+  unified_session = EXCLUDED.unified_session,
+  message_debounce_ms = EXCLUDED.message_debounce_ms,
+  interrupt_on_new_message = EXCLUDED.interrupt_on_new_message,
+  memory_scope = EXCLUDED.memory_scope
+RETURNING bot_id, max_context_load_time, language, allow_guest, unified_session, message_debounce_ms, interrupt_on_new_message, memory_scope
 `
 
 type UpsertBotSettingsParams struct {
-	BotID              pgtype.UUID `json:"bot_id"`
-	MaxContextLoadTime int32       `json:"max_context_load_time"`
-	Language           string      `json:"language"`
-	AllowGuest         bool        `json:"allow_guest"`
+	BotID                 pgtype.UUID `json:"bot_id"`
+	MaxContextLoadTime    int32       `json:"max_context_load_time"`
+	Language              string      `json:"language"`
+	AllowGuest            bool        `json:"allow_guest"`
+	UnifiedSession        bool        `json:"unified_session"`
+	MessageDebounceMs     int32       `json:"message_debounce_ms"`
+	InterruptOnNewMessage bool        `json:"interrupt_on_new_message"`
+	MemoryScope           string      `json:"memory_scope"`
 }
 
 func (q *Queries) UpsertBotSettings(ctx context.Context, arg UpsertBotSettingsParams) (BotSetting, error) {
@@ -155,6 +167,10 @@ func (q *Queries) UpsertBotSettings(ctx context.Context, arg UpsertBotSettingsPa
 		arg.MaxContextLoadTime,
 		arg.Language,
 		arg.AllowGuest,
+		arg.UnifiedSession,
+		arg.MessageDebounceMs,
+		arg.InterruptOnNewMessage,
+		arg.MemoryScope,
 	)
 	var i BotSetting
 	err := row.Scan(
@@ -162,6 +178,10 @@ func (q *Queries) UpsertBotSettings(ctx context.Context, arg UpsertBotSettingsPa
 		&i.MaxContextLoadTime,
 		&i.Language,
 		&i.AllowGuest,
+		&i.UnifiedSession,
+		&i.MessageDebounceMs,
+		&i.InterruptOnNewMessage,
+		&i.MemoryScope,
 	)
 	return i, err
 }
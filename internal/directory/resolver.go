@@ -0,0 +1,137 @@
+package directory
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/memohai/memoh/internal/channel"
+)
+
+// ConfigResolver resolves the channel config a bot uses on a platform.
+type ConfigResolver interface {
+	ResolveEffectiveConfig(ctx context.Context, botID string, channelType channel.ChannelType) (channel.ChannelConfig, error)
+}
+
+// Service serves directory lookups from the channel adapter when it implements
+// channel.DirectoryProvider, and falls back to the LocalService built from contacts and
+// channel sessions when the adapter cannot answer.
+type Service struct {
+	registry *channel.Registry
+	configs  ConfigResolver
+	local    *LocalService
+	logger   *slog.Logger
+}
+
+func NewService(log *slog.Logger, registry *channel.Registry, configs ConfigResolver, local *LocalService) *Service {
+	if log == nil {
+		log = slog.Default()
+	}
+	return &Service{
+		registry: registry,
+		configs:  configs,
+		local:    local,
+		logger:   log.With(slog.String("service", "directory")),
+	}
+}
+
+func (s *Service) ListPeers(ctx context.Context, botID string, channelType channel.ChannelType, query string, limit int) ([]channel.DirectoryEntry, error) {
+	items, err := fromAdapter(ctx, s, botID, channelType, func(dir channel.ChannelDirectoryAdapter, cfg channel.ChannelConfig) ([]channel.DirectoryEntry, error) {
+		return dir.ListPeers(ctx, cfg, channel.DirectoryQuery{Query: query, Limit: limit, Kind: channel.DirectoryEntryUser})
+	})
+	if err == nil {
+		return items, nil
+	}
+	if s.local == nil {
+		return nil, err
+	}
+	return s.local.ListPeers(ctx, botID, channelType.String(), query, limit)
+}
+
+func (s *Service) ListGroups(ctx context.Context, botID string, channelType channel.ChannelType, query string, limit int) ([]channel.DirectoryEntry, error) {
+	items, err := fromAdapter(ctx, s, botID, channelType, func(dir channel.ChannelDirectoryAdapter, cfg channel.ChannelConfig) ([]channel.DirectoryEntry, error) {
+		return dir.ListGroups(ctx, cfg, channel.DirectoryQuery{Query: query, Limit: limit, Kind: channel.DirectoryEntryGroup})
+	})
+	if err == nil {
+		return items, nil
+	}
+	if s.local == nil {
+		return nil, err
+	}
+	return s.local.ListGroups(ctx, botID, channelType.String(), query, limit)
+}
+
+func (s *Service) ListGroupMembers(ctx context.Context, botID string, channelType channel.ChannelType, groupID, query string, limit int) ([]channel.DirectoryEntry, error) {
+	items, err := fromAdapter(ctx, s, botID, channelType, func(dir channel.ChannelDirectoryAdapter, cfg channel.ChannelConfig) ([]channel.DirectoryEntry, error) {
+		return dir.ListGroupMembers(ctx, cfg, groupID, channel.DirectoryQuery{Query: query, Limit: limit, Kind: channel.DirectoryEntryUser})
+	})
+	if err == nil {
+		return items, nil
+	}
+	if s.local == nil || !errors.Is(err, ErrUnsupported) {
+		return nil, err
+	}
+	return s.local.ListGroupMembers(ctx, botID, channelType.String(), groupID, limit)
+}
+
+// ResolveTarget resolves a human-friendly name, handle or ID to a directory entry whose ID is
+// a valid send target. An empty kind matches users first, then groups.
+func (s *Service) ResolveTarget(ctx context.Context, botID string, channelType channel.ChannelType, input string, kind channel.DirectoryEntryKind) (channel.DirectoryEntry, error) {
+	trimmed := strings.TrimSpace(input)
+	if trimmed == "" {
+		return channel.DirectoryEntry{}, ErrNotFound
+	}
+	entry, err := fromAdapter(ctx, s, botID, channelType, func(dir channel.ChannelDirectoryAdapter, cfg channel.ChannelConfig) (channel.DirectoryEntry, error) {
+		return dir.ResolveTarget(ctx, cfg, trimmed, kind)
+	})
+	if err == nil {
+		return entry, nil
+	}
+	if errors.Is(err, ErrAmbiguous) || s.local == nil {
+		return channel.DirectoryEntry{}, err
+	}
+	if kind != "" {
+		return s.local.ResolveTarget(ctx, botID, channelType.String(), trimmed, kind)
+	}
+	user, err := s.local.ResolveTarget(ctx, botID, channelType.String(), trimmed, channel.DirectoryEntryUser)
+	if !errors.Is(err, ErrNotFound) {
+		return user, err
+	}
+	return s.local.ResolveTarget(ctx, botID, channelType.String(), trimmed, channel.DirectoryEntryGroup)
+}
+
+// ResolveTargetID resolves input to a send target. It implements channel.TargetDirectory.
+func (s *Service) ResolveTargetID(ctx context.Context, botID string, channelType channel.ChannelType, input string) (string, error) {
+	entry, err := s.ResolveTarget(ctx, botID, channelType, input, "")
+	if err != nil {
+		return "", err
+	}
+	return entry.ID, nil
+}
+
+// fromAdapter runs fn against the platform's directory adapter. It returns ErrUnsupported when
+// the adapter has no directory and logs unexpected adapter failures, so callers can fall back.
+func fromAdapter[T any](ctx context.Context, s *Service, botID string, channelType channel.ChannelType, fn func(channel.ChannelDirectoryAdapter, channel.ChannelConfig) (T, error)) (T, error) {
+	var zero T
+	if s.registry == nil {
+		return zero, ErrUnsupported
+	}
+	dir, ok := s.registry.GetDirectory(channelType)
+	if !ok {
+		return zero, ErrUnsupported
+	}
+	if s.configs == nil {
+		return zero, fmt.Errorf("channel config resolver not configured")
+	}
+	cfg, err := s.configs.ResolveEffectiveConfig(ctx, botID, channelType)
+	if err != nil {
+		return zero, err
+	}
+	result, err := fn(dir, cfg)
+	if err != nil && !errors.Is(err, ErrUnsupported) && !errors.Is(err, ErrNotFound) && !errors.Is(err, ErrAmbiguous) && s.logger != nil {
+		s.logger.Warn("channel directory lookup failed", slog.String("channel", channelType.String()), slog.String("bot_id", botID), slog.Any("error", err))
+	}
+	return result, err
+}
@@ -0,0 +1,161 @@
+package directory
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/memohai/memoh/internal/channel"
+	"github.com/memohai/memoh/internal/contacts"
+)
+
+type fakeContacts struct {
+	items    []contacts.Contact
+	channels map[string][]contacts.ContactChannel
+}
+
+func (f *fakeContacts) Search(ctx context.Context, botID, query string) ([]contacts.Contact, error) {
+	var results []contacts.Contact
+	for _, item := range f.items {
+		if strings.Contains(strings.ToLower(item.DisplayName), strings.ToLower(query)) {
+			results = append(results, item)
+		}
+	}
+	return results, nil
+}
+
+func (f *fakeContacts) ListByBot(ctx context.Context, botID string) ([]contacts.Contact, error) {
+	return f.items, nil
+}
+
+func (f *fakeContacts) ListChannelsByContact(ctx context.Context, contactID string) ([]contacts.ContactChannel, error) {
+	return f.channels[contactID], nil
+}
+
+type fakeSessions struct {
+	sessions []channel.ChannelSession
+}
+
+func (f *fakeSessions) ListSessionsByBotPlatform(ctx context.Context, botID, platform string) ([]channel.ChannelSession, error) {
+	return f.sessions, nil
+}
+
+type fakeConfigs struct{}
+
+func (fakeConfigs) ResolveEffectiveConfig(ctx context.Context, botID string, channelType channel.ChannelType) (channel.ChannelConfig, error) {
+	return channel.ChannelConfig{ID: "cfg-1", BotID: botID, ChannelType: channelType}, nil
+}
+
+type fakeDirectoryAdapter struct {
+	entries map[string]channel.DirectoryEntry
+}
+
+func (f *fakeDirectoryAdapter) Type() channel.ChannelType { return "test" }
+
+func (f *fakeDirectoryAdapter) Descriptor() channel.Descriptor {
+	return channel.Descriptor{Type: "test", DisplayName: "Test"}
+}
+
+func (f *fakeDirectoryAdapter) Directory() channel.ChannelDirectoryAdapter { return f }
+
+func (f *fakeDirectoryAdapter) ListPeers(ctx context.Context, cfg channel.ChannelConfig, query channel.DirectoryQuery) ([]channel.DirectoryEntry, error) {
+	return nil, channel.ErrDirectoryNotSupported
+}
+
+func (f *fakeDirectoryAdapter) ListGroups(ctx context.Context, cfg channel.ChannelConfig, query channel.DirectoryQuery) ([]channel.DirectoryEntry, error) {
+	return []channel.DirectoryEntry{{Kind: channel.DirectoryEntryGroup, ID: "-1001", Name: "Platform Group"}}, nil
+}
+
+func (f *fakeDirectoryAdapter) ListGroupMembers(ctx context.Context, cfg channel.ChannelConfig, groupID string, query channel.DirectoryQuery) ([]channel.DirectoryEntry, error) {
+	return nil, errors.New("group not found")
+}
+
+func (f *fakeDirectoryAdapter) ResolveTarget(ctx context.Context, cfg channel.ChannelConfig, input string, kind channel.DirectoryEntryKind) (channel.DirectoryEntry, error) {
+	entry, ok := f.entries[input]
+	if !ok {
+		return channel.DirectoryEntry{}, channel.ErrDirectoryNotSupported
+	}
+	return entry, nil
+}
+
+func newTestService(t *testing.T) *Service {
+	t.Helper()
+	registry := channel.NewRegistry()
+	registry.MustRegister(&fakeDirectoryAdapter{entries: map[string]channel.DirectoryEntry{
+		"@alice": {Kind: channel.DirectoryEntryUser, ID: "100", Handle: "@alice"},
+	}})
+	local := NewLocalService(nil, &fakeContacts{
+		items: []contacts.Contact{{ID: "c1", DisplayName: "Alice Liddell"}, {ID: "c2", DisplayName: "Bob"}},
+		channels: map[string][]contacts.ContactChannel{
+			"c1": {{Platform: "test", ExternalID: "100"}},
+			"c2": {{Platform: "test", ExternalID: "101"}},
+		},
+	}, &fakeSessions{sessions: []channel.ChannelSession{
+		{SessionID: "s1", ReplyTarget: "-2002", Metadata: map[string]any{"conversation_type": "group", "conversation_name": "Wonderland"}},
+	}})
+	return NewService(nil, registry, fakeConfigs{}, local)
+}
+
+func TestServiceResolveTargetPrefersAdapter(t *testing.T) {
+	t.Parallel()
+
+	svc := newTestService(t)
+	entry, err := svc.ResolveTarget(context.Background(), "bot-1", "test", "@alice", "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if entry.ID != "100" || entry.Handle != "@alice" {
+		t.Fatalf("unexpected entry: %+v", entry)
+	}
+}
+
+func TestServiceResolveTargetFallsBackToLocal(t *testing.T) {
+	t.Parallel()
+
+	svc := newTestService(t)
+	entry, err := svc.ResolveTarget(context.Background(), "bot-1", "test", "Bob", "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if entry.Kind != channel.DirectoryEntryUser || entry.ID != "101" {
+		t.Fatalf("unexpected entry: %+v", entry)
+	}
+	group, err := svc.ResolveTarget(context.Background(), "bot-1", "test", "Wonderland", "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if group.Kind != channel.DirectoryEntryGroup || group.ID != "-2002" {
+		t.Fatalf("unexpected group: %+v", group)
+	}
+	if _, err := svc.ResolveTarget(context.Background(), "bot-1", "test", "Carol", ""); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected not found, got %v", err)
+	}
+	id, err := svc.ResolveTargetID(context.Background(), "bot-1", "test", "Alice")
+	if err != nil || id != "100" {
+		t.Fatalf("unexpected target %q: %v", id, err)
+	}
+}
+
+func TestServiceListFallsBackOnlyWhenUnsupported(t *testing.T) {
+	t.Parallel()
+
+	svc := newTestService(t)
+	peers, err := svc.ListPeers(context.Background(), "bot-1", "test", "", 0)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(peers) != 2 {
+		t.Fatalf("expected local peers, got %+v", peers)
+	}
+	groups, err := svc.ListGroups(context.Background(), "bot-1", "test", "", 0)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(groups) != 1 || groups[0].ID != "-1001" {
+		t.Fatalf("expected platform groups, got %+v", groups)
+	}
+	if _, err := svc.ListGroupMembers(context.Background(), "bot-1", "test", "-1001", "", 0); err == nil || errors.Is(err, ErrUnsupported) {
+		t.Fatalf("expected adapter error, got %v", err)
+	}
+}
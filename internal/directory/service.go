@@ -2,7 +2,6 @@ package directory
 
 import (
 	"context"
-	"errors"
 	"fmt"
 	"log/slog"
 	"strings"
@@ -12,9 +11,9 @@ import (
 )
 
 var (
-	ErrNotFound    = errors.New("directory entry not found")
-	ErrAmbiguous   = errors.New("directory entry ambiguous")
-	ErrUnsupported = errors.New("directory operation unsupported")
+	ErrNotFound    = channel.ErrDirectoryEntryNotFound
+	ErrAmbiguous   = channel.ErrDirectoryEntryAmbiguous
+	ErrUnsupported = channel.ErrDirectoryNotSupported
 )
 
 type ContactReader interface {
@@ -1,8 +1,10 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
 	"sort"
+	"strconv"
 	"strings"
 
 	"github.com/labstack/echo/v4"
@@ -10,15 +12,30 @@ import (
 	"github.com/memohai/memoh/internal/auth"
 	"github.com/memohai/memoh/internal/channel"
 	"github.com/memohai/memoh/internal/identity"
+	"github.com/memohai/memoh/internal/router"
+	"github.com/memohai/memoh/internal/users"
 )
 
 type ChannelHandler struct {
-	service  *channel.Service
-	registry *channel.Registry
+	service     *channel.Service
+	registry    *channel.Registry
+	userService *users.Service
+	rateLimiter *router.RateLimiter
+	manager     *channel.Manager
 }
 
-func NewChannelHandler(service *channel.Service, registry *channel.Registry) *ChannelHandler {
-	return &ChannelHandler{service: service, registry: registry}
+func NewChannelHandler(service *channel.Service, registry *channel.Registry, userService *users.Service) *ChannelHandler {
+	return &ChannelHandler{service: service, registry: registry, userService: userService}
+}
+
+// SetRateLimiter exposes the counters of the inbound rate limiter to admins.
+func (h *ChannelHandler) SetRateLimiter(limiter *router.RateLimiter) {
+	h.rateLimiter = limiter
+}
+
+// SetManager exposes the inbound dispatch statistics of the channel manager to admins.
+func (h *ChannelHandler) SetManager(manager *channel.Manager) {
+	h.manager = manager
 }
 
 func (h *ChannelHandler) Register(e *echo.Echo) {
@@ -29,6 +46,11 @@ func (h *ChannelHandler) Register(e *echo.Echo) {
 	metaGroup := e.Group("/channels")
 	metaGroup.GET("", h.ListChannels)
 	metaGroup.GET("/:platform", h.GetChannel)
+	metaGroup.POST("/:platform/webhook/:config_id", h.HandleWebhook)
+	metaGroup.GET("/inbound-queue", h.ListInboundQueue)
+	metaGroup.POST("/inbound-queue/:id/replay", h.ReplayInboundMessage)
+	metaGroup.GET("/rate-limits", h.ListRateLimits)
+	metaGroup.GET("/inbound-stats", h.ListInboundStats)
 }
 
 // GetUserConfig godoc
@@ -160,6 +182,175 @@ func (h *ChannelHandler) GetChannel(c echo.Context) error {
 	return c.JSON(http.StatusOK, resp)
 }
 
+// HandleWebhook godoc
+// @Summary Receive channel webhook
+// @Description Accept an inbound event pushed by a channel platform for an active webhook connection
+// @Tags channel
+// @Param platform path string true "Channel platform"
+// @Param config_id path string true "Channel config ID"
+// @Success 200
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /channels/{platform}/webhook/{config_id} [post]
+func (h *ChannelHandler) HandleWebhook(c echo.Context) error {
+	channelType, err := h.registry.ParseChannelType(c.Param("platform"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	receiver, ok := h.registry.GetWebhookReceiver(channelType)
+	if !ok {
+		return echo.NewHTTPError(http.StatusNotFound, "channel webhook not supported")
+	}
+	configID := strings.TrimSpace(c.Param("config_id"))
+	if configID == "" {
+		return echo.NewHTTPError(http.StatusBadRequest, "config id is required")
+	}
+	if err := receiver.HandleWebhook(c.Request().Context(), configID, c.Request()); err != nil {
+		switch {
+		case errors.Is(err, channel.ErrWebhookNotFound):
+			return echo.NewHTTPError(http.StatusNotFound, err.Error())
+		case errors.Is(err, channel.ErrWebhookUnauthorized):
+			return echo.NewHTTPError(http.StatusUnauthorized, err.Error())
+		default:
+			return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		}
+	}
+	return c.NoContent(http.StatusOK)
+}
+
+type InboundQueueResponse struct {
+	Items []channel.QueuedInbound `json:"items"`
+}
+
+// ListInboundQueue godoc
+// @Summary List queued inbound messages (admin only)
+// @Description List inbound messages waiting in the durable queue, being processed, or dead-lettered
+// @Tags channel
+// @Param status query string false "Status filter: pending, processing or dead"
+// @Param bot_id query string false "Bot ID filter"
+// @Param limit query int false "Maximum number of messages (default 100, max 500)"
+// @Success 200 {object} InboundQueueResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /channels/inbound-queue [get]
+func (h *ChannelHandler) ListInboundQueue(c echo.Context) error {
+	if err := h.requireAdmin(c); err != nil {
+		return err
+	}
+	limit := 0
+	if raw := strings.TrimSpace(c.QueryParam("limit")); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, "invalid limit")
+		}
+		limit = parsed
+	}
+	items, err := h.service.ListInbound(c.Request().Context(), c.QueryParam("status"), c.QueryParam("bot_id"), limit)
+	if err != nil {
+		if strings.Contains(err.Error(), "invalid") {
+			return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		}
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+	return c.JSON(http.StatusOK, InboundQueueResponse{Items: items})
+}
+
+// ReplayInboundMessage godoc
+// @Summary Replay a queued inbound message (admin only)
+// @Description Reset a dead-lettered or waiting inbound message so it is processed again immediately
+// @Tags channel
+// @Param id path string true "Queued message ID"
+// @Success 200 {object} channel.QueuedInbound
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /channels/inbound-queue/{id}/replay [post]
+func (h *ChannelHandler) ReplayInboundMessage(c echo.Context) error {
+	if err := h.requireAdmin(c); err != nil {
+		return err
+	}
+	resp, err := h.service.ReplayInbound(c.Request().Context(), c.Param("id"))
+	if err != nil {
+		switch {
+		case strings.Contains(err.Error(), "invalid"):
+			return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		case strings.Contains(err.Error(), "not found"):
+			return echo.NewHTTPError(http.StatusNotFound, err.Error())
+		default:
+			return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		}
+	}
+	return c.JSON(http.StatusOK, resp)
+}
+
+type RateLimitsResponse struct {
+	Items []router.RateLimitCounter `json:"items"`
+}
+
+// ListRateLimits godoc
+// @Summary List inbound rate limit counters (admin only)
+// @Description List the in-memory counters of the inbound rate limiter per sender and per bot
+// @Tags channel
+// @Param bot_id query string false "Bot ID filter"
+// @Success 200 {object} RateLimitsResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /channels/rate-limits [get]
+func (h *ChannelHandler) ListRateLimits(c echo.Context) error {
+	if err := h.requireAdmin(c); err != nil {
+		return err
+	}
+	if h.rateLimiter == nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "rate limiter not configured")
+	}
+	return c.JSON(http.StatusOK, RateLimitsResponse{Items: h.rateLimiter.Counters(c.QueryParam("bot_id"))})
+}
+
+type InboundStatsResponse struct {
+	Items []channel.InboundStats `json:"items"`
+}
+
+// ListInboundStats godoc
+// @Summary List inbound dispatch statistics (admin only)
+// @Description List the inbound queue depth, active sessions and queue wait times per bot since the server started
+// @Tags channel
+// @Param bot_id query string false "Bot ID filter"
+// @Success 200 {object} InboundStatsResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /channels/inbound-stats [get]
+func (h *ChannelHandler) ListInboundStats(c echo.Context) error {
+	if err := h.requireAdmin(c); err != nil {
+		return err
+	}
+	if h.manager == nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "channel manager not configured")
+	}
+	return c.JSON(http.StatusOK, InboundStatsResponse{Items: h.manager.InboundStats(c.QueryParam("bot_id"))})
+}
+
+func (h *ChannelHandler) requireAdmin(c echo.Context) error {
+	userID, err := h.requireUserID(c)
+	if err != nil {
+		return err
+	}
+	if h.userService == nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "user service not configured")
+	}
+	isAdmin, err := h.userService.IsAdmin(c.Request().Context(), userID)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+	if !isAdmin {
+		return echo.NewHTTPError(http.StatusForbidden, "admin role required")
+	}
+	return nil
+}
+
 func (h *ChannelHandler) requireUserID(c echo.Context) (string, error) {
 	userID, err := auth.UserIDFromContext(c)
 	if err != nil {
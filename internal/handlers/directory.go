@@ -0,0 +1,240 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/memohai/memoh/internal/auth"
+	"github.com/memohai/memoh/internal/bots"
+	"github.com/memohai/memoh/internal/channel"
+	"github.com/memohai/memoh/internal/directory"
+	"github.com/memohai/memoh/internal/identity"
+	"github.com/memohai/memoh/internal/users"
+)
+
+type DirectoryHandler struct {
+	service     *directory.Service
+	registry    *channel.Registry
+	botService  *bots.Service
+	userService *users.Service
+}
+
+func NewDirectoryHandler(service *directory.Service, registry *channel.Registry, botService *bots.Service, userService *users.Service) *DirectoryHandler {
+	return &DirectoryHandler{
+		service:     service,
+		registry:    registry,
+		botService:  botService,
+		userService: userService,
+	}
+}
+
+func (h *DirectoryHandler) Register(e *echo.Echo) {
+	group := e.Group("/bots/:id/channel/:platform/directory")
+	group.GET("/peers", h.ListPeers)
+	group.GET("/groups", h.ListGroups)
+	group.GET("/groups/:group_id/members", h.ListGroupMembers)
+	group.GET("/resolve", h.Resolve)
+}
+
+type DirectoryListResponse struct {
+	Items []channel.DirectoryEntry `json:"items"`
+}
+
+// ListPeers godoc
+// @Summary List channel directory peers
+// @Description List users the bot can reach on a channel, from the platform or from known contacts
+// @Tags bots
+// @Param id path string true "Bot ID"
+// @Param platform path string true "Channel platform"
+// @Param q query string false "Name, handle or ID filter"
+// @Param limit query int false "Maximum number of entries"
+// @Success 200 {object} DirectoryListResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /bots/{id}/channel/{platform}/directory/peers [get]
+func (h *DirectoryHandler) ListPeers(c echo.Context) error {
+	botID, channelType, limit, err := h.parseRequest(c)
+	if err != nil {
+		return err
+	}
+	items, err := h.service.ListPeers(c.Request().Context(), botID, channelType, c.QueryParam("q"), limit)
+	if err != nil {
+		return directoryError(err)
+	}
+	return c.JSON(http.StatusOK, DirectoryListResponse{Items: items})
+}
+
+// ListGroups godoc
+// @Summary List channel directory groups
+// @Description List groups the bot is in on a channel, from the platform or from known sessions
+// @Tags bots
+// @Param id path string true "Bot ID"
+// @Param platform path string true "Channel platform"
+// @Param q query string false "Name or ID filter"
+// @Param limit query int false "Maximum number of entries"
+// @Success 200 {object} DirectoryListResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /bots/{id}/channel/{platform}/directory/groups [get]
+func (h *DirectoryHandler) ListGroups(c echo.Context) error {
+	botID, channelType, limit, err := h.parseRequest(c)
+	if err != nil {
+		return err
+	}
+	items, err := h.service.ListGroups(c.Request().Context(), botID, channelType, c.QueryParam("q"), limit)
+	if err != nil {
+		return directoryError(err)
+	}
+	return c.JSON(http.StatusOK, DirectoryListResponse{Items: items})
+}
+
+// ListGroupMembers godoc
+// @Summary List channel group members
+// @Description List members of a group on a channel, where the platform allows it
+// @Tags bots
+// @Param id path string true "Bot ID"
+// @Param platform path string true "Channel platform"
+// @Param group_id path string true "Group ID"
+// @Param q query string false "Name filter"
+// @Param limit query int false "Maximum number of entries"
+// @Success 200 {object} DirectoryListResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Failure 501 {object} ErrorResponse
+// @Router /bots/{id}/channel/{platform}/directory/groups/{group_id}/members [get]
+func (h *DirectoryHandler) ListGroupMembers(c echo.Context) error {
+	botID, channelType, limit, err := h.parseRequest(c)
+	if err != nil {
+		return err
+	}
+	groupID := strings.TrimSpace(c.Param("group_id"))
+	if groupID == "" {
+		return echo.NewHTTPError(http.StatusBadRequest, "group id is required")
+	}
+	items, err := h.service.ListGroupMembers(c.Request().Context(), botID, channelType, groupID, c.QueryParam("q"), limit)
+	if err != nil {
+		return directoryError(err)
+	}
+	return c.JSON(http.StatusOK, DirectoryListResponse{Items: items})
+}
+
+// Resolve godoc
+// @Summary Resolve a channel target
+// @Description Resolve a name, handle or ID to a directory entry whose ID can be used as a send target
+// @Tags bots
+// @Param id path string true "Bot ID"
+// @Param platform path string true "Channel platform"
+// @Param input query string true "Name, handle or ID to resolve"
+// @Param kind query string false "Entry kind: user or group"
+// @Success 200 {object} channel.DirectoryEntry
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /bots/{id}/channel/{platform}/directory/resolve [get]
+func (h *DirectoryHandler) Resolve(c echo.Context) error {
+	botID, channelType, _, err := h.parseRequest(c)
+	if err != nil {
+		return err
+	}
+	input := strings.TrimSpace(c.QueryParam("input"))
+	if input == "" {
+		return echo.NewHTTPError(http.StatusBadRequest, "input is required")
+	}
+	kind := channel.DirectoryEntryKind(strings.ToLower(strings.TrimSpace(c.QueryParam("kind"))))
+	if kind != "" && kind != channel.DirectoryEntryUser && kind != channel.DirectoryEntryGroup {
+		return echo.NewHTTPError(http.StatusBadRequest, "kind must be user or group")
+	}
+	entry, err := h.service.ResolveTarget(c.Request().Context(), botID, channelType, input, kind)
+	if err != nil {
+		return directoryError(err)
+	}
+	return c.JSON(http.StatusOK, entry)
+}
+
+func (h *DirectoryHandler) parseRequest(c echo.Context) (string, channel.ChannelType, int, error) {
+	userID, err := h.requireUserID(c)
+	if err != nil {
+		return "", "", 0, err
+	}
+	botID := strings.TrimSpace(c.Param("id"))
+	if botID == "" {
+		return "", "", 0, echo.NewHTTPError(http.StatusBadRequest, "bot id is required")
+	}
+	if _, err := h.authorizeBotAccess(c.Request().Context(), userID, botID); err != nil {
+		return "", "", 0, err
+	}
+	if h.service == nil || h.registry == nil {
+		return "", "", 0, echo.NewHTTPError(http.StatusInternalServerError, "directory service not configured")
+	}
+	channelType, err := h.registry.ParseChannelType(c.Param("platform"))
+	if err != nil {
+		return "", "", 0, echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	limit := 0
+	if raw := strings.TrimSpace(c.QueryParam("limit")); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil || parsed < 0 {
+			return "", "", 0, echo.NewHTTPError(http.StatusBadRequest, "invalid limit")
+		}
+		limit = parsed
+	}
+	return botID, channelType, limit, nil
+}
+
+func directoryError(err error) error {
+	switch {
+	case errors.Is(err, directory.ErrNotFound):
+		return echo.NewHTTPError(http.StatusNotFound, err.Error())
+	case errors.Is(err, directory.ErrAmbiguous):
+		return echo.NewHTTPError(http.StatusConflict, err.Error())
+	case errors.Is(err, directory.ErrUnsupported):
+		return echo.NewHTTPError(http.StatusNotImplemented, err.Error())
+	default:
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+}
+
+func (h *DirectoryHandler) requireUserID(c echo.Context) (string, error) {
+	userID, err := auth.UserIDFromContext(c)
+	if err != nil {
+		return "", err
+	}
+	if err := identity.ValidateUserID(userID); err != nil {
+		return "", echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	return userID, nil
+}
+
+func (h *DirectoryHandler) authorizeBotAccess(ctx context.Context, actorID, botID string) (bots.Bot, error) {
+	if h.botService == nil || h.userService == nil {
+		return bots.Bot{}, echo.NewHTTPError(http.StatusInternalServerError, "bot services not configured")
+	}
+	isAdmin, err := h.userService.IsAdmin(ctx, actorID)
+	if err != nil {
+		return bots.Bot{}, echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+	bot, err := h.botService.AuthorizeAccess(ctx, actorID, botID, isAdmin, bots.AccessPolicy{AllowPublicMember: false})
+	if err != nil {
+		if errors.Is(err, bots.ErrBotNotFound) {
+			return bots.Bot{}, echo.NewHTTPError(http.StatusNotFound, "bot not found")
+		}
+		if errors.Is(err, bots.ErrBotAccessDenied) {
+			return bots.Bot{}, echo.NewHTTPError(http.StatusForbidden, "bot access denied")
+		}
+		return bots.Bot{}, echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+	return bot, nil
+}
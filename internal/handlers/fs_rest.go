@@ -1,15 +1,18 @@
 package handlers
 
 import (
+	"context"
 	"errors"
 	"io"
 	"net/http"
 	"os"
+	"path"
 	"path/filepath"
 	"strconv"
 	"strings"
 	"time"
 
+	"github.com/google/uuid"
 	"github.com/labstack/echo/v4"
 
 	"github.com/memohai/memoh/internal/config"
@@ -517,6 +520,33 @@ func (h *ContainerdHandler) ensureBotDataRoot(botID string) (string, error) {
 	return root, nil
 }
 
+// SaveAttachment writes an inbound attachment under the bot data root and returns
+// its path inside the container.
+func (h *ContainerdHandler) SaveAttachment(ctx context.Context, botID, name string, data []byte) (string, error) {
+	root, err := h.ensureBotDataRoot(botID)
+	if err != nil {
+		return "", err
+	}
+	day := time.Now().UTC().Format("20060102")
+	dir := filepath.Join(root, "attachments", day)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", err
+	}
+	base := strings.TrimSpace(filepath.Base(filepath.FromSlash(strings.ReplaceAll(name, "\\", "/"))))
+	if base == "" || base == "." || base == ".." || base == string(filepath.Separator) {
+		base = "attachment"
+	}
+	fileName := uuid.NewString()[:8] + "-" + base
+	if err := os.WriteFile(filepath.Join(dir, fileName), data, 0o644); err != nil {
+		return "", err
+	}
+	dataMount := strings.TrimSpace(h.cfg.DataMount)
+	if dataMount == "" {
+		dataMount = config.DefaultDataMount
+	}
+	return path.Join(dataMount, "attachments", day, fileName), nil
+}
+
 func resolveBotPath(root, requestPath string, allowRoot bool) (string, string, error) {
 	raw := strings.TrimSpace(requestPath)
 	if raw == "" {
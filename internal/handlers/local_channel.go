@@ -126,6 +126,13 @@ func (h *LocalChannelHandler) StreamSession(c echo.Context) error {
 				"target":  msg.Target,
 				"message": msg.Message,
 			}
+			if state, ok := local.PresenceState(msg); ok {
+				payload = map[string]any{
+					"type":   "presence",
+					"target": msg.Target,
+					"state":  state,
+				}
+			}
 			data, err := json.Marshal(payload)
 			if err != nil {
 				continue
@@ -139,6 +146,8 @@ func (h *LocalChannelHandler) StreamSession(c echo.Context) error {
 
 type localMessageRequest struct {
 	Message channel.Message `json:"message"`
+	// Action reports a press on a button of an earlier bot message instead of a typed message.
+	Action *channel.InboundAction `json:"action,omitempty"`
 }
 
 func (h *LocalChannelHandler) PostMessage(c echo.Context) error {
@@ -167,8 +176,11 @@ func (h *LocalChannelHandler) PostMessage(c echo.Context) error {
 	if err := c.Bind(&req); err != nil {
 		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
 	}
+	if req.Action != nil && strings.TrimSpace(req.Action.Value) == "" {
+		return echo.NewHTTPError(http.StatusBadRequest, "action value is required")
+	}
 	text := strings.TrimSpace(req.Message.PlainText())
-	if text == "" {
+	if text == "" && req.Action == nil {
 		return echo.NewHTTPError(http.StatusBadRequest, "message is required")
 	}
 	cfg, err := h.channelService.ResolveEffectiveConfig(c.Request().Context(), botID, h.channelType)
@@ -193,6 +205,7 @@ func (h *LocalChannelHandler) PostMessage(c echo.Context) error {
 		},
 		ReceivedAt: time.Now().UTC(),
 		Source:     "local",
+		Action:     req.Action,
 	}
 	if err := h.channelManager.HandleInbound(c.Request().Context(), cfg, msg); err != nil {
 		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
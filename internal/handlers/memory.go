@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"log/slog"
 	"net/http"
+	"strconv"
 	"strings"
 
 	"github.com/labstack/echo/v4"
@@ -28,6 +29,8 @@ type memoryAddPayload struct {
 	Message          string           `json:"message,omitempty"`
 	Messages         []memory.Message `json:"messages,omitempty"`
 	RunID            string           `json:"run_id,omitempty"`
+	ContactID        string           `json:"contact_id,omitempty"`
+	Scope            string           `json:"scope,omitempty"`
 	Metadata         map[string]any   `json:"metadata,omitempty"`
 	Filters          map[string]any   `json:"filters,omitempty"`
 	Infer            *bool            `json:"infer,omitempty"`
@@ -37,6 +40,7 @@ type memoryAddPayload struct {
 type memorySearchPayload struct {
 	Query            string         `json:"query"`
 	RunID            string         `json:"run_id,omitempty"`
+	ContactID        string         `json:"contact_id,omitempty"`
 	Limit            int            `json:"limit,omitempty"`
 	Filters          map[string]any `json:"filters,omitempty"`
 	Sources          []string       `json:"sources,omitempty"`
@@ -44,20 +48,31 @@ type memorySearchPayload struct {
 }
 
 type memoryEmbedUpsertPayload struct {
-	Type     string            `json:"type"`
-	Provider string            `json:"provider,omitempty"`
-	Model    string            `json:"model,omitempty"`
-	Input    memory.EmbedInput `json:"input"`
-	Source   string            `json:"source,omitempty"`
-	RunID    string            `json:"run_id,omitempty"`
-	Metadata map[string]any    `json:"metadata,omitempty"`
-	Filters  map[string]any    `json:"filters,omitempty"`
+	Type      string            `json:"type"`
+	Provider  string            `json:"provider,omitempty"`
+	Model     string            `json:"model,omitempty"`
+	Input     memory.EmbedInput `json:"input"`
+	Source    string            `json:"source,omitempty"`
+	RunID     string            `json:"run_id,omitempty"`
+	ContactID string            `json:"contact_id,omitempty"`
+	Scope     string            `json:"scope,omitempty"`
+	Metadata  map[string]any    `json:"metadata,omitempty"`
+	Filters   map[string]any    `json:"filters,omitempty"`
 }
 
 type memoryDeleteAllPayload struct {
 	RunID string `json:"run_id,omitempty"`
 }
 
+type memoryRestorePayload struct {
+	HistoryEntryID   string `json:"history_entry_id"`
+	EmbeddingEnabled *bool  `json:"embedding_enabled,omitempty"`
+}
+
+type memoryReindexPayload struct {
+	ModelID string `json:"model_id"`
+}
+
 func NewMemoryHandler(log *slog.Logger, service *memory.Service, botService *bots.Service, userService *users.Service) *MemoryHandler {
 	return &MemoryHandler{
 		service:     service,
@@ -74,9 +89,13 @@ func (h *MemoryHandler) Register(e *echo.Echo) {
 	group.POST("/search", h.Search)
 	group.POST("/update", h.Update)
 	group.GET("/memories/:memoryId", h.Get)
+	group.GET("/memories/:memoryId/history", h.History)
+	group.POST("/memories/:memoryId/restore", h.Restore)
 	group.GET("/memories", h.GetAll)
 	group.DELETE("/memories/:memoryId", h.Delete)
 	group.DELETE("/memories", h.DeleteAll)
+	group.GET("/reindex", h.ReindexStatus)
+	group.POST("/reindex", h.Reindex)
 }
 
 func (h *MemoryHandler) checkService() error {
@@ -130,6 +149,8 @@ func (h *MemoryHandler) EmbedUpsert(c echo.Context) error {
 		BotID:     botID,
 		SessionID: sessionID,
 		RunID:     payload.RunID,
+		ContactID: payload.ContactID,
+		Scope:     payload.Scope,
 		Metadata:  payload.Metadata,
 		Filters:   payload.Filters,
 	}
@@ -182,6 +203,8 @@ func (h *MemoryHandler) Add(c echo.Context) error {
 		BotID:            botID,
 		SessionID:        sessionID,
 		RunID:            payload.RunID,
+		ContactID:        payload.ContactID,
+		Scope:            payload.Scope,
 		Metadata:         payload.Metadata,
 		Filters:          payload.Filters,
 		Infer:            payload.Infer,
@@ -235,6 +258,7 @@ func (h *MemoryHandler) Search(c echo.Context) error {
 		BotID:            botID,
 		SessionID:        sessionID,
 		RunID:            payload.RunID,
+		ContactID:        payload.ContactID,
 		Limit:            payload.Limit,
 		Filters:          payload.Filters,
 		Sources:          payload.Sources,
@@ -485,6 +509,192 @@ func (h *MemoryHandler) DeleteAll(c echo.Context) error {
 	return c.JSON(http.StatusOK, resp)
 }
 
+// History godoc
+// @Summary Get memory history
+// @Description List the changes made to a memory, most recent first. Auth: Bearer JWT determines user_id (sub or user_id).
+// @Tags memory
+// @Param bot_id path string true "Bot ID"
+// @Param memoryId path string true "Memory ID"
+// @Param limit query int false "Maximum number of changes"
+// @Success 200 {object} memory.HistoryResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /bots/{bot_id}/memory/memories/{memoryId}/history [get]
+func (h *MemoryHandler) History(c echo.Context) error {
+	if err := h.checkService(); err != nil {
+		return err
+	}
+
+	userID, err := h.requireUserID(c)
+	if err != nil {
+		return err
+	}
+	botID := strings.TrimSpace(c.Param("bot_id"))
+	if botID == "" {
+		return echo.NewHTTPError(http.StatusBadRequest, "bot id is required")
+	}
+	if _, err := h.authorizeBotAccess(c.Request().Context(), userID, botID); err != nil {
+		return err
+	}
+
+	memoryID := c.Param("memoryId")
+	if memoryID == "" {
+		return echo.NewHTTPError(http.StatusBadRequest, "memory ID required")
+	}
+	limit := 0
+	if raw := strings.TrimSpace(c.QueryParam("limit")); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, "invalid limit")
+		}
+		limit = parsed
+	}
+
+	resp, err := h.service.History(c.Request().Context(), botID, memoryID, limit)
+	if err != nil {
+		return memoryHistoryError(err)
+	}
+	return c.JSON(http.StatusOK, resp)
+}
+
+// Restore godoc
+// @Summary Restore memory
+// @Description Restore a memory to a version in its history. A deleted memory is restored to its text before the deletion. Auth: Bearer JWT determines user_id (sub or user_id).
+// @Tags memory
+// @Param bot_id path string true "Bot ID"
+// @Param memoryId path string true "Memory ID"
+// @Param payload body memoryRestorePayload true "Restore request"
+// @Success 200 {object} memory.MemoryItem
+// @Failure 400 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /bots/{bot_id}/memory/memories/{memoryId}/restore [post]
+func (h *MemoryHandler) Restore(c echo.Context) error {
+	if err := h.checkService(); err != nil {
+		return err
+	}
+
+	userID, err := h.requireUserID(c)
+	if err != nil {
+		return err
+	}
+	botID := strings.TrimSpace(c.Param("bot_id"))
+	if botID == "" {
+		return echo.NewHTTPError(http.StatusBadRequest, "bot id is required")
+	}
+	if _, err := h.authorizeBotAccess(c.Request().Context(), userID, botID); err != nil {
+		return err
+	}
+
+	memoryID := c.Param("memoryId")
+	if memoryID == "" {
+		return echo.NewHTTPError(http.StatusBadRequest, "memory ID required")
+	}
+	var payload memoryRestorePayload
+	if err := c.Bind(&payload); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	if strings.TrimSpace(payload.HistoryEntryID) == "" {
+		return echo.NewHTTPError(http.StatusBadRequest, "history_entry_id is required")
+	}
+
+	resp, err := h.service.Restore(c.Request().Context(), memory.RestoreRequest{
+		BotID:            botID,
+		MemoryID:         memoryID,
+		HistoryEntryID:   payload.HistoryEntryID,
+		EmbeddingEnabled: payload.EmbeddingEnabled,
+	})
+	if err != nil {
+		return memoryHistoryError(err)
+	}
+	return c.JSON(http.StatusOK, resp)
+}
+
+// Reindex godoc
+// @Summary Re-embed memories
+// @Description Re-embed the memories of the bot with an embedding model in the background. Searches switch to the model once every memory is embedded. Auth: Bearer JWT determines user_id (sub or user_id).
+// @Tags memory
+// @Param bot_id path string true "Bot ID"
+// @Param payload body memoryReindexPayload true "Reindex request"
+// @Success 202 {object} memory.ReindexJob
+// @Failure 400 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /bots/{bot_id}/memory/reindex [post]
+func (h *MemoryHandler) Reindex(c echo.Context) error {
+	if err := h.checkService(); err != nil {
+		return err
+	}
+
+	userID, err := h.requireUserID(c)
+	if err != nil {
+		return err
+	}
+	botID := strings.TrimSpace(c.Param("bot_id"))
+	if botID == "" {
+		return echo.NewHTTPError(http.StatusBadRequest, "bot id is required")
+	}
+	if _, err := h.authorizeBotAccess(c.Request().Context(), userID, botID); err != nil {
+		return err
+	}
+
+	var payload memoryReindexPayload
+	if err := c.Bind(&payload); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	if strings.TrimSpace(payload.ModelID) == "" {
+		return echo.NewHTTPError(http.StatusBadRequest, "model_id is required")
+	}
+
+	job, err := h.service.StartReindex(c.Request().Context(), memory.ReindexRequest{
+		BotID:   botID,
+		ModelID: payload.ModelID,
+	})
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+	return c.JSON(http.StatusAccepted, job)
+}
+
+// ReindexStatus godoc
+// @Summary Get memory re-embedding status
+// @Description Get the embedding model the memories of the bot are searched with and the progress of its latest re-embedding job. Auth: Bearer JWT determines user_id (sub or user_id).
+// @Tags memory
+// @Param bot_id path string true "Bot ID"
+// @Success 200 {object} memory.ReindexStatusResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /bots/{bot_id}/memory/reindex [get]
+func (h *MemoryHandler) ReindexStatus(c echo.Context) error {
+	if err := h.checkService(); err != nil {
+		return err
+	}
+
+	userID, err := h.requireUserID(c)
+	if err != nil {
+		return err
+	}
+	botID := strings.TrimSpace(c.Param("bot_id"))
+	if botID == "" {
+		return echo.NewHTTPError(http.StatusBadRequest, "bot id is required")
+	}
+	if _, err := h.authorizeBotAccess(c.Request().Context(), userID, botID); err != nil {
+		return err
+	}
+
+	resp, err := h.service.ReindexStatus(c.Request().Context(), botID)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+	return c.JSON(http.StatusOK, resp)
+}
+
+// memoryHistoryError reports a memory of another bot as forbidden.
+func memoryHistoryError(err error) error {
+	if errors.Is(err, memory.ErrBotMismatch) {
+		return echo.NewHTTPError(http.StatusForbidden, "bot mismatch")
+	}
+	return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+}
+
 func (h *MemoryHandler) requireUserID(c echo.Context) (string, error) {
 	userID, err := auth.UserIDFromContext(c)
 	if err != nil {
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"log/slog"
 	"net/http"
+	"strconv"
 	"strings"
 
 	"github.com/jackc/pgx/v5"
@@ -65,6 +66,10 @@ func (h *UsersHandler) Register(e *echo.Echo) {
 	botGroup.PUT("/:id/channel/:platform", h.UpsertBotChannelConfig)
 	botGroup.POST("/:id/channel/:platform/send", h.SendBotMessage)
 	botGroup.POST("/:id/channel/:platform/send_session", h.SendBotMessageSession)
+	botGroup.GET("/:id/channel/:platform/messages", h.ListBotChannelMessages)
+	botGroup.PUT("/:id/channel/:platform/messages/:message_id", h.EditBotChannelMessage)
+	botGroup.DELETE("/:id/channel/:platform/messages/:message_id", h.UnsendBotChannelMessage)
+	botGroup.GET("/:id/channel/:platform/status", h.GetBotChannelStatus)
 }
 
 // GetMe godoc
@@ -733,7 +738,7 @@ func (h *UsersHandler) UpsertBotChannelConfig(c echo.Context) error {
 // @Param id path string true "Bot ID"
 // @Param platform path string true "Channel platform"
 // @Param payload body channel.SendRequest true "Send payload"
-// @Success 200 {object} map[string]string
+// @Success 200 {object} channel.OutboundRecord
 // @Failure 400 {object} ErrorResponse
 // @Failure 403 {object} ErrorResponse
 // @Failure 404 {object} ErrorResponse
@@ -765,10 +770,11 @@ func (h *UsersHandler) SendBotMessage(c echo.Context) error {
 	if req.Message.IsEmpty() {
 		return echo.NewHTTPError(http.StatusBadRequest, "message is required")
 	}
-	if err := h.channelManager.Send(c.Request().Context(), botID, channelType, req); err != nil {
+	record, err := h.channelManager.Send(c.Request().Context(), botID, channelType, req)
+	if err != nil {
 		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
 	}
-	return c.JSON(http.StatusOK, map[string]string{"status": "ok"})
+	return c.JSON(http.StatusOK, record)
 }
 
 // SendBotMessageSession godoc
@@ -778,7 +784,7 @@ func (h *UsersHandler) SendBotMessage(c echo.Context) error {
 // @Param id path string true "Bot ID"
 // @Param platform path string true "Channel platform"
 // @Param payload body channel.SendRequest true "Send payload"
-// @Success 200 {object} map[string]string
+// @Success 200 {object} channel.OutboundRecord
 // @Failure 400 {object} ErrorResponse
 // @Failure 401 {object} ErrorResponse
 // @Failure 403 {object} ErrorResponse
@@ -813,13 +819,208 @@ func (h *UsersHandler) SendBotMessageSession(c echo.Context) error {
 	if strings.TrimSpace(sessionToken.ReplyTarget) == "" {
 		return echo.NewHTTPError(http.StatusBadRequest, "reply target missing")
 	}
-	if err := h.channelManager.Send(c.Request().Context(), botID, channelType, channel.SendRequest{
-		Target:  sessionToken.ReplyTarget,
-		Message: req.Message,
-	}); err != nil {
+	record, err := h.channelManager.Send(c.Request().Context(), botID, channelType, channel.SendRequest{
+		Target:    sessionToken.ReplyTarget,
+		SessionID: sessionToken.SessionID,
+		Message:   req.Message,
+	})
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	return c.JSON(http.StatusOK, record)
+}
+
+// BotChannelMessagesResponse lists outbound messages of a bot channel.
+type BotChannelMessagesResponse struct {
+	Items []channel.OutboundRecord `json:"items"`
+}
+
+// ListBotChannelMessages godoc
+// @Summary List outbound messages of a bot channel
+// @Description List the most recent messages the bot sent on a channel with their platform message IDs and delivery status
+// @Tags bots
+// @Param id path string true "Bot ID"
+// @Param platform path string true "Channel platform"
+// @Param session_id query string false "Filter by session ID"
+// @Param status query string false "Filter by status (sent, failed, edited, deleted)"
+// @Param limit query int false "Maximum number of messages (default 100, max 500)"
+// @Success 200 {object} BotChannelMessagesResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /bots/{id}/channel/{platform}/messages [get]
+func (h *UsersHandler) ListBotChannelMessages(c echo.Context) error {
+	botID, channelType, err := h.resolveBotChannel(c)
+	if err != nil {
+		return err
+	}
+	limit := 0
+	if raw := strings.TrimSpace(c.QueryParam("limit")); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil || parsed < 0 {
+			return echo.NewHTTPError(http.StatusBadRequest, "invalid limit")
+		}
+		limit = parsed
+	}
+	items, err := h.channelService.ListOutbound(c.Request().Context(), botID, channelType, c.QueryParam("session_id"), c.QueryParam("status"), limit)
+	if err != nil {
+		if strings.Contains(err.Error(), "invalid") {
+			return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		}
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+	return c.JSON(http.StatusOK, BotChannelMessagesResponse{Items: items})
+}
+
+// EditBotChannelMessage godoc
+// @Summary Edit a message sent via bot channel
+// @Description Replace the content of a delivered message, identified by its outbound message ID
+// @Tags bots
+// @Param id path string true "Bot ID"
+// @Param platform path string true "Channel platform"
+// @Param message_id path string true "Outbound message ID"
+// @Param payload body channel.EditMessageRequest true "Edit payload"
+// @Success 200 {object} channel.OutboundRecord
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 501 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /bots/{id}/channel/{platform}/messages/{message_id} [put]
+func (h *UsersHandler) EditBotChannelMessage(c echo.Context) error {
+	botID, channelType, err := h.resolveBotChannel(c)
+	if err != nil {
+		return err
+	}
+	if h.channelManager == nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "channel manager not configured")
+	}
+	var req channel.EditMessageRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	if req.Message.IsEmpty() {
+		return echo.NewHTTPError(http.StatusBadRequest, "message is required")
+	}
+	record, err := h.channelManager.EditMessage(c.Request().Context(), botID, channelType, c.Param("message_id"), req.Message)
+	if err != nil {
+		return outboundMessageError(err)
+	}
+	return c.JSON(http.StatusOK, record)
+}
+
+// UnsendBotChannelMessage godoc
+// @Summary Unsend a message sent via bot channel
+// @Description Delete a delivered message from the platform, identified by its outbound message ID
+// @Tags bots
+// @Param id path string true "Bot ID"
+// @Param platform path string true "Channel platform"
+// @Param message_id path string true "Outbound message ID"
+// @Success 200 {object} channel.OutboundRecord
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 501 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /bots/{id}/channel/{platform}/messages/{message_id} [delete]
+func (h *UsersHandler) UnsendBotChannelMessage(c echo.Context) error {
+	botID, channelType, err := h.resolveBotChannel(c)
+	if err != nil {
+		return err
+	}
+	if h.channelManager == nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "channel manager not configured")
+	}
+	record, err := h.channelManager.UnsendMessage(c.Request().Context(), botID, channelType, c.Param("message_id"))
+	if err != nil {
+		return outboundMessageError(err)
+	}
+	return c.JSON(http.StatusOK, record)
+}
+
+// resolveBotChannel authorizes the caller on the bot and parses the channel platform.
+// BotChannelStatusResponse is the connection health of a bot channel with its recent
+// lifecycle events, newest first.
+type BotChannelStatusResponse struct {
+	Status channel.ConnectionStatus  `json:"status"`
+	Events []channel.ConnectionEvent `json:"events"`
+}
+
+// GetBotChannelStatus godoc
+// @Summary Get bot channel connection status
+// @Description Get the connection state, last error, last inbound and outbound times and restart count of a bot channel, with its recent connection events
+// @Tags bots
+// @Param id path string true "Bot ID"
+// @Param platform path string true "Channel platform"
+// @Param limit query int false "Maximum number of events (default 20, max 500)"
+// @Success 200 {object} BotChannelStatusResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /bots/{id}/channel/{platform}/status [get]
+func (h *UsersHandler) GetBotChannelStatus(c echo.Context) error {
+	botID, channelType, err := h.resolveBotChannel(c)
+	if err != nil {
+		return err
+	}
+	if h.channelManager == nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "channel manager not configured")
+	}
+	limit := 0
+	if raw := strings.TrimSpace(c.QueryParam("limit")); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil || parsed < 0 {
+			return echo.NewHTTPError(http.StatusBadRequest, "invalid limit")
+		}
+		limit = parsed
+	}
+	cfg, err := h.channelService.ResolveEffectiveConfig(c.Request().Context(), botID, channelType)
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			return echo.NewHTTPError(http.StatusNotFound, err.Error())
+		}
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+	events, err := h.channelService.ListConnectionEvents(c.Request().Context(), cfg.ID, limit)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+	return c.JSON(http.StatusOK, BotChannelStatusResponse{
+		Status: h.channelManager.ConnectionStatus(cfg),
+		Events: events,
+	})
+}
+
+func (h *UsersHandler) resolveBotChannel(c echo.Context) (string, channel.ChannelType, error) {
+	actorID, err := h.requireUserID(c)
+	if err != nil {
+		return "", "", err
+	}
+	botID := strings.TrimSpace(c.Param("id"))
+	if botID == "" {
+		return "", "", echo.NewHTTPError(http.StatusBadRequest, "bot id is required")
+	}
+	if _, err := h.authorizeBotAccess(c.Request().Context(), actorID, botID); err != nil {
+		return "", "", err
+	}
+	channelType, err := h.registry.ParseChannelType(c.Param("platform"))
+	if err != nil {
+		return "", "", echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	return botID, channelType, nil
+}
+
+func outboundMessageError(err error) error {
+	switch {
+	case errors.Is(err, channel.ErrOutboundNotFound):
+		return echo.NewHTTPError(http.StatusNotFound, err.Error())
+	case errors.Is(err, channel.ErrEditNotSupported), errors.Is(err, channel.ErrUnsendNotSupported):
+		return echo.NewHTTPError(http.StatusNotImplemented, err.Error())
+	default:
 		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
 	}
-	return c.JSON(http.StatusOK, map[string]string{"status": "ok"})
 }
 
 func (h *UsersHandler) authorizeBotAccess(ctx context.Context, actorID, botID string) (bots.Bot, error) {
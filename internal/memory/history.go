@@ -0,0 +1,207 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+)
+
+const (
+	HistoryEventAdd    = "ADD"
+	HistoryEventUpdate = "UPDATE"
+	HistoryEventDelete = "DELETE"
+
+	defaultHistoryLimit = 50
+	maxHistoryLimit     = 500
+)
+
+// ErrBotMismatch is returned when a memory or its history belongs to another bot than the
+// one it is accessed through.
+var ErrBotMismatch = errors.New("memory belongs to another bot")
+
+// HistoryStore persists the changes made to memories.
+type HistoryStore interface {
+	RecordHistory(ctx context.Context, entry HistoryEntry) error
+	ListHistory(ctx context.Context, memoryID string, limit int) ([]HistoryEntry, error)
+	GetHistory(ctx context.Context, id string) (HistoryEntry, error)
+}
+
+// changeSource is what made a change to a memory: the conversation and history record the
+// memory was learned from, the LLM model that decided the change, or the history entry it
+// restored.
+type changeSource struct {
+	sessionID    string
+	historyID    string
+	model        string
+	restoredFrom string
+}
+
+// SetHistoryStore keeps the history of memory changes in the store.
+func (s *Service) SetHistoryStore(store HistoryStore) {
+	s.history = store
+}
+
+// History returns the changes made to a memory of the bot, most recent first.
+func (s *Service) History(ctx context.Context, botID, memoryID string, limit int) (HistoryResponse, error) {
+	botID = strings.TrimSpace(botID)
+	if botID == "" {
+		return HistoryResponse{}, fmt.Errorf("bot_id is required")
+	}
+	memoryID = strings.TrimSpace(memoryID)
+	if memoryID == "" {
+		return HistoryResponse{}, fmt.Errorf("memory_id is required")
+	}
+	if s.history == nil {
+		return HistoryResponse{}, fmt.Errorf("memory history not configured")
+	}
+	if limit <= 0 {
+		limit = defaultHistoryLimit
+	}
+	limit = min(limit, maxHistoryLimit)
+	entries, err := s.history.ListHistory(ctx, memoryID, limit)
+	if err != nil {
+		return HistoryResponse{}, err
+	}
+	for _, entry := range entries {
+		if entry.BotID != botID {
+			return HistoryResponse{}, ErrBotMismatch
+		}
+	}
+	if entries == nil {
+		entries = []HistoryEntry{}
+	}
+	return HistoryResponse{Results: entries}, nil
+}
+
+// Restore brings a memory back to the text it had after a change in its history, or to the
+// text it had before it was deleted. A deleted memory is recreated with the same ID. Both the
+// history entry and the stored memory must belong to the bot of the request.
+func (s *Service) Restore(ctx context.Context, req RestoreRequest) (MemoryItem, error) {
+	botID := strings.TrimSpace(req.BotID)
+	if botID == "" {
+		return MemoryItem{}, fmt.Errorf("bot_id is required")
+	}
+	memoryID := strings.TrimSpace(req.MemoryID)
+	if memoryID == "" {
+		return MemoryItem{}, fmt.Errorf("memory_id is required")
+	}
+	if strings.TrimSpace(req.HistoryEntryID) == "" {
+		return MemoryItem{}, fmt.Errorf("history_entry_id is required")
+	}
+	if s.history == nil {
+		return MemoryItem{}, fmt.Errorf("memory history not configured")
+	}
+	if s.store == nil {
+		return MemoryItem{}, fmt.Errorf("vector store not configured")
+	}
+	entry, err := s.history.GetHistory(ctx, req.HistoryEntryID)
+	if err != nil {
+		return MemoryItem{}, err
+	}
+	if entry.MemoryID != memoryID {
+		return MemoryItem{}, fmt.Errorf("history entry does not belong to memory %s", memoryID)
+	}
+	if entry.BotID != botID {
+		return MemoryItem{}, ErrBotMismatch
+	}
+	text := entry.NewMemory
+	if entry.Event == HistoryEventDelete {
+		text = entry.OldMemory
+	}
+	if strings.TrimSpace(text) == "" {
+		return MemoryItem{}, fmt.Errorf("history entry has no memory to restore")
+	}
+
+	source := changeSource{restoredFrom: entry.ID}
+	existing, err := s.store.Get(ctx, memoryID)
+	if err != nil {
+		return MemoryItem{}, err
+	}
+	if existing != nil {
+		if existingBotID, _ := existing.Payload["botId"].(string); existingBotID != botID {
+			return MemoryItem{}, ErrBotMismatch
+		}
+		return s.update(ctx, UpdateRequest{
+			MemoryID:         memoryID,
+			Memory:           text,
+			EmbeddingEnabled: req.EmbeddingEnabled,
+		}, source)
+	}
+	return s.recreate(ctx, entry, text, req.EmbeddingEnabled != nil && *req.EmbeddingEnabled, source)
+}
+
+// recreate stores a deleted memory again with its ID and the payload kept in its history.
+func (s *Service) recreate(ctx context.Context, entry HistoryEntry, text string, embeddingEnabled bool, source changeSource) (MemoryItem, error) {
+	if s.bm25 == nil {
+		return MemoryItem{}, fmt.Errorf("bm25 indexer not configured")
+	}
+	lang, err := s.detectLanguage(ctx, text)
+	if err != nil {
+		return MemoryItem{}, err
+	}
+	termFreq, docLen, err := s.bm25.TermFrequencies(lang, text)
+	if err != nil {
+		return MemoryItem{}, err
+	}
+	sparseIndices, sparseValues := s.bm25.AddDocument(lang, termFreq, docLen)
+	payload := make(map[string]any, len(entry.Payload)+4)
+	for key, value := range entry.Payload {
+		payload[key] = value
+	}
+	payload["data"] = text
+	payload["hash"] = hashMemory(text)
+	payload["lang"] = lang
+	payload["updatedAt"] = time.Now().UTC().Format(time.RFC3339)
+	if _, ok := payload["createdAt"]; !ok {
+		payload["createdAt"] = payload["updatedAt"]
+	}
+	point := VectorPoint{
+		ID:               entry.MemoryID,
+		SparseIndices:    sparseIndices,
+		SparseValues:     sparseValues,
+		SparseVectorName: s.store.SparseVectorName(),
+		Payload:          payload,
+	}
+	botID, _ := payload["botId"].(string)
+	if embeddingEnabled {
+		vector, vectorName, err := s.embedText(ctx, botID, text)
+		if err != nil {
+			return MemoryItem{}, err
+		}
+		point.Vector = vector
+		point.VectorName = vectorName
+	}
+	if err := s.upsertPoint(ctx, point); err != nil {
+		return MemoryItem{}, err
+	}
+	s.mirrorReindex(ctx, botID, entry.MemoryID, text, payload)
+	s.recordHistory(ctx, HistoryEventAdd, entry.MemoryID, "", text, payload, source)
+	return payloadToMemoryItem(entry.MemoryID, payload), nil
+}
+
+// recordHistory keeps a change made to a memory. The change is already applied, so failing
+// to record it is only logged.
+func (s *Service) recordHistory(ctx context.Context, event, memoryID, oldText, newText string, payload map[string]any, source changeSource) {
+	if s.history == nil {
+		return
+	}
+	botID, _ := payload["botId"].(string)
+	err := s.history.RecordHistory(context.WithoutCancel(ctx), HistoryEntry{
+		MemoryID:     memoryID,
+		BotID:        botID,
+		Event:        event,
+		OldMemory:    oldText,
+		NewMemory:    newText,
+		Payload:      payload,
+		SessionID:    source.sessionID,
+		HistoryID:    source.historyID,
+		Model:        source.model,
+		RestoredFrom: source.restoredFrom,
+	})
+	if err != nil && s.logger != nil {
+		s.logger.Warn("record memory history failed", slog.String("memory_id", memoryID), slog.String("event", event), slog.Any("error", err))
+	}
+}
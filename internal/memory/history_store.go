@@ -0,0 +1,123 @@
+package memory
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"github.com/memohai/memoh/internal/db"
+	"github.com/memohai/memoh/internal/db/sqlc"
+)
+
+// DBHistoryStore keeps the history of memory changes in the memory_history table.
+type DBHistoryStore struct {
+	queries *sqlc.Queries
+}
+
+func NewDBHistoryStore(queries *sqlc.Queries) *DBHistoryStore {
+	return &DBHistoryStore{queries: queries}
+}
+
+func (s *DBHistoryStore) RecordHistory(ctx context.Context, entry HistoryEntry) error {
+	if s.queries == nil {
+		return fmt.Errorf("memory history queries not configured")
+	}
+	payload := []byte("{}")
+	if entry.Payload != nil {
+		encoded, err := json.Marshal(entry.Payload)
+		if err != nil {
+			return err
+		}
+		payload = encoded
+	}
+	historyID, err := optionalUUID(entry.HistoryID)
+	if err != nil {
+		return err
+	}
+	restoredFrom, err := optionalUUID(entry.RestoredFrom)
+	if err != nil {
+		return err
+	}
+	_, err = s.queries.CreateMemoryHistory(ctx, sqlc.CreateMemoryHistoryParams{
+		MemoryID:     entry.MemoryID,
+		BotID:        entry.BotID,
+		Event:        entry.Event,
+		OldMemory:    entry.OldMemory,
+		NewMemory:    entry.NewMemory,
+		Payload:      payload,
+		SessionID:    entry.SessionID,
+		HistoryID:    historyID,
+		Model:        entry.Model,
+		RestoredFrom: restoredFrom,
+	})
+	return err
+}
+
+func (s *DBHistoryStore) ListHistory(ctx context.Context, memoryID string, limit int) ([]HistoryEntry, error) {
+	if s.queries == nil {
+		return nil, fmt.Errorf("memory history queries not configured")
+	}
+	rows, err := s.queries.ListMemoryHistory(ctx, sqlc.ListMemoryHistoryParams{
+		MemoryID: memoryID,
+		MaxItems: int32(limit),
+	})
+	if err != nil {
+		return nil, err
+	}
+	entries := make([]HistoryEntry, 0, len(rows))
+	for _, row := range rows {
+		entries = append(entries, historyEntryFromRow(row))
+	}
+	return entries, nil
+}
+
+func (s *DBHistoryStore) GetHistory(ctx context.Context, id string) (HistoryEntry, error) {
+	if s.queries == nil {
+		return HistoryEntry{}, fmt.Errorf("memory history queries not configured")
+	}
+	pgID, err := db.ParseUUID(id)
+	if err != nil {
+		return HistoryEntry{}, err
+	}
+	row, err := s.queries.GetMemoryHistory(ctx, pgID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return HistoryEntry{}, fmt.Errorf("history entry not found")
+		}
+		return HistoryEntry{}, err
+	}
+	return historyEntryFromRow(row), nil
+}
+
+func historyEntryFromRow(row sqlc.MemoryHistory) HistoryEntry {
+	var payload map[string]any
+	if len(row.Payload) > 0 {
+		_ = json.Unmarshal(row.Payload, &payload)
+	}
+	return HistoryEntry{
+		ID:           db.UUIDToString(row.ID),
+		MemoryID:     row.MemoryID,
+		BotID:        row.BotID,
+		Event:        row.Event,
+		OldMemory:    row.OldMemory,
+		NewMemory:    row.NewMemory,
+		Payload:      payload,
+		SessionID:    row.SessionID,
+		HistoryID:    db.UUIDToString(row.HistoryID),
+		Model:        row.Model,
+		RestoredFrom: db.UUIDToString(row.RestoredFrom),
+		CreatedAt:    db.TimeFromPg(row.CreatedAt),
+	}
+}
+
+func optionalUUID(id string) (pgtype.UUID, error) {
+	if strings.TrimSpace(id) == "" {
+		return pgtype.UUID{}, nil
+	}
+	return db.ParseUUID(id)
+}
@@ -0,0 +1,279 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sort"
+	"sync"
+	"testing"
+)
+
+// memStore 是只用于测试的内存版 VectorStore，SearchSparse 返回全部记忆。
+// named 为 true 时按模型保存多个命名向量，只按字符串字段过滤；
+// dims 不为空时只接受其中列出的向量，模拟创建时就固定了向量的 Qdrant 集合
+type memStore struct {
+	mu          sync.Mutex
+	points      map[string]VectorPoint
+	vectors     map[string]map[string][]float32
+	named       bool
+	dims        map[string]int
+	searchedFor string
+}
+
+func newMemStore() *memStore {
+	return &memStore{points: map[string]VectorPoint{}, vectors: map[string]map[string][]float32{}}
+}
+
+func (m *memStore) Upsert(ctx context.Context, points []VectorPoint) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, point := range points {
+		m.points[point.ID] = point
+		// 与 Qdrant 一样，写入会替换点的全部向量
+		m.vectors[point.ID] = map[string][]float32{}
+		if len(point.Vector) > 0 {
+			m.vectors[point.ID][point.VectorName] = point.Vector
+		}
+	}
+	return nil
+}
+
+func (m *memStore) UpdateVectors(ctx context.Context, points []VectorPoint) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, point := range points {
+		if vectors, ok := m.vectors[point.ID]; ok {
+			vectors[point.VectorName] = point.Vector
+		}
+	}
+	return nil
+}
+
+func (m *memStore) vector(id, name string) []float32 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.vectors[id][name]
+}
+
+func (m *memStore) Search(ctx context.Context, vector []float32, limit int, filters map[string]any, vectorName string) ([]VectorPoint, []float64, error) {
+	m.mu.Lock()
+	m.searchedFor = vectorName
+	m.mu.Unlock()
+	return nil, nil, nil
+}
+
+func (m *memStore) SearchSparse(ctx context.Context, indices []uint32, values []float32, limit int, filters map[string]any) ([]VectorPoint, []float64, error) {
+	points, _ := m.List(ctx, limit, filters)
+	return points, make([]float64, len(points)), nil
+}
+
+func (m *memStore) Get(ctx context.Context, id string) (*VectorPoint, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	point, ok := m.points[id]
+	if !ok {
+		return nil, nil
+	}
+	payload := make(map[string]any, len(point.Payload))
+	for key, value := range point.Payload {
+		payload[key] = value
+	}
+	point.Payload = payload
+	return &point, nil
+}
+
+func (m *memStore) Delete(ctx context.Context, id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.points, id)
+	delete(m.vectors, id)
+	return nil
+}
+
+func (m *memStore) List(ctx context.Context, limit int, filters map[string]any) ([]VectorPoint, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	ids := make([]string, 0, len(m.points))
+	for id := range m.points {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	points := make([]VectorPoint, 0, len(ids))
+next:
+	for _, id := range ids {
+		for key, value := range filters {
+			if want, ok := value.(string); ok && m.points[id].Payload[key] != want {
+				continue next
+			}
+		}
+		points = append(points, m.points[id])
+	}
+	return points, nil
+}
+
+func (m *memStore) Scroll(ctx context.Context, limit int, filters map[string]any, offset string) ([]VectorPoint, string, error) {
+	points, err := m.List(ctx, limit, filters)
+	return points, "", err
+}
+
+func (m *memStore) DeleteAll(ctx context.Context, filters map[string]any) error {
+	points, _ := m.List(ctx, 0, filters)
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, point := range points {
+		delete(m.points, point.ID)
+		delete(m.vectors, point.ID)
+	}
+	return nil
+}
+
+func (m *memStore) AcceptsVector(name string, dimensions int) bool {
+	if m.dims == nil {
+		return m.named
+	}
+	dim, ok := m.dims[name]
+	return m.named && ok && (dimensions <= 0 || dim == dimensions)
+}
+
+func (m *memStore) UsesNamedVectors() bool   { return m.named }
+func (m *memStore) SparseVectorName() string { return "" }
+
+// memHistory 按写入顺序保存记忆的变更，List 时最新的在前
+type memHistory struct {
+	entries []HistoryEntry
+}
+
+func (m *memHistory) RecordHistory(ctx context.Context, entry HistoryEntry) error {
+	entry.ID = fmt.Sprintf("h%d", len(m.entries)+1)
+	m.entries = append(m.entries, entry)
+	return nil
+}
+
+func (m *memHistory) ListHistory(ctx context.Context, memoryID string, limit int) ([]HistoryEntry, error) {
+	var entries []HistoryEntry
+	for i := len(m.entries) - 1; i >= 0 && len(entries) < limit; i-- {
+		if m.entries[i].MemoryID == memoryID {
+			entries = append(entries, m.entries[i])
+		}
+	}
+	return entries, nil
+}
+
+func (m *memHistory) GetHistory(ctx context.Context, id string) (HistoryEntry, error) {
+	for _, entry := range m.entries {
+		if entry.ID == id {
+			return entry, nil
+		}
+	}
+	return HistoryEntry{}, fmt.Errorf("history entry not found")
+}
+
+func TestService_AddRecordsHistory(t *testing.T) {
+	ctx := context.Background()
+	store := newMemStore()
+	history := &memHistory{}
+	decisions := []DecideResponse{
+		{Actions: []DecisionAction{{Event: "ADD", Text: "User likes tea"}}, Model: "gpt-test"},
+	}
+	llm := &MockLLM{
+		ExtractFunc: func(ctx context.Context, req ExtractRequest) (ExtractResponse, error) {
+			return ExtractResponse{Facts: []string{"fact"}}, nil
+		},
+		DecideFunc: func(ctx context.Context, req DecideRequest) (DecideResponse, error) {
+			resp := decisions[0]
+			decisions = decisions[1:]
+			return resp, nil
+		},
+		DetectLanguageFunc: func(ctx context.Context, text string) (string, error) {
+			return "en", nil
+		},
+	}
+	s := NewService(slog.Default(), llm, nil, store, nil, NewBM25Indexer(nil), "", "")
+	s.SetHistoryStore(history)
+
+	added, err := s.Add(ctx, AddRequest{Message: "I like tea", BotID: "bot-1", SessionID: "s1", HistoryID: "r1"})
+	if err != nil {
+		t.Fatalf("add failed: %v", err)
+	}
+	id := added.Results[0].ID
+
+	// 第二轮对话把记忆改掉，再删除
+	decisions = append(decisions,
+		DecideResponse{Actions: []DecisionAction{{Event: "UPDATE", ID: id, Text: "User likes coffee"}}, Model: "gpt-test"},
+		DecideResponse{Actions: []DecisionAction{{Event: "DELETE", ID: id}}, Model: "gpt-test"},
+	)
+	if _, err := s.Add(ctx, AddRequest{Message: "Now I like coffee", BotID: "bot-1", SessionID: "s2", HistoryID: "r2"}); err != nil {
+		t.Fatalf("update failed: %v", err)
+	}
+	if _, err := s.Add(ctx, AddRequest{Message: "Forget it", BotID: "bot-1", SessionID: "s2", HistoryID: "r3"}); err != nil {
+		t.Fatalf("delete failed: %v", err)
+	}
+
+	resp, err := s.History(ctx, "bot-1", id, 0)
+	if err != nil {
+		t.Fatalf("history failed: %v", err)
+	}
+	if len(resp.Results) != 3 {
+		t.Fatalf("应记录三次变更，实际: %+v", resp.Results)
+	}
+	deleted, updated, created := resp.Results[0], resp.Results[1], resp.Results[2]
+	if created.Event != HistoryEventAdd || created.NewMemory != "User likes tea" || created.SessionID != "s1" || created.HistoryID != "r1" || created.Model != "gpt-test" || created.BotID != "bot-1" {
+		t.Fatalf("unexpected add entry: %+v", created)
+	}
+	if updated.Event != HistoryEventUpdate || updated.OldMemory != "User likes tea" || updated.NewMemory != "User likes coffee" || updated.HistoryID != "r2" {
+		t.Fatalf("unexpected update entry: %+v", updated)
+	}
+	if deleted.Event != HistoryEventDelete || deleted.OldMemory != "User likes coffee" || deleted.HistoryID != "r3" {
+		t.Fatalf("unexpected delete entry: %+v", deleted)
+	}
+
+	// 删除后恢复到第一次的版本，记忆以原 ID 重新写入
+	restored, err := s.Restore(ctx, RestoreRequest{BotID: "bot-1", MemoryID: id, HistoryEntryID: created.ID})
+	if err != nil {
+		t.Fatalf("restore failed: %v", err)
+	}
+	if restored.ID != id || restored.Memory != "User likes tea" || restored.BotID != "bot-1" || restored.SessionID != "s1" {
+		t.Fatalf("unexpected restored memory: %+v", restored)
+	}
+	latest := history.entries[len(history.entries)-1]
+	if latest.Event != HistoryEventAdd || latest.RestoredFrom != created.ID {
+		t.Fatalf("restore should be recorded: %+v", latest)
+	}
+
+	// 恢复删除记录时取删除前的内容
+	restored, err = s.Restore(ctx, RestoreRequest{BotID: "bot-1", MemoryID: id, HistoryEntryID: deleted.ID})
+	if err != nil {
+		t.Fatalf("restore failed: %v", err)
+	}
+	if restored.Memory != "User likes coffee" {
+		t.Fatalf("expected the text before the deletion, got %q", restored.Memory)
+	}
+	latest = history.entries[len(history.entries)-1]
+	if latest.Event != HistoryEventUpdate || latest.OldMemory != "User likes tea" || latest.RestoredFrom != deleted.ID {
+		t.Fatalf("unexpected restore entry: %+v", latest)
+	}
+
+	if _, err := s.Restore(ctx, RestoreRequest{BotID: "bot-1", MemoryID: "other", HistoryEntryID: created.ID}); err == nil {
+		t.Fatal("restoring the history of another memory should fail")
+	}
+
+	// 其他机器人既不能查看也不能恢复这条记忆，缺少机器人时同样拒绝
+	if _, err := s.History(ctx, "bot-2", id, 0); !errors.Is(err, ErrBotMismatch) {
+		t.Fatalf("history of another bot should be rejected, got %v", err)
+	}
+	if _, err := s.History(ctx, "", id, 0); err == nil {
+		t.Fatal("history without a bot should fail")
+	}
+	before := len(history.entries)
+	if _, err := s.Restore(ctx, RestoreRequest{BotID: "bot-2", MemoryID: id, HistoryEntryID: created.ID}); !errors.Is(err, ErrBotMismatch) {
+		t.Fatalf("restore through another bot should be rejected, got %v", err)
+	}
+	if _, err := s.Restore(ctx, RestoreRequest{MemoryID: id, HistoryEntryID: created.ID}); err == nil {
+		t.Fatal("restore without a bot should fail")
+	}
+	if len(history.entries) != before {
+		t.Fatalf("rejected restores should not change the memory: %+v", history.entries[before:])
+	}
+}
@@ -127,7 +127,7 @@ func (c *LLMClient) Decide(ctx context.Context, req DecideRequest) (DecideRespon
 			OldMemory: asString(item["old_memory"]),
 		})
 	}
-	return DecideResponse{Actions: actions}, nil
+	return DecideResponse{Actions: actions, Model: c.model}, nil
 }
 
 func (c *LLMClient) DetectLanguage(ctx context.Context, text string) (string, error) {
@@ -0,0 +1,481 @@
+package memory
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// PgvectorStore keeps memory points in Postgres with the pgvector extension. Payloads are
+// JSONB, dense vectors live in memory_vectors (one row per vector name) and the BM25 sparse
+// vector is a sparsevec on the point, searched by inner product like the Qdrant sparse index.
+type PgvectorStore struct {
+	pool             *pgxpool.Pool
+	logger           *slog.Logger
+	vectorNames      map[string]int
+	usesNamedVectors bool
+	sparseVectorName string
+}
+
+// NewPgvectorStore returns a store on the memory tables of the database. Like
+// NewQdrantStoreWithVectors, a non-empty vectors map stores one named vector per model.
+func NewPgvectorStore(log *slog.Logger, pool *pgxpool.Pool, vectors map[string]int, sparseVectorName string) (*PgvectorStore, error) {
+	if pool == nil {
+		return nil, fmt.Errorf("postgres pool is required")
+	}
+	if strings.TrimSpace(sparseVectorName) == "" {
+		sparseVectorName = sparseHashVectorName
+	}
+	store := &PgvectorStore{
+		pool:             pool,
+		logger:           log.With(slog.String("store", "pgvector")),
+		vectorNames:      vectors,
+		usesNamedVectors: len(vectors) > 0,
+		sparseVectorName: strings.TrimSpace(sparseVectorName),
+	}
+	var ready bool
+	err := pool.QueryRow(context.Background(), `SELECT to_regclass('memory_points') IS NOT NULL AND to_regclass('memory_vectors') IS NOT NULL`).Scan(&ready)
+	if err != nil {
+		return nil, err
+	}
+	if !ready {
+		return nil, fmt.Errorf("memory vector tables missing; install the pgvector extension and run the migrations")
+	}
+	return store, nil
+}
+
+// UsesNamedVectors reports whether one named vector is stored per embedding model.
+func (s *PgvectorStore) UsesNamedVectors() bool {
+	return s.usesNamedVectors
+}
+
+// SparseVectorName returns the name of the BM25 sparse vector.
+func (s *PgvectorStore) SparseVectorName() string {
+	return s.sparseVectorName
+}
+
+// Upsert replaces points with their payload and vectors, as a Qdrant upsert does: vectors a
+// point is written without are dropped.
+func (s *PgvectorStore) Upsert(ctx context.Context, points []VectorPoint) error {
+	if len(points) == 0 {
+		return nil
+	}
+	for _, point := range points {
+		if len(point.Vector) == 0 && (len(point.SparseIndices) == 0 || len(point.SparseValues) == 0) {
+			return fmt.Errorf("no vector data provided for point %s", point.ID)
+		}
+	}
+	return pgx.BeginFunc(ctx, s.pool, func(tx pgx.Tx) error {
+		for _, point := range points {
+			payload, err := json.Marshal(point.Payload)
+			if err != nil {
+				return err
+			}
+			var sparse *string
+			if len(point.SparseIndices) > 0 && len(point.SparseValues) > 0 {
+				literal, err := sparseVectorLiteral(point.SparseIndices, point.SparseValues)
+				if err != nil {
+					return err
+				}
+				sparse = &literal
+			}
+			if _, err := tx.Exec(ctx, `
+INSERT INTO memory_points (id, payload, sparse)
+VALUES ($1::uuid, $2::jsonb, $3::sparsevec)
+ON CONFLICT (id) DO UPDATE SET payload = EXCLUDED.payload, sparse = EXCLUDED.sparse, updated_at = now()`,
+				point.ID, payload, sparse); err != nil {
+				return err
+			}
+			if _, err := tx.Exec(ctx, `DELETE FROM memory_vectors WHERE point_id = $1::uuid`, point.ID); err != nil {
+				return err
+			}
+			if len(point.Vector) == 0 {
+				continue
+			}
+			if _, err := tx.Exec(ctx, `INSERT INTO memory_vectors (point_id, name, embedding) VALUES ($1::uuid, $2, $3::vector)`,
+				point.ID, s.vectorName(point.VectorName), denseVectorLiteral(point.Vector)); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// UpdateVectors sets one named dense vector per point and keeps the other vectors.
+func (s *PgvectorStore) UpdateVectors(ctx context.Context, points []VectorPoint) error {
+	if len(points) == 0 {
+		return nil
+	}
+	for _, point := range points {
+		if len(point.Vector) == 0 {
+			return fmt.Errorf("no vector data provided for point %s", point.ID)
+		}
+	}
+	return pgx.BeginFunc(ctx, s.pool, func(tx pgx.Tx) error {
+		for _, point := range points {
+			if _, err := tx.Exec(ctx, `
+INSERT INTO memory_vectors (point_id, name, embedding)
+SELECT id, $2, $3::vector FROM memory_points WHERE id = $1::uuid
+ON CONFLICT (point_id, name) DO UPDATE SET embedding = EXCLUDED.embedding`,
+				point.ID, s.vectorName(point.VectorName), denseVectorLiteral(point.Vector)); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// AcceptsVector reports whether named vectors are stored. Dimensions are not constrained.
+func (s *PgvectorStore) AcceptsVector(name string, dimensions int) bool {
+	return s.usesNamedVectors
+}
+
+// Search ranks points by cosine similarity, scored 1 - cosine distance like Qdrant.
+func (s *PgvectorStore) Search(ctx context.Context, vector []float32, limit int, filters map[string]any, vectorName string) ([]VectorPoint, []float64, error) {
+	if limit <= 0 {
+		limit = 10
+	}
+	args := []any{denseVectorLiteral(vector), s.vectorName(vectorName), len(vector)}
+	where, args := buildPgvectorFilter(filters, args)
+	query := `
+SELECT p.id::text, p.payload, 1 - (v.embedding <=> $1::vector) AS score
+FROM memory_vectors v
+JOIN memory_points p ON p.id = v.point_id
+WHERE v.name = $2 AND vector_dims(v.embedding) = $3`
+	if where != "" {
+		query += " AND " + where
+	}
+	args = append(args, limit)
+	query += fmt.Sprintf("\nORDER BY v.embedding <=> $1::vector\nLIMIT $%d", len(args))
+	return s.queryScored(ctx, query, args...)
+}
+
+// SearchSparse ranks points by the inner product of their BM25 vector with the query vector.
+// Points sharing no term with the query are left out.
+func (s *PgvectorStore) SearchSparse(ctx context.Context, indices []uint32, values []float32, limit int, filters map[string]any) ([]VectorPoint, []float64, error) {
+	if limit <= 0 {
+		limit = 10
+	}
+	if len(indices) == 0 || len(values) == 0 {
+		return nil, nil, nil
+	}
+	literal, err := sparseVectorLiteral(indices, values)
+	if err != nil {
+		return nil, nil, err
+	}
+	args := []any{literal}
+	where, args := buildPgvectorFilter(filters, args)
+	// <#> is the negative inner product.
+	query := `
+SELECT p.id::text, p.payload, -(p.sparse <#> $1::sparsevec) AS score
+FROM memory_points p
+WHERE p.sparse IS NOT NULL AND (p.sparse <#> $1::sparsevec) < 0`
+	if where != "" {
+		query += " AND " + where
+	}
+	args = append(args, limit)
+	query += fmt.Sprintf("\nORDER BY p.sparse <#> $1::sparsevec\nLIMIT $%d", len(args))
+	return s.queryScored(ctx, query, args...)
+}
+
+func (s *PgvectorStore) Get(ctx context.Context, id string) (*VectorPoint, error) {
+	var (
+		pointID string
+		raw     []byte
+	)
+	err := s.pool.QueryRow(ctx, `SELECT id::text, payload FROM memory_points WHERE id = $1::uuid`, id).Scan(&pointID, &raw)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	payload, err := decodePgvectorPayload(raw)
+	if err != nil {
+		return nil, err
+	}
+	return &VectorPoint{ID: pointID, Payload: payload}, nil
+}
+
+func (s *PgvectorStore) Delete(ctx context.Context, id string) error {
+	_, err := s.pool.Exec(ctx, `DELETE FROM memory_points WHERE id = $1::uuid`, id)
+	return err
+}
+
+func (s *PgvectorStore) List(ctx context.Context, limit int, filters map[string]any) ([]VectorPoint, error) {
+	points, _, err := s.Scroll(ctx, limit, filters, "")
+	return points, err
+}
+
+func (s *PgvectorStore) Scroll(ctx context.Context, limit int, filters map[string]any, offset string) ([]VectorPoint, string, error) {
+	if limit <= 0 {
+		limit = 100
+	}
+	var args []any
+	conditions := []string{}
+	if offset != "" {
+		args = append(args, offset)
+		conditions = append(conditions, "p.id >= $1::uuid")
+	}
+	where, args := buildPgvectorFilter(filters, args)
+	if where != "" {
+		conditions = append(conditions, where)
+	}
+	query := `SELECT p.id::text, p.payload FROM memory_points p`
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	// One extra row tells whether there is a next page and where it starts.
+	args = append(args, limit+1)
+	query += fmt.Sprintf(" ORDER BY p.id LIMIT $%d", len(args))
+	rows, err := s.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, "", err
+	}
+	defer rows.Close()
+	points := make([]VectorPoint, 0, limit)
+	next := ""
+	for rows.Next() {
+		var (
+			id  string
+			raw []byte
+		)
+		if err := rows.Scan(&id, &raw); err != nil {
+			return nil, "", err
+		}
+		if len(points) == limit {
+			next = id
+			break
+		}
+		payload, err := decodePgvectorPayload(raw)
+		if err != nil {
+			return nil, "", err
+		}
+		points = append(points, VectorPoint{ID: id, Payload: payload})
+	}
+	if err := rows.Err(); err != nil {
+		return nil, "", err
+	}
+	return points, next, nil
+}
+
+func (s *PgvectorStore) DeleteAll(ctx context.Context, filters map[string]any) error {
+	where, args := buildPgvectorFilter(filters, nil)
+	if where == "" {
+		return fmt.Errorf("delete all requires filters")
+	}
+	_, err := s.pool.Exec(ctx, `DELETE FROM memory_points p WHERE `+where, args...)
+	return err
+}
+
+// vectorName returns the stored name of a dense vector. Without named vectors every point
+// has a single unnamed one.
+func (s *PgvectorStore) vectorName(name string) string {
+	if !s.usesNamedVectors {
+		return ""
+	}
+	return name
+}
+
+func (s *PgvectorStore) queryScored(ctx context.Context, query string, args ...any) ([]VectorPoint, []float64, error) {
+	rows, err := s.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+	points := []VectorPoint{}
+	scores := []float64{}
+	for rows.Next() {
+		var (
+			id    string
+			raw   []byte
+			score float64
+		)
+		if err := rows.Scan(&id, &raw, &score); err != nil {
+			return nil, nil, err
+		}
+		payload, err := decodePgvectorPayload(raw)
+		if err != nil {
+			return nil, nil, err
+		}
+		points = append(points, VectorPoint{ID: id, Payload: payload})
+		scores = append(scores, score)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, nil, err
+	}
+	return points, scores, nil
+}
+
+// buildPgvectorFilter turns filters into SQL conditions on the payload of p, binding values
+// as placeholders numbered after args. Dotted keys address nested payload fields, as in Qdrant.
+func buildPgvectorFilter(filters map[string]any, args []any) (string, []any) {
+	if len(filters) == 0 {
+		return "", args
+	}
+	keys := make([]string, 0, len(filters))
+	for key := range filters {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	conditions := make([]string, 0, len(keys))
+	for _, key := range keys {
+		var condition string
+		condition, args = buildPgvectorCondition(key, filters[key], args)
+		if condition != "" {
+			conditions = append(conditions, condition)
+		}
+	}
+	return strings.Join(conditions, " AND "), args
+}
+
+var pgvectorRangeOps = map[string]string{"gte": ">=", "gt": ">", "lte": "<=", "lt": "<"}
+
+func buildPgvectorCondition(key string, value any, args []any) (string, []any) {
+	jsonPath, textPath := pgvectorPayloadPath(key)
+	bind := func(v any) string {
+		args = append(args, v)
+		return fmt.Sprintf("$%d", len(args))
+	}
+	numeric := func(ops map[string]float64) string {
+		parts := make([]string, 0, len(ops))
+		for _, op := range []string{"gte", "gt", "lte", "lt"} {
+			v, ok := ops[op]
+			if !ok {
+				continue
+			}
+			sqlOp := pgvectorRangeOps[op]
+			parts = append(parts, fmt.Sprintf("(%s)::float8 %s %s::float8", textPath, sqlOp, bind(v)))
+		}
+		// The CASE keeps non-numeric values from being cast.
+		return fmt.Sprintf("(CASE WHEN jsonb_typeof(%s) = 'number' THEN %s ELSE false END)", jsonPath, strings.Join(parts, " AND "))
+	}
+	var condition string
+	switch typed := value.(type) {
+	case nil:
+		condition = fmt.Sprintf("COALESCE(%s, 'null'::jsonb) = 'null'::jsonb", jsonPath)
+	case filterAnyOf:
+		alternatives := make([]string, 0, len(typed))
+		bound := len(args)
+		for _, filters := range typed {
+			var where string
+			where, args = buildPgvectorFilter(filters, args)
+			if where == "" {
+				// An empty alternative matches every point.
+				return "", args[:bound]
+			}
+			alternatives = append(alternatives, "("+where+")")
+		}
+		if len(alternatives) == 0 {
+			return "false", args
+		}
+		condition = "(" + strings.Join(alternatives, " OR ") + ")"
+	case string:
+		condition = fmt.Sprintf("%s = %s", textPath, bind(typed))
+	case bool:
+		condition = fmt.Sprintf("%s = to_jsonb(%s::boolean)", jsonPath, bind(typed))
+	case int:
+		condition = fmt.Sprintf("%s = to_jsonb(%s::bigint)", jsonPath, bind(int64(typed)))
+	case int64:
+		condition = fmt.Sprintf("%s = to_jsonb(%s::bigint)", jsonPath, bind(typed))
+	case float32:
+		v := float64(typed)
+		condition = numeric(map[string]float64{"gte": v, "lte": v})
+	case float64:
+		condition = numeric(map[string]float64{"gte": typed, "lte": typed})
+	case map[string]any:
+		ops := map[string]float64{}
+		for _, op := range []string{"gte", "gt", "lte", "lt"} {
+			if raw, ok := typed[op]; ok {
+				if v, ok := toFloat(raw); ok {
+					ops[op] = v
+				}
+			}
+		}
+		if len(ops) > 0 {
+			condition = numeric(ops)
+		}
+	}
+	if condition == "" {
+		condition = fmt.Sprintf("%s = %s", textPath, bind(fmt.Sprint(value)))
+	}
+	return condition, args
+}
+
+// pgvectorPayloadPath returns the JSONB and text expressions of a payload field. Keys are
+// inlined rather than bound so that the expression indexes on botId, sessionId and runId apply.
+func pgvectorPayloadPath(key string) (string, string) {
+	segments := strings.Split(key, ".")
+	var b strings.Builder
+	b.WriteString("p.payload")
+	for _, segment := range segments[:len(segments)-1] {
+		b.WriteString("->")
+		b.WriteString(quotePgLiteral(segment))
+	}
+	last := quotePgLiteral(segments[len(segments)-1])
+	return b.String() + "->" + last, b.String() + "->>" + last
+}
+
+func quotePgLiteral(value string) string {
+	return "'" + strings.ReplaceAll(value, "'", "''") + "'"
+}
+
+func denseVectorLiteral(vector []float32) string {
+	var b strings.Builder
+	b.WriteByte('[')
+	for i, v := range vector {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		b.WriteString(strconv.FormatFloat(float64(v), 'g', -1, 32))
+	}
+	b.WriteByte(']')
+	return b.String()
+}
+
+// sparseVectorLiteral formats a BM25 vector as a sparsevec, whose indices start at 1.
+func sparseVectorLiteral(indices []uint32, values []float32) (string, error) {
+	if len(indices) != len(values) {
+		return "", fmt.Errorf("sparse vector has %d indices and %d values", len(indices), len(values))
+	}
+	order := make([]int, len(indices))
+	for i := range order {
+		order[i] = i
+	}
+	sort.Slice(order, func(a, b int) bool { return indices[order[a]] < indices[order[b]] })
+	var b strings.Builder
+	b.WriteByte('{')
+	for n, i := range order {
+		if indices[i] >= sparseDimSize {
+			return "", fmt.Errorf("sparse index %d out of range", indices[i])
+		}
+		if n > 0 {
+			b.WriteByte(',')
+		}
+		b.WriteString(strconv.FormatUint(uint64(indices[i])+1, 10))
+		b.WriteByte(':')
+		b.WriteString(strconv.FormatFloat(float64(values[i]), 'g', -1, 32))
+	}
+	b.WriteString("}/")
+	b.WriteString(strconv.Itoa(sparseDimSize))
+	return b.String(), nil
+}
+
+func decodePgvectorPayload(raw []byte) (map[string]any, error) {
+	payload := map[string]any{}
+	if len(raw) == 0 {
+		return payload, nil
+	}
+	if err := json.Unmarshal(raw, &payload); err != nil {
+		return nil, err
+	}
+	return payload, nil
+}
@@ -0,0 +1,307 @@
+package memory
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+func TestBuildPgvectorFilter(t *testing.T) {
+	t.Parallel()
+
+	where, args := buildPgvectorFilter(map[string]any{
+		"userId": "u1",
+		"score":  map[string]any{"gte": 0.5},
+	}, []any{"[0.1,0.2]"})
+	conditions := strings.Split(where, " AND ")
+	if len(conditions) != 2 {
+		t.Fatalf("expected two conditions, got %q", where)
+	}
+	if !strings.Contains(conditions[0], "p.payload->>'score'") || !strings.Contains(conditions[0], ">= $2::float8") {
+		t.Fatalf("unexpected range condition: %s", conditions[0])
+	}
+	if conditions[1] != "p.payload->>'userId' = $3" {
+		t.Fatalf("unexpected match condition: %s", conditions[1])
+	}
+	if len(args) != 3 || args[1] != 0.5 || args[2] != "u1" {
+		t.Fatalf("unexpected args: %v", args)
+	}
+
+	if where, _ := buildPgvectorFilter(nil, nil); where != "" {
+		t.Fatalf("expected no conditions, got %q", where)
+	}
+}
+
+func TestPgvectorPayloadPath(t *testing.T) {
+	t.Parallel()
+
+	jsonPath, textPath := pgvectorPayloadPath("metadata.it's")
+	if jsonPath != "p.payload->'metadata'->'it''s'" {
+		t.Fatalf("unexpected json path: %s", jsonPath)
+	}
+	if textPath != "p.payload->'metadata'->>'it''s'" {
+		t.Fatalf("unexpected text path: %s", textPath)
+	}
+}
+
+func TestPgvectorLiterals(t *testing.T) {
+	t.Parallel()
+
+	if got := denseVectorLiteral([]float32{0.5, -1, 2.25}); got != "[0.5,-1,2.25]" {
+		t.Fatalf("unexpected dense literal: %s", got)
+	}
+	got, err := sparseVectorLiteral([]uint32{7, 0}, []float32{0.25, 1.5})
+	if err != nil {
+		t.Fatalf("sparse literal: %v", err)
+	}
+	if got != "{1:1.5,8:0.25}/1048576" {
+		t.Fatalf("unexpected sparse literal: %s", got)
+	}
+	if _, err := sparseVectorLiteral([]uint32{1}, nil); err == nil {
+		t.Fatalf("expected mismatched lengths to fail")
+	}
+}
+
+// contractVectors 是契约测试使用的两个嵌入模型
+var contractVectors = map[string]int{"model-a": 3, "model-b": 3}
+
+// TestVectorStoreContract 对进程内的 fakeVectorStore、Qdrant 和 pgvector 运行同一组用例。
+// 后两者需要真实服务：MEMOH_TEST_QDRANT_URL 指向 Qdrant，MEMOH_TEST_POSTGRES_DSN 指向已执行迁移的 Postgres。
+func TestVectorStoreContract(t *testing.T) {
+	t.Run("fake", func(t *testing.T) {
+		runVectorStoreContract(t, newFakeVectorStore(contractVectors))
+	})
+	t.Run("qdrant", func(t *testing.T) {
+		baseURL := os.Getenv("MEMOH_TEST_QDRANT_URL")
+		if baseURL == "" {
+			t.Skip("跳过集成测试: 未设置 MEMOH_TEST_QDRANT_URL 环境变量")
+		}
+		collection := "memoh_contract_" + strings.ReplaceAll(uuid.NewString(), "-", "")
+		store, err := NewQdrantStoreWithVectors(slog.Default(), baseURL, os.Getenv("MEMOH_TEST_QDRANT_API_KEY"), collection, contractVectors, "", 10*time.Second)
+		if err != nil {
+			t.Fatalf("create qdrant store: %v", err)
+		}
+		t.Cleanup(func() {
+			_ = store.client.DeleteCollection(context.Background(), collection)
+		})
+		runVectorStoreContract(t, store)
+	})
+	t.Run("pgvector", func(t *testing.T) {
+		dsn := os.Getenv("MEMOH_TEST_POSTGRES_DSN")
+		if dsn == "" {
+			t.Skip("跳过集成测试: 未设置 MEMOH_TEST_POSTGRES_DSN 环境变量")
+		}
+		pool, err := pgxpool.New(context.Background(), dsn)
+		if err != nil {
+			t.Fatalf("connect postgres: %v", err)
+		}
+		t.Cleanup(pool.Close)
+		store, err := NewPgvectorStore(slog.Default(), pool, contractVectors, "")
+		if err != nil {
+			t.Fatalf("create pgvector store: %v", err)
+		}
+		runVectorStoreContract(t, store)
+	})
+}
+
+func runVectorStoreContract(t *testing.T, store VectorStore) {
+	ctx := context.Background()
+	botID := "contract-" + uuid.NewString()
+	t.Cleanup(func() {
+		_ = store.DeleteAll(context.Background(), map[string]any{"botId": botID})
+	})
+
+	ids := make([]string, 5)
+	for i := range ids {
+		ids[i] = uuid.NewString()
+	}
+	byID := map[string]string{}
+	for i, id := range ids {
+		byID[id] = string(rune('0' + i))
+	}
+	// names 把结果转成排好序的点编号，便于比较
+	names := func(points []VectorPoint) string {
+		items := make([]string, 0, len(points))
+		for _, point := range points {
+			items = append(items, byID[point.ID])
+		}
+		sort.Strings(items)
+		return strings.Join(items, ",")
+	}
+	withBot := func(filters map[string]any) map[string]any {
+		merged := map[string]any{"botId": botID}
+		for key, value := range filters {
+			merged[key] = value
+		}
+		return merged
+	}
+
+	points := []VectorPoint{
+		{ID: ids[0], Vector: []float32{1, 0, 0}, VectorName: "model-a", SparseIndices: []uint32{1, 2}, SparseValues: []float32{1, 1},
+			Payload: map[string]any{"botId": botID, "data": "tea", "kind": "fact", "score": 0.9, "pinned": true, "count": 3, "meta": map[string]any{"lang": "en"}}},
+		{ID: ids[1], Vector: []float32{0, 1, 0}, VectorName: "model-a", SparseIndices: []uint32{2, 3}, SparseValues: []float32{1, 1},
+			Payload: map[string]any{"botId": botID, "data": "coffee", "kind": "fact", "score": 0.4, "pinned": false, "count": 5, "meta": map[string]any{"lang": "zh"}, "runId": "r1"}},
+		{ID: ids[2], Vector: []float32{0, 0, 1}, VectorName: "model-a", SparseIndices: []uint32{4}, SparseValues: []float32{1},
+			Payload: map[string]any{"botId": botID, "data": "cat", "kind": "event", "score": 0.7, "pinned": false, "count": 3, "meta": map[string]any{"lang": "en"}, "runId": "r1"}},
+		{ID: ids[3], Vector: []float32{1, 1, 0}, VectorName: "model-a", SparseIndices: []uint32{5}, SparseValues: []float32{1},
+			Payload: map[string]any{"botId": botID, "data": "rain", "kind": "event", "score": 0.1, "count": 7}},
+		{ID: ids[4], Vector: []float32{0, 1, 1}, VectorName: "model-a", SparseIndices: []uint32{6}, SparseValues: []float32{1},
+			Payload: map[string]any{"botId": botID, "data": "music", "kind": "note", "score": 0.5, "count": 1}},
+	}
+	if err := store.Upsert(ctx, points); err != nil {
+		t.Fatalf("upsert: %v", err)
+	}
+
+	// 过滤语义
+	filterCases := []struct {
+		name    string
+		filters map[string]any
+		want    string
+	}{
+		{"string", map[string]any{"kind": "fact"}, "0,1"},
+		{"bool", map[string]any{"pinned": true}, "0"},
+		{"int", map[string]any{"count": 3}, "0,2"},
+		{"float", map[string]any{"score": 0.4}, "1"},
+		{"range", map[string]any{"score": map[string]any{"gte": 0.5}}, "0,2,4"},
+		{"open range", map[string]any{"score": map[string]any{"gt": 0.1, "lt": 0.7}}, "1,4"},
+		{"nested", map[string]any{"meta.lang": "en"}, "0,2"},
+		{"missing", map[string]any{"runId": nil}, "0,3,4"},
+		{"any of", map[string]any{"$any": filterAnyOf{{"runId": "r1", "kind": "event"}, {"count": 7}}}, "2,3"},
+		{"combined", map[string]any{"kind": "fact", "count": 5}, "1"},
+	}
+	for _, tc := range filterCases {
+		listed, err := store.List(ctx, 100, withBot(tc.filters))
+		if err != nil {
+			t.Fatalf("list %s: %v", tc.name, err)
+		}
+		if got := names(listed); got != tc.want {
+			t.Errorf("filter %s: got %q, want %q", tc.name, got, tc.want)
+		}
+	}
+
+	// 分页偏移
+	seen := map[string]bool{}
+	offset := ""
+	for page := 0; ; page++ {
+		if page > 5 {
+			t.Fatal("scroll did not end")
+		}
+		items, next, err := store.Scroll(ctx, 2, withBot(nil), offset)
+		if err != nil {
+			t.Fatalf("scroll: %v", err)
+		}
+		if len(items) > 2 {
+			t.Fatalf("page larger than the limit: %d", len(items))
+		}
+		for _, item := range items {
+			if seen[item.ID] {
+				t.Fatalf("point %s returned on two pages", item.ID)
+			}
+			seen[item.ID] = true
+		}
+		if next == "" {
+			break
+		}
+		if len(items) != 2 {
+			t.Fatalf("a short page must be the last one: %d points, next %q", len(items), next)
+		}
+		offset = next
+	}
+	if len(seen) != len(ids) {
+		t.Fatalf("scroll should return every point once, got %d", len(seen))
+	}
+
+	// 稠密与稀疏检索
+	found, scores, err := store.Search(ctx, []float32{1, 0, 0}, 2, withBot(nil), "model-a")
+	if err != nil {
+		t.Fatalf("search: %v", err)
+	}
+	if len(found) != 2 || len(scores) != 2 || found[0].ID != ids[0] || scores[0] < scores[1] {
+		t.Fatalf("search should rank the closest point first: %v %v", found, scores)
+	}
+	if found[0].Payload["data"] != "tea" {
+		t.Fatalf("search should return the payload: %v", found[0].Payload)
+	}
+	found, _, err = store.Search(ctx, []float32{1, 0, 0}, 10, withBot(map[string]any{"kind": "event"}), "model-a")
+	if err != nil {
+		t.Fatalf("filtered search: %v", err)
+	}
+	if got := names(found); got != "2,3" {
+		t.Fatalf("filtered search: got %q", got)
+	}
+	found, scores, err = store.SearchSparse(ctx, []uint32{2}, []float32{1}, 10, withBot(nil))
+	if err != nil {
+		t.Fatalf("sparse search: %v", err)
+	}
+	if got := names(found); got != "0,1" || len(scores) != 2 {
+		t.Fatalf("sparse search should match points sharing a term: %q", got)
+	}
+	if found, _, err = store.SearchSparse(ctx, []uint32{9}, []float32{1}, 10, withBot(nil)); err != nil || len(found) != 0 {
+		t.Fatalf("sparse search without shared terms: %v %v", found, err)
+	}
+
+	// 命名向量的更新与替换
+	if found, _, err = store.Search(ctx, []float32{0, 0, 1}, 10, withBot(nil), "model-b"); err != nil || len(found) != 0 {
+		t.Fatalf("no point has model-b yet: %v %v", found, err)
+	}
+	if err := store.UpdateVectors(ctx, []VectorPoint{{ID: ids[0], Vector: []float32{0, 0, 1}, VectorName: "model-b"}}); err != nil {
+		t.Fatalf("update vectors: %v", err)
+	}
+	if found, _, err = store.Search(ctx, []float32{0, 0, 1}, 10, withBot(nil), "model-b"); err != nil || names(found) != "0" {
+		t.Fatalf("updated point should be found by model-b: %v %v", found, err)
+	}
+	if found, _, err = store.Search(ctx, []float32{1, 0, 0}, 1, withBot(nil), "model-a"); err != nil || len(found) != 1 || found[0].ID != ids[0] {
+		t.Fatalf("update should keep the model-a vector: %v %v", found, err)
+	}
+	if found, _, err = store.SearchSparse(ctx, []uint32{1}, []float32{1}, 10, withBot(nil)); err != nil || names(found) != "0" {
+		t.Fatalf("update should keep the sparse vector: %v %v", found, err)
+	}
+	if point, err := store.Get(ctx, ids[0]); err != nil || point == nil || point.Payload["data"] != "tea" {
+		t.Fatalf("update should keep the payload: %v %v", point, err)
+	}
+
+	replaced := points[0]
+	replaced.SparseIndices, replaced.SparseValues = nil, nil
+	replaced.Payload = map[string]any{"botId": botID, "data": "green tea", "kind": "fact"}
+	if err := store.Upsert(ctx, []VectorPoint{replaced}); err != nil {
+		t.Fatalf("re-upsert: %v", err)
+	}
+	if found, _, err = store.Search(ctx, []float32{0, 0, 1}, 10, withBot(nil), "model-b"); err != nil || len(found) != 0 {
+		t.Fatalf("upsert should drop vectors the point is written without: %v %v", found, err)
+	}
+	if found, _, err = store.SearchSparse(ctx, []uint32{1}, []float32{1}, 10, withBot(nil)); err != nil || len(found) != 0 {
+		t.Fatalf("upsert should drop the sparse vector: %v %v", found, err)
+	}
+	point, err := store.Get(ctx, ids[0])
+	if err != nil || point == nil || point.Payload["data"] != "green tea" || point.Payload["pinned"] != nil {
+		t.Fatalf("upsert should replace the payload: %v %v", point, err)
+	}
+
+	// 删除
+	if err := store.Delete(ctx, ids[4]); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	if point, err := store.Get(ctx, ids[4]); err != nil || point != nil {
+		t.Fatalf("deleted point should be gone: %v %v", point, err)
+	}
+	if err := store.DeleteAll(ctx, withBot(map[string]any{"kind": "event"})); err != nil {
+		t.Fatalf("delete all: %v", err)
+	}
+	listed, err := store.List(ctx, 100, withBot(nil))
+	if err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	if got := names(listed); got != "0,1" {
+		t.Fatalf("delete all should remove matching points only: %q", got)
+	}
+	if err := store.DeleteAll(ctx, nil); err == nil {
+		t.Fatal("delete all without filters should fail")
+	}
+}
@@ -33,16 +33,6 @@ type QdrantStore struct {
 	usesSparseVectors bool
 }
 
-type qdrantPoint struct {
-	ID               string         `json:"id"`
-	Vector           []float32      `json:"vector"`
-	VectorName       string         `json:"vector_name,omitempty"`
-	SparseIndices    []uint32       `json:"sparse_indices,omitempty"`
-	SparseValues     []float32      `json:"sparse_values,omitempty"`
-	SparseVectorName string         `json:"sparse_vector_name,omitempty"`
-	Payload          map[string]any `json:"payload,omitempty"`
-}
-
 func NewQdrantStore(log *slog.Logger, baseURL, apiKey, collection string, dimension int, sparseVectorName string, timeout time.Duration) (*QdrantStore, error) {
 	host, port, useTLS, err := parseQdrantEndpoint(baseURL)
 	if err != nil {
@@ -89,6 +79,11 @@ func NewQdrantStore(log *slog.Logger, baseURL, apiKey, collection string, dimens
 	return store, nil
 }
 
+// Collection returns the name of the collection.
+func (s *QdrantStore) Collection() string {
+	return s.collection
+}
+
 func (s *QdrantStore) NewSibling(collection string, dimension int) (*QdrantStore, error) {
 	return NewQdrantStore(s.logger, s.baseURL, s.apiKey, collection, dimension, s.sparseVectorName, s.timeout)
 }
@@ -140,7 +135,17 @@ func NewQdrantStoreWithVectors(log *slog.Logger, baseURL, apiKey, collection str
 	return store, nil
 }
 
-func (s *QdrantStore) Upsert(ctx context.Context, points []qdrantPoint) error {
+// UsesNamedVectors reports whether the collection stores one named vector per embedding model.
+func (s *QdrantStore) UsesNamedVectors() bool {
+	return s.usesNamedVectors
+}
+
+// SparseVectorName returns the name of the BM25 sparse vector.
+func (s *QdrantStore) SparseVectorName() string {
+	return s.sparseVectorName
+}
+
+func (s *QdrantStore) Upsert(ctx context.Context, points []VectorPoint) error {
 	if len(points) == 0 {
 		return nil
 	}
@@ -172,7 +177,9 @@ func (s *QdrantStore) Upsert(ctx context.Context, points []qdrantPoint) error {
 			vectorMap[sparseName] = qdrant.NewVectorSparse(point.SparseIndices, point.SparseValues)
 		}
 		if vectors == nil {
-			if len(vectorMap) == 0 {
+			// Named vectors are optional, so a point whose dense vector is kept in a sibling
+			// collection can be stored without vectors.
+			if len(vectorMap) == 0 && !s.usesNamedVectors {
 				return fmt.Errorf("no vector data provided for point %s", point.ID)
 			}
 			vectors = qdrant.NewVectorsMap(vectorMap)
@@ -191,7 +198,42 @@ func (s *QdrantStore) Upsert(ctx context.Context, points []qdrantPoint) error {
 	return err
 }
 
-func (s *QdrantStore) Search(ctx context.Context, vector []float32, limit int, filters map[string]any, vectorName string) ([]qdrantPoint, []float64, error) {
+// UpdateVectors sets one named dense vector per point. Qdrant cannot add a vector to an
+// existing collection, so the name must be one of the vectors the collection was created with.
+func (s *QdrantStore) UpdateVectors(ctx context.Context, points []VectorPoint) error {
+	if len(points) == 0 {
+		return nil
+	}
+	qPoints := make([]*qdrant.PointVectors, 0, len(points))
+	for _, point := range points {
+		if len(point.Vector) == 0 || point.VectorName == "" {
+			return fmt.Errorf("named vector required for point %s", point.ID)
+		}
+		qPoints = append(qPoints, &qdrant.PointVectors{
+			Id: qdrant.NewIDUUID(point.ID),
+			Vectors: qdrant.NewVectorsMap(map[string]*qdrant.Vector{
+				point.VectorName: qdrant.NewVectorDense(point.Vector),
+			}),
+		})
+	}
+	_, err := s.client.UpdateVectors(ctx, &qdrant.UpdatePointVectors{
+		CollectionName: s.collection,
+		Wait:           qdrant.PtrOf(true),
+		Points:         qPoints,
+	})
+	return err
+}
+
+// AcceptsVector reports whether the collection has a named vector of the dimensions.
+func (s *QdrantStore) AcceptsVector(name string, dimensions int) bool {
+	if !s.usesNamedVectors {
+		return false
+	}
+	existing, ok := s.vectorNames[name]
+	return ok && (dimensions <= 0 || existing == dimensions)
+}
+
+func (s *QdrantStore) Search(ctx context.Context, vector []float32, limit int, filters map[string]any, vectorName string) ([]VectorPoint, []float64, error) {
 	if limit <= 0 {
 		limit = 10
 	}
@@ -212,10 +254,10 @@ func (s *QdrantStore) Search(ctx context.Context, vector []float32, limit int, f
 		return nil, nil, err
 	}
 
-	points := make([]qdrantPoint, 0, len(results))
+	points := make([]VectorPoint, 0, len(results))
 	scores := make([]float64, 0, len(results))
 	for _, scored := range results {
-		points = append(points, qdrantPoint{
+		points = append(points, VectorPoint{
 			ID:      pointIDToString(scored.GetId()),
 			Payload: valueMapToInterface(scored.GetPayload()),
 		})
@@ -224,7 +266,7 @@ func (s *QdrantStore) Search(ctx context.Context, vector []float32, limit int, f
 	return points, scores, nil
 }
 
-func (s *QdrantStore) SearchSparse(ctx context.Context, indices []uint32, values []float32, limit int, filters map[string]any) ([]qdrantPoint, []float64, error) {
+func (s *QdrantStore) SearchSparse(ctx context.Context, indices []uint32, values []float32, limit int, filters map[string]any) ([]VectorPoint, []float64, error) {
 	if limit <= 0 {
 		limit = 10
 	}
@@ -247,10 +289,10 @@ func (s *QdrantStore) SearchSparse(ctx context.Context, indices []uint32, values
 	if err != nil {
 		return nil, nil, err
 	}
-	points := make([]qdrantPoint, 0, len(results))
+	points := make([]VectorPoint, 0, len(results))
 	scores := make([]float64, 0, len(results))
 	for _, scored := range results {
-		points = append(points, qdrantPoint{
+		points = append(points, VectorPoint{
 			ID:      pointIDToString(scored.GetId()),
 			Payload: valueMapToInterface(scored.GetPayload()),
 		})
@@ -259,49 +301,7 @@ func (s *QdrantStore) SearchSparse(ctx context.Context, indices []uint32, values
 	return points, scores, nil
 }
 
-func (s *QdrantStore) SearchBySources(ctx context.Context, vector []float32, limit int, filters map[string]any, sources []string, vectorName string) (map[string][]qdrantPoint, map[string][]float64, error) {
-	pointsBySource := make(map[string][]qdrantPoint, len(sources))
-	scoresBySource := make(map[string][]float64, len(sources))
-	if len(sources) == 0 {
-		return pointsBySource, scoresBySource, nil
-	}
-	for _, source := range sources {
-		merged := cloneFilters(filters)
-		if source != "" {
-			merged["source"] = source
-		}
-		points, scores, err := s.Search(ctx, vector, limit, merged, vectorName)
-		if err != nil {
-			return nil, nil, err
-		}
-		pointsBySource[source] = points
-		scoresBySource[source] = scores
-	}
-	return pointsBySource, scoresBySource, nil
-}
-
-func (s *QdrantStore) SearchSparseBySources(ctx context.Context, indices []uint32, values []float32, limit int, filters map[string]any, sources []string) (map[string][]qdrantPoint, map[string][]float64, error) {
-	pointsBySource := make(map[string][]qdrantPoint, len(sources))
-	scoresBySource := make(map[string][]float64, len(sources))
-	if len(sources) == 0 {
-		return pointsBySource, scoresBySource, nil
-	}
-	for _, source := range sources {
-		merged := cloneFilters(filters)
-		if source != "" {
-			merged["source"] = source
-		}
-		points, scores, err := s.SearchSparse(ctx, indices, values, limit, merged)
-		if err != nil {
-			return nil, nil, err
-		}
-		pointsBySource[source] = points
-		scoresBySource[source] = scores
-	}
-	return pointsBySource, scoresBySource, nil
-}
-
-func (s *QdrantStore) Get(ctx context.Context, id string) (*qdrantPoint, error) {
+func (s *QdrantStore) Get(ctx context.Context, id string) (*VectorPoint, error) {
 	result, err := s.client.Get(ctx, &qdrant.GetPoints{
 		CollectionName: s.collection,
 		Ids:            []*qdrant.PointId{qdrant.NewIDUUID(id)},
@@ -314,7 +314,7 @@ func (s *QdrantStore) Get(ctx context.Context, id string) (*qdrantPoint, error)
 		return nil, nil
 	}
 	point := result[0]
-	return &qdrantPoint{
+	return &VectorPoint{
 		ID:      pointIDToString(point.GetId()),
 		Payload: valueMapToInterface(point.GetPayload()),
 	}, nil
@@ -329,7 +329,7 @@ func (s *QdrantStore) Delete(ctx context.Context, id string) error {
 	return err
 }
 
-func (s *QdrantStore) List(ctx context.Context, limit int, filters map[string]any) ([]qdrantPoint, error) {
+func (s *QdrantStore) List(ctx context.Context, limit int, filters map[string]any) ([]VectorPoint, error) {
 	if limit <= 0 {
 		limit = 100
 	}
@@ -344,9 +344,9 @@ func (s *QdrantStore) List(ctx context.Context, limit int, filters map[string]an
 		return nil, err
 	}
 
-	result := make([]qdrantPoint, 0, len(points))
+	result := make([]VectorPoint, 0, len(points))
 	for _, point := range points {
-		result = append(result, qdrantPoint{
+		result = append(result, VectorPoint{
 			ID:      pointIDToString(point.GetId()),
 			Payload: valueMapToInterface(point.GetPayload()),
 		})
@@ -354,29 +354,33 @@ func (s *QdrantStore) List(ctx context.Context, limit int, filters map[string]an
 	return result, nil
 }
 
-func (s *QdrantStore) Scroll(ctx context.Context, limit int, filters map[string]any, offset *qdrant.PointId) ([]qdrantPoint, *qdrant.PointId, error) {
+func (s *QdrantStore) Scroll(ctx context.Context, limit int, filters map[string]any, offset string) ([]VectorPoint, string, error) {
 	if limit <= 0 {
 		limit = 100
 	}
 	filter := buildQdrantFilter(filters)
+	var offsetID *qdrant.PointId
+	if offset != "" {
+		offsetID = qdrant.NewIDUUID(offset)
+	}
 	points, nextOffset, err := s.client.ScrollAndOffset(ctx, &qdrant.ScrollPoints{
 		CollectionName: s.collection,
 		Limit:          qdrant.PtrOf(uint32(limit)),
 		Filter:         filter,
-		Offset:         offset,
+		Offset:         offsetID,
 		WithPayload:    qdrant.NewWithPayload(true),
 	})
 	if err != nil {
-		return nil, nil, err
+		return nil, "", err
 	}
-	result := make([]qdrantPoint, 0, len(points))
+	result := make([]VectorPoint, 0, len(points))
 	for _, point := range points {
-		result = append(result, qdrantPoint{
+		result = append(result, VectorPoint{
 			ID:      pointIDToString(point.GetId()),
 			Payload: valueMapToInterface(point.GetPayload()),
 		})
 	}
-	return result, nextOffset, nil
+	return result, pointIDToString(nextOffset), nil
 }
 
 func (s *QdrantStore) DeleteAll(ctx context.Context, filters map[string]any) error {
@@ -506,7 +510,7 @@ func (s *QdrantStore) ensurePayloadIndexes(ctx context.Context) error {
 	if s.client == nil {
 		return nil
 	}
-	fields := []string{"botId", "sessionId", "runId"}
+	fields := []string{"botId", "sessionId", "contactId", "scope", "runId"}
 	wait := true
 	for _, field := range fields {
 		_, err := s.client.CreateFieldIndex(ctx, &qdrant.CreateFieldIndexCollection{
@@ -596,19 +600,21 @@ func buildQdrantFilter(filters map[string]any) *qdrant.Filter {
 	}
 }
 
-func cloneFilters(filters map[string]any) map[string]any {
-	if len(filters) == 0 {
-		return map[string]any{}
-	}
-	clone := make(map[string]any, len(filters))
-	for key, value := range filters {
-		clone[key] = value
-	}
-	return clone
-}
-
 func buildQdrantCondition(key string, value any) *qdrant.Condition {
 	switch typed := value.(type) {
+	case nil:
+		return qdrant.NewIsEmpty(key)
+	case filterAnyOf:
+		should := make([]*qdrant.Condition, 0, len(typed))
+		for _, filters := range typed {
+			filter := buildQdrantFilter(filters)
+			if filter == nil {
+				// An empty alternative matches every point.
+				return nil
+			}
+			should = append(should, qdrant.NewFilterAsCondition(filter))
+		}
+		return qdrant.NewFilterAsCondition(&qdrant.Filter{Should: should})
 	case string:
 		return qdrant.NewMatch(key, typed)
 	case bool:
@@ -0,0 +1,425 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/memohai/memoh/internal/embeddings"
+)
+
+const (
+	ReindexStatusRunning   = "running"
+	ReindexStatusCompleted = "completed"
+	ReindexStatusFailed    = "failed"
+	ReindexStatusCanceled  = "canceled"
+
+	reindexBatchSize = 64
+)
+
+// ReindexStore persists re-embedding jobs. The model of the latest completed job of a bot is
+// the embedding model its memories are searched with.
+type ReindexStore interface {
+	CreateReindexJob(ctx context.Context, botID, modelID string) (ReindexJob, error)
+	UpdateReindexProgress(ctx context.Context, job ReindexJob) error
+	FinishReindexJob(ctx context.Context, job ReindexJob) (ReindexJob, error)
+	LatestReindexJob(ctx context.Context, botID string) (*ReindexJob, error)
+	EmbeddingModel(ctx context.Context, botID string) (string, error)
+	ListRunningReindexJobs(ctx context.Context) ([]ReindexJob, error)
+}
+
+// reindexRun is a re-embedding job running in the background for a bot.
+type reindexRun struct {
+	job    ReindexJob
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// SetReindexStore enables re-embedding the memories of a bot with another embedding model.
+func (s *Service) SetReindexStore(store ReindexStore) {
+	s.reindex = store
+}
+
+// EmbeddingModelChanged re-embeds the memories of a bot when its embedding model changes to
+// one they are not embedded with yet.
+func (s *Service) EmbeddingModelChanged(ctx context.Context, botID, modelID string) {
+	modelID = strings.TrimSpace(modelID)
+	if s.reindex == nil || modelID == "" {
+		return
+	}
+	current := s.embeddingModel(ctx, botID)
+	if current == "" {
+		current = strings.TrimSpace(s.defaultTextModelID)
+	}
+	if modelID == current {
+		return
+	}
+	if _, err := s.StartReindex(ctx, ReindexRequest{BotID: botID, ModelID: modelID}); err != nil && s.logger != nil {
+		s.logger.Warn("start memory reindex failed", slog.String("bot_id", botID), slog.String("model_id", modelID), slog.Any("error", err))
+	}
+}
+
+// StartReindex starts re-embedding the memories of a bot with a model into the vector named
+// after it, or into the sibling collection of the model when the vector store has no such
+// vector (see SetSiblingStores), and returns the job. Searches and writes keep using the
+// current vector until every memory is embedded, then switch to the new one. A job already
+// running for the bot is canceled, unless it embeds with the same model.
+func (s *Service) StartReindex(ctx context.Context, req ReindexRequest) (ReindexJob, error) {
+	botID := strings.TrimSpace(req.BotID)
+	modelID := strings.TrimSpace(req.ModelID)
+	if botID == "" {
+		return ReindexJob{}, fmt.Errorf("bot_id is required")
+	}
+	if modelID == "" {
+		return ReindexJob{}, fmt.Errorf("model_id is required")
+	}
+	if s.reindex == nil {
+		return ReindexJob{}, fmt.Errorf("memory reindex not configured")
+	}
+	if s.store == nil {
+		return ReindexJob{}, fmt.Errorf("vector store not configured")
+	}
+	if s.resolver == nil {
+		return ReindexJob{}, fmt.Errorf("embeddings resolver not configured")
+	}
+	if !s.store.UsesNamedVectors() {
+		return ReindexJob{}, fmt.Errorf("vector store has a single vector; re-embedding needs one vector per embedding model")
+	}
+
+	// Held until the new run is registered, so two starts for a bot cannot both pass the
+	// check. reindexMu itself cannot be held while waiting: the canceled run takes it to
+	// unregister.
+	s.reindexStartMu.Lock()
+	defer s.reindexStartMu.Unlock()
+	s.reindexMu.Lock()
+	previous := s.reindexRuns[botID]
+	s.reindexMu.Unlock()
+	if previous != nil {
+		if previous.job.ModelID == modelID {
+			return previous.job, nil
+		}
+		previous.cancel()
+		<-previous.done
+	}
+
+	job, err := s.reindex.CreateReindexJob(ctx, botID, modelID)
+	if err != nil {
+		return ReindexJob{}, err
+	}
+	runCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	run := &reindexRun{job: job, cancel: cancel, done: make(chan struct{})}
+	s.reindexMu.Lock()
+	s.reindexRuns[botID] = run
+	s.reindexMu.Unlock()
+	go s.runReindex(runCtx, run)
+	return job, nil
+}
+
+// ReindexStatus returns the embedding model the memories of a bot are searched with and its
+// latest re-embedding job.
+func (s *Service) ReindexStatus(ctx context.Context, botID string) (ReindexStatusResponse, error) {
+	botID = strings.TrimSpace(botID)
+	if botID == "" {
+		return ReindexStatusResponse{}, fmt.Errorf("bot_id is required")
+	}
+	if s.reindex == nil {
+		return ReindexStatusResponse{}, fmt.Errorf("memory reindex not configured")
+	}
+	job, err := s.reindex.LatestReindexJob(ctx, botID)
+	if err != nil {
+		return ReindexStatusResponse{}, err
+	}
+	s.reindexMu.Lock()
+	if run := s.reindexRuns[botID]; run != nil && job != nil && run.job.ID == job.ID {
+		// The running job holds progress not yet saved.
+		current := run.job
+		job = &current
+	}
+	s.reindexMu.Unlock()
+	modelID := s.embeddingModel(ctx, botID)
+	if modelID == "" {
+		modelID = strings.TrimSpace(s.defaultTextModelID)
+	}
+	return ReindexStatusResponse{ModelID: modelID, Job: job}, nil
+}
+
+// ResumeReindexJobs restarts the jobs a restart interrupted.
+func (s *Service) ResumeReindexJobs(ctx context.Context) error {
+	if s.reindex == nil {
+		return nil
+	}
+	jobs, err := s.reindex.ListRunningReindexJobs(ctx)
+	if err != nil {
+		return err
+	}
+	for _, job := range jobs {
+		job.Status = ReindexStatusFailed
+		job.Error = "interrupted by a restart"
+		if _, err := s.reindex.FinishReindexJob(ctx, job); err != nil {
+			return err
+		}
+		if _, err := s.StartReindex(ctx, ReindexRequest{BotID: job.BotID, ModelID: job.ModelID}); err != nil && s.logger != nil {
+			s.logger.Warn("resume memory reindex failed", slog.String("bot_id", job.BotID), slog.String("model_id", job.ModelID), slog.Any("error", err))
+		}
+	}
+	return nil
+}
+
+// runReindex embeds every text memory of the bot with the job's model. Memories written
+// while it runs are embedded with the model too (see mirrorReindex), so none are missing
+// the new vector when the job completes and the bot switches over to it.
+func (s *Service) runReindex(ctx context.Context, run *reindexRun) {
+	defer close(run.done)
+	defer run.cancel()
+
+	job := run.job
+	err := s.reindexPoints(ctx, run)
+	s.reindexMu.Lock()
+	job.Total, job.Processed = run.job.Total, run.job.Processed
+	s.reindexMu.Unlock()
+	switch {
+	case err == nil:
+		job.Status = ReindexStatusCompleted
+	case errors.Is(err, context.Canceled):
+		job.Status = ReindexStatusCanceled
+	default:
+		job.Status = ReindexStatusFailed
+		job.Error = err.Error()
+	}
+	finished, finishErr := s.reindex.FinishReindexJob(context.WithoutCancel(ctx), job)
+	if finishErr != nil {
+		if s.logger != nil {
+			s.logger.Error("finish memory reindex failed", slog.String("job_id", job.ID), slog.Any("error", finishErr))
+		}
+		finished = job
+		if job.Status == ReindexStatusCompleted {
+			// Without the completed job the bot stays on its previous model after a restart.
+			finished.Status = ReindexStatusFailed
+		}
+	}
+
+	s.reindexMu.Lock()
+	if s.reindexRuns[job.BotID] == run {
+		delete(s.reindexRuns, job.BotID)
+	}
+	if finished.Status == ReindexStatusCompleted {
+		s.embeddingModels[job.BotID] = job.ModelID
+	}
+	s.reindexMu.Unlock()
+	if s.logger != nil {
+		s.logger.Info("memory reindex finished",
+			slog.String("bot_id", job.BotID),
+			slog.String("model_id", job.ModelID),
+			slog.String("status", finished.Status),
+			slog.Int("processed", finished.Processed),
+			slog.String("error", finished.Error),
+		)
+	}
+}
+
+func (s *Service) reindexPoints(ctx context.Context, run *reindexRun) error {
+	filters := map[string]any{"botId": run.job.BotID}
+	total, err := s.countReindexPoints(ctx, filters)
+	if err != nil {
+		return err
+	}
+	s.setReindexProgress(ctx, run, total, 0)
+
+	processed := 0
+	checked := false
+	var sibling VectorStore
+	offset := ""
+	for {
+		points, next, err := s.store.Scroll(ctx, reindexBatchSize, filters, offset)
+		if err != nil {
+			return err
+		}
+		batch := make([]VectorPoint, 0, len(points))
+		for _, point := range points {
+			text, ok := reindexText(point)
+			if !ok {
+				continue
+			}
+			vector, err := s.embedWithModel(ctx, run.job.ModelID, text)
+			if err != nil {
+				return err
+			}
+			if !checked {
+				switch {
+				case s.inSibling(run.job.ModelID):
+					if sibling, err = s.siblingStore(run.job.ModelID, len(vector)); err != nil {
+						return err
+					}
+					// Memories deleted since an earlier job filled the collection must not
+					// come back when the bot switches to it.
+					if err := sibling.DeleteAll(ctx, filters); err != nil {
+						return err
+					}
+				case !s.store.AcceptsVector(run.job.ModelID, len(vector)):
+					return fmt.Errorf("vector store has no vector %s (dim %d); add the model to the store before switching to it", run.job.ModelID, len(vector))
+				}
+				checked = true
+			}
+			if sibling != nil {
+				batch = append(batch, VectorPoint{ID: point.ID, Vector: vector, Payload: point.Payload})
+			} else {
+				batch = append(batch, VectorPoint{ID: point.ID, Vector: vector, VectorName: run.job.ModelID})
+			}
+		}
+		if sibling != nil {
+			err = sibling.Upsert(ctx, batch)
+		} else {
+			err = s.store.UpdateVectors(ctx, batch)
+		}
+		if err != nil {
+			return err
+		}
+		processed += len(batch)
+		// Memories added since counting make the total grow.
+		s.setReindexProgress(ctx, run, max(total, processed), processed)
+		if next == "" || len(points) == 0 {
+			return nil
+		}
+		offset = next
+	}
+}
+
+func (s *Service) countReindexPoints(ctx context.Context, filters map[string]any) (int, error) {
+	total := 0
+	offset := ""
+	for {
+		points, next, err := s.store.Scroll(ctx, reindexBatchSize, filters, offset)
+		if err != nil {
+			return 0, err
+		}
+		for _, point := range points {
+			if _, ok := reindexText(point); ok {
+				total++
+			}
+		}
+		if next == "" || len(points) == 0 {
+			return total, nil
+		}
+		offset = next
+	}
+}
+
+func (s *Service) setReindexProgress(ctx context.Context, run *reindexRun, total, processed int) {
+	s.reindexMu.Lock()
+	run.job.Total = total
+	run.job.Processed = processed
+	job := run.job
+	s.reindexMu.Unlock()
+	if err := s.reindex.UpdateReindexProgress(ctx, job); err != nil && s.logger != nil {
+		s.logger.Warn("save memory reindex progress failed", slog.String("job_id", job.ID), slog.Any("error", err))
+	}
+}
+
+// reindexText returns the text of a memory to embed. Images and videos embedded with the
+// multimodal model keep their vector.
+func reindexText(point VectorPoint) (string, bool) {
+	if modality, _ := point.Payload["modality"].(string); modality == embeddings.TypeMultimodal {
+		return "", false
+	}
+	text, _ := point.Payload["data"].(string)
+	if strings.TrimSpace(text) == "" {
+		return "", false
+	}
+	return text, true
+}
+
+// mirrorReindex embeds a memory written while its bot is re-embedded with the job's model
+// too, since writing a point replaces all its vectors. A sibling collection gets the payload
+// of the memory with the vector.
+func (s *Service) mirrorReindex(ctx context.Context, botID, id, text string, payload map[string]any) {
+	if strings.TrimSpace(botID) == "" || strings.TrimSpace(text) == "" {
+		return
+	}
+	s.reindexMu.Lock()
+	run := s.reindexRuns[botID]
+	s.reindexMu.Unlock()
+	if run == nil {
+		return
+	}
+	modelID := run.job.ModelID
+	vector, err := s.embedWithModel(ctx, modelID, text)
+	if err == nil {
+		if s.inSibling(modelID) {
+			var sibling VectorStore
+			if sibling, err = s.siblingStore(modelID, len(vector)); err == nil {
+				err = sibling.Upsert(ctx, []VectorPoint{{ID: id, Vector: vector, Payload: payload}})
+			}
+		} else {
+			err = s.store.UpdateVectors(ctx, []VectorPoint{{ID: id, Vector: vector, VectorName: modelID}})
+		}
+	}
+	if err != nil && s.logger != nil {
+		s.logger.Warn("embed memory for reindex failed", slog.String("memory_id", id), slog.String("model_id", modelID), slog.Any("error", err))
+	}
+}
+
+// embedText embeds the text of a memory of a bot and returns the name of the vector it is
+// stored in: the bot's model once its memories are re-embedded, the default one until then.
+func (s *Service) embedText(ctx context.Context, botID, text string) ([]float32, string, error) {
+	if modelID := s.embeddingModel(ctx, botID); modelID != "" {
+		vector, err := s.embedWithModel(ctx, modelID, text)
+		if err != nil {
+			return nil, "", err
+		}
+		return vector, modelID, nil
+	}
+	if s.embedder == nil {
+		return nil, "", fmt.Errorf("embedder not configured")
+	}
+	vector, err := s.embedder.Embed(ctx, text)
+	if err != nil {
+		return nil, "", err
+	}
+	return vector, s.vectorNameForText(), nil
+}
+
+func (s *Service) embedWithModel(ctx context.Context, modelID, text string) ([]float32, error) {
+	if s.resolver == nil {
+		return nil, fmt.Errorf("embeddings resolver not configured")
+	}
+	result, err := s.resolver.Embed(ctx, embeddings.Request{
+		Type:  embeddings.TypeText,
+		Model: modelID,
+		Input: embeddings.Input{Text: text},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result.Embedding, nil
+}
+
+// embeddingModel returns the model of the last completed re-embedding job of a bot, or ""
+// when its memories are embedded with the default model.
+func (s *Service) embeddingModel(ctx context.Context, botID string) string {
+	botID = strings.TrimSpace(botID)
+	if s.reindex == nil || botID == "" {
+		return ""
+	}
+	s.reindexMu.Lock()
+	modelID, ok := s.embeddingModels[botID]
+	s.reindexMu.Unlock()
+	if ok {
+		return modelID
+	}
+	modelID, err := s.reindex.EmbeddingModel(ctx, botID)
+	if err != nil {
+		if s.logger != nil {
+			s.logger.Warn("load memory embedding model failed", slog.String("bot_id", botID), slog.Any("error", err))
+		}
+		return ""
+	}
+	s.reindexMu.Lock()
+	if _, ok := s.embeddingModels[botID]; !ok {
+		s.embeddingModels[botID] = modelID
+	}
+	modelID = s.embeddingModels[botID]
+	s.reindexMu.Unlock()
+	return modelID
+}
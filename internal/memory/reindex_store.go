@@ -0,0 +1,133 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/memohai/memoh/internal/db"
+	"github.com/memohai/memoh/internal/db/sqlc"
+)
+
+// DBReindexStore keeps re-embedding jobs in the memory_reindex_jobs table.
+type DBReindexStore struct {
+	queries *sqlc.Queries
+}
+
+func NewDBReindexStore(queries *sqlc.Queries) *DBReindexStore {
+	return &DBReindexStore{queries: queries}
+}
+
+func (s *DBReindexStore) CreateReindexJob(ctx context.Context, botID, modelID string) (ReindexJob, error) {
+	if s.queries == nil {
+		return ReindexJob{}, fmt.Errorf("memory reindex queries not configured")
+	}
+	row, err := s.queries.CreateMemoryReindexJob(ctx, sqlc.CreateMemoryReindexJobParams{
+		BotID:   botID,
+		ModelID: modelID,
+	})
+	if err != nil {
+		return ReindexJob{}, err
+	}
+	return reindexJobFromRow(row), nil
+}
+
+func (s *DBReindexStore) UpdateReindexProgress(ctx context.Context, job ReindexJob) error {
+	if s.queries == nil {
+		return fmt.Errorf("memory reindex queries not configured")
+	}
+	pgID, err := db.ParseUUID(job.ID)
+	if err != nil {
+		return err
+	}
+	return s.queries.UpdateMemoryReindexProgress(ctx, sqlc.UpdateMemoryReindexProgressParams{
+		Total:     int32(job.Total),
+		Processed: int32(job.Processed),
+		ID:        pgID,
+	})
+}
+
+func (s *DBReindexStore) FinishReindexJob(ctx context.Context, job ReindexJob) (ReindexJob, error) {
+	if s.queries == nil {
+		return ReindexJob{}, fmt.Errorf("memory reindex queries not configured")
+	}
+	pgID, err := db.ParseUUID(job.ID)
+	if err != nil {
+		return ReindexJob{}, err
+	}
+	row, err := s.queries.FinishMemoryReindexJob(ctx, sqlc.FinishMemoryReindexJobParams{
+		Status:    job.Status,
+		Total:     int32(job.Total),
+		Processed: int32(job.Processed),
+		Error:     job.Error,
+		ID:        pgID,
+	})
+	if err != nil {
+		return ReindexJob{}, err
+	}
+	return reindexJobFromRow(row), nil
+}
+
+func (s *DBReindexStore) LatestReindexJob(ctx context.Context, botID string) (*ReindexJob, error) {
+	if s.queries == nil {
+		return nil, fmt.Errorf("memory reindex queries not configured")
+	}
+	row, err := s.queries.GetLatestMemoryReindexJob(ctx, botID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	job := reindexJobFromRow(row)
+	return &job, nil
+}
+
+func (s *DBReindexStore) EmbeddingModel(ctx context.Context, botID string) (string, error) {
+	if s.queries == nil {
+		return "", fmt.Errorf("memory reindex queries not configured")
+	}
+	modelID, err := s.queries.GetMemoryEmbeddingModel(ctx, botID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return "", nil
+		}
+		return "", err
+	}
+	return modelID, nil
+}
+
+func (s *DBReindexStore) ListRunningReindexJobs(ctx context.Context) ([]ReindexJob, error) {
+	if s.queries == nil {
+		return nil, fmt.Errorf("memory reindex queries not configured")
+	}
+	rows, err := s.queries.ListRunningMemoryReindexJobs(ctx)
+	if err != nil {
+		return nil, err
+	}
+	jobs := make([]ReindexJob, 0, len(rows))
+	for _, row := range rows {
+		jobs = append(jobs, reindexJobFromRow(row))
+	}
+	return jobs, nil
+}
+
+func reindexJobFromRow(row sqlc.MemoryReindexJob) ReindexJob {
+	job := ReindexJob{
+		ID:        db.UUIDToString(row.ID),
+		BotID:     row.BotID,
+		ModelID:   row.ModelID,
+		Status:    row.Status,
+		Total:     int(row.Total),
+		Processed: int(row.Processed),
+		Error:     row.Error,
+		StartedAt: db.TimeFromPg(row.StartedAt),
+	}
+	if row.FinishedAt.Valid {
+		finishedAt := db.TimeFromPg(row.FinishedAt)
+		job.FinishedAt = &finishedAt
+	}
+	return job
+}
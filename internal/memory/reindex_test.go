@@ -0,0 +1,331 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/memohai/memoh/internal/embeddings"
+)
+
+// modelResolver 把文本长度和模型名编码进向量，方便断言用的是哪个模型
+type modelResolver struct{}
+
+func (modelResolver) Embed(ctx context.Context, req embeddings.Request) (embeddings.Result, error) {
+	vector := []float32{float32(len(req.Input.Text)), float32(len(req.Model))}
+	return embeddings.Result{Type: req.Type, Model: req.Model, Dimensions: len(vector), Embedding: vector}, nil
+}
+
+// slowResolver 让重建持续一段时间，便于测试并发
+type slowResolver struct{ modelResolver }
+
+func (r slowResolver) Embed(ctx context.Context, req embeddings.Request) (embeddings.Result, error) {
+	time.Sleep(time.Millisecond)
+	return r.modelResolver.Embed(ctx, req)
+}
+
+// memReindex 是内存版 ReindexStore
+type memReindex struct {
+	mu   sync.Mutex
+	jobs []ReindexJob
+}
+
+func (m *memReindex) CreateReindexJob(ctx context.Context, botID, modelID string) (ReindexJob, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	job := ReindexJob{ID: fmt.Sprintf("j%d", len(m.jobs)+1), BotID: botID, ModelID: modelID, Status: ReindexStatusRunning, StartedAt: time.Now()}
+	m.jobs = append(m.jobs, job)
+	return job, nil
+}
+
+func (m *memReindex) UpdateReindexProgress(ctx context.Context, job ReindexJob) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for i := range m.jobs {
+		if m.jobs[i].ID == job.ID {
+			m.jobs[i].Total, m.jobs[i].Processed = job.Total, job.Processed
+		}
+	}
+	return nil
+}
+
+func (m *memReindex) FinishReindexJob(ctx context.Context, job ReindexJob) (ReindexJob, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	now := time.Now()
+	job.FinishedAt = &now
+	for i := range m.jobs {
+		if m.jobs[i].ID == job.ID {
+			m.jobs[i] = job
+		}
+	}
+	return job, nil
+}
+
+func (m *memReindex) LatestReindexJob(ctx context.Context, botID string) (*ReindexJob, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for i := len(m.jobs) - 1; i >= 0; i-- {
+		if m.jobs[i].BotID == botID {
+			job := m.jobs[i]
+			return &job, nil
+		}
+	}
+	return nil, nil
+}
+
+func (m *memReindex) EmbeddingModel(ctx context.Context, botID string) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for i := len(m.jobs) - 1; i >= 0; i-- {
+		if m.jobs[i].BotID == botID && m.jobs[i].Status == ReindexStatusCompleted {
+			return m.jobs[i].ModelID, nil
+		}
+	}
+	return "", nil
+}
+
+func (m *memReindex) ListRunningReindexJobs(ctx context.Context) ([]ReindexJob, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var jobs []ReindexJob
+	for _, job := range m.jobs {
+		if job.Status == ReindexStatusRunning {
+			jobs = append(jobs, job)
+		}
+	}
+	return jobs, nil
+}
+
+func langLLM() *MockLLM {
+	return &MockLLM{
+		DetectLanguageFunc: func(ctx context.Context, text string) (string, error) {
+			return "en", nil
+		},
+	}
+}
+
+func waitReindex(t *testing.T, s *Service, botID string) ReindexJob {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		status, err := s.ReindexStatus(context.Background(), botID)
+		if err != nil {
+			t.Fatalf("reindex status failed: %v", err)
+		}
+		if status.Job != nil && status.Job.Status != ReindexStatusRunning {
+			return *status.Job
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	t.Fatal("reindex did not finish")
+	return ReindexJob{}
+}
+
+func TestService_Reindex(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	store := newMemStore()
+	store.named = true
+	_ = store.Upsert(ctx, []VectorPoint{
+		{ID: "p1", Vector: []float32{1}, VectorName: "old-model", Payload: map[string]any{"botId": "bot-1", "data": "likes tea"}},
+		{ID: "p2", Vector: []float32{1}, VectorName: "vl-model", Payload: map[string]any{"botId": "bot-1", "data": "a cat", "modality": embeddings.TypeMultimodal}},
+		{ID: "p3", Vector: []float32{1}, VectorName: "old-model", Payload: map[string]any{"botId": "bot-2", "data": "likes coffee"}},
+	})
+	s := NewService(slog.Default(), langLLM(), nil, store, nil, NewBM25Indexer(nil), "old-model", "vl-model")
+	s.resolver = modelResolver{}
+	reindex := &memReindex{}
+	s.SetReindexStore(reindex)
+
+	if _, err := s.StartReindex(ctx, ReindexRequest{BotID: "bot-1", ModelID: "new-model"}); err != nil {
+		t.Fatalf("start reindex failed: %v", err)
+	}
+	job := waitReindex(t, s, "bot-1")
+	if job.Status != ReindexStatusCompleted || job.Total != 1 || job.Processed != 1 || job.FinishedAt == nil {
+		t.Fatalf("unexpected job: %+v", job)
+	}
+
+	// 只有 bot-1 的文本记忆写入新向量，旧向量保留
+	if store.vector("p1", "new-model") == nil || store.vector("p1", "old-model") == nil {
+		t.Fatalf("p1 should keep the old vector and gain the new one: %v", store.vectors["p1"])
+	}
+	if store.vector("p2", "new-model") != nil || store.vector("p3", "new-model") != nil {
+		t.Fatal("multimodal memories and other bots should not be re-embedded")
+	}
+
+	// 完成后切换到新模型检索
+	status, err := s.ReindexStatus(ctx, "bot-1")
+	if err != nil || status.ModelID != "new-model" {
+		t.Fatalf("bot should switch to the new model: %+v %v", status, err)
+	}
+	enabled := true
+	if _, err := s.Search(ctx, SearchRequest{Query: "tea", BotID: "bot-1", EmbeddingEnabled: &enabled}); err != nil {
+		t.Fatalf("search failed: %v", err)
+	}
+	if store.searchedFor != "new-model" {
+		t.Fatalf("search should use the new vector, got %q", store.searchedFor)
+	}
+	if status, _ := s.ReindexStatus(ctx, "bot-2"); status.ModelID != "old-model" || status.Job != nil {
+		t.Fatalf("other bots stay on the default model: %+v", status)
+	}
+
+	// 模型没有变化时不再重建
+	s.EmbeddingModelChanged(ctx, "bot-1", "new-model")
+	if len(reindex.jobs) != 1 {
+		t.Fatalf("unchanged model should not start a job: %+v", reindex.jobs)
+	}
+}
+
+func TestService_ReindexMirrorsWrites(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	store := newMemStore()
+	store.named = true
+	s := NewService(slog.Default(), langLLM(), nil, store, nil, NewBM25Indexer(nil), "old-model", "")
+	s.resolver = modelResolver{}
+	s.SetReindexStore(&memReindex{})
+
+	// 重建进行中写入的记忆同时写入新模型的向量
+	s.reindexRuns["bot-1"] = &reindexRun{job: ReindexJob{BotID: "bot-1", ModelID: "new-model"}}
+	infer := false
+	added, err := s.Add(ctx, AddRequest{Message: "likes tea", BotID: "bot-1", Infer: &infer})
+	if err != nil {
+		t.Fatalf("add failed: %v", err)
+	}
+	if store.vector(added.Results[0].ID, "new-model") == nil {
+		t.Fatal("memory written during a reindex should get the new vector")
+	}
+
+	added, err = s.Add(ctx, AddRequest{Message: "likes coffee", BotID: "bot-2", Infer: &infer})
+	if err != nil {
+		t.Fatalf("add failed: %v", err)
+	}
+	if store.vector(added.Results[0].ID, "new-model") != nil {
+		t.Fatal("memories of other bots should not be mirrored")
+	}
+
+	store.named = false
+	if _, err := s.StartReindex(ctx, ReindexRequest{BotID: "bot-2", ModelID: "new-model"}); err == nil {
+		t.Fatal("a store without named vectors cannot be re-embedded")
+	}
+}
+
+func TestService_ReindexConcurrentStart(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	store := newMemStore()
+	store.named = true
+	for i := 0; i < 50; i++ {
+		_ = store.Upsert(ctx, []VectorPoint{{ID: fmt.Sprintf("p%d", i), Vector: []float32{1}, VectorName: "old-model", Payload: map[string]any{"botId": "bot-1", "data": "likes tea"}}})
+	}
+	s := NewService(slog.Default(), langLLM(), nil, store, nil, NewBM25Indexer(nil), "old-model", "")
+	s.resolver = slowResolver{}
+	reindex := &memReindex{}
+	s.SetReindexStore(reindex)
+
+	// 同一个 bot 同时发起的重建最多只有一个在运行，其余都被取消
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			if _, err := s.StartReindex(ctx, ReindexRequest{BotID: "bot-1", ModelID: fmt.Sprintf("model-%d", i)}); err != nil {
+				t.Errorf("start reindex failed: %v", err)
+			}
+		}(i)
+	}
+	wg.Wait()
+	running, _ := reindex.ListRunningReindexJobs(ctx)
+	if len(running) > 1 {
+		t.Fatalf("only one job may run at a time: %+v", running)
+	}
+	waitReindex(t, s, "bot-1")
+}
+
+func TestService_ReindexIntoSiblingCollection(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	// 集合创建时的向量都是三维的，新模型的向量是二维的，只能写入旁边的集合
+	store := newMemStore()
+	store.named = true
+	store.dims = map[string]int{"old-model": 3, "vl-model": 3}
+	_ = store.Upsert(ctx, []VectorPoint{
+		{ID: "p1", Vector: []float32{1, 1, 1}, VectorName: "old-model", Payload: map[string]any{"botId": "bot-1", "data": "likes tea"}},
+		{ID: "p2", Vector: []float32{1, 1, 1}, VectorName: "old-model", Payload: map[string]any{"botId": "bot-2", "data": "likes coffee"}},
+	})
+	sibling := newMemStore()
+	// 之前的重建留下的、已删除的记忆
+	_ = sibling.Upsert(ctx, []VectorPoint{{ID: "gone", Vector: []float32{1, 1}, Payload: map[string]any{"botId": "bot-1", "data": "deleted"}}})
+	var (
+		openedMu sync.Mutex
+		opened   = map[string]int{}
+	)
+	s := NewService(slog.Default(), langLLM(), nil, store, nil, NewBM25Indexer(nil), "old-model", "vl-model")
+	s.resolver = modelResolver{}
+	s.SetReindexStore(&memReindex{})
+	s.SetSiblingStores("memory", func(collection string, dimension int) (VectorStore, error) {
+		openedMu.Lock()
+		defer openedMu.Unlock()
+		opened[collection] = dimension
+		return sibling, nil
+	})
+
+	if _, err := s.StartReindex(ctx, ReindexRequest{BotID: "bot-1", ModelID: "bge/m3"}); err != nil {
+		t.Fatalf("start reindex failed: %v", err)
+	}
+	job := waitReindex(t, s, "bot-1")
+	if job.Status != ReindexStatusCompleted || job.Processed != 1 {
+		t.Fatalf("unexpected job: %+v", job)
+	}
+	openedMu.Lock()
+	if dim, ok := opened["memory__bge_m3"]; !ok || dim != 2 {
+		t.Fatalf("sibling collection should be created with the new dimension: %v", opened)
+	}
+	openedMu.Unlock()
+	if len(sibling.vector("p1", "")) != 2 || sibling.points["p1"].Payload["data"] != "likes tea" {
+		t.Fatalf("sibling should hold the new vector and the payload: %+v", sibling.points["p1"])
+	}
+	if _, ok := sibling.points["gone"]; ok {
+		t.Fatal("stale memories of the bot should be cleared from the sibling")
+	}
+	if _, ok := sibling.points["p2"]; ok {
+		t.Fatal("memories of other bots should not be copied")
+	}
+
+	// 切换后 bot-1 在旁边的集合检索，bot-2 仍用原来的模型
+	enabled := true
+	if _, err := s.Search(ctx, SearchRequest{Query: "tea", BotID: "bot-1", EmbeddingEnabled: &enabled}); err != nil {
+		t.Fatalf("search failed: %v", err)
+	}
+	if sibling.searchedFor != "bge/m3" || store.searchedFor != "" {
+		t.Fatalf("bot-1 should search the sibling collection: sibling=%q store=%q", sibling.searchedFor, store.searchedFor)
+	}
+	if status, _ := s.ReindexStatus(ctx, "bot-2"); status.ModelID != "old-model" {
+		t.Fatalf("other bots stay on the default model: %+v", status)
+	}
+
+	// 写入也进入旁边的集合，原集合只保留记忆本身
+	if _, err := s.Update(ctx, UpdateRequest{MemoryID: "p1", Memory: "likes green tea", EmbeddingEnabled: &enabled}); err != nil {
+		t.Fatalf("update failed: %v", err)
+	}
+	if len(sibling.vector("p1", "")) != 2 || sibling.points["p1"].Payload["data"] != "likes green tea" {
+		t.Fatalf("update should write the vector and payload to the sibling: %+v", sibling.points["p1"])
+	}
+	if store.vector("p1", "bge/m3") != nil || store.points["p1"].Payload["data"] != "likes green tea" {
+		t.Fatalf("collection should keep the memory without the new vector: %v", store.vectors["p1"])
+	}
+
+	if _, err := s.Delete(ctx, "p1"); err != nil {
+		t.Fatalf("delete failed: %v", err)
+	}
+	if _, ok := sibling.points["p1"]; ok {
+		t.Fatal("deleting a memory should remove it from the sibling")
+	}
+}
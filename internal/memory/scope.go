@@ -0,0 +1,88 @@
+package memory
+
+import "strings"
+
+// Scopes decide whom a memory surfaces for. Memories stored before scopes existed have none
+// and surface in the conversation they were learned in, like ScopeSession.
+const (
+	// ScopeContact memories only surface for the contact they were learned from.
+	ScopeContact = "contact"
+	// ScopeSession memories surface for everyone in the conversation they were learned in.
+	ScopeSession = "session"
+	// ScopeBot memories surface in every conversation of the bot.
+	ScopeBot = "bot"
+
+	visibilityFilterKey = "$visibility"
+)
+
+// filterAnyOf is a filter value matching points that match any of its filters. Its key in
+// the filters map only names it.
+type filterAnyOf []map[string]any
+
+func isScope(scope string) bool {
+	switch strings.ToLower(strings.TrimSpace(scope)) {
+	case "", ScopeContact, ScopeSession, ScopeBot:
+		return true
+	}
+	return false
+}
+
+// normalizeScope returns the scope a memory is stored with. A memory can only be private to
+// a known contact and shared in a known conversation; otherwise it falls back to the next
+// wider scope. The default is ScopeSession.
+func normalizeScope(scope, sessionID, contactID string) string {
+	switch strings.ToLower(strings.TrimSpace(scope)) {
+	case ScopeBot:
+		return ScopeBot
+	case ScopeContact:
+		if contactID != "" {
+			return ScopeContact
+		}
+	}
+	if sessionID != "" {
+		return ScopeSession
+	}
+	return ScopeBot
+}
+
+// visibilityFilter matches the memories a speaker may see: those of the bot, those of the
+// conversation and their own.
+func visibilityFilter(sessionID, contactID string) filterAnyOf {
+	visible := filterAnyOf{{"scope": ScopeBot}}
+	if sessionID != "" {
+		visible = append(visible,
+			map[string]any{"scope": ScopeSession, "sessionId": sessionID},
+			map[string]any{"scope": nil, "sessionId": sessionID},
+		)
+	}
+	if contactID != "" {
+		visible = append(visible, map[string]any{"scope": ScopeContact, "contactId": contactID})
+	}
+	return visible
+}
+
+// candidateFilters turns the filters a memory is stored with into the filters of the
+// memories its speaker may see, which are the ones new facts can update or delete.
+func candidateFilters(filters map[string]any) map[string]any {
+	candidates := cloneFilters(filters)
+	sessionID, _ := candidates["sessionId"].(string)
+	contactID, _ := candidates["contactId"].(string)
+	delete(candidates, "sessionId")
+	delete(candidates, "contactId")
+	delete(candidates, "scope")
+	candidates[visibilityFilterKey] = visibilityFilter(sessionID, contactID)
+	return candidates
+}
+
+// keepOwnership drops the scope, contact and conversation from the filters applied to an
+// existing scoped memory, so that updating a shared memory does not make it the speaker's.
+func keepOwnership(payload map[string]any, filters map[string]any) map[string]any {
+	if scope, _ := payload["scope"].(string); scope == "" {
+		return filters
+	}
+	kept := cloneFilters(filters)
+	delete(kept, "scope")
+	delete(kept, "contactId")
+	delete(kept, "sessionId")
+	return kept
+}
@@ -0,0 +1,95 @@
+package memory
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestNormalizeScope(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		scope, sessionID, contactID, want string
+	}{
+		{"", "s1", "c1", ScopeSession},
+		{"Contact", "s1", "c1", ScopeContact},
+		{"contact", "s1", "", ScopeSession},
+		{"contact", "", "", ScopeBot},
+		{"session", "", "c1", ScopeBot},
+		{"bot", "s1", "c1", ScopeBot},
+	}
+	for _, tc := range cases {
+		if got := normalizeScope(tc.scope, tc.sessionID, tc.contactID); got != tc.want {
+			t.Fatalf("normalizeScope(%q, %q, %q) = %q, want %q", tc.scope, tc.sessionID, tc.contactID, got, tc.want)
+		}
+	}
+	if isScope("everyone") {
+		t.Fatal("unknown scope should be rejected")
+	}
+}
+
+func TestVisibilityFilter(t *testing.T) {
+	t.Parallel()
+
+	visible := visibilityFilter("s1", "c1")
+	if len(visible) != 4 {
+		t.Fatalf("expected bot, session, legacy and contact alternatives, got %v", visible)
+	}
+	if visible[3]["scope"] != ScopeContact || visible[3]["contactId"] != "c1" {
+		t.Fatalf("unexpected contact alternative: %v", visible[3])
+	}
+	if _, ok := visible[2]["scope"]; !ok || visible[2]["scope"] != nil {
+		t.Fatalf("legacy memories should match a missing scope: %v", visible[2])
+	}
+	if visible := visibilityFilter("", ""); len(visible) != 1 || visible[0]["scope"] != ScopeBot {
+		t.Fatalf("without a speaker only bot memories are visible: %v", visible)
+	}
+
+	where, args := buildPgvectorFilter(map[string]any{
+		"botId":             "b1",
+		visibilityFilterKey: visibilityFilter("s1", ""),
+	}, nil)
+	if !strings.Contains(where, " OR ") || !strings.Contains(where, "COALESCE(p.payload->'scope', 'null'::jsonb) = 'null'::jsonb") {
+		t.Fatalf("unexpected visibility condition: %s", where)
+	}
+	if len(args) != 5 {
+		t.Fatalf("unexpected args: %v", args)
+	}
+	if where, args := buildPgvectorFilter(map[string]any{"any": filterAnyOf{{"botId": "b1"}, {}}}, nil); where != "" || len(args) != 0 {
+		t.Fatalf("an empty alternative should match everything: %q %v", where, args)
+	}
+}
+
+func TestCandidateFilters(t *testing.T) {
+	t.Parallel()
+
+	filters := map[string]any{"botId": "b1", "sessionId": "s1", "contactId": "c1", "scope": ScopeContact}
+	candidates := candidateFilters(filters)
+	if candidates["botId"] != "b1" {
+		t.Fatalf("bot filter should be kept: %v", candidates)
+	}
+	for _, key := range []string{"sessionId", "contactId", "scope"} {
+		if _, ok := candidates[key]; ok {
+			t.Fatalf("%s should be replaced by the visibility filter: %v", key, candidates)
+		}
+	}
+	if visible, ok := candidates[visibilityFilterKey].(filterAnyOf); !ok || len(visible) != 4 {
+		t.Fatalf("unexpected visibility filter: %v", candidates[visibilityFilterKey])
+	}
+	if filters["sessionId"] != "s1" {
+		t.Fatal("the original filters should not be changed")
+	}
+}
+
+func TestKeepOwnership(t *testing.T) {
+	t.Parallel()
+
+	filters := map[string]any{"botId": "b1", "sessionId": "s2", "contactId": "c2", "scope": ScopeContact}
+	kept := keepOwnership(map[string]any{"scope": ScopeBot, "botId": "b1"}, filters)
+	if len(kept) != 1 || kept["botId"] != "b1" {
+		t.Fatalf("updating a scoped memory should keep its owner: %v", kept)
+	}
+	if legacy := keepOwnership(map[string]any{"botId": "b1"}, filters); legacy["scope"] != ScopeContact {
+		t.Fatalf("a legacy memory takes the scope of the update: %v", legacy)
+	}
+}
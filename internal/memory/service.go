@@ -9,36 +9,98 @@ import (
 	"math"
 	"sort"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/google/uuid"
-	"github.com/qdrant/go-client/qdrant"
 
+	"github.com/memohai/memoh/internal/config"
 	"github.com/memohai/memoh/internal/embeddings"
 )
 
+const (
+	// SearchModeHybrid fuses dense and BM25 results when embeddings are enabled.
+	SearchModeHybrid = "hybrid"
+	// SearchModeDense only uses dense results when embeddings are enabled.
+	SearchModeDense = "dense"
+
+	defaultSearchLimit = 10
+)
+
 type Service struct {
 	llm                      LLM
 	embedder                 embeddings.Embedder
-	store                    *QdrantStore
-	resolver                 *embeddings.Resolver
+	store                    VectorStore
+	resolver                 embeddingResolver
 	bm25                     *BM25Indexer
+	history                  HistoryStore
+	reindex                  ReindexStore
+	reindexStartMu           sync.Mutex
+	reindexMu                sync.Mutex
+	reindexRuns              map[string]*reindexRun
+	embeddingModels          map[string]string
+	siblingMu                sync.Mutex
+	siblingCollection        string
+	openSibling              SiblingStoreFunc
+	siblings                 map[string]VectorStore
 	logger                   *slog.Logger
 	defaultTextModelID       string
 	defaultMultimodalModelID string
+	searchMode               string
+	denseWeight              float64
+	sparseWeight             float64
 }
 
-func NewService(log *slog.Logger, llm LLM, embedder embeddings.Embedder, store *QdrantStore, resolver *embeddings.Resolver, bm25 *BM25Indexer, defaultTextModelID, defaultMultimodalModelID string) *Service {
-	return &Service{
+func NewService(log *slog.Logger, llm LLM, embedder embeddings.Embedder, store VectorStore, resolver *embeddings.Resolver, bm25 *BM25Indexer, defaultTextModelID, defaultMultimodalModelID string) *Service {
+	s := &Service{
 		llm:                      llm,
 		embedder:                 embedder,
 		store:                    store,
-		resolver:                 resolver,
 		bm25:                     bm25,
+		reindexRuns:              map[string]*reindexRun{},
+		embeddingModels:          map[string]string{},
 		logger:                   log.With(slog.String("service", "memory")),
 		defaultTextModelID:       defaultTextModelID,
 		defaultMultimodalModelID: defaultMultimodalModelID,
+		searchMode:               SearchModeHybrid,
+		denseWeight:              1,
+		sparseWeight:             1,
 	}
+	if resolver != nil {
+		s.resolver = resolver
+	}
+	return s
+}
+
+// embeddingResolver embeds with the embedding model a request selects.
+type embeddingResolver interface {
+	Embed(ctx context.Context, req embeddings.Request) (embeddings.Result, error)
+}
+
+// SetSearchConfig sets how searches with embeddings enabled combine dense and BM25 results.
+// Weights that are not positive keep their default of 1.
+func (s *Service) SetSearchConfig(cfg config.MemoryConfig) {
+	switch mode := strings.ToLower(strings.TrimSpace(cfg.SearchMode)); mode {
+	case SearchModeHybrid, SearchModeDense:
+		s.searchMode = mode
+	case "":
+	default:
+		if s.logger != nil {
+			s.logger.Warn("unknown memory search mode; using hybrid", slog.String("mode", cfg.SearchMode))
+		}
+		s.searchMode = SearchModeHybrid
+	}
+	if cfg.DenseWeight > 0 {
+		s.denseWeight = cfg.DenseWeight
+	}
+	if cfg.SparseWeight > 0 {
+		s.sparseWeight = cfg.SparseWeight
+	}
+}
+
+// MultimodalEnabled reports whether images can be embedded with a multimodal embedding model.
+func (s *Service) MultimodalEnabled() bool {
+	return s.resolver != nil && s.store != nil && s.defaultMultimodalModelID != ""
 }
 
 func (s *Service) Add(ctx context.Context, req AddRequest) (SearchResponse, error) {
@@ -48,13 +110,17 @@ func (s *Service) Add(ctx context.Context, req AddRequest) (SearchResponse, erro
 	if req.BotID == "" && req.AgentID == "" && req.RunID == "" {
 		return SearchResponse{}, fmt.Errorf("bot_id, agent_id or run_id is required")
 	}
+	if !isScope(req.Scope) {
+		return SearchResponse{}, fmt.Errorf("invalid scope: %s", req.Scope)
+	}
 
 	messages := normalizeMessages(req)
 	filters := buildFilters(req)
 
 	embeddingEnabled := req.EmbeddingEnabled != nil && *req.EmbeddingEnabled
+	source := changeSource{sessionID: req.SessionID, historyID: req.HistoryID}
 	if req.Infer != nil && !*req.Infer {
-		return s.addRawMessages(ctx, messages, filters, req.Metadata, embeddingEnabled)
+		return s.addRawMessages(ctx, messages, filters, req.Metadata, embeddingEnabled, source)
 	}
 
 	extractResp, err := s.llm.Extract(ctx, ExtractRequest{
@@ -69,7 +135,7 @@ func (s *Service) Add(ctx context.Context, req AddRequest) (SearchResponse, erro
 		return SearchResponse{Results: []MemoryItem{}}, nil
 	}
 
-	candidates, err := s.collectCandidates(ctx, extractResp.Facts, filters)
+	candidates, err := s.collectCandidates(ctx, extractResp.Facts, candidateFilters(filters))
 	if err != nil {
 		return SearchResponse{}, err
 	}
@@ -83,6 +149,7 @@ func (s *Service) Add(ctx context.Context, req AddRequest) (SearchResponse, erro
 	if err != nil {
 		return SearchResponse{}, err
 	}
+	source.model = decideResp.Model
 
 	actions := decideResp.Actions
 	if len(actions) == 0 && len(extractResp.Facts) > 0 {
@@ -99,7 +166,7 @@ func (s *Service) Add(ctx context.Context, req AddRequest) (SearchResponse, erro
 	for _, action := range actions {
 		switch strings.ToUpper(action.Event) {
 		case "ADD":
-			item, err := s.applyAdd(ctx, action.Text, filters, req.Metadata, embeddingEnabled)
+			item, err := s.applyAdd(ctx, action.Text, filters, req.Metadata, embeddingEnabled, source)
 			if err != nil {
 				return SearchResponse{}, err
 			}
@@ -108,7 +175,7 @@ func (s *Service) Add(ctx context.Context, req AddRequest) (SearchResponse, erro
 			})
 			results = append(results, item)
 		case "UPDATE":
-			item, err := s.applyUpdate(ctx, action.ID, action.Text, filters, req.Metadata, embeddingEnabled)
+			item, err := s.applyUpdate(ctx, action.ID, action.Text, filters, req.Metadata, embeddingEnabled, source)
 			if err != nil {
 				return SearchResponse{}, err
 			}
@@ -118,7 +185,7 @@ func (s *Service) Add(ctx context.Context, req AddRequest) (SearchResponse, erro
 			})
 			results = append(results, item)
 		case "DELETE":
-			item, err := s.applyDelete(ctx, action.ID)
+			item, err := s.applyDelete(ctx, action.ID, source)
 			if err != nil {
 				return SearchResponse{}, err
 			}
@@ -139,7 +206,7 @@ func (s *Service) Search(ctx context.Context, req SearchRequest) (SearchResponse
 		return SearchResponse{}, fmt.Errorf("query is required")
 	}
 	if s.store == nil {
-		return SearchResponse{}, fmt.Errorf("qdrant store not configured")
+		return SearchResponse{}, fmt.Errorf("vector store not configured")
 	}
 	filters := buildSearchFilters(req)
 	modality := ""
@@ -163,95 +230,146 @@ func (s *Service) Search(ctx context.Context, req SearchRequest) (SearchResponse
 		if err != nil {
 			return SearchResponse{}, err
 		}
-		vectorName := s.vectorNameForMultimodal()
-		if len(req.Sources) == 0 {
-			points, scores, err := s.store.Search(ctx, result.Embedding, req.Limit, filters, vectorName)
-			if err != nil {
-				return SearchResponse{}, err
-			}
-			results := make([]MemoryItem, 0, len(points))
-			for idx, point := range points {
-				item := payloadToMemoryItem(point.ID, point.Payload)
-				if idx < len(scores) {
-					item.Score = scores[idx]
-				}
-				results = append(results, item)
-			}
-			return SearchResponse{Results: results}, nil
-		}
-		pointsBySource, scoresBySource, err := s.store.SearchBySources(ctx, result.Embedding, req.Limit, filters, req.Sources, vectorName)
+		return s.searchDense(ctx, req, filters, result.Embedding, s.vectorNameForMultimodal())
+	}
+
+	if embeddingEnabled {
+		botID, _ := filters["botId"].(string)
+		vector, vectorName, err := s.embedText(ctx, botID, req.Query)
 		if err != nil {
 			return SearchResponse{}, err
 		}
-		results := fuseByRankFusion(pointsBySource, scoresBySource)
-		return SearchResponse{Results: results}, nil
+		return s.searchDense(ctx, req, filters, vector, vectorName)
 	}
 
-	if embeddingEnabled {
-		if s.embedder == nil {
-			return SearchResponse{}, fmt.Errorf("embedder not configured")
-		}
-		vector, err := s.embedder.Embed(ctx, req.Query)
+	if s.bm25 == nil {
+		return SearchResponse{}, fmt.Errorf("bm25 indexer not configured")
+	}
+	lists, err := s.sparseLists(ctx, req, filters)
+	if err != nil {
+		return SearchResponse{}, err
+	}
+	return searchResults(req, lists), nil
+}
+
+// searchDense searches with an embedded query. In hybrid mode the BM25 query runs alongside
+// it and both rankings are fused, so exact names and IDs only BM25 matches are kept.
+func (s *Service) searchDense(ctx context.Context, req SearchRequest, filters map[string]any, vector []float32, vectorName string) (SearchResponse, error) {
+	if s.searchMode != SearchModeHybrid || s.bm25 == nil {
+		lists, err := s.denseLists(ctx, req, filters, vector, vectorName)
 		if err != nil {
 			return SearchResponse{}, err
 		}
-		vectorName := s.vectorNameForText()
-		if len(req.Sources) == 0 {
-			points, scores, err := s.store.Search(ctx, vector, req.Limit, filters, vectorName)
-			if err != nil {
-				return SearchResponse{}, err
-			}
-			results := make([]MemoryItem, 0, len(points))
-			for idx, point := range points {
-				item := payloadToMemoryItem(point.ID, point.Payload)
-				if idx < len(scores) {
-					item.Score = scores[idx]
-				}
-				results = append(results, item)
-			}
-			return SearchResponse{Results: results}, nil
+		return searchResults(req, lists), nil
+	}
+
+	var (
+		wg                  sync.WaitGroup
+		dense, sparse       []rankedList
+		denseErr, sparseErr error
+	)
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		dense, denseErr = s.denseLists(ctx, req, filters, vector, vectorName)
+	}()
+	go func() {
+		defer wg.Done()
+		sparse, sparseErr = s.sparseLists(ctx, req, filters)
+	}()
+	wg.Wait()
+	if denseErr != nil {
+		return SearchResponse{}, denseErr
+	}
+	if sparseErr != nil {
+		// The dense results still answer the query on their own.
+		if s.logger != nil {
+			s.logger.Warn("bm25 search failed; using dense results only", slog.Any("error", sparseErr))
 		}
-		pointsBySource, scoresBySource, err := s.store.SearchBySources(ctx, vector, req.Limit, filters, req.Sources, vectorName)
+		return searchResults(req, dense), nil
+	}
+	for i := range dense {
+		dense[i].weight = s.denseWeight
+	}
+	for i := range sparse {
+		sparse[i].weight = s.sparseWeight
+	}
+	results := fuseRankedLists(append(dense, sparse...))
+	limit := req.Limit
+	if limit <= 0 {
+		limit = defaultSearchLimit
+	}
+	if len(req.Sources) > 0 {
+		limit *= len(req.Sources)
+	}
+	if len(results) > limit {
+		results = results[:limit]
+	}
+	return SearchResponse{Results: results}, nil
+}
+
+// denseLists returns the dense ranking of the query, one per source when sources are given.
+func (s *Service) denseLists(ctx context.Context, req SearchRequest, filters map[string]any, vector []float32, vectorName string) ([]rankedList, error) {
+	store, err := s.denseStore(vectorName)
+	if err != nil {
+		return nil, err
+	}
+	if len(req.Sources) == 0 {
+		points, scores, err := store.Search(ctx, vector, req.Limit, filters, vectorName)
 		if err != nil {
-			return SearchResponse{}, err
+			return nil, err
 		}
-		results := fuseByRankFusion(pointsBySource, scoresBySource)
-		return SearchResponse{Results: results}, nil
+		return []rankedList{{points: points, scores: scores, weight: 1}}, nil
 	}
-
-	if s.bm25 == nil {
-		return SearchResponse{}, fmt.Errorf("bm25 indexer not configured")
+	pointsBySource, scoresBySource, err := searchBySources(ctx, store, vector, req.Limit, filters, req.Sources, vectorName)
+	if err != nil {
+		return nil, err
 	}
+	return sourceLists(pointsBySource, scoresBySource), nil
+}
+
+// sparseLists returns the BM25 ranking of the query, one per source when sources are given.
+func (s *Service) sparseLists(ctx context.Context, req SearchRequest, filters map[string]any) ([]rankedList, error) {
 	lang, err := s.detectLanguage(ctx, req.Query)
 	if err != nil {
-		return SearchResponse{}, err
+		return nil, err
 	}
 	termFreq, _, err := s.bm25.TermFrequencies(lang, req.Query)
 	if err != nil {
-		return SearchResponse{}, err
+		return nil, err
 	}
 	indices, values := s.bm25.BuildQueryVector(lang, termFreq)
 	if len(req.Sources) == 0 {
 		points, scores, err := s.store.SearchSparse(ctx, indices, values, req.Limit, filters)
 		if err != nil {
-			return SearchResponse{}, err
+			return nil, err
 		}
-		results := make([]MemoryItem, 0, len(points))
-		for idx, point := range points {
+		return []rankedList{{points: points, scores: scores, weight: 1}}, nil
+	}
+	pointsBySource, scoresBySource, err := searchSparseBySources(ctx, s.store, indices, values, req.Limit, filters, req.Sources)
+	if err != nil {
+		return nil, err
+	}
+	return sourceLists(pointsBySource, scoresBySource), nil
+}
+
+// searchResults returns the results of a single retriever: its own scores for one ranking,
+// rank fusion across sources otherwise.
+func searchResults(req SearchRequest, lists []rankedList) SearchResponse {
+	if len(req.Sources) > 0 {
+		return SearchResponse{Results: fuseRankedLists(lists)}
+	}
+	results := []MemoryItem{}
+	for _, list := range lists {
+		for idx, point := range list.points {
 			item := payloadToMemoryItem(point.ID, point.Payload)
-			if idx < len(scores) {
-				item.Score = scores[idx]
+			if idx < len(list.scores) {
+				item.Score = list.scores[idx]
 			}
 			results = append(results, item)
 		}
-		return SearchResponse{Results: results}, nil
 	}
-	pointsBySource, scoresBySource, err := s.store.SearchSparseBySources(ctx, indices, values, req.Limit, filters, req.Sources)
-	if err != nil {
-		return SearchResponse{}, err
-	}
-	results := fuseByRankFusion(pointsBySource, scoresBySource)
-	return SearchResponse{Results: results}, nil
+	return SearchResponse{Results: results}
 }
 
 func (s *Service) EmbedUpsert(ctx context.Context, req EmbedUpsertRequest) (EmbedUpsertResponse, error) {
@@ -261,12 +379,19 @@ func (s *Service) EmbedUpsert(ctx context.Context, req EmbedUpsertRequest) (Embe
 	if req.BotID == "" && req.AgentID == "" && req.RunID == "" {
 		return EmbedUpsertResponse{}, fmt.Errorf("bot_id, agent_id or run_id is required")
 	}
+	if !isScope(req.Scope) {
+		return EmbedUpsertResponse{}, fmt.Errorf("invalid scope: %s", req.Scope)
+	}
 	req.Type = strings.TrimSpace(req.Type)
 	req.Provider = strings.TrimSpace(req.Provider)
 	req.Model = strings.TrimSpace(req.Model)
 	req.Input.Text = strings.TrimSpace(req.Input.Text)
 	req.Input.ImageURL = strings.TrimSpace(req.Input.ImageURL)
 	req.Input.VideoURL = strings.TrimSpace(req.Input.VideoURL)
+	isText := req.Type == "" || strings.EqualFold(req.Type, embeddings.TypeText)
+	if isText && req.Model == "" && req.Provider == "" {
+		req.Model = s.embeddingModel(ctx, req.BotID)
+	}
 
 	result, err := s.resolver.Embed(ctx, embeddings.Request{
 		Type:     req.Type,
@@ -283,11 +408,11 @@ func (s *Service) EmbedUpsert(ctx context.Context, req EmbedUpsertRequest) (Embe
 	}
 
 	if s.store == nil {
-		return EmbedUpsertResponse{}, fmt.Errorf("qdrant store not configured")
+		return EmbedUpsertResponse{}, fmt.Errorf("vector store not configured")
 	}
 
 	vectorName := ""
-	if s.store != nil && s.store.usesNamedVectors {
+	if s.store != nil && s.store.UsesNamedVectors() {
 		vectorName = result.Model
 	}
 
@@ -297,14 +422,17 @@ func (s *Service) EmbedUpsert(ctx context.Context, req EmbedUpsertRequest) (Embe
 	if metadata, ok := payload["metadata"].(map[string]any); ok && result.Model != "" {
 		metadata["model_id"] = result.Model
 	}
-	if err := s.store.Upsert(ctx, []qdrantPoint{{
+	if err := s.upsertPoint(ctx, VectorPoint{
 		ID:         id,
 		Vector:     result.Embedding,
 		VectorName: vectorName,
 		Payload:    payload,
-	}}); err != nil {
+	}); err != nil {
 		return EmbedUpsertResponse{}, err
 	}
+	if isText {
+		s.mirrorReindex(ctx, req.BotID, id, req.Input.Text, payload)
+	}
 
 	item := payloadToMemoryItem(id, payload)
 	return EmbedUpsertResponse{
@@ -316,6 +444,10 @@ func (s *Service) EmbedUpsert(ctx context.Context, req EmbedUpsertRequest) (Embe
 }
 
 func (s *Service) Update(ctx context.Context, req UpdateRequest) (MemoryItem, error) {
+	return s.update(ctx, req, changeSource{})
+}
+
+func (s *Service) update(ctx context.Context, req UpdateRequest, source changeSource) (MemoryItem, error) {
 	if strings.TrimSpace(req.MemoryID) == "" {
 		return MemoryItem{}, fmt.Errorf("memory_id is required")
 	}
@@ -323,7 +455,7 @@ func (s *Service) Update(ctx context.Context, req UpdateRequest) (MemoryItem, er
 		return MemoryItem{}, fmt.Errorf("memory is required")
 	}
 	if s.store == nil {
-		return MemoryItem{}, fmt.Errorf("qdrant store not configured")
+		return MemoryItem{}, fmt.Errorf("vector store not configured")
 	}
 	if s.bm25 == nil {
 		return MemoryItem{}, fmt.Errorf("bm25 indexer not configured")
@@ -369,27 +501,27 @@ func (s *Service) Update(ctx context.Context, req UpdateRequest) (MemoryItem, er
 	payload["lang"] = newLang
 
 	embeddingEnabled := req.EmbeddingEnabled != nil && *req.EmbeddingEnabled
-	point := qdrantPoint{
+	point := VectorPoint{
 		ID:               req.MemoryID,
 		SparseIndices:    sparseIndices,
 		SparseValues:     sparseValues,
-		SparseVectorName: s.store.sparseVectorName,
+		SparseVectorName: s.store.SparseVectorName(),
 		Payload:          payload,
 	}
+	botID, _ := payload["botId"].(string)
 	if embeddingEnabled {
-		if s.embedder == nil {
-			return MemoryItem{}, fmt.Errorf("embedder not configured")
-		}
-		vector, err := s.embedder.Embed(ctx, req.Memory)
+		vector, vectorName, err := s.embedText(ctx, botID, req.Memory)
 		if err != nil {
 			return MemoryItem{}, err
 		}
 		point.Vector = vector
-		point.VectorName = s.vectorNameForText()
+		point.VectorName = vectorName
 	}
-	if err := s.store.Upsert(ctx, []qdrantPoint{point}); err != nil {
+	if err := s.upsertPoint(ctx, point); err != nil {
 		return MemoryItem{}, err
 	}
+	s.mirrorReindex(ctx, botID, req.MemoryID, req.Memory, payload)
+	s.recordHistory(ctx, HistoryEventUpdate, req.MemoryID, oldText, req.Memory, payload, source)
 	return payloadToMemoryItem(req.MemoryID, payload), nil
 }
 
@@ -437,9 +569,26 @@ func (s *Service) Delete(ctx context.Context, memoryID string) (DeleteResponse,
 	if strings.TrimSpace(memoryID) == "" {
 		return DeleteResponse{}, fmt.Errorf("memory_id is required")
 	}
+	var existing *VectorPoint
+	if s.history != nil || s.openSibling != nil {
+		point, err := s.store.Get(ctx, memoryID)
+		if err != nil {
+			return DeleteResponse{}, err
+		}
+		existing = point
+	}
+	if existing != nil {
+		botID, _ := existing.Payload["botId"].(string)
+		if err := s.deleteFromSiblings(ctx, botID, memoryID); err != nil {
+			return DeleteResponse{}, err
+		}
+	}
 	if err := s.store.Delete(ctx, memoryID); err != nil {
 		return DeleteResponse{}, err
 	}
+	if existing != nil {
+		s.recordHistory(ctx, HistoryEventDelete, memoryID, fmt.Sprint(existing.Payload["data"]), "", existing.Payload, changeSource{})
+	}
 	return DeleteResponse{Message: "Memory deleted successfully!"}, nil
 }
 
@@ -457,6 +606,15 @@ func (s *Service) DeleteAll(ctx context.Context, req DeleteAllRequest) (DeleteRe
 	if len(filters) == 0 {
 		return DeleteResponse{}, fmt.Errorf("bot_id, agent_id or run_id is required")
 	}
+	siblings, err := s.botSiblings(ctx, req.BotID)
+	if err != nil {
+		return DeleteResponse{}, err
+	}
+	for _, sibling := range siblings {
+		if err := sibling.DeleteAll(ctx, filters); err != nil {
+			return DeleteResponse{}, err
+		}
+	}
 	if err := s.store.DeleteAll(ctx, filters); err != nil {
 		return DeleteResponse{}, err
 	}
@@ -467,7 +625,7 @@ func (s *Service) WarmupBM25(ctx context.Context, batchSize int) error {
 	if s.bm25 == nil || s.store == nil {
 		return nil
 	}
-	var offset *qdrant.PointId
+	offset := ""
 	for {
 		points, next, err := s.store.Scroll(ctx, batchSize, nil, offset)
 		if err != nil {
@@ -491,7 +649,7 @@ func (s *Service) WarmupBM25(ctx context.Context, batchSize int) error {
 			}
 			s.bm25.AddDocument(lang, termFreq, docLen)
 		}
-		if next == nil {
+		if next == "" {
 			break
 		}
 		offset = next
@@ -499,10 +657,10 @@ func (s *Service) WarmupBM25(ctx context.Context, batchSize int) error {
 	return nil
 }
 
-func (s *Service) addRawMessages(ctx context.Context, messages []Message, filters map[string]any, metadata map[string]any, embeddingEnabled bool) (SearchResponse, error) {
+func (s *Service) addRawMessages(ctx context.Context, messages []Message, filters map[string]any, metadata map[string]any, embeddingEnabled bool, source changeSource) (SearchResponse, error) {
 	results := make([]MemoryItem, 0, len(messages))
 	for _, message := range messages {
-		item, err := s.applyAdd(ctx, message.Content, filters, metadata, embeddingEnabled)
+		item, err := s.applyAdd(ctx, message.Content, filters, metadata, embeddingEnabled, source)
 		if err != nil {
 			return SearchResponse{}, err
 		}
@@ -529,6 +687,12 @@ func (s *Service) collectCandidates(ctx context.Context, facts []string, filters
 			return nil, err
 		}
 		indices, values := s.bm25.BuildQueryVector(lang, termFreq)
+		if len(indices) == 0 {
+			continue
+		}
+		if s.store == nil {
+			return nil, fmt.Errorf("vector store not configured")
+		}
 		points, _, err := s.store.SearchSparse(ctx, indices, values, 5, filters)
 		if err != nil {
 			return nil, err
@@ -550,9 +714,9 @@ func (s *Service) collectCandidates(ctx context.Context, facts []string, filters
 	return candidates, nil
 }
 
-func (s *Service) applyAdd(ctx context.Context, text string, filters map[string]any, metadata map[string]any, embeddingEnabled bool) (MemoryItem, error) {
+func (s *Service) applyAdd(ctx context.Context, text string, filters map[string]any, metadata map[string]any, embeddingEnabled bool, source changeSource) (MemoryItem, error) {
 	if s.store == nil {
-		return MemoryItem{}, fmt.Errorf("qdrant store not configured")
+		return MemoryItem{}, fmt.Errorf("vector store not configured")
 	}
 	if s.bm25 == nil {
 		return MemoryItem{}, fmt.Errorf("bm25 indexer not configured")
@@ -569,31 +733,31 @@ func (s *Service) applyAdd(ctx context.Context, text string, filters map[string]
 	id := uuid.NewString()
 	payload := buildPayload(text, filters, metadata, "")
 	payload["lang"] = lang
-	point := qdrantPoint{
+	point := VectorPoint{
 		ID:               id,
 		SparseIndices:    sparseIndices,
 		SparseValues:     sparseValues,
-		SparseVectorName: s.store.sparseVectorName,
+		SparseVectorName: s.store.SparseVectorName(),
 		Payload:          payload,
 	}
+	botID, _ := payload["botId"].(string)
 	if embeddingEnabled {
-		if s.embedder == nil {
-			return MemoryItem{}, fmt.Errorf("embedder not configured")
-		}
-		vector, err := s.embedder.Embed(ctx, text)
+		vector, vectorName, err := s.embedText(ctx, botID, text)
 		if err != nil {
 			return MemoryItem{}, err
 		}
 		point.Vector = vector
-		point.VectorName = s.vectorNameForText()
+		point.VectorName = vectorName
 	}
-	if err := s.store.Upsert(ctx, []qdrantPoint{point}); err != nil {
+	if err := s.upsertPoint(ctx, point); err != nil {
 		return MemoryItem{}, err
 	}
+	s.mirrorReindex(ctx, botID, id, text, payload)
+	s.recordHistory(ctx, HistoryEventAdd, id, "", text, payload, source)
 	return payloadToMemoryItem(id, payload), nil
 }
 
-func (s *Service) applyUpdate(ctx context.Context, id, text string, filters map[string]any, metadata map[string]any, embeddingEnabled bool) (MemoryItem, error) {
+func (s *Service) applyUpdate(ctx context.Context, id, text string, filters map[string]any, metadata map[string]any, embeddingEnabled bool, source changeSource) (MemoryItem, error) {
 	if strings.TrimSpace(id) == "" {
 		return MemoryItem{}, fmt.Errorf("update action missing id")
 	}
@@ -634,33 +798,33 @@ func (s *Service) applyUpdate(ctx context.Context, id, text string, filters map[
 		payload["metadata"] = mergeMetadata(payload["metadata"], metadata)
 	}
 	if filters != nil {
-		applyFiltersToPayload(payload, filters)
+		applyFiltersToPayload(payload, keepOwnership(payload, filters))
 	}
-	point := qdrantPoint{
+	point := VectorPoint{
 		ID:               id,
 		SparseIndices:    sparseIndices,
 		SparseValues:     sparseValues,
-		SparseVectorName: s.store.sparseVectorName,
+		SparseVectorName: s.store.SparseVectorName(),
 		Payload:          payload,
 	}
+	botID, _ := payload["botId"].(string)
 	if embeddingEnabled {
-		if s.embedder == nil {
-			return MemoryItem{}, fmt.Errorf("embedder not configured")
-		}
-		vector, err := s.embedder.Embed(ctx, text)
+		vector, vectorName, err := s.embedText(ctx, botID, text)
 		if err != nil {
 			return MemoryItem{}, err
 		}
 		point.Vector = vector
-		point.VectorName = s.vectorNameForText()
+		point.VectorName = vectorName
 	}
-	if err := s.store.Upsert(ctx, []qdrantPoint{point}); err != nil {
+	if err := s.upsertPoint(ctx, point); err != nil {
 		return MemoryItem{}, err
 	}
+	s.mirrorReindex(ctx, botID, id, text, payload)
+	s.recordHistory(ctx, HistoryEventUpdate, id, oldText, text, payload, source)
 	return payloadToMemoryItem(id, payload), nil
 }
 
-func (s *Service) applyDelete(ctx context.Context, id string) (MemoryItem, error) {
+func (s *Service) applyDelete(ctx context.Context, id string, source changeSource) (MemoryItem, error) {
 	if strings.TrimSpace(id) == "" {
 		return MemoryItem{}, fmt.Errorf("delete action missing id")
 	}
@@ -685,9 +849,13 @@ func (s *Service) applyDelete(ctx context.Context, id string) (MemoryItem, error
 			}
 		}
 	}
+	if err := s.deleteFromSiblings(ctx, item.BotID, id); err != nil {
+		return MemoryItem{}, err
+	}
 	if err := s.store.Delete(ctx, id); err != nil {
 		return MemoryItem{}, err
 	}
+	s.recordHistory(ctx, HistoryEventDelete, id, item.Memory, "", existing.Payload, source)
 	return item, nil
 }
 
@@ -759,6 +927,10 @@ func buildFilters(req AddRequest) map[string]any {
 	if req.SessionID != "" {
 		filters["sessionId"] = req.SessionID
 	}
+	if req.ContactID != "" {
+		filters["contactId"] = req.ContactID
+	}
+	filters["scope"] = normalizeScope(req.Scope, req.SessionID, req.ContactID)
 	if req.RunID != "" {
 		filters["runId"] = req.RunID
 	}
@@ -773,8 +945,9 @@ func buildSearchFilters(req SearchRequest) map[string]any {
 	if req.BotID != "" {
 		filters["botId"] = req.BotID
 	}
-	if req.SessionID != "" {
-		filters["sessionId"] = req.SessionID
+	// A speaker only finds the memories their scope lets them see.
+	if req.SessionID != "" || req.ContactID != "" {
+		filters[visibilityFilterKey] = visibilityFilter(req.SessionID, req.ContactID)
 	}
 	if req.RunID != "" {
 		filters["runId"] = req.RunID
@@ -793,6 +966,10 @@ func buildEmbedFilters(req EmbedUpsertRequest) map[string]any {
 	if req.SessionID != "" {
 		filters["sessionId"] = req.SessionID
 	}
+	if req.ContactID != "" {
+		filters["contactId"] = req.ContactID
+	}
+	filters["scope"] = normalizeScope(req.Scope, req.SessionID, req.ContactID)
 	if req.RunID != "" {
 		filters["runId"] = req.RunID
 	}
@@ -820,7 +997,8 @@ func buildEmbeddingPayload(req EmbedUpsertRequest, filters map[string]any) map[s
 			metadata["source"] = req.Source
 		}
 		metadata["modality"] = modality
-		if req.Input.ImageURL != "" {
+		// Inline data URLs are only needed for embedding and would bloat the payload.
+		if req.Input.ImageURL != "" && !strings.HasPrefix(req.Input.ImageURL, "data:") {
 			metadata["image_url"] = req.Input.ImageURL
 		}
 		if req.Input.VideoURL != "" {
@@ -831,14 +1009,14 @@ func buildEmbeddingPayload(req EmbedUpsertRequest, filters map[string]any) map[s
 }
 
 func (s *Service) vectorNameForText() string {
-	if s.store == nil || !s.store.usesNamedVectors {
+	if s.store == nil || !s.store.UsesNamedVectors() {
 		return ""
 	}
 	return strings.TrimSpace(s.defaultTextModelID)
 }
 
 func (s *Service) vectorNameForMultimodal() string {
-	if s.store == nil || !s.store.usesNamedVectors {
+	if s.store == nil || !s.store.UsesNamedVectors() {
 		return ""
 	}
 	return strings.TrimSpace(s.defaultMultimodalModelID)
@@ -886,6 +1064,12 @@ func payloadToMemoryItem(id string, payload map[string]any) MemoryItem {
 	if v, ok := payload["sessionId"].(string); ok {
 		item.SessionID = v
 	}
+	if v, ok := payload["contactId"].(string); ok {
+		item.ContactID = v
+	}
+	if v, ok := payload["scope"].(string); ok {
+		item.Scope = v
+	}
 	if v, ok := payload["runId"].(string); ok {
 		item.RunID = v
 	}
@@ -948,14 +1132,34 @@ const (
 	rrfK              = 60.0
 )
 
-func fuseByRankFusion(pointsBySource map[string][]qdrantPoint, scoresBySource map[string][]float64) []MemoryItem {
+// rankedList is one ranking fed to rank fusion, such as the dense or BM25 results of a
+// source. Its weight scales its contribution to the fused score.
+type rankedList struct {
+	points []VectorPoint
+	scores []float64
+	weight float64
+}
+
+func fuseByRankFusion(pointsBySource map[string][]VectorPoint, scoresBySource map[string][]float64) []MemoryItem {
+	return fuseRankedLists(sourceLists(pointsBySource, scoresBySource))
+}
+
+func sourceLists(pointsBySource map[string][]VectorPoint, scoresBySource map[string][]float64) []rankedList {
+	lists := make([]rankedList, 0, len(pointsBySource))
+	for source, points := range pointsBySource {
+		lists = append(lists, rankedList{points: points, scores: scoresBySource[source], weight: 1})
+	}
+	return lists
+}
+
+func fuseRankedLists(lists []rankedList) []MemoryItem {
 	candidates := map[string]*rerankCandidate{}
 	rrfScores := map[string]float64{}
 	combScores := map[string]float64{}
 	combCounts := map[string]int{}
 
-	for source, points := range pointsBySource {
-		scores := scoresBySource[source]
+	for _, list := range lists {
+		points, scores := list.points, list.scores
 		minScore := math.MaxFloat64
 		maxScore := -math.MaxFloat64
 		for idx, point := range points {
@@ -989,10 +1193,10 @@ func fuseByRankFusion(pointsBySource map[string][]qdrantPoint, scoresBySource ma
 			}
 			score := scores[idx]
 			rank := float64(idx + 1)
-			rrfScores[point.ID] += 1.0 / (rrfK + rank)
+			rrfScores[point.ID] += list.weight / (rrfK + rank)
 
 			scoreNorm := normalizeScore(score, minScore, maxScore)
-			combScores[point.ID] += scoreNorm
+			combScores[point.ID] += list.weight * scoreNorm
 			combCounts[point.ID]++
 		}
 	}
@@ -1010,6 +1214,9 @@ func fuseByRankFusion(pointsBySource map[string][]qdrantPoint, scoresBySource ma
 	}
 
 	sort.Slice(items, func(i, j int) bool {
+		if items[i].Score == items[j].Score {
+			return items[i].ID < items[j].ID
+		}
 		return items[i].Score > items[j].Score
 	})
 	return items
@@ -5,6 +5,8 @@ import (
 	"fmt"
 	"log/slog"
 	"testing"
+
+	"github.com/memohai/memoh/internal/config"
 )
 
 // MockLLM 模拟 LLM 行为
@@ -117,12 +119,12 @@ func TestRankFusion_Logic(t *testing.T) {
 	// 测试 RRF (Reciprocal Rank Fusion) 逻辑
 	// 验证不同来源的结果是否能被正确合并和排序
 
-	p1 := qdrantPoint{ID: "1", Payload: map[string]any{"data": "result 1"}}
-	p2 := qdrantPoint{ID: "2", Payload: map[string]any{"data": "result 2"}}
+	p1 := VectorPoint{ID: "1", Payload: map[string]any{"data": "result 1"}}
+	p2 := VectorPoint{ID: "2", Payload: map[string]any{"data": "result 2"}}
 
 	// 来源 A: 1 号排第一，2 号排第二
 	// 来源 B: 2 号排第一，1 号排第二
-	pointsBySource := map[string][]qdrantPoint{
+	pointsBySource := map[string][]VectorPoint{
 		"source_a": {p1, p2},
 		"source_b": {p2, p1},
 	}
@@ -142,3 +144,61 @@ func TestRankFusion_Logic(t *testing.T) {
 		// 理论上 1/(60+1) + 1/(60+2)
 	}
 }
+
+func TestRankFusion_WeightedHybrid(t *testing.T) {
+	// 混合检索：稠密结果与 BM25 结果按权重融合
+	dense := rankedList{
+		points: []VectorPoint{
+			{ID: "a", Payload: map[string]any{"data": "semantic match"}},
+			{ID: "b", Payload: map[string]any{"data": "shared match"}},
+		},
+		scores: []float64{0.92, 0.81},
+		weight: 1,
+	}
+	sparse := rankedList{
+		points: []VectorPoint{
+			{ID: "c", Payload: map[string]any{"data": "exact id ORD-4411"}},
+			{ID: "b", Payload: map[string]any{"data": "shared match"}},
+		},
+		scores: []float64{12.5, 7.1},
+		weight: 1,
+	}
+
+	results := fuseRankedLists([]rankedList{dense, sparse})
+	if len(results) != 3 {
+		t.Fatalf("expected 3 fused results, got %d", len(results))
+	}
+	// 两路都命中的结果排第一，只被 BM25 命中的结果也不会丢失
+	if results[0].ID != "b" {
+		t.Fatalf("expected b first, got %s", results[0].ID)
+	}
+
+	// 提高 BM25 权重后，BM25 第一名应超过稠密第一名
+	sparse.weight = 2
+	results = fuseRankedLists([]rankedList{dense, sparse})
+	ranks := map[string]int{}
+	for idx, item := range results {
+		ranks[item.ID] = idx
+	}
+	if ranks["c"] > ranks["a"] {
+		t.Fatalf("expected c ahead of a with a higher sparse weight, got %v", ranks)
+	}
+}
+
+func TestService_SetSearchConfig(t *testing.T) {
+	s := NewService(slog.Default(), nil, nil, nil, nil, nil, "", "")
+	if s.searchMode != SearchModeHybrid || s.denseWeight != 1 || s.sparseWeight != 1 {
+		t.Fatalf("unexpected defaults: %s %v %v", s.searchMode, s.denseWeight, s.sparseWeight)
+	}
+	s.SetSearchConfig(config.MemoryConfig{SearchMode: "Dense", SparseWeight: 0.5})
+	if s.searchMode != SearchModeDense {
+		t.Fatalf("expected dense mode, got %s", s.searchMode)
+	}
+	if s.denseWeight != 1 || s.sparseWeight != 0.5 {
+		t.Fatalf("unexpected weights: %v %v", s.denseWeight, s.sparseWeight)
+	}
+	s.SetSearchConfig(config.MemoryConfig{SearchMode: "unknown"})
+	if s.searchMode != SearchModeHybrid {
+		t.Fatalf("expected hybrid fallback, got %s", s.searchMode)
+	}
+}
@@ -0,0 +1,134 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// SiblingStoreFunc opens the collection with the name next to the vector store, creating it
+// with dense vectors of the dimension when it does not exist yet.
+type SiblingStoreFunc func(collection string, dimension int) (VectorStore, error)
+
+// SetSiblingStores lets bots switch to embedding models the vector store has no vector for.
+// The dense vectors of such a model are kept in a sibling collection named after the model,
+// with a copy of the payload of each memory; the vector store keeps the rest of the memory.
+func (s *Service) SetSiblingStores(collection string, open SiblingStoreFunc) {
+	s.siblingMu.Lock()
+	defer s.siblingMu.Unlock()
+	s.siblingCollection = strings.TrimSpace(collection)
+	s.openSibling = open
+	s.siblings = map[string]VectorStore{}
+}
+
+// inSibling reports whether the dense vectors of a model are kept in its sibling collection.
+func (s *Service) inSibling(modelID string) bool {
+	if modelID == "" || s.openSibling == nil || s.store == nil || !s.store.UsesNamedVectors() {
+		return false
+	}
+	return !s.store.AcceptsVector(modelID, 0)
+}
+
+// siblingStore returns the sibling collection of a model, opening it on first use. The
+// dimension is only used to create a collection that does not exist.
+func (s *Service) siblingStore(modelID string, dimension int) (VectorStore, error) {
+	name := siblingCollectionName(s.siblingCollection, modelID)
+	s.siblingMu.Lock()
+	defer s.siblingMu.Unlock()
+	if store, ok := s.siblings[name]; ok {
+		return store, nil
+	}
+	store, err := s.openSibling(name, dimension)
+	if err != nil {
+		return nil, fmt.Errorf("open vector collection %s: %w", name, err)
+	}
+	s.siblings[name] = store
+	return store, nil
+}
+
+// openedSibling returns the sibling collection of a model if it was opened already.
+func (s *Service) openedSibling(modelID string) VectorStore {
+	s.siblingMu.Lock()
+	defer s.siblingMu.Unlock()
+	return s.siblings[siblingCollectionName(s.siblingCollection, modelID)]
+}
+
+// denseStore returns the store the dense vectors named after a model are searched in.
+func (s *Service) denseStore(vectorName string) (VectorStore, error) {
+	if !s.inSibling(vectorName) {
+		return s.store, nil
+	}
+	return s.siblingStore(vectorName, 0)
+}
+
+// upsertPoint writes a memory. A dense vector the vector store cannot hold goes to the
+// sibling collection of its model, and the vector store keeps the memory without it.
+func (s *Service) upsertPoint(ctx context.Context, point VectorPoint) error {
+	if len(point.Vector) == 0 || !s.inSibling(point.VectorName) {
+		return s.store.Upsert(ctx, []VectorPoint{point})
+	}
+	sibling, err := s.siblingStore(point.VectorName, len(point.Vector))
+	if err != nil {
+		return err
+	}
+	if err := sibling.Upsert(ctx, []VectorPoint{{ID: point.ID, Vector: point.Vector, Payload: point.Payload}}); err != nil {
+		return err
+	}
+	point.Vector = nil
+	point.VectorName = ""
+	return s.store.Upsert(ctx, []VectorPoint{point})
+}
+
+// botSiblings returns the sibling collections that may hold vectors of the memories of a bot:
+// the one of its embedding model and the one a running re-embedding job fills.
+func (s *Service) botSiblings(ctx context.Context, botID string) ([]VectorStore, error) {
+	if s.openSibling == nil || strings.TrimSpace(botID) == "" {
+		return nil, nil
+	}
+	var stores []VectorStore
+	if modelID := s.embeddingModel(ctx, botID); s.inSibling(modelID) {
+		store, err := s.siblingStore(modelID, 0)
+		if err != nil {
+			return nil, err
+		}
+		stores = append(stores, store)
+	}
+	s.reindexMu.Lock()
+	run := s.reindexRuns[botID]
+	s.reindexMu.Unlock()
+	if run != nil && s.inSibling(run.job.ModelID) {
+		// The job opens the collection with its first vector; before that it holds nothing.
+		if store := s.openedSibling(run.job.ModelID); store != nil && (len(stores) == 0 || stores[0] != store) {
+			stores = append(stores, store)
+		}
+	}
+	return stores, nil
+}
+
+// deleteFromSiblings removes a memory of a bot from the sibling collections holding its vectors.
+func (s *Service) deleteFromSiblings(ctx context.Context, botID, id string) error {
+	stores, err := s.botSiblings(ctx, botID)
+	if err != nil {
+		return err
+	}
+	for _, store := range stores {
+		if err := store.Delete(ctx, id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// siblingCollectionName names the sibling collection of a model after the base collection.
+// Characters collection names cannot hold are replaced.
+func siblingCollectionName(collection, modelID string) string {
+	name := strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-', r == '_':
+			return r
+		default:
+			return '_'
+		}
+	}, modelID)
+	return collection + "__" + name
+}
@@ -1,6 +1,9 @@
 package memory
 
-import "context"
+import (
+	"context"
+	"time"
+)
 
 // LLM is the interface for LLM operations needed by memory service
 type LLM interface {
@@ -15,28 +18,32 @@ type Message struct {
 }
 
 type AddRequest struct {
-	Message          string                 `json:"message,omitempty"`
-	Messages         []Message              `json:"messages,omitempty"`
-	BotID            string                 `json:"bot_id,omitempty"`
-	SessionID        string                 `json:"session_id,omitempty"`
-	AgentID          string                 `json:"agent_id,omitempty"`
-	RunID            string                 `json:"run_id,omitempty"`
+	Message          string         `json:"message,omitempty"`
+	Messages         []Message      `json:"messages,omitempty"`
+	BotID            string         `json:"bot_id,omitempty"`
+	SessionID        string         `json:"session_id,omitempty"`
+	HistoryID        string         `json:"history_id,omitempty"`
+	ContactID        string         `json:"contact_id,omitempty"`
+	Scope            string         `json:"scope,omitempty"`
+	AgentID          string         `json:"agent_id,omitempty"`
+	RunID            string         `json:"run_id,omitempty"`
 	Metadata         map[string]any `json:"metadata,omitempty"`
 	Filters          map[string]any `json:"filters,omitempty"`
-	Infer            *bool                  `json:"infer,omitempty"`
-	EmbeddingEnabled *bool                  `json:"embedding_enabled,omitempty"`
+	Infer            *bool          `json:"infer,omitempty"`
+	EmbeddingEnabled *bool          `json:"embedding_enabled,omitempty"`
 }
 
 type SearchRequest struct {
-	Query            string                 `json:"query"`
-	BotID            string                 `json:"bot_id,omitempty"`
-	SessionID        string                 `json:"session_id,omitempty"`
-	AgentID          string                 `json:"agent_id,omitempty"`
-	RunID            string                 `json:"run_id,omitempty"`
-	Limit            int                    `json:"limit,omitempty"`
+	Query            string         `json:"query"`
+	BotID            string         `json:"bot_id,omitempty"`
+	SessionID        string         `json:"session_id,omitempty"`
+	ContactID        string         `json:"contact_id,omitempty"`
+	AgentID          string         `json:"agent_id,omitempty"`
+	RunID            string         `json:"run_id,omitempty"`
+	Limit            int            `json:"limit,omitempty"`
 	Filters          map[string]any `json:"filters,omitempty"`
-	Sources          []string               `json:"sources,omitempty"`
-	EmbeddingEnabled *bool                  `json:"embedding_enabled,omitempty"`
+	Sources          []string       `json:"sources,omitempty"`
+	EmbeddingEnabled *bool          `json:"embedding_enabled,omitempty"`
 }
 
 type UpdateRequest struct {
@@ -67,15 +74,17 @@ type EmbedInput struct {
 }
 
 type EmbedUpsertRequest struct {
-	Type      string                 `json:"type"`
-	Provider  string                 `json:"provider,omitempty"`
-	Model     string                 `json:"model,omitempty"`
-	Input     EmbedInput             `json:"input"`
-	Source    string                 `json:"source,omitempty"`
-	BotID     string                 `json:"bot_id,omitempty"`
-	SessionID string                 `json:"session_id,omitempty"`
-	AgentID   string                 `json:"agent_id,omitempty"`
-	RunID     string                 `json:"run_id,omitempty"`
+	Type      string         `json:"type"`
+	Provider  string         `json:"provider,omitempty"`
+	Model     string         `json:"model,omitempty"`
+	Input     EmbedInput     `json:"input"`
+	Source    string         `json:"source,omitempty"`
+	BotID     string         `json:"bot_id,omitempty"`
+	SessionID string         `json:"session_id,omitempty"`
+	ContactID string         `json:"contact_id,omitempty"`
+	Scope     string         `json:"scope,omitempty"`
+	AgentID   string         `json:"agent_id,omitempty"`
+	RunID     string         `json:"run_id,omitempty"`
 	Metadata  map[string]any `json:"metadata,omitempty"`
 	Filters   map[string]any `json:"filters,omitempty"`
 }
@@ -88,17 +97,19 @@ type EmbedUpsertResponse struct {
 }
 
 type MemoryItem struct {
-	ID        string                 `json:"id"`
-	Memory    string                 `json:"memory"`
-	Hash      string                 `json:"hash,omitempty"`
-	CreatedAt string                 `json:"createdAt,omitempty"`
-	UpdatedAt string                 `json:"updatedAt,omitempty"`
-	Score     float64                `json:"score,omitempty"`
+	ID        string         `json:"id"`
+	Memory    string         `json:"memory"`
+	Hash      string         `json:"hash,omitempty"`
+	CreatedAt string         `json:"createdAt,omitempty"`
+	UpdatedAt string         `json:"updatedAt,omitempty"`
+	Score     float64        `json:"score,omitempty"`
 	Metadata  map[string]any `json:"metadata,omitempty"`
-	BotID     string                 `json:"botId,omitempty"`
-	SessionID string                 `json:"sessionId,omitempty"`
-	AgentID   string                 `json:"agentId,omitempty"`
-	RunID     string                 `json:"runId,omitempty"`
+	BotID     string         `json:"botId,omitempty"`
+	SessionID string         `json:"sessionId,omitempty"`
+	ContactID string         `json:"contactId,omitempty"`
+	Scope     string         `json:"scope,omitempty"`
+	AgentID   string         `json:"agentId,omitempty"`
+	RunID     string         `json:"runId,omitempty"`
 }
 
 type SearchResponse struct {
@@ -110,8 +121,58 @@ type DeleteResponse struct {
 	Message string `json:"message"`
 }
 
+// HistoryEntry is one change made to a memory. Memory events are ADD, UPDATE and DELETE.
+type HistoryEntry struct {
+	ID           string         `json:"id"`
+	MemoryID     string         `json:"memory_id"`
+	BotID        string         `json:"bot_id,omitempty"`
+	Event        string         `json:"event"`
+	OldMemory    string         `json:"old_memory,omitempty"`
+	NewMemory    string         `json:"new_memory,omitempty"`
+	Payload      map[string]any `json:"-"`
+	SessionID    string         `json:"session_id,omitempty"`
+	HistoryID    string         `json:"history_id,omitempty"`
+	Model        string         `json:"model,omitempty"`
+	RestoredFrom string         `json:"restored_from,omitempty"`
+	CreatedAt    time.Time      `json:"created_at"`
+}
+
+type HistoryResponse struct {
+	Results []HistoryEntry `json:"results"`
+}
+
+type RestoreRequest struct {
+	BotID            string `json:"bot_id"`
+	MemoryID         string `json:"memory_id"`
+	HistoryEntryID   string `json:"history_entry_id"`
+	EmbeddingEnabled *bool  `json:"embedding_enabled,omitempty"`
+}
+
+type ReindexJob struct {
+	ID         string     `json:"id"`
+	BotID      string     `json:"bot_id"`
+	ModelID    string     `json:"model_id"`
+	Status     string     `json:"status"`
+	Total      int        `json:"total"`
+	Processed  int        `json:"processed"`
+	Error      string     `json:"error,omitempty"`
+	StartedAt  time.Time  `json:"started_at"`
+	FinishedAt *time.Time `json:"finished_at,omitempty"`
+}
+
+type ReindexRequest struct {
+	BotID   string `json:"bot_id"`
+	ModelID string `json:"model_id"`
+}
+
+type ReindexStatusResponse struct {
+	// ModelID is the embedding model the memories of the bot are searched with.
+	ModelID string      `json:"model_id,omitempty"`
+	Job     *ReindexJob `json:"job,omitempty"`
+}
+
 type ExtractRequest struct {
-	Messages []Message              `json:"messages"`
+	Messages []Message      `json:"messages"`
 	Filters  map[string]any `json:"filters,omitempty"`
 	Metadata map[string]any `json:"metadata,omitempty"`
 }
@@ -121,16 +182,16 @@ type ExtractResponse struct {
 }
 
 type CandidateMemory struct {
-	ID       string                 `json:"id"`
-	Memory   string                 `json:"memory"`
+	ID       string         `json:"id"`
+	Memory   string         `json:"memory"`
 	Metadata map[string]any `json:"metadata,omitempty"`
 }
 
 type DecideRequest struct {
-	Facts      []string               `json:"facts"`
-	Candidates []CandidateMemory      `json:"candidates"`
-	Filters    map[string]any `json:"filters,omitempty"`
-	Metadata   map[string]any `json:"metadata,omitempty"`
+	Facts      []string          `json:"facts"`
+	Candidates []CandidateMemory `json:"candidates"`
+	Filters    map[string]any    `json:"filters,omitempty"`
+	Metadata   map[string]any    `json:"metadata,omitempty"`
 }
 
 type DecisionAction struct {
@@ -142,4 +203,6 @@ type DecisionAction struct {
 
 type DecideResponse struct {
 	Actions []DecisionAction `json:"actions"`
+	// Model is the LLM model that decided, kept in the history of the memories it changed.
+	Model string `json:"model,omitempty"`
 }
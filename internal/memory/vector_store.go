@@ -0,0 +1,86 @@
+package memory
+
+import (
+	"context"
+)
+
+// VectorStore stores memory points with a dense vector per embedding model and a BM25
+// sparse vector, and searches them by either. Filters match payload fields: strings, bools
+// and integers by equality, floats and {"gte","gt","lte","lt"} maps by range.
+type VectorStore interface {
+	Upsert(ctx context.Context, points []VectorPoint) error
+	Search(ctx context.Context, vector []float32, limit int, filters map[string]any, vectorName string) ([]VectorPoint, []float64, error)
+	SearchSparse(ctx context.Context, indices []uint32, values []float32, limit int, filters map[string]any) ([]VectorPoint, []float64, error)
+	Get(ctx context.Context, id string) (*VectorPoint, error)
+	Delete(ctx context.Context, id string) error
+	List(ctx context.Context, limit int, filters map[string]any) ([]VectorPoint, error)
+	// Scroll returns a page of points in ID order and the offset of the next page, which is
+	// empty after the last page.
+	Scroll(ctx context.Context, limit int, filters map[string]any, offset string) ([]VectorPoint, string, error)
+	DeleteAll(ctx context.Context, filters map[string]any) error
+	// UpdateVectors sets the named dense vector of existing points and keeps their payload
+	// and other vectors. Points that no longer exist are skipped.
+	UpdateVectors(ctx context.Context, points []VectorPoint) error
+	// AcceptsVector reports whether points can hold a named dense vector of the dimensions,
+	// or of any dimensions when dimensions is 0.
+	AcceptsVector(name string, dimensions int) bool
+	UsesNamedVectors() bool
+	SparseVectorName() string
+}
+
+type VectorPoint struct {
+	ID               string         `json:"id"`
+	Vector           []float32      `json:"vector"`
+	VectorName       string         `json:"vector_name,omitempty"`
+	SparseIndices    []uint32       `json:"sparse_indices,omitempty"`
+	SparseValues     []float32      `json:"sparse_values,omitempty"`
+	SparseVectorName string         `json:"sparse_vector_name,omitempty"`
+	Payload          map[string]any `json:"payload,omitempty"`
+}
+
+func searchBySources(ctx context.Context, store VectorStore, vector []float32, limit int, filters map[string]any, sources []string, vectorName string) (map[string][]VectorPoint, map[string][]float64, error) {
+	pointsBySource := make(map[string][]VectorPoint, len(sources))
+	scoresBySource := make(map[string][]float64, len(sources))
+	for _, source := range sources {
+		merged := cloneFilters(filters)
+		if source != "" {
+			merged["source"] = source
+		}
+		points, scores, err := store.Search(ctx, vector, limit, merged, vectorName)
+		if err != nil {
+			return nil, nil, err
+		}
+		pointsBySource[source] = points
+		scoresBySource[source] = scores
+	}
+	return pointsBySource, scoresBySource, nil
+}
+
+func searchSparseBySources(ctx context.Context, store VectorStore, indices []uint32, values []float32, limit int, filters map[string]any, sources []string) (map[string][]VectorPoint, map[string][]float64, error) {
+	pointsBySource := make(map[string][]VectorPoint, len(sources))
+	scoresBySource := make(map[string][]float64, len(sources))
+	for _, source := range sources {
+		merged := cloneFilters(filters)
+		if source != "" {
+			merged["source"] = source
+		}
+		points, scores, err := store.SearchSparse(ctx, indices, values, limit, merged)
+		if err != nil {
+			return nil, nil, err
+		}
+		pointsBySource[source] = points
+		scoresBySource[source] = scores
+	}
+	return pointsBySource, scoresBySource, nil
+}
+
+func cloneFilters(filters map[string]any) map[string]any {
+	if len(filters) == 0 {
+		return map[string]any{}
+	}
+	clone := make(map[string]any, len(filters))
+	for key, value := range filters {
+		clone[key] = value
+	}
+	return clone
+}
@@ -0,0 +1,394 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// fakeVectorStore 是进程内的 VectorStore，过滤、检索和分页语义与 Qdrant、pgvector 一致，
+// 让契约用例和依赖这些语义的测试无需外部服务也能运行。
+type fakeVectorStore struct {
+	mu      sync.Mutex
+	dims    map[string]int
+	points  map[string]VectorPoint
+	dense   map[string]map[string][]float32
+	sparse  map[string]map[uint32]float32
+	ordered []string
+}
+
+func newFakeVectorStore(dims map[string]int) *fakeVectorStore {
+	return &fakeVectorStore{
+		dims:   dims,
+		points: map[string]VectorPoint{},
+		dense:  map[string]map[string][]float32{},
+		sparse: map[string]map[uint32]float32{},
+	}
+}
+
+func (f *fakeVectorStore) Upsert(ctx context.Context, points []VectorPoint) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, point := range points {
+		if len(point.Vector) > 0 && !f.acceptsLocked(point.VectorName, len(point.Vector)) {
+			return fmt.Errorf("vector %q of %d dimensions not configured", point.VectorName, len(point.Vector))
+		}
+		if len(point.SparseIndices) != len(point.SparseValues) {
+			return fmt.Errorf("sparse vector has %d indices and %d values", len(point.SparseIndices), len(point.SparseValues))
+		}
+		// 写入替换点的载荷和全部向量
+		f.points[point.ID] = VectorPoint{ID: point.ID, Payload: point.Payload}
+		f.dense[point.ID] = map[string][]float32{}
+		if len(point.Vector) > 0 {
+			f.dense[point.ID][point.VectorName] = point.Vector
+		}
+		f.sparse[point.ID] = map[uint32]float32{}
+		for i, index := range point.SparseIndices {
+			f.sparse[point.ID][index] = point.SparseValues[i]
+		}
+	}
+	f.ordered = nil
+	return nil
+}
+
+func (f *fakeVectorStore) UpdateVectors(ctx context.Context, points []VectorPoint) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, point := range points {
+		if !f.acceptsLocked(point.VectorName, len(point.Vector)) {
+			return fmt.Errorf("vector %q of %d dimensions not configured", point.VectorName, len(point.Vector))
+		}
+		if vectors, ok := f.dense[point.ID]; ok {
+			vectors[point.VectorName] = point.Vector
+		}
+	}
+	return nil
+}
+
+func (f *fakeVectorStore) Search(ctx context.Context, vector []float32, limit int, filters map[string]any, vectorName string) ([]VectorPoint, []float64, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.rankLocked(limit, filters, func(id string) (float64, bool) {
+		stored, ok := f.dense[id][vectorName]
+		if !ok {
+			return 0, false
+		}
+		return cosineSimilarity(vector, stored), true
+	})
+}
+
+func (f *fakeVectorStore) SearchSparse(ctx context.Context, indices []uint32, values []float32, limit int, filters map[string]any) ([]VectorPoint, []float64, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.rankLocked(limit, filters, func(id string) (float64, bool) {
+		score := 0.0
+		for i, index := range indices {
+			score += float64(values[i]) * float64(f.sparse[id][index])
+		}
+		// 与 pgvector 一样，没有共同词项的点不算命中
+		return score, score > 0
+	})
+}
+
+// rankLocked 按分数从高到低返回匹配过滤条件且有分数的点
+func (f *fakeVectorStore) rankLocked(limit int, filters map[string]any, score func(id string) (float64, bool)) ([]VectorPoint, []float64, error) {
+	type scored struct {
+		point VectorPoint
+		score float64
+	}
+	var hits []scored
+	for _, id := range f.idsLocked() {
+		point := f.points[id]
+		if !matchesFakeFilters(point.Payload, filters) {
+			continue
+		}
+		if value, ok := score(id); ok {
+			hits = append(hits, scored{point: point, score: value})
+		}
+	}
+	sort.SliceStable(hits, func(i, j int) bool { return hits[i].score > hits[j].score })
+	if limit > 0 && len(hits) > limit {
+		hits = hits[:limit]
+	}
+	points := make([]VectorPoint, 0, len(hits))
+	scores := make([]float64, 0, len(hits))
+	for _, hit := range hits {
+		points = append(points, hit.point)
+		scores = append(scores, hit.score)
+	}
+	return points, scores, nil
+}
+
+func (f *fakeVectorStore) Get(ctx context.Context, id string) (*VectorPoint, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	point, ok := f.points[id]
+	if !ok {
+		return nil, nil
+	}
+	return &point, nil
+}
+
+func (f *fakeVectorStore) Delete(ctx context.Context, id string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.deleteLocked(id)
+	return nil
+}
+
+func (f *fakeVectorStore) List(ctx context.Context, limit int, filters map[string]any) ([]VectorPoint, error) {
+	points, _, err := f.Scroll(ctx, limit, filters, "")
+	return points, err
+}
+
+func (f *fakeVectorStore) Scroll(ctx context.Context, limit int, filters map[string]any, offset string) ([]VectorPoint, string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var points []VectorPoint
+	for _, id := range f.idsLocked() {
+		if offset != "" && id < offset {
+			continue
+		}
+		if !matchesFakeFilters(f.points[id].Payload, filters) {
+			continue
+		}
+		if limit > 0 && len(points) == limit {
+			return points, id, nil
+		}
+		points = append(points, f.points[id])
+	}
+	return points, "", nil
+}
+
+func (f *fakeVectorStore) DeleteAll(ctx context.Context, filters map[string]any) error {
+	if len(filters) == 0 {
+		return fmt.Errorf("delete all requires filters")
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, id := range f.idsLocked() {
+		if matchesFakeFilters(f.points[id].Payload, filters) {
+			f.deleteLocked(id)
+		}
+	}
+	return nil
+}
+
+func (f *fakeVectorStore) AcceptsVector(name string, dimensions int) bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.acceptsLocked(name, dimensions)
+}
+
+func (f *fakeVectorStore) acceptsLocked(name string, dimensions int) bool {
+	dim, ok := f.dims[name]
+	return ok && (dimensions <= 0 || dim == dimensions)
+}
+
+func (f *fakeVectorStore) UsesNamedVectors() bool   { return true }
+func (f *fakeVectorStore) SparseVectorName() string { return "sparse" }
+
+func (f *fakeVectorStore) deleteLocked(id string) {
+	delete(f.points, id)
+	delete(f.dense, id)
+	delete(f.sparse, id)
+	f.ordered = nil
+}
+
+// idsLocked 返回按 ID 排序的点，检索同分时也按 ID 排列
+func (f *fakeVectorStore) idsLocked() []string {
+	if f.ordered == nil {
+		f.ordered = make([]string, 0, len(f.points))
+		for id := range f.points {
+			f.ordered = append(f.ordered, id)
+		}
+		sort.Strings(f.ordered)
+	}
+	return f.ordered
+}
+
+// matchesFakeFilters 按 buildPgvectorFilter 的语义判断载荷是否匹配全部过滤条件
+func matchesFakeFilters(payload map[string]any, filters map[string]any) bool {
+	for key, want := range filters {
+		if !matchesFakeFilter(payload, key, want) {
+			return false
+		}
+	}
+	return true
+}
+
+func matchesFakeFilter(payload map[string]any, key string, want any) bool {
+	if alternatives, ok := want.(filterAnyOf); ok {
+		for _, filters := range alternatives {
+			if matchesFakeFilters(payload, filters) {
+				return true
+			}
+		}
+		return false
+	}
+	got, found := fakePayloadValue(payload, key)
+	switch typed := want.(type) {
+	case nil:
+		return !found || got == nil
+	case bool:
+		return found && got == typed
+	case string:
+		return found && got != nil && fmt.Sprint(got) == typed
+	case map[string]any:
+		value, ok := toFloat(got)
+		if !found || !ok {
+			return false
+		}
+		for op, raw := range typed {
+			bound, ok := toFloat(raw)
+			if !ok {
+				continue
+			}
+			switch op {
+			case "gte":
+				ok = value >= bound
+			case "gt":
+				ok = value > bound
+			case "lte":
+				ok = value <= bound
+			case "lt":
+				ok = value < bound
+			}
+			if !ok {
+				return false
+			}
+		}
+		return true
+	}
+	if number, ok := toFloat(want); ok {
+		value, isNumber := toFloat(got)
+		return found && isNumber && value == number
+	}
+	return found && fmt.Sprint(got) == fmt.Sprint(want)
+}
+
+func fakePayloadValue(payload map[string]any, key string) (any, bool) {
+	var current any = payload
+	for _, segment := range strings.Split(key, ".") {
+		fields, ok := current.(map[string]any)
+		if !ok {
+			return nil, false
+		}
+		if current, ok = fields[segment]; !ok {
+			return nil, false
+		}
+	}
+	return current, true
+}
+
+func cosineSimilarity(a, b []float32) float64 {
+	var dot, normA, normB float64
+	for i := range a {
+		if i >= len(b) {
+			break
+		}
+		dot += float64(a[i]) * float64(b[i])
+		normA += float64(a[i]) * float64(a[i])
+		normB += float64(b[i]) * float64(b[i])
+	}
+	if normA == 0 || normB == 0 {
+		return 0
+	}
+	return dot / (math.Sqrt(normA) * math.Sqrt(normB))
+}
+
+// fixedEmbedder 按文本返回预设的向量
+type fixedEmbedder map[string][]float32
+
+func (e fixedEmbedder) Embed(ctx context.Context, input string) ([]float32, error) {
+	vector, ok := e[input]
+	if !ok {
+		return nil, fmt.Errorf("no vector for %q", input)
+	}
+	return vector, nil
+}
+
+func (e fixedEmbedder) Dimensions() int { return 3 }
+
+func TestService_HybridSearchScopeAndFusion(t *testing.T) {
+	ctx := context.Background()
+	store := newFakeVectorStore(contractVectors)
+	bm25 := NewBM25Indexer(nil)
+	llm := &MockLLM{
+		DetectLanguageFunc: func(ctx context.Context, text string) (string, error) {
+			return "en", nil
+		},
+	}
+	s := NewService(slog.Default(), llm, fixedEmbedder{"tea": {1, 0, 0}}, store, nil, bm25, "model-a", "")
+
+	// 说话人是会话 s1 中的联系人 c1
+	memories := []struct {
+		id, text string
+		vector   []float32
+		payload  map[string]any
+	}{
+		{"m1", "tea", []float32{1, 0, 0}, map[string]any{"botId": "b1", "scope": ScopeBot}},
+		{"m2", "green tea", nil, map[string]any{"botId": "b1", "scope": ScopeSession, "sessionId": "s1"}},
+		{"m3", "coffee", []float32{1, 1, 0}, map[string]any{"botId": "b1", "sessionId": "s1"}},
+		{"m4", "music", []float32{0, 1, 0}, map[string]any{"botId": "b1", "scope": ScopeContact, "contactId": "c1", "sessionId": "s9"}},
+		{"m5", "tea", []float32{1, 0, 0}, map[string]any{"botId": "b1", "scope": ScopeSession, "sessionId": "s2"}},
+		{"m6", "tea", []float32{1, 0, 0}, map[string]any{"botId": "b1", "scope": ScopeContact, "contactId": "c2", "sessionId": "s1"}},
+		{"m7", "tea", []float32{1, 0, 0}, map[string]any{"botId": "b2", "scope": ScopeBot}},
+		{"m8", "tea", []float32{1, 0, 0}, map[string]any{"botId": "b1", "sessionId": "s2"}},
+	}
+	for _, memory := range memories {
+		termFreq, docLen, err := bm25.TermFrequencies("en", memory.text)
+		if err != nil {
+			t.Fatalf("term frequencies: %v", err)
+		}
+		indices, values := bm25.AddDocument("en", termFreq, docLen)
+		memory.payload["data"] = memory.text
+		point := VectorPoint{ID: memory.id, Vector: memory.vector, SparseIndices: indices, SparseValues: values, Payload: memory.payload}
+		if len(memory.vector) > 0 {
+			point.VectorName = "model-a"
+		}
+		if err := store.Upsert(ctx, []VectorPoint{point}); err != nil {
+			t.Fatalf("upsert: %v", err)
+		}
+	}
+
+	enabled := true
+	resp, err := s.Search(ctx, SearchRequest{Query: "tea", BotID: "b1", SessionID: "s1", ContactID: "c1", Limit: 10, EmbeddingEnabled: &enabled})
+	if err != nil {
+		t.Fatalf("search failed: %v", err)
+	}
+	ids := make([]string, 0, len(resp.Results))
+	for _, item := range resp.Results {
+		ids = append(ids, item.ID)
+	}
+	// 其他会话、其他联系人和其他机器人的记忆不可见
+	if got := strings.Join(ids, ","); got != "m1,m2,m3,m4" {
+		t.Fatalf("unexpected results: %s", got)
+	}
+	// m1 在稠密和 BM25 排名中都是第一，m2 只在 BM25 中排第二
+	if want := 2 / (rrfK + 1); math.Abs(resp.Results[0].Score-want) > 1e-12 {
+		t.Fatalf("m1 should have the fused score of two first ranks: %v, want %v", resp.Results[0].Score, want)
+	}
+	if want := 1 / (rrfK + 2); math.Abs(resp.Results[1].Score-want) > 1e-12 {
+		t.Fatalf("m2 should have the score of a second rank: %v, want %v", resp.Results[1].Score, want)
+	}
+
+	// 没有说话人时不按范围过滤，但仍只检索当前机器人的记忆
+	resp, err = s.Search(ctx, SearchRequest{Query: "tea", BotID: "b1", Limit: 10, EmbeddingEnabled: &enabled})
+	if err != nil {
+		t.Fatalf("search failed: %v", err)
+	}
+	ids = ids[:0]
+	for _, item := range resp.Results {
+		ids = append(ids, item.ID)
+	}
+	sort.Strings(ids)
+	if got := strings.Join(ids, ","); got != "m1,m2,m3,m4,m5,m6,m8" {
+		t.Fatalf("without a speaker every memory of the bot is searched: %s", got)
+	}
+}
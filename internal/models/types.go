@@ -16,6 +16,7 @@ const (
 const (
 	ModelInputText  = "text"
 	ModelInputImage = "image"
+	ModelInputAudio = "audio"
 )
 
 type ClientType string
@@ -11,9 +11,12 @@ import (
 )
 
 type Decision struct {
-	BotID      string
-	BotType    string
-	AllowGuest bool
+	BotID                 string
+	BotType               string
+	AllowGuest            bool
+	UnifiedSession        bool
+	MessageDebounceMs     int
+	InterruptOnNewMessage bool
 }
 
 type Service struct {
@@ -50,9 +53,12 @@ func (s *Service) Resolve(ctx context.Context, botID string) (Decision, error) {
 		return Decision{}, err
 	}
 	decision := Decision{
-		BotID:      botID,
-		BotType:    strings.TrimSpace(bot.Type),
-		AllowGuest: botSettings.AllowGuest,
+		BotID:                 botID,
+		BotType:               strings.TrimSpace(bot.Type),
+		AllowGuest:            botSettings.AllowGuest,
+		UnifiedSession:        botSettings.UnifiedSession,
+		MessageDebounceMs:     botSettings.MessageDebounceMs,
+		InterruptOnNewMessage: botSettings.InterruptOnNewMessage,
 	}
 	if decision.BotType == bots.BotTypePersonal {
 		decision.AllowGuest = false
@@ -14,7 +14,10 @@ import (
 	"github.com/memohai/memoh/internal/db/sqlc"
 )
 
-var ErrKeyNotFound = errors.New("preauth key not found")
+var (
+	ErrKeyNotFound = errors.New("preauth key not found")
+	ErrKeyUsed     = errors.New("preauth key already used")
+)
 
 type Service struct {
 	queries *sqlc.Queries
@@ -25,6 +28,19 @@ func NewService(queries *sqlc.Queries) *Service {
 }
 
 func (s *Service) Issue(ctx context.Context, botID, issuedByUserID string, ttl time.Duration) (Key, error) {
+	return s.issue(ctx, botID, issuedByUserID, "", ttl)
+}
+
+// IssueContactLink issues a one-time code that links the channel identity redeeming it to
+// the given contact.
+func (s *Service) IssueContactLink(ctx context.Context, botID, contactID string, ttl time.Duration) (Key, error) {
+	if strings.TrimSpace(contactID) == "" {
+		return Key{}, fmt.Errorf("contact id is required")
+	}
+	return s.issue(ctx, botID, "", contactID, ttl)
+}
+
+func (s *Service) issue(ctx context.Context, botID, issuedByUserID, contactID string, ttl time.Duration) (Key, error) {
 	if s.queries == nil {
 		return Key{}, fmt.Errorf("preauth queries not configured")
 	}
@@ -43,12 +59,21 @@ func (s *Service) Issue(ctx context.Context, botID, issuedByUserID string, ttl t
 		}
 		pgIssuedBy = parsed
 	}
+	pgContactID := pgtype.UUID{Valid: false}
+	if strings.TrimSpace(contactID) != "" {
+		parsed, err := parseUUID(contactID)
+		if err != nil {
+			return Key{}, err
+		}
+		pgContactID = parsed
+	}
 	token := strings.ReplaceAll(uuid.NewString(), "-", "")[:8]
 	expiresAt := time.Now().UTC().Add(ttl)
 	row, err := s.queries.CreateBotPreauthKey(ctx, sqlc.CreateBotPreauthKeyParams{
 		BotID:          pgBotID,
 		Token:          token,
 		IssuedByUserID: pgIssuedBy,
+		ContactID:      pgContactID,
 		ExpiresAt:      pgtype.Timestamptz{Time: expiresAt, Valid: true},
 	})
 	if err != nil {
@@ -71,6 +96,8 @@ func (s *Service) Get(ctx context.Context, token string) (Key, error) {
 	return normalizeKey(row), nil
 }
 
+// MarkUsed redeems a key. It fails with ErrKeyUsed when the key was already redeemed, so a
+// key is redeemed once even when it is presented twice at the same time.
 func (s *Service) MarkUsed(ctx context.Context, id string) (Key, error) {
 	if s.queries == nil {
 		return Key{}, fmt.Errorf("preauth queries not configured")
@@ -81,6 +108,9 @@ func (s *Service) MarkUsed(ctx context.Context, id string) (Key, error) {
 	}
 	row, err := s.queries.MarkBotPreauthKeyUsed(ctx, pgID)
 	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return Key{}, ErrKeyUsed
+		}
 		return Key{}, err
 	}
 	return normalizeKey(row), nil
@@ -92,6 +122,7 @@ func normalizeKey(row sqlc.BotPreauthKey) Key {
 		BotID:          toUUIDString(row.BotID),
 		Token:          strings.TrimSpace(row.Token),
 		IssuedByUserID: toUUIDString(row.IssuedByUserID),
+		ContactID:      toUUIDString(row.ContactID),
 		ExpiresAt:      timeFromPg(row.ExpiresAt),
 		UsedAt:         timeFromPg(row.UsedAt),
 		CreatedAt:      timeFromPg(row.CreatedAt),
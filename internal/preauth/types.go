@@ -7,6 +7,7 @@ type Key struct {
 	BotID          string
 	Token          string
 	IssuedByUserID string
+	ContactID      string
 	ExpiresAt      time.Time
 	UsedAt         time.Time
 	CreatedAt      time.Time
@@ -0,0 +1,107 @@
+package router
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/memohai/memoh/internal/channel"
+	"github.com/memohai/memoh/internal/chat"
+)
+
+// maxInboundAttachmentBytes caps the size of an attachment forwarded to the agent.
+const maxInboundAttachmentBytes = 20 << 20
+
+// downloadAttachments fetches inbound attachments so they can be forwarded to the agent.
+// Attachments that are too large or cannot be fetched are skipped; the query still names them.
+func (p *ChannelInboundProcessor) downloadAttachments(ctx context.Context, cfg channel.ChannelConfig, msg channel.InboundMessage) []chat.Attachment {
+	if len(msg.Message.Attachments) == 0 {
+		return nil
+	}
+	var downloader channel.AttachmentDownloader
+	if p.registry != nil {
+		downloader, _ = p.registry.GetAttachmentDownloader(msg.Channel)
+	}
+	result := make([]chat.Attachment, 0, len(msg.Message.Attachments))
+	for _, att := range msg.Message.Attachments {
+		if att.Size > maxInboundAttachmentBytes {
+			if p.logger != nil {
+				p.logger.Info("attachment too large, skipped", slog.String("name", att.Name), slog.Int64("size", att.Size))
+			}
+			continue
+		}
+		data, err := p.fetchAttachment(ctx, cfg, downloader, att)
+		if err != nil {
+			if p.logger != nil {
+				p.logger.Warn("download attachment failed", slog.String("channel", msg.Channel.String()), slog.String("name", att.Name), slog.Any("error", err))
+			}
+			continue
+		}
+		result = append(result, chat.Attachment{
+			Type:    string(att.Type),
+			Name:    strings.TrimSpace(att.Name),
+			Mime:    strings.TrimSpace(att.Mime),
+			Caption: strings.TrimSpace(att.Caption),
+			Data:    data,
+		})
+	}
+	return result
+}
+
+func (p *ChannelInboundProcessor) fetchAttachment(ctx context.Context, cfg channel.ChannelConfig, downloader channel.AttachmentDownloader, att channel.Attachment) ([]byte, error) {
+	rawURL := strings.TrimSpace(att.URL)
+	var reader io.ReadCloser
+	switch {
+	case downloader != nil:
+		body, err := downloader.DownloadAttachment(ctx, cfg, att)
+		if err != nil {
+			return nil, err
+		}
+		reader = body
+	case strings.HasPrefix(rawURL, "data:"):
+		meta, payload, ok := strings.Cut(strings.TrimPrefix(rawURL, "data:"), ",")
+		if !ok || !strings.HasSuffix(meta, ";base64") {
+			return nil, fmt.Errorf("unsupported data url")
+		}
+		if base64.StdEncoding.DecodedLen(len(payload)) > maxInboundAttachmentBytes+2 {
+			return nil, fmt.Errorf("attachment exceeds %d bytes", maxInboundAttachmentBytes)
+		}
+		data, err := base64.StdEncoding.DecodeString(payload)
+		if err != nil {
+			return nil, fmt.Errorf("decode data url: %w", err)
+		}
+		return data, nil
+	case strings.HasPrefix(rawURL, "http://") || strings.HasPrefix(rawURL, "https://"):
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+		if err != nil {
+			return nil, err
+		}
+		resp, err := p.httpClient.Do(req)
+		if err != nil {
+			return nil, err
+		}
+		if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+			resp.Body.Close()
+			return nil, fmt.Errorf("download attachment: unexpected status %d", resp.StatusCode)
+		}
+		reader = resp.Body
+	default:
+		return nil, fmt.Errorf("attachment url is not downloadable")
+	}
+	defer reader.Close()
+	data, err := io.ReadAll(io.LimitReader(reader, maxInboundAttachmentBytes+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > maxInboundAttachmentBytes {
+		return nil, fmt.Errorf("attachment exceeds %d bytes", maxInboundAttachmentBytes)
+	}
+	if len(data) == 0 {
+		return nil, fmt.Errorf("attachment is empty")
+	}
+	return data, nil
+}
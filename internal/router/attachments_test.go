@@ -0,0 +1,109 @@
+package router
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/memohai/memoh/internal/channel"
+	"github.com/memohai/memoh/internal/chat"
+)
+
+type fakeDownloadAdapter struct {
+	fakeStreamAdapter
+	requested []string
+}
+
+func (a *fakeDownloadAdapter) DownloadAttachment(ctx context.Context, cfg channel.ChannelConfig, att channel.Attachment) (io.ReadCloser, error) {
+	a.requested = append(a.requested, att.URL)
+	return io.NopCloser(strings.NewReader("resource:" + att.URL)), nil
+}
+
+func TestChannelInboundProcessorForwardsAttachments(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/missing" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte("photo-bytes"))
+	}))
+	defer srv.Close()
+
+	store := &fakeConfigStore{
+		session: channel.ChannelSession{
+			SessionID: "telegram:bot-1:chat-1",
+			UserID:    "user-123",
+		},
+	}
+	gateway := &fakeChatGateway{
+		resp: chat.ChatResponse{
+			Messages: []chat.ModelMessage{
+				{Role: "assistant", Content: chat.NewTextContent("收到")},
+			},
+		},
+	}
+	processor := NewChannelInboundProcessor(slog.Default(), nil, store, gateway, &fakeContactService{}, &fakePolicyService{}, nil, "", 0)
+	sender := &fakeReplySender{}
+
+	cfg := channel.ChannelConfig{ID: "cfg-1", BotID: "bot-1", ChannelType: channel.ChannelType("telegram")}
+	msg := channel.InboundMessage{
+		Channel: channel.ChannelType("telegram"),
+		Message: channel.Message{
+			Attachments: []channel.Attachment{
+				{Type: channel.AttachmentImage, URL: srv.URL + "/photo.jpg", Caption: "看看这张图"},
+				{Type: channel.AttachmentFile, URL: "data:text/plain;base64,aGVsbG8=", Name: "hello.txt", Mime: "text/plain"},
+				{Type: channel.AttachmentFile, URL: srv.URL + "/missing", Name: "missing.bin"},
+				{Type: channel.AttachmentVideo, URL: srv.URL + "/big.mp4", Size: maxInboundAttachmentBytes + 1},
+			},
+		},
+		ReplyTarget: "target-id",
+		Conversation: channel.Conversation{
+			ID:   "chat-1",
+			Type: "p2p",
+		},
+	}
+
+	if err := processor.HandleInbound(context.Background(), cfg, msg, sender); err != nil {
+		t.Fatalf("不应报错: %v", err)
+	}
+	got := gateway.gotReq.Attachments
+	if len(got) != 2 {
+		t.Fatalf("应转发两个附件，实际: %+v", got)
+	}
+	if got[0].Type != "image" || string(got[0].Data) != "photo-bytes" || got[0].Caption != "看看这张图" {
+		t.Errorf("图片附件错误: %+v", got[0])
+	}
+	if got[1].Name != "hello.txt" || string(got[1].Data) != "hello" || got[1].Mime != "text/plain" {
+		t.Errorf("data url 附件错误: %+v", got[1])
+	}
+	if !strings.Contains(gateway.gotReq.Query, "[attachment:image]") {
+		t.Errorf("Query 应包含附件描述: %s", gateway.gotReq.Query)
+	}
+}
+
+func TestDownloadAttachmentsUsesAdapterDownloader(t *testing.T) {
+	adapter := &fakeDownloadAdapter{}
+	registry := channel.NewRegistry()
+	if err := registry.Register(adapter); err != nil {
+		t.Fatalf("注册 adapter 失败: %v", err)
+	}
+	processor := NewChannelInboundProcessor(slog.Default(), registry, &fakeConfigStore{}, &fakeChatGateway{}, &fakeContactService{}, &fakePolicyService{}, nil, "", 0)
+
+	msg := channel.InboundMessage{
+		Channel: adapter.Type(),
+		Message: channel.Message{
+			Attachments: []channel.Attachment{{Type: channel.AttachmentImage, URL: "img_key_1"}},
+		},
+	}
+	got := processor.downloadAttachments(context.Background(), channel.ChannelConfig{}, msg)
+	if len(got) != 1 || string(got[0].Data) != "resource:img_key_1" {
+		t.Fatalf("应通过 adapter 下载附件，实际: %+v", got)
+	}
+	if len(adapter.requested) != 1 {
+		t.Fatalf("adapter 下载调用次数错误: %v", adapter.requested)
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"log/slog"
+	"net/http"
 	"regexp"
 	"strings"
 	"time"
@@ -47,6 +48,11 @@ type ChannelInboundProcessor struct {
 	jwtSecret string
 	tokenTTL  time.Duration
 	identity  *IdentityResolver
+
+	commands        []command
+	commandServices CommandServices
+
+	httpClient *http.Client
 }
 
 func NewChannelInboundProcessor(log *slog.Logger, registry *channel.Registry, store channel.ConfigStore, chatGateway ChatGateway, contactService ContactService, policyService PolicyService, preauthService PreauthService, jwtSecret string, tokenTTL time.Duration) *ChannelInboundProcessor {
@@ -57,14 +63,18 @@ func NewChannelInboundProcessor(log *slog.Logger, registry *channel.Registry, st
 		tokenTTL = 5 * time.Minute
 	}
 	identityResolver := NewIdentityResolver(log, registry, store, contactService, policyService, preauthService, "", "")
-	return &ChannelInboundProcessor{
+	processor := &ChannelInboundProcessor{
 		chat:      chatGateway,
 		registry:  registry,
 		logger:    log.With(slog.String("component", "channel_router")),
 		jwtSecret: strings.TrimSpace(jwtSecret),
 		tokenTTL:  tokenTTL,
 		identity:  identityResolver,
+
+		httpClient: &http.Client{Timeout: 60 * time.Second},
 	}
+	processor.commands = processor.builtinCommands()
+	return processor
 }
 
 func (p *ChannelInboundProcessor) IdentityMiddleware() channel.Middleware {
@@ -74,6 +84,14 @@ func (p *ChannelInboundProcessor) IdentityMiddleware() channel.Middleware {
 	return p.identity.Middleware()
 }
 
+// SessionKeys returns the chat session resolver the channel manager serializes messages on.
+func (p *ChannelInboundProcessor) SessionKeys() channel.SessionKeyFunc {
+	if p == nil || p.identity == nil {
+		return nil
+	}
+	return p.identity.SessionKey
+}
+
 func (p *ChannelInboundProcessor) HandleInbound(ctx context.Context, cfg channel.ChannelConfig, msg channel.InboundMessage, sender channel.ReplySender) error {
 	if p.chat == nil {
 		return fmt.Errorf("channel inbound processor not configured")
@@ -81,7 +99,7 @@ func (p *ChannelInboundProcessor) HandleInbound(ctx context.Context, cfg channel
 	if sender == nil {
 		return fmt.Errorf("reply sender not configured")
 	}
-	text := buildInboundQuery(msg.Message)
+	text := inboundQueryText(msg)
 	if strings.TrimSpace(text) == "" {
 		return nil
 	}
@@ -89,8 +107,11 @@ func (p *ChannelInboundProcessor) HandleInbound(ctx context.Context, cfg channel
 	if err != nil {
 		return err
 	}
+	cmd, args, isCommand := p.matchCommand(cfg, msg.Message.PlainText())
+	addressed := isCommand || shouldRespond(cfg, msg, text)
 	if state.Decision != nil && state.Decision.Stop {
-		if !state.Decision.Reply.IsEmpty() {
+		// Messages the bot was not addressed by get no reply, nor are they kept as context.
+		if addressed && !state.Decision.Reply.IsEmpty() {
 			return sender.Send(ctx, channel.OutboundMessage{
 				Target:  strings.TrimSpace(msg.ReplyTarget),
 				Message: state.Decision.Reply,
@@ -98,8 +119,24 @@ func (p *ChannelInboundProcessor) HandleInbound(ctx context.Context, cfg channel
 		}
 		return nil
 	}
+	if !addressed {
+		p.recordAmbient(ctx, state.Identity.BotID, msg, text)
+		return nil
+	}
+	if isCommand {
+		return p.handleCommand(ctx, cfg, msg, state.Identity, cmd, args, sender)
+	}
+	input := turnInput{text: text, attachments: p.downloadAttachments(ctx, cfg, msg)}
+	return p.answerSession(ctx, cfg, msg, state.Identity, input, sender)
+}
 
-	identity := state.Identity
+// answer runs an agent round for the input of one or more coalesced messages and delivers
+// the replies to the conversation of msg.
+func (p *ChannelInboundProcessor) answer(ctx context.Context, msg channel.InboundMessage, identity InboundIdentity, input turnInput, sender channel.ReplySender) error {
+	if presence, ok := sender.(channel.PresenceReplySender); ok && strings.TrimSpace(msg.ReplyTarget) != "" {
+		stop := presence.StartPresence(ctx, msg)
+		defer stop()
+	}
 
 	sessionToken := ""
 	if p.jwtSecret != "" && strings.TrimSpace(msg.ReplyTarget) != "" {
@@ -134,40 +171,59 @@ func (p *ChannelInboundProcessor) HandleInbound(ctx context.Context, cfg channel
 	if p.registry != nil {
 		desc, _ = p.registry.GetDescriptor(msg.Channel)
 	}
-	resp, err := p.chat.Chat(ctx, chat.ChatRequest{
-		BotID:          identity.BotID,
-		SessionID:      identity.SessionID,
-		Token:          token,
-		UserID:         identity.UserID,
-		ContactID:      identity.ContactID,
-		ContactName:    strings.TrimSpace(identity.Contact.DisplayName),
-		ContactAlias:   strings.TrimSpace(identity.Contact.Alias),
-		ReplyTarget:    strings.TrimSpace(msg.ReplyTarget),
-		SessionToken:   sessionToken,
-		Query:          text,
-		CurrentChannel: msg.Channel.String(),
-		Channels:       []string{msg.Channel.String()},
-	})
+	req := chat.ChatRequest{
+		BotID:            identity.BotID,
+		SessionID:        chatSessionID(identity),
+		Token:            token,
+		UserID:           identity.UserID,
+		ContactID:        identity.ContactID,
+		ContactName:      strings.TrimSpace(identity.Contact.DisplayName),
+		ContactAlias:     strings.TrimSpace(identity.Contact.Alias),
+		ReplyTarget:      strings.TrimSpace(msg.ReplyTarget),
+		SessionToken:     sessionToken,
+		Query:            input.text,
+		CurrentChannel:   msg.Channel.String(),
+		Channels:         []string{msg.Channel.String()},
+		Attachments:      input.attachments,
+		AmbientSessionID: ambientSessionID(identity.BotID, msg),
+	}
+	if streamer, ok := p.chat.(ChatStreamer); ok && strings.TrimSpace(msg.ReplyTarget) != "" {
+		if mode := resolveStreamMode(desc.Capabilities, sender); mode != streamModeNone {
+			return p.streamReply(ctx, streamer, req, msg, desc, sender, mode)
+		}
+	}
+	resp, err := p.chat.Chat(ctx, req)
 	if err != nil {
 		if p.logger != nil {
 			p.logger.Error("chat gateway failed", slog.String("channel", msg.Channel.String()), slog.String("user_id", identity.UserID), slog.Any("error", err))
 		}
 		return err
 	}
-	outputs := chat.ExtractAssistantOutputs(resp.Messages)
-	if len(outputs) == 0 {
+	chargeRateLimit(ctx, resp.Usage)
+	if len(chat.ExtractAssistantOutputs(resp.Messages)) == 0 {
 		return nil
 	}
 	target := strings.TrimSpace(msg.ReplyTarget)
 	if target == "" {
 		return fmt.Errorf("reply target missing")
 	}
-	sentTexts, suppressReplies := collectMessageToolContext(p.registry, resp.Messages, msg.Channel, target)
+	return sendReplies(ctx, sender, target, buildReplyMessages(p.registry, resp.Messages, msg.Channel, target, desc.Capabilities))
+}
+
+// buildReplyMessages converts the assistant outputs of a round into channel messages,
+// dropping silent replies and text the agent already delivered with the send tool.
+func buildReplyMessages(registry *channel.Registry, messages []chat.ModelMessage, channelType channel.ChannelType, target string, capabilities channel.ChannelCapabilities) []channel.Message {
+	outputs := chat.ExtractAssistantOutputs(messages)
+	if len(outputs) == 0 {
+		return nil
+	}
+	sentTexts, suppressReplies := collectMessageToolContext(registry, messages, channelType, target)
 	if suppressReplies {
 		return nil
 	}
+	replies := make([]channel.Message, 0, len(outputs))
 	for _, output := range outputs {
-		outMessage := buildChannelMessage(output, desc.Capabilities)
+		outMessage := buildChannelMessage(output, capabilities)
 		if outMessage.IsEmpty() {
 			continue
 		}
@@ -178,14 +234,9 @@ func (p *ChannelInboundProcessor) HandleInbound(ctx context.Context, cfg channel
 		if isMessagingToolDuplicate(plainText, sentTexts) {
 			continue
 		}
-		if err := sender.Send(ctx, channel.OutboundMessage{
-			Target:  target,
-			Message: outMessage,
-		}); err != nil {
-			return err
-		}
+		replies = append(replies, outMessage)
 	}
-	return nil
+	return replies
 }
 
 func buildChannelMessage(output chat.AssistantOutput, capabilities channel.ChannelCapabilities) channel.Message {
@@ -287,6 +338,15 @@ func contentPartText(part chat.ContentPart) string {
 	return ""
 }
 
+// inboundQueryText returns the agent query of a message, including a pressed button.
+func inboundQueryText(msg channel.InboundMessage) string {
+	text := buildInboundQuery(msg.Message)
+	if msg.Action != nil {
+		text = buildActionQuery(*msg.Action, text)
+	}
+	return text
+}
+
 func buildInboundQuery(message channel.Message) string {
 	text := strings.TrimSpace(message.PlainText())
 	if len(message.Attachments) == 0 {
@@ -309,6 +369,23 @@ func buildInboundQuery(message channel.Message) string {
 	return strings.Join(lines, "\n")
 }
 
+// buildActionQuery turns a button press into a user turn the agent can act on, in the same
+// marker style as attachments.
+func buildActionQuery(action channel.InboundAction, text string) string {
+	value := strings.TrimSpace(action.Value)
+	if value == "" {
+		return text
+	}
+	line := fmt.Sprintf("[action:%s]", value)
+	if label := strings.TrimSpace(action.Label); label != "" && label != value {
+		line += " " + label
+	}
+	if strings.TrimSpace(text) == "" {
+		return line
+	}
+	return text + "\n" + line
+}
+
 func normalizeContentPartType(raw string) channel.MessagePartType {
 	switch strings.TrimSpace(strings.ToLower(raw)) {
 	case "link":
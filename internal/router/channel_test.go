@@ -120,6 +120,68 @@ func (s *fakeReplySender) Send(ctx context.Context, msg channel.OutboundMessage)
 	return nil
 }
 
+// testProcessorConfig 描述测试用 ChannelInboundProcessor 的依赖，未设置的依赖使用空的 fake
+type testProcessorConfig struct {
+	adapters []channel.Adapter
+	session  channel.ChannelSession
+	gateway  ChatGateway
+	contacts ContactService
+	decision policy.Decision
+	preauth  PreauthService
+}
+
+type testProcessorOption func(*testProcessorConfig)
+
+// withSession 设置会话路由返回的会话及其绑定用户
+func withSession(sessionID, userID string) testProcessorOption {
+	return func(cfg *testProcessorConfig) {
+		cfg.session = channel.ChannelSession{SessionID: sessionID, UserID: userID}
+	}
+}
+
+func withGateway(gateway ChatGateway) testProcessorOption {
+	return func(cfg *testProcessorConfig) { cfg.gateway = gateway }
+}
+
+// withAdapters 为 processor 创建注册了这些 adapter 的 registry
+func withAdapters(adapters ...channel.Adapter) testProcessorOption {
+	return func(cfg *testProcessorConfig) { cfg.adapters = append(cfg.adapters, adapters...) }
+}
+
+func withContacts(contacts ContactService) testProcessorOption {
+	return func(cfg *testProcessorConfig) { cfg.contacts = contacts }
+}
+
+func withDecision(decision policy.Decision) testProcessorOption {
+	return func(cfg *testProcessorConfig) { cfg.decision = decision }
+}
+
+func withPreauth(preauth PreauthService) testProcessorOption {
+	return func(cfg *testProcessorConfig) { cfg.preauth = preauth }
+}
+
+func newTestProcessor(t *testing.T, opts ...testProcessorOption) *ChannelInboundProcessor {
+	t.Helper()
+	cfg := testProcessorConfig{
+		gateway:  &fakeChatGateway{},
+		contacts: &fakeContactService{},
+	}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	var registry *channel.Registry
+	if len(cfg.adapters) > 0 {
+		registry = channel.NewRegistry()
+		for _, adapter := range cfg.adapters {
+			if err := registry.Register(adapter); err != nil {
+				t.Fatalf("注册 adapter 失败: %v", err)
+			}
+		}
+	}
+	store := &fakeConfigStore{session: cfg.session}
+	return NewChannelInboundProcessor(slog.Default(), registry, store, cfg.gateway, cfg.contacts, &fakePolicyService{decision: cfg.decision}, cfg.preauth, "", 0)
+}
+
 func TestChannelInboundProcessorBoundUser(t *testing.T) {
 	store := &fakeConfigStore{
 		session: channel.ChannelSession{
@@ -343,3 +405,57 @@ func TestChannelInboundProcessorDedupeWithToolSend(t *testing.T) {
 		t.Fatalf("工具发送文本与普通回复重复，应去重，实际: %+v", sender.sent)
 	}
 }
+
+type fakePresenceReplySender struct {
+	fakeReplySender
+	events []string
+}
+
+func (s *fakePresenceReplySender) Send(ctx context.Context, msg channel.OutboundMessage) error {
+	s.events = append(s.events, "send")
+	return s.fakeReplySender.Send(ctx, msg)
+}
+
+func (s *fakePresenceReplySender) StartPresence(ctx context.Context, msg channel.InboundMessage) func() {
+	s.events = append(s.events, "presence:"+msg.ReplyTarget)
+	return func() {
+		s.events = append(s.events, "stop")
+	}
+}
+
+func TestChannelInboundProcessorShowsPresenceUntilReplied(t *testing.T) {
+	store := &fakeConfigStore{
+		session: channel.ChannelSession{
+			SessionID: "feishu:bot-1:chat-1",
+			UserID:    "user-123",
+		},
+	}
+	gateway := &fakeChatGateway{
+		resp: chat.ChatResponse{
+			Messages: []chat.ModelMessage{
+				{Role: "assistant", Content: chat.NewTextContent("AI回复内容")},
+			},
+		},
+	}
+	processor := NewChannelInboundProcessor(slog.Default(), nil, store, gateway, &fakeContactService{}, &fakePolicyService{}, nil, "", 0)
+	sender := &fakePresenceReplySender{}
+
+	cfg := channel.ChannelConfig{ID: "cfg-1", BotID: "bot-1", ChannelType: channel.ChannelType("feishu")}
+	msg := channel.InboundMessage{
+		Channel:     channel.ChannelType("feishu"),
+		Message:     channel.Message{Text: "你好"},
+		ReplyTarget: "target-id",
+		Conversation: channel.Conversation{
+			ID:   "chat-1",
+			Type: "p2p",
+		},
+	}
+
+	if err := processor.HandleInbound(context.Background(), cfg, msg, sender); err != nil {
+		t.Fatalf("不应报错: %v", err)
+	}
+	want := []string{"presence:target-id", "send", "stop"}
+	if strings.Join(sender.events, ",") != strings.Join(want, ",") {
+		t.Fatalf("输入状态应在回复发送后结束，实际: %v", sender.events)
+	}
+}
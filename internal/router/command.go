@@ -0,0 +1,338 @@
+package router
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"unicode"
+
+	"github.com/memohai/memoh/internal/bots"
+	"github.com/memohai/memoh/internal/channel"
+	"github.com/memohai/memoh/internal/memory"
+	"github.com/memohai/memoh/internal/models"
+	"github.com/memohai/memoh/internal/settings"
+)
+
+// BotMemberService resolves the role of a user on a bot for command permission checks.
+type BotMemberService interface {
+	Get(ctx context.Context, botID string) (bots.Bot, error)
+	GetMember(ctx context.Context, botID, userID string) (bots.BotMember, error)
+}
+
+// SessionHistoryService clears the history of a session.
+type SessionHistoryService interface {
+	DeleteBySession(ctx context.Context, botID, sessionID string) error
+}
+
+// BotSettingsService reads and updates bot settings.
+type BotSettingsService interface {
+	GetBot(ctx context.Context, botID string) (settings.Settings, error)
+	UpsertBot(ctx context.Context, botID string, req settings.UpsertRequest) (settings.Settings, error)
+}
+
+// ModelLookup finds the models a bot can switch to.
+type ModelLookup interface {
+	GetByModelID(ctx context.Context, modelID string) (models.GetResponse, error)
+	ListByType(ctx context.Context, modelType models.ModelType) ([]models.GetResponse, error)
+}
+
+// MemorySearcher searches bot memories.
+type MemorySearcher interface {
+	Search(ctx context.Context, req memory.SearchRequest) (memory.SearchResponse, error)
+}
+
+// CommandServices are the services behind the built-in commands. A command whose service
+// is not configured replies that it is unavailable.
+type CommandServices struct {
+	Bots     BotMemberService
+	History  SessionHistoryService
+	Settings BotSettingsService
+	Models   ModelLookup
+	Memory   MemorySearcher
+}
+
+const (
+	commandReplyDenied      = "你没有权限使用该命令。"
+	commandReplyFailed      = "命令执行失败，请稍后重试。"
+	commandReplyUnavailable = "该命令当前不可用。"
+	commandMemoryLimit      = 5
+)
+
+// command is a slash command answered by the router without calling the agent.
+type command struct {
+	name        string
+	description string
+	// role is the minimum bots.BotMember role required; empty allows anyone with a contact.
+	role string
+	run  func(ctx context.Context, inv commandInvocation) (string, error)
+}
+
+type commandInvocation struct {
+	cfg      channel.ChannelConfig
+	msg      channel.InboundMessage
+	identity InboundIdentity
+	role     string
+	args     string
+}
+
+// SetCommandServices configures the services used by the built-in commands.
+func (p *ChannelInboundProcessor) SetCommandServices(services CommandServices) {
+	p.commandServices = services
+}
+
+// Commands lists the built-in commands for platform command menus.
+func (p *ChannelInboundProcessor) Commands() []channel.Command {
+	items := make([]channel.Command, 0, len(p.commands))
+	for _, cmd := range p.commands {
+		items = append(items, channel.Command{Name: cmd.name, Description: cmd.description})
+	}
+	return items
+}
+
+func (p *ChannelInboundProcessor) builtinCommands() []command {
+	return []command{
+		{name: "help", description: "查看可用命令", run: p.runHelpCommand},
+		{name: "new", description: "开始新的会话", run: p.runNewCommand},
+		{name: "model", description: "查看或切换对话模型", role: bots.MemberRoleMember, run: p.runModelCommand},
+		{name: "memory", description: "搜索记忆", role: bots.MemberRoleMember, run: p.runMemoryCommand},
+		{name: "whoami", description: "查看当前身份", run: p.runWhoamiCommand},
+		{name: "bind", description: "使用预授权码绑定账号", run: p.runBindCommand},
+		{name: "link", description: "关联其他平台的账号", run: p.runLinkCommand},
+	}
+}
+
+// matchCommand reports whether text invokes a built-in command. Commands addressed to
+// another bot with the /name@bot form are ignored.
+func (p *ChannelInboundProcessor) matchCommand(cfg channel.ChannelConfig, text string) (command, string, bool) {
+	name, addressee, args, ok := parseCommandText(text)
+	if !ok {
+		return command{}, "", false
+	}
+	if addressee != "" && !isBotAddressee(cfg, addressee) {
+		return command{}, "", false
+	}
+	for _, cmd := range p.commands {
+		if cmd.name == name {
+			return cmd, args, true
+		}
+	}
+	return command{}, "", false
+}
+
+// parseCommandText splits "/name@bot args" into its parts. The name is lower-cased.
+func parseCommandText(text string) (string, string, string, bool) {
+	trimmed := strings.TrimSpace(text)
+	if !strings.HasPrefix(trimmed, "/") {
+		return "", "", "", false
+	}
+	head, args := trimmed, ""
+	if idx := strings.IndexFunc(trimmed, unicode.IsSpace); idx >= 0 {
+		head, args = trimmed[:idx], strings.TrimSpace(trimmed[idx:])
+	}
+	name, addressee, _ := strings.Cut(strings.TrimPrefix(head, "/"), "@")
+	name = strings.ToLower(name)
+	if name == "" {
+		return "", "", "", false
+	}
+	return name, addressee, args, true
+}
+
+func isBotAddressee(cfg channel.ChannelConfig, addressee string) bool {
+	ids := botIdentities(cfg)
+	if len(ids) == 0 {
+		return true
+	}
+	for _, id := range ids {
+		if strings.EqualFold(id, addressee) {
+			return true
+		}
+	}
+	return false
+}
+
+// handleCommand runs a command and sends its reply to the conversation.
+func (p *ChannelInboundProcessor) handleCommand(ctx context.Context, cfg channel.ChannelConfig, msg channel.InboundMessage, identity InboundIdentity, cmd command, args string, sender channel.ReplySender) error {
+	target := strings.TrimSpace(msg.ReplyTarget)
+	if target == "" {
+		return fmt.Errorf("reply target missing")
+	}
+	role := p.resolveMemberRole(ctx, identity)
+	reply := commandReplyDenied
+	if memberRoleRank(role) >= memberRoleRank(cmd.role) {
+		text, err := cmd.run(ctx, commandInvocation{cfg: cfg, msg: msg, identity: identity, role: role, args: args})
+		if err != nil {
+			if p.logger != nil {
+				p.logger.Warn("command failed", slog.String("command", cmd.name), slog.String("bot_id", identity.BotID), slog.Any("error", err))
+			}
+			text = commandReplyFailed
+		}
+		reply = text
+	}
+	return sender.Send(ctx, channel.OutboundMessage{
+		Target:  target,
+		Message: channel.Message{Text: reply},
+	})
+}
+
+// resolveMemberRole returns the sender's role on the bot: the owner, a bots.BotMember role,
+// or empty for guests and unbound users.
+func (p *ChannelInboundProcessor) resolveMemberRole(ctx context.Context, identity InboundIdentity) string {
+	service := p.commandServices.Bots
+	if service == nil || strings.TrimSpace(identity.UserID) == "" {
+		return ""
+	}
+	bot, err := service.Get(ctx, identity.BotID)
+	if err != nil {
+		return ""
+	}
+	if bot.OwnerUserID == identity.UserID {
+		return bots.MemberRoleOwner
+	}
+	member, err := service.GetMember(ctx, identity.BotID, identity.UserID)
+	if err != nil {
+		return ""
+	}
+	return member.Role
+}
+
+func memberRoleRank(role string) int {
+	switch role {
+	case bots.MemberRoleOwner:
+		return 3
+	case bots.MemberRoleAdmin:
+		return 2
+	case bots.MemberRoleMember:
+		return 1
+	default:
+		return 0
+	}
+}
+
+func (p *ChannelInboundProcessor) runHelpCommand(ctx context.Context, inv commandInvocation) (string, error) {
+	lines := []string{"可用命令："}
+	for _, cmd := range p.commands {
+		if memberRoleRank(inv.role) < memberRoleRank(cmd.role) {
+			continue
+		}
+		lines = append(lines, fmt.Sprintf("/%s - %s", cmd.name, cmd.description))
+	}
+	return strings.Join(lines, "\n"), nil
+}
+
+func (p *ChannelInboundProcessor) runNewCommand(ctx context.Context, inv commandInvocation) (string, error) {
+	if p.commandServices.History == nil {
+		return commandReplyUnavailable, nil
+	}
+	if err := p.commandServices.History.DeleteBySession(ctx, inv.identity.BotID, chatSessionID(inv.identity)); err != nil {
+		return "", err
+	}
+	return "已开始新的会话，之前的上下文已清除。", nil
+}
+
+func (p *ChannelInboundProcessor) runModelCommand(ctx context.Context, inv commandInvocation) (string, error) {
+	services := p.commandServices
+	if services.Settings == nil || services.Models == nil {
+		return commandReplyUnavailable, nil
+	}
+	if inv.args == "" {
+		current, err := services.Settings.GetBot(ctx, inv.identity.BotID)
+		if err != nil {
+			return "", err
+		}
+		available, err := services.Models.ListByType(ctx, models.ModelTypeChat)
+		if err != nil {
+			return "", err
+		}
+		modelID := current.ChatModelID
+		if modelID == "" {
+			modelID = "未设置"
+		}
+		lines := []string{"当前模型：" + modelID}
+		if len(available) > 0 {
+			ids := make([]string, 0, len(available))
+			for _, item := range available {
+				ids = append(ids, item.ModelID)
+			}
+			lines = append(lines, "可用模型："+strings.Join(ids, ", "))
+		}
+		lines = append(lines, "切换模型：/model <模型 ID>")
+		return strings.Join(lines, "\n"), nil
+	}
+	if memberRoleRank(inv.role) < memberRoleRank(bots.MemberRoleAdmin) {
+		return commandReplyDenied, nil
+	}
+	model, err := services.Models.GetByModelID(ctx, inv.args)
+	if err != nil {
+		return "未找到模型：" + inv.args, nil
+	}
+	if model.Type != models.ModelTypeChat {
+		return inv.args + " 不是对话模型。", nil
+	}
+	if _, err := services.Settings.UpsertBot(ctx, inv.identity.BotID, settings.UpsertRequest{ChatModelID: model.ModelID}); err != nil {
+		return "", err
+	}
+	return "已切换到模型：" + model.ModelID, nil
+}
+
+func (p *ChannelInboundProcessor) runMemoryCommand(ctx context.Context, inv commandInvocation) (string, error) {
+	if p.commandServices.Memory == nil {
+		return commandReplyUnavailable, nil
+	}
+	if inv.args == "" {
+		return "用法：/memory <关键词>", nil
+	}
+	resp, err := p.commandServices.Memory.Search(ctx, memory.SearchRequest{
+		Query:     inv.args,
+		BotID:     inv.identity.BotID,
+		SessionID: chatSessionID(inv.identity),
+		ContactID: inv.identity.ContactID,
+		Limit:     commandMemoryLimit,
+	})
+	if err != nil {
+		return "", err
+	}
+	if len(resp.Results) == 0 {
+		return "没有找到相关记忆。", nil
+	}
+	lines := make([]string, 0, len(resp.Results))
+	for i, item := range resp.Results {
+		lines = append(lines, fmt.Sprintf("%d. %s", i+1, strings.TrimSpace(item.Memory)))
+	}
+	return strings.Join(lines, "\n"), nil
+}
+
+func (p *ChannelInboundProcessor) runWhoamiCommand(ctx context.Context, inv commandInvocation) (string, error) {
+	role := inv.role
+	if role == "" {
+		role = "guest"
+	}
+	lines := []string{
+		"平台：" + inv.msg.Channel.String(),
+		"账号：" + valueOrUnknown(inv.identity.ExternalID),
+		"联系人：" + valueOrUnknown(strings.TrimSpace(inv.identity.Contact.DisplayName)),
+		"用户：" + valueOrUnknown(inv.identity.UserID),
+		"角色：" + role,
+		"会话：" + chatSessionID(inv.identity),
+	}
+	return strings.Join(lines, "\n"), nil
+}
+
+// runBindCommand redeems a preauth key for a sender that identity resolution let through,
+// such as a guest. Unknown senders of bots without guests are bound by the identity resolver.
+func (p *ChannelInboundProcessor) runBindCommand(ctx context.Context, inv commandInvocation) (string, error) {
+	if p.identity == nil || p.identity.preauth == nil {
+		return commandReplyUnavailable, nil
+	}
+	if inv.args == "" {
+		return "用法：/bind <预授权码>", nil
+	}
+	return p.identity.redeemPreauthKey(ctx, inv.msg, inv.identity, inv.args)
+}
+
+func valueOrUnknown(value string) string {
+	if strings.TrimSpace(value) == "" {
+		return "未知"
+	}
+	return value
+}
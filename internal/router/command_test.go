@@ -0,0 +1,252 @@
+package router
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/memohai/memoh/internal/bots"
+	"github.com/memohai/memoh/internal/channel"
+	"github.com/memohai/memoh/internal/contacts"
+	"github.com/memohai/memoh/internal/memory"
+	"github.com/memohai/memoh/internal/models"
+	"github.com/memohai/memoh/internal/policy"
+	"github.com/memohai/memoh/internal/preauth"
+	"github.com/memohai/memoh/internal/settings"
+)
+
+type fakeBotMemberService struct {
+	ownerID string
+	roles   map[string]string
+}
+
+func (f *fakeBotMemberService) Get(ctx context.Context, botID string) (bots.Bot, error) {
+	return bots.Bot{ID: botID, OwnerUserID: f.ownerID}, nil
+}
+
+func (f *fakeBotMemberService) GetMember(ctx context.Context, botID, userID string) (bots.BotMember, error) {
+	role, ok := f.roles[userID]
+	if !ok {
+		return bots.BotMember{}, fmt.Errorf("member not found")
+	}
+	return bots.BotMember{BotID: botID, UserID: userID, Role: role}, nil
+}
+
+type fakeSessionHistoryService struct {
+	deleted []string
+}
+
+func (f *fakeSessionHistoryService) DeleteBySession(ctx context.Context, botID, sessionID string) error {
+	f.deleted = append(f.deleted, sessionID)
+	return nil
+}
+
+type fakeBotSettingsService struct {
+	current settings.Settings
+}
+
+func (f *fakeBotSettingsService) GetBot(ctx context.Context, botID string) (settings.Settings, error) {
+	return f.current, nil
+}
+
+func (f *fakeBotSettingsService) UpsertBot(ctx context.Context, botID string, req settings.UpsertRequest) (settings.Settings, error) {
+	if req.ChatModelID != "" {
+		f.current.ChatModelID = req.ChatModelID
+	}
+	return f.current, nil
+}
+
+type fakeModelLookup struct {
+	items []models.GetResponse
+}
+
+func (f *fakeModelLookup) GetByModelID(ctx context.Context, modelID string) (models.GetResponse, error) {
+	for _, item := range f.items {
+		if item.ModelID == modelID {
+			return item, nil
+		}
+	}
+	return models.GetResponse{}, fmt.Errorf("model not found")
+}
+
+func (f *fakeModelLookup) ListByType(ctx context.Context, modelType models.ModelType) ([]models.GetResponse, error) {
+	var items []models.GetResponse
+	for _, item := range f.items {
+		if item.Type == modelType {
+			items = append(items, item)
+		}
+	}
+	return items, nil
+}
+
+type fakeMemorySearcher struct {
+	gotReq memory.SearchRequest
+}
+
+func (f *fakeMemorySearcher) Search(ctx context.Context, req memory.SearchRequest) (memory.SearchResponse, error) {
+	f.gotReq = req
+	return memory.SearchResponse{Results: []memory.MemoryItem{{ID: "m1", Memory: "喜欢喝咖啡"}}}, nil
+}
+
+type commandTestEnv struct {
+	processor *ChannelInboundProcessor
+	gateway   *fakeChatGateway
+	history   *fakeSessionHistoryService
+	settings  *fakeBotSettingsService
+	memory    *fakeMemorySearcher
+}
+
+func newCommandTestEnv(t *testing.T, userID, role string) commandTestEnv {
+	t.Helper()
+	env := commandTestEnv{
+		gateway:  &fakeChatGateway{},
+		history:  &fakeSessionHistoryService{},
+		settings: &fakeBotSettingsService{current: settings.Settings{ChatModelID: "gpt-4o"}},
+		memory:   &fakeMemorySearcher{},
+	}
+	env.processor = newTestProcessor(t, withSession("telegram:bot-1:100", userID), withGateway(env.gateway), withDecision(policy.Decision{AllowGuest: true}))
+	env.processor.SetCommandServices(CommandServices{
+		Bots:     &fakeBotMemberService{ownerID: "owner-1", roles: map[string]string{userID: role}},
+		History:  env.history,
+		Settings: env.settings,
+		Models: &fakeModelLookup{items: []models.GetResponse{
+			{Model: models.Model{ModelID: "gpt-4o", Type: models.ModelTypeChat}},
+			{Model: models.Model{ModelID: "claude", Type: models.ModelTypeChat}},
+			{Model: models.Model{ModelID: "embed", Type: models.ModelTypeEmbedding}},
+		}},
+		Memory: env.memory,
+	})
+	return env
+}
+
+func (env commandTestEnv) send(t *testing.T, text string) []channel.OutboundMessage {
+	t.Helper()
+	sender := &fakeReplySender{}
+	cfg := channel.ChannelConfig{
+		ID:           "cfg-1",
+		BotID:        "bot-1",
+		ChannelType:  channel.ChannelType("telegram"),
+		SelfIdentity: map[string]any{"username": "memoh_bot"},
+	}
+	msg := channel.InboundMessage{
+		Channel:      channel.ChannelType("telegram"),
+		Message:      channel.Message{Text: text},
+		ReplyTarget:  "100",
+		Sender:       channel.Identity{ExternalID: "100", DisplayName: "Alice"},
+		Conversation: channel.Conversation{ID: "100", Type: "private"},
+	}
+	if err := env.processor.HandleInbound(context.Background(), cfg, msg, sender); err != nil {
+		t.Fatalf("不应报错: %v", err)
+	}
+	return sender.sent
+}
+
+func TestChannelInboundProcessorCommandsSkipGateway(t *testing.T) {
+	env := newCommandTestEnv(t, "user-1", bots.MemberRoleMember)
+
+	sent := env.send(t, "/whoami")
+	if len(sent) != 1 || !strings.Contains(sent[0].Message.PlainText(), "角色：member") {
+		t.Fatalf("应回复身份信息，实际: %+v", sent)
+	}
+	sent = env.send(t, "/new@memoh_bot")
+	if len(sent) != 1 || len(env.history.deleted) != 1 || env.history.deleted[0] != "telegram:bot-1:100" {
+		t.Fatalf("应清除当前会话历史，实际: %+v %+v", sent, env.history.deleted)
+	}
+	sent = env.send(t, "/memory 咖啡")
+	if len(sent) != 1 || !strings.Contains(sent[0].Message.PlainText(), "喜欢喝咖啡") {
+		t.Fatalf("应返回记忆搜索结果，实际: %+v", sent)
+	}
+	if env.memory.gotReq.Query != "咖啡" || env.memory.gotReq.BotID != "bot-1" || env.memory.gotReq.SessionID == "" {
+		t.Fatalf("记忆搜索请求错误: %+v", env.memory.gotReq)
+	}
+	if env.gateway.gotReq.Query != "" {
+		t.Fatal("命令不应触发 Chat 调用")
+	}
+
+	env.send(t, "/new@other_bot")
+	if env.gateway.gotReq.Query != "/new@other_bot" {
+		t.Fatalf("发给其他机器人的命令应按普通消息处理: %q", env.gateway.gotReq.Query)
+	}
+	if len(env.history.deleted) != 1 {
+		t.Fatalf("发给其他机器人的命令不应执行: %+v", env.history.deleted)
+	}
+}
+
+func TestChannelInboundProcessorModelCommandPermissions(t *testing.T) {
+	member := newCommandTestEnv(t, "user-1", bots.MemberRoleMember)
+	sent := member.send(t, "/model")
+	if len(sent) != 1 || !strings.Contains(sent[0].Message.PlainText(), "当前模型：gpt-4o") || strings.Contains(sent[0].Message.PlainText(), "embed") {
+		t.Fatalf("应显示当前模型和可用对话模型，实际: %+v", sent)
+	}
+	sent = member.send(t, "/model claude")
+	if len(sent) != 1 || sent[0].Message.PlainText() != commandReplyDenied || member.settings.current.ChatModelID != "gpt-4o" {
+		t.Fatalf("普通成员不应切换模型，实际: %+v", sent)
+	}
+
+	admin := newCommandTestEnv(t, "user-2", bots.MemberRoleAdmin)
+	sent = admin.send(t, "/model embed")
+	if len(sent) != 1 || admin.settings.current.ChatModelID != "gpt-4o" {
+		t.Fatalf("不应切换到非对话模型，实际: %+v", sent)
+	}
+	sent = admin.send(t, "/model claude")
+	if len(sent) != 1 || admin.settings.current.ChatModelID != "claude" {
+		t.Fatalf("管理员应能切换模型，实际: %+v", sent)
+	}
+
+	guest := newCommandTestEnv(t, "", "")
+	sent = guest.send(t, "/memory 咖啡")
+	if len(sent) != 1 || sent[0].Message.PlainText() != commandReplyDenied {
+		t.Fatalf("访客不应搜索记忆，实际: %+v", sent)
+	}
+}
+
+func TestChannelInboundProcessorBindCommand(t *testing.T) {
+	newBindEnv := func(userID string) (commandTestEnv, *fakeIdentityContactService, *fakePreauthService) {
+		contactsService := &fakeIdentityContactService{existing: contacts.Contact{ID: "contact-9", BotID: "bot-1", UserID: "user-9"}}
+		preauthService := &fakePreauthService{
+			key: preauth.Key{
+				ID:        "key-1",
+				BotID:     "bot-1",
+				Token:     "LINK1234",
+				ContactID: "contact-9",
+				ExpiresAt: time.Now().UTC().Add(10 * time.Minute),
+			},
+		}
+		env := commandTestEnv{gateway: &fakeChatGateway{}}
+		env.processor = newTestProcessor(t,
+			withSession("telegram:bot-1:100", userID),
+			withGateway(env.gateway),
+			withContacts(contactsService),
+			withDecision(policy.Decision{AllowGuest: true}),
+			withPreauth(preauthService),
+		)
+		return env, contactsService, preauthService
+	}
+
+	guest, contactsService, preauthService := newBindEnv("")
+	sent := guest.send(t, "/bind LINK1234")
+	if len(sent) != 1 || sent[0].Message.PlainText() != linkReplySuccess {
+		t.Fatalf("访客应能使用预授权码绑定账号，实际: %+v", sent)
+	}
+	if contactsService.upsertContactID != "contact-9" || !preauthService.markUsed {
+		t.Fatalf("应绑定到预授权码的联系人并标记已使用: %s", contactsService.upsertContactID)
+	}
+	if guest.gateway.gotReq.Query != "" {
+		t.Fatal("/bind 不应触发 Chat 调用")
+	}
+	sent = guest.send(t, "/bind LINK1234")
+	if len(sent) != 1 || sent[0].Message.PlainText() != linkReplyUsed {
+		t.Fatalf("预授权码不应被重复兑换，实际: %+v", sent)
+	}
+
+	bound, contactsService, preauthService := newBindEnv("user-1")
+	sent = bound.send(t, "/bind LINK1234")
+	if len(sent) != 1 || sent[0].Message.PlainText() != "当前账号已绑定，无需重复操作。" {
+		t.Fatalf("已绑定账号应提示无需重复操作，实际: %+v", sent)
+	}
+	if preauthService.markUsed || contactsService.upsertContactID == "contact-9" {
+		t.Fatal("已绑定账号不应消耗预授权码")
+	}
+}
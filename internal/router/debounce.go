@@ -0,0 +1,157 @@
+package router
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/memohai/memoh/internal/channel"
+	"github.com/memohai/memoh/internal/chat"
+)
+
+// maxDebounceWait bounds how long a steady stream of messages can hold back a turn.
+const maxDebounceWait = 30 * time.Second
+
+// turnInput is the part of an agent query contributed by one inbound message.
+type turnInput struct {
+	text        string
+	attachments []chat.Attachment
+}
+
+// turnPolicy is the per-bot behavior for consecutive messages of a session.
+type turnPolicy struct {
+	debounce  time.Duration
+	interrupt bool
+}
+
+func (p turnPolicy) enabled() bool {
+	return p.debounce > 0 || p.interrupt
+}
+
+// answerSession answers a message together with the messages of its session queued behind
+// it. The dispatcher processes a session one message at a time and exposes the later ones
+// through the session inbox, so a burst is coalesced into one turn: with debounce the turn
+// starts once the session has been quiet for the window, and with interrupt a new message
+// cancels the running turn, which is started again with the new input appended.
+func (p *ChannelInboundProcessor) answerSession(ctx context.Context, cfg channel.ChannelConfig, msg channel.InboundMessage, identity InboundIdentity, input turnInput, sender channel.ReplySender) error {
+	inbox, ok := channel.SessionInboxFromContext(ctx)
+	if !ok {
+		return p.answer(ctx, msg, identity, input, sender)
+	}
+	policy := p.resolveTurnPolicy(ctx, identity.BotID)
+	if !policy.enabled() {
+		return p.answer(ctx, msg, identity, input, sender)
+	}
+	if policy.debounce > 0 {
+		waitQuiet(ctx, inbox, policy.debounce)
+	}
+	msg, input, _ = p.takeFollowUps(ctx, cfg, inbox, msg, input)
+	if !policy.interrupt {
+		return p.answer(ctx, msg, identity, input, sender)
+	}
+	for {
+		turnCtx, cancel := context.WithCancel(ctx)
+		done := make(chan error, 1)
+		go func(msg channel.InboundMessage, input turnInput) {
+			done <- p.answer(turnCtx, msg, identity, input, sender)
+		}(msg, input)
+		interrupted := false
+		for !interrupted {
+			select {
+			case err := <-done:
+				cancel()
+				return err
+			case <-inbox.Arrivals():
+				var taken int
+				msg, input, taken = p.takeFollowUps(ctx, cfg, inbox, msg, input)
+				interrupted = taken > 0
+			}
+		}
+		cancel()
+		<-done
+		if p.logger != nil {
+			p.logger.Info("agent turn interrupted by new message", slog.String("bot_id", identity.BotID), slog.String("session_id", identity.SessionID))
+		}
+	}
+}
+
+// waitQuiet returns once no message of the session has arrived for the debounce window.
+func waitQuiet(ctx context.Context, inbox channel.SessionInbox, window time.Duration) {
+	timer := time.NewTimer(window)
+	defer timer.Stop()
+	limit := time.NewTimer(maxDebounceWait)
+	defer limit.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-timer.C:
+			return
+		case <-limit.C:
+			return
+		case <-inbox.Arrivals():
+			timer.Reset(window)
+		}
+	}
+}
+
+// takeFollowUps drains the queued messages that can join the turn of msg and merges their
+// input. Commands, group messages the bot does not answer and messages over the rate limit
+// end the batch and are handled on their own. It returns the last message taken, which the reply goes to, and how many
+// messages were taken.
+func (p *ChannelInboundProcessor) takeFollowUps(ctx context.Context, cfg channel.ChannelConfig, inbox channel.SessionInbox, msg channel.InboundMessage, input turnInput) (channel.InboundMessage, turnInput, int) {
+	next, err := inbox.Drain(ctx, func(item channel.InboundMessage) bool {
+		if _, _, isCommand := p.matchCommand(cfg, item.Message.PlainText()); isCommand {
+			return false
+		}
+		return shouldRespond(cfg, item, inboundQueryText(item)) && admitRateLimit(ctx)
+	})
+	if err != nil {
+		if p.logger != nil {
+			p.logger.Warn("drain session inbox failed", slog.String("bot_id", cfg.BotID), slog.Any("error", err))
+		}
+		return msg, input, 0
+	}
+	if len(next) == 0 {
+		return msg, input, 0
+	}
+	parts := []turnInput{input}
+	for _, item := range next {
+		parts = append(parts, turnInput{text: inboundQueryText(item), attachments: p.downloadAttachments(ctx, cfg, item)})
+		msg = item
+	}
+	return msg, mergeTurnInputs(parts), len(next)
+}
+
+func mergeTurnInputs(parts []turnInput) turnInput {
+	if len(parts) == 1 {
+		return parts[0]
+	}
+	texts := make([]string, 0, len(parts))
+	var merged turnInput
+	for _, part := range parts {
+		if text := strings.TrimSpace(part.text); text != "" {
+			texts = append(texts, text)
+		}
+		merged.attachments = append(merged.attachments, part.attachments...)
+	}
+	merged.text = strings.Join(texts, "\n")
+	return merged
+}
+
+// resolveTurnPolicy reads the debounce and interrupt settings of the bot. Failures fall back
+// to answering every message at once.
+func (p *ChannelInboundProcessor) resolveTurnPolicy(ctx context.Context, botID string) turnPolicy {
+	if p.identity == nil || p.identity.policy == nil {
+		return turnPolicy{}
+	}
+	decision, err := p.identity.policy.Resolve(ctx, botID)
+	if err != nil {
+		return turnPolicy{}
+	}
+	return turnPolicy{
+		debounce:  time.Duration(decision.MessageDebounceMs) * time.Millisecond,
+		interrupt: decision.InterruptOnNewMessage,
+	}
+}
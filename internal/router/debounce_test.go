@@ -0,0 +1,215 @@
+package router
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/memohai/memoh/internal/channel"
+	"github.com/memohai/memoh/internal/chat"
+	"github.com/memohai/memoh/internal/policy"
+)
+
+type turnChatGateway struct {
+	mu         sync.Mutex
+	queries    []string
+	blockFirst bool
+}
+
+func (g *turnChatGateway) Chat(ctx context.Context, req chat.ChatRequest) (chat.ChatResponse, error) {
+	g.mu.Lock()
+	g.queries = append(g.queries, req.Query)
+	first := len(g.queries) == 1
+	g.mu.Unlock()
+	if first && g.blockFirst {
+		<-ctx.Done()
+		return chat.ChatResponse{}, ctx.Err()
+	}
+	return chat.ChatResponse{
+		Messages: []chat.ModelMessage{{Role: "assistant", Content: chat.NewTextContent("好的")}},
+	}, nil
+}
+
+func (g *turnChatGateway) calls() []string {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return append([]string(nil), g.queries...)
+}
+
+type syncReplySender struct {
+	mu   sync.Mutex
+	sent []channel.OutboundMessage
+}
+
+func (s *syncReplySender) Send(ctx context.Context, msg channel.OutboundMessage) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sent = append(s.sent, msg)
+	return nil
+}
+
+// fakeSessionInbox 模拟调度器为同一会话暂存的后续消息
+type fakeSessionInbox struct {
+	mu       sync.Mutex
+	queue    []channel.InboundMessage
+	arrivals chan struct{}
+}
+
+func newFakeSessionInbox(texts ...string) *fakeSessionInbox {
+	inbox := &fakeSessionInbox{arrivals: make(chan struct{}, 1)}
+	for _, text := range texts {
+		inbox.push(text)
+	}
+	return inbox
+}
+
+func (b *fakeSessionInbox) push(text string) {
+	_, msg := turnTestMessage(text)
+	b.mu.Lock()
+	b.queue = append(b.queue, msg)
+	b.mu.Unlock()
+	select {
+	case b.arrivals <- struct{}{}:
+	default:
+	}
+}
+
+func (b *fakeSessionInbox) Arrivals() <-chan struct{} {
+	return b.arrivals
+}
+
+func (b *fakeSessionInbox) Drain(ctx context.Context, accept func(channel.InboundMessage) bool) ([]channel.InboundMessage, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	n := 0
+	for n < len(b.queue) && accept(b.queue[n]) {
+		n++
+	}
+	taken := append([]channel.InboundMessage(nil), b.queue[:n]...)
+	b.queue = b.queue[n:]
+	return taken, nil
+}
+
+func (b *fakeSessionInbox) remaining() []string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	texts := make([]string, 0, len(b.queue))
+	for _, msg := range b.queue {
+		texts = append(texts, msg.Message.Text)
+	}
+	return texts
+}
+
+func turnTestMessage(text string) (channel.ChannelConfig, channel.InboundMessage) {
+	cfg := channel.ChannelConfig{ID: "cfg-1", BotID: "bot-1", ChannelType: channel.ChannelType("telegram")}
+	msg := channel.InboundMessage{
+		Channel:      channel.ChannelType("telegram"),
+		Message:      channel.Message{Text: text},
+		ReplyTarget:  "100",
+		Sender:       channel.Identity{ExternalID: "100"},
+		Conversation: channel.Conversation{ID: "100", Type: "private"},
+	}
+	return cfg, msg
+}
+
+func TestChannelInboundProcessorDebounceCoalescesBurst(t *testing.T) {
+	gateway := &turnChatGateway{}
+	processor := newTestProcessor(t, withSession("telegram:bot-1:100", "user-1"), withGateway(gateway), withDecision(policy.Decision{MessageDebounceMs: 80, AllowGuest: true}))
+	sender := &syncReplySender{}
+	inbox := newFakeSessionInbox("帮我查下天气")
+	go func() {
+		time.Sleep(30 * time.Millisecond)
+		inbox.push("北京")
+	}()
+
+	cfg, msg := turnTestMessage("在吗")
+	if err := processor.HandleInbound(channel.WithSessionInbox(context.Background(), inbox), cfg, msg, sender); err != nil {
+		t.Fatalf("不应报错: %v", err)
+	}
+
+	calls := gateway.calls()
+	if len(calls) != 1 || calls[0] != "在吗\n帮我查下天气\n北京" {
+		t.Fatalf("连续消息应合并为一次请求，实际: %q", calls)
+	}
+	if len(sender.sent) != 1 {
+		t.Fatalf("应只回复一次，实际: %+v", sender.sent)
+	}
+	if rest := inbox.remaining(); len(rest) != 0 {
+		t.Fatalf("合并的消息应从会话队列取出，剩余: %q", rest)
+	}
+}
+
+func TestChannelInboundProcessorDebounceStopsAtCommand(t *testing.T) {
+	gateway := &turnChatGateway{}
+	processor := newTestProcessor(t, withSession("telegram:bot-1:100", "user-1"), withGateway(gateway), withDecision(policy.Decision{MessageDebounceMs: 10, AllowGuest: true}))
+	sender := &syncReplySender{}
+	inbox := newFakeSessionInbox("补充一句", "/whoami", "之后的")
+
+	cfg, msg := turnTestMessage("问题")
+	if err := processor.HandleInbound(channel.WithSessionInbox(context.Background(), inbox), cfg, msg, sender); err != nil {
+		t.Fatalf("不应报错: %v", err)
+	}
+	if calls := gateway.calls(); len(calls) != 1 || calls[0] != "问题\n补充一句" {
+		t.Fatalf("应只合并命令之前的消息，实际: %q", calls)
+	}
+	if rest := inbox.remaining(); len(rest) != 2 || rest[0] != "/whoami" {
+		t.Fatalf("命令及之后的消息应留在队列中单独处理，剩余: %q", rest)
+	}
+}
+
+func TestChannelInboundProcessorInterruptMergesInFlightTurn(t *testing.T) {
+	gateway := &turnChatGateway{blockFirst: true}
+	processor := newTestProcessor(t, withSession("telegram:bot-1:100", "user-1"), withGateway(gateway), withDecision(policy.Decision{InterruptOnNewMessage: true, AllowGuest: true}))
+	sender := &syncReplySender{}
+	inbox := newFakeSessionInbox()
+
+	done := make(chan error, 1)
+	cfg, msg := turnTestMessage("帮我写首诗")
+	go func() {
+		done <- processor.HandleInbound(channel.WithSessionInbox(context.Background(), inbox), cfg, msg, sender)
+	}()
+	deadline := time.Now().Add(time.Second)
+	for len(gateway.calls()) == 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("第一条消息未发起请求")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	inbox.push("关于秋天的")
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("不应报错: %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("被打断的请求应以合并后的输入重新完成")
+	}
+
+	calls := gateway.calls()
+	if len(calls) != 2 || calls[1] != "帮我写首诗\n关于秋天的" {
+		t.Fatalf("新消息应与进行中的消息合并，实际: %q", calls)
+	}
+	if len(sender.sent) != 1 {
+		t.Fatalf("应只回复合并后的请求，实际: %+v", sender.sent)
+	}
+}
+
+func TestChannelInboundProcessorNoDebounceByDefault(t *testing.T) {
+	gateway := &turnChatGateway{}
+	processor := newTestProcessor(t, withSession("telegram:bot-1:100", "user-1"), withGateway(gateway), withDecision(policy.Decision{AllowGuest: true}))
+	sender := &syncReplySender{}
+	inbox := newFakeSessionInbox("二")
+
+	cfg, msg := turnTestMessage("一")
+	if err := processor.HandleInbound(channel.WithSessionInbox(context.Background(), inbox), cfg, msg, sender); err != nil {
+		t.Fatalf("不应报错: %v", err)
+	}
+	if calls := gateway.calls(); len(calls) != 1 || calls[0] != "一" {
+		t.Fatalf("未开启防抖时每条消息应单独请求，实际: %q", calls)
+	}
+	if rest := inbox.remaining(); len(rest) != 1 {
+		t.Fatalf("未开启防抖时不应取出后续消息，剩余: %q", rest)
+	}
+}
@@ -0,0 +1,152 @@
+package router
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/memohai/memoh/internal/channel"
+	"github.com/memohai/memoh/internal/chat"
+)
+
+// AmbientRecorder is implemented by chat gateways that can record group messages the bot
+// observed without answering.
+type AmbientRecorder interface {
+	RecordAmbient(ctx context.Context, msg chat.AmbientMessage) error
+}
+
+// ambientSessionID is the conversation-wide session that holds ambient group messages.
+// Group sessions are per sender, so the ambient log lives next to them.
+func ambientSessionID(botID string, msg channel.InboundMessage) string {
+	if !channel.IsGroupConversation(msg.Conversation.Type) || strings.TrimSpace(msg.Conversation.ID) == "" {
+		return ""
+	}
+	return strings.Join([]string{msg.Channel.String(), botID, strings.TrimSpace(msg.Conversation.ID), "ambient"}, ":")
+}
+
+// shouldRespond applies the group policy of the config to a message. Direct messages and
+// button presses are always answered.
+func shouldRespond(cfg channel.ChannelConfig, msg channel.InboundMessage, text string) bool {
+	if !channel.IsGroupConversation(msg.Conversation.Type) || msg.Action != nil {
+		return true
+	}
+	policy := channel.ResolveGroupPolicy(cfg.Routing, msg.Conversation.ID)
+	if policy.Has(channel.GroupTriggerAlways) {
+		return true
+	}
+	if policy.Has(channel.GroupTriggerMention) && isBotMentioned(cfg, msg) {
+		return true
+	}
+	if policy.Has(channel.GroupTriggerReply) && readBoolMetadata(msg.Metadata, channel.MetadataKeyReplyToBot) {
+		return true
+	}
+	if policy.Has(channel.GroupTriggerKeyword) && policy.MatchesKeyword(text) {
+		return true
+	}
+	return false
+}
+
+// isBotMentioned trusts the adapter's mentioned flag, then looks for mention parts or
+// @handles that match the bot's identity on the platform.
+func isBotMentioned(cfg channel.ChannelConfig, msg channel.InboundMessage) bool {
+	if readBoolMetadata(msg.Metadata, channel.MetadataKeyMentioned) {
+		return true
+	}
+	selfIDs := botIdentities(cfg)
+	if len(selfIDs) == 0 {
+		return false
+	}
+	for _, part := range msg.Message.Parts {
+		if part.Type != channel.MessagePartMention {
+			continue
+		}
+		for _, id := range selfIDs {
+			if strings.EqualFold(strings.TrimSpace(part.UserID), id) || strings.EqualFold(strings.TrimPrefix(strings.TrimSpace(part.Text), "@"), id) {
+				return true
+			}
+		}
+	}
+	text := strings.ToLower(msg.Message.PlainText())
+	for _, id := range selfIDs {
+		if containsHandle(text, "@"+strings.ToLower(id)) {
+			return true
+		}
+	}
+	return false
+}
+
+// containsHandle reports whether text holds handle as a whole word, so @memoh does not
+// match @memoh_bot or mail@memoh.
+func containsHandle(text, handle string) bool {
+	for offset := 0; offset < len(text); {
+		i := strings.Index(text[offset:], handle)
+		if i < 0 {
+			return false
+		}
+		start := offset + i
+		end := start + len(handle)
+		before, _ := utf8.DecodeLastRuneInString(text[:start])
+		after, _ := utf8.DecodeRuneInString(text[end:])
+		if (start == 0 || !isHandleRune(before)) && (end == len(text) || !isHandleRune(after)) {
+			return true
+		}
+		offset = start + 1
+	}
+	return false
+}
+
+func isHandleRune(r rune) bool {
+	return r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+func botIdentities(cfg channel.ChannelConfig) []string {
+	var ids []string
+	if value := strings.TrimPrefix(strings.TrimSpace(cfg.ExternalIdentity), "@"); value != "" {
+		ids = append(ids, value)
+	}
+	for _, key := range []string{"user_id", "open_id", "username", "name"} {
+		if value := strings.TrimPrefix(strings.TrimSpace(channel.ReadString(cfg.SelfIdentity, key)), "@"); value != "" {
+			ids = append(ids, value)
+		}
+	}
+	return ids
+}
+
+func readBoolMetadata(metadata map[string]any, key string) bool {
+	value, ok := metadata[key]
+	if !ok {
+		return false
+	}
+	switch v := value.(type) {
+	case bool:
+		return v
+	case string:
+		return strings.EqualFold(strings.TrimSpace(v), "true")
+	default:
+		return false
+	}
+}
+
+// recordAmbient keeps a group message the bot does not answer as context for later turns.
+// Failures are logged only; the message has been handled either way.
+func (p *ChannelInboundProcessor) recordAmbient(ctx context.Context, botID string, msg channel.InboundMessage, text string) {
+	recorder, ok := p.chat.(AmbientRecorder)
+	if !ok {
+		return
+	}
+	sessionID := ambientSessionID(botID, msg)
+	if sessionID == "" {
+		return
+	}
+	err := recorder.RecordAmbient(ctx, chat.AmbientMessage{
+		BotID:     botID,
+		SessionID: sessionID,
+		Sender:    extractDisplayName(msg),
+		Text:      text,
+	})
+	if err != nil && p.logger != nil {
+		p.logger.Warn("record ambient message failed", slog.String("channel", msg.Channel.String()), slog.String("conversation_id", msg.Conversation.ID), slog.Any("error", err))
+	}
+}
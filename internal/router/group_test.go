@@ -0,0 +1,214 @@
+package router
+
+import (
+	"context"
+	"testing"
+
+	"github.com/memohai/memoh/internal/channel"
+	"github.com/memohai/memoh/internal/chat"
+)
+
+type fakeAmbientChatGateway struct {
+	fakeChatGateway
+	ambient []chat.AmbientMessage
+}
+
+func (f *fakeAmbientChatGateway) RecordAmbient(ctx context.Context, msg chat.AmbientMessage) error {
+	f.ambient = append(f.ambient, msg)
+	return nil
+}
+
+func newAmbientTestGateway() *fakeAmbientChatGateway {
+	return &fakeAmbientChatGateway{
+		fakeChatGateway: fakeChatGateway{
+			resp: chat.ChatResponse{
+				Messages: []chat.ModelMessage{
+					{Role: "assistant", Content: chat.NewTextContent("收到")},
+				},
+			},
+		},
+	}
+}
+
+func groupTestMessage(text string) channel.InboundMessage {
+	return channel.InboundMessage{
+		Channel:     channel.ChannelType("telegram"),
+		Message:     channel.Message{Text: text},
+		ReplyTarget: "-1001",
+		Sender:      channel.Identity{ExternalID: "42", DisplayName: "Alice"},
+		Conversation: channel.Conversation{
+			ID:   "-1001",
+			Type: "supergroup",
+		},
+	}
+}
+
+func TestChannelInboundProcessorGroupRecordsAmbientWhenNotAddressed(t *testing.T) {
+	gateway := newAmbientTestGateway()
+	processor := newTestProcessor(t, withSession("telegram:bot-1:-1001:42", "user-123"), withGateway(gateway))
+	sender := &fakeReplySender{}
+	cfg := channel.ChannelConfig{
+		ID:          "cfg-1",
+		BotID:       "bot-1",
+		ChannelType: channel.ChannelType("telegram"),
+		Routing:     map[string]any{"group_policy": map[string]any{"triggers": []any{"mention"}}},
+	}
+
+	if err := processor.HandleInbound(context.Background(), cfg, groupTestMessage("今天吃什么"), sender); err != nil {
+		t.Fatalf("不应报错: %v", err)
+	}
+	if gateway.gotReq.Query != "" {
+		t.Error("未提及机器人的群消息不应触发 Chat 调用")
+	}
+	if len(sender.sent) != 0 {
+		t.Fatalf("未提及机器人的群消息不应回复: %+v", sender.sent)
+	}
+	if len(gateway.ambient) != 1 {
+		t.Fatalf("应记录一条环境消息，实际: %+v", gateway.ambient)
+	}
+	got := gateway.ambient[0]
+	if got.SessionID != "telegram:bot-1:-1001:ambient" || got.Text != "今天吃什么" || got.Sender != "Alice" {
+		t.Fatalf("环境消息内容错误: %+v", got)
+	}
+}
+
+func TestChannelInboundProcessorGroupStoppedSenderNotRecorded(t *testing.T) {
+	gateway := &fakeAmbientChatGateway{}
+	processor := newTestProcessor(t, withGateway(gateway))
+	sender := &fakeReplySender{}
+	cfg := channel.ChannelConfig{
+		ID:           "cfg-1",
+		BotID:        "bot-1",
+		ChannelType:  channel.ChannelType("telegram"),
+		SelfIdentity: map[string]any{"username": "memoh_bot"},
+		Routing:      map[string]any{"group_policy": map[string]any{"triggers": []any{"mention"}}},
+	}
+
+	// 身份校验拦下的消息既不回复也不记录为环境消息
+	if err := processor.HandleInbound(context.Background(), cfg, groupTestMessage("今天吃什么"), sender); err != nil {
+		t.Fatalf("不应报错: %v", err)
+	}
+	if len(gateway.ambient) != 0 || len(sender.sent) != 0 {
+		t.Fatalf("未绑定用户的群消息不应记录或回复: %+v %+v", gateway.ambient, sender.sent)
+	}
+
+	// 提及机器人时仍发送绑定提示
+	if err := processor.HandleInbound(context.Background(), cfg, groupTestMessage("@memoh_bot 你好"), sender); err != nil {
+		t.Fatalf("不应报错: %v", err)
+	}
+	if len(sender.sent) != 1 || gateway.gotReq.Query != "" {
+		t.Fatalf("提及机器人的未绑定用户应收到绑定提示，实际: %+v", sender.sent)
+	}
+}
+
+func TestChannelInboundProcessorGroupRespondsWhenMentioned(t *testing.T) {
+	gateway := newAmbientTestGateway()
+	processor := newTestProcessor(t, withSession("telegram:bot-1:-1001:42", "user-123"), withGateway(gateway))
+	sender := &fakeReplySender{}
+	cfg := channel.ChannelConfig{
+		ID:           "cfg-1",
+		BotID:        "bot-1",
+		ChannelType:  channel.ChannelType("telegram"),
+		SelfIdentity: map[string]any{"username": "memoh_bot"},
+		Routing:      map[string]any{"group_policy": map[string]any{"triggers": []any{"mention", "reply"}}},
+	}
+
+	if err := processor.HandleInbound(context.Background(), cfg, groupTestMessage("@memoh_bot 今天吃什么"), sender); err != nil {
+		t.Fatalf("不应报错: %v", err)
+	}
+	if gateway.gotReq.Query == "" {
+		t.Fatal("提及机器人时应触发 Chat 调用")
+	}
+	if gateway.gotReq.AmbientSessionID != "telegram:bot-1:-1001:ambient" {
+		t.Errorf("AmbientSessionID 传递错误: %s", gateway.gotReq.AmbientSessionID)
+	}
+	if len(gateway.ambient) != 0 {
+		t.Fatalf("被提及的消息不应记录为环境消息: %+v", gateway.ambient)
+	}
+
+	gateway.gotReq = chat.ChatRequest{}
+	reply := groupTestMessage("好的")
+	reply.Metadata = map[string]any{channel.MetadataKeyReplyToBot: true}
+	if err := processor.HandleInbound(context.Background(), cfg, reply, sender); err != nil {
+		t.Fatalf("不应报错: %v", err)
+	}
+	if gateway.gotReq.Query == "" {
+		t.Fatal("回复机器人时应触发 Chat 调用")
+	}
+}
+
+func TestChannelInboundProcessorGroupConversationOverride(t *testing.T) {
+	gateway := newAmbientTestGateway()
+	processor := newTestProcessor(t, withSession("telegram:bot-1:-1001:42", "user-123"), withGateway(gateway))
+	sender := &fakeReplySender{}
+	cfg := channel.ChannelConfig{
+		ID:          "cfg-1",
+		BotID:       "bot-1",
+		ChannelType: channel.ChannelType("telegram"),
+		Routing: map[string]any{
+			"group_policy": map[string]any{"triggers": []any{"mention"}},
+			"conversations": map[string]any{
+				"-1001": map[string]any{"group_policy": map[string]any{"triggers": []any{"keyword"}, "prefixes": []any{"/ask"}}},
+			},
+		},
+	}
+
+	if err := processor.HandleInbound(context.Background(), cfg, groupTestMessage("随便聊聊"), sender); err != nil {
+		t.Fatalf("不应报错: %v", err)
+	}
+	if gateway.gotReq.Query != "" || len(gateway.ambient) != 1 {
+		t.Fatalf("未命中前缀的消息应只记录为环境消息: %+v", gateway.ambient)
+	}
+	if err := processor.HandleInbound(context.Background(), cfg, groupTestMessage("/ask 今天天气"), sender); err != nil {
+		t.Fatalf("不应报错: %v", err)
+	}
+	if gateway.gotReq.Query == "" {
+		t.Fatal("命中前缀时应触发 Chat 调用")
+	}
+}
+
+func TestChannelInboundProcessorActionBypassesGroupPolicy(t *testing.T) {
+	gateway := newAmbientTestGateway()
+	processor := newTestProcessor(t, withSession("telegram:bot-1:-1001:42", "user-123"), withGateway(gateway))
+	sender := &fakeReplySender{}
+	cfg := channel.ChannelConfig{
+		ID:          "cfg-1",
+		BotID:       "bot-1",
+		ChannelType: channel.ChannelType("telegram"),
+		Routing:     map[string]any{"group_policy": map[string]any{"triggers": []any{"mention"}}},
+	}
+
+	msg := groupTestMessage("")
+	msg.Action = &channel.InboundAction{Value: "approve", Label: "同意", MessageID: "77"}
+	if err := processor.HandleInbound(context.Background(), cfg, msg, sender); err != nil {
+		t.Fatalf("不应报错: %v", err)
+	}
+	if gateway.gotReq.Query != "[action:approve] 同意" {
+		t.Fatalf("按钮点击应作为用户消息传给 Chat，实际: %q", gateway.gotReq.Query)
+	}
+	if len(gateway.ambient) != 0 {
+		t.Fatalf("按钮点击不应记录为环境消息: %+v", gateway.ambient)
+	}
+	if len(sender.sent) == 0 {
+		t.Fatal("按钮点击后应回复")
+	}
+}
+
+func TestIsBotMentionedRequiresWholeHandle(t *testing.T) {
+	cfg := channel.ChannelConfig{SelfIdentity: map[string]any{"username": "memoh"}}
+	cases := map[string]bool{
+		"@memoh 你好":        true,
+		"你好 @Memoh":        true,
+		"(@memoh) 在吗":      true,
+		"@memoh_bot 你好":    false,
+		"@memoh2 你好":       false,
+		"mail@memoh.com":   false,
+		"@memohbot @memoh": true,
+	}
+	for text, want := range cases {
+		msg := groupTestMessage(text)
+		if got := isBotMentioned(cfg, msg); got != want {
+			t.Errorf("isBotMentioned(%q) = %v, want %v", text, got, want)
+		}
+	}
+}
@@ -22,6 +22,7 @@ type IdentityDecision struct {
 type InboundIdentity struct {
 	BotID           string
 	SessionID       string
+	ChatSessionID   string
 	ChannelConfigID string
 	ExternalID      string
 	UserID          string
@@ -76,6 +77,7 @@ type PolicyService interface {
 type PreauthService interface {
 	Get(ctx context.Context, token string) (preauth.Key, error)
 	MarkUsed(ctx context.Context, id string) (preauth.Key, error)
+	IssueContactLink(ctx context.Context, botID, contactID string, ttl time.Duration) (preauth.Key, error)
 }
 
 func NewIdentityResolver(log *slog.Logger, registry *channel.Registry, store IdentityStore, contacts ContactService, policyService PolicyService, preauthService PreauthService, unboundReply, preauthReply string) *IdentityResolver {
@@ -222,11 +224,15 @@ func (r *IdentityResolver) Resolve(ctx context.Context, cfg channel.ChannelConfi
 	state.Identity.UserID = userID
 	state.Identity.ContactID = contactID
 	state.Identity.Contact = contact
+	state.Identity.ChatSessionID = r.resolveChatSessionID(ctx, botID, contactID, sessionID, msg)
 	return state, nil
 }
 
 func (r *IdentityResolver) tryHandlePreauthKey(ctx context.Context, msg channel.InboundMessage, externalID string) (bool, IdentityDecision, error) {
 	tokenText := strings.TrimSpace(msg.Message.PlainText())
+	if name, _, args, ok := parseCommandText(tokenText); ok && (name == "bind" || name == "link") {
+		tokenText = args
+	}
 	if tokenText == "" || r.preauth == nil {
 		return false, IdentityDecision{}, nil
 	}
@@ -243,29 +249,79 @@ func (r *IdentityResolver) tryHandlePreauthKey(ctx context.Context, msg channel.
 			Reply: channel.Message{Text: text},
 		}
 	}
-	if !key.UsedAt.IsZero() {
-		return true, reply("预授权码已使用。"), nil
-	}
-	if !key.ExpiresAt.IsZero() && time.Now().UTC().After(key.ExpiresAt) {
-		return true, reply("预授权码已过期，请重新获取。"), nil
-	}
-	if key.BotID != msg.BotID {
-		return true, reply("预授权码不匹配。"), nil
+	if problem := preauthKeyProblem(key, msg.BotID); problem != "" {
+		return true, reply(problem), nil
 	}
 	if externalID == "" {
 		return true, reply("无法识别当前账号，授权失败。"), nil
 	}
-	displayName := extractDisplayName(msg)
-	contact, err := r.contacts.CreateGuest(ctx, msg.BotID, displayName)
-	if err != nil {
+	identity := InboundIdentity{BotID: msg.BotID, SessionID: msg.SessionID(), ExternalID: externalID}
+	if err := r.bindChannel(ctx, msg, identity, key); err != nil {
+		if errors.Is(err, preauth.ErrKeyUsed) {
+			return true, reply(linkReplyUsed), nil
+		}
 		return true, reply("授权失败，请稍后重试。"), nil
 	}
-	if _, err := r.contacts.UpsertChannel(ctx, msg.BotID, contact.ID, msg.Channel.String(), externalID, nil); err != nil {
-		return true, reply("授权失败，请稍后重试。"), nil
+	return true, reply(r.preauthKeyReply(key)), nil
+}
+
+// redeemPreauthKey binds a sender that already passed identity resolution, such as a guest,
+// with a preauth key sent through /bind.
+func (r *IdentityResolver) redeemPreauthKey(ctx context.Context, msg channel.InboundMessage, identity InboundIdentity, token string) (string, error) {
+	key, err := r.preauth.Get(ctx, token)
+	if err != nil {
+		if errors.Is(err, preauth.ErrKeyNotFound) {
+			return "预授权码无效。", nil
+		}
+		return "", err
+	}
+	if problem := preauthKeyProblem(key, identity.BotID); problem != "" {
+		return problem, nil
+	}
+	if identity.UserID != "" || (key.ContactID != "" && key.ContactID == identity.ContactID) {
+		return "当前账号已绑定，无需重复操作。", nil
+	}
+	if identity.ExternalID == "" {
+		return "无法识别当前账号，授权失败。", nil
+	}
+	if err := r.bindChannel(ctx, msg, identity, key); err != nil {
+		if errors.Is(err, preauth.ErrKeyUsed) {
+			return linkReplyUsed, nil
+		}
+		return "", err
+	}
+	return r.preauthKeyReply(key), nil
+}
+
+// bindChannel marks a preauth key used and binds the sender's channel identity and session.
+// A key issued for a contact links the sender to that contact; any other key keeps the
+// sender's current contact, creating a guest contact when there is none.
+func (r *IdentityResolver) bindChannel(ctx context.Context, msg channel.InboundMessage, identity InboundIdentity, key preauth.Key) error {
+	if key.ContactID != "" {
+		return r.linkChannel(ctx, msg, identity, key)
+	}
+	if _, err := r.preauth.MarkUsed(ctx, key.ID); err != nil {
+		return err
+	}
+	contactID := identity.ContactID
+	if contactID == "" {
+		contact, err := r.contacts.CreateGuest(ctx, identity.BotID, extractDisplayName(msg))
+		if err != nil {
+			return err
+		}
+		contactID = contact.ID
+	}
+	if _, err := r.contacts.UpsertChannel(ctx, identity.BotID, contactID, msg.Channel.String(), identity.ExternalID, nil); err != nil {
+		return err
+	}
+	return r.store.UpsertChannelSession(ctx, identity.SessionID, identity.BotID, identity.ChannelConfigID, identity.UserID, contactID, msg.Channel.String(), strings.TrimSpace(msg.ReplyTarget), extractThreadID(msg), buildSessionMetadata(msg))
+}
+
+func (r *IdentityResolver) preauthKeyReply(key preauth.Key) string {
+	if key.ContactID != "" {
+		return linkReplySuccess
 	}
-	_ = r.store.UpsertChannelSession(ctx, msg.SessionID(), msg.BotID, "", "", contact.ID, msg.Channel.String(), strings.TrimSpace(msg.ReplyTarget), extractThreadID(msg), buildSessionMetadata(msg))
-	_, _ = r.preauth.MarkUsed(ctx, key.ID)
-	return true, reply(r.preauthReply), nil
+	return r.preauthReply
 }
 
 func extractExternalIdentity(msg channel.InboundMessage) string {
@@ -66,9 +66,14 @@ func (f *fakeIdentityConfigStore) UpsertChannelSession(ctx context.Context, sess
 type fakeIdentityContactService struct {
 	createGuestCalled bool
 	upsertCalled      bool
+	upsertContactID   string
+	existing          contacts.Contact
 }
 
 func (f *fakeIdentityContactService) GetByID(ctx context.Context, contactID string) (contacts.Contact, error) {
+	if f.existing.ID != "" && f.existing.ID == contactID {
+		return f.existing, nil
+	}
 	return contacts.Contact{}, fmt.Errorf("not found")
 }
 
@@ -91,13 +96,15 @@ func (f *fakeIdentityContactService) CreateGuest(ctx context.Context, botID, dis
 
 func (f *fakeIdentityContactService) UpsertChannel(ctx context.Context, botID, contactID, platform, externalID string, metadata map[string]any) (contacts.ContactChannel, error) {
 	f.upsertCalled = true
+	f.upsertContactID = contactID
 	return contacts.ContactChannel{ID: "channel-1", ContactID: contactID}, nil
 }
 
 type fakePreauthService struct {
-	key      preauth.Key
-	err      error
-	markUsed bool
+	key       preauth.Key
+	err       error
+	markUsed  bool
+	issuedFor string
 }
 
 func (f *fakePreauthService) Get(ctx context.Context, token string) (preauth.Key, error) {
@@ -111,10 +118,18 @@ func (f *fakePreauthService) Get(ctx context.Context, token string) (preauth.Key
 }
 
 func (f *fakePreauthService) MarkUsed(ctx context.Context, id string) (preauth.Key, error) {
+	if f.markUsed {
+		return preauth.Key{}, preauth.ErrKeyUsed
+	}
 	f.markUsed = true
 	return f.key, nil
 }
 
+func (f *fakePreauthService) IssueContactLink(ctx context.Context, botID, contactID string, ttl time.Duration) (preauth.Key, error) {
+	f.issuedFor = contactID
+	return preauth.Key{ID: "key-link", BotID: botID, Token: "LINK1234", ContactID: contactID}, nil
+}
+
 func TestIdentityResolverAllowGuestCreatesContact(t *testing.T) {
 	store := &fakeIdentityConfigStore{}
 	contactsService := &fakeIdentityContactService{}
@@ -208,3 +223,138 @@ func TestIdentityResolverPreauthKeyExpired(t *testing.T) {
 		t.Fatalf("过期预授权码不应被使用")
 	}
 }
+
+func TestIdentityResolverPreauthKeyBindCommand(t *testing.T) {
+	store := &fakeIdentityConfigStore{}
+	contactsService := &fakeIdentityContactService{}
+	policyService := &fakePolicyServiceIdentity{}
+	preauthService := &fakePreauthService{
+		key: preauth.Key{
+			ID:        "key-1",
+			BotID:     "bot-1",
+			Token:     "PREAUTH123",
+			ExpiresAt: time.Now().UTC().Add(1 * time.Hour),
+		},
+	}
+	resolver := NewIdentityResolver(slog.Default(), nil, store, contactsService, policyService, preauthService, "禁止访问", "授权成功")
+
+	msg := channel.InboundMessage{
+		BotID:       "bot-1",
+		Channel:     channel.ChannelType("telegram"),
+		Message:     channel.Message{Text: "/bind@memoh_bot PREAUTH123"},
+		ReplyTarget: "target-id",
+		Sender:      channel.Identity{ExternalID: "user-1"},
+	}
+	state, err := resolver.Resolve(context.Background(), channel.ChannelConfig{BotID: "bot-1"}, msg)
+	if err != nil {
+		t.Fatalf("不应报错: %v", err)
+	}
+	if state.Decision == nil || state.Decision.Reply.PlainText() != "授权成功" {
+		t.Fatalf("/bind 命令应使用预授权码完成授权: %+v", state.Decision)
+	}
+	if !preauthService.markUsed {
+		t.Fatalf("应标记预授权码已使用")
+	}
+}
+
+func TestIdentityResolverLinkCodeJoinsExistingContact(t *testing.T) {
+	store := &fakeIdentityConfigStore{}
+	contactsService := &fakeIdentityContactService{existing: contacts.Contact{ID: "contact-9", BotID: "bot-1"}}
+	policyService := &fakePolicyServiceIdentity{}
+	preauthService := &fakePreauthService{
+		key: preauth.Key{
+			ID:        "key-1",
+			BotID:     "bot-1",
+			Token:     "LINK1234",
+			ContactID: "contact-9",
+			ExpiresAt: time.Now().UTC().Add(10 * time.Minute),
+		},
+	}
+	resolver := NewIdentityResolver(slog.Default(), nil, store, contactsService, policyService, preauthService, "禁止访问", "授权成功")
+
+	msg := channel.InboundMessage{
+		BotID:       "bot-1",
+		Channel:     channel.ChannelType("feishu"),
+		Message:     channel.Message{Text: "/link LINK1234"},
+		ReplyTarget: "ou_1",
+		Sender:      channel.Identity{ExternalID: "ou_1"},
+	}
+	state, err := resolver.Resolve(context.Background(), channel.ChannelConfig{BotID: "bot-1"}, msg)
+	if err != nil {
+		t.Fatalf("不应报错: %v", err)
+	}
+	if state.Decision == nil || state.Decision.Reply.PlainText() != linkReplySuccess {
+		t.Fatalf("关联码应完成账号关联: %+v", state.Decision)
+	}
+	if contactsService.createGuestCalled {
+		t.Fatalf("关联码不应创建新联系人")
+	}
+	if contactsService.upsertContactID != "contact-9" || !preauthService.markUsed {
+		t.Fatalf("应关联到签发关联码的联系人: %s", contactsService.upsertContactID)
+	}
+}
+
+func TestIdentityResolverLinkCodeRedeemedConcurrently(t *testing.T) {
+	contactsService := &fakeIdentityContactService{existing: contacts.Contact{ID: "contact-9", BotID: "bot-1"}}
+	// Get 仍返回未使用的关联码，但另一条消息已抢先兑换。
+	preauthService := &fakePreauthService{
+		key: preauth.Key{
+			ID:        "key-1",
+			BotID:     "bot-1",
+			Token:     "LINK1234",
+			ContactID: "contact-9",
+			ExpiresAt: time.Now().UTC().Add(10 * time.Minute),
+		},
+		markUsed: true,
+	}
+	resolver := NewIdentityResolver(slog.Default(), nil, &fakeIdentityConfigStore{}, contactsService, &fakePolicyServiceIdentity{}, preauthService, "禁止访问", "授权成功")
+
+	msg := channel.InboundMessage{
+		BotID:       "bot-1",
+		Channel:     channel.ChannelType("feishu"),
+		Message:     channel.Message{Text: "/link LINK1234"},
+		ReplyTarget: "ou_2",
+		Sender:      channel.Identity{ExternalID: "ou_2"},
+	}
+	state, err := resolver.Resolve(context.Background(), channel.ChannelConfig{BotID: "bot-1"}, msg)
+	if err != nil {
+		t.Fatalf("不应报错: %v", err)
+	}
+	if state.Decision == nil || state.Decision.Reply.PlainText() != linkReplyUsed {
+		t.Fatalf("已兑换的关联码应提示已使用: %+v", state.Decision)
+	}
+	if contactsService.upsertCalled {
+		t.Fatalf("关联码兑换失败时不应关联渠道")
+	}
+}
+
+func TestIdentityResolverUnifiedSession(t *testing.T) {
+	store := &fakeIdentityConfigStore{}
+	policyService := &fakePolicyServiceIdentity{decision: policy.Decision{AllowGuest: true, UnifiedSession: true}}
+	resolver := NewIdentityResolver(slog.Default(), nil, store, &fakeIdentityContactService{}, policyService, nil, "禁止访问", "授权成功")
+
+	msg := channel.InboundMessage{
+		BotID:        "bot-1",
+		Channel:      channel.ChannelType("telegram"),
+		Message:      channel.Message{Text: "hello"},
+		ReplyTarget:  "100",
+		Sender:       channel.Identity{ExternalID: "100"},
+		Conversation: channel.Conversation{ID: "100", Type: "private"},
+	}
+	state, err := resolver.Resolve(context.Background(), channel.ChannelConfig{BotID: "bot-1"}, msg)
+	if err != nil {
+		t.Fatalf("不应报错: %v", err)
+	}
+	if state.Identity.ChatSessionID != "contact:bot-1:contact-guest" || state.Identity.SessionID != "telegram:bot-1:100" {
+		t.Fatalf("私聊应使用联系人会话: %+v", state.Identity)
+	}
+
+	msg.Conversation = channel.Conversation{ID: "-1001", Type: "supergroup"}
+	state, err = resolver.Resolve(context.Background(), channel.ChannelConfig{BotID: "bot-1"}, msg)
+	if err != nil {
+		t.Fatalf("不应报错: %v", err)
+	}
+	if state.Identity.ChatSessionID != state.Identity.SessionID {
+		t.Fatalf("群聊应保留渠道会话: %+v", state.Identity)
+	}
+}
@@ -0,0 +1,166 @@
+package router
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/memohai/memoh/internal/channel"
+	"github.com/memohai/memoh/internal/preauth"
+)
+
+// linkCodeTTL is how long a contact link code issued by /link stays valid.
+const linkCodeTTL = 10 * time.Minute
+
+const (
+	linkReplySuccess = "账号关联成功。"
+	linkReplyInvalid = "关联码无效。"
+	linkReplyUsed    = "预授权码已使用。"
+)
+
+// contactSessionID is the conversation shared by all channels of a contact when the bot
+// uses unified sessions. Direct messages store their history and memory under it.
+func contactSessionID(botID, contactID string) string {
+	return strings.Join([]string{"contact", botID, contactID}, ":")
+}
+
+// chatSessionID returns the session that holds the chat history and memory of the sender.
+// It differs from SessionID when the bot shares one conversation per contact across channels.
+func chatSessionID(identity InboundIdentity) string {
+	if value := strings.TrimSpace(identity.ChatSessionID); value != "" {
+		return value
+	}
+	return identity.SessionID
+}
+
+// resolveChatSessionID moves direct conversations of known contacts to the contact session
+// when unified sessions are enabled for the bot. Group conversations keep their own session.
+func (r *IdentityResolver) resolveChatSessionID(ctx context.Context, botID, contactID, sessionID string, msg channel.InboundMessage) string {
+	if contactID == "" || channel.IsGroupConversation(msg.Conversation.Type) {
+		return sessionID
+	}
+	decision, err := r.policy.Resolve(ctx, botID)
+	if err != nil || !decision.UnifiedSession {
+		return sessionID
+	}
+	return contactSessionID(botID, contactID)
+}
+
+// SessionKey returns the chat session of a message without creating contacts or sessions,
+// so the channel manager serializes a unified contact session across channels. Messages of
+// unknown senders keep their channel session.
+func (r *IdentityResolver) SessionKey(ctx context.Context, cfg channel.ChannelConfig, msg channel.InboundMessage) string {
+	if r.store == nil || r.contacts == nil || r.policy == nil {
+		return ""
+	}
+	botID := strings.TrimSpace(msg.BotID)
+	if botID == "" {
+		botID = cfg.BotID
+	}
+	keyed := msg
+	keyed.BotID = botID
+	sessionID := keyed.SessionID()
+	if channel.IsGroupConversation(msg.Conversation.Type) {
+		return sessionID
+	}
+	session, _ := r.store.GetChannelSession(ctx, sessionID)
+	contactID := strings.TrimSpace(session.ContactID)
+	if contactID == "" && strings.TrimSpace(session.UserID) != "" {
+		if contact, err := r.contacts.GetByUserID(ctx, botID, strings.TrimSpace(session.UserID)); err == nil {
+			contactID = contact.ID
+		}
+	}
+	if contactID == "" {
+		if externalID := extractExternalIdentity(msg); externalID != "" {
+			if binding, err := r.contacts.GetByChannelIdentity(ctx, botID, msg.Channel.String(), externalID); err == nil {
+				contactID = binding.ContactID
+			}
+		}
+	}
+	return r.resolveChatSessionID(ctx, botID, contactID, sessionID, keyed)
+}
+
+// preauthKeyProblem returns the reply for a key that cannot be redeemed, or empty when the
+// key is valid for the bot.
+func preauthKeyProblem(key preauth.Key, botID string) string {
+	if !key.UsedAt.IsZero() {
+		return linkReplyUsed
+	}
+	if !key.ExpiresAt.IsZero() && time.Now().UTC().After(key.ExpiresAt) {
+		return "预授权码已过期，请重新获取。"
+	}
+	if key.BotID != botID {
+		return "预授权码不匹配。"
+	}
+	return ""
+}
+
+// redeemLinkCode attaches the sender's channel identity to the contact that issued the code.
+func (r *IdentityResolver) redeemLinkCode(ctx context.Context, msg channel.InboundMessage, identity InboundIdentity, code string) (string, error) {
+	key, err := r.preauth.Get(ctx, code)
+	if err != nil {
+		if errors.Is(err, preauth.ErrKeyNotFound) {
+			return linkReplyInvalid, nil
+		}
+		return "", err
+	}
+	if key.ContactID == "" {
+		return linkReplyInvalid, nil
+	}
+	if problem := preauthKeyProblem(key, identity.BotID); problem != "" {
+		return problem, nil
+	}
+	if key.ContactID == identity.ContactID {
+		return "当前账号已关联到该联系人。", nil
+	}
+	if identity.ExternalID == "" {
+		return "无法识别当前账号，关联失败。", nil
+	}
+	if err := r.linkChannel(ctx, msg, identity, key); err != nil {
+		if errors.Is(err, preauth.ErrKeyUsed) {
+			return linkReplyUsed, nil
+		}
+		return "", err
+	}
+	return linkReplySuccess, nil
+}
+
+// linkChannel marks a link code used and then points the sender's channel identity and
+// session at its contact. Marking first lets only one of two concurrent redemptions link.
+func (r *IdentityResolver) linkChannel(ctx context.Context, msg channel.InboundMessage, identity InboundIdentity, key preauth.Key) error {
+	contact, err := r.contacts.GetByID(ctx, key.ContactID)
+	if err != nil {
+		return err
+	}
+	if _, err := r.preauth.MarkUsed(ctx, key.ID); err != nil {
+		return err
+	}
+	if _, err := r.contacts.UpsertChannel(ctx, identity.BotID, contact.ID, msg.Channel.String(), identity.ExternalID, nil); err != nil {
+		return err
+	}
+	return r.store.UpsertChannelSession(ctx, identity.SessionID, identity.BotID, identity.ChannelConfigID, contact.UserID, contact.ID, msg.Channel.String(), strings.TrimSpace(msg.ReplyTarget), extractThreadID(msg), buildSessionMetadata(msg))
+}
+
+// runLinkCommand issues a link code for the sender's contact, or redeems one issued on
+// another channel.
+func (p *ChannelInboundProcessor) runLinkCommand(ctx context.Context, inv commandInvocation) (string, error) {
+	if p.identity == nil || p.identity.preauth == nil {
+		return commandReplyUnavailable, nil
+	}
+	if inv.args != "" {
+		return p.identity.redeemLinkCode(ctx, inv.msg, inv.identity, inv.args)
+	}
+	if channel.IsGroupConversation(inv.msg.Conversation.Type) {
+		return "请在私聊中使用该命令。", nil
+	}
+	if inv.identity.ContactID == "" {
+		return "当前账号尚未成为联系人，无法关联。", nil
+	}
+	key, err := p.identity.preauth.IssueContactLink(ctx, inv.identity.BotID, inv.identity.ContactID, linkCodeTTL)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("关联码：%s\n请在 %d 分钟内通过其他平台向我发送 /link %s 完成关联。", key.Token, int(linkCodeTTL.Minutes()), key.Token), nil
+}
@@ -0,0 +1,102 @@
+package router
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/memohai/memoh/internal/channel"
+	"github.com/memohai/memoh/internal/chat"
+	"github.com/memohai/memoh/internal/policy"
+)
+
+func TestChannelInboundProcessorLinkCommandIssuesCode(t *testing.T) {
+	preauthService := &fakePreauthService{}
+	processor := NewChannelInboundProcessor(slog.Default(), nil, &fakeConfigStore{}, &fakeChatGateway{}, &fakeContactService{}, &fakePolicyService{decision: policy.Decision{AllowGuest: true}}, preauthService, "", 0)
+	sender := &fakeReplySender{}
+	cfg := channel.ChannelConfig{ID: "cfg-1", BotID: "bot-1", ChannelType: channel.ChannelType("telegram")}
+	msg := channel.InboundMessage{
+		Channel:      channel.ChannelType("telegram"),
+		Message:      channel.Message{Text: "/link"},
+		ReplyTarget:  "100",
+		Sender:       channel.Identity{ExternalID: "100"},
+		Conversation: channel.Conversation{ID: "100", Type: "private"},
+	}
+	if err := processor.HandleInbound(context.Background(), cfg, msg, sender); err != nil {
+		t.Fatalf("不应报错: %v", err)
+	}
+	if preauthService.issuedFor != "contact-guest" {
+		t.Fatalf("应为当前联系人签发关联码: %q", preauthService.issuedFor)
+	}
+	if len(sender.sent) != 1 || !strings.Contains(sender.sent[0].Message.PlainText(), "/link LINK1234") {
+		t.Fatalf("应回复关联码: %+v", sender.sent)
+	}
+
+	preauthService.issuedFor = ""
+	msg.Conversation = channel.Conversation{ID: "-1001", Type: "supergroup"}
+	if err := processor.HandleInbound(context.Background(), cfg, msg, sender); err != nil {
+		t.Fatalf("不应报错: %v", err)
+	}
+	if preauthService.issuedFor != "" {
+		t.Fatal("群聊中不应签发关联码")
+	}
+}
+
+func TestChannelInboundProcessorUnifiedSessionChat(t *testing.T) {
+	gateway := &fakeChatGateway{
+		resp: chat.ChatResponse{
+			Messages: []chat.ModelMessage{
+				{Role: "assistant", Content: chat.NewTextContent("你好")},
+			},
+		},
+	}
+	policyService := &fakePolicyService{decision: policy.Decision{AllowGuest: true, UnifiedSession: true}}
+	processor := NewChannelInboundProcessor(slog.Default(), nil, &fakeConfigStore{}, gateway, &fakeContactService{}, policyService, nil, "", 0)
+	sender := &fakeReplySender{}
+	cfg := channel.ChannelConfig{ID: "cfg-1", BotID: "bot-1", ChannelType: channel.ChannelType("feishu")}
+	msg := channel.InboundMessage{
+		Channel:      channel.ChannelType("feishu"),
+		Message:      channel.Message{Text: "你好"},
+		ReplyTarget:  "ou_1",
+		Sender:       channel.Identity{ExternalID: "ou_1"},
+		Conversation: channel.Conversation{ID: "oc_1", Type: "p2p"},
+	}
+	if err := processor.HandleInbound(context.Background(), cfg, msg, sender); err != nil {
+		t.Fatalf("不应报错: %v", err)
+	}
+	if gateway.gotReq.SessionID != "contact:bot-1:contact-guest" {
+		t.Fatalf("应使用联系人会话保存历史和记忆: %s", gateway.gotReq.SessionID)
+	}
+	if len(sender.sent) != 1 || sender.sent[0].Target != "ou_1" {
+		t.Fatalf("回复应发送到当前渠道: %+v", sender.sent)
+	}
+}
+
+func TestChannelInboundProcessorSessionKeys(t *testing.T) {
+	store := &fakeConfigStore{session: channel.ChannelSession{SessionID: "feishu:bot-1:oc_1", ContactID: "contact-1"}}
+	policyService := &fakePolicyService{decision: policy.Decision{UnifiedSession: true}}
+	processor := NewChannelInboundProcessor(slog.Default(), nil, store, &fakeChatGateway{}, &fakeContactService{}, policyService, nil, "", 0)
+	keys := processor.SessionKeys()
+	cfg := channel.ChannelConfig{ID: "cfg-1", BotID: "bot-1", ChannelType: channel.ChannelType("feishu")}
+	msg := channel.InboundMessage{
+		Channel:      channel.ChannelType("feishu"),
+		Message:      channel.Message{Text: "你好"},
+		Sender:       channel.Identity{ExternalID: "ou_1"},
+		Conversation: channel.Conversation{ID: "oc_1", Type: "p2p"},
+	}
+
+	// 统一会话下，私聊按联系人会话串行
+	if key := keys(context.Background(), cfg, msg); key != "contact:bot-1:contact-1" {
+		t.Fatalf("私聊应使用联系人会话: %s", key)
+	}
+	group := msg
+	group.Conversation = channel.Conversation{ID: "oc_2", Type: "group"}
+	if key := keys(context.Background(), cfg, group); key != "feishu:bot-1:oc_2:ou_1" {
+		t.Fatalf("群聊应保留渠道会话: %s", key)
+	}
+	policyService.decision.UnifiedSession = false
+	if key := keys(context.Background(), cfg, msg); strings.HasPrefix(key, "contact:") {
+		t.Fatalf("未开启统一会话时不应使用联系人会话: %s", key)
+	}
+}
@@ -0,0 +1,340 @@
+package router
+
+import (
+	"context"
+	"log/slog"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/memohai/memoh/internal/channel"
+	"github.com/memohai/memoh/internal/chat"
+	"github.com/memohai/memoh/internal/config"
+)
+
+const (
+	rateLimitScopeSender = "sender"
+	rateLimitScopeBot    = "bot"
+
+	rateLimitReasonMessages   = "messages_per_minute"
+	rateLimitReasonConcurrent = "max_concurrent"
+	rateLimitReasonTokens     = "daily_tokens"
+)
+
+const (
+	rateLimitReplyBusy   = "消息太频繁，请稍后再试。"
+	rateLimitReplyTokens = "今日额度已用完，请明天再试。"
+)
+
+// RateLimitCounter is the current usage of one rate limit key.
+type RateLimitCounter struct {
+	BotID              string `json:"bot_id"`
+	Scope              string `json:"scope"`
+	Key                string `json:"key"`
+	MessagesLastMinute int    `json:"messages_last_minute"`
+	InFlight           int    `json:"in_flight"`
+	TokensToday        int64  `json:"tokens_today"`
+	Rejected           int64  `json:"rejected"`
+}
+
+// RateLimiter throttles inbound messages of each sender and each bot by messages per minute,
+// concurrent in-flight turns and a daily token budget. Counters are kept in memory and
+// reset when the server restarts.
+type RateLimiter struct {
+	cfg    config.RateLimitConfig
+	logger *slog.Logger
+	now    func() time.Time
+
+	mu        sync.Mutex
+	counters  map[string]*rateCounter
+	lastSweep time.Time
+}
+
+type rateCounter struct {
+	botID    string
+	scope    string
+	key      string
+	recent   []time.Time
+	inFlight int
+	day      string
+	tokens   int64
+	rejected int64
+	noticeAt time.Time
+}
+
+// rateLease holds the concurrency slots of an admitted message and receives its token usage.
+type rateLease struct {
+	limiter  *RateLimiter
+	counters []*rateCounter
+	policies []config.RateLimitPolicy
+	once     sync.Once
+}
+
+type rateLeaseContextKey struct{}
+
+func NewRateLimiter(log *slog.Logger, cfg config.RateLimitConfig) *RateLimiter {
+	if log == nil {
+		log = slog.Default()
+	}
+	return &RateLimiter{
+		cfg:      cfg,
+		logger:   log.With(slog.String("component", "channel_rate_limit")),
+		now:      time.Now,
+		counters: map[string]*rateCounter{},
+	}
+}
+
+func (l *RateLimiter) enabled() bool {
+	return rateLimitPolicyEnabled(l.cfg.Sender) || rateLimitPolicyEnabled(l.cfg.Bot)
+}
+
+func rateLimitPolicyEnabled(policy config.RateLimitPolicy) bool {
+	return policy.MessagesPerMinute > 0 || policy.MaxConcurrent > 0 || policy.DailyTokens > 0
+}
+
+// Middleware admits or rejects inbound messages. It must run after the identity middleware so
+// senders are keyed by contact. Rejected messages stop with the reject reply, which is sent at
+// most once a minute per sender.
+func (l *RateLimiter) Middleware() channel.Middleware {
+	return func(next channel.InboundHandler) channel.InboundHandler {
+		return func(ctx context.Context, cfg channel.ChannelConfig, msg channel.InboundMessage) error {
+			if !l.enabled() {
+				return next(ctx, cfg, msg)
+			}
+			state, ok := IdentityStateFromContext(ctx)
+			if ok && state.Decision != nil && state.Decision.Stop {
+				return next(ctx, cfg, msg)
+			}
+			if !ok {
+				state.Identity = fallbackRateLimitIdentity(cfg, msg)
+			}
+			lease, reason, notify := l.acquire(state.Identity.BotID, rateLimitSenderKey(state.Identity))
+			if reason != "" {
+				if l.logger != nil {
+					l.logger.Warn("inbound rate limited", slog.String("bot_id", state.Identity.BotID), slog.String("key", rateLimitSenderKey(state.Identity)), slog.String("reason", reason))
+				}
+				decision := &IdentityDecision{Stop: true}
+				if notify {
+					decision.Reply = channel.Message{Text: l.rejectReply(reason)}
+				}
+				state.Decision = decision
+				return next(WithIdentityState(ctx, state), cfg, msg)
+			}
+			defer lease.release()
+			return next(context.WithValue(ctx, rateLeaseContextKey{}, lease), cfg, msg)
+		}
+	}
+}
+
+// Counters returns the counters of a bot, or of all bots when botID is empty.
+func (l *RateLimiter) Counters(botID string) []RateLimitCounter {
+	botID = strings.TrimSpace(botID)
+	now := l.now()
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	items := make([]RateLimitCounter, 0, len(l.counters))
+	for _, c := range l.counters {
+		if botID != "" && c.botID != botID {
+			continue
+		}
+		c.prune(now)
+		items = append(items, RateLimitCounter{
+			BotID:              c.botID,
+			Scope:              c.scope,
+			Key:                c.key,
+			MessagesLastMinute: len(c.recent),
+			InFlight:           c.inFlight,
+			TokensToday:        c.tokens,
+			Rejected:           c.rejected,
+		})
+	}
+	sort.Slice(items, func(i, j int) bool {
+		if items[i].BotID != items[j].BotID {
+			return items[i].BotID < items[j].BotID
+		}
+		if items[i].Scope != items[j].Scope {
+			return items[i].Scope < items[j].Scope
+		}
+		return items[i].Key < items[j].Key
+	})
+	return items
+}
+
+// acquire admits a message of the sender and returns its lease, or the reason it is rejected
+// and whether the sender should be told.
+func (l *RateLimiter) acquire(botID, senderKey string) (*rateLease, string, bool) {
+	now := l.now()
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.sweep(now)
+	sender := l.counter(botID, rateLimitScopeSender, senderKey, now)
+	bot := l.counter(botID, rateLimitScopeBot, botID, now)
+	for _, check := range []struct {
+		counter *rateCounter
+		policy  config.RateLimitPolicy
+	}{{sender, l.cfg.Sender}, {bot, l.cfg.Bot}} {
+		reason := check.counter.exceeded(check.policy)
+		if reason == "" {
+			continue
+		}
+		check.counter.rejected++
+		notify := now.Sub(sender.noticeAt) >= time.Minute
+		if notify {
+			sender.noticeAt = now
+		}
+		return nil, reason, notify
+	}
+	counters := []*rateCounter{sender, bot}
+	for _, c := range counters {
+		c.recent = append(c.recent, now)
+		c.inFlight++
+	}
+	return &rateLease{limiter: l, counters: counters, policies: []config.RateLimitPolicy{l.cfg.Sender, l.cfg.Bot}}, "", false
+}
+
+func (l *RateLimiter) counter(botID, scope, key string, now time.Time) *rateCounter {
+	id := scope + "|" + botID + "|" + key
+	c, ok := l.counters[id]
+	if !ok {
+		c = &rateCounter{botID: botID, scope: scope, key: key}
+		l.counters[id] = c
+	}
+	c.prune(now)
+	return c
+}
+
+// sweep drops idle counters at most once a minute so one-off senders do not accumulate.
+func (l *RateLimiter) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < time.Minute {
+		return
+	}
+	l.lastSweep = now
+	for id, c := range l.counters {
+		c.prune(now)
+		if len(c.recent) == 0 && c.inFlight == 0 && c.tokens == 0 {
+			delete(l.counters, id)
+		}
+	}
+}
+
+func (l *RateLimiter) rejectReply(reason string) string {
+	if value := strings.TrimSpace(l.cfg.RejectMessage); value != "" {
+		return value
+	}
+	if reason == rateLimitReasonTokens {
+		return rateLimitReplyTokens
+	}
+	return rateLimitReplyBusy
+}
+
+// prune forgets messages older than a minute and resets the token count on a new UTC day.
+func (c *rateCounter) prune(now time.Time) {
+	cutoff := now.Add(-time.Minute)
+	idx := 0
+	for idx < len(c.recent) && !c.recent[idx].After(cutoff) {
+		idx++
+	}
+	c.recent = c.recent[idx:]
+	if day := now.UTC().Format(time.DateOnly); day != c.day {
+		c.day = day
+		c.tokens = 0
+	}
+}
+
+// exceeded returns the limit of the policy another message would exceed, or empty.
+func (c *rateCounter) exceeded(policy config.RateLimitPolicy) string {
+	switch {
+	case policy.DailyTokens > 0 && c.tokens >= policy.DailyTokens:
+		return rateLimitReasonTokens
+	case policy.MessagesPerMinute > 0 && len(c.recent) >= policy.MessagesPerMinute:
+		return rateLimitReasonMessages
+	case policy.MaxConcurrent > 0 && c.inFlight >= policy.MaxConcurrent:
+		return rateLimitReasonConcurrent
+	default:
+		return ""
+	}
+}
+
+func (lease *rateLease) release() {
+	lease.once.Do(func() {
+		lease.limiter.mu.Lock()
+		defer lease.limiter.mu.Unlock()
+		for _, c := range lease.counters {
+			if c.inFlight > 0 {
+				c.inFlight--
+			}
+		}
+	})
+}
+
+// admit counts a message merged into the admitted turn, reporting false when it would exceed
+// a limit. The turn already holds the concurrency slots, so those are not checked.
+func (lease *rateLease) admit() bool {
+	now := lease.limiter.now()
+	lease.limiter.mu.Lock()
+	defer lease.limiter.mu.Unlock()
+	for i, c := range lease.counters {
+		c.prune(now)
+		policy := lease.policies[i]
+		policy.MaxConcurrent = 0
+		if c.exceeded(policy) != "" {
+			return false
+		}
+	}
+	for _, c := range lease.counters {
+		c.recent = append(c.recent, now)
+	}
+	return true
+}
+
+func (lease *rateLease) charge(tokens int64) {
+	if tokens <= 0 {
+		return
+	}
+	now := lease.limiter.now()
+	lease.limiter.mu.Lock()
+	defer lease.limiter.mu.Unlock()
+	for _, c := range lease.counters {
+		c.prune(now)
+		c.tokens += tokens
+	}
+}
+
+// chargeRateLimit adds the token usage of a chat round to the daily budgets of the message
+// being handled. It does nothing when the message was not admitted by a RateLimiter.
+func chargeRateLimit(ctx context.Context, usage *chat.Usage) {
+	lease, ok := ctx.Value(rateLeaseContextKey{}).(*rateLease)
+	if !ok || lease == nil {
+		return
+	}
+	lease.charge(usage.Tokens())
+}
+
+// admitRateLimit counts a queued message merged into the turn of the message being handled
+// against its limits. A message that would exceed them is left to be handled, and rejected,
+// on its own.
+func admitRateLimit(ctx context.Context) bool {
+	lease, ok := ctx.Value(rateLeaseContextKey{}).(*rateLease)
+	if !ok || lease == nil {
+		return true
+	}
+	return lease.admit()
+}
+
+// rateLimitSenderKey keys a sender by contact so limits hold across channels, falling back to
+// the channel session for senders without a contact.
+func rateLimitSenderKey(identity InboundIdentity) string {
+	if value := strings.TrimSpace(identity.ContactID); value != "" {
+		return "contact:" + value
+	}
+	return "session:" + identity.SessionID
+}
+
+func fallbackRateLimitIdentity(cfg channel.ChannelConfig, msg channel.InboundMessage) InboundIdentity {
+	keyed := msg
+	if strings.TrimSpace(keyed.BotID) == "" {
+		keyed.BotID = cfg.BotID
+	}
+	return InboundIdentity{BotID: keyed.BotID, SessionID: keyed.SessionID()}
+}
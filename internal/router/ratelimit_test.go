@@ -0,0 +1,164 @@
+package router
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/memohai/memoh/internal/channel"
+	"github.com/memohai/memoh/internal/chat"
+	"github.com/memohai/memoh/internal/config"
+)
+
+type rateLimitTestEnv struct {
+	limiter *RateLimiter
+	gateway *fakeChatGateway
+	now     time.Time
+	handle  channel.InboundHandler
+	sender  *fakeReplySender
+}
+
+func newRateLimitTestEnv(t *testing.T, cfg config.RateLimitConfig) *rateLimitTestEnv {
+	t.Helper()
+	env := &rateLimitTestEnv{
+		gateway: &fakeChatGateway{
+			resp: chat.ChatResponse{
+				Messages: []chat.ModelMessage{{Role: "assistant", Content: chat.NewTextContent("AI回复内容")}},
+				Usage:    &chat.Usage{InputTokens: 60, OutputTokens: 40},
+			},
+		},
+		now:    time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC),
+		sender: &fakeReplySender{},
+	}
+	processor := newTestProcessor(t, withSession("feishu:bot-1:chat-1", "user-123"), withGateway(env.gateway))
+	env.limiter = NewRateLimiter(slog.Default(), cfg)
+	env.limiter.now = func() time.Time { return env.now }
+	final := func(ctx context.Context, cfg channel.ChannelConfig, msg channel.InboundMessage) error {
+		return processor.HandleInbound(ctx, cfg, msg, env.sender)
+	}
+	env.handle = processor.IdentityMiddleware()(env.limiter.Middleware()(final))
+	return env
+}
+
+func (env *rateLimitTestEnv) send(t *testing.T) string {
+	t.Helper()
+	env.sender.sent = nil
+	cfg := channel.ChannelConfig{ID: "cfg-1", BotID: "bot-1", ChannelType: channel.ChannelType("feishu")}
+	msg := channel.InboundMessage{
+		Channel:      channel.ChannelType("feishu"),
+		Message:      channel.Message{Text: "你好"},
+		ReplyTarget:  "target-id",
+		Conversation: channel.Conversation{ID: "chat-1", Type: "p2p"},
+	}
+	if err := env.handle(context.Background(), cfg, msg); err != nil {
+		t.Fatalf("不应报错: %v", err)
+	}
+	if len(env.sender.sent) == 0 {
+		return ""
+	}
+	return env.sender.sent[0].Message.PlainText()
+}
+
+func TestRateLimiterMessagesPerMinute(t *testing.T) {
+	env := newRateLimitTestEnv(t, config.RateLimitConfig{
+		Sender: config.RateLimitPolicy{MessagesPerMinute: 2},
+	})
+
+	for i := 0; i < 2; i++ {
+		if reply := env.send(t); reply != "AI回复内容" {
+			t.Fatalf("第 %d 条消息应正常回复，实际: %q", i+1, reply)
+		}
+	}
+	if reply := env.send(t); reply != rateLimitReplyBusy {
+		t.Fatalf("超出频率应回复限流提示，实际: %q", reply)
+	}
+	if reply := env.send(t); reply != "" {
+		t.Fatalf("一分钟内只应提示一次，实际: %q", reply)
+	}
+
+	env.now = env.now.Add(61 * time.Second)
+	if reply := env.send(t); reply != "AI回复内容" {
+		t.Fatalf("窗口过后应恢复，实际: %q", reply)
+	}
+
+	counters := env.limiter.Counters("bot-1")
+	if len(counters) != 2 {
+		t.Fatalf("应有发送者和机器人两个计数器，实际: %+v", counters)
+	}
+	for _, counter := range counters {
+		if counter.Scope == rateLimitScopeSender && (counter.Rejected != 2 || counter.MessagesLastMinute != 1) {
+			t.Fatalf("发送者计数错误: %+v", counter)
+		}
+		if counter.InFlight != 0 {
+			t.Fatalf("处理结束后不应有进行中的请求: %+v", counter)
+		}
+	}
+}
+
+func TestRateLimiterDailyTokens(t *testing.T) {
+	env := newRateLimitTestEnv(t, config.RateLimitConfig{
+		RejectMessage: "今天就聊到这里吧。",
+		Bot:           config.RateLimitPolicy{DailyTokens: 150},
+	})
+
+	env.send(t)
+	env.send(t)
+	if reply := env.send(t); reply != "今天就聊到这里吧。" {
+		t.Fatalf("超出每日额度应回复自定义提示，实际: %q", reply)
+	}
+	for _, counter := range env.limiter.Counters("") {
+		if counter.TokensToday != 200 {
+			t.Fatalf("应累计每轮用量，实际: %+v", counter)
+		}
+	}
+
+	env.now = env.now.Add(24 * time.Hour)
+	if reply := env.send(t); reply != "AI回复内容" {
+		t.Fatalf("新的一天应重置额度，实际: %q", reply)
+	}
+}
+
+func TestRateLimiterMaxConcurrent(t *testing.T) {
+	limiter := NewRateLimiter(slog.Default(), config.RateLimitConfig{
+		Sender: config.RateLimitPolicy{MaxConcurrent: 1},
+	})
+	lease, reason, _ := limiter.acquire("bot-1", "contact:c1")
+	if reason != "" {
+		t.Fatalf("第一个请求应被接受: %s", reason)
+	}
+	if _, reason, notify := limiter.acquire("bot-1", "contact:c1"); reason != rateLimitReasonConcurrent || !notify {
+		t.Fatalf("并发超限应被拒绝，实际: %q %v", reason, notify)
+	}
+	if _, reason, _ := limiter.acquire("bot-1", "contact:c2"); reason != "" {
+		t.Fatalf("其他联系人不应受影响: %s", reason)
+	}
+	lease.release()
+	lease.release()
+	if _, reason, _ := limiter.acquire("bot-1", "contact:c1"); reason != "" {
+		t.Fatalf("释放后应可再次请求: %s", reason)
+	}
+}
+
+func TestRateLimiterCountsMergedMessages(t *testing.T) {
+	limiter := NewRateLimiter(slog.Default(), config.RateLimitConfig{
+		Sender: config.RateLimitPolicy{MessagesPerMinute: 2, MaxConcurrent: 1},
+	})
+	lease, reason, _ := limiter.acquire("bot-1", "session:chat-1")
+	if reason != "" {
+		t.Fatalf("首条消息应被接受: %s", reason)
+	}
+	defer lease.release()
+	ctx := context.WithValue(context.Background(), rateLeaseContextKey{}, lease)
+
+	// 合并进当前轮次的消息同样计入频率，但不占用并发
+	if !admitRateLimit(ctx) {
+		t.Fatal("未超出频率的消息应可合并")
+	}
+	if admitRateLimit(ctx) {
+		t.Fatal("超出频率的消息不应合并")
+	}
+	if counters := limiter.Counters("bot-1"); counters[1].MessagesLastMinute != 2 || counters[1].InFlight != 1 {
+		t.Fatalf("计数错误: %+v", counters)
+	}
+}
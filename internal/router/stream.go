@@ -0,0 +1,461 @@
+package router
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/memohai/memoh/internal/channel"
+	"github.com/memohai/memoh/internal/chat"
+)
+
+// ChatStreamer is implemented by chat gateways that can stream a response as it is generated.
+type ChatStreamer interface {
+	StreamChat(ctx context.Context, req chat.ChatRequest) (<-chan chat.StreamChunk, <-chan error)
+}
+
+// streamMode selects how a streamed answer is delivered to a channel.
+type streamMode int
+
+const (
+	streamModeNone streamMode = iota
+	// streamModeEdit posts a draft reply and edits it in place as text arrives.
+	streamModeEdit
+	// streamModeBlock sends finished paragraphs as separate messages.
+	streamModeBlock
+)
+
+// blockStreamMinRunes is how much text block streaming buffers before it flushes
+// at the next paragraph boundary.
+const blockStreamMinRunes = 800
+
+// streamInterruptedNote ends a reply whose stream failed after part of it was delivered.
+const streamInterruptedNote = "（回复中断，请稍后重试。）"
+
+func resolveStreamMode(capabilities channel.ChannelCapabilities, sender channel.ReplySender) streamMode {
+	if capabilities.Streaming && capabilities.Edit {
+		if _, ok := sender.(channel.StreamReplySender); ok {
+			return streamModeEdit
+		}
+	}
+	if capabilities.BlockStreaming {
+		return streamModeBlock
+	}
+	return streamModeNone
+}
+
+// streamEvent is the subset of agent stream actions the router consumes.
+type streamEvent struct {
+	Type     string              `json:"type"`
+	Delta    string              `json:"delta"`
+	ToolName string              `json:"toolName"`
+	Input    json.RawMessage     `json:"input"`
+	Message  string              `json:"message"`
+	Messages []chat.ModelMessage `json:"messages"`
+	Usage    *chat.GatewayUsage  `json:"usage"`
+}
+
+// streamWriter delivers streamed text to a channel.
+type streamWriter interface {
+	write(ctx context.Context, delta string)
+	endSegment(ctx context.Context)
+	tick(ctx context.Context)
+	finish(ctx context.Context, replies []channel.Message) error
+	// abort ends a failed stream. It reports whether part of the reply had reached the
+	// channel, in which case the reply was closed with streamInterruptedNote.
+	abort(ctx context.Context) bool
+}
+
+// streamReply runs a chat round with StreamChat and delivers the answer progressively.
+func (p *ChannelInboundProcessor) streamReply(ctx context.Context, streamer ChatStreamer, req chat.ChatRequest, msg channel.InboundMessage, desc channel.Descriptor, sender channel.ReplySender, mode streamMode) error {
+	target := strings.TrimSpace(msg.ReplyTarget)
+	policy := channel.NormalizeOutboundPolicy(desc.OutboundPolicy)
+
+	var out streamWriter
+	switch mode {
+	case streamModeEdit:
+		out = &draftWriter{
+			sender:   sender.(channel.StreamReplySender),
+			target:   target,
+			policy:   policy,
+			interval: time.Duration(policy.StreamEditIntervalMs) * time.Millisecond,
+			logger:   p.logger,
+		}
+	default:
+		out = &blockWriter{
+			sender:       sender,
+			target:       target,
+			capabilities: desc.Capabilities,
+			logger:       p.logger,
+		}
+	}
+
+	chunks, errs := streamer.StreamChat(ctx, req)
+	ticker := time.NewTicker(time.Duration(policy.StreamEditIntervalMs) * time.Millisecond)
+	defer ticker.Stop()
+
+	var (
+		finalMessages []chat.ModelMessage
+		streamErr     error
+	)
+loop:
+	for {
+		select {
+		case chunk, ok := <-chunks:
+			if !ok {
+				break loop
+			}
+			var event streamEvent
+			if err := json.Unmarshal(chunk, &event); err != nil {
+				continue
+			}
+			switch event.Type {
+			case "text_delta":
+				out.write(ctx, event.Delta)
+			case "text_end":
+				out.endSegment(ctx)
+			case "tool_call_start":
+				if w, ok := out.(*blockWriter); ok && event.ToolName == "send_message" {
+					var args sendMessageToolArgs
+					if parseToolArguments(string(event.Input), &args) {
+						w.noteToolSend(extractSendMessageText(args), shouldSuppressForToolCall(p.registry, args, msg.Channel, target))
+					}
+				}
+			case "agent_end":
+				finalMessages = event.Messages
+				chargeRateLimit(ctx, event.Usage.ToUsage())
+			case "error":
+				streamErr = fmt.Errorf("agent stream error: %s", strings.TrimSpace(event.Message))
+			}
+		case <-ticker.C:
+			out.tick(ctx)
+		}
+	}
+	if err := <-errs; err != nil {
+		streamErr = err
+	}
+	if streamErr != nil {
+		// Retrying a partly delivered reply would send its first part twice.
+		if out.abort(ctx) {
+			if p.logger != nil {
+				p.logger.Warn("stream reply interrupted", slog.Any("error", streamErr))
+			}
+			return nil
+		}
+		return streamErr
+	}
+
+	var replies []channel.Message
+	if len(finalMessages) > 0 {
+		replies = buildReplyMessages(p.registry, finalMessages, msg.Channel, target, desc.Capabilities)
+	} else if text := streamedText(out); text != "" && !isSilentReplyText(text) {
+		// The gateway did not report final messages; fall back to the streamed text.
+		replies = []channel.Message{buildChannelMessage(chat.AssistantOutput{Content: text}, desc.Capabilities)}
+	}
+	return out.finish(ctx, replies)
+}
+
+func streamedText(out streamWriter) string {
+	switch w := out.(type) {
+	case *draftWriter:
+		return strings.TrimSpace(w.text.String())
+	case *blockWriter:
+		if w.sent || w.toolSent {
+			return ""
+		}
+		return strings.TrimSpace(w.all.String())
+	}
+	return ""
+}
+
+// appendSegmentText appends a text delta, separating text segments with a blank line.
+func appendSegmentText(buf *strings.Builder, pendingBreak *bool, delta string) {
+	if delta == "" {
+		return
+	}
+	if *pendingBreak && strings.TrimSpace(buf.String()) != "" {
+		buf.WriteString("\n\n")
+	}
+	*pendingBreak = false
+	buf.WriteString(delta)
+}
+
+// isSilentReplyPrefix reports whether text may still turn into the silent reply token,
+// in which case it must not be shown as a draft.
+func isSilentReplyPrefix(text string) bool {
+	trimmed := strings.TrimSpace(text)
+	return trimmed != "" && strings.HasPrefix(silentReplyToken, trimmed)
+}
+
+// draftWriter streams text into one draft message that is edited in place, at most
+// once per interval. The final reply replaces the draft when the round ends.
+type draftWriter struct {
+	sender   channel.StreamReplySender
+	target   string
+	policy   channel.OutboundPolicy
+	interval time.Duration
+	logger   *slog.Logger
+
+	text         strings.Builder
+	pendingBreak bool
+	messageID    string
+	shown        string
+	edits        int
+	lastEdit     time.Time
+	failed       bool
+}
+
+func (w *draftWriter) write(ctx context.Context, delta string) {
+	appendSegmentText(&w.text, &w.pendingBreak, delta)
+	if w.messageID != "" || w.failed {
+		return
+	}
+	draft := w.draft()
+	if draft == "" {
+		return
+	}
+	messageID, err := w.sender.SendEditable(ctx, channel.OutboundMessage{
+		Target:  w.target,
+		Message: channel.Message{Text: draft},
+	})
+	if err != nil {
+		w.failed = true
+		if w.logger != nil {
+			w.logger.Warn("send stream draft failed", slog.Any("error", err))
+		}
+		return
+	}
+	w.messageID = messageID
+	w.shown = draft
+	w.lastEdit = time.Now()
+}
+
+func (w *draftWriter) endSegment(context.Context) {
+	w.pendingBreak = true
+}
+
+func (w *draftWriter) tick(ctx context.Context) {
+	if w.messageID == "" || w.failed {
+		return
+	}
+	if w.policy.StreamMaxEdits > 0 && w.edits >= w.policy.StreamMaxEdits {
+		return
+	}
+	if time.Since(w.lastEdit) < w.interval {
+		return
+	}
+	draft := w.draft()
+	if draft == "" || draft == w.shown {
+		return
+	}
+	w.edits++
+	w.lastEdit = time.Now()
+	if err := w.sender.Edit(ctx, w.target, w.messageID, channel.Message{Text: draft}); err != nil {
+		if w.logger != nil {
+			w.logger.Warn("edit stream draft failed", slog.Any("error", err))
+		}
+		return
+	}
+	w.shown = draft
+}
+
+// draft returns the text to show in the draft, truncated to the chunk limit.
+func (w *draftWriter) draft() string {
+	text := strings.TrimSpace(w.text.String())
+	if text == "" || isSilentReplyPrefix(text) || isSilentReplyText(text) {
+		return ""
+	}
+	runes := []rune(text)
+	if w.policy.TextChunkLimit > 0 && len(runes) > w.policy.TextChunkLimit {
+		return string(runes[:w.policy.TextChunkLimit-1]) + "…"
+	}
+	return text
+}
+
+func (w *draftWriter) finish(ctx context.Context, replies []channel.Message) error {
+	if w.messageID == "" {
+		return sendReplies(ctx, w.sender, w.target, replies)
+	}
+	if len(replies) == 0 {
+		return nil
+	}
+	first := replies[0]
+	attachments := first.Attachments
+	first.Attachments = nil
+	var rest []channel.Message
+	if !first.IsEmpty() {
+		head, tail := w.splitFirst(first)
+		if err := w.sender.Edit(ctx, w.target, w.messageID, head); err != nil {
+			if w.logger != nil {
+				w.logger.Warn("edit final stream reply failed", slog.Any("error", err))
+			}
+			rest = append(rest, first)
+		} else {
+			rest = append(rest, tail...)
+		}
+	}
+	if len(attachments) > 0 {
+		rest = append(rest, channel.Message{Attachments: attachments, Thread: first.Thread, Reply: first.Reply})
+	}
+	rest = append(rest, replies[1:]...)
+	return sendReplies(ctx, w.sender, w.target, rest)
+}
+
+func (w *draftWriter) abort(ctx context.Context) bool {
+	if w.messageID == "" {
+		return false
+	}
+	text := strings.TrimSpace(w.shown) + "\n\n" + streamInterruptedNote
+	if err := w.sender.Edit(ctx, w.target, w.messageID, channel.Message{Text: text}); err != nil && w.logger != nil {
+		w.logger.Warn("edit interrupted stream draft failed", slog.Any("error", err))
+	}
+	return true
+}
+
+// splitFirst splits an over-long text reply so its first chunk fits in the draft.
+func (w *draftWriter) splitFirst(msg channel.Message) (channel.Message, []channel.Message) {
+	limit := w.policy.TextChunkLimit
+	if limit <= 0 || len(msg.Parts) > 0 || len([]rune(msg.Text)) <= limit {
+		return msg, nil
+	}
+	chunker := w.policy.Chunker
+	if msg.Format == channel.MessageFormatMarkdown {
+		chunker = channel.ChunkMarkdownText
+	}
+	var head channel.Message
+	tail := make([]channel.Message, 0)
+	for _, chunk := range chunker(msg.Text, limit) {
+		chunk = strings.TrimSpace(chunk)
+		if chunk == "" {
+			continue
+		}
+		item := msg
+		item.Text = chunk
+		if head.IsEmpty() {
+			head = item
+			continue
+		}
+		tail = append(tail, item)
+	}
+	if head.IsEmpty() {
+		return msg, nil
+	}
+	return head, tail
+}
+
+// blockWriter sends finished paragraphs as separate messages while the answer streams.
+type blockWriter struct {
+	sender       channel.ReplySender
+	target       string
+	capabilities channel.ChannelCapabilities
+	logger       *slog.Logger
+
+	buf          strings.Builder
+	all          strings.Builder
+	pendingBreak bool
+	sent         bool
+	// toolTexts holds the texts the agent sent with the send_message tool; toolSent is set
+	// once it sent one to the reply target, after which no more blocks are sent.
+	toolTexts []string
+	toolSent  bool
+}
+
+// noteToolSend records a send_message call, so the blocks after it are deduplicated as
+// buildReplyMessages does for replies that are not streamed.
+func (w *blockWriter) noteToolSend(text string, sameTarget bool) {
+	if text = strings.TrimSpace(text); text != "" {
+		w.toolTexts = append(w.toolTexts, text)
+	}
+	if sameTarget {
+		w.toolSent = true
+	}
+}
+
+func (w *blockWriter) write(ctx context.Context, delta string) {
+	appendSegmentText(&w.all, &w.pendingBreak, delta)
+	w.buf.WriteString(delta)
+	text := w.buf.String()
+	if len([]rune(text)) < blockStreamMinRunes {
+		return
+	}
+	idx := strings.LastIndex(text, "\n\n")
+	if idx <= 0 {
+		return
+	}
+	w.flush(ctx, text[:idx])
+	w.buf.Reset()
+	w.buf.WriteString(text[idx+2:])
+}
+
+func (w *blockWriter) endSegment(ctx context.Context) {
+	w.pendingBreak = true
+	w.flush(ctx, w.buf.String())
+	w.buf.Reset()
+}
+
+func (w *blockWriter) tick(context.Context) {}
+
+func (w *blockWriter) flush(ctx context.Context, text string) {
+	text = strings.TrimSpace(text)
+	if text == "" || isSilentReplyText(text) || w.toolSent || isMessagingToolDuplicate(text, w.toolTexts) {
+		return
+	}
+	err := w.sender.Send(ctx, channel.OutboundMessage{
+		Target:  w.target,
+		Message: buildChannelMessage(chat.AssistantOutput{Content: text}, w.capabilities),
+	})
+	if err != nil {
+		if w.logger != nil {
+			w.logger.Warn("send stream block failed", slog.Any("error", err))
+		}
+		return
+	}
+	w.sent = true
+}
+
+func (w *blockWriter) finish(ctx context.Context, replies []channel.Message) error {
+	if !w.sent {
+		return sendReplies(ctx, w.sender, w.target, replies)
+	}
+	w.flush(ctx, w.buf.String())
+	w.buf.Reset()
+	// Text already went out block by block; only attachments are left to deliver.
+	attachments := make([]channel.Message, 0)
+	for _, reply := range replies {
+		if len(reply.Attachments) > 0 {
+			attachments = append(attachments, channel.Message{Attachments: reply.Attachments})
+		}
+	}
+	return sendReplies(ctx, w.sender, w.target, attachments)
+}
+
+func (w *blockWriter) abort(ctx context.Context) bool {
+	if !w.sent {
+		return false
+	}
+	w.flush(ctx, w.buf.String())
+	w.buf.Reset()
+	err := w.sender.Send(ctx, channel.OutboundMessage{
+		Target:  w.target,
+		Message: channel.Message{Text: streamInterruptedNote},
+	})
+	if err != nil && w.logger != nil {
+		w.logger.Warn("send stream interrupted note failed", slog.Any("error", err))
+	}
+	return true
+}
+
+func sendReplies(ctx context.Context, sender channel.ReplySender, target string, replies []channel.Message) error {
+	for _, reply := range replies {
+		if err := sender.Send(ctx, channel.OutboundMessage{
+			Target:  target,
+			Message: reply,
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,292 @@
+package router
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/memohai/memoh/internal/channel"
+	"github.com/memohai/memoh/internal/chat"
+)
+
+type fakeStreamGateway struct {
+	fakeChatGateway
+	chunks []string
+	err    error
+}
+
+func (f *fakeStreamGateway) StreamChat(ctx context.Context, req chat.ChatRequest) (<-chan chat.StreamChunk, <-chan error) {
+	f.gotReq = req
+	chunkCh := make(chan chat.StreamChunk)
+	errCh := make(chan error, 1)
+	go func() {
+		defer close(chunkCh)
+		defer close(errCh)
+		for _, chunk := range f.chunks {
+			chunkCh <- chat.StreamChunk(chunk)
+		}
+		if f.err != nil {
+			errCh <- f.err
+		}
+	}()
+	return chunkCh, errCh
+}
+
+type fakeStreamReplySender struct {
+	fakeReplySender
+	drafts []string
+	edits  []string
+}
+
+func (s *fakeStreamReplySender) SendEditable(ctx context.Context, msg channel.OutboundMessage) (string, error) {
+	s.drafts = append(s.drafts, msg.Message.PlainText())
+	return "msg-1", nil
+}
+
+func (s *fakeStreamReplySender) Edit(ctx context.Context, target, messageID string, msg channel.Message) error {
+	if messageID != "msg-1" {
+		return fmt.Errorf("unexpected message id %s", messageID)
+	}
+	s.edits = append(s.edits, msg.PlainText())
+	return nil
+}
+
+type fakeStreamAdapter struct {
+	capabilities channel.ChannelCapabilities
+}
+
+func (a *fakeStreamAdapter) Type() channel.ChannelType { return channel.ChannelType("stream-test") }
+
+func (a *fakeStreamAdapter) Descriptor() channel.Descriptor {
+	return channel.Descriptor{
+		Type:           a.Type(),
+		DisplayName:    "Stream Test",
+		Capabilities:   a.capabilities,
+		OutboundPolicy: channel.OutboundPolicy{StreamEditIntervalMs: 1},
+	}
+}
+
+func streamTestInbound() (channel.ChannelConfig, channel.InboundMessage) {
+	cfg := channel.ChannelConfig{ID: "cfg-1", BotID: "bot-1", ChannelType: channel.ChannelType("stream-test")}
+	msg := channel.InboundMessage{
+		Channel:     channel.ChannelType("stream-test"),
+		Message:     channel.Message{Text: "你好"},
+		ReplyTarget: "target-id",
+		Conversation: channel.Conversation{
+			ID:   "chat-1",
+			Type: "p2p",
+		},
+	}
+	return cfg, msg
+}
+
+func TestChannelInboundProcessorStreamEdit(t *testing.T) {
+	gateway := &fakeStreamGateway{
+		chunks: []string{
+			`{"type":"agent_start"}`,
+			`{"type":"text_start"}`,
+			`{"type":"text_delta","delta":"正在"}`,
+			`{"type":"text_delta","delta":"思考"}`,
+			`{"type":"text_end"}`,
+			`{"type":"agent_end","messages":[{"role":"assistant","content":"正在思考，答案是 42"}]}`,
+		},
+	}
+	processor := newTestProcessor(t, withAdapters(&fakeStreamAdapter{capabilities: channel.ChannelCapabilities{Text: true, Edit: true, Streaming: true}}), withSession("stream-test:bot-1:chat-1", "user-123"), withGateway(gateway))
+	sender := &fakeStreamReplySender{}
+	cfg, msg := streamTestInbound()
+
+	if err := processor.HandleInbound(context.Background(), cfg, msg, sender); err != nil {
+		t.Fatalf("不应报错: %v", err)
+	}
+	if gateway.gotReq.Query != "你好" {
+		t.Errorf("StreamChat 请求 Query 错误: %s", gateway.gotReq.Query)
+	}
+	if len(sender.drafts) != 1 || sender.drafts[0] != "正在" {
+		t.Fatalf("应发送一条草稿，实际: %+v", sender.drafts)
+	}
+	if len(sender.edits) == 0 || sender.edits[len(sender.edits)-1] != "正在思考，答案是 42" {
+		t.Fatalf("最终应编辑为完整回复，实际: %+v", sender.edits)
+	}
+	if len(sender.sent) != 0 {
+		t.Fatalf("编辑模式不应另发消息，实际: %+v", sender.sent)
+	}
+}
+
+func TestChannelInboundProcessorStreamSilentReply(t *testing.T) {
+	gateway := &fakeStreamGateway{
+		chunks: []string{
+			`{"type":"text_delta","delta":"NO_"}`,
+			`{"type":"text_delta","delta":"REPLY"}`,
+			`{"type":"agent_end","messages":[{"role":"assistant","content":"NO_REPLY"}]}`,
+		},
+	}
+	processor := newTestProcessor(t, withAdapters(&fakeStreamAdapter{capabilities: channel.ChannelCapabilities{Text: true, Edit: true, Streaming: true}}), withSession("stream-test:bot-1:chat-1", "user-123"), withGateway(gateway))
+	sender := &fakeStreamReplySender{}
+	cfg, msg := streamTestInbound()
+
+	if err := processor.HandleInbound(context.Background(), cfg, msg, sender); err != nil {
+		t.Fatalf("不应报错: %v", err)
+	}
+	if len(sender.drafts) != 0 || len(sender.edits) != 0 || len(sender.sent) != 0 {
+		t.Fatalf("NO_REPLY 不应发送任何内容，实际: %+v %+v %+v", sender.drafts, sender.edits, sender.sent)
+	}
+}
+
+func TestChannelInboundProcessorStreamWithoutEditSender(t *testing.T) {
+	gateway := &fakeStreamGateway{
+		chunks: []string{
+			`{"type":"text_delta","delta":"你好"}`,
+			`{"type":"agent_end","messages":[{"role":"assistant","content":"你好"}]}`,
+		},
+	}
+	gateway.resp = chat.ChatResponse{
+		Messages: []chat.ModelMessage{
+			{Role: "assistant", Content: chat.NewTextContent("同步回复")},
+		},
+	}
+	processor := newTestProcessor(t, withAdapters(&fakeStreamAdapter{capabilities: channel.ChannelCapabilities{Text: true, Edit: true, Streaming: true}}), withSession("stream-test:bot-1:chat-1", "user-123"), withGateway(gateway))
+	sender := &fakeReplySender{}
+	cfg, msg := streamTestInbound()
+
+	if err := processor.HandleInbound(context.Background(), cfg, msg, sender); err != nil {
+		t.Fatalf("不应报错: %v", err)
+	}
+	if len(sender.sent) != 1 || sender.sent[0].Message.PlainText() != "同步回复" {
+		t.Fatalf("不支持编辑时应走同步 Chat，实际: %+v", sender.sent)
+	}
+}
+
+func TestChannelInboundProcessorStreamBlocks(t *testing.T) {
+	gateway := &fakeStreamGateway{
+		chunks: []string{
+			`{"type":"text_delta","delta":"先查一下天气。"}`,
+			`{"type":"text_end"}`,
+			`{"type":"tool_call_start","toolName":"weather"}`,
+			`{"type":"tool_call_end","toolName":"weather"}`,
+			`{"type":"text_delta","delta":"今天晴。"}`,
+			`{"type":"text_end"}`,
+			`{"type":"agent_end","messages":[{"role":"assistant","content":"先查一下天气。"},{"role":"assistant","content":"今天晴。"}]}`,
+		},
+	}
+	processor := newTestProcessor(t, withAdapters(&fakeStreamAdapter{capabilities: channel.ChannelCapabilities{Text: true, BlockStreaming: true}}), withSession("stream-test:bot-1:chat-1", "user-123"), withGateway(gateway))
+	sender := &fakeReplySender{}
+	cfg, msg := streamTestInbound()
+
+	if err := processor.HandleInbound(context.Background(), cfg, msg, sender); err != nil {
+		t.Fatalf("不应报错: %v", err)
+	}
+	if len(sender.sent) != 2 {
+		t.Fatalf("应按段落发送两条消息，实际: %+v", sender.sent)
+	}
+	if sender.sent[0].Message.PlainText() != "先查一下天气。" || sender.sent[1].Message.PlainText() != "今天晴。" {
+		t.Fatalf("段落内容错误: %+v", sender.sent)
+	}
+}
+
+func TestChannelInboundProcessorStreamError(t *testing.T) {
+	gateway := &fakeStreamGateway{
+		chunks: []string{`{"type":"error","message":"model overloaded"}`},
+	}
+	processor := newTestProcessor(t, withAdapters(&fakeStreamAdapter{capabilities: channel.ChannelCapabilities{Text: true, Edit: true, Streaming: true}}), withSession("stream-test:bot-1:chat-1", "user-123"), withGateway(gateway))
+	sender := &fakeStreamReplySender{}
+	cfg, msg := streamTestInbound()
+
+	if err := processor.HandleInbound(context.Background(), cfg, msg, sender); err == nil {
+		t.Fatalf("流式错误应返回错误")
+	}
+	if len(sender.drafts) != 0 || len(sender.sent) != 0 {
+		t.Fatalf("出错时不应发送消息，实际: %+v %+v", sender.drafts, sender.sent)
+	}
+}
+
+func TestChannelInboundProcessorStreamErrorAfterDraft(t *testing.T) {
+	gateway := &fakeStreamGateway{
+		chunks: []string{
+			`{"type":"text_delta","delta":"正在思考"}`,
+			`{"type":"error","message":"model overloaded"}`,
+		},
+	}
+	processor := newTestProcessor(t, withAdapters(&fakeStreamAdapter{capabilities: channel.ChannelCapabilities{Text: true, Edit: true, Streaming: true}}), withSession("stream-test:bot-1:chat-1", "user-123"), withGateway(gateway))
+	sender := &fakeStreamReplySender{}
+	cfg, msg := streamTestInbound()
+
+	// 草稿已经发出时不再返回错误，避免重试重复发送
+	if err := processor.HandleInbound(context.Background(), cfg, msg, sender); err != nil {
+		t.Fatalf("已发出部分回复时不应返回错误: %v", err)
+	}
+	if len(sender.drafts) != 1 || len(sender.edits) == 0 {
+		t.Fatalf("应发送草稿并在出错后编辑，实际: %+v %+v", sender.drafts, sender.edits)
+	}
+	if last := sender.edits[len(sender.edits)-1]; last != "正在思考\n\n"+streamInterruptedNote {
+		t.Fatalf("草稿应以中断提示结束，实际: %q", last)
+	}
+}
+
+func TestChannelInboundProcessorStreamBlocksErrorAfterSend(t *testing.T) {
+	gateway := &fakeStreamGateway{
+		chunks: []string{
+			`{"type":"text_delta","delta":"先查一下天气。"}`,
+			`{"type":"text_end"}`,
+			`{"type":"text_delta","delta":"今天"}`,
+		},
+		err: fmt.Errorf("connection reset"),
+	}
+	processor := newTestProcessor(t, withAdapters(&fakeStreamAdapter{capabilities: channel.ChannelCapabilities{Text: true, BlockStreaming: true}}), withSession("stream-test:bot-1:chat-1", "user-123"), withGateway(gateway))
+	sender := &fakeReplySender{}
+	cfg, msg := streamTestInbound()
+
+	if err := processor.HandleInbound(context.Background(), cfg, msg, sender); err != nil {
+		t.Fatalf("已发出部分回复时不应返回错误: %v", err)
+	}
+	if len(sender.sent) != 3 || sender.sent[2].Message.PlainText() != streamInterruptedNote {
+		t.Fatalf("应发送已生成的段落和中断提示，实际: %+v", sender.sent)
+	}
+}
+
+func TestChannelInboundProcessorStreamBlocksSuppressOnToolSend(t *testing.T) {
+	gateway := &fakeStreamGateway{
+		chunks: []string{
+			`{"type":"text_delta","delta":"我来发一下。"}`,
+			`{"type":"text_end"}`,
+			`{"type":"tool_call_start","toolName":"send_message","input":{"platform":"stream-test","target":"target-id","message":{"text":"AI回复内容"}}}`,
+			`{"type":"tool_call_end","toolName":"send_message"}`,
+			`{"type":"text_delta","delta":"AI回复内容"}`,
+			`{"type":"text_end"}`,
+			`{"type":"agent_end","messages":[{"role":"assistant","content":"我来发一下。","tool_calls":[{"id":"call-1","type":"function","function":{"name":"send_message","arguments":"{\"platform\":\"stream-test\",\"target\":\"target-id\",\"message\":{\"text\":\"AI回复内容\"}}"}}]},{"role":"assistant","content":"AI回复内容"}]}`,
+		},
+	}
+	processor := newTestProcessor(t, withAdapters(&fakeStreamAdapter{capabilities: channel.ChannelCapabilities{Text: true, BlockStreaming: true}}), withSession("stream-test:bot-1:chat-1", "user-123"), withGateway(gateway))
+	sender := &fakeReplySender{}
+	cfg, msg := streamTestInbound()
+
+	if err := processor.HandleInbound(context.Background(), cfg, msg, sender); err != nil {
+		t.Fatalf("不应报错: %v", err)
+	}
+	// 工具调用前的段落已经发出，之后的文本不应重复发送
+	if len(sender.sent) != 1 || sender.sent[0].Message.PlainText() != "我来发一下。" {
+		t.Fatalf("工具已发送当前会话消息，应抑制之后的段落，实际: %+v", sender.sent)
+	}
+}
+
+func TestChannelInboundProcessorStreamBlocksDedupeWithToolSend(t *testing.T) {
+	gateway := &fakeStreamGateway{
+		chunks: []string{
+			`{"type":"tool_call_start","toolName":"send_message","input":{"platform":"stream-test","target":"other-target","message":{"text":"AI回复内容"}}}`,
+			`{"type":"tool_call_end","toolName":"send_message"}`,
+			`{"type":"text_delta","delta":"AI回复内容"}`,
+			`{"type":"text_end"}`,
+			`{"type":"text_delta","delta":"已转发给对方。"}`,
+			`{"type":"text_end"}`,
+		},
+	}
+	processor := newTestProcessor(t, withAdapters(&fakeStreamAdapter{capabilities: channel.ChannelCapabilities{Text: true, BlockStreaming: true}}), withSession("stream-test:bot-1:chat-1", "user-123"), withGateway(gateway))
+	sender := &fakeReplySender{}
+	cfg, msg := streamTestInbound()
+
+	if err := processor.HandleInbound(context.Background(), cfg, msg, sender); err != nil {
+		t.Fatalf("不应报错: %v", err)
+	}
+	if len(sender.sent) != 1 || sender.sent[0].Message.PlainText() != "已转发给对方。" {
+		t.Fatalf("工具已发送的文本不应重复发送，实际: %+v", sender.sent)
+	}
+}
@@ -15,8 +15,9 @@ import (
 )
 
 type Service struct {
-	queries *sqlc.Queries
-	logger  *slog.Logger
+	queries                *sqlc.Queries
+	logger                 *slog.Logger
+	embeddingModelListener EmbeddingModelListener
 }
 
 func NewService(log *slog.Logger, queries *sqlc.Queries) *Service {
@@ -26,6 +27,11 @@ func NewService(log *slog.Logger, queries *sqlc.Queries) *Service {
 	}
 }
 
+// SetEmbeddingModelListener registers a listener for changes of the embedding model of bots.
+func (s *Service) SetEmbeddingModelListener(listener EmbeddingModelListener) {
+	s.embeddingModelListener = listener
+}
+
 func (s *Service) Get(ctx context.Context, userID string) (Settings, error) {
 	pgID, err := parseUUID(userID)
 	if err != nil {
@@ -113,6 +119,7 @@ func (s *Service) GetBot(ctx context.Context, botID string) (Settings, error) {
 				MaxContextLoadTime: DefaultMaxContextLoadTime,
 				Language:           DefaultLanguage,
 				AllowGuest:         false,
+				MemoryScope:        DefaultMemoryScope,
 			}
 			if err := s.attachBotModelConfig(ctx, pgID, &settings); err != nil {
 				return Settings{}, err
@@ -141,6 +148,7 @@ func (s *Service) UpsertBot(ctx context.Context, botID string, req UpsertRequest
 		MaxContextLoadTime: DefaultMaxContextLoadTime,
 		Language:           DefaultLanguage,
 		AllowGuest:         false,
+		MemoryScope:        DefaultMemoryScope,
 	}
 	existing, err := s.queries.GetSettingsByBotID(ctx, pgID)
 	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
@@ -158,22 +166,53 @@ func (s *Service) UpsertBot(ctx context.Context, botID string, req UpsertRequest
 	if req.AllowGuest != nil {
 		current.AllowGuest = *req.AllowGuest
 	}
+	if req.UnifiedSession != nil {
+		current.UnifiedSession = *req.UnifiedSession
+	}
+	if req.MessageDebounceMs != nil {
+		current.MessageDebounceMs = min(max(*req.MessageDebounceMs, 0), MaxMessageDebounceMs)
+	}
+	if req.InterruptOnNewMessage != nil {
+		current.InterruptOnNewMessage = *req.InterruptOnNewMessage
+	}
+	if scope := strings.ToLower(strings.TrimSpace(req.MemoryScope)); scope != "" {
+		if !isMemoryScope(scope) {
+			return Settings{}, fmt.Errorf("invalid memory scope: %s", req.MemoryScope)
+		}
+		current.MemoryScope = scope
+	}
 
 	_, err = s.queries.UpsertBotSettings(ctx, sqlc.UpsertBotSettingsParams{
-		BotID:              pgID,
-		MaxContextLoadTime: int32(current.MaxContextLoadTime),
-		Language:           current.Language,
-		AllowGuest:         current.AllowGuest,
+		BotID:                 pgID,
+		MaxContextLoadTime:    int32(current.MaxContextLoadTime),
+		Language:              current.Language,
+		AllowGuest:            current.AllowGuest,
+		UnifiedSession:        current.UnifiedSession,
+		MessageDebounceMs:     int32(current.MessageDebounceMs),
+		InterruptOnNewMessage: current.InterruptOnNewMessage,
+		MemoryScope:           current.MemoryScope,
 	})
 	if err != nil {
 		return Settings{}, err
 	}
+	notifyEmbedding := s.embeddingModelListener != nil && strings.TrimSpace(req.EmbeddingModelID) != ""
+	previousEmbeddingModelID := ""
+	if notifyEmbedding {
+		var previous Settings
+		if err := s.attachBotModelConfig(ctx, pgID, &previous); err != nil {
+			return Settings{}, err
+		}
+		previousEmbeddingModelID = previous.EmbeddingModelID
+	}
 	if err := s.upsertBotModelConfig(ctx, pgID, req); err != nil {
 		return Settings{}, err
 	}
 	if err := s.attachBotModelConfig(ctx, pgID, &current); err != nil {
 		return Settings{}, err
 	}
+	if notifyEmbedding && current.EmbeddingModelID != previousEmbeddingModelID {
+		s.embeddingModelListener.EmbeddingModelChanged(ctx, botID, current.EmbeddingModelID)
+	}
 	return current, nil
 }
 
@@ -207,9 +246,13 @@ func normalizeUserSetting(row sqlc.UserSetting) Settings {
 
 func normalizeBotSetting(row sqlc.BotSetting) Settings {
 	settings := Settings{
-		MaxContextLoadTime: int(row.MaxContextLoadTime),
-		Language:           strings.TrimSpace(row.Language),
-		AllowGuest:         row.AllowGuest,
+		MaxContextLoadTime:    int(row.MaxContextLoadTime),
+		Language:              strings.TrimSpace(row.Language),
+		AllowGuest:            row.AllowGuest,
+		UnifiedSession:        row.UnifiedSession,
+		MessageDebounceMs:     int(row.MessageDebounceMs),
+		InterruptOnNewMessage: row.InterruptOnNewMessage,
+		MemoryScope:           strings.TrimSpace(row.MemoryScope),
 	}
 	if settings.MaxContextLoadTime <= 0 {
 		settings.MaxContextLoadTime = DefaultMaxContextLoadTime
@@ -217,9 +260,20 @@ func normalizeBotSetting(row sqlc.BotSetting) Settings {
 	if settings.Language == "" {
 		settings.Language = DefaultLanguage
 	}
+	if !isMemoryScope(settings.MemoryScope) {
+		settings.MemoryScope = DefaultMemoryScope
+	}
 	return settings
 }
 
+func isMemoryScope(scope string) bool {
+	switch scope {
+	case MemoryScopeContact, MemoryScopeSession, MemoryScopeBot:
+		return true
+	}
+	return false
+}
+
 func (s *Service) attachBotModelConfig(ctx context.Context, botID pgtype.UUID, target *Settings) error {
 	if s.queries == nil || target == nil {
 		return nil
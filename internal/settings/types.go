@@ -1,24 +1,48 @@
 package settings
 
+import "context"
+
 const (
 	DefaultMaxContextLoadTime = 24 * 60
 	DefaultLanguage           = "auto"
+	MaxMessageDebounceMs      = 10000
+
+	// Memory scopes: memories learned from a contact are private to them, shared with the
+	// conversation, or shared across all conversations of the bot.
+	MemoryScopeContact = "contact"
+	MemoryScopeSession = "session"
+	MemoryScopeBot     = "bot"
+	DefaultMemoryScope = MemoryScopeSession
 )
 
+// EmbeddingModelListener is told when the embedding model of a bot changes, so its memories
+// can be embedded with the new model.
+type EmbeddingModelListener interface {
+	EmbeddingModelChanged(ctx context.Context, botID, modelID string)
+}
+
 type Settings struct {
-	ChatModelID        string `json:"chat_model_id" validate:"required"`
-	MemoryModelID      string `json:"memory_model_id" validate:"required"`
-	EmbeddingModelID   string `json:"embedding_model_id" validate:"required"`
-	MaxContextLoadTime int    `json:"max_context_load_time" validate:"required"`
-	Language           string `json:"language" validate:"required"`
-	AllowGuest         bool   `json:"allow_guest" validate:"required"`
+	ChatModelID           string `json:"chat_model_id" validate:"required"`
+	MemoryModelID         string `json:"memory_model_id" validate:"required"`
+	EmbeddingModelID      string `json:"embedding_model_id" validate:"required"`
+	MaxContextLoadTime    int    `json:"max_context_load_time" validate:"required"`
+	Language              string `json:"language" validate:"required"`
+	AllowGuest            bool   `json:"allow_guest" validate:"required"`
+	UnifiedSession        bool   `json:"unified_session"`
+	MessageDebounceMs     int    `json:"message_debounce_ms"`
+	InterruptOnNewMessage bool   `json:"interrupt_on_new_message"`
+	MemoryScope           string `json:"memory_scope"`
 }
 
 type UpsertRequest struct {
-	ChatModelID        string `json:"chat_model_id,omitempty"`
-	MemoryModelID      string `json:"memory_model_id,omitempty"`
-	EmbeddingModelID   string `json:"embedding_model_id,omitempty"`
-	MaxContextLoadTime *int   `json:"max_context_load_time,omitempty"`
-	Language           string `json:"language,omitempty"`
-	AllowGuest         *bool  `json:"allow_guest,omitempty"`
+	ChatModelID           string `json:"chat_model_id,omitempty"`
+	MemoryModelID         string `json:"memory_model_id,omitempty"`
+	EmbeddingModelID      string `json:"embedding_model_id,omitempty"`
+	MaxContextLoadTime    *int   `json:"max_context_load_time,omitempty"`
+	Language              string `json:"language,omitempty"`
+	AllowGuest            *bool  `json:"allow_guest,omitempty"`
+	UnifiedSession        *bool  `json:"unified_session,omitempty"`
+	MessageDebounceMs     *int   `json:"message_debounce_ms,omitempty"`
+	InterruptOnNewMessage *bool  `json:"interrupt_on_new_message,omitempty"`
+	MemoryScope           string `json:"memory_scope,omitempty"`
 }
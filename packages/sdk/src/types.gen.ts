@@ -96,6 +96,7 @@ export type ChannelChannelCapabilities = {
     media?: boolean;
     native_commands?: boolean;
     polls?: boolean;
+    presence?: boolean;
     reactions?: boolean;
     reply?: boolean;
     rich_text?: boolean;
@@ -194,7 +195,9 @@ export type ChannelReplyRef = {
 
 export type ChannelSendRequest = {
     message?: ChannelMessage;
+    session_id?: string;
     target?: string;
+    to?: string;
     user_id?: string;
 };
 
@@ -251,6 +254,7 @@ export type ChatChatResponse = {
     model?: string;
     provider?: string;
     skills?: Array<string>;
+    usage?: ChatUsage;
 };
 
 export type ChatModelMessage = {
@@ -272,6 +276,12 @@ export type ChatToolCallFunction = {
     name?: string;
 };
 
+export type ChatUsage = {
+    input_tokens?: number;
+    output_tokens?: number;
+    total_tokens?: number;
+};
+
 export type GithubComMemohaiMemohInternalMcpConnection = {
     active: boolean;
     bot_id: string;
@@ -493,6 +503,7 @@ export type HandlersSnapshotInfo = {
 };
 
 export type HandlersMemoryAddPayload = {
+    contact_id?: string;
     embedding_enabled?: boolean;
     filters?: {
         [key: string]: unknown;
@@ -504,6 +515,7 @@ export type HandlersMemoryAddPayload = {
         [key: string]: unknown;
     };
     run_id?: string;
+    scope?: string;
 };
 
 export type HandlersMemoryDeleteAllPayload = {
@@ -511,6 +523,7 @@ export type HandlersMemoryDeleteAllPayload = {
 };
 
 export type HandlersMemoryEmbedUpsertPayload = {
+    contact_id?: string;
     filters?: {
         [key: string]: unknown;
     };
@@ -521,11 +534,13 @@ export type HandlersMemoryEmbedUpsertPayload = {
     model?: string;
     provider?: string;
     run_id?: string;
+    scope?: string;
     source?: string;
     type?: string;
 };
 
 export type HandlersMemorySearchPayload = {
+    contact_id?: string;
     embedding_enabled?: boolean;
     filters?: {
         [key: string]: unknown;
@@ -601,6 +616,7 @@ export type MemoryEmbedUpsertResponse = {
 export type MemoryMemoryItem = {
     agentId?: string;
     botId?: string;
+    contactId?: string;
     createdAt?: string;
     hash?: string;
     id?: string;
@@ -609,6 +625,7 @@ export type MemoryMemoryItem = {
         [key: string]: unknown;
     };
     runId?: string;
+    scope?: string;
     score?: number;
     sessionId?: string;
     updatedAt?: string;
@@ -758,18 +775,26 @@ export type SettingsSettings = {
     allow_guest: boolean;
     chat_model_id: string;
     embedding_model_id: string;
+    interrupt_on_new_message?: boolean;
     language: string;
     max_context_load_time: number;
     memory_model_id: string;
+    memory_scope?: string;
+    message_debounce_ms?: number;
+    unified_session?: boolean;
 };
 
 export type SettingsUpsertRequest = {
     allow_guest?: boolean;
     chat_model_id?: string;
     embedding_model_id?: string;
+    interrupt_on_new_message?: boolean;
     language?: string;
     max_context_load_time?: number;
     memory_model_id?: string;
+    memory_scope?: string;
+    message_debounce_ms?: number;
+    unified_session?: boolean;
 };
 
 export type SubagentAddSkillsRequest = {
@@ -5277,6 +5277,9 @@ const docTemplate = `{
                 "polls": {
                     "type": "boolean"
                 },
+                "presence": {
+                    "type": "boolean"
+                },
                 "reactions": {
                     "type": "boolean"
                 },
@@ -5557,9 +5560,15 @@ const docTemplate = `{
                 "message": {
                     "$ref": "#/definitions/channel.Message"
                 },
+                "session_id": {
+                    "type": "string"
+                },
                 "target": {
                     "type": "string"
                 },
+                "to": {
+                    "type": "string"
+                },
                 "user_id": {
                     "type": "string"
                 }
@@ -5700,6 +5709,9 @@ const docTemplate = `{
                     "items": {
                         "type": "string"
                     }
+                },
+                "usage": {
+                    "$ref": "#/definitions/chat.Usage"
                 }
             }
         },
@@ -5754,6 +5766,20 @@ const docTemplate = `{
                 }
             }
         },
+        "chat.Usage": {
+            "type": "object",
+            "properties": {
+                "input_tokens": {
+                    "type": "integer"
+                },
+                "output_tokens": {
+                    "type": "integer"
+                },
+                "total_tokens": {
+                    "type": "integer"
+                }
+            }
+        },
         "github_com_memohai_memoh_internal_mcp.Connection": {
             "type": "object",
             "required": [
@@ -6326,6 +6352,9 @@ const docTemplate = `{
         "handlers.memoryAddPayload": {
             "type": "object",
             "properties": {
+                "contact_id": {
+                    "type": "string"
+                },
                 "embedding_enabled": {
                     "type": "boolean"
                 },
@@ -6351,6 +6380,9 @@ const docTemplate = `{
                 },
                 "run_id": {
                     "type": "string"
+                },
+                "scope": {
+                    "type": "string"
                 }
             }
         },
@@ -6365,6 +6397,9 @@ const docTemplate = `{
         "handlers.memoryEmbedUpsertPayload": {
             "type": "object",
             "properties": {
+                "contact_id": {
+                    "type": "string"
+                },
                 "filters": {
                     "type": "object",
                     "additionalProperties": {}
@@ -6385,6 +6420,9 @@ const docTemplate = `{
                 "run_id": {
                     "type": "string"
                 },
+                "scope": {
+                    "type": "string"
+                },
                 "source": {
                     "type": "string"
                 },
@@ -6396,6 +6434,9 @@ const docTemplate = `{
         "handlers.memorySearchPayload": {
             "type": "object",
             "properties": {
+                "contact_id": {
+                    "type": "string"
+                },
                 "embedding_enabled": {
                     "type": "boolean"
                 },
@@ -6572,6 +6613,9 @@ const docTemplate = `{
                 "botId": {
                     "type": "string"
                 },
+                "contactId": {
+                    "type": "string"
+                },
                 "createdAt": {
                     "type": "string"
                 },
@@ -6591,6 +6635,9 @@ const docTemplate = `{
                 "runId": {
                     "type": "string"
                 },
+                "scope": {
+                    "type": "string"
+                },
                 "score": {
                     "type": "number"
                 },
@@ -7014,6 +7061,9 @@ const docTemplate = `{
                 "embedding_model_id": {
                     "type": "string"
                 },
+                "interrupt_on_new_message": {
+                    "type": "boolean"
+                },
                 "language": {
                     "type": "string"
                 },
@@ -7022,6 +7072,15 @@ const docTemplate = `{
                 },
                 "memory_model_id": {
                     "type": "string"
+                },
+                "memory_scope": {
+                    "type": "string"
+                },
+                "message_debounce_ms": {
+                    "type": "integer"
+                },
+                "unified_session": {
+                    "type": "boolean"
                 }
             }
         },
@@ -7037,6 +7096,9 @@ const docTemplate = `{
                 "embedding_model_id": {
                     "type": "string"
                 },
+                "interrupt_on_new_message": {
+                    "type": "boolean"
+                },
                 "language": {
                     "type": "string"
                 },
@@ -7045,6 +7107,15 @@ const docTemplate = `{
                 },
                 "memory_model_id": {
                     "type": "string"
+                },
+                "memory_scope": {
+                    "type": "string"
+                },
+                "message_debounce_ms": {
+                    "type": "integer"
+                },
+                "unified_session": {
+                    "type": "boolean"
                 }
             }
         },